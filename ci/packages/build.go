@@ -44,6 +44,9 @@ func Build() error {
 	if err := buildBinary(path.Join("cmd", "supervisor", "supervisor.go"), "myst_supervisor"); err != nil {
 		return err
 	}
+	if err := buildBinary(path.Join("cmd", "relay", "relay.go"), "myst_relay"); err != nil {
+		return err
+	}
 	return nil
 }
 
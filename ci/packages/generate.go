@@ -48,19 +48,34 @@ func GenerateProtobuf() error {
 	if err := sh.Run("protoc", "-I=.", "--go_out=./pb", "./pb/session.proto"); err != nil {
 		return err
 	}
-	return sh.Run("protoc", "-I=.", "--go_out=./pb", "./pb/payment.proto")
+	if err := sh.Run("protoc", "-I=.", "--go_out=./pb", "./pb/payment.proto"); err != nil {
+		return err
+	}
+	return sh.Run(
+		"protoc", "-I=.",
+		"--go_out=.", "--go_opt=paths=source_relative",
+		"--connect-go_out=.", "--connect-go_opt=paths=source_relative",
+		"./pb/control.proto",
+	)
 }
 
-// GetProtobuf installs protobuf golang compiler.
+// GetProtobuf installs protobuf golang compiler and Connect service generator.
 func GetProtobuf() error {
-	path, _ := util.GetGoBinaryPath("protoc-gen-go")
+	if err := getGoTool("protoc-gen-go", "google.golang.org/protobuf/cmd/protoc-gen-go@v1.25.0"); err != nil {
+		return err
+	}
+	return getGoTool("protoc-gen-connect-go", "github.com/bufbuild/connect-go/cmd/protoc-gen-connect-go@v1.10.0")
+}
+
+func getGoTool(name, pkg string) error {
+	path, _ := util.GetGoBinaryPath(name)
 	if path != "" {
-		fmt.Println("Tool 'protoc-gen-go' already installed")
+		fmt.Printf("Tool '%s' already installed\n", name)
 		return nil
 	}
-	err := sh.RunV("go", "get", "-u", "google.golang.org/protobuf/cmd/protoc-gen-go@v1.25.0")
+	err := sh.RunV("go", "get", "-u", pkg)
 	if err != nil {
-		fmt.Println("could not go get 'protoc-gen-go'")
+		fmt.Printf("could not go get '%s'\n", name)
 		return err
 	}
 	return nil
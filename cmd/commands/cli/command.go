@@ -55,18 +55,30 @@ const serviceHelp = `service <action> [args]
 
 	example: service start 0x7d5ee3557775aed0b85d691b036769c17349db23 openvpn --openvpn.port=1194 --openvpn.proto=UDP`
 
+// flagToken sets the token the CLI authorizes Tequilapi requests with.
+var flagToken = cli.StringFlag{
+	Name:    "token",
+	Usage:   "API or login token to authorize Tequilapi requests with, required once API tokens are created",
+	EnvVars: []string{"MYST_TEQUILAPI_TOKEN"},
+}
+
 // NewCommand constructs CLI based Mysterium UI with possibility to control quiting
 func NewCommand() *cli.Command {
 	return &cli.Command{
 		Name:   cliCommandName,
 		Usage:  "Starts a CLI client with a Tequilapi",
+		Flags:  []cli.Flag{&flagToken},
 		Before: clicontext.LoadUserConfigQuietly,
 		Action: func(ctx *cli.Context) error {
 			config.ParseFlagsNode(ctx)
 			nodeOptions := node.GetOptions()
+			tequilapi := tequilapi_client.NewClient(nodeOptions.TequilapiAddress, nodeOptions.TequilapiPort)
+			if token := ctx.String(flagToken.Name); token != "" {
+				tequilapi.SetToken(token)
+			}
 			cmdCLI := &cliApp{
 				historyFile: filepath.Join(nodeOptions.Directories.Data, ".cli_history"),
-				tequilapi:   tequilapi_client.NewClient(nodeOptions.TequilapiAddress, nodeOptions.TequilapiPort),
+				tequilapi:   tequilapi,
 			}
 			cmd.RegisterSignalCallback(utils.SoftKiller(cmdCLI.Kill))
 
@@ -325,7 +337,7 @@ func (c *cliApp) serviceGet(id string) {
 func (c *cliApp) connect(argsString string) {
 	args := strings.Fields(argsString)
 
-	helpMsg := "Please type in the provider identity. connect <consumer-identity> <provider-identity> <service-type> [dns=auto|provider|system|1.1.1.1] [disable-kill-switch]"
+	helpMsg := "Please type in the provider identity. connect <consumer-identity> <provider-identity> <service-type> [dns=auto|provider|system|1.1.1.1|doh://1.1.1.1/dns-query|dot://1.1.1.1] [include=10.0.0.0/8,example.com] [exclude=192.168.1.0/24,intranet.local] [disable-kill-switch] [failover] [dns-blocking|disable-dns-blocking] [socks5=127.0.0.1:1081] [http-proxy=127.0.0.1:8081]"
 	if len(args) < 3 {
 		info(helpMsg)
 		return
@@ -333,12 +345,44 @@ func (c *cliApp) connect(argsString string) {
 
 	consumerID, providerID, serviceType := args[0], args[1], args[2]
 
-	var disableKillSwitch bool
+	var disableKillSwitch, failover bool
 	var dns connection.DNSOption
+	var includeRoutes, excludeRoutes []string
+	var proxy *contract.ProxyOptions
+	var dnsBlocking *bool
 	var err error
 	for _, arg := range args[3:] {
+		if strings.HasPrefix(arg, "socks5=") || strings.HasPrefix(arg, "http-proxy=") {
+			kv := strings.SplitN(arg, "=", 2)
+			if proxy == nil {
+				proxy = &contract.ProxyOptions{}
+			}
+			if kv[0] == "socks5" {
+				proxy.SOCKS5Address = kv[1]
+			} else {
+				proxy.HTTPAddress = kv[1]
+			}
+			continue
+		}
+		if strings.HasPrefix(arg, "include=") || strings.HasPrefix(arg, "exclude=") {
+			kv := strings.SplitN(arg, "=", 2)
+			routes := strings.Split(kv[1], ",")
+			for _, route := range routes {
+				if err := connection.ValidateRoute(route); err != nil {
+					warn("Invalid value: ", err)
+					info(helpMsg)
+					return
+				}
+			}
+			if kv[0] == "include" {
+				includeRoutes = append(includeRoutes, routes...)
+			} else {
+				excludeRoutes = append(excludeRoutes, routes...)
+			}
+			continue
+		}
 		if strings.HasPrefix(arg, "dns=") {
-			kv := strings.Split(arg, "=")
+			kv := strings.SplitN(arg, "=", 2)
 			dns, err = connection.NewDNSOption(kv[1])
 			if err != nil {
 				warn("Invalid value: ", err)
@@ -350,6 +394,11 @@ func (c *cliApp) connect(argsString string) {
 		switch arg {
 		case "disable-kill-switch":
 			disableKillSwitch = true
+		case "failover":
+			failover = true
+		case "dns-blocking", "disable-dns-blocking":
+			enabled := arg == "dns-blocking"
+			dnsBlocking = &enabled
 		default:
 			warn("Unexpected arg:", arg)
 			info(helpMsg)
@@ -360,6 +409,11 @@ func (c *cliApp) connect(argsString string) {
 	connectOptions := contract.ConnectOptions{
 		DNS:               dns,
 		DisableKillSwitch: disableKillSwitch,
+		IncludeRoutes:     includeRoutes,
+		ExcludeRoutes:     excludeRoutes,
+		Failover:          failover,
+		Proxy:             proxy,
+		DNSBlocking:       dnsBlocking,
 	}
 
 	if consumerID == "new" {
@@ -649,6 +703,15 @@ func newAutocompleter(tequilapi *tequilapi_client.Client, proposals []contract.P
 		readline.PcItem("dns=provider"),
 		readline.PcItem("dns=system"),
 		readline.PcItem("dns=1.1.1.1"),
+		readline.PcItem("dns=doh://1.1.1.1/dns-query"),
+		readline.PcItem("dns=dot://1.1.1.1"),
+		readline.PcItem("include="),
+		readline.PcItem("exclude="),
+		readline.PcItem("failover"),
+		readline.PcItem("dns-blocking"),
+		readline.PcItem("disable-dns-blocking"),
+		readline.PcItem("socks5="),
+		readline.PcItem("http-proxy="),
 	}
 	return readline.NewPrefixCompleter(
 		readline.PcItem(
@@ -726,6 +789,7 @@ func parseStartFlags(serviceType string, args ...string) (services.StartOptions,
 	config.RegisterFlagsServiceOpenvpn(&flags)
 	config.RegisterFlagsServiceWireguard(&flags)
 	config.RegisterFlagsServiceNoop(&flags)
+	config.RegisterFlagsServiceSOCKS5(&flags)
 
 	set := flag.NewFlagSet("", flag.ContinueOnError)
 	for _, f := range flags {
@@ -740,6 +804,7 @@ func parseStartFlags(serviceType string, args ...string) (services.StartOptions,
 	config.ParseFlagsServiceOpenvpn(ctx)
 	config.ParseFlagsServiceWireguard(ctx)
 	config.ParseFlagsServiceNoop(ctx)
+	config.ParseFlagsServiceSOCKS5(ctx)
 
 	return services.GetStartOptions(serviceType)
 }
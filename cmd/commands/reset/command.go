@@ -24,7 +24,8 @@ import (
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/auth"
 	"github.com/mysteriumnetwork/node/core/node"
-	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/core/storage/backend"
 	"github.com/urfave/cli/v2"
 )
 
@@ -74,7 +75,7 @@ func newAction(ctx *cli.Context) (*resetAction, error) {
 		return nil, err
 	}
 
-	storage, err := boltdb.NewStorage(nodeOptions.Directories.Storage)
+	storage, err := backend.Open(nodeOptions.StorageBackend, nodeOptions.Directories.Storage)
 	if err != nil {
 		return nil, err
 	}
@@ -88,7 +89,7 @@ func newAction(ctx *cli.Context) (*resetAction, error) {
 // resetAction represent entrypoint for reset command with top level components.
 type resetAction struct {
 	writer  io.Writer
-	storage *boltdb.Bolt
+	storage storage.Storage
 }
 
 // Run runs action tasks.
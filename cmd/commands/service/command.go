@@ -27,6 +27,7 @@ import (
 	"github.com/mysteriumnetwork/node/cmd/commands/license"
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/config/urfavecli/clicontext"
+	"github.com/mysteriumnetwork/node/core/auth"
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/mysteriumnetwork/node/services"
@@ -56,6 +57,7 @@ func NewCommand(licenseCommandName string) *cli.Command {
 			config.ParseFlagsServiceOpenvpn(ctx)
 			config.ParseFlagsServiceWireguard(ctx)
 			config.ParseFlagsServiceNoop(ctx)
+			config.ParseFlagsServiceSOCKS5(ctx)
 			config.ParseFlagsNode(ctx)
 
 			nodeOptions := node.GetOptions()
@@ -67,8 +69,12 @@ func NewCommand(licenseCommandName string) *cli.Command {
 
 			cmd.RegisterSignalCallback(func() { quit <- nil })
 
+			tequilapi, err := newLocalClient(nodeOptions.TequilapiAddress, nodeOptions.TequilapiPort, di.JWTAuthenticator)
+			if err != nil {
+				return err
+			}
 			cmdService := &serviceCommand{
-				tequilapi:    client.NewClient(nodeOptions.TequilapiAddress, nodeOptions.TequilapiPort),
+				tequilapi:    tequilapi,
 				errorChannel: quit,
 			}
 			go func() {
@@ -86,10 +92,23 @@ func NewCommand(licenseCommandName string) *cli.Command {
 	config.RegisterFlagsServiceOpenvpn(&command.Flags)
 	config.RegisterFlagsServiceWireguard(&command.Flags)
 	config.RegisterFlagsServiceNoop(&command.Flags)
+	config.RegisterFlagsServiceSOCKS5(&command.Flags)
 
 	return command
 }
 
+// newLocalClient creates Tequilapi client of the node running in this process. The client is authorized with
+// the login token issued by the node itself, since requests without a token are rejected once API tokens exist.
+func newLocalClient(address string, port int, jwtAuth *auth.JWTAuthenticator) (*client.Client, error) {
+	token, err := jwtAuth.CreateToken(config.GetString(config.FlagTequilapiUsername))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to issue Tequilapi token")
+	}
+	tequilapi := client.NewClient(address, port)
+	tequilapi.SetToken(token.Token)
+	return tequilapi, nil
+}
+
 func describeQuit(err error) error {
 	if err == nil {
 		log.Info().Msg("Stopping application")
@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/auth"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/tequilapi"
+	"github.com/mysteriumnetwork/node/tequilapi/client"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceCommand_StartsServicesOnceAPITokenExists(t *testing.T) {
+	db, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	defer db.Close()
+
+	tokens := auth.NewAPITokens(db)
+	_, _, err = tokens.Create("dashboard", []auth.Scope{auth.ScopeReadOnly})
+	require.NoError(t, err)
+	jwtAuth := auth.NewJWTAuthenticator([]byte("secret"))
+
+	router := httprouter.New()
+	router.PUT("/identities/current", func(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		json.NewEncoder(resp).Encode(contract.IdentityRefDTO{Address: "0x1"})
+	})
+	router.POST("/services", func(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		resp.WriteHeader(http.StatusCreated)
+		json.NewEncoder(resp).Encode(contract.ServiceInfoDTO{ID: "service-1"})
+	})
+	server := httptest.NewServer(tequilapi.ApplyAuthorization(router, auth.NewAuthorizer(jwtAuth, tokens), false))
+	defer server.Close()
+
+	host, portValue, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portValue)
+	require.NoError(t, err)
+
+	// Requests without a token are rejected once API token exists.
+	_, err = client.NewClient(host, port).CurrentIdentity("", "")
+	assert.Error(t, err)
+
+	tequilapi, err := newLocalClient(host, port, jwtAuth)
+	require.NoError(t, err)
+	sc := &serviceCommand{tequilapi: tequilapi, errorChannel: make(chan error, 1)}
+
+	assert.Equal(t, "0x1", sc.unlockIdentity("", ""))
+	sc.runService(contract.ServiceStartRequest{ProviderID: "0x1", Type: "wireguard"})
+	select {
+	case err := <-sc.errorChannel:
+		assert.NoError(t, err)
+	default:
+	}
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package token
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/auth"
+	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/core/storage/backend"
+	"github.com/urfave/cli/v2"
+)
+
+// flagScope sets the scopes of the created token.
+var flagScope = cli.StringSliceFlag{
+	Name:  "scope",
+	Usage: "Scope granted by the token, one of " + scopeNames() + ", can be repeated",
+	Value: cli.NewStringSlice(string(auth.ScopeReadOnly)),
+}
+
+// NewCommand creates token command.
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "token",
+		Usage: "Manages Tequilapi API tokens, the node database is opened directly, so the node must not be running",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "create",
+				Usage:     "Creates API token, the token is only shown once",
+				ArgsUsage: "<name>",
+				Flags:     []cli.Flag{&flagScope},
+				Action: func(ctx *cli.Context) error {
+					return run(ctx, (*tokenAction).create)
+				},
+			},
+			{
+				Name:      "list",
+				Usage:     "Lists API tokens",
+				ArgsUsage: " ",
+				Action: func(ctx *cli.Context) error {
+					return run(ctx, (*tokenAction).list)
+				},
+			},
+			{
+				Name:      "revoke",
+				Usage:     "Revokes API token",
+				ArgsUsage: "<name>",
+				Action: func(ctx *cli.Context) error {
+					return run(ctx, (*tokenAction).revoke)
+				},
+			},
+		},
+	}
+}
+
+func run(ctx *cli.Context, do func(*tokenAction, *cli.Context) error) error {
+	config.ParseFlagsNode(ctx)
+
+	nodeOptions := node.GetOptions()
+	if err := nodeOptions.Directories.Check(); err != nil {
+		return err
+	}
+
+	storage, err := backend.Open(nodeOptions.StorageBackend, nodeOptions.Directories.Storage)
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+
+	action := &tokenAction{
+		writer: ctx.App.Writer,
+		tokens: auth.NewAPITokens(storage),
+	}
+	return do(action, ctx)
+}
+
+// tokenAction represent entrypoint for token commands with top level components.
+type tokenAction struct {
+	writer io.Writer
+	tokens *auth.APITokens
+}
+
+func (ta *tokenAction) create(ctx *cli.Context) error {
+	name := ctx.Args().First()
+	if name == "" {
+		return fmt.Errorf("token name is required")
+	}
+
+	var scopes []auth.Scope
+	for _, value := range ctx.StringSlice(flagScope.Name) {
+		scope, err := auth.ParseScope(value)
+		if err != nil {
+			return err
+		}
+		scopes = append(scopes, scope)
+	}
+
+	token, _, err := ta.tokens.Create(name, scopes)
+	if err != nil {
+		return fmt.Errorf("error creating API token: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(ta.writer, "API token %q created, it will not be shown again:\n%s\n", name, token)
+	_, _ = fmt.Fprintln(ta.writer, "Requests without a token are rejected from now on, pass the token to 'myst cli' with --token or MYST_TEQUILAPI_TOKEN")
+	return nil
+}
+
+func (ta *tokenAction) list(_ *cli.Context) error {
+	tokens, err := ta.tokens.List()
+	if err != nil {
+		return fmt.Errorf("error listing API tokens: %w", err)
+	}
+
+	for _, token := range tokens {
+		scopes := make([]string, 0, len(token.Scopes))
+		for _, scope := range token.Scopes {
+			scopes = append(scopes, string(scope))
+		}
+		_, _ = fmt.Fprintf(ta.writer, "%s\t%s\tcreated %s\n", token.Name, strings.Join(scopes, ","), token.CreatedAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (ta *tokenAction) revoke(ctx *cli.Context) error {
+	name := ctx.Args().First()
+	if name == "" {
+		return fmt.Errorf("token name is required")
+	}
+
+	if err := ta.tokens.Revoke(name); err != nil {
+		return fmt.Errorf("error revoking API token: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(ta.writer, "API token %q revoked\n", name)
+	return nil
+}
+
+func scopeNames() string {
+	names := make([]string, 0, len(auth.Scopes))
+	for _, scope := range auth.Scopes {
+		names = append(names, string(scope))
+	}
+	return strings.Join(names, ", ")
+}
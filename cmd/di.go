@@ -40,11 +40,14 @@ import (
 	"github.com/mysteriumnetwork/node/consumer/bandwidth"
 	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
 	"github.com/mysteriumnetwork/node/consumer/statistics"
+	"github.com/mysteriumnetwork/node/controlapi"
 	"github.com/mysteriumnetwork/node/core/auth"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/core/journal"
 	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/core/metrics"
 	"github.com/mysteriumnetwork/node/core/node"
 	nodevent "github.com/mysteriumnetwork/node/core/node/event"
 	"github.com/mysteriumnetwork/node/core/policy"
@@ -52,12 +55,14 @@ import (
 	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/state"
-	"github.com/mysteriumnetwork/node/core/storage/boltdb"
-	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrations/history"
-	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrator"
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/core/storage/backend"
+	"github.com/mysteriumnetwork/node/core/webhook"
+	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/feedback"
 	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/firewall/nftables"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	identity_registry "github.com/mysteriumnetwork/node/identity/registry"
@@ -76,8 +81,10 @@ import (
 	"github.com/mysteriumnetwork/node/services"
 	service_noop "github.com/mysteriumnetwork/node/services/noop"
 	service_openvpn "github.com/mysteriumnetwork/node/services/openvpn"
+	service_socks5 "github.com/mysteriumnetwork/node/services/socks5"
 	"github.com/mysteriumnetwork/node/session/connectivity"
 	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/session/pingpong/simulator"
 	"github.com/mysteriumnetwork/node/sleep"
 	"github.com/mysteriumnetwork/node/tequilapi"
 	tequilapi_endpoints "github.com/mysteriumnetwork/node/tequilapi/endpoints"
@@ -85,6 +92,7 @@ import (
 	"github.com/mysteriumnetwork/node/utils/netutil"
 
 	paymentClient "github.com/mysteriumnetwork/payments/client"
+	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/mysteriumnetwork/payments/uniswap"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -112,7 +120,7 @@ type Dependencies struct {
 	BrokerConnection nats.Connection
 
 	NATService       nat.NATService
-	Storage          *boltdb.Bolt
+	Storage          storage.Storage
 	Keystore         *identity.Keystore
 	IdentityManager  identity.Manager
 	SignerFactory    identity.SignerFactory
@@ -128,7 +136,14 @@ type Dependencies struct {
 	IPResolver       ip.Resolver
 	LocationResolver *location.Cache
 
-	PolicyOracle *policy.Oracle
+	PolicyOracle  *policy.Oracle
+	LocalPolicies *policy.LocalPolicies
+
+	DNSBlocklist *dns.Blocklist
+
+	WebhookDispatcher *webhook.Dispatcher
+
+	EventJournal *journal.Journal
 
 	StatisticsReporter               *statistics.SessionStatisticsReporter
 	SessionStorage                   *consumer_session.Storage
@@ -156,6 +171,8 @@ type Dependencies struct {
 
 	Authenticator     *auth.Authenticator
 	JWTAuthenticator  *auth.JWTAuthenticator
+	APITokens         *auth.APITokens
+	Authorizer        *auth.Authorizer
 	UIServer          UIServer
 	Transactor        *registry.Transactor
 	BCHelper          *paymentClient.MultichainBlockchainClient
@@ -178,6 +195,13 @@ type Dependencies struct {
 
 	MMN         *mmn.MMN
 	PilvytisAPI *pilvytis.API
+
+	MetricsExporter *metrics.Exporter
+	MetricsServer   *http.Server
+
+	ControlAPIServer *controlapi.Server
+
+	PaymentsSimulator *simulator.Simulator
 }
 
 // Bootstrap initiates all container dependencies
@@ -206,7 +230,18 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 
 	di.bootstrapEventBus()
 
-	if err := di.bootstrapStorage(nodeOptions.Directories.Storage); err != nil {
+	if err := di.bootstrapStorage(nodeOptions.StorageBackend, nodeOptions.Directories.Storage); err != nil {
+		return err
+	}
+
+	if err := di.bootstrapMetrics(nodeOptions); err != nil {
+		return err
+	}
+
+	if err := di.bootstrapWebhooks(); err != nil {
+		return err
+	}
+	if err := di.bootstrapEventJournal(); err != nil {
 		return err
 	}
 
@@ -246,6 +281,10 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 
 	di.bootstrapP2P(nodeOptions.P2PPorts)
 	di.SessionConnectivityStatusStorage = connectivity.NewStatusStorage()
+	di.LocalPolicies = policy.NewLocalPolicies(filepath.Join(config.GetString(config.FlagConfigDir), "access-policies"))
+	if err := di.bootstrapDNSBlocklist(); err != nil {
+		return err
+	}
 
 	if err := di.bootstrapServices(nodeOptions); err != nil {
 		return err
@@ -283,7 +322,7 @@ func (di *Dependencies) bootstrapP2P(p2pPorts *port.Range) {
 		natPinger = traversal.NewNoopPinger()
 	}
 
-	di.P2PListener = p2p.NewListener(di.BrokerConnection, di.SignerFactory, identityVerifier, di.IPResolver, natPinger, portPool, di.PortMapper)
+	di.P2PListener = p2p.NewListener(di.BrokerConnection, di.SignerFactory, identityVerifier, di.IPResolver, natPinger, portPool, di.PortMapper, config.GetStringSlice(config.FlagP2PRelays))
 	di.P2PDialer = p2p.NewDialer(di.BrokerConnector, di.SignerFactory, identityVerifier, di.IPResolver, natPinger, portPool)
 }
 
@@ -342,6 +381,13 @@ func (di *Dependencies) registerNoopConnection() {
 	di.ConnectionRegistry.Register(service_noop.ServiceType, service_noop.NewConnection)
 }
 
+func (di *Dependencies) registerSOCKS5Connection() {
+	service_socks5.Bootstrap()
+	di.ConnectionRegistry.Register(service_socks5.ServiceType, func() (connection.Connection, error) {
+		return service_socks5.NewConnection(service_socks5.GetConnectionOptions())
+	})
+}
+
 // Shutdown stops container
 func (di *Dependencies) Shutdown() (err error) {
 	var errs []error
@@ -371,6 +417,35 @@ func (di *Dependencies) Shutdown() (err error) {
 		di.PolicyOracle.Stop()
 	}
 
+	if di.DNSBlocklist != nil {
+		di.DNSBlocklist.Stop()
+	}
+
+	if di.EventJournal != nil {
+		di.EventJournal.Stop()
+	}
+	if di.WebhookDispatcher != nil {
+		di.WebhookDispatcher.Stop()
+	}
+
+	if di.MetricsServer != nil {
+		if err := di.MetricsServer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if di.ControlAPIServer != nil {
+		if err := di.ControlAPIServer.Stop(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if di.PaymentsSimulator != nil {
+		if err := di.PaymentsSimulator.Stop(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	if di.NATService != nil {
 		if err := di.NATService.Disable(); err != nil {
 			errs = append(errs, err)
@@ -401,14 +476,8 @@ func (di *Dependencies) Shutdown() (err error) {
 	return nil
 }
 
-func (di *Dependencies) bootstrapStorage(path string) error {
-	localStorage, err := boltdb.NewStorage(path)
-	if err != nil {
-		return err
-	}
-
-	migrator := migrator.NewMigrator(localStorage)
-	err = migrator.RunMigrations(history.Sequence)
+func (di *Dependencies) bootstrapStorage(storageBackend, path string) error {
+	localStorage, err := backend.Open(storageBackend, path)
 	if err != nil {
 		return err
 	}
@@ -441,9 +510,13 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 		return err
 	}
 
+	transactorAddress := nodeOptions.Transactor.TransactorEndpointAddress
+	if di.PaymentsSimulator != nil {
+		transactorAddress = di.PaymentsSimulator.TransactorURL()
+	}
 	di.Transactor = registry.NewTransactor(
 		di.HTTPClient,
-		nodeOptions.Transactor.TransactorEndpointAddress,
+		transactorAddress,
 		nodeOptions.Transactor.RegistryAddress,
 		nodeOptions.Hermes.HermesID,
 		nodeOptions.Transactor.ChannelImplementation,
@@ -515,6 +588,7 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 			nodeOptions.Transactor.RegistryAddress,
 			di.EventBus,
 			nodeOptions.Payments.ConsumerDataLeewayMegabytes,
+			di.SessionStorage,
 		),
 		di.ConnectionRegistry.CreateConnection,
 		di.EventBus,
@@ -527,6 +601,8 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 			di.IdentityManager,
 		),
 		di.P2PDialer,
+		di.ProposalRepository,
+		di.QualityClient,
 	)
 
 	di.LogCollector = logconfig.NewCollector(&logconfig.CurrentLogOptions)
@@ -557,6 +633,10 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 		return err
 	}
 
+	if err := di.bootstrapControlAPI(nodeOptions); err != nil {
+		return err
+	}
+
 	sleepNotifier := sleep.NewNotifier(di.ConnectionManager, di.EventBus)
 	sleepNotifier.Subscribe()
 
@@ -572,7 +652,7 @@ func (di *Dependencies) bootstrapTequilapi(nodeOptions node.Options, listener ne
 	router := tequilapi.NewAPIRouter()
 	tequilapi_endpoints.AddRoutesForDocs(router)
 	tequilapi_endpoints.AddRouteForStop(router, utils.SoftKiller(di.Shutdown))
-	tequilapi_endpoints.AddRoutesForAuthentication(router, di.Authenticator, di.JWTAuthenticator)
+	tequilapi_endpoints.AddRoutesForAuthentication(router, di.Authenticator, di.JWTAuthenticator, di.APITokens)
 	tequilapi_endpoints.AddRoutesForIdentities(router, di.IdentityManager, di.IdentitySelector, di.IdentityRegistry, di.ConsumerBalanceTracker, di.ChannelAddressCalculator, di.HermesChannelRepository, di.BCHelper, di.Transactor)
 	tequilapi_endpoints.AddRoutesForConnection(router, di.ConnectionManager, di.StateKeeper, di.ProposalRepository, di.IdentityRegistry)
 	tequilapi_endpoints.AddRoutesForSessions(router, di.SessionStorage)
@@ -580,8 +660,11 @@ func (di *Dependencies) bootstrapTequilapi(nodeOptions node.Options, listener ne
 	tequilapi_endpoints.AddRoutesForProposals(router, di.ProposalRepository, di.QualityClient)
 	tequilapi_endpoints.AddRoutesForService(router, di.ServicesManager, services.JSONParsersByType)
 	tequilapi_endpoints.AddRoutesForPayout(router, di.IdentityManager, di.SignerFactory, di.MysteriumAPI)
-	tequilapi_endpoints.AddRoutesForAccessPolicies(di.HTTPClient, router, config.GetString(config.FlagAccessPolicyAddress))
+	tequilapi_endpoints.AddRoutesForAccessPolicies(di.HTTPClient, router, config.GetString(config.FlagAccessPolicyAddress), di.LocalPolicies)
 	tequilapi_endpoints.AddRoutesForNAT(router, di.StateKeeper)
+	tequilapi_endpoints.AddRoutesForDNS(router, di.DNSBlocklist)
+	tequilapi_endpoints.AddRoutesForWebhooks(router, di.WebhookDispatcher)
+	tequilapi_endpoints.AddRoutesForJournal(router, di.EventJournal)
 	tequilapi_endpoints.AddRoutesForTransactor(router, di.Transactor, di.HermesPromiseSettler, di.SettlementHistoryStorage, common.HexToAddress(nodeOptions.Hermes.HermesID))
 	tequilapi_endpoints.AddRoutesForConfig(router)
 	tequilapi_endpoints.AddRoutesForMMN(router, di.MMN)
@@ -589,6 +672,7 @@ func (di *Dependencies) bootstrapTequilapi(nodeOptions node.Options, listener ne
 	tequilapi_endpoints.AddRoutesForConnectivityStatus(router, di.SessionConnectivityStatusStorage)
 	tequilapi_endpoints.AddRoutesForCurrencyExchange(router, di.Exchange)
 	tequilapi_endpoints.AddRoutesForPilvytis(router, di.PilvytisAPI)
+	tequilapi_endpoints.AddRoutesForMetrics(router, di.MetricsExporter.Handler())
 	if err := tequilapi_endpoints.AddRoutesForSSE(router, di.StateKeeper, di.EventBus); err != nil {
 		return nil, err
 	}
@@ -598,7 +682,48 @@ func (di *Dependencies) bootstrapTequilapi(nodeOptions node.Options, listener ne
 	}
 
 	corsPolicy := tequilapi.NewMysteriumCorsPolicy()
-	return tequilapi.NewServer(listener, router, corsPolicy), nil
+	handler := tequilapi.ApplyAuthorization(router, di.Authorizer, nodeOptions.TequilapiAuthRequired)
+	return tequilapi.NewServer(listener, handler, corsPolicy), nil
+}
+
+func (di *Dependencies) bootstrapControlAPI(nodeOptions node.Options) error {
+	if nodeOptions.ControlAPIAddress == "" {
+		return nil
+	}
+
+	service := controlapi.NewService(controlapi.Dependencies{
+		IdentityManager:    di.IdentityManager,
+		IdentitySelector:   di.IdentitySelector,
+		IdentityRegistry:   di.IdentityRegistry,
+		ConnectionManager:  di.ConnectionManager,
+		StateProvider:      di.StateKeeper,
+		ProposalRepository: di.ProposalRepository,
+		ServiceManager:     di.ServicesManager,
+		ServiceOptions:     services.JSONParsersByType,
+		SessionStorage:     di.SessionStorage,
+		SignerFactory:      di.SignerFactory,
+		PayoutInfoRegistry: di.MysteriumAPI,
+	})
+	if err := service.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+
+	tlsConfig, err := controlapi.TLSConfig(nodeOptions.ControlAPITLSCert, nodeOptions.ControlAPITLSKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to load control API certificate")
+	}
+	listener, err := controlapi.Listen(nodeOptions.ControlAPIAddress, tlsConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to listen for control API requests")
+	}
+	interceptor := controlapi.NewAuthInterceptor(di.Authorizer, nodeOptions.TequilapiAuthRequired)
+	di.ControlAPIServer = controlapi.NewServer(listener, service, interceptor)
+	go func() {
+		if err := di.ControlAPIServer.Serve(); err != nil {
+			log.Error().Err(err).Msg("Control API server stopped")
+		}
+	}()
+	return nil
 }
 
 // function decides on network definition combined from testnet/localnet flags and possible overrides
@@ -666,18 +791,25 @@ func (di *Dependencies) bootstrapNetworkComponents(options node.Options) (err er
 		return err
 	}
 
-	bc := paymentClient.NewBlockchain(di.EtherClient, options.Payments.BCTimeout)
-	clients := make(map[int64]paymentClient.BC)
-	clients[network.DefaultChainID] = paymentClient.NewBlockchainWithRetries(bc, time.Millisecond*300, 3)
-	di.BCHelper = paymentClient.NewMultichainBlockchainClient(clients)
-
-	di.HermesURLGetter = pingpong.NewHermesURLGetter(di.BCHelper, common.HexToAddress(options.Transactor.RegistryAddress))
+	if options.Payments.Simulator {
+		log.Warn().Msg("Using payments simulator, Hermes, transactor and blockchain are not contacted")
+		if err := di.bootstrapPaymentsSimulator(options, network); err != nil {
+			return err
+		}
+	} else {
+		bc := paymentClient.NewBlockchain(di.EtherClient, options.Payments.BCTimeout)
+		clients := make(map[int64]paymentClient.BC)
+		clients[network.DefaultChainID] = paymentClient.NewBlockchainWithRetries(bc, time.Millisecond*300, 3)
+		di.BCHelper = paymentClient.NewMultichainBlockchainClient(clients)
 
-	registryStorage := registry.NewRegistrationStatusStorage(di.Storage)
-	if di.IdentityRegistry, err = identity_registry.NewIdentityRegistryContract(di.EtherClient, common.HexToAddress(options.Transactor.RegistryAddress), common.HexToAddress(options.Hermes.HermesID), registryStorage, di.EventBus); err != nil {
-		return err
+		registryStorage := registry.NewRegistrationStatusStorage(di.Storage)
+		if di.IdentityRegistry, err = identity_registry.NewIdentityRegistryContract(di.EtherClient, common.HexToAddress(options.Transactor.RegistryAddress), common.HexToAddress(options.Hermes.HermesID), registryStorage, di.EventBus); err != nil {
+			return err
+		}
 	}
 
+	di.HermesURLGetter = pingpong.NewHermesURLGetter(di.BCHelper, common.HexToAddress(options.Transactor.RegistryAddress))
+
 	hermesURL, err := di.HermesURLGetter.GetHermesURL(common.HexToAddress(options.Hermes.HermesID))
 	if err != nil {
 		return err
@@ -703,6 +835,29 @@ func (di *Dependencies) bootstrapNetworkComponents(options node.Options) (err er
 	return di.IdentityRegistry.Subscribe(di.EventBus)
 }
 
+// bootstrapPaymentsSimulator replaces Hermes, transactor and blockchain with the in-process simulator,
+// so payments work without any external services.
+func (di *Dependencies) bootstrapPaymentsSimulator(options node.Options, network metadata.NetworkDefinition) (err error) {
+	simulatorConfig := simulator.DefaultConfig(
+		network.DefaultChainID,
+		common.HexToAddress(options.Hermes.HermesID),
+		common.HexToAddress(options.Transactor.RegistryAddress),
+		common.HexToAddress(options.Transactor.ChannelImplementation),
+	)
+	if di.PaymentsSimulator, err = simulator.New(simulatorConfig, di.Storage); err != nil {
+		return err
+	}
+	if err := di.PaymentsSimulator.Start("127.0.0.1:0"); err != nil {
+		return err
+	}
+
+	di.BCHelper = paymentClient.NewMultichainBlockchainClient(map[int64]paymentClient.BC{
+		network.DefaultChainID: di.PaymentsSimulator.Blockchain(),
+	})
+	di.IdentityRegistry = di.PaymentsSimulator.Registry(di.EventBus)
+	return nil
+}
+
 func (di *Dependencies) bootstrapEventBus() {
 	di.EventBus = eventbus.New()
 }
@@ -778,6 +933,31 @@ func (di *Dependencies) bootstrapQualityComponents(options node.OptionsQuality)
 	return nil
 }
 
+func (di *Dependencies) bootstrapMetrics(options node.Options) error {
+	di.MetricsExporter = metrics.NewExporter()
+	if err := di.MetricsExporter.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+
+	if options.MetricsAddress == "" {
+		return nil
+	}
+	listener, err := net.Listen("tcp", options.MetricsAddress)
+	if err != nil {
+		return errors.Wrap(err, "failed to listen for metrics requests")
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", di.MetricsExporter.Handler())
+	di.MetricsServer = &http.Server{Handler: mux}
+	go func() {
+		if err := di.MetricsServer.Serve(listener); err != http.ErrServerClosed {
+			log.Error().Err(err).Msg("Metrics server stopped")
+		}
+	}()
+	log.Info().Msgf("Metrics served on: %s", listener.Addr())
+	return nil
+}
+
 func (di *Dependencies) bootstrapLocationComponents(options node.Options) (err error) {
 	if _, err = firewall.AllowURLAccess(options.Location.IPDetectorURL); err != nil {
 		return errors.Wrap(err, "failed to add firewall exception")
@@ -833,6 +1013,8 @@ func (di *Dependencies) bootstrapAuthenticator() error {
 	}
 	di.Authenticator = auth.NewAuthenticator(di.Storage)
 	di.JWTAuthenticator = auth.NewJWTAuthenticator(key)
+	di.APITokens = auth.NewAPITokens(di.Storage)
+	di.Authorizer = auth.NewAuthorizer(di.JWTAuthenticator, di.APITokens)
 
 	return nil
 }
@@ -841,6 +1023,50 @@ func (di *Dependencies) bootstrapPilvytis(options node.Options) {
 	di.PilvytisAPI = pilvytis.NewAPI(di.HTTPClient, options.PilvytisAddress, di.SignerFactory)
 }
 
+// bootstrapWebhooks starts posting the node events to the webhooks.
+func (di *Dependencies) bootstrapWebhooks() error {
+	di.WebhookDispatcher = webhook.NewDispatcher(di.Storage, webhook.Config{
+		MaxAttempts:         config.GetInt(config.FlagWebhookMaxAttempts),
+		Retention:           config.GetDuration(config.FlagWebhookRetention),
+		BalanceLowThreshold: crypto.FloatToBigMyst(config.GetFloat64(config.FlagWebhookBalanceLowThreshold)),
+	})
+	if err := di.WebhookDispatcher.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+	di.WebhookDispatcher.Start()
+	return nil
+}
+
+// bootstrapEventJournal starts recording the selected event bus topics, nothing is recorded when none are selected.
+func (di *Dependencies) bootstrapEventJournal() error {
+	di.EventJournal = journal.NewJournal(di.Storage, journal.Config{
+		Topics:     config.GetStringSlice(config.FlagJournalTopics),
+		MaxAge:     config.GetDuration(config.FlagJournalMaxAge),
+		MaxEntries: config.GetInt(config.FlagJournalMaxEntries),
+	})
+	if err := di.EventJournal.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+	di.EventJournal.Start()
+	return nil
+}
+
+// bootstrapDNSBlocklist loads the configured DNS blocklists, it is left nil when there are none.
+func (di *Dependencies) bootstrapDNSBlocklist() error {
+	sources := config.GetStringSlice(config.FlagDNSBlocklistSources)
+	if len(sources) == 0 {
+		return nil
+	}
+
+	mode, err := dns.ParseBlockMode(config.GetString(config.FlagDNSBlocklistMode))
+	if err != nil {
+		return err
+	}
+	di.DNSBlocklist = dns.NewBlocklist(sources, config.GetDuration(config.FlagDNSBlocklistRefreshInterval), mode)
+	di.DNSBlocklist.Start()
+	return nil
+}
+
 func (di *Dependencies) bootstrapNATComponents(options node.Options) error {
 	di.NATTracker = event.NewTracker()
 	if err := di.NATTracker.Subscribe(di.EventBus); err != nil {
@@ -860,6 +1086,9 @@ func (di *Dependencies) bootstrapNATComponents(options node.Options) error {
 }
 
 func (di *Dependencies) bootstrapFirewall(options node.OptionsFirewall) error {
+	if err := nftables.SetBackend(config.GetString(config.FlagFirewallBackend)); err != nil {
+		return err
+	}
 	firewall.DefaultOutgoingFirewall = firewall.NewOutgoingTrafficFirewall(config.GetBool(config.FlagOutgoingFirewall))
 	if err := firewall.DefaultOutgoingFirewall.Setup(); err != nil {
 		return err
@@ -878,7 +1107,12 @@ func (di *Dependencies) bootstrapFirewall(options node.OptionsFirewall) error {
 			return err
 		}
 
-		_, err = firewall.BlockNonTunnelTraffic(firewall.Global, outboundIP)
+		outboundIPs := []string{outboundIP}
+		if outboundIPv6, err := resolver.GetOutboundIPv6(); err == nil {
+			outboundIPs = append(outboundIPs, outboundIPv6)
+		}
+
+		_, err = firewall.BlockNonTunnelTraffic(firewall.Global, outboundIPs...)
 		return err
 	}
 	return nil
@@ -29,6 +29,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/core/shaper"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mmn"
@@ -38,11 +39,14 @@ import (
 	service_openvpn "github.com/mysteriumnetwork/node/services/openvpn"
 	openvpn_discovery "github.com/mysteriumnetwork/node/services/openvpn/discovery"
 	openvpn_service "github.com/mysteriumnetwork/node/services/openvpn/service"
+	service_socks5 "github.com/mysteriumnetwork/node/services/socks5"
 	"github.com/mysteriumnetwork/node/services/wireguard"
 	wireguard_connection "github.com/mysteriumnetwork/node/services/wireguard/connection"
 	"github.com/mysteriumnetwork/node/services/wireguard/endpoint"
+	"github.com/mysteriumnetwork/node/services/wireguard/endpoint/netstack"
 	"github.com/mysteriumnetwork/node/services/wireguard/resources"
 	wireguard_service "github.com/mysteriumnetwork/node/services/wireguard/service"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/session/pingpong"
 	pingpong_noop "github.com/mysteriumnetwork/node/session/pingpong/noop"
 	"github.com/mysteriumnetwork/node/ui"
@@ -68,6 +72,7 @@ func (di *Dependencies) bootstrapServices(nodeOptions node.Options) error {
 	di.bootstrapServiceOpenvpn(nodeOptions)
 	di.bootstrapServiceNoop(nodeOptions)
 	di.bootstrapServiceWireguard(nodeOptions)
+	di.bootstrapServiceSOCKS5(nodeOptions)
 
 	return nil
 }
@@ -101,8 +106,9 @@ func (di *Dependencies) bootstrapServiceWireguard(nodeOptions node.Options) {
 				wgOptions,
 				portPool,
 				di.ServiceFirewall,
+				di.DNSBlocklist,
 			)
-			return svc, wireguard_service.GetProposal(loc), nil
+			return svc, wireguard_service.GetProposal(loc, svc.IPv6()), nil
 		},
 	)
 }
@@ -140,6 +146,7 @@ func (di *Dependencies) bootstrapServiceOpenvpn(nodeOptions node.Options) {
 			portPool,
 			di.EventBus,
 			di.ServiceFirewall,
+			di.DNSBlocklist,
 		)
 		return manager, proposal, nil
 	}
@@ -160,6 +167,20 @@ func (di *Dependencies) bootstrapServiceNoop(nodeOptions node.Options) {
 	)
 }
 
+func (di *Dependencies) bootstrapServiceSOCKS5(nodeOptions node.Options) {
+	di.ServiceRegistry.Register(
+		service_socks5.ServiceType,
+		func(serviceOptions service.Options) (service.Service, market.ServiceProposal, error) {
+			loc, err := di.LocationResolver.DetectLocation()
+			if err != nil {
+				return nil, market.ServiceProposal{}, err
+			}
+
+			return service_socks5.NewManager(di.EventBus), service_socks5.GetProposal(loc), nil
+		},
+	)
+}
+
 func (di *Dependencies) bootstrapProviderRegistrar(nodeOptions node.Options) error {
 	if nodeOptions.Consumer {
 		log.Debug().Msg("Skipping provider registrar for consumer mode")
@@ -197,6 +218,7 @@ func (di *Dependencies) bootstrapHermesPromiseSettler(nodeOptions node.Options)
 		di.IdentityRegistry,
 		di.Keystore,
 		di.SettlementHistoryStorage,
+		di.EventBus,
 		pingpong.HermesPromiseSettlerConfig{
 			HermesAddress:        common.HexToAddress(nodeOptions.Hermes.HermesID),
 			Threshold:            nodeOptions.Payments.HermesPromiseSettlingThreshold,
@@ -211,6 +233,24 @@ func (di *Dependencies) bootstrapHermesPromiseSettler(nodeOptions node.Options)
 	return nil
 }
 
+func newPricingSchedule() (service.PricingSchedule, error) {
+	schedule := service.PricingSchedule{
+		Demand: service.DemandPricing{
+			Capacity:      config.GetInt(config.FlagPaymentDemandCapacity),
+			Threshold:     config.GetFloat64(config.FlagPaymentDemandThreshold),
+			MaxMultiplier: config.GetFloat64(config.FlagPaymentDemandMaxMultiplier),
+		},
+	}
+	for _, value := range config.GetStringSlice(config.FlagPaymentPricingSchedule) {
+		window, err := service.ParsePricingWindow(value)
+		if err != nil {
+			return service.PricingSchedule{}, err
+		}
+		schedule.Windows = append(schedule.Windows, window)
+	}
+	return schedule, nil
+}
+
 // bootstrapServiceComponents initiates ServicesManager dependency
 func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) error {
 	di.NATService = nat.NewService()
@@ -225,6 +265,7 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 		di.HTTPClient,
 		config.GetString(config.FlagAccessPolicyAddress),
 		config.GetDuration(config.FlagAccessPolicyFetchInterval),
+		di.LocalPolicies,
 	)
 	go di.PolicyOracle.Start()
 
@@ -239,7 +280,6 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 			nodeOptions.Payments.MaxUnpaidInvoiceValue,
 			di.BCHelper,
 			di.EventBus,
-			serviceInstance.Proposal,
 			di.HermesPromiseHandler,
 			common.HexToAddress(nodeOptions.Hermes.HermesID),
 		)
@@ -254,6 +294,14 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 		)
 	}
 
+	pricingSchedule, err := newPricingSchedule()
+	if err != nil {
+		return err
+	}
+	newPaymentMethod := func(price service.Price) market.PaymentMethod {
+		return pingpong.NewPaymentMethod(price.PerGB, price.PerMinute)
+	}
+
 	di.ServicesManager = service.NewManager(
 		di.ServiceRegistry,
 		di.DiscoveryFactory,
@@ -262,6 +310,7 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 		di.P2PListener,
 		newP2PSessionHandler,
 		di.SessionConnectivityStatusStorage,
+		service.NewPricer(pricingSchedule, newPaymentMethod, di.ServiceSessions),
 	)
 
 	serviceCleaner := service.Cleaner{SessionStorage: di.ServiceSessions}
@@ -269,12 +318,18 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 		log.Error().Err(err).Msg("Failed to subscribe service cleaner")
 	}
 
+	sessionShaper := shaper.New()
+	if err := di.EventBus.Subscribe(sessionEvent.AppTopicSessionTunnel, sessionShaper.HandleSessionTunnel); err != nil {
+		log.Error().Err(err).Msg("Failed to subscribe session shaper")
+	}
+
 	return nil
 }
 
 func (di *Dependencies) registerConnections(nodeOptions node.Options) {
 	di.registerOpenvpnConnection(nodeOptions)
 	di.registerNoopConnection()
+	di.registerSOCKS5Connection()
 	di.registerWireguardConnection(nodeOptions)
 }
 
@@ -289,8 +344,10 @@ func (di *Dependencies) registerWireguardConnection(nodeOptions node.Options) {
 		opts := wireguard_connection.Options{
 			DNSScriptDir:     nodeOptions.Directories.Script,
 			HandshakeTimeout: 1 * time.Minute,
+			Blocklist:        di.DNSBlocklist,
+			DNSBlocking:      config.GetBool(config.FlagDNSBlocklistConsumer),
 		}
-		return wireguard_connection.NewConnection(opts, di.IPResolver, endpointFactory, handshakeWaiter)
+		return wireguard_connection.NewConnection(opts, di.IPResolver, endpointFactory, netstack.NewConnectionEndpoint, handshakeWaiter)
 	}
 	di.ConnectionRegistry.Register(wireguard.ServiceType, connFactory)
 }
@@ -308,7 +365,7 @@ func (di *Dependencies) bootstrapUIServer(options node.Options) (err error) {
 			return err
 		}
 	}
-	di.UIServer = ui.NewServer(bindAddress, options.UI.UIPort, options.TequilapiAddress, options.TequilapiPort, di.JWTAuthenticator, di.HTTPClient)
+	di.UIServer = ui.NewServer(bindAddress, options.UI.UIPort, options.TequilapiAddress, options.TequilapiPort, di.Authorizer, di.HTTPClient)
 	return nil
 }
 
@@ -61,8 +61,14 @@ func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsDiscove
 			}
 			discoveryWorker.AddWorker(dhtNode)
 
-			proposalRegistry.AddRegistry(dhtdiscovery.NewRegistry())
-			proposalRepository.Add(dhtdiscovery.NewRepository())
+			storage := brokerdiscovery.NewStorage(di.EventBus)
+			dhtRepository := dhtdiscovery.NewRepository(dhtNode, storage, options.FetchInterval)
+			if options.FetchEnabled {
+				discoveryWorker.AddWorker(dhtRepository)
+			}
+
+			proposalRegistry.AddRegistry(dhtdiscovery.NewRegistry(dhtNode, 2*options.PingInterval))
+			proposalRepository.Add(dhtRepository)
 
 		default:
 			return errors.Errorf("unknown discovery adapter: %s", discoveryType)
@@ -25,6 +25,7 @@ import (
 	"github.com/mysteriumnetwork/node/cmd/commands/license"
 	"github.com/mysteriumnetwork/node/cmd/commands/reset"
 	"github.com/mysteriumnetwork/node/cmd/commands/service"
+	"github.com/mysteriumnetwork/node/cmd/commands/token"
 	"github.com/mysteriumnetwork/node/cmd/commands/version"
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/logconfig"
@@ -45,6 +46,7 @@ var (
 	serviceCommand = service.NewCommand(licenseCommand.Name)
 	cliCommand     = command_cli.NewCommand()
 	resetCommand   = reset.NewCommand()
+	tokenCommand   = token.NewCommand()
 )
 
 func main() {
@@ -85,6 +87,7 @@ func NewCommand() (*cli.App, error) {
 		daemonCommand,
 		cliCommand,
 		resetCommand,
+		tokenCommand,
 	}
 
 	return app, nil
@@ -0,0 +1,87 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/mysteriumnetwork/node/logconfig"
+	"github.com/mysteriumnetwork/node/metadata"
+	"github.com/mysteriumnetwork/node/p2p/relay"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Relay CLI flags.
+var (
+	flagVersion     = flag.Bool("version", false, "Print version")
+	flagAddress     = flag.String("address", ":4100", "UDP address to serve relay on")
+	flagIdleTimeout = flag.Duration("idle-timeout", relay.DefaultIdleTimeout, "Time after which connection without traffic is dropped")
+	flagMaxBindings = flag.Int("max-bindings", relay.DefaultLimits.MaxBindings, "Maximum number of peer bindings kept by the relay")
+	flagMaxPerIP    = flag.Int("max-bindings-per-ip", relay.DefaultLimits.MaxBindingsPerIP, "Maximum number of peer bindings created from a single IP")
+	flagRegRate     = flag.Int("registrations-per-second", relay.DefaultLimits.RegistrationsPerSecond, "Maximum number of registrations accepted from a single IP per second")
+	flagLogLevel    = flag.String("log-level", zerolog.InfoLevel.String(), "Logging level")
+)
+
+func main() {
+	flag.Parse()
+
+	if *flagVersion {
+		fmt.Println(metadata.VersionAsString())
+		os.Exit(0)
+	}
+
+	logconfig.Bootstrap()
+	level, err := zerolog.ParseLevel(*flagLogLevel)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid log level")
+	}
+	zerolog.SetGlobalLevel(level)
+
+	addr, err := net.ResolveUDPAddr("udp4", *flagAddress)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid relay address")
+	}
+	conn, err := net.ListenUDP("udp4", addr)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to listen")
+	}
+	server := relay.NewServer(conn, *flagIdleTimeout, relay.Limits{
+		MaxBindings:            *flagMaxBindings,
+		MaxBindingsPerIP:       *flagMaxPerIP,
+		RegistrationsPerSecond: *flagRegRate,
+	})
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-stop
+		log.Info().Msg("Stopping relay")
+		server.Stop()
+	}()
+
+	log.Info().Msgf("Serving relay on %s", server.Addr())
+	if err := server.Serve(); err != nil {
+		log.Fatal().Err(err).Msg("Relay stopped")
+	}
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagDNSBlocklistSources blocklists of DNS names.
+	FlagDNSBlocklistSources = cli.StringSliceFlag{
+		Name:  "dns.blocklist.sources",
+		Usage: "Hosts files and domain lists of the blocked DNS names, given as local paths or HTTP(S) URLs",
+		Value: cli.NewStringSlice(),
+	}
+	// FlagDNSBlocklistRefreshInterval blocklists refresh interval.
+	FlagDNSBlocklistRefreshInterval = cli.DurationFlag{
+		Name:  "dns.blocklist.refresh-interval",
+		Usage: `Blocklists refresh interval { "30m", "6h", "24h" }`,
+		Value: 24 * time.Hour,
+	}
+	// FlagDNSBlocklistMode answer to the blocked DNS queries.
+	FlagDNSBlocklistMode = cli.StringFlag{
+		Name:  "dns.blocklist.mode",
+		Usage: "Answer to the blocked DNS queries: 'nxdomain' or 'null' for 0.0.0.0 and :: addresses",
+		Value: "nxdomain",
+	}
+	// FlagDNSBlocklistConsumer enables DNS blocking for consumer connections by default.
+	FlagDNSBlocklistConsumer = cli.BoolFlag{
+		Name:  "dns.blocklist.consumer",
+		Usage: "Block the listed DNS names for consumer connections, unless the connection disables it",
+		Value: false,
+	}
+	// FlagDNSBlocklistProvider enables DNS blocking for consumers of the provided services by default.
+	FlagDNSBlocklistProvider = cli.BoolFlag{
+		Name:  "dns.blocklist.provider",
+		Usage: "Block the listed DNS names for consumers of the provided services, unless the service disables it",
+		Value: false,
+	}
+)
+
+// RegisterFlagsDNS function registers DNS flags to flag list.
+func RegisterFlagsDNS(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagDNSBlocklistSources,
+		&FlagDNSBlocklistRefreshInterval,
+		&FlagDNSBlocklistMode,
+		&FlagDNSBlocklistConsumer,
+		&FlagDNSBlocklistProvider,
+	)
+}
+
+// ParseFlagsDNS function fills in DNS options from CLI context.
+func ParseFlagsDNS(ctx *cli.Context) {
+	Current.ParseStringSliceFlag(ctx, FlagDNSBlocklistSources)
+	Current.ParseDurationFlag(ctx, FlagDNSBlocklistRefreshInterval)
+	Current.ParseStringFlag(ctx, FlagDNSBlocklistMode)
+	Current.ParseBoolFlag(ctx, FlagDNSBlocklistConsumer)
+	Current.ParseBoolFlag(ctx, FlagDNSBlocklistProvider)
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagJournalTopics event bus topics recorded to the event journal.
+	FlagJournalTopics = cli.StringSliceFlag{
+		Name:  "journal.topics",
+		Usage: `Event bus topics to record to the event journal, e.g. "Session change", "settlement_complete". The journal is disabled when none are given`,
+		Value: cli.NewStringSlice(),
+	}
+	// FlagJournalMaxAge how long the event journal entries are kept.
+	FlagJournalMaxAge = cli.DurationFlag{
+		Name:  "journal.max-age",
+		Usage: "How long the event journal entries are kept, 0 keeps them regardless of age",
+		Value: 7 * 24 * time.Hour,
+	}
+	// FlagJournalMaxEntries maximum number of the event journal entries.
+	FlagJournalMaxEntries = cli.IntFlag{
+		Name:  "journal.max-entries",
+		Usage: "Maximum number of the event journal entries kept, the oldest ones are removed first. 0 disables the limit",
+		Value: 100000,
+	}
+)
+
+// RegisterFlagsJournal function registers event journal flags to flag list.
+func RegisterFlagsJournal(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagJournalTopics,
+		&FlagJournalMaxAge,
+		&FlagJournalMaxEntries,
+	)
+}
+
+// ParseFlagsJournal function fills in event journal options from CLI context.
+func ParseFlagsJournal(ctx *cli.Context) {
+	Current.ParseStringSliceFlag(ctx, FlagJournalTopics)
+	Current.ParseDurationFlag(ctx, FlagJournalMaxAge)
+	Current.ParseIntFlag(ctx, FlagJournalMaxEntries)
+}
@@ -97,10 +97,24 @@ var (
 		Usage: "List of comma separated (no spaces) subnets to be protected from access via VPN",
 		Value: "10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,127.0.0.0/8",
 	}
+	// FlagFirewallBackend chooses the firewall backend on linux.
+	FlagFirewallBackend = cli.StringFlag{
+		Name: "firewall.backend",
+		Usage: "Firewall backend on linux: auto, nftables or iptables. " +
+			"The auto backend uses iptables if iptables FORWARD chain has rules or DROP policy (e.g. set by Docker), " +
+			"because nftables can't accept traffic dropped by iptables",
+		Value: "auto",
+	}
 	// FlagShaperEnabled enables bandwidth limitation.
 	FlagShaperEnabled = cli.BoolFlag{
 		Name:  "shaper.enabled",
-		Usage: "Limit service bandwidth",
+		Usage: "Limit bandwidth of every service session",
+	}
+	// FlagShaperBandwidth sets the default bandwidth limit of every service session.
+	FlagShaperBandwidth = cli.Uint64Flag{
+		Name:  "shaper.bandwidth",
+		Usage: fmt.Sprintf("Bandwidth limit of every service session in Kbps, used when --%s is set", FlagShaperEnabled.Name),
+		Value: 5000,
 	}
 	// FlagKeystoreLightweight determines the scrypt memory complexity.
 	FlagKeystoreLightweight = cli.BoolFlag{
@@ -175,6 +189,12 @@ var (
 		Usage: "Default password for API authentication",
 		Value: "mystberry",
 	}
+	// FlagTequilapiAuthRequired rejects API requests without login or API token.
+	FlagTequilapiAuthRequired = cli.BoolFlag{
+		Name:  "tequilapi.auth.required",
+		Usage: "Reject API requests without login or API token, they are rejected anyway once any API token is created",
+		Value: false,
+	}
 	// FlagPProfEnable enables pprof via TequilAPI.
 	FlagPProfEnable = cli.BoolFlag{
 		Name:  "pprof.enable",
@@ -217,6 +237,12 @@ var (
 		Usage: "Range of P2P listen ports (e.g. 51820:52075), value of 0:0 means disabled",
 		Value: "0:0",
 	}
+	// FlagP2PRelays sets relay servers offered to consumers as a fallback when NAT hole punching fails.
+	FlagP2PRelays = cli.StringSliceFlag{
+		Name:  "p2p.relays",
+		Usage: "Relay server addresses (host:port) separated by comma, offered to consumers when NAT hole punching fails",
+		Value: cli.NewStringSlice(),
+	}
 
 	//FlagConsumer sets to run as consumer only which allows to skip bootstrap for some of the dependencies.
 	FlagConsumer = cli.BoolFlag{
@@ -224,6 +250,42 @@ var (
 		Usage: "Run in consumer mode only.",
 		Value: false,
 	}
+	// FlagMetricsAddress sets the address of dedicated Prometheus metrics listener.
+	FlagMetricsAddress = cli.StringFlag{
+		Name:  "metrics.address",
+		Usage: "Address to serve Prometheus metrics on besides Tequilapi /metrics (e.g. :9091), disabled if empty",
+		Value: "",
+	}
+	// FlagControlAPIAddress sets the address of gRPC control API listener.
+	FlagControlAPIAddress = cli.StringFlag{
+		Name:  "controlapi.address",
+		Usage: "Address to serve gRPC control API on (e.g. tcp://127.0.0.1:4450 or unix:///var/run/myst.sock), disabled if empty",
+		Value: "",
+	}
+	// FlagControlAPITLSCert sets the certificate file of gRPC control API.
+	FlagControlAPITLSCert = cli.StringFlag{
+		Name:  "controlapi.tls.cert",
+		Usage: "Certificate file to serve gRPC control API with TLS, required to listen on non-loopback TCP address",
+		Value: "",
+	}
+	// FlagControlAPITLSKey sets the private key file of gRPC control API certificate.
+	FlagControlAPITLSKey = cli.StringFlag{
+		Name:  "controlapi.tls.key",
+		Usage: "Private key file of gRPC control API certificate",
+		Value: "",
+	}
+	// FlagSOCKS5ListenAddress sets the local address consumer SOCKS5 connection serves the proxy on.
+	FlagSOCKS5ListenAddress = cli.StringFlag{
+		Name:  "socks5.listen-address",
+		Usage: "Local address to serve the proxy on when connected to SOCKS5 service",
+		Value: "127.0.0.1:1080",
+	}
+	// FlagStorageBackend sets the database backend of node storage.
+	FlagStorageBackend = cli.StringFlag{
+		Name:  "storage.backend",
+		Usage: "Storage backend: boltdb or sqlite. Data of boltdb is imported on the first start of sqlite, which requires the node built with cgo",
+		Value: "boltdb",
+	}
 )
 
 // RegisterFlagsNode function register node flags to flag list
@@ -238,6 +300,9 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 	RegisterFlagsHermes(flags)
 	RegisterFlagsPayments(flags)
 	RegisterFlagsPolicy(flags)
+	RegisterFlagsDNS(flags)
+	RegisterFlagsWebhook(flags)
+	RegisterFlagsJournal(flags)
 	RegisterFlagsMMN(flags)
 	RegisterFlagsPilvytis(flags)
 
@@ -253,7 +318,9 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 		&FlagFeedbackURL,
 		&FlagFirewallKillSwitch,
 		&FlagFirewallProtectedNetworks,
+		&FlagFirewallBackend,
 		&FlagShaperEnabled,
+		&FlagShaperBandwidth,
 		&FlagKeystoreLightweight,
 		&FlagLogHTTP,
 		&FlagLogLevel,
@@ -264,6 +331,7 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 		&FlagTequilapiPort,
 		&FlagTequilapiUsername,
 		&FlagTequilapiPassword,
+		&FlagTequilapiAuthRequired,
 		&FlagPProfEnable,
 		&FlagUIEnable,
 		&FlagUIAddress,
@@ -271,7 +339,14 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 		&FlagUserMode,
 		&FlagVendorID,
 		&FlagP2PListenPorts,
+		&FlagP2PRelays,
 		&FlagConsumer,
+		&FlagStorageBackend,
+		&FlagMetricsAddress,
+		&FlagControlAPIAddress,
+		&FlagControlAPITLSCert,
+		&FlagControlAPITLSKey,
+		&FlagSOCKS5ListenAddress,
 	)
 
 	return nil
@@ -287,6 +362,9 @@ func ParseFlagsNode(ctx *cli.Context) {
 	ParseFlagsHermes(ctx)
 	ParseFlagsPayments(ctx)
 	ParseFlagsPolicy(ctx)
+	ParseFlagsDNS(ctx)
+	ParseFlagsWebhook(ctx)
+	ParseFlagsJournal(ctx)
 	ParseFlagsMMN(ctx)
 	ParseFlagPilvytis(ctx)
 
@@ -301,7 +379,9 @@ func ParseFlagsNode(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagFeedbackURL)
 	Current.ParseBoolFlag(ctx, FlagFirewallKillSwitch)
 	Current.ParseStringFlag(ctx, FlagFirewallProtectedNetworks)
+	Current.ParseStringFlag(ctx, FlagFirewallBackend)
 	Current.ParseBoolFlag(ctx, FlagShaperEnabled)
+	Current.ParseUInt64Flag(ctx, FlagShaperBandwidth)
 	Current.ParseBoolFlag(ctx, FlagKeystoreLightweight)
 	Current.ParseBoolFlag(ctx, FlagLogHTTP)
 	Current.ParseStringFlag(ctx, FlagLogLevel)
@@ -312,6 +392,7 @@ func ParseFlagsNode(ctx *cli.Context) {
 	Current.ParseIntFlag(ctx, FlagTequilapiPort)
 	Current.ParseStringFlag(ctx, FlagTequilapiUsername)
 	Current.ParseStringFlag(ctx, FlagTequilapiPassword)
+	Current.ParseBoolFlag(ctx, FlagTequilapiAuthRequired)
 	Current.ParseBoolFlag(ctx, FlagPProfEnable)
 	Current.ParseBoolFlag(ctx, FlagUIEnable)
 	Current.ParseStringFlag(ctx, FlagUIAddress)
@@ -319,7 +400,14 @@ func ParseFlagsNode(ctx *cli.Context) {
 	Current.ParseBoolFlag(ctx, FlagUserMode)
 	Current.ParseStringFlag(ctx, FlagVendorID)
 	Current.ParseStringFlag(ctx, FlagP2PListenPorts)
+	Current.ParseStringSliceFlag(ctx, FlagP2PRelays)
 	Current.ParseBoolFlag(ctx, FlagConsumer)
+	Current.ParseStringFlag(ctx, FlagStorageBackend)
+	Current.ParseStringFlag(ctx, FlagMetricsAddress)
+	Current.ParseStringFlag(ctx, FlagControlAPIAddress)
+	Current.ParseStringFlag(ctx, FlagControlAPITLSCert)
+	Current.ParseStringFlag(ctx, FlagControlAPITLSKey)
+	Current.ParseStringFlag(ctx, FlagSOCKS5ListenAddress)
 
 	ValidateAddressFlags(FlagTequilapiAddress)
 }
@@ -109,6 +109,35 @@ var (
 		Usage: "sets the upper limit of session payment value before forcing an invoice. If this value is exceeded before a payment interval is reached, an invoice is sent.",
 		Value: "30000000000000000",
 	}
+	// FlagPaymentsConsumerLimitSession caps the spending of a single consumer session.
+	FlagPaymentsConsumerLimitSession = cli.Float64Flag{
+		Name:  "payments.consumer.limit.session",
+		Usage: "Caps the spending of a single session in MYST, the connection is disconnected once the cap would be exceeded. Zero is unlimited.",
+		Value: 0,
+	}
+	// FlagPaymentsConsumerLimitDay caps the daily spending of the consumer.
+	FlagPaymentsConsumerLimitDay = cli.Float64Flag{
+		Name:  "payments.consumer.limit.day",
+		Usage: "Caps the spending of the sessions started during the current UTC day in MYST, the connection is disconnected once the cap would be exceeded. Zero is unlimited.",
+		Value: 0,
+	}
+	// FlagPaymentsConsumerLimitMonth caps the monthly spending of the consumer.
+	FlagPaymentsConsumerLimitMonth = cli.Float64Flag{
+		Name:  "payments.consumer.limit.month",
+		Usage: "Caps the spending of the sessions started during the current UTC month in MYST, the connection is disconnected once the cap would be exceeded. Zero is unlimited.",
+		Value: 0,
+	}
+	// FlagPaymentsConsumerLimitPerProvider counts the daily and monthly spending of each provider separately.
+	FlagPaymentsConsumerLimitPerProvider = cli.BoolFlag{
+		Name:  "payments.consumer.limit.per-provider",
+		Usage: "Apply the daily and monthly spending caps to each provider separately",
+		Value: false,
+	}
+	// FlagPaymentsSimulator runs in-process Hermes and transactor simulator in place of the real ones.
+	FlagPaymentsSimulator = cli.BoolFlag{
+		Name:  "payments.simulator",
+		Usage: "Use in-process Hermes, transactor and payment channels simulator instead of the real ones. Payments are not real, for testing only.",
+	}
 )
 
 // RegisterFlagsPayments function register payments flags to flag list.
@@ -127,8 +156,13 @@ func RegisterFlagsPayments(flags *[]cli.Flag) {
 		&FlagPaymentsConsumerPricePerGBLowerBound,
 		&FlagPaymentsConsumerDataLeewayMegabytes,
 		&FlagPaymentsMaxUnpaidInvoiceValue,
+		&FlagPaymentsConsumerLimitSession,
+		&FlagPaymentsConsumerLimitDay,
+		&FlagPaymentsConsumerLimitMonth,
+		&FlagPaymentsConsumerLimitPerProvider,
 		&FlagPaymentsWethAddress,
 		&FlagPaymentsDaiAddress,
+		&FlagPaymentsSimulator,
 	)
 }
 
@@ -146,6 +180,11 @@ func ParseFlagsPayments(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagPaymentsConsumerPricePerGBLowerBound)
 	Current.ParseUInt64Flag(ctx, FlagPaymentsConsumerDataLeewayMegabytes)
 	Current.ParseStringFlag(ctx, FlagPaymentsMaxUnpaidInvoiceValue)
+	Current.ParseFloat64Flag(ctx, FlagPaymentsConsumerLimitSession)
+	Current.ParseFloat64Flag(ctx, FlagPaymentsConsumerLimitDay)
+	Current.ParseFloat64Flag(ctx, FlagPaymentsConsumerLimitMonth)
+	Current.ParseBoolFlag(ctx, FlagPaymentsConsumerLimitPerProvider)
 	Current.ParseStringFlag(ctx, FlagPaymentsWethAddress)
 	Current.ParseStringFlag(ctx, FlagPaymentsDaiAddress)
+	Current.ParseBoolFlag(ctx, FlagPaymentsSimulator)
 }
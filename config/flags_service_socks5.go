@@ -0,0 +1,56 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagSOCKS5PriceMinute sets the price per minute for provided SOCKS5 service.
+	FlagSOCKS5PriceMinute = cli.Float64Flag{
+		Name:  "socks5.price-minute",
+		Usage: "Sets the price of the SOCKS5 service per minute.",
+	}
+	// FlagSOCKS5PriceGB sets the price per GiB for provided SOCKS5 service.
+	FlagSOCKS5PriceGB = cli.Float64Flag{
+		Name:  "socks5.price-gb",
+		Usage: "Sets the price of the SOCKS5 service per GiB.",
+	}
+	// FlagSOCKS5AccessPolicies a comma-separated list of access policies that determines allowed identities to use the service.
+	FlagSOCKS5AccessPolicies = cli.StringFlag{
+		Name:  "socks5.access-policies",
+		Usage: "Comma separated list that determines the access policies of the SOCKS5 service.",
+	}
+)
+
+// RegisterFlagsServiceSOCKS5 function register SOCKS5 flags to flag list
+func RegisterFlagsServiceSOCKS5(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagSOCKS5PriceMinute,
+		&FlagSOCKS5PriceGB,
+		&FlagSOCKS5AccessPolicies,
+	)
+}
+
+// ParseFlagsServiceSOCKS5 parses CLI flags and registers value to configuration
+func ParseFlagsServiceSOCKS5(ctx *cli.Context) {
+	Current.ParseFloat64Flag(ctx, FlagSOCKS5PriceMinute)
+	Current.ParseFloat64Flag(ctx, FlagSOCKS5PriceGB)
+	Current.ParseStringFlag(ctx, FlagSOCKS5AccessPolicies)
+}
@@ -59,6 +59,29 @@ var (
 		Usage: "Sets the price per minute applied to provider service.",
 		Value: 0.00001,
 	}
+	// FlagPaymentPricingSchedule sets the prices of the provided services for the time windows of the day.
+	FlagPaymentPricingSchedule = cli.StringSliceFlag{
+		Name:  "payment.pricing-schedule",
+		Usage: "Time windows of the local day with their own prices, formatted as HH:MM-HH:MM/<price per GiB>/<price per minute>, e.g. 18:00-23:00/0.3/0.00002.",
+	}
+	// FlagPaymentDemandCapacity sets the count of sessions the provided service is able to serve.
+	FlagPaymentDemandCapacity = cli.IntFlag{
+		Name:  "payment.demand.capacity",
+		Usage: "Count of sessions the service is able to serve, used to raise the price as the service gets busy. Disabled if 0.",
+		Value: 0,
+	}
+	// FlagPaymentDemandThreshold sets the share of the capacity in use, above which the price starts to rise.
+	FlagPaymentDemandThreshold = cli.Float64Flag{
+		Name:  "payment.demand.threshold",
+		Usage: "Share of the service capacity in use, above which the price starts to rise.",
+		Value: 0.5,
+	}
+	// FlagPaymentDemandMaxMultiplier sets the multiplier of the price of the service at its capacity.
+	FlagPaymentDemandMaxMultiplier = cli.Float64Flag{
+		Name:  "payment.demand.max-multiplier",
+		Usage: "Multiplier of the price once the service is at its capacity.",
+		Value: 2,
+	}
 )
 
 // RegisterFlagsServiceStart registers CLI flags used to start a service.
@@ -69,6 +92,10 @@ func RegisterFlagsServiceStart(flags *[]cli.Flag) {
 		&FlagAgreedTermsConditions,
 		&FlagPaymentPricePerGB,
 		&FlagPaymentPricePerMinute,
+		&FlagPaymentPricingSchedule,
+		&FlagPaymentDemandCapacity,
+		&FlagPaymentDemandThreshold,
+		&FlagPaymentDemandMaxMultiplier,
 		&FlagAccessPolicyList,
 	)
 }
@@ -80,5 +107,9 @@ func ParseFlagsServiceStart(ctx *cli.Context) {
 	Current.ParseBoolFlag(ctx, FlagAgreedTermsConditions)
 	Current.ParseFloat64Flag(ctx, FlagPaymentPricePerGB)
 	Current.ParseFloat64Flag(ctx, FlagPaymentPricePerMinute)
+	Current.ParseStringSliceFlag(ctx, FlagPaymentPricingSchedule)
+	Current.ParseIntFlag(ctx, FlagPaymentDemandCapacity)
+	Current.ParseFloat64Flag(ctx, FlagPaymentDemandThreshold)
+	Current.ParseFloat64Flag(ctx, FlagPaymentDemandMaxMultiplier)
 	Current.ParseStringFlag(ctx, FlagAccessPolicyList)
 }
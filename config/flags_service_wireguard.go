@@ -34,6 +34,12 @@ var (
 		Usage: "Subnet to be used by the wireguard service",
 		Value: "10.182.0.0/16",
 	}
+	// FlagWireguardListenSubnet6 IPv6 subnet to be used by the wireguard service.
+	FlagWireguardListenSubnet6 = cli.StringFlag{
+		Name:  "wireguard.allowed.subnet6",
+		Usage: "IPv6 subnet to be used by the wireguard service, /64 network of it is allocated for every session (e.g. fd10:182::/48). IPv6 is not provided when empty",
+		Value: "",
+	}
 	// FlagWireguardPriceMinute sets the price per minute for provided wireguard service.
 	FlagWireguardPriceMinute = cli.Float64Flag{
 		Name:  "wireguard.price-minute",
@@ -56,6 +62,7 @@ func RegisterFlagsServiceWireguard(flags *[]cli.Flag) {
 	*flags = append(*flags,
 		&FlagWireguardListenPorts,
 		&FlagWireguardListenSubnet,
+		&FlagWireguardListenSubnet6,
 		&FlagWireguardPriceMinute,
 		&FlagWireguardPriceGB,
 		&FlagWireguardAccessPolicies,
@@ -66,6 +73,7 @@ func RegisterFlagsServiceWireguard(flags *[]cli.Flag) {
 func ParseFlagsServiceWireguard(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagWireguardListenPorts)
 	Current.ParseStringFlag(ctx, FlagWireguardListenSubnet)
+	Current.ParseStringFlag(ctx, FlagWireguardListenSubnet6)
 	Current.ParseFloat64Flag(ctx, FlagWireguardPriceMinute)
 	Current.ParseFloat64Flag(ctx, FlagWireguardPriceGB)
 	Current.ParseStringFlag(ctx, FlagWireguardAccessPolicies)
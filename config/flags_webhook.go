@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagWebhookMaxAttempts number of attempts to deliver a webhook event.
+	FlagWebhookMaxAttempts = cli.IntFlag{
+		Name:  "webhooks.max-attempts",
+		Usage: "Number of attempts to deliver a webhook event before giving up",
+		Value: 10,
+	}
+	// FlagWebhookRetention how long the finished webhook deliveries are kept.
+	FlagWebhookRetention = cli.DurationFlag{
+		Name:  "webhooks.retention",
+		Usage: "How long the delivered and failed webhook deliveries are kept for inspection",
+		Value: 7 * 24 * time.Hour,
+	}
+	// FlagWebhookBalanceLowThreshold balance in MYST to announce low balance at.
+	FlagWebhookBalanceLowThreshold = cli.Float64Flag{
+		Name:  "webhooks.balance-low-threshold",
+		Usage: "Balance in MYST, the balance.low webhook event is sent once the balance drops below it",
+		Value: 1,
+	}
+)
+
+// RegisterFlagsWebhook function registers webhook flags to flag list.
+func RegisterFlagsWebhook(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagWebhookMaxAttempts,
+		&FlagWebhookRetention,
+		&FlagWebhookBalanceLowThreshold,
+	)
+}
+
+// ParseFlagsWebhook function fills in webhook options from CLI context.
+func ParseFlagsWebhook(ctx *cli.Context) {
+	Current.ParseIntFlag(ctx, FlagWebhookMaxAttempts)
+	Current.ParseDurationFlag(ctx, FlagWebhookRetention)
+	Current.ParseFloat64Flag(ctx, FlagWebhookBalanceLowThreshold)
+}
@@ -20,7 +20,7 @@ package session
 import (
 	"time"
 
-	"github.com/asdine/storm/v3/q"
+	"github.com/mysteriumnetwork/node/core/storage"
 	"github.com/mysteriumnetwork/node/identity"
 )
 
@@ -91,31 +91,31 @@ func (f *Filter) SetStatus(status string) *Filter {
 	return f
 }
 
-func (f *Filter) toMatcher() q.Matcher {
-	where := make([]q.Matcher, 0)
+func (f *Filter) toQuery() storage.Query {
+	where := make([]storage.Condition, 0)
 	if f.StartedFrom != nil {
-		where = append(where, q.Gte("Started", *f.StartedFrom))
+		where = append(where, storage.Gte("Started", *f.StartedFrom))
 	}
 	if f.StartedTo != nil {
-		where = append(where, q.Lte("Started", *f.StartedTo))
+		where = append(where, storage.Lte("Started", *f.StartedTo))
 	}
 	if f.Direction != nil {
-		where = append(where, q.Eq("Direction", *f.Direction))
+		where = append(where, storage.Eq("Direction", *f.Direction))
 	}
 	if f.ConsumerID != nil {
-		where = append(where, q.Eq("ConsumerID", *f.ConsumerID))
+		where = append(where, storage.Eq("ConsumerID", *f.ConsumerID))
 	}
 	if f.HermesID != nil {
-		where = append(where, q.Eq("HermesID", *f.HermesID))
+		where = append(where, storage.Eq("HermesID", *f.HermesID))
 	}
 	if f.ProviderID != nil {
-		where = append(where, q.Eq("ProviderID", *f.ProviderID))
+		where = append(where, storage.Eq("ProviderID", *f.ProviderID))
 	}
 	if f.ServiceType != nil {
-		where = append(where, q.Eq("ServiceType", *f.ServiceType))
+		where = append(where, storage.Eq("ServiceType", *f.ServiceType))
 	}
 	if f.Status != nil {
-		where = append(where, q.Eq("Status", *f.Status))
+		where = append(where, storage.Eq("Status", *f.Status))
 	}
-	return q.And(where...)
+	return storage.Query{Where: where, OrderBy: "Started", Reverse: true}
 }
@@ -23,9 +23,8 @@ import (
 	"sync"
 	"time"
 
-	"github.com/asdine/storm/v3"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
-	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	session_node "github.com/mysteriumnetwork/node/session"
@@ -40,7 +39,7 @@ type timeGetter func() time.Time
 
 // Storage contains functions for storing, getting session objects.
 type Storage struct {
-	storage    *boltdb.Bolt
+	storage    storage.Storage
 	timeGetter timeGetter
 
 	mu             sync.RWMutex
@@ -48,7 +47,7 @@ type Storage struct {
 }
 
 // NewSessionStorage creates session repository with given dependencies.
-func NewSessionStorage(storage *boltdb.Bolt) *Storage {
+func NewSessionStorage(storage storage.Storage) *Storage {
 	return &Storage{
 		storage:    storage,
 		timeGetter: time.Now,
@@ -74,6 +73,12 @@ func (repo *Storage) Subscribe(bus eventbus.Subscriber) error {
 	if err := bus.Subscribe(connectionstate.AppTopicConnectionStatistics, repo.consumeConnectionStatisticsEvent); err != nil {
 		return err
 	}
+	if err := bus.Subscribe(connectionstate.AppTopicConnectionHopSession, repo.consumeConnectionSessionEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(connectionstate.AppTopicConnectionHopStatistics, repo.consumeConnectionStatisticsEvent); err != nil {
+		return err
+	}
 	return bus.Subscribe(pingpong_event.AppTopicInvoicePaid, repo.consumeConnectionSpendingEvent)
 }
 
@@ -84,14 +89,8 @@ func (repo *Storage) GetAll() ([]History, error) {
 
 // List retrieves stored entries.
 func (repo *Storage) List(filter *Filter) (result []History, err error) {
-	query := repo.storage.DB().
-		From(sessionStorageBucketName).
-		Select(filter.toMatcher()).
-		OrderBy("Started").
-		Reverse()
-
-	err = query.Find(&result)
-	if errors.Is(err, storm.ErrNotFound) {
+	err = repo.storage.Find(sessionStorageBucketName, filter.toQuery(), &result)
+	if errors.Is(err, storage.ErrNotFound) {
 		return []History{}, nil
 	}
 
@@ -100,20 +99,12 @@ func (repo *Storage) List(filter *Filter) (result []History, err error) {
 
 // Stats fetches aggregated statistics to Filter.Stats.
 func (repo *Storage) Stats(filter *Filter) (result Stats, err error) {
-	query := repo.storage.DB().
-		From(sessionStorageBucketName).
-		Select(filter.toMatcher()).
-		OrderBy("Started").
-		Reverse()
+	groups, err := repo.aggregate(filter, "ConsumerID")
 
 	result = NewStats()
-	err = query.Each(new(History), func(record interface{}) error {
-		session := record.(*History)
-
-		result.Add(*session)
-
-		return nil
-	})
+	for _, group := range groups {
+		result.addGroup(group.Values[0].(identity.Identity), group)
+	}
 	return result, err
 }
 
@@ -121,11 +112,7 @@ const stepDay = 24 * time.Hour
 
 // StatsByDay retrieves aggregated statistics grouped by day to Filter.StatsByDay.
 func (repo *Storage) StatsByDay(filter *Filter) (result map[time.Time]Stats, err error) {
-	query := repo.storage.DB().
-		From(sessionStorageBucketName).
-		Select(filter.toMatcher()).
-		OrderBy("Started").
-		Reverse()
+	groups, err := repo.aggregate(filter, "Started", "ConsumerID")
 
 	// fill the period with zeros
 	result = make(map[time.Time]Stats)
@@ -135,17 +122,25 @@ func (repo *Storage) StatsByDay(filter *Filter) (result map[time.Time]Stats, err
 		}
 	}
 
-	err = query.Each(new(History), func(record interface{}) error {
-		session := record.(*History)
-
-		i := session.Started.Truncate(stepDay)
-		stats := result[i]
-		stats.Add(*session)
+	for _, group := range groups {
+		i := group.Values[0].(time.Time)
+		stats, ok := result[i]
+		if !ok {
+			stats = NewStats()
+		}
+		stats.addGroup(group.Values[1].(identity.Identity), group)
 		result[i] = stats
+	}
+	return result, err
+}
 
-		return nil
+// aggregate sums the sessions selected by the filter in the storage, grouped by the given fields.
+func (repo *Storage) aggregate(filter *Filter, groupBy ...string) ([]storage.Group, error) {
+	return repo.storage.Aggregate(sessionStorageBucketName, &History{}, storage.Aggregation{
+		Where:   filter.toQuery().Where,
+		GroupBy: groupBy,
+		Sum:     statsSums,
 	})
-	return result, err
 }
 
 // consumeServiceSessionEvent consumes the provided sessions.
@@ -21,9 +21,18 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/mysteriumnetwork/node/core/storage"
 	"github.com/mysteriumnetwork/node/identity"
 )
 
+// statsSums are the session sums aggregated by the storage, in the order addGroup reads them.
+var statsSums = []storage.Sum{
+	storage.SumField("DataSent"),
+	storage.SumField("DataReceived"),
+	storage.SumDuration("Started", "Updated"),
+	storage.SumField("Tokens"),
+}
+
 // NewStats initiates zero Stats instance.
 func NewStats() Stats {
 	return Stats{
@@ -57,3 +66,13 @@ func (s *Stats) Add(session History) {
 	s.SumDuration += session.GetDuration()
 	s.SumTokens = new(big.Int).Add(s.SumTokens, session.Tokens)
 }
+
+// addGroup accumulates sessions of the consumer aggregated by the storage to statistics.
+func (s *Stats) addGroup(consumerID identity.Identity, group storage.Group) {
+	s.Count += group.Count
+	s.ConsumerCounts[consumerID] += group.Count
+	s.SumDataSent += group.Sums[0].Uint64()
+	s.SumDataReceived += group.Sums[1].Uint64()
+	s.SumDuration += time.Duration(group.Sums[2].Int64())
+	s.SumTokens = new(big.Int).Add(s.SumTokens, group.Sums[3])
+}
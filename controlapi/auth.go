@@ -0,0 +1,127 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package controlapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/mysteriumnetwork/node/core/auth"
+	"github.com/mysteriumnetwork/node/pb/pbconnect"
+)
+
+// Authorizer resolves the scopes granted by the login or API token, same as for Tequilapi.
+type Authorizer interface {
+	Authorize(token string) ([]auth.Scope, error)
+	TokensIssued() (bool, error)
+}
+
+// procedureScopes maps the RPCs to the scopes needed to call them, the ones missing here require the admin scope.
+var procedureScopes = map[string]auth.Scope{
+	pbconnect.ControlServiceListIdentitiesProcedure:   auth.ScopeReadOnly,
+	pbconnect.ControlServiceGetConnectionProcedure:    auth.ScopeReadOnly,
+	pbconnect.ControlServiceWatchConnectionProcedure:  auth.ScopeReadOnly,
+	pbconnect.ControlServiceListServicesProcedure:     auth.ScopeReadOnly,
+	pbconnect.ControlServiceListSessionsProcedure:     auth.ScopeReadOnly,
+	pbconnect.ControlServiceListProposalsProcedure:    auth.ScopeReadOnly,
+	pbconnect.ControlServiceGetPayoutInfoProcedure:    auth.ScopeReadOnly,
+	pbconnect.ControlServiceWatchStateProcedure:       auth.ScopeReadOnly,
+	pbconnect.ControlServiceConnectProcedure:          auth.ScopeConnection,
+	pbconnect.ControlServiceDisconnectProcedure:       auth.ScopeConnection,
+	pbconnect.ControlServiceStartServiceProcedure:     auth.ScopeService,
+	pbconnect.ControlServiceStopServiceProcedure:      auth.ScopeService,
+	pbconnect.ControlServiceUpdatePayoutInfoProcedure: auth.ScopePayments,
+}
+
+func requiredScope(procedure string) auth.Scope {
+	if scope, ok := procedureScopes[procedure]; ok {
+		return scope
+	}
+	return auth.ScopeAdmin
+}
+
+// authInterceptor checks whether the token of the call grants the scope of the RPC.
+// Calls without the token are let through unless the authorization is required or any API token is issued.
+type authInterceptor struct {
+	authorizer Authorizer
+	required   bool
+}
+
+// NewAuthInterceptor creates the interceptor authorizing the calls with the given authorizer.
+func NewAuthInterceptor(authorizer Authorizer, required bool) connect.Interceptor {
+	return &authInterceptor{authorizer: authorizer, required: required}
+}
+
+func (i *authInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if err := i.authorize(req.Spec().Procedure, req.Header()); err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+func (i *authInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *authInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		if err := i.authorize(conn.Spec().Procedure, conn.RequestHeader()); err != nil {
+			return err
+		}
+		return next(ctx, conn)
+	}
+}
+
+func (i *authInterceptor) authorize(procedure string, header http.Header) error {
+	token := ""
+	if value := header.Get("Authorization"); strings.HasPrefix(value, "Bearer ") {
+		token = strings.TrimPrefix(value, "Bearer ")
+	}
+
+	if token == "" {
+		required := i.required
+		if !required {
+			issued, err := i.authorizer.TokensIssued()
+			if err != nil {
+				return connect.NewError(connect.CodeInternal, err)
+			}
+			required = issued
+		}
+		if required {
+			return connect.NewError(connect.CodeUnauthenticated, errors.New("authorization token is required"))
+		}
+		return nil
+	}
+
+	scopes, err := i.authorizer.Authorize(token)
+	if errors.Is(err, auth.ErrUnauthorized) {
+		return connect.NewError(connect.CodeUnauthenticated, err)
+	}
+	if err != nil {
+		return connect.NewError(connect.CodeInternal, err)
+	}
+	if required := requiredScope(procedure); !auth.Allows(scopes, required) {
+		return connect.NewError(connect.CodePermissionDenied, errors.New("token lacks the "+string(required)+" scope"))
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package controlapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/mysteriumnetwork/node/core/auth"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/pb/pbconnect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAuthorizer struct {
+	tokens map[string][]auth.Scope
+	issued bool
+}
+
+func (ma *mockAuthorizer) Authorize(token string) ([]auth.Scope, error) {
+	scopes, ok := ma.tokens[token]
+	if !ok {
+		return nil, auth.ErrUnauthorized
+	}
+	return scopes, nil
+}
+
+func (ma *mockAuthorizer) TokensIssued() (bool, error) {
+	return ma.issued, nil
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	tokens := map[string][]auth.Scope{
+		"dashboard": {auth.ScopeReadOnly},
+		"vpn":       {auth.ScopeConnection},
+	}
+	for _, tc := range []struct {
+		name      string
+		procedure string
+		token     string
+		required  bool
+		issued    bool
+		code      connect.Code
+	}{
+		{name: "read-only reads", procedure: pbconnect.ControlServiceGetConnectionProcedure, token: "dashboard"},
+		{name: "read-only connects", procedure: pbconnect.ControlServiceConnectProcedure, token: "dashboard", code: connect.CodePermissionDenied},
+		{name: "connection control connects", procedure: pbconnect.ControlServiceConnectProcedure, token: "vpn"},
+		{name: "connection control unlocks identity", procedure: pbconnect.ControlServiceUnlockIdentityProcedure, token: "vpn", code: connect.CodePermissionDenied},
+		{name: "invalid token", procedure: pbconnect.ControlServiceGetConnectionProcedure, token: "invalid", code: connect.CodeUnauthenticated},
+		{name: "no token", procedure: pbconnect.ControlServiceConnectProcedure},
+		{name: "no token when required", procedure: pbconnect.ControlServiceGetConnectionProcedure, required: true, code: connect.CodeUnauthenticated},
+		{name: "no token when API tokens are issued", procedure: pbconnect.ControlServiceGetConnectionProcedure, issued: true, code: connect.CodeUnauthenticated},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			interceptor := &authInterceptor{authorizer: &mockAuthorizer{tokens: tokens, issued: tc.issued}, required: tc.required}
+			header := http.Header{}
+			if tc.token != "" {
+				header.Set("Authorization", "Bearer "+tc.token)
+			}
+
+			err := interceptor.authorize(tc.procedure, header)
+			if tc.code == 0 {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, tc.code, connect.CodeOf(err))
+			}
+		})
+	}
+}
+
+func TestAuthInterceptor_AuthorizesCalls(t *testing.T) {
+	url := startAuthorizedTestServer(t, Dependencies{
+		ConnectionManager: &mockConnectionManager{onStatusReturn: connectionstate.Status{State: connectionstate.NotConnected}},
+	}, eventbus.New(), &mockAuthorizer{tokens: map[string][]auth.Scope{"dashboard": {auth.ScopeReadOnly}}, issued: true})
+	client := pbconnect.NewControlServiceClient(http.DefaultClient, url)
+
+	_, err := client.GetConnection(context.Background(), connect.NewRequest(&pb.GetConnectionRequest{}))
+	assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+
+	req := connect.NewRequest(&pb.GetConnectionRequest{})
+	req.Header().Set("Authorization", "Bearer dashboard")
+	_, err = client.GetConnection(context.Background(), req)
+	assert.NoError(t, err)
+
+	// Streams are authorized before they start.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.WatchConnection(ctx, connect.NewRequest(&pb.WatchConnectionRequest{}))
+	require.NoError(t, err)
+	defer stream.Close()
+	assert.False(t, stream.Receive())
+	assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(stream.Err()))
+}
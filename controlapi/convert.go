@@ -0,0 +1,202 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package controlapi
+
+import (
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/service"
+	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toProposal(p market.ServiceProposal) *pb.Proposal {
+	proposal := &pb.Proposal{
+		ProviderID:    p.ProviderID,
+		ServiceType:   p.ServiceType,
+		BandwidthKbps: p.BandwidthKbps,
+	}
+	if p.ServiceDefinition != nil {
+		location := p.ServiceDefinition.GetLocation()
+		proposal.Country = location.Country
+		proposal.NodeType = location.NodeType
+		if definition, ok := p.ServiceDefinition.(market.IPv6ServiceDefinition); ok {
+			proposal.Ipv6 = definition.SupportsIPv6()
+		}
+	}
+	if p.PaymentMethod != nil {
+		price := p.PaymentMethod.GetPrice()
+		rate := p.PaymentMethod.GetRate()
+		proposal.PaymentMethod = p.PaymentMethod.GetType()
+		proposal.Price = bigString(price.Amount)
+		proposal.Currency = string(price.Currency)
+		proposal.RatePerSeconds = uint64(rate.PerTime.Seconds())
+		proposal.RatePerBytes = rate.PerByte
+	}
+	return proposal
+}
+
+func toProposalFromDTO(p contract.ProposalDTO) *pb.Proposal {
+	return &pb.Proposal{
+		ProviderID:     p.ProviderID,
+		ServiceType:    p.ServiceType,
+		Country:        p.ServiceDefinition.LocationOriginate.Country,
+		NodeType:       p.ServiceDefinition.LocationOriginate.NodeType,
+		Ipv6:           p.ServiceDefinition.IPv6,
+		BandwidthKbps:  p.BandwidthKbps,
+		PaymentMethod:  p.PaymentMethod.Type,
+		Price:          bigString(p.PaymentMethod.Price.Amount),
+		Currency:       string(p.PaymentMethod.Price.Currency),
+		RatePerSeconds: p.PaymentMethod.Rate.PerSeconds,
+		RatePerBytes:   p.PaymentMethod.Rate.PerBytes,
+	}
+}
+
+func toConnectionStatus(status connectionstate.Status) *pb.ConnectionStatus {
+	res := &pb.ConnectionStatus{
+		Status:     string(status.State),
+		ConsumerID: status.ConsumerID.Address,
+		SessionID:  string(status.SessionID),
+		StartedAt:  toTimestamp(status.StartedAt),
+	}
+	if status.HermesID != (common.Address{}) {
+		res.HermesID = status.HermesID.Hex()
+	}
+	// None exists, for not started connection.
+	if status.Proposal.ProviderID != "" {
+		res.Proposal = toProposal(status.Proposal)
+	}
+	for _, hop := range status.Hops {
+		resHop := &pb.ConnectionHop{
+			Status:        string(hop.State),
+			SessionID:     string(hop.SessionID),
+			BytesSent:     hop.Statistics.BytesSent,
+			BytesReceived: hop.Statistics.BytesReceived,
+		}
+		if hop.Proposal.ProviderID != "" {
+			resHop.Proposal = toProposal(hop.Proposal)
+		}
+		res.Hops = append(res.Hops, resHop)
+	}
+	return res
+}
+
+func toConnectionStatistics(conn stateEvent.Connection) *pb.ConnectionStatistics {
+	return &pb.ConnectionStatistics{
+		BytesSent:          conn.Statistics.BytesSent,
+		BytesReceived:      conn.Statistics.BytesReceived,
+		ThroughputSent:     uint64(conn.Throughput.Up),
+		ThroughputReceived: uint64(conn.Throughput.Down),
+		TokensSpent:        bigString(conn.Invoice.AgreementTotal),
+	}
+}
+
+func toService(id service.ID, instance *service.Instance) (*pb.Service, error) {
+	options, err := json.Marshal(instance.Options)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Service{
+		Id:         string(id),
+		ProviderID: instance.ProviderID.Address,
+		Type:       instance.Type,
+		Status:     string(instance.State()),
+		Options:    options,
+		Proposal:   toProposal(instance.CopyProposal()),
+	}, nil
+}
+
+func toSession(se session.History) *pb.Session {
+	return &pb.Session{
+		Id:              string(se.SessionID),
+		Direction:       se.Direction,
+		ConsumerID:      se.ConsumerID.Address,
+		HermesID:        se.HermesID,
+		ProviderID:      se.ProviderID.Address,
+		ServiceType:     se.ServiceType,
+		ConsumerCountry: se.ConsumerCountry,
+		ProviderCountry: se.ProviderCountry,
+		BytesSent:       se.DataSent,
+		BytesReceived:   se.DataReceived,
+		Tokens:          bigString(se.Tokens),
+		Status:          se.Status,
+		StartedAt:       toTimestamp(se.Started),
+		UpdatedAt:       toTimestamp(se.Updated),
+	}
+}
+
+func toNodeState(state stateEvent.State) (*pb.NodeState, error) {
+	res := &pb.NodeState{
+		NatStatus: &pb.NATStatus{
+			Status: state.NATStatus.Status,
+			Error:  state.NATStatus.Error,
+		},
+		Connection:           toConnectionStatus(state.Connection.Session),
+		ConnectionStatistics: toConnectionStatistics(state.Connection),
+	}
+	for _, s := range state.Services {
+		options, err := json.Marshal(s.Options)
+		if err != nil {
+			return nil, err
+		}
+		res.Services = append(res.Services, &pb.Service{
+			Id:         s.ID,
+			ProviderID: s.ProviderID,
+			Type:       s.Type,
+			Status:     s.Status,
+			Options:    options,
+			Proposal:   toProposalFromDTO(s.Proposal),
+		})
+	}
+	for _, se := range state.Sessions {
+		res.Sessions = append(res.Sessions, toSession(se))
+	}
+	for _, id := range state.Identities {
+		res.Identities = append(res.Identities, &pb.Identity{
+			Address:            id.Address,
+			RegistrationStatus: id.RegistrationStatus.String(),
+			ChannelAddress:     id.ChannelAddress.Hex(),
+			Balance:            bigString(id.Balance),
+			Earnings:           bigString(id.Earnings),
+			EarningsTotal:      bigString(id.EarningsTotal),
+		})
+	}
+	return res, nil
+}
+
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func bigString(i *big.Int) string {
+	if i == nil {
+		return "0"
+	}
+	return i.String()
+}
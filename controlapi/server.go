@@ -0,0 +1,140 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package controlapi serves the gRPC control API of the node, typed and streaming alternative to Tequilapi.
+package controlapi
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/mysteriumnetwork/node/pb/pbconnect"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// TLSConfig loads the certificate the control API is served with, nil config is returned when no files are given.
+func TLSConfig(certFile, keyFile string) (*tls.Config, error) {
+	if certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("both certificate and key files are required")
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{"h2", "http/1.1"},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// Listen listens on the address given either as tcp://host:port or unix:///path/to/socket.
+// TCP listener is wrapped with TLS when the config is given, otherwise only loopback addresses are allowed.
+// Unix socket is accessible by the node user only.
+func Listen(address string, tlsConfig *tls.Config) (net.Listener, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid control API address %q: %w", address, err)
+	}
+
+	switch u.Scheme {
+	case "tcp":
+		if tlsConfig == nil && !isLoopback(u.Hostname()) {
+			return nil, fmt.Errorf("control API without TLS must listen on a loopback address: %s", address)
+		}
+		listener, err := net.Listen("tcp", u.Host)
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig != nil {
+			return tls.NewListener(listener, tlsConfig), nil
+		}
+		return listener, nil
+	case "unix":
+		path := u.Host + u.Path
+		// Socket of the node which was not stopped gracefully would fail the listening.
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("could not remove stale socket %s: %w", path, err)
+		}
+		listener, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Chmod(path, 0600); err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("could not restrict socket %s permissions: %w", path, err)
+		}
+		return listener, nil
+	}
+	return nil, fmt.Errorf("unsupported control API address %q, expected tcp:// or unix:// scheme", address)
+}
+
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Server serves the control API. gRPC clients are served over HTTP/2, with or without TLS
+// depending on the listener, while Connect and gRPC-Web clients can use HTTP/1.1 too.
+type Server struct {
+	listener net.Listener
+	server   *http.Server
+}
+
+// NewServer creates the control API server, the calls are authorized by the interceptor.
+func NewServer(listener net.Listener, service pbconnect.ControlServiceHandler, interceptor connect.Interceptor) *Server {
+	mux := http.NewServeMux()
+	mux.Handle(pbconnect.NewControlServiceHandler(service, connect.WithInterceptors(interceptor)))
+	return &Server{
+		listener: listener,
+		server:   &http.Server{Handler: h2c.NewHandler(mux, &http2.Server{})},
+	}
+}
+
+// Serve serves the API until the server is stopped.
+func (s *Server) Serve() error {
+	log.Info().Msgf("Control API started on: %s", s.listener.Addr())
+	if err := s.server.Serve(s.listener); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Address returns the address the server listens on.
+func (s *Server) Address() net.Addr {
+	return s.listener.Addr()
+}
+
+// Stop closes the listener and the active connections, watching streams included.
+func (s *Server) Stop() error {
+	return s.server.Close()
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package controlapi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListen(t *testing.T) {
+	listener, err := Listen("tcp://127.0.0.1:0", nil)
+	require.NoError(t, err)
+	assert.IsType(t, &net.TCPListener{}, listener)
+	listener.Close()
+
+	// Socket left by the previous run is replaced.
+	socket := filepath.Join(t.TempDir(), "control.sock")
+	stale, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	listener, err = Listen("unix://"+socket, nil)
+	require.NoError(t, err)
+	assert.Equal(t, socket, listener.Addr().String())
+	info, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	listener.Close()
+
+	_, err = Listen("http://127.0.0.1:4450", nil)
+	assert.Error(t, err)
+}
+
+func TestListen_RequiresTLSOutsideLoopback(t *testing.T) {
+	_, err := Listen("tcp://0.0.0.0:0", nil)
+	assert.Error(t, err)
+	_, err = Listen("tcp://:0", nil)
+	assert.Error(t, err)
+
+	listener, err := Listen("tcp://localhost:0", nil)
+	require.NoError(t, err)
+	listener.Close()
+
+	tlsConfig, err := TLSConfig(writeTestCertificate(t))
+	require.NoError(t, err)
+	listener, err = Listen("tcp://0.0.0.0:0", tlsConfig)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+	conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	conn.Close()
+}
+
+func TestTLSConfig(t *testing.T) {
+	config, err := TLSConfig("", "")
+	assert.NoError(t, err)
+	assert.Nil(t, config)
+
+	cert, _ := writeTestCertificate(t)
+	_, err = TLSConfig(cert, "")
+	assert.Error(t, err)
+}
+
+func writeTestCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "node"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
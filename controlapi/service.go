@@ -0,0 +1,470 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package controlapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/consumer/session"
+	nodeConnection "github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/service"
+	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	identity_selector "github.com/mysteriumnetwork/node/identity/selector"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/pb/pbconnect"
+	"github.com/mysteriumnetwork/node/services"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/endpoints"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+	"github.com/rs/zerolog/log"
+)
+
+type identityRegistry interface {
+	GetRegistrationStatus(int64, identity.Identity) (registry.RegistrationStatus, error)
+}
+
+type stateProvider interface {
+	GetState() stateEvent.State
+}
+
+type sessionStorage interface {
+	List(*session.Filter) ([]session.History, error)
+}
+
+// Dependencies are the node components managed through the control API,
+// the same ones Tequilapi endpoints work with.
+type Dependencies struct {
+	IdentityManager    identity.Manager
+	IdentitySelector   identity_selector.Handler
+	IdentityRegistry   identityRegistry
+	ConnectionManager  nodeConnection.Manager
+	StateProvider      stateProvider
+	ProposalRepository proposal.Repository
+	ServiceManager     endpoints.ServiceManager
+	ServiceOptions     map[string]services.ServiceOptionsParser
+	SessionStorage     sessionStorage
+	SignerFactory      identity.SignerFactory
+	PayoutInfoRegistry endpoints.PayoutInfoRegistry
+}
+
+// Service implements the control API.
+type Service struct {
+	deps               Dependencies
+	stateWatchers      *watchers
+	connectionWatchers *watchers
+}
+
+var _ pbconnect.ControlServiceHandler = &Service{}
+
+// NewService returns the control API service.
+func NewService(deps Dependencies) *Service {
+	return &Service{
+		deps:               deps,
+		stateWatchers:      newWatchers(),
+		connectionWatchers: newWatchers(),
+	}
+}
+
+// Subscribe subscribes to the events streamed to the watching clients.
+func (s *Service) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.Subscribe(stateEvent.AppTopicState, s.consumeStateEvent); err != nil {
+		return err
+	}
+	return bus.Subscribe(connectionstate.AppTopicConnectionState, s.consumeConnectionStateEvent)
+}
+
+func (s *Service) consumeStateEvent(state stateEvent.State) {
+	s.stateWatchers.publish(state)
+}
+
+func (s *Service) consumeConnectionStateEvent(e connectionstate.AppEventConnectionState) {
+	status := e.SessionInfo
+	status.State = e.State
+	s.connectionWatchers.publish(status)
+}
+
+// ListIdentities lists identities stored in the keystore.
+func (s *Service) ListIdentities(context.Context, *connect.Request[pb.ListIdentitiesRequest]) (*connect.Response[pb.ListIdentitiesResponse], error) {
+	res := &pb.ListIdentitiesResponse{}
+	for _, id := range s.deps.IdentityManager.GetIdentities() {
+		res.Identities = append(res.Identities, &pb.Identity{Address: id.Address})
+	}
+	return connect.NewResponse(res), nil
+}
+
+// CurrentIdentity selects the identity used by the node, creating a new one if there are none.
+func (s *Service) CurrentIdentity(_ context.Context, req *connect.Request[pb.CurrentIdentityRequest]) (*connect.Response[pb.Identity], error) {
+	id, err := s.deps.IdentitySelector.UseOrCreate(req.Msg.Address, req.Msg.Passphrase, config.GetInt64(config.FlagChainID))
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	return connect.NewResponse(&pb.Identity{Address: id.Address}), nil
+}
+
+// UnlockIdentity unlocks the identity with the given passphrase.
+func (s *Service) UnlockIdentity(_ context.Context, req *connect.Request[pb.UnlockIdentityRequest]) (*connect.Response[pb.UnlockIdentityResponse], error) {
+	id, err := s.deps.IdentityManager.GetIdentity(req.Msg.Address)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	}
+	if err := s.deps.IdentityManager.Unlock(config.GetInt64(config.FlagChainID), id.Address, req.Msg.Passphrase); err != nil {
+		return nil, connect.NewError(connect.CodePermissionDenied, err)
+	}
+	return connect.NewResponse(&pb.UnlockIdentityResponse{}), nil
+}
+
+// Connect creates a consumer connection to the provider.
+func (s *Service) Connect(_ context.Context, req *connect.Request[pb.ConnectRequest]) (*connect.Response[pb.ConnectionStatus], error) {
+	cr := toConnectionCreateRequest(req.Msg)
+	if errs := cr.Validate(); errs.HasErrors() {
+		return nil, validationError(errs)
+	}
+
+	consumerID := identity.FromAddress(cr.ConsumerID)
+	status, err := s.deps.IdentityRegistry.GetRegistrationStatus(config.GetInt64(config.FlagChainID), consumerID)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("could not check registration status: %w", err))
+	}
+	if status == registry.Unregistered || status == registry.RegistrationError {
+		return nil, connect.NewError(connect.CodeFailedPrecondition, fmt.Errorf("identity %q is not registered. Please register the identity first", cr.ConsumerID))
+	}
+
+	exitProposal, err := s.proposal(cr.ProviderID, cr.ServiceType)
+	if err != nil {
+		return nil, err
+	}
+	params := cr.ConnectParams()
+	if cr.EntryProviderID != "" {
+		if params.EntryProposal, err = s.proposal(cr.EntryProviderID, cr.ServiceType); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.deps.ConnectionManager.Connect(consumerID, common.HexToAddress(cr.HermesID), *exitProposal, params)
+	switch {
+	case err == nil:
+	case err == nodeConnection.ErrAlreadyExists:
+		return nil, connect.NewError(connect.CodeAlreadyExists, err)
+	case err == nodeConnection.ErrConnectionCancelled:
+		return nil, connect.NewError(connect.CodeCanceled, err)
+	case errors.Is(err, nodeConnection.ErrUnsupportedMultiHop), err == nodeConnection.ErrMultiHopIncludeRoutes,
+		errors.Is(err, nodeConnection.ErrUnsupportedProxy), err == nodeConnection.ErrProxyUnsupportedParams,
+		err == nodeConnection.ErrApplicationSplitTunnel:
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	default:
+		log.Error().Err(err).Msg("Control API connect failed")
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	return connect.NewResponse(toConnectionStatus(s.deps.ConnectionManager.Status())), nil
+}
+
+func (s *Service) proposal(providerID, serviceType string) (*market.ServiceProposal, error) {
+	p, err := s.deps.ProposalRepository.Proposal(market.ProposalID{
+		ProviderID:  providerID,
+		ServiceType: serviceType,
+	})
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	if p == nil {
+		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("provider %s has no %s service proposals", providerID, serviceType))
+	}
+	return p, nil
+}
+
+// Disconnect closes the consumer connection.
+func (s *Service) Disconnect(context.Context, *connect.Request[pb.DisconnectRequest]) (*connect.Response[pb.DisconnectResponse], error) {
+	if err := s.deps.ConnectionManager.Disconnect(); err == nodeConnection.ErrNoConnection {
+		return nil, connect.NewError(connect.CodeFailedPrecondition, err)
+	} else if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	return connect.NewResponse(&pb.DisconnectResponse{}), nil
+}
+
+// GetConnection returns the status of the consumer connection.
+func (s *Service) GetConnection(context.Context, *connect.Request[pb.GetConnectionRequest]) (*connect.Response[pb.ConnectionStatus], error) {
+	return connect.NewResponse(toConnectionStatus(s.deps.ConnectionManager.Status())), nil
+}
+
+// WatchConnection streams the status of the consumer connection, starting with the current one.
+func (s *Service) WatchConnection(ctx context.Context, _ *connect.Request[pb.WatchConnectionRequest], stream *connect.ServerStream[pb.ConnectionStatus]) error {
+	updates := s.connectionWatchers.add()
+	defer s.connectionWatchers.remove(updates)
+
+	status := s.deps.ConnectionManager.Status()
+	for {
+		if err := stream.Send(toConnectionStatus(status)); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case update := <-updates:
+			status = update.(connectionstate.Status)
+		}
+	}
+}
+
+// ListServices lists services running on the node.
+func (s *Service) ListServices(context.Context, *connect.Request[pb.ListServicesRequest]) (*connect.Response[pb.ListServicesResponse], error) {
+	res := &pb.ListServicesResponse{}
+	for id, instance := range s.deps.ServiceManager.List() {
+		srv, err := toService(id, instance)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInternal, err)
+		}
+		res.Services = append(res.Services, srv)
+	}
+	return connect.NewResponse(res), nil
+}
+
+// StartService starts the provider service.
+func (s *Service) StartService(_ context.Context, req *connect.Request[pb.StartServiceRequest]) (*connect.Response[pb.Service], error) {
+	errs := validation.NewErrorMap()
+	if req.Msg.ProviderID == "" {
+		errs.ForField("providerID").Required()
+	}
+	parseOptions, ok := s.deps.ServiceOptions[req.Msg.Type]
+	if !ok {
+		errs.ForField("type").Invalid("Invalid service type")
+		return nil, validationError(errs)
+	}
+	var rawOptions *json.RawMessage
+	if len(req.Msg.Options) > 0 {
+		raw := json.RawMessage(req.Msg.Options)
+		rawOptions = &raw
+	}
+	options, err := parseOptions(rawOptions)
+	if err != nil {
+		errs.ForField("options").Invalid(err.Error())
+	}
+
+	startOptions, _ := services.GetStartOptions(req.Msg.Type)
+	priceGB := parsePrice(errs, "priceGB", req.Msg.PriceGB, startOptions.PaymentPricePerGB)
+	priceMinute := parsePrice(errs, "priceMinute", req.Msg.PriceMinute, startOptions.PaymentPricePerMinute)
+	if errs.HasErrors() {
+		return nil, validationError(errs)
+	}
+	policies := req.Msg.AccessPolicies
+	if len(policies) == 0 {
+		policies = startOptions.AccessPolicyList
+	}
+
+	for _, instance := range s.deps.ServiceManager.List() {
+		if instance.ProviderID.Address == req.Msg.ProviderID && instance.Type == req.Msg.Type {
+			return nil, connect.NewError(connect.CodeAlreadyExists, errors.New("service already running"))
+		}
+	}
+
+	id, err := s.deps.ServiceManager.Start(
+		identity.FromAddress(req.Msg.ProviderID),
+		req.Msg.Type,
+		policies,
+		options,
+		service.Price{PerGB: priceGB, PerMinute: priceMinute},
+	)
+	if err == service.ErrorLocation {
+		return nil, connect.NewError(connect.CodeFailedPrecondition, err)
+	} else if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	instance := s.deps.ServiceManager.Service(id)
+	if instance == nil {
+		return nil, connect.NewError(connect.CodeInternal, errors.New("service stopped right after the start"))
+	}
+	res, err := toService(id, instance)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+// StopService stops the provider service.
+func (s *Service) StopService(_ context.Context, req *connect.Request[pb.StopServiceRequest]) (*connect.Response[pb.StopServiceResponse], error) {
+	id := service.ID(req.Msg.Id)
+	if s.deps.ServiceManager.Service(id) == nil {
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("service not found"))
+	}
+	if err := s.deps.ServiceManager.Stop(id); err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	return connect.NewResponse(&pb.StopServiceResponse{}), nil
+}
+
+// ListSessions lists the history of consumer and provider sessions.
+func (s *Service) ListSessions(_ context.Context, req *connect.Request[pb.ListSessionsRequest]) (*connect.Response[pb.ListSessionsResponse], error) {
+	filter := session.NewFilter()
+	if req.Msg.Direction != "" {
+		filter.SetDirection(req.Msg.Direction)
+	}
+	if req.Msg.ServiceType != "" {
+		filter.SetServiceType(req.Msg.ServiceType)
+	}
+	if req.Msg.Status != "" {
+		filter.SetStatus(req.Msg.Status)
+	}
+	if req.Msg.StartedFrom != nil {
+		filter.SetStartedFrom(req.Msg.StartedFrom.AsTime())
+	}
+	if req.Msg.StartedTo != nil {
+		filter.SetStartedTo(req.Msg.StartedTo.AsTime())
+	}
+
+	sessions, err := s.deps.SessionStorage.List(filter)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	res := &pb.ListSessionsResponse{}
+	for _, se := range sessions {
+		res.Sessions = append(res.Sessions, toSession(se))
+	}
+	return connect.NewResponse(res), nil
+}
+
+// ListProposals lists proposals of the providers.
+func (s *Service) ListProposals(_ context.Context, req *connect.Request[pb.ListProposalsRequest]) (*connect.Response[pb.ListProposalsResponse], error) {
+	proposals, err := s.deps.ProposalRepository.Proposals(&proposal.Filter{
+		ProviderID:         req.Msg.ProviderID,
+		ServiceType:        req.Msg.ServiceType,
+		AccessPolicyID:     req.Msg.AccessPolicyID,
+		AccessPolicySource: req.Msg.AccessPolicySource,
+		MinBandwidthKbps:   req.Msg.MinBandwidthKbps,
+		IPv6:               req.Msg.Ipv6,
+		ExcludeUnsupported: true,
+		IncludeFailed:      req.Msg.IncludeFailed,
+	})
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	res := &pb.ListProposalsResponse{}
+	for _, p := range proposals {
+		res.Proposals = append(res.Proposals, toProposal(p))
+	}
+	return connect.NewResponse(res), nil
+}
+
+// GetPayoutInfo returns the payout info of the identity.
+func (s *Service) GetPayoutInfo(_ context.Context, req *connect.Request[pb.GetPayoutInfoRequest]) (*connect.Response[pb.PayoutInfo], error) {
+	id := identity.FromAddress(req.Msg.Identity)
+	info, err := s.deps.PayoutInfoRegistry.GetPayoutInfo(id, s.deps.SignerFactory(id))
+	if err != nil {
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	}
+	return connect.NewResponse(&pb.PayoutInfo{
+		EthAddress:   info.EthAddress,
+		ReferralCode: info.ReferralCode,
+		Email:        info.Email,
+	}), nil
+}
+
+// UpdatePayoutInfo sets the payout address of the identity.
+func (s *Service) UpdatePayoutInfo(_ context.Context, req *connect.Request[pb.UpdatePayoutInfoRequest]) (*connect.Response[pb.UpdatePayoutInfoResponse], error) {
+	if req.Msg.EthAddress == "" {
+		errs := validation.NewErrorMap()
+		errs.ForField("ethAddress").Required()
+		return nil, validationError(errs)
+	}
+	id := identity.FromAddress(req.Msg.Identity)
+	if err := s.deps.PayoutInfoRegistry.UpdatePayoutInfo(id, req.Msg.EthAddress, s.deps.SignerFactory(id)); err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	return connect.NewResponse(&pb.UpdatePayoutInfoResponse{}), nil
+}
+
+// WatchState streams the state of the node, starting with the current one.
+func (s *Service) WatchState(ctx context.Context, _ *connect.Request[pb.WatchStateRequest], stream *connect.ServerStream[pb.NodeState]) error {
+	updates := s.stateWatchers.add()
+	defer s.stateWatchers.remove(updates)
+
+	state := s.deps.StateProvider.GetState()
+	for {
+		res, err := toNodeState(state)
+		if err != nil {
+			return connect.NewError(connect.CodeInternal, err)
+		}
+		if err := stream.Send(res); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case update := <-updates:
+			state = update.(stateEvent.State)
+		}
+	}
+}
+
+func toConnectionCreateRequest(req *pb.ConnectRequest) contract.ConnectionCreateRequest {
+	cr := contract.ConnectionCreateRequest{
+		ConsumerID:      req.ConsumerID,
+		ProviderID:      req.ProviderID,
+		HermesID:        req.HermesID,
+		ServiceType:     req.ServiceType,
+		EntryProviderID: req.EntryProviderID,
+	}
+	if cr.HermesID == "" {
+		cr.HermesID = config.GetString(config.FlagHermesID)
+	}
+	if opts := req.Options; opts != nil {
+		cr.ConnectOptions = contract.ConnectOptions{
+			DisableKillSwitch: opts.DisableKillSwitch,
+			DNS:               nodeConnection.DNSOption(opts.Dns),
+			IncludeRoutes:     opts.IncludeRoutes,
+			ExcludeRoutes:     opts.ExcludeRoutes,
+			Failover:          opts.Failover,
+		}
+		if opts.Proxy != nil {
+			cr.ConnectOptions.Proxy = &contract.ProxyOptions{
+				SOCKS5Address: opts.Proxy.Socks5Address,
+				HTTPAddress:   opts.Proxy.HttpAddress,
+			}
+		}
+	}
+	return cr
+}
+
+func parsePrice(errs *validation.FieldErrorMap, field, value string, defaultPrice *big.Int) *big.Int {
+	if value == "" {
+		return defaultPrice
+	}
+	price, ok := new(big.Int).SetString(value, 10)
+	if !ok || price.Sign() < 0 {
+		errs.ForField(field).Invalid("Invalid price")
+	}
+	return price
+}
+
+func validationError(errs *validation.FieldErrorMap) error {
+	details, _ := json.Marshal(errs)
+	return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("request validation failed: %s", details))
+}
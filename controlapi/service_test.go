@@ -0,0 +1,272 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package controlapi
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/ethereum/go-ethereum/common"
+	nodeConnection "github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/pb/pbconnect"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+)
+
+type mockConnectionManager struct {
+	onConnectReturn   error
+	onStatusReturn    connectionstate.Status
+	requestedConsumer identity.Identity
+	requestedHermesID common.Address
+	requestedProposal market.ServiceProposal
+	requestedParams   nodeConnection.ConnectParams
+}
+
+func (cm *mockConnectionManager) Connect(consumerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal, params nodeConnection.ConnectParams) error {
+	cm.requestedConsumer = consumerID
+	cm.requestedHermesID = hermesID
+	cm.requestedProposal = proposal
+	cm.requestedParams = params
+	return cm.onConnectReturn
+}
+
+func (cm *mockConnectionManager) Status() connectionstate.Status {
+	return cm.onStatusReturn
+}
+
+func (cm *mockConnectionManager) Disconnect() error {
+	return nil
+}
+
+func (cm *mockConnectionManager) CheckChannel(context.Context) error {
+	return nil
+}
+
+func (cm *mockConnectionManager) Reconnect() {}
+
+func (cm *mockConnectionManager) Resume() error {
+	return nil
+}
+
+type mockProposalRepository struct {
+	proposals []market.ServiceProposal
+}
+
+func (m *mockProposalRepository) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
+	for _, p := range m.proposals {
+		if p.ProviderID == id.ProviderID && p.ServiceType == id.ServiceType {
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockProposalRepository) Proposals(*proposal.Filter) ([]market.ServiceProposal, error) {
+	return m.proposals, nil
+}
+
+type mockStateProvider struct {
+	state stateEvent.State
+}
+
+func (m *mockStateProvider) GetState() stateEvent.State {
+	return m.state
+}
+
+func startTestServer(t *testing.T, deps Dependencies, bus eventbus.Subscriber) string {
+	return startAuthorizedTestServer(t, deps, bus, &mockAuthorizer{})
+}
+
+func startAuthorizedTestServer(t *testing.T, deps Dependencies, bus eventbus.Subscriber, authorizer Authorizer) string {
+	service := NewService(deps)
+	require.NoError(t, service.Subscribe(bus))
+
+	listener, err := Listen("tcp://127.0.0.1:0", nil)
+	require.NoError(t, err)
+	server := NewServer(listener, service, NewAuthInterceptor(authorizer, false))
+	go server.Serve()
+	t.Cleanup(func() { server.Stop() })
+
+	return "http://" + server.Address().String()
+}
+
+func TestService_Connect(t *testing.T) {
+	manager := &mockConnectionManager{
+		onStatusReturn: connectionstate.Status{
+			State:      connectionstate.Connected,
+			ConsumerID: identity.FromAddress("0x1"),
+			SessionID:  session.ID("session-1"),
+			Proposal:   market.ServiceProposal{ProviderID: "0x2", ServiceType: "wireguard"},
+		},
+	}
+	url := startTestServer(t, Dependencies{
+		IdentityRegistry:   &registry.FakeRegistry{RegistrationStatus: registry.Registered},
+		ConnectionManager:  manager,
+		ProposalRepository: &mockProposalRepository{proposals: []market.ServiceProposal{{ProviderID: "0x2", ServiceType: "wireguard"}}},
+	}, eventbus.New())
+	client := pbconnect.NewControlServiceClient(http.DefaultClient, url)
+
+	res, err := client.Connect(context.Background(), connect.NewRequest(&pb.ConnectRequest{
+		ConsumerID:  "0x1",
+		ProviderID:  "0x2",
+		HermesID:    "0x3",
+		ServiceType: "wireguard",
+		Options: &pb.ConnectOptions{
+			Dns:   "provider",
+			Proxy: &pb.ProxyOptions{Socks5Address: "127.0.0.1:1080"},
+		},
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, identity.FromAddress("0x1"), manager.requestedConsumer)
+	assert.Equal(t, common.HexToAddress("0x3"), manager.requestedHermesID)
+	assert.Equal(t, "0x2", manager.requestedProposal.ProviderID)
+	assert.Equal(t, nodeConnection.DNSOption("provider"), manager.requestedParams.DNS)
+	assert.Equal(t, &nodeConnection.ProxyParams{SOCKS5Address: "127.0.0.1:1080"}, manager.requestedParams.Proxy)
+
+	assert.Equal(t, "Connected", res.Msg.Status)
+	assert.Equal(t, "session-1", res.Msg.SessionID)
+	assert.Equal(t, "0x2", res.Msg.Proposal.ProviderID)
+}
+
+func TestService_ConnectErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   registry.RegistrationStatus
+		req      *pb.ConnectRequest
+		connErr  error
+		wantCode connect.Code
+	}{
+		{
+			name:     "missing provider",
+			status:   registry.Registered,
+			req:      &pb.ConnectRequest{ConsumerID: "0x1", ServiceType: "wireguard"},
+			wantCode: connect.CodeInvalidArgument,
+		},
+		{
+			name:     "unregistered consumer",
+			status:   registry.Unregistered,
+			req:      &pb.ConnectRequest{ConsumerID: "0x1", ProviderID: "0x2", ServiceType: "wireguard"},
+			wantCode: connect.CodeFailedPrecondition,
+		},
+		{
+			name:     "unknown provider",
+			status:   registry.Registered,
+			req:      &pb.ConnectRequest{ConsumerID: "0x1", ProviderID: "0x4", ServiceType: "wireguard"},
+			wantCode: connect.CodeNotFound,
+		},
+		{
+			name:     "already connected",
+			status:   registry.Registered,
+			req:      &pb.ConnectRequest{ConsumerID: "0x1", ProviderID: "0x2", ServiceType: "wireguard"},
+			connErr:  nodeConnection.ErrAlreadyExists,
+			wantCode: connect.CodeAlreadyExists,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := startTestServer(t, Dependencies{
+				IdentityRegistry:   &registry.FakeRegistry{RegistrationStatus: tt.status},
+				ConnectionManager:  &mockConnectionManager{onConnectReturn: tt.connErr},
+				ProposalRepository: &mockProposalRepository{proposals: []market.ServiceProposal{{ProviderID: "0x2", ServiceType: "wireguard"}}},
+			}, eventbus.New())
+			client := pbconnect.NewControlServiceClient(http.DefaultClient, url)
+
+			_, err := client.Connect(context.Background(), connect.NewRequest(tt.req))
+			assert.Equal(t, tt.wantCode, connect.CodeOf(err))
+		})
+	}
+}
+
+func TestService_WatchConnection(t *testing.T) {
+	bus := eventbus.New()
+	url := startTestServer(t, Dependencies{
+		ConnectionManager: &mockConnectionManager{onStatusReturn: connectionstate.Status{State: connectionstate.NotConnected}},
+	}, bus)
+	client := pbconnect.NewControlServiceClient(http.DefaultClient, url)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.WatchConnection(ctx, connect.NewRequest(&pb.WatchConnectionRequest{}))
+	require.NoError(t, err)
+	// Watching streams never end, so the request is cancelled before closing the stream.
+	defer func() {
+		cancel()
+		stream.Close()
+	}()
+
+	require.True(t, stream.Receive())
+	assert.Equal(t, "NotConnected", stream.Msg().Status)
+
+	bus.Publish(connectionstate.AppTopicConnectionState, connectionstate.AppEventConnectionState{
+		State:       connectionstate.Connecting,
+		SessionInfo: connectionstate.Status{ConsumerID: identity.FromAddress("0x1")},
+	})
+	require.True(t, stream.Receive())
+	assert.Equal(t, "Connecting", stream.Msg().Status)
+	assert.Equal(t, "0x1", stream.Msg().ConsumerID)
+}
+
+func TestService_WatchStateOverGRPC(t *testing.T) {
+	bus := eventbus.New()
+	url := startTestServer(t, Dependencies{
+		StateProvider: &mockStateProvider{state: stateEvent.State{
+			NATStatus: contract.NATStatusDTO{Status: "not_finished"},
+		}},
+	}, bus)
+	// gRPC clients use HTTP/2 over plain TCP connections.
+	httpClient := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	client := pbconnect.NewControlServiceClient(httpClient, url, connect.WithGRPC())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.WatchState(ctx, connect.NewRequest(&pb.WatchStateRequest{}))
+	require.NoError(t, err)
+	// Watching streams never end, so the request is cancelled before closing the stream.
+	defer func() {
+		cancel()
+		stream.Close()
+	}()
+
+	require.True(t, stream.Receive())
+	assert.Equal(t, "not_finished", stream.Msg().NatStatus.Status)
+
+	bus.Publish(stateEvent.AppTopicState, stateEvent.State{
+		NATStatus: contract.NATStatusDTO{Status: "successful"},
+	})
+	require.True(t, stream.Receive())
+	assert.Equal(t, "successful", stream.Msg().NatStatus.Status)
+}
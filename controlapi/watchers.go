@@ -0,0 +1,57 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package controlapi
+
+import "sync"
+
+// watchers passes the published values to the streams watching them. Streams which can't keep up
+// skip the intermediate values, since only the latest one describes the current state.
+type watchers struct {
+	mu    sync.Mutex
+	chans map[chan interface{}]struct{}
+}
+
+func newWatchers() *watchers {
+	return &watchers{chans: make(map[chan interface{}]struct{})}
+}
+
+func (w *watchers) add() chan interface{} {
+	ch := make(chan interface{}, 1)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.chans[ch] = struct{}{}
+	return ch
+}
+
+func (w *watchers) remove(ch chan interface{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.chans, ch)
+}
+
+func (w *watchers) publish(value interface{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for ch := range w.chans {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- value
+	}
+}
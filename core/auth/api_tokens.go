@@ -0,0 +1,255 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+)
+
+// Scope grants access to a group of Tequilapi routes.
+type Scope string
+
+const (
+	// ScopeReadOnly grants reading the state of the node.
+	ScopeReadOnly Scope = "read-only"
+	// ScopeConnection grants connecting and disconnecting the consumer.
+	ScopeConnection Scope = "connection-control"
+	// ScopeService grants starting and stopping the provider services.
+	ScopeService Scope = "service-control"
+	// ScopePayments grants settling and withdrawing earnings, changing the payout details and topping up.
+	ScopePayments Scope = "payments"
+	// ScopeAdmin grants everything, stopping the node and managing the identities and tokens included.
+	ScopeAdmin Scope = "admin"
+)
+
+// Scopes lists all the known scopes.
+var Scopes = []Scope{ScopeReadOnly, ScopeConnection, ScopeService, ScopePayments, ScopeAdmin}
+
+// ParseScope parses the scope name.
+func ParseScope(name string) (Scope, error) {
+	for _, scope := range Scopes {
+		if string(scope) == name {
+			return scope, nil
+		}
+	}
+	return "", fmt.Errorf("unknown scope %q", name)
+}
+
+// Allows checks whether the granted scopes allow access to the routes of the required scope.
+// Every scope allows reading, admin scope allows everything.
+func Allows(granted []Scope, required Scope) bool {
+	for _, scope := range granted {
+		if scope == required || scope == ScopeAdmin || required == ScopeReadOnly {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	apiTokensBucket = "api-tokens"
+	apiTokenPrefix  = "myst_"
+)
+
+var (
+	// ErrAPITokenExists represents an error when creating a token with the name already taken.
+	ErrAPITokenExists = errors.New("API token with the same name already exists")
+	// ErrAPITokenNotFound represents an error when revoking a token which does not exist.
+	ErrAPITokenNotFound = errors.New("API token not found")
+)
+
+// APIToken is a named long-lived token granting the scopes to Tequilapi clients.
+type APIToken struct {
+	Name      string `storm:"id"`
+	Hash      string // SHA-256 of the token, the token itself is only shown when created.
+	Scopes    []Scope
+	CreatedAt time.Time
+}
+
+// IsAPIToken checks whether the token is an API token rather than a login one.
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, apiTokenPrefix)
+}
+
+type apiTokenStorage interface {
+	Store(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+	Delete(bucket string, data interface{}) error
+}
+
+// APITokens manages API tokens stored in the node database.
+type APITokens struct {
+	lock    sync.Mutex
+	storage apiTokenStorage
+}
+
+// NewAPITokens creates API tokens manager.
+func NewAPITokens(storage apiTokenStorage) *APITokens {
+	return &APITokens{storage: storage}
+}
+
+// Create creates the token with the given name and scopes, returning the token to be handed to the client.
+func (t *APITokens) Create(name string, scopes []Scope) (string, APIToken, error) {
+	if name == "" {
+		return "", APIToken{}, errors.New("API token name is required")
+	}
+	if len(scopes) == 0 {
+		return "", APIToken{}, errors.New("API token needs at least one scope")
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	tokens, err := t.list()
+	if err != nil {
+		return "", APIToken{}, err
+	}
+	for _, token := range tokens {
+		if token.Name == name {
+			return "", APIToken{}, ErrAPITokenExists
+		}
+	}
+
+	secret, err := generateRandomBytes(32)
+	if err != nil {
+		return "", APIToken{}, fmt.Errorf("failed to generate API token: %w", err)
+	}
+	token := apiTokenPrefix + hex.EncodeToString(secret)
+	stored := APIToken{
+		Name:      name,
+		Hash:      hashAPIToken(token),
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := t.storage.Store(apiTokensBucket, &stored); err != nil {
+		return "", APIToken{}, fmt.Errorf("failed to store API token: %w", err)
+	}
+	return token, stored, nil
+}
+
+// List lists the stored tokens.
+func (t *APITokens) List() ([]APIToken, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.list()
+}
+
+func (t *APITokens) list() ([]APIToken, error) {
+	var tokens []APIToken
+	err := t.storage.GetAllFrom(apiTokensBucket, &tokens)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get API tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// Revoke deletes the token with the given name.
+func (t *APITokens) Revoke(name string) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	tokens, err := t.list()
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if token.Name == name {
+			if err := t.storage.Delete(apiTokensBucket, &token); err != nil {
+				return fmt.Errorf("failed to delete API token: %w", err)
+			}
+			return nil
+		}
+	}
+	return ErrAPITokenNotFound
+}
+
+// Validate finds the stored token matching the given one.
+func (t *APITokens) Validate(token string) (APIToken, error) {
+	tokens, err := t.List()
+	if err != nil {
+		return APIToken{}, err
+	}
+
+	hash := hashAPIToken(token)
+	for _, stored := range tokens {
+		if subtle.ConstantTimeCompare([]byte(stored.Hash), []byte(hash)) == 1 {
+			return stored, nil
+		}
+	}
+	return APIToken{}, ErrUnauthorized
+}
+
+func hashAPIToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// Authorizer resolves the scopes granted to Tequilapi clients by their tokens.
+type Authorizer struct {
+	jwt    *JWTAuthenticator
+	tokens *APITokens
+}
+
+// NewAuthorizer creates the authorizer of login and API tokens.
+func NewAuthorizer(jwt *JWTAuthenticator, tokens *APITokens) *Authorizer {
+	return &Authorizer{jwt: jwt, tokens: tokens}
+}
+
+// Authorize returns the scopes granted by the token. Login tokens are issued
+// to the node owner, so they grant the admin scope.
+func (a *Authorizer) Authorize(token string) ([]Scope, error) {
+	if IsAPIToken(token) {
+		apiToken, err := a.tokens.Validate(token)
+		if err != nil {
+			return nil, err
+		}
+		return apiToken.Scopes, nil
+	}
+
+	if _, err := a.jwt.ValidateToken(token); err != nil {
+		return nil, ErrUnauthorized
+	}
+	return []Scope{ScopeAdmin}, nil
+}
+
+// TokensIssued tells whether any API token exists, the clients are expected to authorize once it does.
+func (a *Authorizer) TokensIssued() (bool, error) {
+	tokens, err := a.tokens.List()
+	if err != nil {
+		return false, err
+	}
+	return len(tokens) > 0, nil
+}
+
+// ValidateToken validates either login or API token.
+func (a *Authorizer) ValidateToken(token string) (bool, error) {
+	if _, err := a.Authorize(token); err != nil {
+		return false, err
+	}
+	return true, nil
+}
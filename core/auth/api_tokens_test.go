@@ -0,0 +1,110 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package auth
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPITokens(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apiTokensTest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	require.NoError(t, err)
+	defer bolt.Close()
+
+	tokens := NewAPITokens(bolt)
+	authorizer := NewAuthorizer(NewJWTAuthenticator([]byte("secret")), tokens)
+
+	list, err := tokens.List()
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+	issued, err := authorizer.TokensIssued()
+	assert.NoError(t, err)
+	assert.False(t, issued)
+
+	token, created, err := tokens.Create("dashboard", []Scope{ScopeReadOnly})
+	assert.NoError(t, err)
+	issued, err = authorizer.TokensIssued()
+	assert.NoError(t, err)
+	assert.True(t, issued)
+	assert.True(t, IsAPIToken(token))
+	assert.NotContains(t, created.Hash, token)
+
+	_, _, err = tokens.Create("dashboard", []Scope{ScopeAdmin})
+	assert.Equal(t, ErrAPITokenExists, err)
+
+	validated, err := tokens.Validate(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "dashboard", validated.Name)
+	assert.Equal(t, []Scope{ScopeReadOnly}, validated.Scopes)
+
+	_, err = tokens.Validate(token + "0")
+	assert.Equal(t, ErrUnauthorized, err)
+
+	list, err = tokens.List()
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	assert.NoError(t, tokens.Revoke("dashboard"))
+	assert.Equal(t, ErrAPITokenNotFound, tokens.Revoke("dashboard"))
+
+	_, err = tokens.Validate(token)
+	assert.Equal(t, ErrUnauthorized, err)
+}
+
+func TestAllows(t *testing.T) {
+	for _, tc := range []struct {
+		granted  []Scope
+		required Scope
+		allowed  bool
+	}{
+		{granted: []Scope{ScopeReadOnly}, required: ScopeReadOnly, allowed: true},
+		{granted: []Scope{ScopeReadOnly}, required: ScopePayments, allowed: false},
+		{granted: []Scope{ScopeConnection}, required: ScopeReadOnly, allowed: true},
+		{granted: []Scope{ScopeConnection}, required: ScopeService, allowed: false},
+		{granted: []Scope{ScopeConnection, ScopeService}, required: ScopeService, allowed: true},
+		{granted: []Scope{ScopeAdmin}, required: ScopePayments, allowed: true},
+		{granted: nil, required: ScopeReadOnly, allowed: false},
+	} {
+		assert.Equal(t, tc.allowed, Allows(tc.granted, tc.required), "%v requiring %v", tc.granted, tc.required)
+	}
+}
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	jwtAuth := NewJWTAuthenticator([]byte("secret"))
+	jwt, err := jwtAuth.CreateToken("myst")
+	require.NoError(t, err)
+
+	authorizer := NewAuthorizer(jwtAuth, NewAPITokens(nil))
+
+	scopes, err := authorizer.Authorize(jwt.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, []Scope{ScopeAdmin}, scopes)
+
+	_, err = authorizer.Authorize("invalid")
+	assert.Equal(t, ErrUnauthorized, err)
+}
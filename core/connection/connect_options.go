@@ -18,7 +18,10 @@
 package connection
 
 import (
+	"fmt"
+	"math/big"
 	"net"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 
@@ -33,6 +36,95 @@ type ConnectParams struct {
 	DisableKillSwitch bool
 	// DNS servers to use
 	DNS DNSOption
+	// EntryProposal makes a multi-hop connection, tunneling the connection to the exit provider through this one
+	EntryProposal *market.ServiceProposal
+	// IncludeRoutes limits the tunnel to the given CIDRs, IP addresses and domain names, all traffic goes through it when empty
+	IncludeRoutes []string
+	// ExcludeRoutes are CIDRs, IP addresses and domain names which bypass the tunnel
+	ExcludeRoutes []string
+	// IncludeApplications and ExcludeApplications split the traffic by application,
+	// which is not supported: connecting with them fails with ErrApplicationSplitTunnel
+	IncludeApplications []string
+	ExcludeApplications []string
+	// Failover enables reconnecting to an equivalent provider once the current one stops responding
+	Failover *FailoverPolicy
+	// Proxy terminates the tunnel inside the process and exposes it as local proxies instead of changing the system routes
+	Proxy *ProxyParams
+	// SpendingLimits tightens the configured spending limits for this connection
+	SpendingLimits *SpendingLimits
+	// DNSBlocking enables or disables blocking of the DNS names in the blocklists, the configured default is used when nil
+	DNSBlocking *bool
+}
+
+// SpendingLimits caps the spending of the consumer, the connection is disconnected once a cap would be exceeded.
+// Caps are in the smallest MYST units, nil cap is unlimited.
+type SpendingLimits struct {
+	// Session caps the spending of a single session
+	Session *big.Int
+	// Day caps the spending of the sessions started during the current UTC day
+	Day *big.Int
+	// Month caps the spending of the sessions started during the current UTC month
+	Month *big.Int
+	// PerProvider counts the daily and monthly spending of each provider separately
+	PerProvider bool
+}
+
+// Tighten returns the limits with the lower caps of both, counted per provider if either of them is.
+func (l SpendingLimits) Tighten(other SpendingLimits) SpendingLimits {
+	return SpendingLimits{
+		Session:     minCap(l.Session, other.Session),
+		Day:         minCap(l.Day, other.Day),
+		Month:       minCap(l.Month, other.Month),
+		PerProvider: l.PerProvider || other.PerProvider,
+	}
+}
+
+func minCap(a, b *big.Int) *big.Int {
+	if a == nil || (b != nil && b.Cmp(a) < 0) {
+		return b
+	}
+	return a
+}
+
+// ProxyParams holds listen addresses of the local proxies, a proxy is not started when its address is empty.
+type ProxyParams struct {
+	// SOCKS5Address is the listen address of the SOCKS5 proxy
+	SOCKS5Address string
+	// HTTPAddress is the listen address of the HTTP CONNECT proxy
+	HTTPAddress string
+}
+
+// ValidateProxyAddress checks that the local proxy listens on a loopback address.
+// Proxies do not authenticate their clients, so they would open the tunnel to anyone on the network otherwise.
+func ValidateProxyAddress(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(host, "localhost") {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("proxy must listen on a loopback address: %s", address)
+}
+
+// FailoverPolicy limits the proposals which can replace the one of a dead connection.
+// Replacements always match the service type and country of the original proposal.
+type FailoverPolicy struct {
+	// UpperTimePriceBound limits the price per minute, the price of the original proposal is used when nil
+	UpperTimePriceBound *big.Int
+	// UpperGBPriceBound limits the price per GiB, the price of the original proposal is used when nil
+	UpperGBPriceBound *big.Int
+}
+
+// hopProposals returns proposals of all connection hops, starting with the entry one.
+func (p ConnectParams) hopProposals(exit market.ServiceProposal) []market.ServiceProposal {
+	if p.EntryProposal == nil {
+		return []market.ServiceProposal{exit}
+	}
+	return []market.ServiceProposal{*p.EntryProposal, exit}
 }
 
 // ConnectOptions represents the params we need to ensure a successful connection
@@ -46,4 +138,8 @@ type ConnectOptions struct {
 	ProviderNATConn *net.UDPConn
 	ChannelConn     *net.UDPConn
 	HermesID        common.Address
+	// OuterIface is the tunnel interface of the previous multi-hop connection hop, which this connection is nested into
+	OuterIface string
+	// SplitTunnel holds networks resolved from the split tunneling routes of Params
+	SplitTunnel SplitTunnel
 }
@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpendingLimits_Tighten(t *testing.T) {
+	configured := SpendingLimits{Session: big.NewInt(10), Day: big.NewInt(100)}
+
+	assert.Equal(t, configured, configured.Tighten(SpendingLimits{}))
+	assert.Equal(t,
+		SpendingLimits{Session: big.NewInt(5), Day: big.NewInt(100), Month: big.NewInt(1000), PerProvider: true},
+		configured.Tighten(SpendingLimits{Session: big.NewInt(5), Day: big.NewInt(200), Month: big.NewInt(1000), PerProvider: true}),
+	)
+}
+
+func TestValidateProxyAddress(t *testing.T) {
+	for _, address := range []string{"127.0.0.1:1080", "127.0.0.2:0", "[::1]:1080", "localhost:8080"} {
+		assert.NoError(t, ValidateProxyAddress(address), address)
+	}
+	for _, address := range []string{"1080", ":1080", "0.0.0.0:1080", "[::]:1080", "192.168.1.2:1080", "example.com:1080"} {
+		assert.Error(t, ValidateProxyAddress(address), address)
+	}
+}
@@ -35,6 +35,10 @@ const (
 	AppTopicConnectionStatistics = "Statistics"
 	// AppTopicConnectionSession represents the session lifetime changes
 	AppTopicConnectionSession = "Session"
+	// AppTopicConnectionHopSession represents the session lifetime changes of the intermediate multi-hop connection hops
+	AppTopicConnectionHopSession = "HopSession"
+	// AppTopicConnectionHopStatistics represents the session stats topic of the intermediate multi-hop connection hops
+	AppTopicConnectionHopStatistics = "HopStatistics"
 )
 
 // AppEventConnectionState is the struct we'll emit on a AppEventConnectionState topic event
@@ -76,6 +80,28 @@ type Status struct {
 	State            State
 	SessionID        session.ID
 	Proposal         market.ServiceProposal
+	// Hops lists every provider session of a multi-hop connection, starting with the entry hop.
+	// It is empty for single hop connections.
+	Hops []HopStatus
+}
+
+// HopStatus holds the state of a single provider session in a multi-hop connection.
+type HopStatus struct {
+	SessionID  session.ID
+	Proposal   market.ServiceProposal
+	State      State
+	Statistics Statistics
+}
+
+// HopSessionInfo returns session info of the given multi-hop connection hop.
+func (s Status) HopSessionInfo(hop int) Status {
+	info := s
+	if hop >= 0 && hop < len(s.Hops) {
+		info.SessionID = s.Hops[hop].SessionID
+		info.Proposal = s.Hops[hop].Proposal
+	}
+	info.Hops = nil
+	return info
 }
 
 // Duration returns elapsed time from marked session start
@@ -22,6 +22,7 @@ import (
 	"net"
 	"strings"
 
+	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/utils/stringutil"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -46,8 +47,17 @@ func NewDNSOption(str string) (DNSOption, error) {
 	case DNSOptionAuto, DNSOptionProvider, DNSOptionSystem, "":
 		return opt, nil
 	}
-	// It may also be a set of IP addresses, e.g. 1.1.1.1,8.8.8.8
+	// It may also be a set of encrypted DNS upstreams, e.g. doh://1.1.1.1/dns-query,dot://8.8.8.8
 	split := strings.Split(str, ",")
+	if strings.Contains(str, "://") {
+		for _, s := range split {
+			if _, err := dns.ParseUpstream(s); err != nil {
+				return "", err
+			}
+		}
+		return opt, nil
+	}
+	// Or a set of IP addresses, e.g. 1.1.1.1,8.8.8.8
 	for _, s := range split {
 		if ip := net.ParseIP(s); ip == nil {
 			return "", errors.New("invalid IP address provided as a DNS option: " + s)
@@ -76,6 +86,17 @@ func (o DNSOption) Exact() (servers []string, ok bool) {
 	case DNSOptionAuto, DNSOptionProvider, DNSOptionSystem:
 		return nil, false
 	}
+	if _, ok := o.Encrypted(); ok {
+		return nil, false
+	}
+	return stringutil.Split(string(o), ','), true
+}
+
+// Encrypted returns a slice of DNS-over-HTTPS and DNS-over-TLS upstreams in the order of preference, if they were set
+func (o DNSOption) Encrypted() (upstreams []string, ok bool) {
+	if !strings.Contains(string(o), "://") {
+		return nil, false
+	}
 	return stringutil.Split(string(o), ','), true
 }
 
@@ -86,6 +107,9 @@ func (o *DNSOption) ResolveIPs(providerDNS string) ([]string, error) {
 	if exact, ok := o.Exact(); ok {
 		return exact, nil
 	}
+	if _, ok := o.Encrypted(); ok {
+		return nil, errors.New("encrypted DNS upstreams have no plaintext DNS servers")
+	}
 	switch *o {
 	case DNSOptionProvider:
 		return selectProviderDNS(providerDNS)
@@ -39,6 +39,11 @@ func TestNewDNSOption(t *testing.T) {
 		{input: "AA", expectErr: true},
 		{input: "512.512.512.512", expectErr: true},
 		{input: "1.1.1.1,512.512.512.512", expectErr: true},
+		{input: "doh://1.1.1.1/dns-query,dot://9.9.9.9", expect: DNSOption("doh://1.1.1.1/dns-query,dot://9.9.9.9")},
+		{input: "dot://[2620:fe::fe]:853", expect: DNSOption("dot://[2620:fe::fe]:853")},
+		{input: "doh://dns.google/dns-query", expectErr: true},
+		{input: "https://1.1.1.1/dns-query", expectErr: true},
+		{input: "dot://9.9.9.9,1.1.1.1", expectErr: true},
 	}
 	for i, tt := range tests {
 		option, err := NewDNSOption(tt.input)
@@ -86,6 +91,7 @@ func TestDNSOption_Exact(t *testing.T) {
 		{option: DNSOption("1.1.1.1,9.9.9.9"), expectServers: []string{"1.1.1.1", "9.9.9.9"}, expectOK: true},
 		{option: DNSOption("9.9.9.9"), expectServers: []string{"9.9.9.9"}, expectOK: true},
 		{option: DNSOption(""), expectServers: nil, expectOK: true},
+		{option: DNSOption("dot://9.9.9.9"), expectOK: false},
 	}
 	for _, tt := range tests {
 		servers, ok := tt.option.Exact()
@@ -93,3 +99,18 @@ func TestDNSOption_Exact(t *testing.T) {
 		assert.Equal(tt.expectServers, servers)
 	}
 }
+
+func TestDNSOption_Encrypted(t *testing.T) {
+	upstreams, ok := DNSOption("doh://1.1.1.1/dns-query,dot://9.9.9.9").Encrypted()
+	assert.True(t, ok)
+	assert.Equal(t, []string{"doh://1.1.1.1/dns-query", "dot://9.9.9.9"}, upstreams)
+
+	_, ok = DNSOption("1.1.1.1").Encrypted()
+	assert.False(t, ok)
+	_, ok = DNSOptionAuto.Encrypted()
+	assert.False(t, ok)
+
+	option := DNSOption("dot://9.9.9.9")
+	_, err := option.ResolveIPs("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,187 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// failover replaces the connection to a dead provider with the one to the best equivalent provider.
+// Kill switch stays up until the new connection is established.
+func (m *connectionManager) failover(options ConnectOptions, retrySameProvider bool) {
+	if !atomic.CompareAndSwapInt32(&m.failingOver, 0, 1) {
+		log.Debug().Msg("Failover is already in progress")
+		return
+	}
+	defer atomic.StoreInt32(&m.failingOver, 0)
+
+	removeTrafficBlock := m.holdTrafficBlock(options)
+	defer removeTrafficBlock()
+
+	if retrySameProvider {
+		err := m.reconnect(options, options.Proposal)
+		if err == nil || err == ErrConnectionCancelled {
+			return
+		}
+		log.Warn().Err(err).Msgf("Failed to reconnect to provider %s", options.Proposal.ProviderID)
+	}
+
+	candidates, err := m.failoverCandidates(options.Proposal, options.Params)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failover proposals lookup failed")
+	}
+	if len(candidates) > m.config.Failover.MaxAttempts {
+		candidates = candidates[:m.config.Failover.MaxAttempts]
+	}
+
+	for _, candidate := range candidates {
+		log.Info().Msgf("Failing over from provider %s to %s", options.Proposal.ProviderID, candidate.ProviderID)
+		err := m.reconnect(options, candidate)
+		if err == nil {
+			atomic.StoreInt32(&m.channelErrCount, 0)
+			return
+		}
+		if err == ErrConnectionCancelled {
+			return
+		}
+		log.Warn().Err(err).Msgf("Failed to fail over to provider %s", candidate.ProviderID)
+	}
+	log.Error().Msgf("No equivalent provider to fail over from %s, staying disconnected", options.Proposal.ProviderID)
+}
+
+// failoverCandidates returns proposals equivalent to the failed one, best quality first.
+func (m *connectionManager) failoverCandidates(failed market.ServiceProposal, params ConnectParams) ([]market.ServiceProposal, error) {
+	if m.proposalRepository == nil {
+		return nil, errors.New("proposal repository is not available")
+	}
+
+	filter := &proposal.Filter{
+		ServiceType:        failed.ServiceType,
+		ExcludeUnsupported: true,
+	}
+	if failed.ServiceDefinition != nil {
+		filter.LocationCountry = failed.ServiceDefinition.GetLocation().Country
+	}
+	filter.UpperTimePriceBound, filter.UpperGBPriceBound = proposalPrices(failed)
+	if params.Failover.UpperTimePriceBound != nil {
+		filter.UpperTimePriceBound = params.Failover.UpperTimePriceBound
+	}
+	if params.Failover.UpperGBPriceBound != nil {
+		filter.UpperGBPriceBound = params.Failover.UpperGBPriceBound
+	}
+	if filter.UpperTimePriceBound != nil {
+		filter.LowerTimePriceBound = big.NewInt(0)
+	}
+	if filter.UpperGBPriceBound != nil {
+		filter.LowerGBPriceBound = big.NewInt(0)
+	}
+
+	// Repository may return proposals of the available discovery sources along with the errors of the other ones.
+	proposals, err := m.proposalRepository.Proposals(filter)
+	if len(proposals) == 0 {
+		return nil, err
+	}
+
+	var candidates []market.ServiceProposal
+	for _, p := range proposals {
+		if p.ProviderID == failed.ProviderID {
+			continue
+		}
+		if params.EntryProposal != nil && p.ProviderID == params.EntryProposal.ProviderID {
+			continue
+		}
+		candidates = append(candidates, p)
+	}
+
+	scores := m.qualityScores(failed.ServiceType)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return scores[candidates[i].ProviderID] > scores[candidates[j].ProviderID]
+	})
+	return candidates, nil
+}
+
+// qualityScores returns the ratio of successful connections by provider.
+func (m *connectionManager) qualityScores(serviceType string) map[string]float64 {
+	scores := make(map[string]float64)
+	if m.qualityFinder == nil {
+		return scores
+	}
+
+	for _, metric := range m.qualityFinder.ProposalsMetrics() {
+		if metric.ProposalID.ServiceType != serviceType || metric.MonitoringFailed {
+			continue
+		}
+		count := metric.ConnectCount
+		total := count.Success + count.Fail + count.Timeout
+		if total > 0 {
+			scores[metric.ProposalID.ProviderID] = float64(count.Success) / float64(total)
+		}
+	}
+	return scores
+}
+
+// holdTrafficBlock keeps non tunnel traffic blocked while the connection is being replaced.
+func (m *connectionManager) holdTrafficBlock(options ConnectOptions) func() {
+	if options.Params.DisableKillSwitch {
+		return func() {}
+	}
+
+	outboundIPs, err := m.outboundIPs()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to keep traffic blocked during failover")
+		return func() {}
+	}
+	removeRule, err := blockNonTunnelTraffic(options.SplitTunnel, outboundIPs)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to keep traffic blocked during failover")
+		return func() {}
+	}
+	return removeRule
+}
+
+// proposalPrices returns the price per minute and per GiB of the proposal, the way discovery filters compare them.
+func proposalPrices(p market.ServiceProposal) (perMinute, perGiB *big.Int) {
+	if p.PaymentMethod == nil {
+		return nil, nil
+	}
+
+	price := new(big.Float).SetInt(p.PaymentMethod.GetPrice().Amount)
+	rate := p.PaymentMethod.GetRate()
+
+	perMinute = big.NewInt(0)
+	if rate.PerTime != 0 {
+		chunks := big.NewFloat(float64(time.Minute) / float64(rate.PerTime))
+		perMinute, _ = new(big.Float).Mul(chunks, price).Int(nil)
+	}
+	perGiB = big.NewInt(0)
+	if rate.PerByte != 0 {
+		chunks := big.NewFloat(float64(datasize.GiB.Bytes()) / float64(rate.PerByte))
+		perGiB, _ = new(big.Float).Mul(chunks, price).Int(nil)
+	}
+	return perMinute, perGiB
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/stretchr/testify/assert"
+)
+
+type countryServiceDefinition struct {
+	country string
+}
+
+func (d countryServiceDefinition) GetLocation() market.Location {
+	return market.Location{Country: d.country}
+}
+
+func TestFailoverCandidates(t *testing.T) {
+	failed := market.ServiceProposal{
+		ProviderID:        "0x1",
+		ServiceType:       "wireguard",
+		ServiceDefinition: countryServiceDefinition{country: "LT"},
+		PaymentMethod: &mockPaymentMethod{
+			price: money.NewMoney(big.NewInt(100), money.CurrencyMyst),
+			rate:  market.PaymentRate{PerTime: 30 * time.Second, PerByte: 0},
+		},
+	}
+	entry := market.ServiceProposal{ProviderID: "0x2", ServiceType: "wireguard"}
+	repository := &mockProposalRepository{
+		proposals: []market.ServiceProposal{
+			{ProviderID: "0x1"},
+			{ProviderID: "0x2"},
+			{ProviderID: "0x3"},
+			{ProviderID: "0x4"},
+			{ProviderID: "0x5"},
+		},
+	}
+	qualityFinder := &mockQualityFinder{
+		metrics: []quality.ConnectMetric{
+			{ProposalID: quality.ProposalID{ProviderID: "0x3", ServiceType: "wireguard"}, ConnectCount: quality.ConnectCount{Success: 1, Fail: 1}},
+			{ProposalID: quality.ProposalID{ProviderID: "0x4", ServiceType: "wireguard"}, ConnectCount: quality.ConnectCount{Success: 9, Timeout: 1}},
+			{ProposalID: quality.ProposalID{ProviderID: "0x5", ServiceType: "openvpn"}, ConnectCount: quality.ConnectCount{Success: 10}},
+		},
+	}
+	manager := &connectionManager{proposalRepository: repository, qualityFinder: qualityFinder}
+
+	candidates, err := manager.failoverCandidates(failed, ConnectParams{
+		EntryProposal: &entry,
+		Failover:      &FailoverPolicy{UpperGBPriceBound: big.NewInt(5)},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []market.ServiceProposal{{ProviderID: "0x4"}, {ProviderID: "0x3"}, {ProviderID: "0x5"}}, candidates)
+	assert.Equal(t, &proposal.Filter{
+		ServiceType:         "wireguard",
+		LocationCountry:     "LT",
+		LowerTimePriceBound: big.NewInt(0),
+		UpperTimePriceBound: big.NewInt(200),
+		LowerGBPriceBound:   big.NewInt(0),
+		UpperGBPriceBound:   big.NewInt(5),
+		ExcludeUnsupported:  true,
+	}, repository.filter)
+}
+
+type mockProposalRepository struct {
+	proposals []market.ServiceProposal
+	filter    *proposal.Filter
+	lock      sync.Mutex
+}
+
+func (m *mockProposalRepository) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
+	return nil, nil
+}
+
+func (m *mockProposalRepository) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.filter = filter
+	return m.proposals, nil
+}
+
+type mockQualityFinder struct {
+	metrics []quality.ConnectMetric
+}
+
+func (m *mockQualityFinder) ProposalsMetrics() []quality.ConnectMetric {
+	return m.metrics
+}
@@ -19,6 +19,7 @@ package connection
 
 import (
 	"context"
+	"net"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
@@ -39,6 +40,22 @@ type Connection interface {
 	Statistics() (connectionstate.Statistics, error)
 }
 
+// TunnelConnection represents a connection which routes traffic through its own tunnel interface.
+// Only tunnel connections can be chained into multi-hop connections.
+type TunnelConnection interface {
+	Connection
+	// InterfaceName returns the name of the tunnel interface, available once the connection is started
+	InterfaceName() string
+}
+
+// ProxyConnection represents a connection which can terminate its tunnel inside the process and expose it as local proxies.
+// Proxy connections neither need privileges nor change the system routes and DNS.
+type ProxyConnection interface {
+	Connection
+	// ProxyAddresses returns the addresses of the local proxies, available once the connection is started in proxy mode
+	ProxyAddresses() []net.Addr
+}
+
 // StateChannel is the channel we receive state change events on
 type StateChannel chan connectionstate.State
 
@@ -54,4 +71,6 @@ type Manager interface {
 	CheckChannel(context.Context) error
 	// Reconnect reconnects current session
 	Reconnect()
+	// Resume moves current session channels to the network consumer is currently on, keeping the sessions
+	Resume() error
 }
@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -30,9 +31,12 @@ import (
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/core/quality"
 
 	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
@@ -63,6 +67,16 @@ var (
 	ErrInsufficientBalance = errors.New("insufficient balance")
 	// ErrUnlockRequired indicates that the consumer identity has not been unlocked yet
 	ErrUnlockRequired = errors.New("unlock required")
+	// ErrUnsupportedMultiHop indicates that service type of the proposal can not be chained into multi-hop connection
+	ErrUnsupportedMultiHop = errors.New("service type does not support multi-hop connections")
+	// ErrMultiHopIncludeRoutes indicates that multi-hop connection can not be limited to the include routes
+	ErrMultiHopIncludeRoutes = errors.New("include routes are not supported by multi-hop connections")
+	// ErrUnsupportedProxy indicates that service type of the proposal can not be exposed as local proxies
+	ErrUnsupportedProxy = errors.New("service type does not support proxy mode")
+	// ErrProxyUnsupportedParams indicates that proxy mode can not be combined with multi-hop connection or split tunneling
+	ErrProxyUnsupportedParams = errors.New("multi-hop connections and split tunneling are not supported in proxy mode")
+	// ErrApplicationSplitTunnel indicates that traffic was requested to be split by application, it can only be split by destination
+	ErrApplicationSplitTunnel = errors.New("per application split tunneling is not supported, traffic can only be split by destination")
 )
 
 // IPCheckConfig contains common params for connection ip check.
@@ -76,12 +90,21 @@ type KeepAliveConfig struct {
 	SendInterval    time.Duration
 	SendTimeout     time.Duration
 	MaxSendErrCount int
+	// ResumeTimeout limits moving the channel to the new consumer network before giving up on it.
+	ResumeTimeout time.Duration
+}
+
+// FailoverConfig contains options of switching to another provider.
+type FailoverConfig struct {
+	MaxChannelErrCount int
+	MaxAttempts        int
 }
 
 // Config contains common configuration options for connection manager.
 type Config struct {
 	IPCheck   IPCheckConfig
 	KeepAlive KeepAliveConfig
+	Failover  FailoverConfig
 }
 
 // DefaultConfig returns default params.
@@ -95,6 +118,11 @@ func DefaultConfig() Config {
 			SendInterval:    20 * time.Second,
 			SendTimeout:     5 * time.Second,
 			MaxSendErrCount: 5,
+			ResumeTimeout:   30 * time.Second,
+		},
+		Failover: FailoverConfig{
+			MaxChannelErrCount: 3,
+			MaxAttempts:        5,
 		},
 	}
 }
@@ -113,11 +141,16 @@ type validator interface {
 	Validate(chainID int64, consumerID identity.Identity, proposal market.ServiceProposal) error
 }
 
+// QualityFinder provides proposal quality metrics
+type QualityFinder interface {
+	ProposalsMetrics() []quality.ConnectMetric
+}
+
 // TimeGetter function returns current time
 type TimeGetter func() time.Time
 
 // PaymentEngineFactory creates a new payment issuer from the given params
-type PaymentEngineFactory func(channel p2p.Channel, consumer, provider identity.Identity, hermes common.Address, proposal market.ServiceProposal) (PaymentIssuer, error)
+type PaymentEngineFactory func(channel p2p.Channel, consumer, provider identity.Identity, hermes common.Address, proposal market.ServiceProposal, limits *SpendingLimits) (PaymentIssuer, error)
 
 type connectionManager struct {
 	// These are passed on creation.
@@ -131,6 +164,9 @@ type connectionManager struct {
 	validator            validator
 	p2pDialer            p2p.Dialer
 	timeGetter           TimeGetter
+	hostResolver         HostResolver
+	proposalRepository   proposal.Repository
+	qualityFinder        QualityFinder
 
 	// These are populated by Connect at runtime.
 	ctx                    context.Context
@@ -144,10 +180,20 @@ type connectionManager struct {
 	cleanupFinishedLock    sync.Mutex
 	acknowledge            func()
 	cancel                 func()
-	channel                p2p.Channel
+	hops                   []hop
+	hopsLock               sync.RWMutex
 
 	discoLock      sync.Mutex
 	connectOptions ConnectOptions
+
+	channelErrCount int32
+	failingOver     int32
+}
+
+// hop holds the p2p channel and session of a single provider in the connection chain.
+type hop struct {
+	channel   p2p.Channel
+	sessionID session.ID
 }
 
 // NewManager creates connection manager with given dependencies
@@ -161,6 +207,8 @@ func NewManager(
 	statsReportInterval time.Duration,
 	validator validator,
 	p2pDialer p2p.Dialer,
+	proposalRepository proposal.Repository,
+	qualityFinder QualityFinder,
 ) *connectionManager {
 	return &connectionManager{
 		newConnection:        connectionCreator,
@@ -176,6 +224,9 @@ func NewManager(
 		validator:            validator,
 		p2pDialer:            p2pDialer,
 		timeGetter:           time.Now,
+		hostResolver:         dns.LookupIPv4,
+		proposalRepository:   proposalRepository,
+		qualityFinder:        qualityFinder,
 	}
 }
 
@@ -184,11 +235,9 @@ func (m *connectionManager) chainID() int64 {
 }
 
 func (m *connectionManager) Connect(consumerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal, params ConnectParams) (err error) {
-	var sessionID session.ID
-
 	tracer := trace.NewTracer("Consumer whole Connect")
 	defer func() {
-		traceResult := tracer.Finish(m.eventBus, string(sessionID))
+		traceResult := tracer.Finish(m.eventBus, string(m.Status().SessionID))
 		log.Debug().Msgf("Consumer connection trace: %s", traceResult)
 	}()
 
@@ -203,7 +252,25 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 		return ErrAlreadyExists
 	}
 
-	err = m.validator.Validate(m.chainID(), consumerID, proposal)
+	proposals := params.hopProposals(proposal)
+	for _, hopProposal := range proposals {
+		err = m.validator.Validate(m.chainID(), consumerID, hopProposal)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(params.IncludeApplications) > 0 || len(params.ExcludeApplications) > 0 {
+		return ErrApplicationSplitTunnel
+	}
+	if params.EntryProposal != nil && len(params.IncludeRoutes) > 0 {
+		return ErrMultiHopIncludeRoutes
+	}
+	if params.Proxy != nil && (params.EntryProposal != nil || len(params.IncludeRoutes) > 0 || len(params.ExcludeRoutes) > 0) {
+		return ErrProxyUnsupportedParams
+	}
+	// Domains are resolved before the tunnel is up, so that they point to the destinations reachable outside of it.
+	splitTunnel, err := params.splitTunnel(m.hostResolver)
 	if err != nil {
 		return err
 	}
@@ -212,7 +279,8 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 	m.ctx, m.cancel = context.WithCancel(context.Background())
 	m.ctxLock.Unlock()
 
-	m.statusConnecting(consumerID, hermesID, proposal)
+	m.setHops(nil)
+	m.statusConnecting(consumerID, hermesID, proposal, params.EntryProposal)
 	defer func() {
 		if err != nil {
 			log.Err(err).Msg("Connect failed, disconnecting")
@@ -220,67 +288,117 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 		}
 	}()
 
+	originalPublicIP := m.getPublicIP()
+
+	// Every next hop is nested into the tunnel of the previous one, so that only the exit provider sees the destinations.
+	var outerIface string
+	for i, hopProposal := range proposals {
+		hopTracer := tracer
+		if !isExitHop(m.Status(), i) {
+			// Tracer stages are unique, so intermediate hops are traced separately.
+			hopTracer = trace.NewTracer("Consumer hop Connect")
+		}
+
+		var connection Connection
+		connection, err = m.connectHop(i, consumerID, hermesID, hopProposal, params, splitTunnel, outerIface, hopTracer)
+		if hopTracer != tracer {
+			traceResult := hopTracer.Finish(m.eventBus, string(m.Status().HopSessionInfo(i).SessionID))
+			log.Debug().Msgf("Consumer hop connection trace: %s", traceResult)
+		}
+		if err != nil {
+			return err
+		}
+
+		if tunnel, ok := connection.(TunnelConnection); ok {
+			outerIface = tunnel.InterfaceName()
+		}
+	}
+
+	// Proxy mode leaves the system routes intact, so the public IP of the system is not expected to change.
+	if params.Proxy == nil {
+		for _, h := range m.currentHops() {
+			go m.checkSessionIP(h.channel, consumerID, h.sessionID, originalPublicIP)
+		}
+	}
+
+	return nil
+}
+
+// connectHop creates a session with the provider of the given connection hop and starts the connection to it.
+func (m *connectionManager) connectHop(hop int, consumerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal, params ConnectParams, splitTunnel SplitTunnel, outerIface string, tracer *trace.Tracer) (Connection, error) {
 	providerID := identity.FromAddress(proposal.ProviderID)
 
-	err = m.createP2PChannel(m.currentCtx(), consumerID, providerID, proposal, tracer)
+	channel, err := m.createP2PChannel(m.currentCtx(), consumerID, providerID, proposal, tracer)
 	if err != nil {
-		return fmt.Errorf("could not create p2p channel during connect: %w", err)
+		return nil, fmt.Errorf("could not create p2p channel during connect: %w", err)
 	}
 
 	connection, err := m.newConnection(proposal.ServiceType)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if _, ok := connection.(TunnelConnection); !ok && params.EntryProposal != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMultiHop, proposal.ServiceType)
+	}
+	if _, ok := connection.(ProxyConnection); !ok && params.Proxy != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedProxy, proposal.ServiceType)
 	}
 
-	paymentSession, err := m.paymentLoop(m.channel, consumerID, providerID, hermesID, proposal)
+	paymentSession, err := m.paymentLoop(channel, consumerID, providerID, hermesID, proposal, params.SpendingLimits)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	sessionDTO, err := m.createP2PSession(m.currentCtx(), connection, m.channel, consumerID, hermesID, proposal, tracer)
-	sessionID = session.ID(sessionDTO.GetID())
+	sessionDTO, err := m.createP2PSession(m.currentCtx(), connection, channel, consumerID, hermesID, proposal, tracer)
+	sessionID := session.ID(sessionDTO.GetID())
 	if err != nil {
-		m.sendSessionStatus(m.channel, consumerID, sessionID, connectivity.StatusSessionEstablishmentFailed, err)
-		return err
+		m.sendSessionStatus(channel, consumerID, sessionID, connectivity.StatusSessionEstablishmentFailed, err)
+		return nil, err
 	}
 
 	traceStart := tracer.StartStage("Consumer session creation (start)")
-	go m.keepAliveLoop(m.channel, sessionID)
-	m.setStatus(func(status *connectionstate.Status) {
-		status.SessionID = sessionID
-	})
-	m.publishSessionCreate(sessionID)
+	go m.keepAliveLoop(hop, channel, sessionID)
+	m.addHop(hop, channel, sessionID)
+	m.publishSessionCreate(hop)
 	paymentSession.SetSessionID(string(sessionID))
 	tracer.EndStage(traceStart)
 
 	// Try to establish connection with peer.
-	m.connectOptions = ConnectOptions{
+	connectOptions := ConnectOptions{
 		SessionID:       sessionID,
 		SessionConfig:   sessionDTO.GetConfig(),
 		Params:          params,
 		ConsumerID:      consumerID,
 		ProviderID:      providerID,
 		Proposal:        proposal,
-		ProviderNATConn: m.channel.ServiceConn(),
-		ChannelConn:     m.channel.Conn(),
+		ProviderNATConn: channel.ServiceConn(),
+		ChannelConn:     channel.Conn(),
 		HermesID:        hermesID,
+		OuterIface:      outerIface,
+		SplitTunnel:     splitTunnel,
 	}
-	err = m.startConnection(m.currentCtx(), connection, m.connectOptions, tracer)
+	if isExitHop(m.Status(), hop) {
+		m.connectOptions = connectOptions
+	}
+	err = m.startConnection(m.currentCtx(), hop, connection, connectOptions, tracer)
 	if err != nil {
 		if err == context.Canceled {
-			return ErrConnectionCancelled
+			return nil, ErrConnectionCancelled
 		}
 		m.addCleanupAfterDisconnect(func() error {
-			return m.sendSessionStatus(m.channel, consumerID, sessionID, connectivity.StatusConnectionFailed, err)
+			return m.sendSessionStatus(channel, consumerID, sessionID, connectivity.StatusConnectionFailed, err)
 		})
 		m.publishStateEvent(connectionstate.StateConnectionFailed)
 
 		log.Info().Err(err).Msg("Cancelling connection initiation: ")
 		m.Cancel()
-		return err
+		return nil, err
+	}
+	if proxy, ok := connection.(ProxyConnection); ok && params.Proxy != nil {
+		log.Info().Msgf("Tunnel is available through the local proxies: %v", proxy.ProxyAddresses())
 	}
 
-	return nil
+	return connection, nil
 }
 
 func (m *connectionManager) clearIPCache() {
@@ -350,8 +468,8 @@ func (m *connectionManager) getPublicIP() string {
 	return currentPublicIP
 }
 
-func (m *connectionManager) paymentLoop(channel p2p.Channel, consumerID, providerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal) (PaymentIssuer, error) {
-	payments, err := m.paymentEngineFactory(channel, consumerID, providerID, hermesID, proposal)
+func (m *connectionManager) paymentLoop(channel p2p.Channel, consumerID, providerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal, limits *SpendingLimits) (PaymentIssuer, error) {
+	payments, err := m.paymentEngineFactory(channel, consumerID, providerID, hermesID, proposal, limits)
 	if err != nil {
 		return nil, err
 	}
@@ -403,13 +521,13 @@ func (m *connectionManager) cleanAfterDisconnect() {
 	m.cleanupAfterDisconnect = nil
 }
 
-func (m *connectionManager) createP2PChannel(ctx context.Context, consumerID, providerID identity.Identity, proposal market.ServiceProposal, tracer *trace.Tracer) error {
+func (m *connectionManager) createP2PChannel(ctx context.Context, consumerID, providerID identity.Identity, proposal market.ServiceProposal, tracer *trace.Tracer) (p2p.Channel, error) {
 	trace := tracer.StartStage("Consumer P2P channel creation")
 	defer tracer.EndStage(trace)
 
 	contactDef, err := p2p.ParseContact(proposal.ProviderContacts)
 	if err != nil {
-		return fmt.Errorf("provider does not support p2p communication: %w", err)
+		return nil, fmt.Errorf("provider does not support p2p communication: %w", err)
 	}
 
 	timeoutCtx, cancel := context.WithTimeout(ctx, p2pDialTimeout)
@@ -418,7 +536,7 @@ func (m *connectionManager) createP2PChannel(ctx context.Context, consumerID, pr
 	// TODO register all handlers before channel read/write loops
 	channel, err := m.p2pDialer.Dial(timeoutCtx, consumerID, providerID, proposal.ServiceType, contactDef, tracer)
 	if err != nil {
-		return fmt.Errorf("p2p dialer failed: %w", err)
+		return nil, fmt.Errorf("p2p dialer failed: %w", err)
 	}
 	m.addCleanupAfterDisconnect(func() error {
 		log.Trace().Msg("Cleaning: closing P2P communication channel")
@@ -427,8 +545,7 @@ func (m *connectionManager) createP2PChannel(ctx context.Context, consumerID, pr
 		return channel.Close()
 	})
 
-	m.channel = channel
-	return nil
+	return channel, nil
 }
 
 func (m *connectionManager) addCleanupAfterDisconnect(fn func() error) {
@@ -520,29 +637,53 @@ func (m *connectionManager) createP2PSession(ctx context.Context, c Connection,
 	return &sessionResponse, nil
 }
 
-func (m *connectionManager) publishSessionCreate(sessionID session.ID) {
-	m.eventBus.Publish(connectionstate.AppTopicConnectionSession, connectionstate.AppEventConnectionSession{
+func (m *connectionManager) publishSessionCreate(hop int) {
+	// Sessions of the intermediate hops are published separately, so that subscribers
+	// tracking the connection keep seeing the exit session only.
+	topic := connectionstate.AppTopicConnectionSession
+	if !isExitHop(m.Status(), hop) {
+		topic = connectionstate.AppTopicConnectionHopSession
+	}
+
+	m.eventBus.Publish(topic, connectionstate.AppEventConnectionSession{
 		Status:      connectionstate.SessionCreatedStatus,
-		SessionInfo: m.Status(),
+		SessionInfo: m.hopSessionInfo(hop),
 	})
 
 	m.addCleanup(func() error {
 		log.Trace().Msg("Cleaning: publishing session ended status")
 		defer log.Trace().Msg("Cleaning: publishing session ended status DONE")
-		m.eventBus.Publish(connectionstate.AppTopicConnectionSession, connectionstate.AppEventConnectionSession{
+		m.eventBus.Publish(topic, connectionstate.AppEventConnectionSession{
 			Status:      connectionstate.SessionEndedStatus,
-			SessionInfo: m.Status(),
+			SessionInfo: m.hopSessionInfo(hop),
 		})
 		return nil
 	})
 }
 
-func (m *connectionManager) startConnection(ctx context.Context, conn Connection, connectOptions ConnectOptions, tracer *trace.Tracer) (err error) {
+// publishStatistics reports statistics of the given connection hop.
+func (m *connectionManager) publishStatistics(hop int, stats connectionstate.Statistics) {
+	m.setStatus(func(status *connectionstate.Status) {
+		if hop < len(status.Hops) {
+			status.Hops[hop].Statistics = stats
+		}
+	})
+
+	topic := connectionstate.AppTopicConnectionStatistics
+	if !isExitHop(m.Status(), hop) {
+		topic = connectionstate.AppTopicConnectionHopStatistics
+	}
+
+	m.eventBus.Publish(topic, connectionstate.AppEventConnectionStatistics{
+		Stats:       stats,
+		SessionInfo: m.hopSessionInfo(hop),
+	})
+}
+
+func (m *connectionManager) startConnection(ctx context.Context, hop int, conn Connection, connectOptions ConnectOptions, tracer *trace.Tracer) (err error) {
 	trace := tracer.StartStage("Consumer start connection")
 	defer tracer.EndStage(trace)
 
-	originalPublicIP := m.getPublicIP()
-
 	if err = conn.Start(ctx, connectOptions); err != nil {
 		return err
 	}
@@ -553,18 +694,21 @@ func (m *connectionManager) startConnection(ctx context.Context, conn Connection
 		return nil
 	})
 
-	err = m.setupTrafficBlock(connectOptions.Params.DisableKillSwitch)
-	if err != nil {
-		return err
+	// Traffic of the nested hops goes through the tunnel of the first one, which is already protected.
+	if hop == 0 {
+		err = m.setupTrafficBlock(connectOptions)
+		if err != nil {
+			return err
+		}
 	}
 
-	err = m.waitForConnectedState(conn.State())
+	err = m.waitForConnectedState(hop, conn.State())
 	if err != nil {
 		return err
 	}
 
-	statsPublisher := newStatsPublisher(m.eventBus, m.statsReportInterval)
-	go statsPublisher.start(m, conn)
+	statsPublisher := newStatsPublisher(m.statsReportInterval)
+	go statsPublisher.start(m, hop, conn)
 	m.addCleanup(func() error {
 		log.Trace().Msg("Cleaning: stopping statistics publisher")
 		defer log.Trace().Msg("Cleaning: stopping statistics publisher DONE")
@@ -572,14 +716,12 @@ func (m *connectionManager) startConnection(ctx context.Context, conn Connection
 		return nil
 	})
 
-	go m.consumeConnectionStates(conn.State())
-	go m.connectionWaiter(conn)
+	go m.consumeConnectionStates(ctx, hop, conn.State())
+	go m.connectionWaiter(ctx, conn)
 
 	// Clear IP cache so session IP check can report that IP has really changed.
 	m.clearIPCache()
 
-	go m.checkSessionIP(m.channel, connectOptions.ConsumerID, connectOptions.SessionID, originalPublicIP)
-
 	return nil
 }
 
@@ -587,7 +729,54 @@ func (m *connectionManager) Status() connectionstate.Status {
 	m.statusLock.RLock()
 	defer m.statusLock.RUnlock()
 
-	return m.status
+	status := m.status
+	if status.Hops != nil {
+		status.Hops = append([]connectionstate.HopStatus(nil), status.Hops...)
+	}
+	return status
+}
+
+// hopSessionInfo returns connection status describing the session of the given hop.
+func (m *connectionManager) hopSessionInfo(hop int) connectionstate.Status {
+	status := m.Status()
+	if isExitHop(status, hop) {
+		return status
+	}
+	return status.HopSessionInfo(hop)
+}
+
+// isExitHop checks if the given hop is the last one in the connection chain.
+func isExitHop(status connectionstate.Status, hop int) bool {
+	return len(status.Hops) == 0 || hop == len(status.Hops)-1
+}
+
+func (m *connectionManager) addHop(index int, channel p2p.Channel, sessionID session.ID) {
+	m.hopsLock.Lock()
+	m.hops = append(m.hops, hop{channel: channel, sessionID: sessionID})
+	m.hopsLock.Unlock()
+
+	m.setStatus(func(status *connectionstate.Status) {
+		if index < len(status.Hops) {
+			status.Hops[index].SessionID = sessionID
+		}
+		if isExitHop(*status, index) {
+			status.SessionID = sessionID
+		}
+	})
+}
+
+func (m *connectionManager) setHops(hops []hop) {
+	m.hopsLock.Lock()
+	defer m.hopsLock.Unlock()
+
+	m.hops = hops
+}
+
+func (m *connectionManager) currentHops() []hop {
+	m.hopsLock.RLock()
+	defer m.hopsLock.RUnlock()
+
+	return append([]hop(nil), m.hops...)
 }
 
 func (m *connectionManager) setStatus(delta func(status *connectionstate.Status)) {
@@ -605,7 +794,15 @@ func (m *connectionManager) setStatus(delta func(status *connectionstate.Status)
 	}
 }
 
-func (m *connectionManager) statusConnecting(consumerID identity.Identity, accountantID common.Address, proposal market.ServiceProposal) {
+func (m *connectionManager) statusConnecting(consumerID identity.Identity, accountantID common.Address, proposal market.ServiceProposal, entryProposal *market.ServiceProposal) {
+	var hops []connectionstate.HopStatus
+	if entryProposal != nil {
+		hops = []connectionstate.HopStatus{
+			{Proposal: *entryProposal, State: connectionstate.Connecting},
+			{Proposal: proposal, State: connectionstate.Connecting},
+		}
+	}
+
 	m.setStatus(func(status *connectionstate.Status) {
 		*status = connectionstate.Status{
 			StartedAt:        m.timeGetter(),
@@ -614,18 +811,31 @@ func (m *connectionManager) statusConnecting(consumerID identity.Identity, accou
 			HermesID:         accountantID,
 			Proposal:         proposal,
 			State:            connectionstate.Connecting,
+			Hops:             hops,
 		}
 	})
 }
 
-func (m *connectionManager) statusConnected() {
+func (m *connectionManager) statusConnected(hop int) {
 	m.setStatus(func(status *connectionstate.Status) {
+		if hop < len(status.Hops) {
+			status.Hops[hop].State = connectionstate.Connected
+		}
+		// Multi-hop connection is connected only when every hop is.
+		for _, h := range status.Hops {
+			if h.State != connectionstate.Connected {
+				return
+			}
+		}
 		status.State = connectionstate.Connected
 	})
 }
 
-func (m *connectionManager) statusReconnecting() {
+func (m *connectionManager) statusReconnecting(hop int) {
 	m.setStatus(func(status *connectionstate.Status) {
+		if hop < len(status.Hops) {
+			status.Hops[hop].State = connectionstate.Reconnecting
+		}
 		status.State = connectionstate.Reconnecting
 	})
 }
@@ -665,9 +875,13 @@ func (m *connectionManager) Disconnect() error {
 }
 
 func (m *connectionManager) CheckChannel(ctx context.Context) error {
-	if err := m.sendKeepAlivePing(ctx, m.channel, m.Status().SessionID); err != nil {
-		return fmt.Errorf("keep alive ping failed: %w", err)
+	for _, h := range m.currentHops() {
+		if err := m.sendKeepAlivePing(ctx, h.channel, h.sessionID); err != nil {
+			atomic.AddInt32(&m.channelErrCount, 1)
+			return fmt.Errorf("keep alive ping failed: %w", err)
+		}
 	}
+	atomic.StoreInt32(&m.channelErrCount, 0)
 	return nil
 }
 
@@ -690,7 +904,7 @@ func (m *connectionManager) disconnect() {
 	m.cleanAfterDisconnect()
 }
 
-func (m *connectionManager) connectionWaiter(connection Connection) {
+func (m *connectionManager) connectionWaiter(ctx context.Context, connection Connection) {
 	err := connection.Wait()
 	if err != nil {
 		log.Warn().Err(err).Msg("Connection exited with error")
@@ -698,10 +912,14 @@ func (m *connectionManager) connectionWaiter(connection Connection) {
 		log.Info().Msg("Connection exited")
 	}
 
+	// Connection stopped by disconnect must not tear down the one which might have replaced it.
+	if ctx.Err() != nil {
+		return
+	}
 	logDisconnectError(m.Disconnect())
 }
 
-func (m *connectionManager) waitForConnectedState(stateChannel <-chan connectionstate.State) error {
+func (m *connectionManager) waitForConnectedState(hop int, stateChannel <-chan connectionstate.State) error {
 	log.Debug().Msg("waiting for connected state")
 	for {
 		select {
@@ -716,10 +934,10 @@ func (m *connectionManager) waitForConnectedState(stateChannel <-chan connection
 				if m.acknowledge != nil {
 					go m.acknowledge()
 				}
-				m.onStateChanged(state)
+				m.onStateChanged(hop, state)
 				return nil
 			default:
-				m.onStateChanged(state)
+				m.onStateChanged(hop, state)
 			}
 		case <-m.currentCtx().Done():
 			return m.currentCtx().Err()
@@ -727,38 +945,61 @@ func (m *connectionManager) waitForConnectedState(stateChannel <-chan connection
 	}
 }
 
-func (m *connectionManager) consumeConnectionStates(stateChannel <-chan connectionstate.State) {
+func (m *connectionManager) consumeConnectionStates(ctx context.Context, hop int, stateChannel <-chan connectionstate.State) {
 	for state := range stateChannel {
-		m.onStateChanged(state)
+		m.onStateChanged(hop, state)
 	}
 
 	log.Debug().Msg("State updater stopCalled")
+	if ctx.Err() != nil {
+		return
+	}
 	logDisconnectError(m.Disconnect())
 }
 
-func (m *connectionManager) onStateChanged(state connectionstate.State) {
-	log.Debug().Msgf("Connection state received: %s", state)
+func (m *connectionManager) onStateChanged(hop int, state connectionstate.State) {
+	log.Debug().Msgf("Connection state received: %s (hop %d)", state, hop)
 
 	// React just to certain stains from connection. Because disconnect happens in connectionWaiter
 	switch state {
 	case connectionstate.Connected:
-		m.statusConnected()
+		m.statusConnected(hop)
 	case connectionstate.Reconnecting:
-		m.statusReconnecting()
+		m.statusReconnecting(hop)
 	}
 }
 
-func (m *connectionManager) setupTrafficBlock(disableKillSwitch bool) error {
-	if disableKillSwitch {
+// outboundIPs returns the outbound IPs of the consumer, IPv6 one is included only if the system has IPv6 connectivity.
+func (m *connectionManager) outboundIPs() ([]string, error) {
+	outboundIP, err := m.ipResolver.GetOutboundIP()
+	if err != nil {
+		return nil, err
+	}
+
+	outboundIPs := []string{outboundIP}
+	if outboundIPv6, err := m.ipResolver.GetOutboundIPv6(); err == nil {
+		outboundIPs = append(outboundIPs, outboundIPv6)
+	} else {
+		log.Debug().Err(err).Msg("No outbound IPv6 found")
+	}
+	return outboundIPs, nil
+}
+
+func (m *connectionManager) setupTrafficBlock(connectOptions ConnectOptions) error {
+	if connectOptions.Params.DisableKillSwitch {
+		return nil
+	}
+	if connectOptions.Params.Proxy != nil {
+		log.Info().Msg("Kill switch is not applied, only traffic of the local proxies goes through the tunnel")
 		return nil
 	}
 
-	outboundIP, err := m.ipResolver.GetOutboundIP()
+	outboundIPs, err := m.outboundIPs()
 	if err != nil {
 		return err
 	}
 
-	removeRule, err := firewall.BlockNonTunnelTraffic(firewall.Session, outboundIP)
+	removeRule, err := blockNonTunnelTraffic(connectOptions.SplitTunnel, outboundIPs)
 	if err != nil {
 		return err
 	}
@@ -768,9 +1009,36 @@ func (m *connectionManager) setupTrafficBlock(disableKillSwitch bool) error {
 		removeRule()
 		return nil
 	})
+
+	for _, network := range connectOptions.SplitTunnel.Exclude {
+		removeRule, err := firewall.AllowIPAccess(network.String())
+		if err != nil {
+			return err
+		}
+		m.addCleanup(func() error {
+			log.Trace().Msg("Cleaning: excluded route rule")
+			defer log.Trace().Msg("Cleaning: excluded route rule DONE")
+			removeRule()
+			return nil
+		})
+	}
 	return nil
 }
 
+// blockNonTunnelTraffic blocks all traffic outside of the tunnel, or only the traffic to the include networks
+// when the tunnel is limited to them, since the rest of the traffic bypasses the tunnel anyway.
+func blockNonTunnelTraffic(splitTunnel SplitTunnel, outboundIPs []string) (firewall.OutgoingRuleRemove, error) {
+	if len(splitTunnel.Include) == 0 {
+		return firewall.BlockNonTunnelTraffic(firewall.Session, outboundIPs...)
+	}
+
+	networks := make([]string, len(splitTunnel.Include))
+	for i, network := range splitTunnel.Include {
+		networks[i] = network.String()
+	}
+	return firewall.BlockNonTunnelTrafficTo(firewall.Session, networks, outboundIPs...)
+}
+
 func (m *connectionManager) publishStateEvent(state connectionstate.State) {
 	m.eventBus.Publish(connectionstate.AppTopicConnectionState, connectionstate.AppEventConnectionState{
 		State:       state,
@@ -778,7 +1046,7 @@ func (m *connectionManager) publishStateEvent(state connectionstate.State) {
 	})
 }
 
-func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session.ID) {
+func (m *connectionManager) keepAliveLoop(hop int, channel p2p.Channel, sessionID session.ID) {
 	// TODO: Remove this check once all provider migrates to p2p.
 	if channel == nil {
 		return
@@ -807,6 +1075,26 @@ func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session
 			if err := m.sendKeepAlivePing(ctx, channel, sessionID); err != nil {
 				log.Err(err).Msgf("Failed to send p2p keepalive ping. SessionID=%s", sessionID)
 				errCount++
+				// Consumer may have moved to another network, so try to take the channel along before giving up on it.
+				if errCount == m.config.KeepAlive.MaxSendErrCount {
+					if err := m.resumeChannel(channel, sessionID); err != nil {
+						log.Warn().Err(err).Msgf("Could not resume p2p channel. SessionID=%s", sessionID)
+					} else {
+						log.Info().Msgf("P2P channel resumed. SessionID=%s", sessionID)
+						errCount = 0
+					}
+				}
+				m.eventBus.Publish(p2p.AppTopicKeepAliveFailed, p2p.AppEventKeepAliveFailed{
+					SessionID: string(sessionID),
+					Consumer:  true,
+					Fatal:     errCount == m.config.KeepAlive.MaxSendErrCount,
+				})
+				if errCount == m.config.KeepAlive.MaxSendErrCount && m.connectOptions.Params.Failover != nil && isExitHop(m.Status(), hop) {
+					log.Error().Msgf("Max p2p keepalive err count reached, failing over to another provider. SessionID=%s", sessionID)
+					go m.failover(m.connectOptions, false)
+					cancel()
+					return
+				}
 				if errCount == m.config.KeepAlive.MaxSendErrCount {
 					log.Error().Msgf("Max p2p keepalive err count reached, disconnecting. SessionID=%s", sessionID)
 					m.Disconnect()
@@ -837,19 +1125,74 @@ func (m *connectionManager) currentCtx() context.Context {
 }
 
 func (m *connectionManager) Reconnect() {
+	if err := m.Resume(); err != nil {
+		log.Warn().Err(err).Msg("Could not resume p2p channels, reconnecting")
+	} else {
+		log.Info().Msg("P2P channels resumed, no need to reconnect")
+		return
+	}
+
+	options := m.connectOptions
+	if options.Params.Failover != nil {
+		retrySameProvider := int(atomic.LoadInt32(&m.channelErrCount)) < m.config.Failover.MaxChannelErrCount
+		m.failover(options, retrySameProvider)
+		return
+	}
+
+	if err := m.reconnect(options, options.Proposal); err != nil {
+		log.Error().Err(err).Msgf("Failed to reconnect")
+	}
+}
+
+// Resume moves p2p channels of the current connection to the network consumer is currently on,
+// keeping the sessions and their payments running.
+func (m *connectionManager) Resume() error {
+	hops := m.currentHops()
+	if len(hops) == 0 {
+		return ErrNoConnection
+	}
+	for _, h := range hops {
+		if err := m.resumeChannel(h.channel, h.sessionID); err != nil {
+			return fmt.Errorf("could not resume channel of session %s: %w", h.sessionID, err)
+		}
+	}
+	atomic.StoreInt32(&m.channelErrCount, 0)
+	return nil
+}
+
+func (m *connectionManager) resumeChannel(channel p2p.Channel, sessionID session.ID) error {
+	resumable, ok := channel.(p2p.ResumableChannel)
+	if !ok {
+		return p2p.ErrResumeNotSupported
+	}
+
+	// Public IP has most likely changed together with the network.
+	m.clearIPCache()
+	ctx, cancel := context.WithTimeout(m.currentCtx(), m.config.KeepAlive.ResumeTimeout)
+	defer cancel()
+	if err := resumable.Resume(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), m.config.KeepAlive.SendTimeout)
+	defer cancel()
+	return m.sendKeepAlivePing(ctx, channel, sessionID)
+}
+
+// reconnect replaces the current connection with a new one to the given proposal, keeping the rest of connect options.
+func (m *connectionManager) reconnect(options ConnectOptions, proposal market.ServiceProposal) error {
 	err := m.Disconnect()
-	if err != nil {
+	if err != nil && err != ErrNoConnection {
 		log.Error().Err(err).Msgf("Failed to disconnect stale session")
 	}
 	log.Info().Msg("Waiting for previous session to cleanup")
 
 	m.cleanupFinishedLock.Lock()
-	defer m.cleanupFinishedLock.Unlock()
-	<-m.cleanupFinished
-	err = m.Connect(m.connectOptions.ConsumerID, m.connectOptions.HermesID, m.connectOptions.Proposal, m.connectOptions.Params)
-	if err != nil {
-		log.Error().Err(err).Msgf("Failed to reconnect")
-	}
+	cleanupFinished := m.cleanupFinished
+	m.cleanupFinishedLock.Unlock()
+	<-cleanupFinished
+
+	return m.Connect(options.ConsumerID, options.HermesID, proposal, options.Params)
 }
 
 func logDisconnectError(err error) {
@@ -30,6 +30,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/location/locationstate"
+	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/trace"
 
 	"github.com/stretchr/testify/assert"
@@ -63,6 +64,8 @@ type testContext struct {
 	config                Config
 	statsReportInterval   time.Duration
 	mockP2P               *mockP2PDialer
+	mockProposals         *mockProposalRepository
+	mockQuality           *mockQualityFinder
 	mockTime              time.Time
 	sync.RWMutex
 }
@@ -124,6 +127,10 @@ func (tc *testContext) SetupTest() {
 			SendInterval:    100 * time.Millisecond,
 			MaxSendErrCount: 5,
 		},
+		Failover: FailoverConfig{
+			MaxChannelErrCount: 3,
+			MaxAttempts:        5,
+		},
 	}
 	tc.fakeIPResolver = ip.NewResolverMock("ip")
 	tc.fakeLocationResolver = &mockLocationResolver{}
@@ -132,12 +139,14 @@ func (tc *testContext) SetupTest() {
 	brokerConn := nats.StartConnectionMock()
 	brokerConn.MockResponse("fake-node-1.p2p-config-exchange", []byte("123"))
 
-	tc.mockP2P = &mockP2PDialer{&mockP2PChannel{}}
+	tc.mockP2P = &mockP2PDialer{ch: &mockP2PChannel{}}
+	tc.mockProposals = &mockProposalRepository{}
+	tc.mockQuality = &mockQualityFinder{}
 	tc.mockTime = time.Date(2000, time.January, 0, 10, 12, 3, 0, time.UTC)
 
 	tc.connManager = NewManager(
 		func(channel p2p.Channel,
-			consumer, provider identity.Identity, hermes common.Address, proposal market.ServiceProposal) (PaymentIssuer, error) {
+			consumer, provider identity.Identity, hermes common.Address, proposal market.ServiceProposal, limits *SpendingLimits) (PaymentIssuer, error) {
 			tc.MockPaymentIssuer = &MockPaymentIssuer{
 				paymentDefinition: market.PaymentRate{},
 				stopChan:          make(chan struct{}),
@@ -152,6 +161,8 @@ func (tc *testContext) SetupTest() {
 		tc.statsReportInterval,
 		&mockValidator{},
 		tc.mockP2P,
+		tc.mockProposals,
+		tc.mockQuality,
 	)
 	tc.connManager.timeGetter = func() time.Time {
 		return tc.mockTime
@@ -606,6 +617,281 @@ func (tc *testContext) Test_ManagerNotifiesAboutSuccessfulConnection() {
 	)
 }
 
+func (tc *testContext) setupMultiHop() (entryProposal market.ServiceProposal, entrySessionID session.ID) {
+	entryProviderID := identity.FromAddress("fake-node-entry")
+	entryProposal = activeProposal
+	entryProposal.ProviderID = entryProviderID.Address
+	entrySessionID = session.ID("session-entry")
+
+	tc.mockP2P.providerChannels = map[identity.Identity]*mockP2PChannel{
+		entryProviderID: {sessionID: entrySessionID},
+	}
+	return entryProposal, entrySessionID
+}
+
+func (tc *testContext) Test_MultiHopConnectionReportsEveryHop() {
+	entryProposal, entrySessionID := tc.setupMultiHop()
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{EntryProposal: &entryProposal})
+	assert.NoError(tc.T(), err)
+
+	status := tc.connManager.Status()
+	assert.Equal(tc.T(), connectionstate.Connected, status.State)
+	assert.Equal(tc.T(), establishedSessionID, status.SessionID)
+	assert.Equal(tc.T(), activeProposal, status.Proposal)
+	if assert.Len(tc.T(), status.Hops, 2) {
+		assert.Equal(tc.T(), entrySessionID, status.Hops[0].SessionID)
+		assert.Equal(tc.T(), entryProposal, status.Hops[0].Proposal)
+		assert.Equal(tc.T(), connectionstate.Connected, status.Hops[0].State)
+		assert.Equal(tc.T(), establishedSessionID, status.Hops[1].SessionID)
+		assert.Equal(tc.T(), activeProposal, status.Hops[1].Proposal)
+		assert.Equal(tc.T(), connectionstate.Connected, status.Hops[1].State)
+	}
+
+	// Exit hop has to be nested into the tunnel of the entry one.
+	if assert.Len(tc.T(), tc.fakeConnectionFactory.created, 2) {
+		entry, exit := tc.fakeConnectionFactory.created[0], tc.fakeConnectionFactory.created[1]
+		assert.Equal(tc.T(), "", entry.startOptions.OuterIface)
+		assert.Equal(tc.T(), entrySessionID, entry.startOptions.SessionID)
+		assert.Equal(tc.T(), entry.InterfaceName(), exit.startOptions.OuterIface)
+		assert.Equal(tc.T(), establishedSessionID, exit.startOptions.SessionID)
+	}
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+	assert.Equal(tc.T(), connectionstate.NotConnected, tc.connManager.Status().State)
+}
+
+func (tc *testContext) Test_MultiHopConnectionPublishesHopEventsSeparately() {
+	entryProposal, entrySessionID := tc.setupMultiHop()
+	tc.stubPublisher.Clear()
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{EntryProposal: &entryProposal})
+	assert.NoError(tc.T(), err)
+
+	waitABit()
+
+	var hopSessionPublished, hopStatsPublished bool
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		switch v.Topic {
+		case connectionstate.AppTopicConnectionSession, connectionstate.AppTopicConnectionStatistics:
+			var info connectionstate.Status
+			if event, ok := v.Event.(connectionstate.AppEventConnectionSession); ok {
+				info = event.SessionInfo
+			} else {
+				info = v.Event.(connectionstate.AppEventConnectionStatistics).SessionInfo
+			}
+			assert.Equal(tc.T(), establishedSessionID, info.SessionID)
+			assert.Equal(tc.T(), activeProposal.ProviderID, info.Proposal.ProviderID)
+		case connectionstate.AppTopicConnectionHopSession:
+			event := v.Event.(connectionstate.AppEventConnectionSession)
+			assert.Equal(tc.T(), entrySessionID, event.SessionInfo.SessionID)
+			assert.Equal(tc.T(), entryProposal.ProviderID, event.SessionInfo.Proposal.ProviderID)
+			assert.Empty(tc.T(), event.SessionInfo.Hops)
+			hopSessionPublished = true
+		case connectionstate.AppTopicConnectionHopStatistics:
+			event := v.Event.(connectionstate.AppEventConnectionStatistics)
+			assert.Equal(tc.T(), entrySessionID, event.SessionInfo.SessionID)
+			assert.Equal(tc.T(), tc.mockStatistics.BytesSent, event.Stats.BytesSent)
+			hopStatsPublished = true
+		}
+	}
+	assert.True(tc.T(), hopSessionPublished)
+	assert.True(tc.T(), hopStatsPublished)
+	assert.Equal(tc.T(), tc.mockStatistics.BytesReceived, tc.connManager.Status().Hops[0].Statistics.BytesReceived)
+}
+
+func (tc *testContext) Test_MultiHopConnectionIsReconnectingWhenEntryHopReconnects() {
+	entryProposal, _ := tc.setupMultiHop()
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{EntryProposal: &entryProposal})
+	assert.NoError(tc.T(), err)
+
+	entry := tc.fakeConnectionFactory.created[0]
+	entry.reportState(reconnectingState)
+	waitABit()
+	assert.Equal(tc.T(), connectionstate.Reconnecting, tc.connManager.Status().State)
+	assert.Equal(tc.T(), connectionstate.Reconnecting, tc.connManager.Status().Hops[0].State)
+
+	entry.reportState(connectedState)
+	waitABit()
+	assert.Equal(tc.T(), connectionstate.Connected, tc.connManager.Status().State)
+}
+
+func (tc *testContext) Test_MultiHopConnectionRequiresTunnelConnections() {
+	entryProposal, _ := tc.setupMultiHop()
+	tc.connManager.newConnection = func(serviceType string) (Connection, error) {
+		conn, err := tc.fakeConnectionFactory.CreateConnection(serviceType)
+		return struct{ Connection }{conn}, err
+	}
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{EntryProposal: &entryProposal})
+	assert.True(tc.T(), errors.Is(err, ErrUnsupportedMultiHop))
+	assert.Equal(tc.T(), connectionstate.NotConnected, tc.connManager.Status().State)
+}
+
+func (tc *testContext) Test_ConnectPassesResolvedSplitTunnelRoutes() {
+	tc.connManager.hostResolver = func(host string) ([]net.IP, error) {
+		assert.Equal(tc.T(), "intranet.example", host)
+		return []net.IP{net.ParseIP("10.8.0.1"), net.ParseIP("10.8.0.2")}, nil
+	}
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{
+		IncludeRoutes: []string{"8.8.8.0/24"},
+		ExcludeRoutes: []string{"intranet.example", "192.168.1.10"},
+	})
+	assert.NoError(tc.T(), err)
+
+	if assert.Len(tc.T(), tc.fakeConnectionFactory.created, 1) {
+		assert.Equal(
+			tc.T(),
+			SplitTunnel{
+				Include: []net.IPNet{{IP: net.IPv4(8, 8, 8, 0).To4(), Mask: net.CIDRMask(24, 32)}},
+				Exclude: []net.IPNet{
+					{IP: net.IPv4(10, 8, 0, 1).To4(), Mask: net.CIDRMask(32, 32)},
+					{IP: net.IPv4(10, 8, 0, 2).To4(), Mask: net.CIDRMask(32, 32)},
+					{IP: net.IPv4(192, 168, 1, 10).To4(), Mask: net.CIDRMask(32, 32)},
+				},
+			},
+			tc.fakeConnectionFactory.created[0].startOptions.SplitTunnel,
+		)
+	}
+}
+
+func (tc *testContext) Test_ConnectFailsWhenSplitTunnelRouteIsNotResolved() {
+	tc.connManager.hostResolver = func(host string) ([]net.IP, error) {
+		return nil, errors.New("NXDOMAIN")
+	}
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{ExcludeRoutes: []string{"intranet.example"}})
+	assert.EqualError(tc.T(), err, "could not resolve exclude routes: NXDOMAIN")
+	assert.Equal(tc.T(), connectionstate.NotConnected, tc.connManager.Status().State)
+}
+
+func (tc *testContext) Test_ConnectRejectsApplicationSplitTunnel() {
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{ExcludeApplications: []string{"/usr/bin/ssh"}})
+	assert.Equal(tc.T(), ErrApplicationSplitTunnel, err)
+	assert.Empty(tc.T(), tc.fakeConnectionFactory.created)
+}
+
+func (tc *testContext) Test_MultiHopConnectionRejectsIncludeRoutes() {
+	entryProposal, _ := tc.setupMultiHop()
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{
+		EntryProposal: &entryProposal,
+		IncludeRoutes: []string{"10.0.0.0/8"},
+	})
+	assert.Equal(tc.T(), ErrMultiHopIncludeRoutes, err)
+	assert.Empty(tc.T(), tc.fakeConnectionFactory.created)
+}
+
+func (tc *testContext) Test_ProxyConnectionDoesNotBlockTraffic() {
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{
+		Proxy: &ProxyParams{SOCKS5Address: "127.0.0.1:1081"},
+	})
+	assert.NoError(tc.T(), err)
+	assert.Equal(tc.T(), connectionstate.Connected, tc.connManager.Status().State)
+	assert.Equal(tc.T(), &ProxyParams{SOCKS5Address: "127.0.0.1:1081"}, tc.fakeConnectionFactory.created[0].startOptions.Params.Proxy)
+}
+
+func (tc *testContext) Test_ProxyConnectionRequiresProxyConnections() {
+	tc.connManager.newConnection = func(serviceType string) (Connection, error) {
+		conn, err := tc.fakeConnectionFactory.CreateConnection(serviceType)
+		return struct{ Connection }{conn}, err
+	}
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{Proxy: &ProxyParams{SOCKS5Address: "127.0.0.1:1081"}})
+	assert.True(tc.T(), errors.Is(err, ErrUnsupportedProxy))
+	assert.Equal(tc.T(), connectionstate.NotConnected, tc.connManager.Status().State)
+}
+
+func (tc *testContext) Test_ProxyConnectionRejectsSplitTunnel() {
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{
+		Proxy:         &ProxyParams{SOCKS5Address: "127.0.0.1:1081"},
+		ExcludeRoutes: []string{"10.0.0.0/8"},
+	})
+	assert.Equal(tc.T(), ErrProxyUnsupportedParams, err)
+	assert.Empty(tc.T(), tc.fakeConnectionFactory.created)
+}
+
+func (tc *testContext) Test_FailoverToEquivalentProviderWhenKeepAliveFails() {
+	replacementID := identity.FromAddress("fake-node-3")
+	tc.mockP2P.providerChannels = map[identity.Identity]*mockP2PChannel{
+		replacementID: {alive: true, sessionID: "session-300"},
+	}
+	tc.mockProposals.proposals = []market.ServiceProposal{
+		activeProposal,
+		{ProviderID: "fake-node-2", ProviderContacts: []market.Contact{activeProviderContact}, ServiceType: activeServiceType},
+		{ProviderID: replacementID.Address, ProviderContacts: []market.Contact{activeProviderContact}, ServiceType: activeServiceType},
+	}
+	tc.mockQuality.metrics = []quality.ConnectMetric{
+		{ProposalID: quality.ProposalID{ProviderID: "fake-node-2", ServiceType: activeServiceType}, ConnectCount: quality.ConnectCount{Success: 1, Fail: 9}},
+		{ProposalID: quality.ProposalID{ProviderID: replacementID.Address, ServiceType: activeServiceType}, ConnectCount: quality.ConnectCount{Success: 9, Fail: 1}},
+	}
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{Failover: &FailoverPolicy{}})
+	assert.NoError(tc.T(), err)
+
+	assert.Eventually(tc.T(), func() bool {
+		status := tc.connManager.Status()
+		return status.State == connectionstate.Connected && status.Proposal.ProviderID == replacementID.Address
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(tc.T(), session.ID("session-300"), tc.connManager.Status().SessionID)
+	assert.Equal(tc.T(), activeServiceType, tc.mockProposals.filter.ServiceType)
+}
+
+func (tc *testContext) Test_ReconnectFailsOverAfterRepeatedChannelErrors() {
+	replacementID := identity.FromAddress("fake-node-3")
+	tc.mockP2P.providerChannels = map[identity.Identity]*mockP2PChannel{
+		replacementID: {alive: true, sessionID: "session-300"},
+	}
+	tc.mockProposals.proposals = []market.ServiceProposal{
+		{ProviderID: replacementID.Address, ProviderContacts: []market.Contact{activeProviderContact}, ServiceType: activeServiceType},
+	}
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{Failover: &FailoverPolicy{}})
+	assert.NoError(tc.T(), err)
+
+	for i := 0; i < tc.config.Failover.MaxChannelErrCount; i++ {
+		assert.Error(tc.T(), tc.connManager.CheckChannel(context.Background()))
+	}
+	tc.connManager.Reconnect()
+
+	status := tc.connManager.Status()
+	assert.Equal(tc.T(), connectionstate.Connected, status.State)
+	assert.Equal(tc.T(), replacementID.Address, status.Proposal.ProviderID)
+}
+
+func (tc *testContext) Test_ReconnectKeepsProviderWithoutFailover() {
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	for i := 0; i < tc.config.Failover.MaxChannelErrCount; i++ {
+		assert.Error(tc.T(), tc.connManager.CheckChannel(context.Background()))
+	}
+	tc.connManager.Reconnect()
+
+	status := tc.connManager.Status()
+	assert.Equal(tc.T(), connectionstate.Connected, status.State)
+	assert.Equal(tc.T(), activeProposal.ProviderID, status.Proposal.ProviderID)
+	assert.Nil(tc.T(), tc.mockProposals.filter)
+}
+
+func (tc *testContext) Test_ReconnectResumesChannelOfMovedConsumer() {
+	tc.mockP2P.ch.resumable = true
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{Failover: &FailoverPolicy{}})
+	assert.NoError(tc.T(), err)
+
+	assert.Error(tc.T(), tc.connManager.CheckChannel(context.Background()))
+	tc.connManager.Reconnect()
+
+	status := tc.connManager.Status()
+	assert.Equal(tc.T(), connectionstate.Connected, status.State)
+	assert.Equal(tc.T(), establishedSessionID, status.SessionID)
+	assert.Len(tc.T(), tc.fakeConnectionFactory.created, 1)
+	assert.NoError(tc.T(), tc.connManager.CheckChannel(context.Background()))
+}
+
 func TestConnectionManagerSuite(t *testing.T) {
 	suite.Run(t, new(testContext))
 }
@@ -679,15 +965,25 @@ func (mpm *mockPaymentMethod) GetRate() market.PaymentRate {
 
 type mockP2PDialer struct {
 	ch *mockP2PChannel
+	// providerChannels overrides the channel to dial for the given providers.
+	providerChannels map[identity.Identity]*mockP2PChannel
 }
 
 func (m mockP2PDialer) Dial(ctx context.Context, consumerID identity.Identity, providerID identity.Identity, serviceType string, contactDef p2p.ContactDefinition, tracer *trace.Tracer) (p2p.Channel, error) {
+	if ch, ok := m.providerChannels[providerID]; ok {
+		return ch, nil
+	}
 	return m.ch, nil
 }
 
 type mockP2PChannel struct {
-	status proto.Message
-	lock   sync.Mutex
+	status    proto.Message
+	sessionID session.ID
+	// alive channel answers keep alive pings.
+	alive bool
+	// resumable channel comes alive after it is resumed.
+	resumable bool
+	lock      sync.Mutex
 }
 
 func (m *mockP2PChannel) Conn() *net.UDPConn {
@@ -703,8 +999,12 @@ func (m *mockP2PChannel) getSentMsg() proto.Message {
 func (m *mockP2PChannel) Send(_ context.Context, topic string, msg *p2p.Message) (*p2p.Message, error) {
 	switch topic {
 	case p2p.TopicSessionCreate:
+		sessionID := establishedSessionID
+		if m.sessionID != "" {
+			sessionID = m.sessionID
+		}
 		res := &pb.SessionResponse{
-			ID: string(establishedSessionID),
+			ID: string(sessionID),
 		}
 		return p2p.ProtoMessage(res), nil
 	case p2p.TopicSessionStatus:
@@ -716,6 +1016,10 @@ func (m *mockP2PChannel) Send(_ context.Context, topic string, msg *p2p.Message)
 		return nil, nil
 	case p2p.TopicSessionAcknowledge:
 		return nil, nil
+	case p2p.TopicKeepAlive:
+		if m.alive {
+			return nil, nil
+		}
 	}
 
 	return nil, errors.New("unexpected error")
@@ -738,6 +1042,14 @@ func (m *mockP2PChannel) Close() error {
 	return nil
 }
 
+func (m *mockP2PChannel) Resume(context.Context) error {
+	if !m.resumable {
+		return p2p.ErrResumeNotSupported
+	}
+	m.alive = true
+	return nil
+}
+
 type mockValidator struct {
 	errorToReturn error
 }
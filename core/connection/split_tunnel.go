@@ -0,0 +1,115 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+var domainNameRegex = regexp.MustCompile(`^([a-zA-Z0-9_]([a-zA-Z0-9\-_]*[a-zA-Z0-9_])?\.)*[a-zA-Z0-9_]([a-zA-Z0-9\-_]*[a-zA-Z0-9_])?\.?$`)
+
+// HostResolver resolves IPv4 addresses of the given domain name.
+type HostResolver func(host string) ([]net.IP, error)
+
+// SplitTunnel holds networks resolved from the split tunneling routes of ConnectParams.
+// Traffic is split by the destination only, connecting with application scope fails with ErrApplicationSplitTunnel.
+type SplitTunnel struct {
+	// Include networks are the only ones routed through the tunnel, all traffic is routed when empty.
+	Include []net.IPNet
+	// Exclude networks are routed outside of the tunnel.
+	Exclude []net.IPNet
+}
+
+// ValidateRoute checks that split tunneling route is an IPv4 CIDR, IPv4 address or a domain name.
+func ValidateRoute(route string) error {
+	if strings.Contains(route, "/") {
+		_, err := parseIPv4CIDR(route)
+		return err
+	}
+	if ip := net.ParseIP(route); ip != nil {
+		if ip.To4() == nil {
+			return fmt.Errorf("only IPv4 addresses are supported: %s", route)
+		}
+		return nil
+	}
+	if !domainNameRegex.MatchString(route) {
+		return fmt.Errorf("invalid route %q, expected CIDR, IP address or domain name", route)
+	}
+	return nil
+}
+
+// splitTunnel resolves split tunneling routes into networks.
+func (p ConnectParams) splitTunnel(resolve HostResolver) (SplitTunnel, error) {
+	include, err := resolveRoutes(p.IncludeRoutes, resolve)
+	if err != nil {
+		return SplitTunnel{}, fmt.Errorf("could not resolve include routes: %w", err)
+	}
+
+	exclude, err := resolveRoutes(p.ExcludeRoutes, resolve)
+	if err != nil {
+		return SplitTunnel{}, fmt.Errorf("could not resolve exclude routes: %w", err)
+	}
+
+	return SplitTunnel{Include: include, Exclude: exclude}, nil
+}
+
+func resolveRoutes(routes []string, resolve HostResolver) ([]net.IPNet, error) {
+	var networks []net.IPNet
+	for _, route := range routes {
+		if err := ValidateRoute(route); err != nil {
+			return nil, err
+		}
+
+		if strings.Contains(route, "/") {
+			network, err := parseIPv4CIDR(route)
+			if err != nil {
+				return nil, err
+			}
+			networks = append(networks, network)
+			continue
+		}
+
+		ips := []net.IP{net.ParseIP(route)}
+		if ips[0] == nil {
+			var err error
+			if ips, err = resolve(route); err != nil {
+				return nil, err
+			}
+		}
+		for _, ip := range ips {
+			if ip4 := ip.To4(); ip4 != nil {
+				networks = append(networks, net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)})
+			}
+		}
+	}
+	return networks, nil
+}
+
+func parseIPv4CIDR(route string) (net.IPNet, error) {
+	_, network, err := net.ParseCIDR(route)
+	if err != nil {
+		return net.IPNet{}, err
+	}
+	if network.IP.To4() == nil {
+		return net.IPNet{}, fmt.Errorf("only IPv4 networks are supported: %s", route)
+	}
+	return net.IPNet{IP: network.IP.To4(), Mask: network.Mask}, nil
+}
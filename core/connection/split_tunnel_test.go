@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRoute(t *testing.T) {
+	for route, valid := range map[string]bool{
+		"10.0.0.0/8":           true,
+		"192.168.1.10":         true,
+		"intranet":             true,
+		"git.corp.example":     true,
+		"git.corp.example.":    true,
+		"10.0.0.0/33":          false,
+		"fd00::/8":             false,
+		"::1":                  false,
+		"":                     false,
+		"corp example":         false,
+		"-corp.example":        false,
+		"https://corp.example": false,
+	} {
+		err := ValidateRoute(route)
+		assert.Equal(t, valid, err == nil, "route %q: %v", route, err)
+	}
+}
+
+func TestResolveRoutes(t *testing.T) {
+	resolve := func(host string) ([]net.IP, error) {
+		assert.Equal(t, "git.corp.example", host)
+		return []net.IP{net.ParseIP("10.1.0.5"), net.ParseIP("fd00::5")}, nil
+	}
+
+	networks, err := resolveRoutes([]string{"10.1.2.3/16", "172.16.0.1", "git.corp.example"}, resolve)
+	assert.NoError(t, err)
+	assert.Equal(t, []net.IPNet{
+		{IP: net.IPv4(10, 1, 0, 0).To4(), Mask: net.CIDRMask(16, 32)},
+		{IP: net.IPv4(172, 16, 0, 1).To4(), Mask: net.CIDRMask(32, 32)},
+		{IP: net.IPv4(10, 1, 0, 5).To4(), Mask: net.CIDRMask(32, 32)},
+	}, networks)
+
+	_, err = resolveRoutes([]string{"fd00::/8"}, resolve)
+	assert.EqualError(t, err, "only IPv4 networks are supported: fd00::/8")
+}
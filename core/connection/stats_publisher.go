@@ -21,7 +21,6 @@ import (
 	"time"
 
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
-	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/rs/zerolog/log"
 )
 
@@ -34,19 +33,17 @@ type statsSupplier interface {
 
 type statsPublisher struct {
 	done     chan struct{}
-	bus      eventbus.Publisher
 	interval time.Duration
 }
 
-func newStatsPublisher(bus eventbus.Publisher, interval time.Duration) statsPublisher {
+func newStatsPublisher(interval time.Duration) statsPublisher {
 	return statsPublisher{
 		done:     make(chan struct{}),
-		bus:      bus,
 		interval: interval,
 	}
 }
 
-func (s statsPublisher) start(sessionSupplier *connectionManager, statsSupplier statsSupplier) {
+func (s statsPublisher) start(sessionSupplier *connectionManager, hop int, statsSupplier statsSupplier) {
 	for {
 		select {
 		case <-time.After(s.interval):
@@ -55,10 +52,7 @@ func (s statsPublisher) start(sessionSupplier *connectionManager, statsSupplier
 				log.Warn().Err(err).Msg("Could not get connection statistics")
 				continue
 			}
-			s.bus.Publish(connectionstate.AppTopicConnectionStatistics, connectionstate.AppEventConnectionStatistics{
-				Stats:       stats,
-				SessionInfo: sessionSupplier.Status(),
-			})
+			sessionSupplier.publishStatistics(hop, stats)
 		case <-s.done:
 			log.Info().Msg("Stopped publishing connection statistics")
 			return
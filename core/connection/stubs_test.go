@@ -19,6 +19,8 @@ package connection
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"sync"
 
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
@@ -42,6 +44,7 @@ const (
 type connectionFactoryFake struct {
 	mockError      error
 	mockConnection *connectionMock
+	created        []*connectionMock
 }
 
 func (c *connectionFactoryFake) CreateConnection(serviceType string) (Connection, error) {
@@ -50,21 +53,23 @@ func (c *connectionFactoryFake) CreateConnection(serviceType string) (Connection
 		return nil, c.mockError
 	}
 
-	c.mockConnection.stateChannel = make(chan connectionstate.State, 100)
+	// every connection reports to its own channel, so that several connections can run at once
+	stateChannel := make(chan connectionstate.State, 100)
+	c.mockConnection.stateChannel = stateChannel
 
 	stateCallback := func(state fakeState) {
 		if state == connectedState {
-			c.mockConnection.stateChannel <- connectionstate.Connected
+			stateChannel <- connectionstate.Connected
 		}
 		if state == exitingState {
-			c.mockConnection.stateChannel <- connectionstate.Disconnecting
+			stateChannel <- connectionstate.Disconnecting
 		}
 		if state == reconnectingState {
-			c.mockConnection.stateChannel <- connectionstate.Reconnecting
+			stateChannel <- connectionstate.Reconnecting
 		}
 		//this is the last state - close channel (according to best practices of go - channel writer controls channel)
 		if state == processExited {
-			close(c.mockConnection.stateChannel)
+			close(stateChannel)
 		}
 	}
 	c.mockConnection.StateCallback(stateCallback)
@@ -79,7 +84,9 @@ func (c *connectionFactoryFake) CreateConnection(serviceType string) (Connection
 		onStartReportStats:  c.mockConnection.onStartReportStats,
 		fakeProcess:         sync.WaitGroup{},
 		stopBlock:           c.mockConnection.stopBlock,
+		ifaceName:           fmt.Sprintf("mock%d", len(c.created)),
 	}
+	c.created = append(c.created, &copy)
 
 	return &copy, nil
 }
@@ -93,6 +100,8 @@ type connectionMock struct {
 	onStartReportStats  connectionstate.Statistics
 	fakeProcess         sync.WaitGroup
 	stopBlock           chan struct{}
+	ifaceName           string
+	startOptions        ConnectOptions
 	sync.RWMutex
 }
 
@@ -115,6 +124,7 @@ func (foc *connectionMock) Start(ctx context.Context, connectionParams ConnectOp
 	if foc.onStartReturnError != nil {
 		return foc.onStartReturnError
 	}
+	foc.startOptions = connectionParams
 
 	foc.fakeProcess.Add(1)
 	for _, fakeState := range foc.onStartReportStates {
@@ -123,6 +133,14 @@ func (foc *connectionMock) Start(ctx context.Context, connectionParams ConnectOp
 	return nil
 }
 
+func (foc *connectionMock) InterfaceName() string {
+	return foc.ifaceName
+}
+
+func (foc *connectionMock) ProxyAddresses() []net.Addr {
+	return nil
+}
+
 func (foc *connectionMock) Wait() error {
 	foc.fakeProcess.Wait()
 	return nil
@@ -19,22 +19,45 @@ package dhtdiscovery
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/ipfs/go-cid"
 	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/libp2p/go-libp2p-core/host"
 	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	dhtopts "github.com/libp2p/go-libp2p-kad-dht/opts"
+	kbucket "github.com/libp2p/go-libp2p-kbucket"
 	"github.com/multiformats/go-multiaddr"
+	"github.com/multiformats/go-multihash"
 	"github.com/rs/zerolog/log"
 )
 
+// dhtProtocol separates Mysterium DHT from the other Kademlia networks (e.g. IPFS).
+const dhtProtocol = protocol.ID("/mysterium/kad/1.0.0")
+
+// proposalsRendezvous is a well-known key, which is provided by every node announcing proposals.
+const proposalsRendezvous = "mysterium/proposals"
+
+// errNoPeers is returned when there are no DHT peers to announce records to.
+var errNoPeers = errors.New("no DHT peers to announce to")
+
 // Node represents DHT server-client in P2P network.
 type Node struct {
 	libP2PConfig     libp2p.Config
 	libP2PNode       host.Host
 	libP2PNodeCtx    context.Context
 	libP2PNodeCancel context.CancelFunc
+	libP2PKey        crypto.PrivKey
 
+	dht            *dht.IpfsDHT
+	rendezvous     cid.Cid
 	bootstrapPeers []*peer.AddrInfo
 }
 
@@ -61,9 +84,21 @@ func NewNode(listenAddress string, bootstrapPeerAddresses []string) (*Node, erro
 		}
 	}
 
+	rendezvousHash, err := multihash.Sum([]byte(proposalsRendezvous), multihash.SHA2_256, -1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare DHT rendezvous key: %w", err)
+	}
+	node.rendezvous = cid.NewCidV1(cid.Raw, rendezvousHash)
+
+	// Ed25519 keys are inlined into peer IDs, so any peer is able to verify records we sign.
+	if node.libP2PKey, _, err = crypto.GenerateEd25519Key(rand.Reader); err != nil {
+		return nil, fmt.Errorf("failed to generate DHT node key: %w", err)
+	}
+
 	// Preparing config for libp2p Host. Other options can be added here.
 	if err = node.libP2PConfig.Apply(
 		libp2p.ListenAddrs(listenAddr),
+		libp2p.Identity(node.libP2PKey),
 		libp2p.FallbackDefaults,
 	); err != nil {
 		return nil, fmt.Errorf("failed to configure DHT node: %w", err)
@@ -83,6 +118,16 @@ func (n *Node) Start() (err error) {
 		return fmt.Errorf("failed to start DHT node: %w", err)
 	}
 
+	n.dht, err = dht.New(
+		n.libP2PNodeCtx,
+		n.libP2PNode,
+		dhtopts.Protocols(dhtProtocol),
+		dhtopts.NamespacedValidator(recordNamespace, newRecordValidator()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to start DHT: %w", err)
+	}
+
 	log.Info().Msgf("DHT node started on %s with ID=%s", n.libP2PNode.Addrs(), n.libP2PNode.ID())
 
 	// Start connecting to the bootstrap peer nodes early. They will tell us about the other nodes in the network.
@@ -95,9 +140,65 @@ func (n *Node) Start() (err error) {
 
 // Stop stops DHT node.
 func (n *Node) Stop() {
+	if n.dht != nil {
+		if err := n.dht.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to close DHT")
+		}
+	}
+	if n.libP2PNode != nil {
+		if err := n.libP2PNode.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to close DHT node")
+		}
+	}
 	n.libP2PNodeCancel()
 }
 
+// putRecord signs and stores proposal record of this node in the DHT.
+func (n *Node) putRecord(ctx context.Context, announcements []signedAnnouncement, issuedAt time.Time) error {
+	record, err := newProposalRecord(announcements, issuedAt, n.libP2PKey)
+	if err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode proposal record: %w", err)
+	}
+
+	err = n.dht.PutValue(ctx, recordKey(n.libP2PNode.ID()), value)
+	if errors.Is(err, kbucket.ErrLookupFailure) {
+		return errNoPeers
+	}
+	if err != nil {
+		return fmt.Errorf("failed to put proposal record: %w", err)
+	}
+
+	err = n.dht.Provide(ctx, n.rendezvous, true)
+	if errors.Is(err, kbucket.ErrLookupFailure) {
+		return errNoPeers
+	}
+	if err != nil {
+		return fmt.Errorf("failed to provide proposal record: %w", err)
+	}
+
+	return nil
+}
+
+// getRecord fetches proposal record of the given peer from the DHT.
+func (n *Node) getRecord(ctx context.Context, peerID peer.ID) (*proposalRecord, error) {
+	value, err := n.dht.GetValue(ctx, recordKey(peerID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proposal record of peer %s: %w", peerID, err)
+	}
+
+	return decodeProposalRecord(value)
+}
+
+// findAnnouncers searches the DHT for peers, which announce proposals.
+func (n *Node) findAnnouncers(ctx context.Context, limit int) <-chan peer.AddrInfo {
+	return n.dht.FindProvidersAsync(ctx, n.rendezvous, limit)
+}
+
 func (n *Node) connectToPeer(peerInfo peer.AddrInfo) {
 	if err := n.libP2PNode.Connect(n.libP2PNodeCtx, peerInfo); err != nil {
 		log.Warn().Err(err).Msgf("Failed to contact DHT peer %s", peerInfo.ID)
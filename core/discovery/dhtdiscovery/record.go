@@ -0,0 +1,217 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dhtdiscovery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// recordNamespace is the DHT key namespace under which proposal records are stored.
+const recordNamespace = "mysterium"
+
+// announcement is the proposal payload signed by the provider identity.
+type announcement struct {
+	Proposal  market.ServiceProposal `json:"proposal"`
+	ExpiresAt int64                  `json:"expires_at"`
+}
+
+// signedAnnouncement keeps the exact signed payload, so that the signature can be verified by any peer.
+type signedAnnouncement struct {
+	Payload   json.RawMessage `json:"payload"`
+	Signature string          `json:"signature"`
+}
+
+// proposalRecord is a value stored in the DHT under the key of the announcing peer.
+type proposalRecord struct {
+	Announcements []signedAnnouncement `json:"announcements"`
+	IssuedAt      int64                `json:"issued_at"`
+	Signature     []byte               `json:"signature"`
+}
+
+type recordPayload struct {
+	Announcements []signedAnnouncement `json:"announcements"`
+	IssuedAt      int64                `json:"issued_at"`
+}
+
+func newSignedAnnouncement(proposal market.ServiceProposal, expiresAt time.Time, signer identity.Signer) (signedAnnouncement, error) {
+	payload, err := json.Marshal(announcement{Proposal: proposal, ExpiresAt: expiresAt.Unix()})
+	if err != nil {
+		return signedAnnouncement{}, fmt.Errorf("failed to encode proposal announcement: %w", err)
+	}
+
+	signature, err := signer.Sign(payload)
+	if err != nil {
+		return signedAnnouncement{}, fmt.Errorf("failed to sign proposal announcement: %w", err)
+	}
+
+	return signedAnnouncement{Payload: payload, Signature: signature.Base64()}, nil
+}
+
+// decode verifies the provider signature and returns the announced proposal.
+func (sa signedAnnouncement) decode(extractor identity.Extractor) (announcement, error) {
+	var a announcement
+	if err := json.Unmarshal(sa.Payload, &a); err != nil {
+		return a, fmt.Errorf("failed to decode proposal announcement: %w", err)
+	}
+
+	signer, err := extractor.Extract(sa.Payload, identity.SignatureBase64(sa.Signature))
+	if err != nil {
+		return a, fmt.Errorf("failed to verify proposal signature: %w", err)
+	}
+	if signer != identity.FromAddress(a.Proposal.ProviderID) {
+		return a, fmt.Errorf("proposal of %s is signed by %s", a.Proposal.ProviderID, signer.Address)
+	}
+
+	return a, nil
+}
+
+func (a announcement) expired(now time.Time) bool {
+	return now.Unix() >= a.ExpiresAt
+}
+
+func newProposalRecord(announcements []signedAnnouncement, issuedAt time.Time, key crypto.PrivKey) (*proposalRecord, error) {
+	record := &proposalRecord{
+		Announcements: announcements,
+		IssuedAt:      issuedAt.UnixNano(),
+	}
+
+	data, err := record.signedData()
+	if err != nil {
+		return nil, err
+	}
+
+	if record.Signature, err = key.Sign(data); err != nil {
+		return nil, fmt.Errorf("failed to sign proposal record: %w", err)
+	}
+
+	return record, nil
+}
+
+func decodeProposalRecord(value []byte) (*proposalRecord, error) {
+	record := &proposalRecord{}
+	if err := json.Unmarshal(value, record); err != nil {
+		return nil, fmt.Errorf("failed to decode proposal record: %w", err)
+	}
+
+	return record, nil
+}
+
+func (r *proposalRecord) signedData() ([]byte, error) {
+	data, err := json.Marshal(recordPayload{Announcements: r.Announcements, IssuedAt: r.IssuedAt})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode proposal record: %w", err)
+	}
+
+	return data, nil
+}
+
+// verify checks that record was published by the given peer and every proposal in it is signed by its provider.
+func (r *proposalRecord) verify(peerID peer.ID, extractor identity.Extractor) error {
+	publicKey, err := peerID.ExtractPublicKey()
+	if err != nil {
+		return fmt.Errorf("failed to extract public key of peer %s: %w", peerID, err)
+	}
+
+	data, err := r.signedData()
+	if err != nil {
+		return err
+	}
+
+	valid, err := publicKey.Verify(data, r.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to verify proposal record: %w", err)
+	}
+	if !valid {
+		return fmt.Errorf("proposal record is not signed by peer %s", peerID)
+	}
+
+	for _, sa := range r.Announcements {
+		if _, err := sa.decode(extractor); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func recordKey(peerID peer.ID) string {
+	return "/" + recordNamespace + "/" + peer.IDB58Encode(peerID)
+}
+
+func peerFromRecordKey(key string) (peer.ID, error) {
+	prefix := "/" + recordNamespace + "/"
+	if !strings.HasPrefix(key, prefix) {
+		return "", fmt.Errorf("key %q is not in the %q namespace", key, recordNamespace)
+	}
+
+	return peer.IDB58Decode(strings.TrimPrefix(key, prefix))
+}
+
+// recordValidator validates proposal records stored and returned by the DHT.
+type recordValidator struct {
+	extractor identity.Extractor
+}
+
+func newRecordValidator() *recordValidator {
+	return &recordValidator{extractor: identity.NewExtractor()}
+}
+
+// Validate validates the given record, returning an error if it's invalid.
+func (v *recordValidator) Validate(key string, value []byte) error {
+	peerID, err := peerFromRecordKey(key)
+	if err != nil {
+		return err
+	}
+
+	record, err := decodeProposalRecord(value)
+	if err != nil {
+		return err
+	}
+
+	return record.verify(peerID, v.extractor)
+}
+
+// Select selects the most recently issued record from the set of records.
+func (v *recordValidator) Select(key string, values [][]byte) (int, error) {
+	best, bestIssuedAt := -1, int64(0)
+	for i, value := range values {
+		record, err := decodeProposalRecord(value)
+		if err != nil {
+			continue
+		}
+
+		if best == -1 || record.IssuedAt > bestIssuedAt {
+			best, bestIssuedAt = i, record.IssuedAt
+		}
+	}
+
+	if best == -1 {
+		return 0, errors.New("no valid proposal records to select from")
+	}
+
+	return best, nil
+}
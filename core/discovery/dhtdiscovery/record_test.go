@@ -0,0 +1,184 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dhtdiscovery
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+	"time"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	market.RegisterServiceDefinitionUnserializer(
+		"mock_service",
+		func(rawDefinition *json.RawMessage) (market.ServiceDefinition, error) {
+			return mockServiceDefinition{}, nil
+		},
+	)
+	market.RegisterPaymentMethodUnserializer(
+		"mock_payment",
+		func(rawDefinition *json.RawMessage) (market.PaymentMethod, error) {
+			return mockPaymentMethod{}, nil
+		},
+	)
+	market.RegisterContactUnserializer("mock_contact",
+		func(rawMessage *json.RawMessage) (market.ContactDefinition, error) {
+			return mockContact{}, nil
+		},
+	)
+}
+
+func Test_RecordValidator_AcceptsSignedRecord(t *testing.T) {
+	signer := newTestSigner(t)
+	key, peerID := newTestPeer(t)
+
+	record := newTestRecord(t, key, time.Now(), signedTestAnnouncement(t, signer, signer.proposal()))
+
+	assert.NoError(t, newRecordValidator().Validate(recordKey(peerID), record))
+}
+
+func Test_RecordValidator_RejectsRecordOfOtherPeer(t *testing.T) {
+	signer := newTestSigner(t)
+	key, _ := newTestPeer(t)
+	_, otherPeerID := newTestPeer(t)
+
+	record := newTestRecord(t, key, time.Now(), signedTestAnnouncement(t, signer, signer.proposal()))
+
+	assert.Error(t, newRecordValidator().Validate(recordKey(otherPeerID), record))
+}
+
+func Test_RecordValidator_RejectsProposalOfOtherProvider(t *testing.T) {
+	signer := newTestSigner(t)
+	otherSigner := newTestSigner(t)
+	key, peerID := newTestPeer(t)
+
+	record := newTestRecord(t, key, time.Now(), signedTestAnnouncement(t, signer, otherSigner.proposal()))
+
+	assert.Error(t, newRecordValidator().Validate(recordKey(peerID), record))
+}
+
+func Test_RecordValidator_RejectsUnknownNamespace(t *testing.T) {
+	signer := newTestSigner(t)
+	key, peerID := newTestPeer(t)
+
+	record := newTestRecord(t, key, time.Now(), signedTestAnnouncement(t, signer, signer.proposal()))
+
+	assert.Error(t, newRecordValidator().Validate("/other/"+peer.IDB58Encode(peerID), record))
+}
+
+func Test_RecordValidator_SelectsNewestRecord(t *testing.T) {
+	key, peerID := newTestPeer(t)
+
+	older := newTestRecord(t, key, time.Now().Add(-time.Minute))
+	newer := newTestRecord(t, key, time.Now())
+
+	index, err := newRecordValidator().Select(recordKey(peerID), [][]byte{older, newer, []byte("garbage")})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, index)
+
+	_, err = newRecordValidator().Select(recordKey(peerID), [][]byte{[]byte("garbage")})
+	assert.Error(t, err)
+}
+
+type testSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func newTestSigner(t *testing.T) *testSigner {
+	key, err := ethcrypto.GenerateKey()
+	assert.NoError(t, err)
+
+	return &testSigner{key: key}
+}
+
+func (ts *testSigner) Sign(message []byte) (identity.Signature, error) {
+	signature, err := ethcrypto.Sign(ethcrypto.Keccak256(message), ts.key)
+	return identity.SignatureBytes(signature), err
+}
+
+func (ts *testSigner) proposal() market.ServiceProposal {
+	return market.ServiceProposal{
+		ProviderID:        ethcrypto.PubkeyToAddress(ts.key.PublicKey).Hex(),
+		ServiceType:       "mock_service",
+		ServiceDefinition: mockServiceDefinition{},
+		PaymentMethodType: "mock_payment",
+		PaymentMethod:     mockPaymentMethod{},
+		ProviderContacts:  []market.Contact{{Type: "mock_contact", Definition: mockContact{}}},
+	}
+}
+
+func newTestPeer(t *testing.T) (crypto.PrivKey, peer.ID) {
+	key, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	assert.NoError(t, err)
+
+	peerID, err := peer.IDFromPrivateKey(key)
+	assert.NoError(t, err)
+
+	return key, peerID
+}
+
+func signedTestAnnouncement(t *testing.T, signer identity.Signer, proposal market.ServiceProposal) signedAnnouncement {
+	announcement, err := newSignedAnnouncement(proposal, time.Now().Add(time.Hour), signer)
+	assert.NoError(t, err)
+
+	return announcement
+}
+
+func newTestRecord(t *testing.T, key crypto.PrivKey, issuedAt time.Time, announcements ...signedAnnouncement) []byte {
+	record, err := newProposalRecord(announcements, issuedAt, key)
+	assert.NoError(t, err)
+
+	value, err := json.Marshal(record)
+	assert.NoError(t, err)
+
+	return value
+}
+
+type mockServiceDefinition struct{}
+
+func (service mockServiceDefinition) GetLocation() market.Location {
+	return market.Location{}
+}
+
+type mockPaymentMethod struct{}
+
+func (method mockPaymentMethod) GetPrice() money.Money {
+	return money.Money{}
+}
+
+func (method mockPaymentMethod) GetType() string {
+	return "mock_payment"
+}
+
+func (method mockPaymentMethod) GetRate() market.PaymentRate {
+	return market.PaymentRate{
+		PerTime: time.Minute,
+	}
+}
+
+type mockContact struct{}
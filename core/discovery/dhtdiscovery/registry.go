@@ -18,29 +18,88 @@
 package dhtdiscovery
 
 import (
+	"context"
+	"sync"
+	"time"
+
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/rs/zerolog/log"
 )
 
+const announceTimeout = 30 * time.Second
+
+type recordPublisher interface {
+	putRecord(ctx context.Context, announcements []signedAnnouncement, issuedAt time.Time) error
+}
+
 type registryDHT struct {
+	publisher   recordPublisher
+	proposalTTL time.Duration
+
+	mu            sync.Mutex
+	announcements map[market.ProposalID]signedAnnouncement
 }
 
-// NewRegistry create an instance of Broker registryBroker.
-func NewRegistry() *registryDHT {
-	return &registryDHT{}
+// NewRegistry create an instance of DHT registryDHT.
+// Proposals announced are valid for the given TTL, so they must be pinged more often than that.
+func NewRegistry(node *Node, proposalTTL time.Duration) *registryDHT {
+	return &registryDHT{
+		publisher:     node,
+		proposalTTL:   proposalTTL,
+		announcements: make(map[market.ProposalID]signedAnnouncement),
+	}
 }
 
 // RegisterProposal registers service proposal to discovery service.
 func (rd *registryDHT) RegisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
-	return nil
+	return rd.announce(proposal, signer)
 }
 
 // UnregisterProposal unregisters a service proposal when client disconnects.
 func (rd *registryDHT) UnregisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
-	return nil
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+
+	delete(rd.announcements, proposal.UniqueID())
+
+	return rd.publish()
 }
 
 // PingProposal pings service proposal as being alive.
 func (rd *registryDHT) PingProposal(proposal market.ServiceProposal, signer identity.Signer) error {
-	return nil
+	return rd.announce(proposal, signer)
+}
+
+func (rd *registryDHT) announce(proposal market.ServiceProposal, signer identity.Signer) error {
+	announcement, err := newSignedAnnouncement(proposal, time.Now().Add(rd.proposalTTL), signer)
+	if err != nil {
+		return err
+	}
+
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+
+	rd.announcements[proposal.UniqueID()] = announcement
+
+	return rd.publish()
+}
+
+func (rd *registryDHT) publish() error {
+	announcements := make([]signedAnnouncement, 0, len(rd.announcements))
+	for _, announcement := range rd.announcements {
+		announcements = append(announcements, announcement)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), announceTimeout)
+	defer cancel()
+
+	err := rd.publisher.putRecord(ctx, announcements, time.Now())
+	if err == errNoPeers {
+		// Record is kept locally and will be re-announced on the next ping.
+		log.Debug().Msg("No DHT peers known yet, proposals will be announced later")
+		return nil
+	}
+
+	return err
 }
@@ -18,37 +18,72 @@
 package dhtdiscovery
 
 import (
+	"context"
 	"sync"
+	"time"
 
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/mysteriumnetwork/node/core/discovery/brokerdiscovery"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/rs/zerolog/log"
 )
 
+const (
+	fetchTimeout      = time.Minute
+	fetchPeersLimit   = 1000
+	fetchRecordsLimit = 20
+)
+
+type recordFetcher interface {
+	findAnnouncers(ctx context.Context, limit int) <-chan peer.AddrInfo
+	getRecord(ctx context.Context, peerID peer.ID) (*proposalRecord, error)
+}
+
 // Repository provides proposals from the DHT.
 type Repository struct {
+	fetcher       recordFetcher
+	storage       *brokerdiscovery.ProposalStorage
+	extractor     identity.Extractor
+	fetchInterval time.Duration
+
 	stopOnce sync.Once
 	stopChan chan struct{}
+
+	announcedLock sync.Mutex
+	expirations   map[market.ProposalID]time.Time
+	peerProposals map[peer.ID]map[market.ProposalID]struct{}
 }
 
 // NewRepository constructs a new proposal repository (backed by the DHT).
-func NewRepository() *Repository {
+func NewRepository(node *Node, storage *brokerdiscovery.ProposalStorage, fetchInterval time.Duration) *Repository {
 	return &Repository{
-		stopChan: make(chan struct{}),
+		fetcher:       node,
+		storage:       storage,
+		extractor:     identity.NewExtractor(),
+		fetchInterval: fetchInterval,
+
+		stopChan:      make(chan struct{}),
+		expirations:   make(map[market.ProposalID]time.Time),
+		peerProposals: make(map[peer.ID]map[market.ProposalID]struct{}),
 	}
 }
 
 // Proposal returns a single proposal by its ID.
 func (r *Repository) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
-	return nil, nil
+	return r.storage.GetProposal(id)
 }
 
 // Proposals returns proposals matching the filter.
 func (r *Repository) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
-	return []market.ServiceProposal{}, nil
+	return r.storage.FindProposals(*filter)
 }
 
 // Start begins proposals synchronization to storage.
 func (r *Repository) Start() error {
+	go r.fetchLoop()
+
 	return nil
 }
 
@@ -58,3 +93,109 @@ func (r *Repository) Stop() {
 		close(r.stopChan)
 	})
 }
+
+func (r *Repository) fetchLoop() {
+	for {
+		r.fetch()
+		r.removeExpired(time.Now())
+
+		select {
+		case <-r.stopChan:
+			return
+		case <-time.After(r.fetchInterval):
+		}
+	}
+}
+
+func (r *Repository) fetch() {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-r.stopChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var wg sync.WaitGroup
+	limiter := make(chan struct{}, fetchRecordsLimit)
+	for peerInfo := range r.fetcher.findAnnouncers(ctx, fetchPeersLimit) {
+		wg.Add(1)
+		limiter <- struct{}{}
+		go func(peerID peer.ID) {
+			defer func() {
+				<-limiter
+				wg.Done()
+			}()
+
+			record, err := r.fetcher.getRecord(ctx, peerID)
+			if err != nil {
+				log.Debug().Err(err).Msgf("Skipping proposals of DHT peer %s", peerID)
+				return
+			}
+			r.storeRecord(peerID, record, time.Now())
+		}(peerInfo.ID)
+	}
+	wg.Wait()
+}
+
+func (r *Repository) storeRecord(peerID peer.ID, record *proposalRecord, now time.Time) {
+	r.announcedLock.Lock()
+	defer r.announcedLock.Unlock()
+
+	announced := make(map[market.ProposalID]struct{})
+	for _, sa := range record.Announcements {
+		announcement, err := sa.decode(r.extractor)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Skipping invalid proposal of DHT peer %s", peerID)
+			continue
+		}
+
+		if announcement.expired(now) || !announcement.Proposal.IsSupported() {
+			continue
+		}
+
+		r.storage.AddProposal(announcement.Proposal)
+		r.expirations[announcement.Proposal.UniqueID()] = time.Unix(announcement.ExpiresAt, 0)
+		announced[announcement.Proposal.UniqueID()] = struct{}{}
+	}
+
+	// Proposals which peer stopped announcing are unregistered.
+	for proposalID := range r.peerProposals[peerID] {
+		if _, ok := announced[proposalID]; !ok {
+			r.storage.RemoveProposal(proposalID)
+			delete(r.expirations, proposalID)
+		}
+	}
+	r.peerProposals[peerID] = announced
+}
+
+func (r *Repository) removeExpired(now time.Time) {
+	r.announcedLock.Lock()
+	defer r.announcedLock.Unlock()
+
+	expired := make(map[market.ProposalID]struct{})
+	for proposalID, expiresAt := range r.expirations {
+		if !now.Before(expiresAt) {
+			r.storage.RemoveProposal(proposalID)
+			delete(r.expirations, proposalID)
+			expired[proposalID] = struct{}{}
+		}
+	}
+	if len(expired) == 0 {
+		return
+	}
+
+	for peerID, announced := range r.peerProposals {
+		for proposalID := range announced {
+			if _, ok := expired[proposalID]; ok {
+				delete(announced, proposalID)
+			}
+		}
+		if len(announced) == 0 {
+			delete(r.peerProposals, peerID)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dhtdiscovery
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/mysteriumnetwork/node/core/discovery/brokerdiscovery"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Repository_FetchesRegisteredProposals(t *testing.T) {
+	dht := newDHTMock()
+	signer := newTestSigner(t)
+	registry := newTestRegistry(dht, time.Hour)
+	repo := newTestRepository(dht)
+
+	assert.NoError(t, registry.RegisterProposal(signer.proposal(), signer))
+	repo.fetch()
+
+	proposals, err := repo.Proposals(&proposal.Filter{})
+	assert.NoError(t, err)
+	assert.Equal(t, []market.ServiceProposal{signer.proposal()}, proposals)
+
+	expected := signer.proposal()
+	found, err := repo.Proposal(expected.UniqueID())
+	assert.NoError(t, err)
+	assert.Equal(t, expected, *found)
+}
+
+func Test_Repository_RemovesUnregisteredProposals(t *testing.T) {
+	dht := newDHTMock()
+	signer := newTestSigner(t)
+	registry := newTestRegistry(dht, time.Hour)
+	repo := newTestRepository(dht)
+
+	assert.NoError(t, registry.RegisterProposal(signer.proposal(), signer))
+	repo.fetch()
+	assert.NoError(t, registry.UnregisterProposal(signer.proposal(), signer))
+	repo.fetch()
+
+	proposals, err := repo.Proposals(&proposal.Filter{})
+	assert.NoError(t, err)
+	assert.Empty(t, proposals)
+}
+
+func Test_Repository_RemovesExpiredProposals(t *testing.T) {
+	dht := newDHTMock()
+	signer := newTestSigner(t)
+	registry := newTestRegistry(dht, time.Minute)
+	repo := newTestRepository(dht)
+
+	assert.NoError(t, registry.RegisterProposal(signer.proposal(), signer))
+	repo.fetch()
+
+	repo.removeExpired(time.Now())
+	proposals, _ := repo.Proposals(&proposal.Filter{})
+	assert.Len(t, proposals, 1)
+
+	repo.removeExpired(time.Now().Add(2 * time.Minute))
+	proposals, _ = repo.Proposals(&proposal.Filter{})
+	assert.Empty(t, proposals)
+	assert.Empty(t, repo.expirations)
+	assert.Empty(t, repo.peerProposals)
+}
+
+func Test_Registry_IgnoresMissingPeers(t *testing.T) {
+	dht := newDHTMock()
+	dht.putErr = errNoPeers
+	signer := newTestSigner(t)
+	registry := newTestRegistry(dht, time.Hour)
+
+	assert.NoError(t, registry.RegisterProposal(signer.proposal(), signer))
+	assert.NoError(t, registry.PingProposal(signer.proposal(), signer))
+
+	dht.putErr = errors.New("boom")
+	assert.EqualError(t, registry.PingProposal(signer.proposal(), signer), "boom")
+}
+
+func newTestRegistry(dht *dhtMock, proposalTTL time.Duration) *registryDHT {
+	return &registryDHT{
+		publisher:     dht,
+		proposalTTL:   proposalTTL,
+		announcements: make(map[market.ProposalID]signedAnnouncement),
+	}
+}
+
+func newTestRepository(dht *dhtMock) *Repository {
+	return &Repository{
+		fetcher:       dht,
+		storage:       brokerdiscovery.NewStorage(eventbus.New()),
+		extractor:     identity.NewExtractor(),
+		fetchInterval: time.Hour,
+		stopChan:      make(chan struct{}),
+		expirations:   make(map[market.ProposalID]time.Time),
+		peerProposals: make(map[peer.ID]map[market.ProposalID]struct{}),
+	}
+}
+
+// dhtMock imitates a single peer publishing its record to the DHT.
+type dhtMock struct {
+	key    crypto.PrivKey
+	peerID peer.ID
+	putErr error
+
+	mu     sync.Mutex
+	record *proposalRecord
+}
+
+func newDHTMock() *dhtMock {
+	key, _, _ := crypto.GenerateEd25519Key(nil)
+	peerID, _ := peer.IDFromPrivateKey(key)
+
+	return &dhtMock{key: key, peerID: peerID}
+}
+
+func (dm *dhtMock) putRecord(ctx context.Context, announcements []signedAnnouncement, issuedAt time.Time) error {
+	if dm.putErr != nil {
+		return dm.putErr
+	}
+
+	record, err := newProposalRecord(announcements, issuedAt, dm.key)
+	if err != nil {
+		return err
+	}
+
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.record = record
+
+	return nil
+}
+
+func (dm *dhtMock) findAnnouncers(ctx context.Context, limit int) <-chan peer.AddrInfo {
+	peers := make(chan peer.AddrInfo, 1)
+	peers <- peer.AddrInfo{ID: dm.peerID}
+	close(peers)
+
+	return peers
+}
+
+func (dm *dhtMock) getRecord(ctx context.Context, peerID peer.ID) (*proposalRecord, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	if dm.record == nil {
+		return nil, errors.New("record not found")
+	}
+
+	return dm.record, nil
+}
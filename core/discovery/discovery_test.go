@@ -113,6 +113,24 @@ func TestStartStopUnregisterProposal(t *testing.T) {
 	assert.Equal(t, ProposalUnregistered, actualStatus)
 }
 
+func TestUpdateRegistersUpdatedProposal(t *testing.T) {
+	d := discoveryWithMockedDependencies()
+	d.identityRegistry = &identityregistry.FakeRegistry{RegistrationStatus: identityregistry.Registered}
+	registry := &mockedProposalRegistry{}
+	d.proposalRegistry = registry
+
+	d.Start(providerID, serviceProposal)
+	defer d.Stop()
+	observeStatus(d, PingProposal)
+
+	updated := serviceProposal
+	updated.ID = 2
+	d.Update(updated)
+
+	assert.Equal(t, updated, registry.lastRegistered())
+	assert.Equal(t, updated, d.currentProposal())
+}
+
 func observeStatus(d *Discovery, status Status) Status {
 	for {
 		d.mu.RLock()
@@ -126,17 +144,28 @@ func observeStatus(d *Discovery, status Status) Status {
 }
 
 type mockedProposalRegistry struct {
+	mu         sync.Mutex
+	registered []market.ServiceProposal
 }
 
-func (mockedProposalRegistry) RegisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+func (mpr *mockedProposalRegistry) RegisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	mpr.mu.Lock()
+	defer mpr.mu.Unlock()
+	mpr.registered = append(mpr.registered, proposal)
 	return nil
 }
 
-func (mockedProposalRegistry) PingProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+func (mpr *mockedProposalRegistry) lastRegistered() market.ServiceProposal {
+	mpr.mu.Lock()
+	defer mpr.mu.Unlock()
+	return mpr.registered[len(mpr.registered)-1]
+}
+
+func (*mockedProposalRegistry) PingProposal(proposal market.ServiceProposal, signer identity.Signer) error {
 	return nil
 }
 
-func (mockedProposalRegistry) UnregisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+func (*mockedProposalRegistry) UnregisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
 	return nil
 }
 
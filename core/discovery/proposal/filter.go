@@ -30,12 +30,15 @@ type Filter struct {
 	ProviderID          string
 	ServiceType         string
 	LocationType        string
+	LocationCountry     string
 	AccessPolicyID      string
 	AccessPolicySource  string
 	UpperTimePriceBound *big.Int
 	LowerTimePriceBound *big.Int
 	UpperGBPriceBound   *big.Int
 	LowerGBPriceBound   *big.Int
+	MinBandwidthKbps    uint64
+	IPv6                bool
 	ExcludeUnsupported  bool
 	IncludeFailed       bool
 }
@@ -57,6 +60,9 @@ func (filter *Filter) Matches(proposal market.ServiceProposal) bool {
 	if filter.LocationType != "" {
 		conditions = append(conditions, reducer.Equal(reducer.LocationType, filter.LocationType))
 	}
+	if filter.LocationCountry != "" {
+		conditions = append(conditions, reducer.Equal(reducer.LocationCountry, filter.LocationCountry))
+	}
 	if filter.AccessPolicyID != "" || filter.AccessPolicySource != "" {
 		conditions = append(conditions, reducer.AccessPolicy(filter.AccessPolicyID, filter.AccessPolicySource))
 	}
@@ -69,6 +75,14 @@ func (filter *Filter) Matches(proposal market.ServiceProposal) bool {
 		conditions = append(conditions, reducer.PriceGiB(filter.LowerGBPriceBound, filter.UpperGBPriceBound))
 	}
 
+	if filter.MinBandwidthKbps > 0 {
+		conditions = append(conditions, reducer.Bandwidth(filter.MinBandwidthKbps))
+	}
+
+	if filter.IPv6 {
+		conditions = append(conditions, reducer.IPv6)
+	}
+
 	if len(conditions) > 0 {
 		return reducer.And(conditions...)(proposal)
 	}
@@ -194,6 +194,16 @@ func Test_ProposalFilter_FiltersByLocationType(t *testing.T) {
 	assert.True(t, filter.Matches(proposalProvider2Streaming))
 }
 
+func Test_ProposalFilter_FiltersByLocationCountry(t *testing.T) {
+	filter := &Filter{
+		LocationCountry: "LT",
+	}
+	assert.False(t, filter.Matches(proposalEmpty))
+	assert.False(t, filter.Matches(proposalProvider1Streaming))
+	assert.False(t, filter.Matches(proposalProvider1Noop))
+	assert.True(t, filter.Matches(proposalProvider2Streaming))
+}
+
 func Test_ProposalFilter_FiltersByAccessID(t *testing.T) {
 	filter := &Filter{
 		AccessPolicyID: "whitelist",
@@ -246,6 +256,35 @@ func Test_ProposalFilter_Filters_ByTimeBounds(t *testing.T) {
 	assert.True(t, filter.Matches(proposalTimeExact))
 }
 
+func Test_ProposalFilter_Filters_ByBandwidth(t *testing.T) {
+	filter := &Filter{
+		MinBandwidthKbps: 5000,
+	}
+
+	assert.True(t, filter.Matches(proposalEmpty))
+	assert.True(t, filter.Matches(market.ServiceProposal{BandwidthKbps: 10000}))
+	assert.False(t, filter.Matches(market.ServiceProposal{BandwidthKbps: 1000}))
+}
+
+func Test_ProposalFilter_Filters_ByIPv6(t *testing.T) {
+	filter := &Filter{
+		IPv6: true,
+	}
+
+	assert.False(t, filter.Matches(proposalEmpty))
+	assert.False(t, filter.Matches(market.ServiceProposal{ServiceDefinition: ipv6ServiceDefinition{ipv6: false}}))
+	assert.True(t, filter.Matches(market.ServiceProposal{ServiceDefinition: ipv6ServiceDefinition{ipv6: true}}))
+}
+
+type ipv6ServiceDefinition struct {
+	market.ServiceDefinition
+	ipv6 bool
+}
+
+func (s ipv6ServiceDefinition) SupportsIPv6() bool {
+	return s.ipv6
+}
+
 func Test_ProposalFilter_Filters_Unsupported(t *testing.T) {
 	filter := &Filter{
 		ExcludeUnsupported: true,
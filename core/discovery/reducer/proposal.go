@@ -67,6 +67,19 @@ func LocationType(proposal market.ServiceProposal) interface{} {
 	return service.GetLocation().NodeType
 }
 
+// Bandwidth checks if sessions of the proposal are not limited below the given rate
+func Bandwidth(minKbps uint64) func(market.ServiceProposal) bool {
+	return func(proposal market.ServiceProposal) bool {
+		return proposal.BandwidthKbps == 0 || proposal.BandwidthKbps >= minKbps
+	}
+}
+
+// IPv6 checks if the service of the proposal routes IPv6 traffic
+func IPv6(proposal market.ServiceProposal) bool {
+	service, ok := proposal.ServiceDefinition.(market.IPv6ServiceDefinition)
+	return ok && service.SupportsIPv6()
+}
+
 // PriceMinute checks if the price per minute is below the given value
 func PriceMinute(lowerBound, upperBound *big.Int) func(market.ServiceProposal) bool {
 	return pricePerTime(lowerBound, upperBound, time.Minute)
@@ -21,6 +21,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -87,6 +88,30 @@ func Test_AccessPolicy_FiltersByIDAndSource(t *testing.T) {
 	assert.True(t, match(proposalProvider2Streaming))
 }
 
+func Test_Bandwidth_FiltersByMinimalBandwidth(t *testing.T) {
+	match := Bandwidth(5000)
+
+	assert.True(t, match(proposalEmpty))
+	assert.True(t, match(market.ServiceProposal{BandwidthKbps: 5000}))
+	assert.True(t, match(market.ServiceProposal{BandwidthKbps: 10000}))
+	assert.False(t, match(market.ServiceProposal{BandwidthKbps: 1000}))
+}
+
+func Test_IPv6_FiltersByIPv6Support(t *testing.T) {
+	assert.False(t, IPv6(proposalEmpty))
+	assert.False(t, IPv6(market.ServiceProposal{ServiceDefinition: mockIPv6Service{ipv6: false}}))
+	assert.True(t, IPv6(market.ServiceProposal{ServiceDefinition: mockIPv6Service{ipv6: true}}))
+}
+
+type mockIPv6Service struct {
+	market.ServiceDefinition
+	ipv6 bool
+}
+
+func (s mockIPv6Service) SupportsIPv6() bool {
+	return s.ipv6
+}
+
 func Test_PriceMinute_FiltersByPrice(t *testing.T) {
 	match := PriceMinute(big.NewInt(100), big.NewInt(1000000))
 
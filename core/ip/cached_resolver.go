@@ -33,6 +33,10 @@ type CachedResolver struct {
 	outboundIPLock     sync.Mutex
 	outboundIPCachedAt time.Time
 
+	outboundIPv6         string
+	outboundIPv6Lock     sync.Mutex
+	outboundIPv6CachedAt time.Time
+
 	publicIP         string
 	publicIPLock     sync.Mutex
 	publicIPCachedAt time.Time
@@ -66,6 +70,26 @@ func (r *CachedResolver) GetOutboundIP() (string, error) {
 	return r.outboundIP, nil
 }
 
+// GetOutboundIPv6 returns current outbound IPv6 as string for current system.
+func (r *CachedResolver) GetOutboundIPv6() (string, error) {
+	r.outboundIPv6Lock.Lock()
+	defer r.outboundIPv6Lock.Unlock()
+
+	if r.outboundIPv6CachedAt.Add(r.cacheDuration).After(time.Now()) && r.outboundIPv6 != "" {
+		log.Debug().Msgf("Found cached outbound IPv6")
+		return r.outboundIPv6, nil
+	}
+
+	log.Debug().Msg("Outbound IPv6 cache is empty, fetching IP")
+	outboundIPv6, err := r.resolver.GetOutboundIPv6()
+	if err != nil {
+		return "", err
+	}
+	r.outboundIPv6CachedAt = time.Now()
+	r.outboundIPv6 = outboundIPv6
+	return r.outboundIPv6, nil
+}
+
 // GetPublicIP returns current public IP.
 func (r *CachedResolver) GetPublicIP() (string, error) {
 	r.publicIPLock.Lock()
@@ -95,6 +119,11 @@ func (r *CachedResolver) ClearCache() {
 	r.outboundIPCachedAt = time.Time{}
 	r.outboundIPLock.Unlock()
 
+	r.outboundIPv6Lock.Lock()
+	r.outboundIPv6 = ""
+	r.outboundIPv6CachedAt = time.Time{}
+	r.outboundIPv6Lock.Unlock()
+
 	r.publicIPLock.Lock()
 	r.publicIP = ""
 	r.publicIPCachedAt = time.Time{}
@@ -111,9 +111,27 @@ func TestCachedResolverCachesOutboundIP(t *testing.T) {
 	}
 }
 
+func TestCachedResolverCachesOutboundIPv6(t *testing.T) {
+	mr := &mockRealResolver{}
+	cr := NewCachedResolver(mr, 50*time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		actualIP, err := cr.GetOutboundIPv6()
+		assert.NoError(t, err)
+		assert.Equal(t, "2001:db8::2", actualIP)
+	}
+	assert.Equal(t, 1, mr.getOutboundIPv6Calls)
+
+	cr.ClearCache()
+	_, err := cr.GetOutboundIPv6()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, mr.getOutboundIPv6Calls)
+}
+
 type mockRealResolver struct {
-	getOutboundIPCalls int
-	getPublicIPCalls   int
+	getOutboundIPCalls   int
+	getOutboundIPv6Calls int
+	getPublicIPCalls     int
 }
 
 func (m *mockRealResolver) GetOutboundIP() (string, error) {
@@ -121,6 +139,11 @@ func (m *mockRealResolver) GetOutboundIP() (string, error) {
 	return "192.168.1.2", nil
 }
 
+func (m *mockRealResolver) GetOutboundIPv6() (string, error) {
+	m.getOutboundIPv6Calls++
+	return "2001:db8::2", nil
+}
+
 func (m *mockRealResolver) GetPublicIP() (string, error) {
 	m.getPublicIPCalls++
 	return "1.1.1.1", nil
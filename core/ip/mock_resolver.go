@@ -18,6 +18,7 @@
 package ip
 
 import (
+	"errors"
 	"net"
 )
 
@@ -41,6 +42,16 @@ func NewResolverMockMultiple(outboundIP string, publicIPs ...string) Resolver {
 	}
 }
 
+// NewResolverMockDualStack returns mockResolver which resolves statically entered IPv4 and IPv6 addresses.
+func NewResolverMockDualStack(ip, ipv6 string) Resolver {
+	return &mockResolver{
+		publicIP:     ip,
+		outboundIP:   net.ParseIP(ip),
+		outboundIPv6: net.ParseIP(ipv6),
+		error:        nil,
+	}
+}
+
 // NewResolverMockFailing returns mockResolver with entered error
 func NewResolverMockFailing(err error) Resolver {
 	return &mockResolver{
@@ -49,10 +60,11 @@ func NewResolverMockFailing(err error) Resolver {
 }
 
 type mockResolver struct {
-	publicIP   string
-	publicIPs  []string
-	outboundIP net.IP
-	error      error
+	publicIP     string
+	publicIPs    []string
+	outboundIP   net.IP
+	outboundIPv6 net.IP
+	error        error
 }
 
 func (client *mockResolver) MockPublicIPs(ips ...string) {
@@ -70,6 +82,16 @@ func (client *mockResolver) GetOutboundIP() (string, error) {
 	return client.outboundIP.String(), client.error
 }
 
+func (client *mockResolver) GetOutboundIPv6() (string, error) {
+	if client.error != nil {
+		return "", client.error
+	}
+	if client.outboundIPv6 == nil {
+		return "", errors.New("no IPv6 connectivity")
+	}
+	return client.outboundIPv6.String(), nil
+}
+
 func (client *mockResolver) getNextIP() string {
 	// Return first address if only one provided.
 	if len(client.publicIPs) == 1 {
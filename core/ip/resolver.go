@@ -31,6 +31,7 @@ const apiClient = "goclient-v0.1"
 // Resolver allows resolving current public and outbound IPs
 type Resolver interface {
 	GetOutboundIP() (string, error)
+	GetOutboundIPv6() (string, error)
 	GetPublicIP() (string, error)
 }
 
@@ -55,7 +56,10 @@ type ipResponse struct {
 }
 
 // declared as var for override in test
-var checkAddress = "8.8.8.8:53"
+var (
+	checkAddress  = "8.8.8.8:53"
+	checkAddress6 = "[2001:4860:4860::8888]:53"
+)
 
 // GetOutboundIP returns current outbound IP as string for current system
 func (r *ResolverImpl) GetOutboundIP() (string, error) {
@@ -81,6 +85,28 @@ func (r *ResolverImpl) getOutboundIP() (net.IP, error) {
 	return conn.LocalAddr().(*net.UDPAddr).IP, nil
 }
 
+// GetOutboundIPv6 returns current outbound IPv6 as string for current system.
+// Error is returned when the system has no IPv6 connectivity.
+func (r *ResolverImpl) GetOutboundIPv6() (string, error) {
+	dialer := net.Dialer{}
+	// IPv4 bind address can not be used for IPv6 connections.
+	if ipAddress := net.ParseIP(r.bindAddress); ipAddress != nil && ipAddress.To4() == nil {
+		dialer.LocalAddr = &net.UDPAddr{IP: ipAddress}
+	}
+
+	conn, err := dialer.Dial("udp6", checkAddress6)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to determine outbound IPv6")
+	}
+	defer conn.Close()
+
+	ip := conn.LocalAddr().(*net.UDPAddr).IP
+	if !ip.IsGlobalUnicast() {
+		return "", errors.Errorf("outbound IPv6 %s is not global unicast", ip)
+	}
+	return ip.String(), nil
+}
+
 // GetPublicIP returns current public IP
 func (r *ResolverImpl) GetPublicIP() (string, error) {
 	var ipResponse ipResponse
@@ -0,0 +1,310 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	journalBucket   = "event-journal"
+	cleanupInterval = time.Hour
+	listenerBuffer  = 64
+	queueSize       = 1024
+)
+
+// Entry is a recorded event bus event.
+// IDs are assigned in the order the events are published and never reused, so they can be used to resume reading.
+type Entry struct {
+	ID      uint64 `storm:"id"`
+	Topic   string
+	Time    time.Time
+	Payload string
+}
+
+// Filter selects the journal entries.
+type Filter struct {
+	Topic   string
+	From    time.Time
+	To      time.Time
+	AfterID uint64
+	Limit   int
+}
+
+// Config limits the recorded topics and how long the entries are kept.
+type Config struct {
+	Topics     []string
+	MaxAge     time.Duration
+	MaxEntries int
+}
+
+// Journal is an append-only journal of the selected event bus topics kept in the node storage.
+type Journal struct {
+	storage storage.Storage
+	config  Config
+	now     func() time.Time
+
+	queue chan Entry
+
+	lock      sync.Mutex
+	lastID    uint64
+	listeners map[chan Entry]struct{}
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	writer   sync.WaitGroup
+}
+
+// NewJournal creates the journal keeping the entries in the given storage.
+func NewJournal(storage storage.Storage, config Config) *Journal {
+	return &Journal{
+		storage:   storage,
+		config:    config,
+		now:       time.Now,
+		queue:     make(chan Entry, queueSize),
+		listeners: make(map[chan Entry]struct{}),
+		stop:      make(chan struct{}),
+	}
+}
+
+// Subscribe starts recording the configured topics. Events are queued in the order they are published in
+// and written by the journal once it is started, so that the publishers are not blocked by the storage.
+func (j *Journal) Subscribe(bus eventbus.Subscriber) error {
+	var last Entry
+	err := j.storage.GetLast(journalBucket, &last)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("failed to get the last journal entry: %w", err)
+	}
+	j.lastID = last.ID
+
+	for _, topic := range j.config.Topics {
+		topic := topic
+		if err := bus.Subscribe(topic, func(data interface{}) { j.enqueue(topic, data) }); err != nil {
+			return fmt.Errorf("failed to subscribe to %q: %w", topic, err)
+		}
+	}
+	return nil
+}
+
+// Enabled tells whether any topics are recorded.
+func (j *Journal) Enabled() bool {
+	return len(j.config.Topics) > 0
+}
+
+// Start starts writing the queued events and removing the entries beyond the retention limits.
+func (j *Journal) Start() {
+	if !j.Enabled() {
+		return
+	}
+	j.writer.Add(1)
+	go j.write()
+	go j.run()
+}
+
+// Stop writes the events queued so far, stops the journal cleanup and disconnects the listeners.
+func (j *Journal) Stop() {
+	j.stopOnce.Do(func() {
+		close(j.stop)
+		j.writer.Wait()
+
+		j.lock.Lock()
+		defer j.lock.Unlock()
+		for listener := range j.listeners {
+			delete(j.listeners, listener)
+			close(listener)
+		}
+	})
+}
+
+// Entries returns the entries selected by the filter, oldest first.
+func (j *Journal) Entries(filter Filter) ([]Entry, error) {
+	where := make([]storage.Condition, 0)
+	if filter.Topic != "" {
+		where = append(where, storage.Eq("Topic", filter.Topic))
+	}
+	if !filter.From.IsZero() {
+		where = append(where, storage.Gte("Time", filter.From))
+	}
+	if !filter.To.IsZero() {
+		where = append(where, storage.Lte("Time", filter.To))
+	}
+	if filter.AfterID > 0 {
+		where = append(where, storage.Gte("ID", filter.AfterID+1))
+	}
+
+	var entries []Entry
+	err := j.storage.Find(journalBucket, storage.Query{Where: where, OrderBy: "ID", Limit: filter.Limit}, &entries)
+	if errors.Is(err, storage.ErrNotFound) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find journal entries: %w", err)
+	}
+	return entries, nil
+}
+
+// Listen returns the channel receiving the entries as they are recorded.
+// The channel is closed when the listener falls behind, it should resume by reading the entries after the last one received.
+func (j *Journal) Listen() chan Entry {
+	listener := make(chan Entry, listenerBuffer)
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	select {
+	case <-j.stop:
+		close(listener)
+	default:
+		j.listeners[listener] = struct{}{}
+	}
+	return listener
+}
+
+// Unlisten stops sending the entries to the listener.
+func (j *Journal) Unlisten(listener chan Entry) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if _, ok := j.listeners[listener]; ok {
+		delete(j.listeners, listener)
+		close(listener)
+	}
+}
+
+// enqueue queues the event for writing without waiting for the storage, the event is dropped when the queue is full.
+func (j *Journal) enqueue(topic string, data interface{}) {
+	entry, err := j.newEntry(topic, data)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Could not record %q event to the journal", topic)
+		return
+	}
+
+	select {
+	case j.queue <- entry:
+	default:
+		log.Warn().Msgf("Journal queue is full, dropping %q event", topic)
+	}
+}
+
+// newEntry creates the entry of the event, its ID is assigned when the entry is written.
+func (j *Journal) newEntry(topic string, data interface{}) (Entry, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return Entry{}, err
+	}
+	return Entry{Topic: topic, Time: j.now().UTC(), Payload: string(payload)}, nil
+}
+
+// write writes the queued entries until the journal is stopped, the entries queued by then are written too.
+func (j *Journal) write() {
+	defer j.writer.Done()
+
+	for {
+		select {
+		case entry := <-j.queue:
+			j.record(entry)
+		case <-j.stop:
+			for {
+				select {
+				case entry := <-j.queue:
+					j.record(entry)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (j *Journal) record(entry Entry) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	entry.ID = j.lastID + 1
+	if err := j.storage.Store(journalBucket, &entry); err != nil {
+		log.Error().Err(err).Msgf("Could not record %q event to the journal", entry.Topic)
+		return
+	}
+	j.lastID = entry.ID
+
+	for listener := range j.listeners {
+		select {
+		case listener <- entry:
+		default:
+			delete(j.listeners, listener)
+			close(listener)
+		}
+	}
+}
+
+func (j *Journal) run() {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		j.cleanup()
+
+		select {
+		case <-j.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// cleanup removes the entries older than the max age and the oldest ones over the max entry count.
+func (j *Journal) cleanup() {
+	if j.config.MaxAge > 0 {
+		if err := j.remove(storage.Lte("Time", j.now().Add(-j.config.MaxAge))); err != nil {
+			log.Error().Err(err).Msg("Could not remove the expired journal entries")
+		}
+	}
+
+	j.lock.Lock()
+	lastID := j.lastID
+	j.lock.Unlock()
+	if j.config.MaxEntries > 0 && lastID > uint64(j.config.MaxEntries) {
+		// IDs are sequential, so everything up to this one is over the limit.
+		if err := j.remove(storage.Lte("ID", lastID-uint64(j.config.MaxEntries))); err != nil {
+			log.Error().Err(err).Msg("Could not remove the journal entries over the limit")
+		}
+	}
+}
+
+func (j *Journal) remove(condition storage.Condition) error {
+	var entries []Entry
+	err := j.storage.Find(journalBucket, storage.Query{Where: []storage.Condition{condition}}, &entries)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		if err := j.storage.Delete(journalBucket, &entries[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
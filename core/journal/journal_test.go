@@ -0,0 +1,202 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package journal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+	"github.com/mysteriumnetwork/node/core/storage/sqlite"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEvent struct {
+	Name string `json:"name"`
+}
+
+func TestJournal_RecordsSelectedTopics(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, db storage.Storage) {
+		bus := eventbus.New()
+		journal := NewJournal(db, Config{Topics: []string{"first", "second"}})
+		require.NoError(t, journal.Subscribe(bus))
+		journal.Start()
+
+		bus.Publish("first", testEvent{Name: "a"})
+		bus.Publish("ignored", testEvent{Name: "b"})
+		bus.Publish("second", testEvent{Name: "c"})
+		bus.Publish("first", testEvent{Name: "d"})
+
+		var entries []Entry
+		var err error
+		assert.Eventually(t, func() bool {
+			entries, err = journal.Entries(Filter{})
+			return err == nil && len(entries) == 3
+		}, 2*time.Second, 10*time.Millisecond)
+		journal.Stop()
+		require.Len(t, entries, 3)
+		assert.Equal(t, []uint64{1, 2, 3}, ids(entries))
+		assert.Equal(t, "first", entries[0].Topic)
+		assert.JSONEq(t, `{"name": "a"}`, entries[0].Payload)
+
+		entries, err = journal.Entries(Filter{Topic: "first"})
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1, 3}, ids(entries))
+
+		entries, err = journal.Entries(Filter{AfterID: 1, Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{2}, ids(entries))
+
+		// IDs continue after the restart.
+		journal = NewJournal(db, Config{Topics: []string{"first"}})
+		require.NoError(t, journal.Subscribe(eventbus.New()))
+		record(t, journal, "first", testEvent{Name: "e"})
+		entries, err = journal.Entries(Filter{AfterID: 3})
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{4}, ids(entries))
+	})
+}
+
+func TestJournal_WritesQueuedEventsOnStop(t *testing.T) {
+	bus := eventbus.New()
+	db := newBoltStorage(t)
+	journal := NewJournal(db, Config{Topics: []string{"topic"}})
+	require.NoError(t, journal.Subscribe(bus))
+
+	// Publishers are not blocked while the journal is not writing, the events over the queue size are dropped.
+	for i := 0; i <= queueSize; i++ {
+		bus.Publish("topic", testEvent{})
+	}
+
+	journal.Start()
+	journal.Stop()
+	entries, err := journal.Entries(Filter{})
+	require.NoError(t, err)
+	assert.Len(t, entries, queueSize)
+}
+
+func TestJournal_FiltersByTime(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, db storage.Storage) {
+		journal := NewJournal(db, Config{Topics: []string{"topic"}})
+		start := time.Date(2020, 10, 22, 12, 0, 0, 0, time.UTC)
+		for i := 0; i < 4; i++ {
+			at := start.Add(time.Duration(i) * time.Hour)
+			journal.now = func() time.Time { return at }
+			record(t, journal, "topic", testEvent{})
+		}
+
+		entries, err := journal.Entries(Filter{From: start.Add(time.Hour), To: start.Add(2 * time.Hour)})
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{2, 3}, ids(entries))
+	})
+}
+
+func TestJournal_Cleanup(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, db storage.Storage) {
+		journal := NewJournal(db, Config{Topics: []string{"topic"}, MaxAge: 3 * time.Hour, MaxEntries: 3})
+		start := time.Date(2020, 10, 22, 12, 0, 0, 0, time.UTC)
+		for i := 0; i < 5; i++ {
+			at := start.Add(time.Duration(i) * time.Hour)
+			journal.now = func() time.Time { return at }
+			record(t, journal, "topic", testEvent{})
+		}
+
+		journal.now = func() time.Time { return start.Add(4 * time.Hour) }
+		journal.cleanup()
+		entries, err := journal.Entries(Filter{})
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{3, 4, 5}, ids(entries))
+
+		journal.now = func() time.Time { return start.Add(6*time.Hour + 30*time.Minute) }
+		journal.cleanup()
+		entries, err = journal.Entries(Filter{})
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{5}, ids(entries))
+	})
+}
+
+func TestJournal_Listen(t *testing.T) {
+	journal := NewJournal(newBoltStorage(t), Config{Topics: []string{"topic"}})
+	listener := journal.Listen()
+
+	record(t, journal, "topic", testEvent{Name: "a"})
+	entry := <-listener
+	assert.Equal(t, uint64(1), entry.ID)
+	assert.JSONEq(t, `{"name": "a"}`, entry.Payload)
+
+	// The listener falling behind is disconnected.
+	for i := 0; i <= listenerBuffer; i++ {
+		record(t, journal, "topic", testEvent{})
+	}
+	received := 0
+	for range listener {
+		received++
+	}
+	assert.Equal(t, listenerBuffer, received)
+	journal.Unlisten(listener)
+
+	journal.Stop()
+	_, open := <-journal.Listen()
+	assert.False(t, open)
+}
+
+func record(t *testing.T, journal *Journal, topic string, data interface{}) {
+	entry, err := journal.newEntry(topic, data)
+	require.NoError(t, err)
+	journal.record(entry)
+}
+
+func forEachStorage(t *testing.T, test func(t *testing.T, db storage.Storage)) {
+	t.Run("boltdb", func(t *testing.T) {
+		test(t, newBoltStorage(t))
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		dir := boltdbtest.CreateTempDir(t)
+		db, err := sqlite.NewStorage(dir)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			db.Close()
+			boltdbtest.RemoveTempDir(t, dir)
+		})
+		require.NoError(t, db.RunMigrations(sqlite.Sequence))
+		test(t, db)
+	})
+}
+
+func newBoltStorage(t *testing.T) storage.Storage {
+	dir := boltdbtest.CreateTempDir(t)
+	db, err := boltdb.NewStorage(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+		boltdbtest.RemoveTempDir(t, dir)
+	})
+	return db
+}
+
+func ids(entries []Entry) []uint64 {
+	result := make([]uint64, len(entries))
+	for i, entry := range entries {
+		result[i] = entry.ID
+	}
+	return result
+}
@@ -0,0 +1,312 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package metrics
+
+import (
+	"math/big"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/eventbus"
+	natEvent "github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/p2p"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/node/trace"
+)
+
+const (
+	namespace = "myst"
+
+	directionProvided = "provided"
+	directionConsumed = "consumed"
+)
+
+// Exporter keeps node metrics fed by the event bus and exposes them in Prometheus format.
+type Exporter struct {
+	registry *prometheus.Registry
+
+	sessionsActive    *prometheus.GaugeVec
+	bytes             *prometheus.CounterVec
+	tokensEarned      prometheus.Counter
+	tokensSpent       prometheus.Counter
+	settlements       *prometheus.CounterVec
+	tokensSettled     prometheus.Counter
+	natTraversals     *prometheus.CounterVec
+	traceStages       *prometheus.HistogramVec
+	keepAliveFailures *prometheus.CounterVec
+
+	mu       sync.Mutex
+	sessions map[string]*sessionCounters
+}
+
+// sessionCounters keeps the last totals of the session, since events carry totals rather than increments.
+type sessionCounters struct {
+	direction   string
+	serviceType string
+	sent        uint64
+	received    uint64
+	tokens      *big.Int
+}
+
+// NewExporter creates metrics exporter with its own registry, which also collects Go runtime and process metrics.
+func NewExporter() *Exporter {
+	e := &Exporter{
+		registry: prometheus.NewRegistry(),
+		sessionsActive: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "sessions_active",
+			Help:      "Number of active sessions by direction and service type.",
+		}, []string{"direction", "service_type"}),
+		bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "session_bytes_total",
+			Help:      "Bytes transferred in sessions by session direction and traffic flow (sent or received by this node).",
+		}, []string{"direction", "flow"}),
+		tokensEarned: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tokens_earned_total",
+			Help:      "MYST earned in provided sessions.",
+		}),
+		tokensSpent: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tokens_spent_total",
+			Help:      "MYST paid for consumed sessions.",
+		}),
+		settlements: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "settlements_total",
+			Help:      "Hermes promise settlements by result.",
+		}, []string{"result"}),
+		tokensSettled: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tokens_settled_total",
+			Help:      "MYST sent to beneficiary by successful settlements.",
+		}),
+		natTraversals: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "nat_traversals_total",
+			Help:      "NAT traversal attempts by stage and result.",
+		}, []string{"stage", "result"}),
+		traceStages: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "trace_stage_duration_seconds",
+			Help:      "Duration of traced session establishment stages, including p2p NAT traversal.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		}, []string{"stage"}),
+		keepAliveFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "p2p_keepalive_failures_total",
+			Help:      "Failed p2p channel keepalive pings by side, fatal ones closed the channel.",
+		}, []string{"side", "fatal"}),
+		sessions: make(map[string]*sessionCounters),
+	}
+
+	e.registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		e.sessionsActive,
+		e.bytes,
+		e.tokensEarned,
+		e.tokensSpent,
+		e.settlements,
+		e.tokensSettled,
+		e.natTraversals,
+		e.traceStages,
+		e.keepAliveFailures,
+	)
+	return e
+}
+
+// Handler returns HTTP handler serving metrics to Prometheus.
+func (e *Exporter) Handler() http.Handler {
+	return promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{})
+}
+
+// Subscribe subscribes to relevant events of event bus.
+func (e *Exporter) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.Subscribe(sessionEvent.AppTopicSession, e.consumeServiceSessionEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(sessionEvent.AppTopicDataTransferred, e.consumeServiceDataTransferredEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(sessionEvent.AppTopicTokensEarned, e.consumeTokensEarnedEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(connectionstate.AppTopicConnectionSession, e.consumeConnectionSessionEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(connectionstate.AppTopicConnectionHopSession, e.consumeConnectionSessionEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(connectionstate.AppTopicConnectionStatistics, e.consumeConnectionStatisticsEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(connectionstate.AppTopicConnectionHopStatistics, e.consumeConnectionStatisticsEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(pingpongEvent.AppTopicInvoicePaid, e.consumeInvoicePaidEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(pingpongEvent.AppTopicSettlementComplete, e.consumeSettlementEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(natEvent.AppTopicTraversal, e.consumeNATEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(trace.AppTopicTraceEvent, e.consumeTraceEvent); err != nil {
+		return err
+	}
+	return bus.Subscribe(p2p.AppTopicKeepAliveFailed, e.consumeKeepAliveFailedEvent)
+}
+
+func (e *Exporter) consumeServiceSessionEvent(ev sessionEvent.AppEventSession) {
+	switch ev.Status {
+	case sessionEvent.CreatedStatus:
+		e.sessionStarted(ev.Session.ID, directionProvided, ev.Session.Proposal.ServiceType)
+	case sessionEvent.RemovedStatus:
+		e.sessionEnded(ev.Session.ID)
+	}
+}
+
+func (e *Exporter) consumeConnectionSessionEvent(ev connectionstate.AppEventConnectionSession) {
+	sessionID := string(ev.SessionInfo.SessionID)
+
+	switch ev.Status {
+	case connectionstate.SessionCreatedStatus:
+		e.sessionStarted(sessionID, directionConsumed, ev.SessionInfo.Proposal.ServiceType)
+	case connectionstate.SessionEndedStatus:
+		e.sessionEnded(sessionID)
+	}
+}
+
+func (e *Exporter) consumeServiceDataTransferredEvent(ev sessionEvent.AppEventDataTransferred) {
+	// Provider sends the data downloaded by consumer.
+	e.dataTransferred(ev.ID, ev.Down, ev.Up)
+}
+
+func (e *Exporter) consumeConnectionStatisticsEvent(ev connectionstate.AppEventConnectionStatistics) {
+	e.dataTransferred(string(ev.SessionInfo.SessionID), ev.Stats.BytesSent, ev.Stats.BytesReceived)
+}
+
+func (e *Exporter) consumeTokensEarnedEvent(ev sessionEvent.AppEventTokensEarned) {
+	e.tokensTransferred(ev.SessionID, ev.Total, e.tokensEarned)
+}
+
+func (e *Exporter) consumeInvoicePaidEvent(ev pingpongEvent.AppEventInvoicePaid) {
+	e.tokensTransferred(ev.SessionID, ev.Invoice.AgreementTotal, e.tokensSpent)
+}
+
+func (e *Exporter) consumeSettlementEvent(ev pingpongEvent.AppEventSettlementComplete) {
+	e.settlements.WithLabelValues(result(ev.Successful)).Inc()
+	if ev.Successful && ev.Amount != nil {
+		e.tokensSettled.Add(crypto.BigMystToFloat(ev.Amount))
+	}
+}
+
+func (e *Exporter) consumeNATEvent(ev natEvent.Event) {
+	e.natTraversals.WithLabelValues(ev.Stage, result(ev.Successful)).Inc()
+}
+
+func (e *Exporter) consumeTraceEvent(ev trace.Event) {
+	e.traceStages.WithLabelValues(ev.Key).Observe(ev.Duration.Seconds())
+}
+
+func (e *Exporter) consumeKeepAliveFailedEvent(ev p2p.AppEventKeepAliveFailed) {
+	side := "provider"
+	if ev.Consumer {
+		side = "consumer"
+	}
+	e.keepAliveFailures.WithLabelValues(side, strconv.FormatBool(ev.Fatal)).Inc()
+}
+
+func (e *Exporter) sessionStarted(sessionID, direction, serviceType string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.sessions[sessionID]; ok {
+		return
+	}
+	e.sessions[sessionID] = &sessionCounters{direction: direction, serviceType: serviceType, tokens: new(big.Int)}
+	e.sessionsActive.WithLabelValues(direction, serviceType).Inc()
+}
+
+func (e *Exporter) sessionEnded(sessionID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	session, ok := e.sessions[sessionID]
+	if !ok {
+		return
+	}
+	delete(e.sessions, sessionID)
+	e.sessionsActive.WithLabelValues(session.direction, session.serviceType).Dec()
+}
+
+func (e *Exporter) dataTransferred(sessionID string, sent, received uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	session, ok := e.sessions[sessionID]
+	if !ok {
+		return
+	}
+	e.bytes.WithLabelValues(session.direction, "sent").Add(float64(increment(session.sent, sent)))
+	e.bytes.WithLabelValues(session.direction, "received").Add(float64(increment(session.received, received)))
+	session.sent, session.received = sent, received
+}
+
+func (e *Exporter) tokensTransferred(sessionID string, total *big.Int, counter prometheus.Counter) {
+	if total == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	session, ok := e.sessions[sessionID]
+	if !ok {
+		return
+	}
+	if total.Cmp(session.tokens) > 0 {
+		counter.Add(crypto.BigMystToFloat(new(big.Int).Sub(total, session.tokens)))
+	}
+	session.tokens = new(big.Int).Set(total)
+}
+
+// increment returns the increase of the total, the total restarted by the reconnection counts as a whole.
+func increment(previous, current uint64) uint64 {
+	if current < previous {
+		return current
+	}
+	return current - previous
+}
+
+func result(successful bool) string {
+	if successful {
+		return "success"
+	}
+	return "failure"
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/market"
+	natEvent "github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/p2p"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+func newSubscribedExporter(t *testing.T) (*Exporter, eventbus.EventBus) {
+	bus := eventbus.New()
+	exporter := NewExporter()
+	assert.NoError(t, exporter.Subscribe(bus))
+	return exporter, bus
+}
+
+func TestExporter_ProvidedSession(t *testing.T) {
+	exporter, bus := newSubscribedExporter(t)
+
+	session := sessionEvent.SessionContext{ID: "s1", Proposal: market.ServiceProposal{ServiceType: "wireguard"}}
+	bus.Publish(sessionEvent.AppTopicSession, sessionEvent.AppEventSession{Status: sessionEvent.CreatedStatus, Session: session})
+	assert.Equal(t, 1.0, testutil.ToFloat64(exporter.sessionsActive.WithLabelValues(directionProvided, "wireguard")))
+
+	bus.Publish(sessionEvent.AppTopicDataTransferred, sessionEvent.AppEventDataTransferred{ID: "s1", Up: 10, Down: 100})
+	bus.Publish(sessionEvent.AppTopicDataTransferred, sessionEvent.AppEventDataTransferred{ID: "s1", Up: 15, Down: 300})
+	// Unknown sessions are ignored.
+	bus.Publish(sessionEvent.AppTopicDataTransferred, sessionEvent.AppEventDataTransferred{ID: "s2", Up: 1000, Down: 1000})
+	assert.Equal(t, 300.0, testutil.ToFloat64(exporter.bytes.WithLabelValues(directionProvided, "sent")))
+	assert.Equal(t, 15.0, testutil.ToFloat64(exporter.bytes.WithLabelValues(directionProvided, "received")))
+
+	bus.Publish(sessionEvent.AppTopicTokensEarned, sessionEvent.AppEventTokensEarned{SessionID: "s1", Total: crypto.FloatToBigMyst(0.5)})
+	bus.Publish(sessionEvent.AppTopicTokensEarned, sessionEvent.AppEventTokensEarned{SessionID: "s1", Total: crypto.FloatToBigMyst(1.5)})
+	assert.InDelta(t, 1.5, testutil.ToFloat64(exporter.tokensEarned), 1e-9)
+
+	bus.Publish(sessionEvent.AppTopicSession, sessionEvent.AppEventSession{Status: sessionEvent.RemovedStatus, Session: session})
+	assert.Equal(t, 0.0, testutil.ToFloat64(exporter.sessionsActive.WithLabelValues(directionProvided, "wireguard")))
+}
+
+func TestExporter_ConsumedSession(t *testing.T) {
+	exporter, bus := newSubscribedExporter(t)
+
+	info := connectionstate.Status{SessionID: "s1", Proposal: market.ServiceProposal{ServiceType: "openvpn"}}
+	bus.Publish(connectionstate.AppTopicConnectionSession, connectionstate.AppEventConnectionSession{Status: connectionstate.SessionCreatedStatus, SessionInfo: info})
+	assert.Equal(t, 1.0, testutil.ToFloat64(exporter.sessionsActive.WithLabelValues(directionConsumed, "openvpn")))
+
+	bus.Publish(connectionstate.AppTopicConnectionStatistics, connectionstate.AppEventConnectionStatistics{
+		SessionInfo: info,
+		Stats:       connectionstate.Statistics{BytesSent: 50, BytesReceived: 500},
+	})
+	// Counters restart on reconnection.
+	bus.Publish(connectionstate.AppTopicConnectionStatistics, connectionstate.AppEventConnectionStatistics{
+		SessionInfo: info,
+		Stats:       connectionstate.Statistics{BytesSent: 20, BytesReceived: 200},
+	})
+	assert.Equal(t, 70.0, testutil.ToFloat64(exporter.bytes.WithLabelValues(directionConsumed, "sent")))
+	assert.Equal(t, 700.0, testutil.ToFloat64(exporter.bytes.WithLabelValues(directionConsumed, "received")))
+
+	bus.Publish(connectionstate.AppTopicConnectionSession, connectionstate.AppEventConnectionSession{Status: connectionstate.SessionEndedStatus, SessionInfo: info})
+	assert.Equal(t, 0.0, testutil.ToFloat64(exporter.sessionsActive.WithLabelValues(directionConsumed, "openvpn")))
+}
+
+func TestExporter_Handler(t *testing.T) {
+	exporter, bus := newSubscribedExporter(t)
+
+	bus.Publish(pingpongEvent.AppTopicSettlementComplete, pingpongEvent.AppEventSettlementComplete{Successful: true, Amount: crypto.FloatToBigMyst(2)})
+	bus.Publish(pingpongEvent.AppTopicSettlementComplete, pingpongEvent.AppEventSettlementComplete{Successful: false})
+	bus.Publish(natEvent.AppTopicTraversal, natEvent.Event{Stage: "hole_punching", Successful: false})
+	bus.Publish(p2p.AppTopicKeepAliveFailed, p2p.AppEventKeepAliveFailed{SessionID: "s1", Consumer: true, Fatal: true})
+
+	recorder := httptest.NewRecorder()
+	exporter.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := ioutil.ReadAll(recorder.Body)
+	assert.NoError(t, err)
+
+	assert.Contains(t, string(body), `myst_settlements_total{result="success"} 1`)
+	assert.Contains(t, string(body), `myst_settlements_total{result="failure"} 1`)
+	assert.Contains(t, string(body), `myst_tokens_settled_total 2`)
+	assert.Contains(t, string(body), `myst_nat_traversals_total{result="failure",stage="hole_punching"} 1`)
+	assert.Contains(t, string(body), `myst_p2p_keepalive_failures_total{fatal="true",side="consumer"} 1`)
+	assert.Contains(t, string(body), `go_goroutines`)
+}
@@ -53,14 +53,23 @@ var _ Openvpn = wrapper{}
 
 // Options describes options which are required to start Node
 type Options struct {
-	Directories OptionsDirectory
+	Directories    OptionsDirectory
+	StorageBackend string
 
 	TequilapiAddress string
 	TequilapiPort    int
 	TequilapiEnabled bool
-	BindAddress      string
-	UI               OptionsUI
-	FeedbackURL      string
+	// TequilapiAuthRequired rejects Tequilapi requests without login or API token.
+	TequilapiAuthRequired bool
+	BindAddress           string
+	UI                    OptionsUI
+	FeedbackURL           string
+	MetricsAddress        string
+	// ControlAPIAddress is the tcp:// or unix:// address of gRPC control API, it is disabled if empty.
+	ControlAPIAddress string
+	// ControlAPITLSCert and ControlAPITLSKey are the certificate files gRPC control API is served with, TLS is disabled if empty.
+	ControlAPITLSCert string
+	ControlAPITLSKey  string
 
 	Keystore OptionsKeystore
 
@@ -108,17 +117,23 @@ func GetOptions() *Options {
 		},
 	}
 	return &Options{
-		Directories:      *GetOptionsDirectory(&network),
-		TequilapiAddress: config.GetString(config.FlagTequilapiAddress),
-		TequilapiPort:    config.GetInt(config.FlagTequilapiPort),
-		TequilapiEnabled: true,
-		BindAddress:      config.GetString(config.FlagBindAddress),
+		Directories:           *GetOptionsDirectory(&network),
+		StorageBackend:        config.GetString(config.FlagStorageBackend),
+		TequilapiAddress:      config.GetString(config.FlagTequilapiAddress),
+		TequilapiPort:         config.GetInt(config.FlagTequilapiPort),
+		TequilapiEnabled:      true,
+		TequilapiAuthRequired: config.GetBool(config.FlagTequilapiAuthRequired),
+		BindAddress:           config.GetString(config.FlagBindAddress),
 		UI: OptionsUI{
 			UIEnabled:     config.GetBool(config.FlagUIEnable),
 			UIBindAddress: config.GetString(config.FlagUIAddress),
 			UIPort:        config.GetInt(config.FlagUIPort),
 		},
-		FeedbackURL: config.GetString(config.FlagFeedbackURL),
+		FeedbackURL:       config.GetString(config.FlagFeedbackURL),
+		MetricsAddress:    config.GetString(config.FlagMetricsAddress),
+		ControlAPIAddress: config.GetString(config.FlagControlAPIAddress),
+		ControlAPITLSCert: config.GetString(config.FlagControlAPITLSCert),
+		ControlAPITLSKey:  config.GetString(config.FlagControlAPITLSKey),
 		Keystore: OptionsKeystore{
 			UseLightweight: config.GetBool(config.FlagKeystoreLightweight),
 		},
@@ -161,6 +176,7 @@ func GetOptions() *Options {
 			ConsumerDataLeewayMegabytes:    config.GetUInt64(config.FlagPaymentsConsumerDataLeewayMegabytes),
 			ProviderInvoiceFrequency:       config.GetDuration(config.FlagPaymentsProviderInvoiceFrequency),
 			MaxUnpaidInvoiceValue:          config.GetBigInt(config.FlagPaymentsMaxUnpaidInvoiceValue),
+			Simulator:                      config.GetBool(config.FlagPaymentsSimulator),
 		},
 		Hermes: OptionsHermes{
 			HermesID: config.GetString(config.FlagHermesID),
@@ -38,4 +38,5 @@ type OptionsPayments struct {
 	ConsumerDataLeewayMegabytes    uint64
 	ProviderInvoiceFrequency       time.Duration
 	MaxUnpaidInvoiceValue          *big.Int
+	Simulator                      bool
 }
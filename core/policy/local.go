@@ -0,0 +1,127 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v2"
+)
+
+// LocalPolicyPrefix marks policies, which are loaded from local files instead of TrustOracle ("local:corporate-apis")
+const LocalPolicyPrefix = "local:"
+
+var localPolicyExtensions = []string{".json", ".yaml", ".yml"}
+
+// IsLocalPolicy returns flag if given policy is loaded from local files
+func IsLocalPolicy(policy market.AccessPolicy) bool {
+	return strings.HasPrefix(policy.Source, LocalPolicyPrefix)
+}
+
+// LocalPolicies loads policy rule sets from JSON or YAML files in the directory, file name being the policy name
+type LocalPolicies struct {
+	dir string
+}
+
+// NewLocalPolicies creates instance of local policies loader
+func NewLocalPolicies(dir string) *LocalPolicies {
+	return &LocalPolicies{dir: dir}
+}
+
+// List gives rule sets of all valid local policies
+func (lp *LocalPolicies) List() ([]market.AccessPolicyRuleSet, error) {
+	files, err := ioutil.ReadDir(lp.dir)
+	if os.IsNotExist(err) {
+		return []market.AccessPolicyRuleSet{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list local policies: %w", err)
+	}
+
+	policiesRules := make([]market.AccessPolicyRuleSet, 0)
+	for _, file := range files {
+		ext := filepath.Ext(file.Name())
+		if file.IsDir() || !isLocalPolicyExtension(ext) {
+			continue
+		}
+
+		rules, _, err := lp.Load(strings.TrimSuffix(file.Name(), ext))
+		if err != nil {
+			log.Warn().Err(err).Msg("Skipping invalid local policy")
+			continue
+		}
+		policiesRules = append(policiesRules, rules)
+	}
+	return policiesRules, nil
+}
+
+// Load reads rule set of the named local policy.
+// Returned version changes with every modification of policy file.
+func (lp *LocalPolicies) Load(name string) (market.AccessPolicyRuleSet, string, error) {
+	if name == "" || filepath.Base(name) != name {
+		return market.AccessPolicyRuleSet{}, "", fmt.Errorf("invalid local policy name: %q", name)
+	}
+
+	for _, ext := range localPolicyExtensions {
+		path := filepath.Join(lp.dir, name+ext)
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return market.AccessPolicyRuleSet{}, "", fmt.Errorf("failed to read local policy %s: %w", name, err)
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return market.AccessPolicyRuleSet{}, "", fmt.Errorf("failed to read local policy %s: %w", name, err)
+		}
+
+		var rules market.AccessPolicyRuleSet
+		if ext == ".json" {
+			err = json.Unmarshal(data, &rules)
+		} else {
+			err = yaml.UnmarshalStrict(data, &rules)
+		}
+		if err != nil {
+			return market.AccessPolicyRuleSet{}, "", fmt.Errorf("failed to parse local policy %s: %w", name, err)
+		}
+		rules.ID = LocalPolicyPrefix + name
+
+		version := fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size())
+		return rules, version, nil
+	}
+
+	return market.AccessPolicyRuleSet{}, "", fmt.Errorf("local policy %s not found in %s", name, lp.dir)
+}
+
+func isLocalPolicyExtension(ext string) bool {
+	for _, policyExt := range localPolicyExtensions {
+		if ext == policyExt {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	localPolicyJSON = `{
+		"title": "Corporate APIs",
+		"allow": [{"type": "dst_cidr", "value": "93.184.216.0/24"}]
+	}`
+	localPolicyYAML = `
+title: Mail
+description: Blocks outgoing mail
+deny:
+  - type: dst_port
+    value: tcp:25
+`
+)
+
+func Test_LocalPolicies_List(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-policies")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writePolicyFile(t, dir, "corporate.json", localPolicyJSON)
+	writePolicyFile(t, dir, "mail.yaml", localPolicyYAML)
+	writePolicyFile(t, dir, "broken.yml", "allow: {")
+	writePolicyFile(t, dir, "notes.txt", "not a policy")
+
+	policiesRules, err := NewLocalPolicies(dir).List()
+	assert.NoError(t, err)
+	assert.Equal(t, []market.AccessPolicyRuleSet{
+		{
+			ID:    "local:corporate",
+			Title: "Corporate APIs",
+			Allow: []market.AccessRule{{Type: market.AccessPolicyTypeDestinationCIDR, Value: "93.184.216.0/24"}},
+		},
+		{
+			ID:          "local:mail",
+			Title:       "Mail",
+			Description: "Blocks outgoing mail",
+			Deny:        []market.AccessRule{{Type: market.AccessPolicyTypeDestinationPort, Value: "tcp:25"}},
+		},
+	}, policiesRules)
+
+	policiesRules, err = NewLocalPolicies(filepath.Join(dir, "missing")).List()
+	assert.NoError(t, err)
+	assert.Empty(t, policiesRules)
+}
+
+func Test_LocalPolicies_Load(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-policies")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writePolicyFile(t, dir, "corporate.json", localPolicyJSON)
+	local := NewLocalPolicies(dir)
+
+	rules, version, err := local.Load("corporate")
+	assert.NoError(t, err)
+	assert.Equal(t, "local:corporate", rules.ID)
+	assert.NotEmpty(t, version)
+
+	_, _, err = local.Load("mail")
+	assert.EqualError(t, err, "local policy mail not found in "+dir)
+
+	_, _, err = local.Load("../corporate")
+	assert.EqualError(t, err, `invalid local policy name: "../corporate"`)
+}
+
+func writePolicyFile(t *testing.T, dir, name, content string) {
+	err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+	assert.NoError(t, err)
+}
@@ -37,51 +37,72 @@ type policySubscription struct {
 	subscribers []*Repository
 }
 
-// Oracle represents async policy fetcher from TrustOracle
+// localReloadInterval is how often local policy files are checked for changes.
+const localReloadInterval = 5 * time.Second
+
+// Oracle represents async policy fetcher from TrustOracle and local policy files
 type Oracle struct {
-	client             *requests.HTTPClient
-	fetchURL           string
-	fetchInterval      time.Duration
-	fetchLock          sync.RWMutex
-	fetchSubscriptions []policySubscription
+	client              *requests.HTTPClient
+	fetchURL            string
+	fetchInterval       time.Duration
+	fetchLock           sync.RWMutex
+	fetchSubscriptions  []policySubscription
+	local               *LocalPolicies
+	localReloadInterval time.Duration
 
 	fetchShutdown     chan struct{}
 	fetchShutdownOnce sync.Once
 }
 
 // NewOracle create instance of policy fetcher
-func NewOracle(client *requests.HTTPClient, policyURL string, interval time.Duration) *Oracle {
+func NewOracle(client *requests.HTTPClient, policyURL string, interval time.Duration, local *LocalPolicies) *Oracle {
 	return &Oracle{
-		client:             client,
-		fetchURL:           policyURL,
-		fetchInterval:      interval,
-		fetchSubscriptions: make([]policySubscription, 0),
-		fetchShutdown:      make(chan struct{}),
+		client:              client,
+		fetchURL:            policyURL,
+		fetchInterval:       interval,
+		fetchSubscriptions:  make([]policySubscription, 0),
+		local:               local,
+		localReloadInterval: localReloadInterval,
+		fetchShutdown:       make(chan struct{}),
 	}
 }
 
 // Start begins fetching policies to subscribers
 func (pr *Oracle) Start() {
+	fetchTicker := time.NewTicker(pr.fetchInterval)
+	defer fetchTicker.Stop()
+	localTicker := time.NewTicker(pr.localReloadInterval)
+	defer localTicker.Stop()
+
 	for {
 		select {
 		case <-pr.fetchShutdown:
 			return
-		case <-time.After(pr.fetchInterval):
-			pr.fetchLock.Lock()
+		case <-fetchTicker.C:
+			pr.synchronise(false)
+		case <-localTicker.C:
+			pr.synchronise(true)
+		}
+	}
+}
 
-			subscriptionsActive := make([]policySubscription, len(pr.fetchSubscriptions))
-			copy(subscriptionsActive, pr.fetchSubscriptions)
+// synchronise fetches changes of either local or remote policies to subscribers
+func (pr *Oracle) synchronise(local bool) {
+	pr.fetchLock.Lock()
+	defer pr.fetchLock.Unlock()
 
-			for index := range subscriptionsActive {
-				if err := pr.fetchPolicyRules(&subscriptionsActive[index]); err != nil {
-					log.Warn().Err(err).Msg("synchronise fetch failed")
-				}
-			}
-			pr.fetchSubscriptions = subscriptionsActive
+	subscriptionsActive := make([]policySubscription, len(pr.fetchSubscriptions))
+	copy(subscriptionsActive, pr.fetchSubscriptions)
 
-			pr.fetchLock.Unlock()
+	for index := range subscriptionsActive {
+		if IsLocalPolicy(subscriptionsActive[index].policy) != local {
+			continue
+		}
+		if err := pr.fetchPolicyRules(&subscriptionsActive[index]); err != nil {
+			log.Warn().Err(err).Msg("synchronise fetch failed")
 		}
 	}
+	pr.fetchSubscriptions = subscriptionsActive
 }
 
 // Stop ends fetching policies to subscribers
@@ -91,8 +112,15 @@ func (pr *Oracle) Stop() {
 	})
 }
 
-// Policy converts given value to valid policy rule
+// Policy converts given value to valid policy rule, values with "local:" prefix refer to local policy files
 func (pr *Oracle) Policy(policyID string) market.AccessPolicy {
+	if strings.HasPrefix(policyID, LocalPolicyPrefix) {
+		return market.AccessPolicy{
+			ID:     policyID,
+			Source: policyID,
+		}
+	}
+
 	policyURL := pr.fetchURL
 	if !strings.HasSuffix(policyURL, "/") {
 		policyURL += "/"
@@ -137,6 +165,10 @@ func (pr *Oracle) SubscribePolicies(policies []market.AccessPolicy, repository *
 }
 
 func (pr *Oracle) fetchPolicyRules(subscription *policySubscription) error {
+	if IsLocalPolicy(subscription.policy) {
+		return pr.loadPolicyRules(subscription)
+	}
+
 	req, err := requests.NewGetRequest(subscription.policy.Source, "", nil)
 	if err != nil {
 		return errors.Wrap(err, "failed to create policy request")
@@ -171,3 +203,24 @@ func (pr *Oracle) fetchPolicyRules(subscription *policySubscription) error {
 
 	return nil
 }
+
+func (pr *Oracle) loadPolicyRules(subscription *policySubscription) error {
+	if pr.local == nil {
+		return fmt.Errorf("local policies are not available: %s", subscription.policy)
+	}
+
+	rules, version, err := pr.local.Load(strings.TrimPrefix(subscription.policy.Source, LocalPolicyPrefix))
+	if err != nil {
+		return err
+	}
+	if version == subscription.eTag {
+		return nil
+	}
+	subscription.eTag = version
+
+	for _, subscriber := range subscription.subscribers {
+		subscriber.SetPolicyRules(subscription.policy, rules)
+	}
+
+	return nil
+}
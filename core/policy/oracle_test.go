@@ -19,12 +19,15 @@ package policy
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/requests"
 	"github.com/stretchr/testify/assert"
@@ -178,8 +181,40 @@ func Test_Oracle_StartSyncsPolicies(t *testing.T) {
 	assert.Equal(t, []market.AccessPolicyRuleSet{policyOneRulesUpdated, policyTwoRulesUpdated}, policiesRules)
 }
 
+func Test_Oracle_Policy_Local(t *testing.T) {
+	repo := &Oracle{fetchURL: "http://policy.localhost"}
+	assert.Equal(
+		t,
+		market.AccessPolicy{ID: "local:corporate", Source: "local:corporate"},
+		repo.Policy("local:corporate"),
+	)
+}
+
+func Test_PolicyRepository_ReloadsLocalPolicies(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-policies")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writePolicyFile(t, dir, "corporate.json", `{"title": "One", "allow": []}`)
+
+	repo := NewRepository()
+	oracle := NewOracle(requests.NewHTTPClient("0.0.0.0", time.Second), "http://policy.localhost", time.Minute, NewLocalPolicies(dir))
+	oracle.localReloadInterval = 10 * time.Millisecond
+	err = oracle.SubscribePolicies([]market.AccessPolicy{oracle.Policy("local:corporate")}, repo)
+	assert.NoError(t, err)
+	assert.Equal(t, "One", repo.Rules()[0].Title)
+
+	go oracle.Start()
+	defer oracle.Stop()
+
+	writePolicyFile(t, dir, "corporate.json", `{"title": "One (updated)", "allow": [{"type": "identity", "value": "0x1"}]}`)
+	assert.Eventually(t, func() bool {
+		return repo.Rules()[0].Title == "One (updated)"
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.False(t, repo.IsIdentityAllowed(identity.FromAddress("0x2")))
+}
+
 func Test_PolicyRepository_StartMultipleTimes(t *testing.T) {
-	oracle := NewOracle(requests.NewHTTPClient("0.0.0.0", time.Second), "http://policy.localhost", time.Minute)
+	oracle := NewOracle(requests.NewHTTPClient("0.0.0.0", time.Second), "http://policy.localhost", time.Minute, nil)
 	go oracle.Start()
 	oracle.Stop()
 
@@ -192,6 +227,7 @@ func createEmptyOracle(mockServerURL string) *Oracle {
 		requests.NewHTTPClient("0.0.0.0", 100*time.Millisecond),
 		mockServerURL+"/",
 		time.Minute,
+		nil,
 	)
 }
 
@@ -200,6 +236,7 @@ func createFilledOracle(mockServerURL string, interval time.Duration, repo *Repo
 		requests.NewHTTPClient("0.0.0.0", time.Second),
 		mockServerURL+"/",
 		interval,
+		nil,
 	)
 	oracle.SubscribePolicies(
 		[]market.AccessPolicy{oracle.Policy("1"), oracle.Policy("2")},
@@ -35,6 +35,10 @@ type listItem struct {
 type Repository struct {
 	lock  sync.RWMutex
 	items []listItem
+
+	listenersLock  sync.Mutex
+	listeners      map[int]func()
+	lastListenerID int
 }
 
 // NewRepository create instance of policy repository
@@ -45,10 +49,9 @@ func NewRepository() *Repository {
 }
 
 // SetPolicyRules set policy and it's items to repository
+// Listeners registered with OnChange are notified once the rules are set.
 func (r *Repository) SetPolicyRules(policy market.AccessPolicy, policyRules market.AccessPolicyRuleSet) {
 	r.lock.Lock()
-	defer r.lock.Unlock()
-
 	item, err := r.findItemFor(policy)
 	if err != nil {
 		r.items = append(r.items, listItem{
@@ -58,6 +61,42 @@ func (r *Repository) SetPolicyRules(policy market.AccessPolicy, policyRules mark
 	} else {
 		item.rules = policyRules
 	}
+	r.lock.Unlock()
+
+	r.notifyListeners()
+}
+
+// OnChange registers function, which is called every time policy rules change. Returned function unregisters it.
+func (r *Repository) OnChange(listener func()) (unsubscribe func()) {
+	r.listenersLock.Lock()
+	defer r.listenersLock.Unlock()
+
+	if r.listeners == nil {
+		r.listeners = make(map[int]func())
+	}
+	r.lastListenerID++
+	id := r.lastListenerID
+	r.listeners[id] = listener
+
+	return func() {
+		r.listenersLock.Lock()
+		defer r.listenersLock.Unlock()
+
+		delete(r.listeners, id)
+	}
+}
+
+func (r *Repository) notifyListeners() {
+	r.listenersLock.Lock()
+	listeners := make([]func(), 0, len(r.listeners))
+	for _, listener := range r.listeners {
+		listeners = append(listeners, listener)
+	}
+	r.listenersLock.Unlock()
+
+	for _, listener := range listeners {
+		listener()
+	}
 }
 
 // Policies list policies in repository
@@ -118,67 +157,115 @@ func (r *Repository) Rules() []market.AccessPolicyRuleSet {
 
 // IsIdentityAllowed returns flag if given identity should be allowed by rules
 func (r *Repository) IsIdentityAllowed(identity identity.Identity) bool {
+	return r.isAllowed(func(rule market.AccessRule) (bool, bool) {
+		if rule.Type != market.AccessPolicyTypeIdentity {
+			return false, false
+		}
+		return true, identity.Address == rule.Value
+	})
+}
+
+// IsCountryAllowed returns flag if consumer from given country should be allowed by rules
+func (r *Repository) IsCountryAllowed(country string) bool {
+	return r.isAllowed(func(rule market.AccessRule) (bool, bool) {
+		if rule.Type != market.AccessPolicyTypeConsumerCountry {
+			return false, false
+		}
+		return true, strings.EqualFold(country, rule.Value)
+	})
+}
+
+// HasDNSRules returns flag if any DNS rules are applied
+func (r *Repository) HasDNSRules() bool {
+	return r.hasRules(market.AccessPolicyTypeDNSZone, market.AccessPolicyTypeDNSHostname)
+}
+
+// IsHostAllowed returns flag if given FQDN host should be allowed by rules
+func (r *Repository) IsHostAllowed(host string) bool {
+	return r.isAllowed(func(rule market.AccessRule) (bool, bool) {
+		return matchHost(rule, host)
+	})
+}
+
+// IsHostDenied returns flag if given FQDN host is explicitly denied by rules
+func (r *Repository) IsHostDenied(host string) bool {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
 
-	isAllowedByDefault := true
 	for _, item := range r.items {
-		for _, rule := range item.rules.Allow {
-			if rule.Type == market.AccessPolicyTypeIdentity {
-				isAllowedByDefault = false
-				if identity.Address == rule.Value {
-					return true
-				}
+		for _, rule := range item.rules.Deny {
+			if _, matches := matchHost(rule, host); matches {
+				return true
 			}
 		}
 	}
+	return false
+}
 
-	return isAllowedByDefault
+// matchHost returns whether the rule is a DNS rule and whether it matches given host.
+// Zones match on label boundaries, so "example.com" matches "cdn.example.com", but not "badexample.com".
+func matchHost(rule market.AccessRule, host string) (isDNSRule, matches bool) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	value := strings.ToLower(strings.TrimSuffix(rule.Value, "."))
+
+	switch rule.Type {
+	case market.AccessPolicyTypeDNSZone:
+		return true, host == value || strings.HasSuffix(host, "."+value)
+	case market.AccessPolicyTypeDNSHostname:
+		return true, host == value
+	}
+	return false, false
 }
 
-// HasDNSRules returns flag if any DNS rules are applied
-func (r *Repository) HasDNSRules() bool {
+// isAllowed checks rules using given matcher, which reports whether the rule is of the checked type and whether it matches.
+// Matching deny rule rejects, otherwise rules of the checked type, if any, must have matching allow rule.
+func (r *Repository) isAllowed(match func(rule market.AccessRule) (isChecked, matches bool)) bool {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
 
+	for _, item := range r.items {
+		for _, rule := range item.rules.Deny {
+			if _, matches := match(rule); matches {
+				return false
+			}
+		}
+	}
+
+	isAllowedByDefault := true
 	for _, item := range r.items {
 		for _, rule := range item.rules.Allow {
-			if rule.Type == market.AccessPolicyTypeDNSZone {
-				return true
+			isChecked, matches := match(rule)
+			if !isChecked {
+				continue
 			}
-			if rule.Type == market.AccessPolicyTypeDNSHostname {
+			isAllowedByDefault = false
+			if matches {
 				return true
 			}
 		}
 	}
 
-	return false
+	return isAllowedByDefault
 }
 
-// IsHostAllowed returns flag if given FQDN host should be allowed by rules
-func (r *Repository) IsHostAllowed(host string) bool {
+// hasRules returns flag if any allow or deny rules of given types are applied.
+func (r *Repository) hasRules(ruleTypes ...string) bool {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
 
-	isAllowedByDefault := true
 	for _, item := range r.items {
-		for _, rule := range item.rules.Allow {
-			if rule.Type == market.AccessPolicyTypeDNSZone {
-				isAllowedByDefault = false
-				if strings.HasSuffix(host, rule.Value) {
-					return true
-				}
-			}
-			if rule.Type == market.AccessPolicyTypeDNSHostname {
-				isAllowedByDefault = false
-				if host == rule.Value {
-					return true
+		for _, rules := range [][]market.AccessRule{item.rules.Allow, item.rules.Deny} {
+			for _, rule := range rules {
+				for _, ruleType := range ruleTypes {
+					if rule.Type == ruleType {
+						return true
+					}
 				}
 			}
 		}
 	}
 
-	return isAllowedByDefault
+	return false
 }
 
 func (r *Repository) findItemFor(policy market.AccessPolicy) (*listItem, error) {
@@ -125,6 +125,23 @@ func Test_Repository_SetPolicyRules(t *testing.T) {
 	assert.True(t, repo.IsIdentityAllowed(identity.FromAddress("0x2")))
 }
 
+func Test_Repository_OnChange(t *testing.T) {
+	repo := NewRepository()
+	changes := 0
+	unsubscribe := repo.OnChange(func() {
+		changes++
+		assert.Len(t, repo.Rules(), 1)
+	})
+
+	repo.SetPolicyRules(policyOne, policyOneRules)
+	repo.SetPolicyRules(policyOne, policyOneRules)
+	assert.Equal(t, 2, changes)
+
+	unsubscribe()
+	repo.SetPolicyRules(policyOne, policyOneRules)
+	assert.Equal(t, 2, changes)
+}
+
 func Test_Repository_Rules(t *testing.T) {
 	repo := createEmptyRepo()
 	assert.Equal(t, []market.AccessPolicyRuleSet{}, repo.Rules())
@@ -133,6 +150,79 @@ func Test_Repository_Rules(t *testing.T) {
 	assert.Equal(t, []market.AccessPolicyRuleSet{policyOneRules, policyTwoRules}, repo.Rules())
 }
 
+func Test_Repository_IsIdentityAllowed(t *testing.T) {
+	repo := createEmptyRepo()
+	assert.True(t, repo.IsIdentityAllowed(identity.FromAddress("0x1")))
+
+	repo = createFullRepo()
+	assert.True(t, repo.IsIdentityAllowed(identity.FromAddress("0x1")))
+	assert.False(t, repo.IsIdentityAllowed(identity.FromAddress("0x2")))
+
+	repo.SetPolicyRules(policyThree, market.AccessPolicyRuleSet{
+		ID: "3",
+		Deny: []market.AccessRule{
+			{Type: market.AccessPolicyTypeIdentity, Value: "0x1"},
+		},
+	})
+	assert.False(t, repo.IsIdentityAllowed(identity.FromAddress("0x1")))
+}
+
+func Test_Repository_IsCountryAllowed(t *testing.T) {
+	repo := createEmptyRepo()
+	assert.True(t, repo.IsCountryAllowed("LT"))
+	assert.True(t, repo.IsCountryAllowed(""))
+
+	repo.SetPolicyRules(policyOne, market.AccessPolicyRuleSet{
+		ID: "1",
+		Deny: []market.AccessRule{
+			{Type: market.AccessPolicyTypeConsumerCountry, Value: "US"},
+		},
+	})
+	assert.True(t, repo.IsCountryAllowed("LT"))
+	assert.True(t, repo.IsCountryAllowed(""))
+	assert.False(t, repo.IsCountryAllowed("us"))
+
+	repo.SetPolicyRules(policyTwo, market.AccessPolicyRuleSet{
+		ID: "2",
+		Allow: []market.AccessRule{
+			{Type: market.AccessPolicyTypeConsumerCountry, Value: "LT"},
+			{Type: market.AccessPolicyTypeConsumerCountry, Value: "US"},
+		},
+	})
+	assert.True(t, repo.IsCountryAllowed("LT"))
+	assert.False(t, repo.IsCountryAllowed("DE"))
+	assert.False(t, repo.IsCountryAllowed(""))
+	assert.False(t, repo.IsCountryAllowed("US"))
+}
+
+func Test_Repository_IsHostAllowed(t *testing.T) {
+	repo := createEmptyRepo()
+	assert.True(t, repo.IsHostAllowed("example.com"))
+
+	repo.SetPolicyRules(policyOne, market.AccessPolicyRuleSet{
+		ID: "1",
+		Allow: []market.AccessRule{
+			{Type: market.AccessPolicyTypeDNSZone, Value: "example.com"},
+			{Type: market.AccessPolicyTypeDNSHostname, Value: "ipinfo.io"},
+		},
+		Deny: []market.AccessRule{
+			{Type: market.AccessPolicyTypeDNSZone, Value: "ads.example.com"},
+		},
+	})
+	assert.True(t, repo.IsHostAllowed("example.com"))
+	assert.True(t, repo.IsHostAllowed("api.example.com"))
+	assert.True(t, repo.IsHostAllowed("API.Example.com."))
+	assert.True(t, repo.IsHostAllowed("ipinfo.io"))
+	assert.False(t, repo.IsHostAllowed("badexample.com"))
+	assert.False(t, repo.IsHostAllowed("www.ipinfo.io"))
+	assert.False(t, repo.IsHostAllowed("ads.example.com"))
+	assert.False(t, repo.IsHostAllowed("cdn.ads.example.com"))
+
+	assert.True(t, repo.IsHostDenied("cdn.ads.example.com"))
+	assert.False(t, repo.IsHostDenied("badads.example.com"))
+	assert.False(t, repo.IsHostDenied("api.example.com"))
+}
+
 func createEmptyRepo() *Repository {
 	return NewRepository()
 }
@@ -0,0 +1,281 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrDNSRulesUnenforced is returned when traffic is restricted by DNS zone or hostname rules,
+// but the provider DNS, which resolves the allowed hosts, is unavailable.
+var ErrDNSRulesUnenforced = errors.New("DNS zone and hostname rules can not be enforced without the provider DNS")
+
+// HasTrafficRules returns flag if any rules restricting VPN traffic destinations are applied
+func (r *Repository) HasTrafficRules() bool {
+	return r.hasRules(
+		market.AccessPolicyTypeDNSZone,
+		market.AccessPolicyTypeDNSHostname,
+		market.AccessPolicyTypeDestinationCIDR,
+		market.AccessPolicyTypeDestinationPort,
+	)
+}
+
+// ApplyTrafficRules blocks traffic of the VPN network which is not allowed by destination rules.
+// Traffic to allowed hosts is let through by the DNS handler once their names are resolved.
+// Firewall rules are re-applied every time policy rules change, until the returned function removes them.
+func (r *Repository) ApplyTrafficRules(fw firewall.IncomingTrafficFirewall, network net.IPNet) (firewall.IncomingRuleRemove, error) {
+	var lock sync.Mutex
+	remove, err := r.applyTrafficRules(fw, network)
+	if err != nil {
+		return nil, err
+	}
+
+	unsubscribe := r.OnChange(func() {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if remove == nil {
+			return
+		}
+		if err := remove(); err != nil {
+			log.Warn().Err(err).Msgf("Could not remove previous traffic rules of %s", network.String())
+		}
+		var applyErr error
+		remove, applyErr = r.applyTrafficRules(fw, network)
+		if applyErr == nil {
+			log.Info().Msgf("Traffic rules of %s updated", network.String())
+			return
+		}
+
+		// Nothing is let through, rather than traffic which new rules deny.
+		log.Error().Err(applyErr).Msgf("Could not apply updated traffic rules of %s, blocking all its traffic", network.String())
+		if remove, applyErr = fw.BlockIncomingTraffic(network); applyErr != nil {
+			log.Error().Err(applyErr).Msgf("Could not block traffic of %s", network.String())
+		}
+	})
+
+	return func() error {
+		unsubscribe()
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		if remove == nil {
+			return nil
+		}
+		err := remove()
+		remove = nil
+		return err
+	}, nil
+}
+
+func (r *Repository) applyTrafficRules(fw firewall.IncomingTrafficFirewall, network net.IPNet) (firewall.IncomingRuleRemove, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var allowRules, denyRules []firewall.TrafficRule
+	hasAllowRules := false
+	for _, item := range r.items {
+		for _, rule := range item.rules.Allow {
+			rules, err := parseTrafficRule(rule, network)
+			if err != nil {
+				return nil, err
+			}
+			allowRules = append(allowRules, rules...)
+			hasAllowRules = hasAllowRules || len(rules) > 0 ||
+				rule.Type == market.AccessPolicyTypeDNSZone || rule.Type == market.AccessPolicyTypeDNSHostname
+		}
+		for _, rule := range item.rules.Deny {
+			rules, err := parseTrafficRule(rule, network)
+			if err != nil {
+				return nil, err
+			}
+			denyRules = append(denyRules, rules...)
+		}
+	}
+	if !hasAllowRules {
+		// Only explicitly denied traffic is blocked.
+		allowRules = append(allowRules, firewall.TrafficRule{Source: &network})
+	}
+
+	var removers []firewall.IncomingRuleRemove
+	removeAll := func() error {
+		var lastErr error
+		for i := len(removers) - 1; i >= 0; i-- {
+			if err := removers[i](); err != nil {
+				lastErr = err
+			}
+		}
+		return lastErr
+	}
+
+	remove, err := fw.BlockIncomingTraffic(network)
+	if err != nil {
+		return nil, err
+	}
+	removers = append(removers, remove)
+
+	for _, rule := range denyRules {
+		remove, err := fw.DenyTraffic(rule)
+		if err != nil {
+			removeAll()
+			return nil, fmt.Errorf("could not deny traffic to %s: %w", rule, err)
+		}
+		removers = append(removers, remove)
+	}
+	for _, rule := range allowRules {
+		remove, err := fw.AllowTraffic(rule)
+		if err != nil {
+			removeAll()
+			return nil, fmt.Errorf("could not allow traffic to %s: %w", rule, err)
+		}
+		removers = append(removers, remove)
+	}
+
+	return removeAll, nil
+}
+
+// IsDestinationAllowed returns flag if connection to the host, resolved to the given IP, should be allowed by destination rules.
+// Rules are matched the same way the firewall applies them: matching deny rule rejects,
+// otherwise any matching allow rule accepts, unless there are none.
+func (r *Repository) IsDestinationAllowed(host string, ip net.IP, protocol string, portNum int) bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	match := func(rule market.AccessRule) (isDestinationRule, matches bool) {
+		switch rule.Type {
+		case market.AccessPolicyTypeDNSZone, market.AccessPolicyTypeDNSHostname:
+			if host == "" {
+				return true, false
+			}
+			return matchHost(rule, host)
+		case market.AccessPolicyTypeDestinationCIDR:
+			network, err := parseCIDR(rule.Value)
+			if err != nil {
+				return true, false
+			}
+			return true, network.Contains(ip)
+		case market.AccessPolicyTypeDestinationPort:
+			protocols, ports, err := parsePort(rule.Value)
+			if err != nil {
+				return true, false
+			}
+			for _, p := range protocols {
+				if p == protocol && portNum >= ports.Start && portNum <= ports.End {
+					return true, true
+				}
+			}
+			return true, false
+		}
+		return false, false
+	}
+
+	for _, item := range r.items {
+		for _, rule := range item.rules.Deny {
+			if _, matches := match(rule); matches {
+				return false
+			}
+		}
+	}
+
+	hasAllowRules := false
+	for _, item := range r.items {
+		for _, rule := range item.rules.Allow {
+			isDestinationRule, matches := match(rule)
+			if matches {
+				return true
+			}
+			hasAllowRules = hasAllowRules || isDestinationRule
+		}
+	}
+	return !hasAllowRules
+}
+
+// parseTrafficRule converts destination rule to firewall rules, other rule types are ignored.
+func parseTrafficRule(rule market.AccessRule, source net.IPNet) ([]firewall.TrafficRule, error) {
+	switch rule.Type {
+	case market.AccessPolicyTypeDestinationCIDR:
+		network, err := parseCIDR(rule.Value)
+		if err != nil {
+			return nil, err
+		}
+		return []firewall.TrafficRule{{Source: &source, Network: network}}, nil
+	case market.AccessPolicyTypeDestinationPort:
+		protocols, ports, err := parsePort(rule.Value)
+		if err != nil {
+			return nil, err
+		}
+		rules := make([]firewall.TrafficRule, len(protocols))
+		for i, protocol := range protocols {
+			rules[i] = firewall.TrafficRule{Source: &source, Protocol: protocol, Ports: ports}
+		}
+		return rules, nil
+	}
+	return nil, nil
+}
+
+func parseCIDR(value string) (*net.IPNet, error) {
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value).To4()
+		if ip == nil {
+			return nil, fmt.Errorf("invalid destination network: %s", value)
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(32, 32)}, nil
+	}
+
+	_, network, err := net.ParseCIDR(value)
+	if err != nil || network.IP.To4() == nil {
+		return nil, fmt.Errorf("invalid destination network: %s", value)
+	}
+	return network, nil
+}
+
+// parsePort parses "[protocol:]port[-port]" value, port rule without protocol applies to both TCP and UDP.
+func parsePort(value string) ([]string, *port.Range, error) {
+	protocols := []string{"tcp", "udp"}
+	ports := value
+	if i := strings.Index(value, ":"); i >= 0 {
+		protocol := strings.ToLower(value[:i])
+		if protocol != "tcp" && protocol != "udp" {
+			return nil, nil, fmt.Errorf("invalid destination port protocol: %s", value)
+		}
+		protocols = []string{protocol}
+		ports = value[i+1:]
+	}
+
+	bounds := strings.SplitN(ports, "-", 2)
+	if len(bounds) == 1 {
+		bounds = append(bounds, bounds[0])
+	}
+	start, errStart := strconv.Atoi(bounds[0])
+	end, errEnd := strconv.Atoi(bounds[1])
+	if errStart != nil || errEnd != nil || start < 1 || end > 65535 || start > end {
+		return nil, nil, fmt.Errorf("invalid destination port: %s", value)
+	}
+	return protocols, &port.Range{Start: start, End: end}, nil
+}
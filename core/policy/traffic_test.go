@@ -0,0 +1,226 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policy
+
+import (
+	"net"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockFirewall struct {
+	firewall.IncomingTrafficFirewall
+	rules []string
+}
+
+func (fw *mockFirewall) BlockIncomingTraffic(network net.IPNet) (firewall.IncomingRuleRemove, error) {
+	return fw.add("block " + network.String())
+}
+
+func (fw *mockFirewall) AllowTraffic(rule firewall.TrafficRule) (firewall.IncomingRuleRemove, error) {
+	return fw.add("allow " + rule.String())
+}
+
+func (fw *mockFirewall) DenyTraffic(rule firewall.TrafficRule) (firewall.IncomingRuleRemove, error) {
+	return fw.add("deny " + rule.String())
+}
+
+func (fw *mockFirewall) add(rule string) (firewall.IncomingRuleRemove, error) {
+	fw.rules = append(fw.rules, rule)
+	return func() error {
+		for i := range fw.rules {
+			if fw.rules[i] == rule {
+				fw.rules = append(fw.rules[:i], fw.rules[i+1:]...)
+				break
+			}
+		}
+		return nil
+	}, nil
+}
+
+var vpnNetwork = net.IPNet{IP: net.IPv4(10, 8, 0, 0).To4(), Mask: net.CIDRMask(24, 32)}
+
+func Test_Repository_HasTrafficRules(t *testing.T) {
+	repo := createFullRepo()
+	assert.True(t, repo.HasTrafficRules())
+
+	repo = NewRepository()
+	repo.SetPolicyRules(policyOne, policyOneRules)
+	assert.False(t, repo.HasTrafficRules())
+
+	repo.SetPolicyRules(policyTwo, market.AccessPolicyRuleSet{
+		ID:   "2",
+		Deny: []market.AccessRule{{Type: market.AccessPolicyTypeDestinationPort, Value: "25"}},
+	})
+	assert.True(t, repo.HasTrafficRules())
+}
+
+func Test_Repository_ApplyTrafficRules(t *testing.T) {
+	repo := NewRepository()
+	repo.SetPolicyRules(policyOne, market.AccessPolicyRuleSet{
+		ID: "1",
+		Allow: []market.AccessRule{
+			{Type: market.AccessPolicyTypeIdentity, Value: "0x1"},
+			{Type: market.AccessPolicyTypeDestinationCIDR, Value: "93.184.216.0/24"},
+			{Type: market.AccessPolicyTypeDestinationPort, Value: "tcp:443"},
+		},
+		Deny: []market.AccessRule{
+			{Type: market.AccessPolicyTypeDestinationCIDR, Value: "93.184.216.34"},
+			{Type: market.AccessPolicyTypeDestinationPort, Value: "8000-8100"},
+		},
+	})
+
+	fw := &mockFirewall{}
+	remove, err := repo.ApplyTrafficRules(fw, vpnNetwork)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"block 10.8.0.0/24",
+		"deny 93.184.216.34/32 from 10.8.0.0/24",
+		"deny any tcp ports 8000-8100 from 10.8.0.0/24",
+		"deny any udp ports 8000-8100 from 10.8.0.0/24",
+		"allow 93.184.216.0/24 from 10.8.0.0/24",
+		"allow any tcp ports 443-443 from 10.8.0.0/24",
+	}, fw.rules)
+
+	assert.NoError(t, remove())
+	assert.Empty(t, fw.rules)
+}
+
+func Test_Repository_ApplyTrafficRules_DenyOnly(t *testing.T) {
+	repo := NewRepository()
+	repo.SetPolicyRules(policyOne, market.AccessPolicyRuleSet{
+		ID:   "1",
+		Deny: []market.AccessRule{{Type: market.AccessPolicyTypeDestinationPort, Value: "udp:53"}},
+	})
+
+	fw := &mockFirewall{}
+	_, err := repo.ApplyTrafficRules(fw, vpnNetwork)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"block 10.8.0.0/24",
+		"deny any udp ports 53-53 from 10.8.0.0/24",
+		"allow any from 10.8.0.0/24",
+	}, fw.rules)
+}
+
+func Test_Repository_ApplyTrafficRules_InvalidRules(t *testing.T) {
+	for value, expectedErr := range map[string]string{
+		"dst_cidr:fd00::/8":       "invalid destination network: fd00::/8",
+		"dst_cidr:example.com":    "invalid destination network: example.com",
+		"dst_port:sctp:80":        "invalid destination port protocol: sctp:80",
+		"dst_port:tcp:0":          "invalid destination port: tcp:0",
+		"dst_port:9000-8000":      "invalid destination port: 9000-8000",
+		"dst_port:https":          "invalid destination port: https",
+		"dst_port:tcp:1000-70000": "invalid destination port: tcp:1000-70000",
+	} {
+		ruleType, ruleValue := value[:8], value[9:]
+		repo := NewRepository()
+		repo.SetPolicyRules(policyOne, market.AccessPolicyRuleSet{
+			ID:    "1",
+			Allow: []market.AccessRule{{Type: ruleType, Value: ruleValue}},
+		})
+
+		fw := &mockFirewall{}
+		_, err := repo.ApplyTrafficRules(fw, vpnNetwork)
+		assert.EqualError(t, err, expectedErr)
+		assert.Empty(t, fw.rules)
+	}
+}
+
+func Test_Repository_IsDestinationAllowed(t *testing.T) {
+	repo := NewRepository()
+	assert.True(t, repo.IsDestinationAllowed("example.com", net.ParseIP("93.184.216.34"), "tcp", 80))
+
+	repo.SetPolicyRules(policyOne, market.AccessPolicyRuleSet{
+		ID: "1",
+		Allow: []market.AccessRule{
+			{Type: market.AccessPolicyTypeIdentity, Value: "0x1"},
+			{Type: market.AccessPolicyTypeDNSZone, Value: "ipinfo.io"},
+			{Type: market.AccessPolicyTypeDestinationCIDR, Value: "93.184.216.0/24"},
+			{Type: market.AccessPolicyTypeDestinationPort, Value: "tcp:443"},
+		},
+		Deny: []market.AccessRule{
+			{Type: market.AccessPolicyTypeDNSHostname, Value: "blocked.ipinfo.io"},
+			{Type: market.AccessPolicyTypeDestinationCIDR, Value: "93.184.216.34"},
+			{Type: market.AccessPolicyTypeDestinationPort, Value: "8000-8100"},
+		},
+	})
+
+	ip := net.ParseIP("1.1.1.1")
+	assert.True(t, repo.IsDestinationAllowed("ipinfo.io", ip, "tcp", 80))
+	assert.True(t, repo.IsDestinationAllowed("", net.ParseIP("93.184.216.35"), "tcp", 80))
+	assert.True(t, repo.IsDestinationAllowed("", ip, "tcp", 443))
+	assert.False(t, repo.IsDestinationAllowed("", ip, "udp", 443))
+	assert.False(t, repo.IsDestinationAllowed("example.com", ip, "tcp", 80))
+	assert.False(t, repo.IsDestinationAllowed("blocked.ipinfo.io", ip, "tcp", 443))
+	assert.False(t, repo.IsDestinationAllowed("ipinfo.io", net.ParseIP("93.184.216.34"), "tcp", 443))
+	assert.False(t, repo.IsDestinationAllowed("ipinfo.io", ip, "tcp", 8080))
+}
+
+func Test_Repository_ApplyTrafficRules_ReappliesChangedRules(t *testing.T) {
+	repo := NewRepository()
+	repo.SetPolicyRules(policyOne, market.AccessPolicyRuleSet{
+		ID:   "1",
+		Deny: []market.AccessRule{{Type: market.AccessPolicyTypeDestinationPort, Value: "tcp:25"}},
+	})
+
+	fw := &mockFirewall{}
+	remove, err := repo.ApplyTrafficRules(fw, vpnNetwork)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"block 10.8.0.0/24",
+		"deny any tcp ports 25-25 from 10.8.0.0/24",
+		"allow any from 10.8.0.0/24",
+	}, fw.rules)
+
+	repo.SetPolicyRules(policyOne, market.AccessPolicyRuleSet{
+		ID:    "1",
+		Allow: []market.AccessRule{{Type: market.AccessPolicyTypeDestinationCIDR, Value: "93.184.216.0/24"}},
+	})
+	assert.Equal(t, []string{
+		"block 10.8.0.0/24",
+		"allow 93.184.216.0/24 from 10.8.0.0/24",
+	}, fw.rules)
+
+	assert.NoError(t, remove())
+	assert.Empty(t, fw.rules)
+
+	repo.SetPolicyRules(policyOne, market.AccessPolicyRuleSet{ID: "1"})
+	assert.Empty(t, fw.rules)
+}
+
+func Test_Repository_ApplyTrafficRules_BlocksTrafficWhenChangedRulesAreInvalid(t *testing.T) {
+	repo := NewRepository()
+	repo.SetPolicyRules(policyOne, market.AccessPolicyRuleSet{
+		ID:   "1",
+		Deny: []market.AccessRule{{Type: market.AccessPolicyTypeDestinationPort, Value: "tcp:25"}},
+	})
+
+	fw := &mockFirewall{}
+	_, err := repo.ApplyTrafficRules(fw, vpnNetwork)
+	assert.NoError(t, err)
+
+	repo.SetPolicyRules(policyOne, market.AccessPolicyRuleSet{
+		ID:    "1",
+		Allow: []market.AccessRule{{Type: market.AccessPolicyTypeDestinationCIDR, Value: "invalid"}},
+	})
+	assert.Equal(t, []string{"block 10.8.0.0/24"}, fw.rules)
+}
@@ -54,6 +54,7 @@ type DiscoveryFactory func() Discovery
 // Discovery registers the service to the discovery api periodically
 type Discovery interface {
 	Start(ownIdentity identity.Identity, proposal market.ServiceProposal)
+	Update(proposal market.ServiceProposal)
 	Stop()
 	Wait()
 }
@@ -70,6 +71,7 @@ func NewManager(
 	p2pListener p2p.Listener,
 	sessionManager func(service *Instance, channel p2p.Channel) *SessionManager,
 	statusStorage connectivity.StatusStorage,
+	pricer *Pricer,
 ) *Manager {
 	return &Manager{
 		serviceRegistry:  serviceRegistry,
@@ -80,6 +82,7 @@ func NewManager(
 		p2pListener:      p2pListener,
 		sessionManager:   sessionManager,
 		statusStorage:    statusStorage,
+		pricer:           pricer,
 	}
 }
 
@@ -95,18 +98,24 @@ type Manager struct {
 	p2pListener    p2p.Listener
 	sessionManager func(service *Instance, channel p2p.Channel) *SessionManager
 	statusStorage  connectivity.StatusStorage
+	pricer         *Pricer
 }
 
 // Start starts an instance of the given service type if knows one in service registry.
 // It passes the options to the start method of the service.
 // If an error occurs in the underlying service, the error is then returned.
-func (manager *Manager) Start(providerID identity.Identity, serviceType string, policyIDs []string, options Options, pm market.PaymentMethod) (id ID, err error) {
+// The service is charged by the given base price, unless the pricing schedule prices it otherwise.
+func (manager *Manager) Start(providerID identity.Identity, serviceType string, policyIDs []string, options Options, basePrice Price) (id ID, err error) {
 	service, proposal, err := manager.serviceRegistry.Create(serviceType, options)
 	if err != nil {
 		return id, err
 	}
 
-	proposal.SetPaymentMethod(pm)
+	price := manager.pricer.price(basePrice, 0)
+	proposal.SetPaymentMethod(manager.pricer.newPaymentMethod(price))
+	if o, ok := options.(BandwidthOptions); ok {
+		proposal.SetBandwidth(o.SessionBandwidthKbps())
+	}
 	proposal.SetAccessPolicies(nil)
 	policyRules := policy.NewRepository()
 	if len(policyIDs) > 0 {
@@ -157,6 +166,9 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 
 	manager.servicePool.Add(instance)
 
+	stopPricing := make(chan struct{})
+	go manager.pricer.watch(instance, basePrice, price, stopPricing)
+
 	go func() {
 		instance.setState(servicestate.Running)
 
@@ -165,6 +177,7 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 			log.Error().Err(serveErr).Msg("Service serve failed")
 		}
 
+		close(stopPricing)
 		stopP2PListener()
 
 		stopErr := manager.servicePool.Stop(id)
@@ -35,7 +35,7 @@ import (
 
 var (
 	serviceType      = "the-very-awesome-test-service-type"
-	mockPolicyOracle = policy.NewOracle(requests.NewHTTPClient("0.0.0.0", requests.DefaultTimeout), "http://policy.localhost/", 1*time.Minute)
+	mockPolicyOracle = policy.NewOracle(requests.NewHTTPClient("0.0.0.0", requests.DefaultTimeout), "http://policy.localhost/", 1*time.Minute, nil)
 )
 
 func init() {
@@ -58,8 +58,9 @@ func TestManager_StartRemovesServiceFromPoolIfServiceCrashes(t *testing.T) {
 		mocks.NewEventBus(),
 		mockPolicyOracle,
 		&mockP2PListener{}, nil, nil,
+		NewPricer(PricingSchedule{}, mockPaymentMethod, nil),
 	)
-	_, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil)
+	_, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, Price{})
 	assert.Nil(t, err)
 
 	discovery.Wait()
@@ -82,8 +83,9 @@ func TestManager_StartDoesNotCrashIfStoppedByUser(t *testing.T) {
 		mocks.NewEventBus(),
 		mockPolicyOracle,
 		&mockP2PListener{}, nil, nil,
+		NewPricer(PricingSchedule{}, mockPaymentMethod, nil),
 	)
-	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil)
+	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, Price{})
 	assert.Nil(t, err)
 	err = manager.Stop(id)
 	assert.Nil(t, err)
@@ -108,9 +110,10 @@ func TestManager_StopSendsEvent_SucceedsAndPublishesEvent(t *testing.T) {
 		eventBus,
 		mockPolicyOracle,
 		&mockP2PListener{}, nil, nil,
+		NewPricer(PricingSchedule{}, mockPaymentMethod, nil),
 	)
 
-	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil)
+	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, Price{})
 	assert.NoError(t, err)
 
 	services := manager.servicePool.List()
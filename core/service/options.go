@@ -17,5 +17,21 @@
 
 package service
 
+import "github.com/mysteriumnetwork/node/config"
+
 // Options represents any type of options for pluggable service
 type Options interface{}
+
+// BandwidthOptions is implemented by options of services which limit bandwidth of their sessions.
+type BandwidthOptions interface {
+	// SessionBandwidthKbps returns bandwidth limit of every session in Kbps, zero means unlimited.
+	SessionBandwidthKbps() uint64
+}
+
+// DefaultBandwidthKbps returns configured bandwidth limit of every session, zero when shaping is disabled.
+func DefaultBandwidthKbps() uint64 {
+	if !config.GetBool(config.FlagShaperEnabled) {
+		return 0
+	}
+	return config.GetUInt64(config.FlagShaperBandwidth)
+}
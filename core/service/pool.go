@@ -150,6 +150,7 @@ type Instance struct {
 	Options         Options
 	service         Service
 	Proposal        market.ServiceProposal
+	proposalLock    sync.RWMutex
 	policies        *policy.Repository
 	discovery       Discovery
 	eventPublisher  Publisher
@@ -162,6 +163,31 @@ func (i *Instance) Service() Service {
 	return i.service
 }
 
+// CopyProposal returns a copy of the proposal the service is currently announced with.
+func (i *Instance) CopyProposal() market.ServiceProposal {
+	i.proposalLock.RLock()
+	defer i.proposalLock.RUnlock()
+	return i.Proposal
+}
+
+// setPaymentMethod changes the price of the service and announces it with the next proposal serial number,
+// so that consumers agreed to the previous price have to fetch the proposal again.
+func (i *Instance) setPaymentMethod(pm market.PaymentMethod) {
+	i.proposalLock.Lock()
+	i.Proposal.ID++
+	i.Proposal.SetPaymentMethod(pm)
+	proposal := i.Proposal
+	i.proposalLock.Unlock()
+
+	if i.discovery != nil {
+		i.discovery.Update(proposal)
+	}
+
+	i.stateLock.RLock()
+	defer i.stateLock.RUnlock()
+	i.eventPublisher.Publish(servicestate.AppTopicServiceStatus, i.toEvent())
+}
+
 // Policies returns service policies of the running service instance.
 func (i *Instance) Policies() *policy.Repository {
 	return i.policies
@@ -25,11 +25,13 @@ import (
 	"testing"
 
 	"github.com/mysteriumnetwork/node/mocks"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/stretchr/testify/assert"
 )
 
 type mockService struct {
-	killErr error
+	killErr       error
+	sessionTunnel chan sessionEvent.TunnelContext
 }
 
 type mockPublisher struct {
@@ -54,6 +56,9 @@ func (mr *mockService) Stop() error {
 }
 
 func (mr *mockService) ProvideConfig(_ string, _ json.RawMessage, _ *net.UDPConn) (*ConfigParams, error) {
+	if mr.sessionTunnel != nil {
+		return &ConfigParams{SessionTunnel: mr.sessionTunnel}, nil
+	}
 	return &ConfigParams{}, nil
 }
 
@@ -0,0 +1,241 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/rs/zerolog/log"
+)
+
+// Price holds the rates the service charges its consumers.
+type Price struct {
+	PerGB     *big.Int
+	PerMinute *big.Int
+}
+
+// Equal checks whether both prices charge the same rates.
+func (p Price) Equal(other Price) bool {
+	return cmpAmount(p.PerGB, other.PerGB) == 0 && cmpAmount(p.PerMinute, other.PerMinute) == 0
+}
+
+func cmpAmount(a, b *big.Int) int {
+	if a == nil {
+		a = new(big.Int)
+	}
+	if b == nil {
+		b = new(big.Int)
+	}
+	return a.Cmp(b)
+}
+
+// PaymentMethodFactory creates the payment method charging the given price.
+type PaymentMethodFactory func(price Price) market.PaymentMethod
+
+// PricingWindow is the time of the local day having its own price.
+// Window wraps around midnight if it ends before it starts.
+type PricingWindow struct {
+	From, To time.Duration // Offsets from the local midnight.
+	Price    Price
+}
+
+func (w PricingWindow) contains(offset time.Duration) bool {
+	if w.From <= w.To {
+		return offset >= w.From && offset < w.To
+	}
+	return offset >= w.From || offset < w.To
+}
+
+// ParsePricingWindow parses the window formatted as HH:MM-HH:MM/<price per GiB>/<price per minute>, prices given in MYST.
+func ParsePricingWindow(s string) (PricingWindow, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 3 {
+		return PricingWindow{}, fmt.Errorf("pricing window %q is not formatted as HH:MM-HH:MM/<price per GiB>/<price per minute>", s)
+	}
+	bounds := strings.Split(parts[0], "-")
+	if len(bounds) != 2 {
+		return PricingWindow{}, fmt.Errorf("pricing window %q has invalid time range", s)
+	}
+
+	var window PricingWindow
+	var err error
+	if window.From, err = parseTimeOfDay(bounds[0]); err != nil {
+		return PricingWindow{}, fmt.Errorf("pricing window %q has invalid start: %w", s, err)
+	}
+	if window.To, err = parseTimeOfDay(bounds[1]); err != nil {
+		return PricingWindow{}, fmt.Errorf("pricing window %q has invalid end: %w", s, err)
+	}
+	if window.Price.PerGB, err = parseMyst(parts[1]); err != nil {
+		return PricingWindow{}, fmt.Errorf("pricing window %q has invalid price per GiB: %w", s, err)
+	}
+	if window.Price.PerMinute, err = parseMyst(parts[2]); err != nil {
+		return PricingWindow{}, fmt.Errorf("pricing window %q has invalid price per minute: %w", s, err)
+	}
+	return window, nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func parseMyst(s string) (*big.Int, error) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return nil, err
+	}
+	if value < 0 {
+		return nil, fmt.Errorf("price can not be negative")
+	}
+	return crypto.FloatToBigMyst(value), nil
+}
+
+// DemandPricing raises the price as active sessions of the service approach its capacity.
+type DemandPricing struct {
+	// Capacity is the count of sessions the service is able to serve, demand pricing is disabled if zero.
+	Capacity int
+	// Threshold is the share of the capacity in use, above which the price starts to rise.
+	Threshold float64
+	// MaxMultiplier is applied to the price once the service is at its capacity.
+	MaxMultiplier float64
+}
+
+func (d DemandPricing) multiplier(sessions int) float64 {
+	if d.Capacity <= 0 || d.Threshold >= 1 || d.MaxMultiplier <= 1 {
+		return 1
+	}
+	load := float64(sessions) / float64(d.Capacity)
+	if load <= d.Threshold {
+		return 1
+	}
+	if load > 1 {
+		load = 1
+	}
+	return 1 + (d.MaxMultiplier-1)*(load-d.Threshold)/(1-d.Threshold)
+}
+
+// PricingSchedule changes the price of the running services by the time of the day and their demand.
+type PricingSchedule struct {
+	Windows []PricingWindow
+	Demand  DemandPricing
+}
+
+// IsDynamic checks whether the price of the service may change while it is running.
+func (s PricingSchedule) IsDynamic() bool {
+	return len(s.Windows) > 0 || s.Demand.multiplier(s.Demand.Capacity) != 1
+}
+
+// Price returns the price of the service at the given time with the given count of active sessions.
+// The base price is used outside the windows of the schedule.
+func (s PricingSchedule) Price(base Price, at time.Time, sessions int) Price {
+	price := base
+	midnight := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+	offset := at.Sub(midnight)
+	for _, window := range s.Windows {
+		if window.contains(offset) {
+			price = window.Price
+			break
+		}
+	}
+
+	multiplier := s.Demand.multiplier(sessions)
+	if multiplier == 1 {
+		return price
+	}
+	return Price{
+		PerGB:     multiplyAmount(price.PerGB, multiplier),
+		PerMinute: multiplyAmount(price.PerMinute, multiplier),
+	}
+}
+
+func multiplyAmount(amount *big.Int, multiplier float64) *big.Int {
+	if amount == nil {
+		return nil
+	}
+	result, _ := new(big.Float).Mul(new(big.Float).SetInt(amount), big.NewFloat(multiplier)).Int(nil)
+	return result
+}
+
+// Pricer keeps the proposals of the running services priced by the pricing schedule.
+type Pricer struct {
+	schedule         PricingSchedule
+	newPaymentMethod PaymentMethodFactory
+	sessions         *SessionPool
+	interval         time.Duration
+}
+
+// NewPricer returns new pricer of the services, counting their active sessions in the given pool.
+func NewPricer(schedule PricingSchedule, newPaymentMethod PaymentMethodFactory, sessions *SessionPool) *Pricer {
+	return &Pricer{
+		schedule:         schedule,
+		newPaymentMethod: newPaymentMethod,
+		sessions:         sessions,
+		interval:         30 * time.Second,
+	}
+}
+
+func (p *Pricer) price(base Price, sessions int) Price {
+	return p.schedule.Price(base, time.Now(), sessions)
+}
+
+// watch re-prices the service instance until it is stopped, announcing the proposal with the new price on every change.
+// Running sessions keep the price they were started with.
+func (p *Pricer) watch(instance *Instance, base, current Price, stop <-chan struct{}) {
+	if !p.schedule.IsDynamic() {
+		return
+	}
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		price := p.price(base, p.activeSessions(instance.ID))
+		if price.Equal(current) {
+			continue
+		}
+		log.Info().Msgf("Changing price of service %s to %s per GiB and %s per minute", instance.ID, price.PerGB, price.PerMinute)
+		current = price
+		instance.setPaymentMethod(p.newPaymentMethod(price))
+	}
+}
+
+func (p *Pricer) activeSessions(id ID) (count int) {
+	if p.sessions == nil {
+		return 0
+	}
+	for _, session := range p.sessions.GetAll() {
+		if session.ServiceID == string(id) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/trace"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePricingWindow(t *testing.T) {
+	window, err := ParsePricingWindow("18:00-02:30/0.3/0.00002")
+	require.NoError(t, err)
+	assert.Equal(t, 18*time.Hour, window.From)
+	assert.Equal(t, 2*time.Hour+30*time.Minute, window.To)
+	assert.Equal(t, crypto.FloatToBigMyst(0.3), window.Price.PerGB)
+	assert.Equal(t, crypto.FloatToBigMyst(0.00002), window.Price.PerMinute)
+
+	for _, value := range []string{
+		"18:00-23:00/0.3",
+		"18:00/0.3/0.00002",
+		"25:00-23:00/0.3/0.00002",
+		"18:00-23:00/abc/0.00002",
+		"18:00-23:00/0.3/-1",
+	} {
+		_, err := ParsePricingWindow(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestPricingSchedule_Price(t *testing.T) {
+	base := Price{PerGB: big.NewInt(100), PerMinute: big.NewInt(10)}
+	peak := Price{PerGB: big.NewInt(200), PerMinute: big.NewInt(20)}
+	night := Price{PerGB: big.NewInt(50), PerMinute: big.NewInt(5)}
+	schedule := PricingSchedule{
+		Windows: []PricingWindow{
+			{From: 18 * time.Hour, To: 22 * time.Hour, Price: peak},
+			{From: 22 * time.Hour, To: 6 * time.Hour, Price: night},
+		},
+	}
+	day := func(hour, min int) time.Time {
+		return time.Date(2020, 11, 5, hour, min, 0, 0, time.Local)
+	}
+
+	assert.True(t, schedule.IsDynamic())
+	assert.Equal(t, base, schedule.Price(base, day(12, 0), 0))
+	assert.Equal(t, peak, schedule.Price(base, day(18, 0), 0))
+	assert.Equal(t, peak, schedule.Price(base, day(21, 59), 0))
+	assert.Equal(t, night, schedule.Price(base, day(22, 0), 0))
+	assert.Equal(t, night, schedule.Price(base, day(3, 0), 0))
+	assert.Equal(t, base, schedule.Price(base, day(6, 0), 0))
+}
+
+func TestPricingSchedule_PriceByDemand(t *testing.T) {
+	base := Price{PerGB: big.NewInt(100), PerMinute: big.NewInt(10)}
+	schedule := PricingSchedule{
+		Demand: DemandPricing{Capacity: 4, Threshold: 0.5, MaxMultiplier: 3},
+	}
+	now := time.Now()
+
+	assert.True(t, schedule.IsDynamic())
+	assert.Equal(t, base, schedule.Price(base, now, 0))
+	assert.Equal(t, base, schedule.Price(base, now, 2))
+	assert.Equal(t, Price{PerGB: big.NewInt(200), PerMinute: big.NewInt(20)}, schedule.Price(base, now, 3))
+	assert.Equal(t, Price{PerGB: big.NewInt(300), PerMinute: big.NewInt(30)}, schedule.Price(base, now, 4))
+	assert.Equal(t, Price{PerGB: big.NewInt(300), PerMinute: big.NewInt(30)}, schedule.Price(base, now, 6))
+
+	assert.False(t, PricingSchedule{}.IsDynamic())
+	assert.False(t, PricingSchedule{Demand: DemandPricing{Capacity: 10, Threshold: 0.5, MaxMultiplier: 1}}.IsDynamic())
+}
+
+func TestPricer_WatchAnnouncesPriceChange(t *testing.T) {
+	sessions := NewSessionPool(mocks.NewEventBus())
+	discovery := &mockDiscovery{}
+	publisher := &mockPublisher{}
+	instance := &Instance{
+		ID:             "service",
+		Proposal:       proposalMock,
+		discovery:      discovery,
+		eventPublisher: publisher,
+	}
+	instance.Proposal.ID = 1
+	oldSession, err := NewSession(instance, &pb.SessionRequest{}, trace.NewTracer(""))
+	require.NoError(t, err)
+	sessions.Add(oldSession)
+
+	pricer := NewPricer(PricingSchedule{
+		Demand: DemandPricing{Capacity: 1, Threshold: 0.5, MaxMultiplier: 2},
+	}, mockPaymentMethod, sessions)
+	pricer.interval = time.Millisecond
+	base := Price{PerGB: big.NewInt(100), PerMinute: big.NewInt(10)}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go pricer.watch(instance, base, base, stop)
+
+	assert.Eventually(t, func() bool {
+		return instance.CopyProposal().ID == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	proposal := instance.CopyProposal()
+	assert.Equal(t, big.NewInt(200), proposal.PaymentMethod.GetPrice().Amount)
+	discovery.mu.Lock()
+	assert.Equal(t, []market.ServiceProposal{proposal}, discovery.updated)
+	discovery.mu.Unlock()
+	// Running session keeps the proposal it was started with.
+	assert.Equal(t, 1, oldSession.Proposal.ID)
+}
@@ -97,6 +97,16 @@ func (s *Session) toEvent(status event.Status) event.AppEventSession {
 	}
 }
 
+func (s *Session) toTunnelEvent(status event.Status, tunnel event.TunnelContext) event.AppEventSessionTunnel {
+	sessionEvent := s.toEvent(status)
+	return event.AppEventSessionTunnel{
+		Status:  status,
+		Service: sessionEvent.Service,
+		Session: sessionEvent.Session,
+		Tunnel:  tunnel,
+	}
+}
+
 // NewSession creates a blank new session with an ID.
 func NewSession(service *Instance, request *pb.SessionRequest, tracer *trace.Tracer) (*Session, error) {
 	uid, err := uuid.NewV4()
@@ -114,7 +124,7 @@ func NewSession(service *Instance, request *pb.SessionRequest, tracer *trace.Tra
 		ConsumerID:       identity.FromAddress(request.GetConsumer().GetId()),
 		ConsumerLocation: consumerLocation,
 		HermesID:         common.HexToAddress(request.GetConsumer().GetHermesID()),
-		Proposal:         service.Proposal,
+		Proposal:         service.CopyProposal(),
 		ServiceID:        string(service.ID),
 		CreatedAt:        time.Now().UTC(),
 		request:          request,
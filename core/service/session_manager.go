@@ -54,6 +54,8 @@ type IDGenerator func() (session.ID, error)
 type ConfigParams struct {
 	SessionServiceConfig   ServiceConfiguration
 	SessionDestroyCallback DestroyCallback
+	// SessionTunnel reports provider side tunnel of the session once it is known, nil for services without one
+	SessionTunnel <-chan sevent.TunnelContext
 }
 
 // ServiceConfiguration defines service configuration from underlying transport mechanism to be passed to remote party
@@ -103,8 +105,8 @@ type PromiseProcessor interface {
 	Stop() error
 }
 
-// PaymentEngineFactory creates a new instance of payment engine
-type PaymentEngineFactory func(providerID, consumerID identity.Identity, chainID int64, hermesID common.Address, sessionID string, exchangeChan chan crypto.ExchangeMessage) (PaymentEngine, error)
+// PaymentEngineFactory creates a new instance of payment engine, charging the consumer by the proposal agreed for the session
+type PaymentEngineFactory func(providerID, consumerID identity.Identity, chainID int64, hermesID common.Address, sessionID string, proposal market.ServiceProposal, exchangeChan chan crypto.ExchangeMessage) (PaymentEngine, error)
 
 // PaymentEngine is responsible for interacting with the consumer in regard to payments.
 type PaymentEngine interface {
@@ -219,7 +221,7 @@ func (manager *SessionManager) startSession(session *Session) error {
 }
 
 func (manager *SessionManager) validateSession(session *Session) error {
-	if manager.service.Proposal.ID != int(session.request.GetProposalID()) {
+	if session.Proposal.ID != int(session.request.GetProposalID()) {
 		return ErrorInvalidProposal
 	}
 
@@ -227,6 +229,10 @@ func (manager *SessionManager) validateSession(session *Session) error {
 		return fmt.Errorf("consumer identity is not allowed: %s", session.ConsumerID.Address)
 	}
 
+	if !manager.service.Policies().IsCountryAllowed(session.ConsumerLocation.Country) {
+		return fmt.Errorf("consumer country is not allowed: %s", session.ConsumerLocation.Country)
+	}
+
 	return nil
 }
 
@@ -266,7 +272,7 @@ func (manager *SessionManager) paymentLoop(session *Session) error {
 	log.Info().Msg("Using new payments")
 
 	chainID := config.GetInt64(config.FlagChainID)
-	engine, err := manager.paymentEngineFactory(manager.service.ProviderID, session.ConsumerID, chainID, session.HermesID, string(session.ID), manager.paymentEngineChan)
+	engine, err := manager.paymentEngineFactory(manager.service.ProviderID, session.ConsumerID, chainID, session.HermesID, string(session.ID), session.Proposal, manager.paymentEngineChan)
 	if err != nil {
 		return err
 	}
@@ -309,6 +315,10 @@ func (manager *SessionManager) providerService(session *Session, channel p2p.Cha
 		})
 	}
 
+	if config.SessionTunnel != nil {
+		go manager.tunnelLoop(session, config.SessionTunnel)
+	}
+
 	data, err := json.Marshal(config.SessionServiceConfig)
 	if err != nil {
 		return pb.SessionResponse{}, fmt.Errorf("cannot pack session %s service config: %w", string(session.ID), err)
@@ -321,6 +331,27 @@ func (manager *SessionManager) providerService(session *Session, channel p2p.Cha
 	}, nil
 }
 
+// tunnelLoop publishes tunnel changes of the session until it is closed.
+func (manager *SessionManager) tunnelLoop(sess *Session, tunnels <-chan sevent.TunnelContext) {
+	var current *sevent.TunnelContext
+	for {
+		select {
+		case <-sess.Done():
+			if current != nil {
+				manager.publisher.Publish(sevent.AppTopicSessionTunnel, sess.toTunnelEvent(sevent.RemovedStatus, *current))
+			}
+			return
+		case tunnel, ok := <-tunnels:
+			if !ok {
+				tunnels = nil
+				continue
+			}
+			current = &tunnel
+			manager.publisher.Publish(sevent.AppTopicSessionTunnel, sess.toTunnelEvent(sevent.CreatedStatus, tunnel))
+		}
+	}
+}
+
 func (manager *SessionManager) keepAliveLoop(sess *Session, channel p2p.Channel) {
 	// Register handler for handling p2p keep alive pings from consumer.
 	channel.Handle(p2p.TopicKeepAlive, func(c p2p.Context) error {
@@ -346,6 +377,10 @@ func (manager *SessionManager) keepAliveLoop(sess *Session, channel p2p.Channel)
 			if err := manager.sendKeepAlivePing(channel, sess.ID); err != nil {
 				log.Err(err).Msgf("Failed to send p2p keepalive ping. SessionID=%s", sess.ID)
 				errCount++
+				manager.publisher.Publish(p2p.AppTopicKeepAliveFailed, p2p.AppEventKeepAliveFailed{
+					SessionID: string(sess.ID),
+					Fatal:     errCount == manager.config.KeepAlive.MaxSendErrCount,
+				})
 				if errCount == manager.config.KeepAlive.MaxSendErrCount {
 					log.Error().Msgf("Max p2p keepalive err count reached, closing p2p channel. SessionID=%s", sess.ID)
 					channel.Close()
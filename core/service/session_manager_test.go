@@ -151,6 +151,59 @@ func TestManager_Start_StoresSession(t *testing.T) {
 	}, 2*time.Second, 10*time.Millisecond)
 }
 
+func TestManager_Start_PublishesSessionTunnel(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	tunnels := make(chan sessionEvent.TunnelContext, 1)
+	service := NewInstance(
+		identity.FromAddress(currentProposal.ProviderID),
+		currentProposal.ServiceType,
+		struct{}{},
+		currentProposal,
+		servicestate.Running,
+		&mockService{sessionTunnel: tunnels},
+		policy.NewRepository(),
+		&mockDiscovery{},
+	)
+	manager := newManager(service, sessionStore, publisher, &mockBalanceTracker{})
+
+	_, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+		},
+		ProposalID: int64(currentProposalID),
+	})
+	assert.NoError(t, err)
+
+	tunnel := sessionEvent.TunnelContext{Interface: "myst0", IP: net.ParseIP("10.182.0.2")}
+	tunnels <- tunnel
+
+	tunnelEvents := func(status sessionEvent.Status) []sessionEvent.AppEventSessionTunnel {
+		var events []sessionEvent.AppEventSessionTunnel
+		for _, v := range publisher.GetEventHistory() {
+			if e, ok := v.Event.(sessionEvent.AppEventSessionTunnel); ok && e.Status == status {
+				events = append(events, e)
+			}
+		}
+		return events
+	}
+	assert.Eventually(t, func() bool {
+		return len(tunnelEvents(sessionEvent.CreatedStatus)) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	session := sessionStore.GetAll()[0]
+	created := tunnelEvents(sessionEvent.CreatedStatus)[0]
+	assert.Equal(t, string(session.ID), created.Session.ID)
+	assert.Equal(t, tunnel, created.Tunnel)
+
+	session.Close()
+	assert.Eventually(t, func() bool {
+		removed := tunnelEvents(sessionEvent.RemovedStatus)
+		return len(removed) == 1 && removed[0].Tunnel.Interface == "myst0"
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
 func TestManager_Start_DisconnectsOnPaymentError(t *testing.T) {
 	publisher := mocks.NewEventBus()
 	sessionStore := NewSessionPool(publisher)
@@ -272,6 +325,41 @@ func TestManager_Start_RejectsUnknownProposal(t *testing.T) {
 	}, 2*time.Second, 10*time.Millisecond)
 }
 
+func TestManager_Start_RejectsDeniedConsumerCountry(t *testing.T) {
+	policies := policy.NewRepository()
+	policies.SetPolicyRules(
+		market.AccessPolicy{ID: "countries"},
+		market.AccessPolicyRuleSet{
+			ID:    "countries",
+			Allow: []market.AccessRule{{Type: market.AccessPolicyTypeConsumerCountry, Value: "LT"}},
+		},
+	)
+	service := NewInstance(
+		identity.FromAddress(currentProposal.ProviderID),
+		currentProposal.ServiceType,
+		struct{}{},
+		currentProposal,
+		servicestate.Running,
+		&mockService{},
+		policies,
+		&mockDiscovery{},
+	)
+	sessionStore := NewSessionPool(mocks.NewEventBus())
+	manager := newManager(service, sessionStore, mocks.NewEventBus(), &mockBalanceTracker{})
+
+	_, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+			Location: &pb.LocationInfo{Country: "US"},
+		},
+		ProposalID: int64(currentProposalID),
+	})
+
+	assert.EqualError(t, err, "consumer country is not allowed: US")
+	assert.Len(t, sessionStore.GetAll(), 0)
+}
+
 type MockNatEventTracker struct {
 }
 
@@ -337,7 +425,7 @@ func newManager(service *Instance, sessions *SessionPool, publisher publisher, p
 	return NewSessionManager(
 		service,
 		sessions,
-		func(_, _ identity.Identity, _ int64, _ common.Address, _ string, _ chan crypto.ExchangeMessage) (PaymentEngine, error) {
+		func(_, _ identity.Identity, _ int64, _ common.Address, _ string, _ market.ServiceProposal, _ chan crypto.ExchangeMessage) (PaymentEngine, error) {
 			return paymentEngine, nil
 		},
 		&MockNatEventTracker{},
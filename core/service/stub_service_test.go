@@ -24,6 +24,8 @@ import (
 
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/money"
 )
 
 var _ Service = &serviceFake{}
@@ -57,12 +59,20 @@ func (service *serviceFake) ProvideConfig(_ string, _ json.RawMessage, _ *net.UD
 }
 
 type mockDiscovery struct {
-	wg sync.WaitGroup
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	updated []market.ServiceProposal
 }
 
 func (mds *mockDiscovery) Start(ownIdentity identity.Identity, proposal market.ServiceProposal) {
 	mds.wg.Add(1)
 }
+func (mds *mockDiscovery) Update(proposal market.ServiceProposal) {
+	mds.mu.Lock()
+	defer mds.mu.Unlock()
+	mds.updated = append(mds.updated, proposal)
+}
+
 func (mds *mockDiscovery) Stop() {
 	mds.wg.Done()
 }
@@ -77,3 +87,7 @@ func MockDiscoveryFactoryFunc(ds Discovery) DiscoveryFactory {
 		return ds
 	}
 }
+
+func mockPaymentMethod(price Price) market.PaymentMethod {
+	return &mocks.PaymentMethod{Price: money.NewMoney(price.PerGB, money.CurrencyMyst)}
+}
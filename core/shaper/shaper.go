@@ -1,5 +1,3 @@
-// +build !linux
-
 /*
  * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
  *
@@ -20,26 +18,65 @@
 package shaper
 
 import (
-	"github.com/mysteriumnetwork/node/config"
+	"net"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/session/event"
 	"github.com/rs/zerolog/log"
 )
 
-// noopShaper does not shaping
-type noopShaper struct {
+// limiter limits bandwidth of a single consumer tunnel on the interface.
+type limiter interface {
+	// limit applies the limit to traffic of all the networks and returns a function removing it.
+	limit(interfaceName string, networks []net.IPNet, kbps uint64) (release func(), err error)
 }
 
-func create(_ eventListener) *noopShaper {
-	return &noopShaper{}
+// SessionShaper limits bandwidth of every service session to the one advertised in its proposal.
+type SessionShaper struct {
+	limiter limiter
+
+	lock     sync.Mutex
+	releases map[string]func()
 }
 
-// Start noop
-func (noopShaper) Start(_ string) error {
-	if config.GetBool(config.FlagShaperEnabled) {
-		log.Warn().Msgf("Flag %q is only supported under linux", config.FlagShaperEnabled.Name)
+// New creates a session traffic shaper (linux) or no-op.
+func New() *SessionShaper {
+	return &SessionShaper{
+		limiter:  newLimiter(),
+		releases: make(map[string]func()),
 	}
-	return nil
 }
 
-// Clear noop
-func (noopShaper) Clear(_ string) {
+// HandleSessionTunnel applies or removes bandwidth limits of the session tunnel.
+func (s *SessionShaper) HandleSessionTunnel(e event.AppEventSessionTunnel) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	sessionID := e.Session.ID
+	if release, ok := s.releases[sessionID]; ok {
+		release()
+		delete(s.releases, sessionID)
+	}
+
+	if e.Status != event.CreatedStatus {
+		return
+	}
+
+	kbps := e.Session.Proposal.BandwidthKbps
+	if kbps == 0 || e.Tunnel.IP == nil {
+		return
+	}
+
+	networks := []net.IPNet{{IP: e.Tunnel.IP, Mask: net.CIDRMask(32, 32)}}
+	if e.Tunnel.IPv6Network.IP != nil {
+		networks = append(networks, e.Tunnel.IPv6Network)
+	}
+
+	release, err := s.limiter.limit(e.Tunnel.Interface, networks, kbps)
+	if err != nil {
+		log.Error().Err(err).Msgf("Could not limit bandwidth of session %s", sessionID)
+		return
+	}
+	log.Info().Msgf("Limited bandwidth of session %s to %d Kbps", sessionID, kbps)
+	s.releases[sessionID] = release
 }
@@ -18,60 +18,140 @@
 package shaper
 
 import (
-	"github.com/mysteriumnetwork/go-wondershaper/wondershaper"
-	"github.com/mysteriumnetwork/node/config"
-	"github.com/pkg/errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/utils/cmdutil"
 	"github.com/rs/zerolog/log"
 )
 
-const limitKbps = 5000
+// minBurstKB is the smallest policer burst, it has to fit a few full size packets.
+const minBurstKB = 16
+
+// tcLimiter limits bandwidth with HTB classes for downlink and ingress policers for uplink.
+// Every limited tunnel gets its own class, every network of the tunnel gets its own filters.
+// Downlink of all networks shares the class, uplink is policed for IPv4 and IPv6 separately.
+type tcLimiter struct {
+	exec func(args ...string) error
 
-type linuxShaper struct {
-	ws          *wondershaper.Shaper
-	listener    eventListener
-	listenTopic string
+	lock   sync.Mutex
+	lastID uint16
+	// limited holds the number of limited tunnels on every interface
+	limited map[string]int
 }
 
-func create(listener eventListener) *linuxShaper {
-	ws := wondershaper.New()
-	ws.Stdout = log.Logger
-	ws.Stderr = log.Logger
-	return &linuxShaper{
-		ws:          ws,
-		listener:    listener,
-		listenTopic: config.AppTopicConfig(config.FlagShaperEnabled.Name),
+func newLimiter() *tcLimiter {
+	return &tcLimiter{
+		exec:    cmdutil.SudoExec,
+		limited: make(map[string]int),
 	}
 }
 
-// Start applies shaping configuration on the specified interface and then continuously ensures it.
-func (s *linuxShaper) Start(interfaceName string) error {
-	applyLimits := func() error {
-		s.ws.Clear(interfaceName)
+func (l *tcLimiter) limit(interfaceName string, networks []net.IPNet, kbps uint64) (func(), error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
 
-		if config.GetBool(config.FlagShaperEnabled) {
-			err := s.ws.LimitDownlink(interfaceName, limitKbps)
-			if err != nil {
-				log.Error().Err(err).Msg("Could not limit download speed")
-				return err
-			}
-			err = s.ws.LimitUplink(interfaceName, limitKbps)
-			if err != nil {
-				log.Error().Err(err).Msg("Could not limit upload speed")
-				return err
+	if l.limited[interfaceName] == 0 {
+		if err := l.setupInterface(interfaceName); err != nil {
+			return nil, err
+		}
+	}
+
+	id := l.nextID()
+
+	rate := fmt.Sprintf("%dkbit", kbps)
+	burst := kbps / 8 / 10
+	if burst < minBurstKB {
+		burst = minBurstKB
+	}
+	classID := fmt.Sprintf("1:%x", id)
+
+	rules := [][]string{
+		{"tc", "class", "add", "dev", interfaceName, "parent", "1:", "classid", classID, "htb", "rate", rate},
+	}
+	cleanup := [][]string{
+		{"tc", "class", "del", "dev", interfaceName, "parent", "1:", "classid", classID},
+	}
+	for i, network := range networks {
+		// Filters of different protocols can not share the priority.
+		prio := fmt.Sprint(id)
+		if i > 0 {
+			prio = fmt.Sprint(l.nextID())
+		}
+		protocol, match := "ip", "ip"
+		if network.IP.To4() == nil {
+			protocol, match = "ipv6", "ip6"
+		}
+		addr := network.String()
+
+		rules = append(rules,
+			[]string{"tc", "filter", "add", "dev", interfaceName, "parent", "1:", "protocol", protocol, "prio", prio, "u32", "match", match, "dst", addr, "flowid", classID},
+			[]string{"tc", "filter", "add", "dev", interfaceName, "parent", "ffff:", "protocol", protocol, "prio", prio, "u32", "match", match, "src", addr,
+				"police", "rate", rate, "burst", fmt.Sprintf("%dk", burst), "drop", "flowid", ":1"},
+		)
+		cleanup = append([][]string{
+			{"tc", "filter", "del", "dev", interfaceName, "parent", "ffff:", "protocol", protocol, "prio", prio},
+			{"tc", "filter", "del", "dev", interfaceName, "parent", "1:", "protocol", protocol, "prio", prio},
+		}, cleanup...)
+	}
+	for i, rule := range rules {
+		if err := l.exec(rule...); err != nil {
+			l.run(cleanup[len(cleanup)-i:])
+			if l.limited[interfaceName] == 0 {
+				l.clearInterface(interfaceName)
 			}
+			return nil, fmt.Errorf("could not limit bandwidth of %s on %s: %w", networks[0].IP, interfaceName, err)
 		}
-		return nil
 	}
+	l.limited[interfaceName]++
+
+	release := func() {
+		l.lock.Lock()
+		defer l.lock.Unlock()
 
-	err := s.listener.SubscribeAsync(s.listenTopic, applyLimits)
-	if err != nil {
-		return errors.Wrap(err, "could not subscribe to topic: "+s.listenTopic)
+		l.run(cleanup)
+		l.limited[interfaceName]--
+		if l.limited[interfaceName] == 0 {
+			delete(l.limited, interfaceName)
+			l.clearInterface(interfaceName)
+		}
 	}
+	return release, nil
+}
 
-	return applyLimits()
+// nextID returns the next class and filter priority number, zero is not a valid one.
+func (l *tcLimiter) nextID() uint16 {
+	l.lastID++
+	if l.lastID == 0 {
+		l.lastID = 1
+	}
+	return l.lastID
 }
 
-// Clear clears shaping rules.
-func (s *linuxShaper) Clear(interfaceName string) {
-	s.ws.Clear(interfaceName)
+func (l *tcLimiter) setupInterface(interfaceName string) error {
+	if err := l.exec("tc", "qdisc", "add", "dev", interfaceName, "root", "handle", "1:", "htb"); err != nil {
+		return fmt.Errorf("could not add root qdisc on %s: %w", interfaceName, err)
+	}
+	if err := l.exec("tc", "qdisc", "add", "dev", interfaceName, "handle", "ffff:", "ingress"); err != nil {
+		l.run([][]string{{"tc", "qdisc", "del", "dev", interfaceName, "root"}})
+		return fmt.Errorf("could not add ingress qdisc on %s: %w", interfaceName, err)
+	}
+	return nil
+}
+
+func (l *tcLimiter) clearInterface(interfaceName string) {
+	l.run([][]string{
+		{"tc", "qdisc", "del", "dev", interfaceName, "ingress"},
+		{"tc", "qdisc", "del", "dev", interfaceName, "root"},
+	})
+}
+
+// run executes cleanup commands, these fail when the interface is already gone with the session.
+func (l *tcLimiter) run(commands [][]string) {
+	for _, command := range commands {
+		if err := l.exec(command...); err != nil {
+			log.Debug().Err(err).Msg("Could not remove bandwidth limit")
+		}
+	}
 }
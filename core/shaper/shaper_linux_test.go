@@ -0,0 +1,134 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package shaper
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type execRecorder struct {
+	commands []string
+	failOn   string
+}
+
+func (r *execRecorder) exec(args ...string) error {
+	command := strings.Join(args, " ")
+	r.commands = append(r.commands, command)
+	if r.failOn != "" && strings.HasPrefix(command, r.failOn) {
+		return errors.New("exit status 2")
+	}
+	return nil
+}
+
+func networks(cidrs ...string) (networks []net.IPNet) {
+	for _, cidr := range cidrs {
+		_, network, _ := net.ParseCIDR(cidr)
+		networks = append(networks, *network)
+	}
+	return networks
+}
+
+func TestTcLimiter_Limit(t *testing.T) {
+	recorder := &execRecorder{}
+	limiter := &tcLimiter{exec: recorder.exec, limited: make(map[string]int)}
+
+	release1, err := limiter.limit("tun0", networks("10.8.0.2/32"), 5000)
+	assert.NoError(t, err)
+	release2, err := limiter.limit("tun0", networks("10.8.0.3/32"), 100)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"tc qdisc add dev tun0 root handle 1: htb",
+		"tc qdisc add dev tun0 handle ffff: ingress",
+		"tc class add dev tun0 parent 1: classid 1:1 htb rate 5000kbit",
+		"tc filter add dev tun0 parent 1: protocol ip prio 1 u32 match ip dst 10.8.0.2/32 flowid 1:1",
+		"tc filter add dev tun0 parent ffff: protocol ip prio 1 u32 match ip src 10.8.0.2/32 police rate 5000kbit burst 62k drop flowid :1",
+		"tc class add dev tun0 parent 1: classid 1:2 htb rate 100kbit",
+		"tc filter add dev tun0 parent 1: protocol ip prio 2 u32 match ip dst 10.8.0.3/32 flowid 1:2",
+		"tc filter add dev tun0 parent ffff: protocol ip prio 2 u32 match ip src 10.8.0.3/32 police rate 100kbit burst 16k drop flowid :1",
+	}, recorder.commands)
+
+	recorder.commands = nil
+	release1()
+	release2()
+	assert.Equal(t, []string{
+		"tc filter del dev tun0 parent ffff: protocol ip prio 1",
+		"tc filter del dev tun0 parent 1: protocol ip prio 1",
+		"tc class del dev tun0 parent 1: classid 1:1",
+		"tc filter del dev tun0 parent ffff: protocol ip prio 2",
+		"tc filter del dev tun0 parent 1: protocol ip prio 2",
+		"tc class del dev tun0 parent 1: classid 1:2",
+		"tc qdisc del dev tun0 ingress",
+		"tc qdisc del dev tun0 root",
+	}, recorder.commands)
+	assert.Empty(t, limiter.limited)
+}
+
+func TestTcLimiter_Limit_CleansUpOnFailure(t *testing.T) {
+	recorder := &execRecorder{failOn: "tc filter add dev wg0 parent ffff:"}
+	limiter := &tcLimiter{exec: recorder.exec, limited: make(map[string]int)}
+
+	_, err := limiter.limit("wg0", networks("10.182.0.2/32"), 5000)
+	assert.Error(t, err)
+	assert.Equal(t, []string{
+		"tc qdisc add dev wg0 root handle 1: htb",
+		"tc qdisc add dev wg0 handle ffff: ingress",
+		"tc class add dev wg0 parent 1: classid 1:1 htb rate 5000kbit",
+		"tc filter add dev wg0 parent 1: protocol ip prio 1 u32 match ip dst 10.182.0.2/32 flowid 1:1",
+		"tc filter add dev wg0 parent ffff: protocol ip prio 1 u32 match ip src 10.182.0.2/32 police rate 5000kbit burst 62k drop flowid :1",
+		"tc filter del dev wg0 parent 1: protocol ip prio 1",
+		"tc class del dev wg0 parent 1: classid 1:1",
+		"tc qdisc del dev wg0 ingress",
+		"tc qdisc del dev wg0 root",
+	}, recorder.commands)
+	assert.Empty(t, limiter.limited)
+}
+
+func TestTcLimiter_Limit_IPv6(t *testing.T) {
+	recorder := &execRecorder{}
+	limiter := &tcLimiter{exec: recorder.exec, limited: make(map[string]int)}
+
+	release, err := limiter.limit("wg0", networks("10.182.0.2/32", "fd10:182:0:1::/64"), 5000)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"tc qdisc add dev wg0 root handle 1: htb",
+		"tc qdisc add dev wg0 handle ffff: ingress",
+		"tc class add dev wg0 parent 1: classid 1:1 htb rate 5000kbit",
+		"tc filter add dev wg0 parent 1: protocol ip prio 1 u32 match ip dst 10.182.0.2/32 flowid 1:1",
+		"tc filter add dev wg0 parent ffff: protocol ip prio 1 u32 match ip src 10.182.0.2/32 police rate 5000kbit burst 62k drop flowid :1",
+		"tc filter add dev wg0 parent 1: protocol ipv6 prio 2 u32 match ip6 dst fd10:182:0:1::/64 flowid 1:1",
+		"tc filter add dev wg0 parent ffff: protocol ipv6 prio 2 u32 match ip6 src fd10:182:0:1::/64 police rate 5000kbit burst 62k drop flowid :1",
+	}, recorder.commands)
+
+	recorder.commands = nil
+	release()
+	assert.Equal(t, []string{
+		"tc filter del dev wg0 parent ffff: protocol ipv6 prio 2",
+		"tc filter del dev wg0 parent 1: protocol ipv6 prio 2",
+		"tc filter del dev wg0 parent ffff: protocol ip prio 1",
+		"tc filter del dev wg0 parent 1: protocol ip prio 1",
+		"tc class del dev wg0 parent 1: classid 1:1",
+		"tc qdisc del dev wg0 ingress",
+		"tc qdisc del dev wg0 root",
+	}, recorder.commands)
+	assert.Empty(t, limiter.limited)
+}
@@ -1,3 +1,5 @@
+// +build !linux
+
 /*
  * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
  *
@@ -17,19 +19,19 @@
 
 package shaper
 
-// Shaper shapes traffic on a network interface.
-type Shaper interface {
-	// Start applies shaping configuration on the specified interface and then continuously ensures it.
-	Start(interfaceName string) error
-	// Clear clears shaping rules.
-	Clear(interfaceName string)
+import (
+	"errors"
+	"net"
+)
+
+// noopLimiter does no shaping
+type noopLimiter struct {
 }
 
-type eventListener interface {
-	SubscribeAsync(topic string, fn interface{}) error
+func newLimiter() *noopLimiter {
+	return &noopLimiter{}
 }
 
-// New creates a traffic shaper (linux) or no-op.
-func New(listener eventListener) (shaper Shaper) {
-	return create(listener)
+func (noopLimiter) limit(_ string, _ []net.IPNet, _ uint64) (func(), error) {
+	return nil, errors.New("session bandwidth limits are only supported under linux")
 }
@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package shaper
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session/event"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockLimiter struct {
+	limited []string
+}
+
+func (l *mockLimiter) limit(interfaceName string, networks []net.IPNet, kbps uint64) (func(), error) {
+	limit := interfaceName
+	for i := range networks {
+		limit += " " + networks[i].String()
+	}
+	limit += fmt.Sprintf(" %d", kbps)
+	l.limited = append(l.limited, limit)
+	return func() {
+		for i := range l.limited {
+			if l.limited[i] == limit {
+				l.limited = append(l.limited[:i], l.limited[i+1:]...)
+				return
+			}
+		}
+	}, nil
+}
+
+func tunnelEvent(status event.Status, sessionID string, kbps uint64, ip string) event.AppEventSessionTunnel {
+	return event.AppEventSessionTunnel{
+		Status: status,
+		Session: event.SessionContext{
+			ID:       sessionID,
+			Proposal: market.ServiceProposal{BandwidthKbps: kbps},
+		},
+		Tunnel: event.TunnelContext{Interface: "tun0", IP: net.ParseIP(ip)},
+	}
+}
+
+func TestSessionShaper_HandleSessionTunnel(t *testing.T) {
+	limiter := &mockLimiter{}
+	shaper := &SessionShaper{limiter: limiter, releases: make(map[string]func())}
+
+	shaper.HandleSessionTunnel(tunnelEvent(event.CreatedStatus, "1", 5000, "10.8.0.2"))
+	shaper.HandleSessionTunnel(tunnelEvent(event.CreatedStatus, "2", 0, "10.8.0.3"))
+	assert.Equal(t, []string{"tun0 10.8.0.2/32 5000"}, limiter.limited)
+
+	// Tunnel IP of the session changed.
+	shaper.HandleSessionTunnel(tunnelEvent(event.CreatedStatus, "1", 5000, "10.8.0.4"))
+	assert.Equal(t, []string{"tun0 10.8.0.4/32 5000"}, limiter.limited)
+
+	shaper.HandleSessionTunnel(tunnelEvent(event.RemovedStatus, "2", 0, "10.8.0.3"))
+	shaper.HandleSessionTunnel(tunnelEvent(event.RemovedStatus, "1", 5000, "10.8.0.4"))
+	assert.Empty(t, limiter.limited)
+	assert.Empty(t, shaper.releases)
+}
+
+func TestSessionShaper_HandleSessionTunnel_IPv6(t *testing.T) {
+	limiter := &mockLimiter{}
+	shaper := &SessionShaper{limiter: limiter, releases: make(map[string]func())}
+
+	e := tunnelEvent(event.CreatedStatus, "1", 5000, "10.182.0.2")
+	_, network, _ := net.ParseCIDR("fd10:182:0:1::/64")
+	e.Tunnel.IPv6Network = *network
+	shaper.HandleSessionTunnel(e)
+	assert.Equal(t, []string{"tun0 10.182.0.2/32 fd10:182:0:1::/64 5000"}, limiter.limited)
+}
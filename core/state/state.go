@@ -244,7 +244,7 @@ func (k *Keeper) updateServices() {
 			Type:                 v.Type,
 			Options:              v.Options,
 			Status:               string(v.State()),
-			Proposal:             contract.NewProposalDTO(v.Proposal),
+			Proposal:             contract.NewProposalDTO(v.CopyProposal()),
 			ConnectionStatistics: match.ConnectionStatistics,
 		}
 		i++
@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package backend
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrations/history"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrator"
+)
+
+const (
+	// BoltDB keeps the data in BoltDB database, it is the default backend
+	BoltDB = "boltdb"
+	// SQLite keeps the data in SQLite database, the data of BoltDB is imported on the first start.
+	// It is only available in nodes built with cgo.
+	SQLite = "sqlite"
+)
+
+// ErrSQLiteUnavailable is returned when SQLite backend is requested from the node built without cgo, which SQLite driver requires.
+var ErrSQLiteUnavailable = errors.New("sqlite storage backend is not available in this build as it requires cgo, use boltdb instead")
+
+// Open opens the storage of the given backend in the given directory and brings it up to date with migrations.
+func Open(backend, dir string) (storage.Storage, error) {
+	switch backend {
+	case "", BoltDB:
+		db, err := boltdb.NewStorage(dir)
+		if err != nil {
+			return nil, err
+		}
+		if err := migrator.NewMigrator(db).RunMigrations(history.Sequence); err != nil {
+			db.Close()
+			return nil, err
+		}
+		return db, nil
+	case SQLite:
+		return openSQLite(dir)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", backend)
+	}
+}
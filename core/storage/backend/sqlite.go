@@ -0,0 +1,38 @@
+// +build cgo
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package backend
+
+import (
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/core/storage/sqlite"
+)
+
+func openSQLite(dir string) (storage.Storage, error) {
+	db, err := sqlite.NewStorage(dir)
+	if err != nil {
+		return nil, err
+	}
+	sequence := append(sqlite.Sequence[:len(sqlite.Sequence):len(sqlite.Sequence)], sqlite.ImportBoltDB(dir))
+	if err := db.RunMigrations(sequence); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
@@ -18,10 +18,19 @@
 package boltdb
 
 import (
+	"bytes"
+	"encoding/json"
+	"math/big"
 	"path/filepath"
+	"reflect"
+	"time"
 
 	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
 	"github.com/pkg/errors"
+	"go.etcd.io/bbolt"
+
+	"github.com/mysteriumnetwork/node/core/storage"
 )
 
 // Bolt is a wrapper around boltdb
@@ -29,6 +38,8 @@ type Bolt struct {
 	db *storm.DB
 }
 
+var _ storage.Storage = (*Bolt)(nil)
+
 // NewStorage creates a new BoltDB storage for service promises
 func NewStorage(path string) (*Bolt, error) {
 	return openDB(filepath.Join(path, "myst.db"))
@@ -80,6 +91,192 @@ func (b *Bolt) GetLast(bucket string, to interface{}) error {
 	return b.db.From(bucket).Select().Reverse().First(to)
 }
 
+// FindValues fetches values of the bucket matching all the conditions
+func (b *Bolt) FindValues(bucket string, where []storage.Condition, to interface{}) error {
+	ref := reflect.ValueOf(to)
+	if ref.Kind() != reflect.Ptr || ref.Elem().Kind() != reflect.Slice {
+		return errors.New("provided target must be a pointer to a slice")
+	}
+	elemType := ref.Elem().Type().Elem()
+
+	matcher, err := toMatcher(where)
+	if err != nil {
+		return err
+	}
+
+	results := reflect.MakeSlice(ref.Elem().Type(), 0, 0)
+	err = b.db.Bolt.View(func(tx *bbolt.Tx) error {
+		bb := tx.Bucket([]byte(bucket))
+		if bb == nil {
+			return nil
+		}
+
+		return bb.ForEach(func(k, v []byte) error {
+			if v == nil || bytes.HasPrefix(k, []byte("__storm")) {
+				return nil
+			}
+
+			value := reflect.New(elemType)
+			if err := b.db.Codec().Unmarshal(v, value.Interface()); err != nil {
+				return err
+			}
+			ok, err := matcher.Match(value.Elem().Interface())
+			if err != nil || !ok {
+				return err
+			}
+			results = reflect.Append(results, value.Elem())
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	ref.Elem().Set(results)
+	return nil
+}
+
+// Find fetches structs of the bucket selected by the query, returns ErrNotFound when there are none
+func (b *Bolt) Find(bucket string, query storage.Query, to interface{}) error {
+	matcher, err := toMatcher(query.Where)
+	if err != nil {
+		return err
+	}
+
+	sq := b.db.From(bucket).Select(matcher)
+	if query.OrderBy != "" {
+		sq = sq.OrderBy(query.OrderBy)
+	}
+	if query.Reverse {
+		sq = sq.Reverse()
+	}
+	if query.Limit > 0 {
+		sq = sq.Limit(query.Limit)
+	}
+	return sq.Find(to)
+}
+
+// Aggregate groups the structs of the record type and sums their fields, streaming the structs one by one.
+func (b *Bolt) Aggregate(bucket string, record interface{}, aggregation storage.Aggregation) ([]storage.Group, error) {
+	matcher, err := toMatcher(aggregation.Where)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	groups := make([]storage.Group, 0)
+	groupIndex := make(map[string]int)
+	err = b.db.From(bucket).Select(matcher).Each(record, func(r interface{}) error {
+		value := reflect.Indirect(reflect.ValueOf(r))
+
+		var values []interface{}
+		for _, fieldName := range aggregation.GroupBy {
+			field, err := structField(value, fieldName)
+			if err != nil {
+				return err
+			}
+			if t, ok := field.Interface().(time.Time); ok {
+				values = append(values, t.UTC().Truncate(24*time.Hour))
+			} else {
+				values = append(values, field.Interface())
+			}
+		}
+		key, err := json.Marshal(values)
+		if err != nil {
+			return err
+		}
+		index, ok := groupIndex[string(key)]
+		if !ok {
+			index = len(groups)
+			groupIndex[string(key)] = index
+			group := storage.Group{Values: values, Sums: make([]*big.Int, len(aggregation.Sum))}
+			for i := range group.Sums {
+				group.Sums[i] = new(big.Int)
+			}
+			groups = append(groups, group)
+		}
+
+		group := &groups[index]
+		group.Count++
+		for i, sum := range aggregation.Sum {
+			addend, err := sumOf(value, sum, now)
+			if err != nil {
+				return err
+			}
+			group.Sums[i].Add(group.Sums[i], addend)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, storm.ErrNotFound) {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func structField(value reflect.Value, fieldName string) (reflect.Value, error) {
+	field := value.FieldByName(fieldName)
+	if !field.IsValid() {
+		return reflect.Value{}, errors.Errorf("field %s not found", fieldName)
+	}
+	return field, nil
+}
+
+// sumOf returns the value of the summed field, or the duration between the fields rounded to milliseconds.
+func sumOf(value reflect.Value, sum storage.Sum, now time.Time) (*big.Int, error) {
+	field, err := structField(value, sum.Field)
+	if err != nil {
+		return nil, err
+	}
+
+	if sum.Since != "" {
+		sinceField, err := structField(value, sum.Since)
+		if err != nil {
+			return nil, err
+		}
+		until, ok := field.Interface().(time.Time)
+		since, sinceOK := sinceField.Interface().(time.Time)
+		if !ok || !sinceOK {
+			return nil, errors.Errorf("duration fields %s and %s must be times", sum.Since, sum.Field)
+		}
+		if until.IsZero() {
+			until = now
+		}
+		return big.NewInt(int64(until.Sub(since).Round(time.Millisecond))), nil
+	}
+
+	switch v := field.Interface().(type) {
+	case *big.Int:
+		if v == nil {
+			return new(big.Int), nil
+		}
+		return v, nil
+	}
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(field.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(field.Uint()), nil
+	}
+	return nil, errors.Errorf("field %s can not be summed", sum.Field)
+}
+
+func toMatcher(where []storage.Condition) (q.Matcher, error) {
+	matchers := make([]q.Matcher, 0, len(where))
+	for _, condition := range where {
+		switch condition.Operator {
+		case storage.OperatorEqual:
+			matchers = append(matchers, q.Eq(condition.Field, condition.Value))
+		case storage.OperatorGreaterOrEqual:
+			matchers = append(matchers, q.Gte(condition.Field, condition.Value))
+		case storage.OperatorLessOrEqual:
+			matchers = append(matchers, q.Lte(condition.Field, condition.Value))
+		default:
+			return nil, errors.Errorf("unsupported operator %q", condition.Operator)
+		}
+	}
+	return q.And(matchers...), nil
+}
+
 // GetBuckets returns a list of buckets
 func (b *Bolt) GetBuckets() []string {
 	return b.db.Bucket()
@@ -18,10 +18,13 @@
 package boltdb
 
 import (
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/mysteriumnetwork/node/core/storage"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
 )
 
@@ -113,3 +116,113 @@ func Test_StorageGetLast_ErrsOnEmpty(t *testing.T) {
 	err = storage.GetLast(bucket, &result)
 	assert.Equal(t, "not found", err.Error())
 }
+
+type myRecord struct {
+	ID     int64 `storm:"id"`
+	Owner  string
+	Amount int
+}
+
+func Test_StorageFind(t *testing.T) {
+	db, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	for _, r := range []myRecord{{ID: 1, Owner: "a", Amount: 1}, {ID: 2, Owner: "b", Amount: 2}, {ID: 3, Owner: "a", Amount: 3}} {
+		record := r
+		assert.NoError(t, db.Store(bucket, &record))
+	}
+
+	var result []myRecord
+	err = db.Find(bucket, storage.Query{Where: []storage.Condition{storage.Eq("Owner", "a")}, OrderBy: "Amount", Reverse: true}, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, []myRecord{{ID: 3, Owner: "a", Amount: 3}, {ID: 1, Owner: "a", Amount: 1}}, result)
+
+	err = db.Find(bucket, storage.Query{Where: []storage.Condition{storage.Eq("Owner", "a")}, OrderBy: "Amount", Reverse: true, Limit: 1}, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, []myRecord{{ID: 3, Owner: "a", Amount: 3}}, result)
+
+	err = db.Find(bucket, storage.Query{Where: []storage.Condition{storage.Gte("Amount", 4)}}, &result)
+	assert.Equal(t, storage.ErrNotFound, err)
+}
+
+type mySession struct {
+	ID      int64 `storm:"id"`
+	Owner   string
+	Bytes   uint64
+	Tokens  *big.Int
+	Started time.Time
+	Updated time.Time
+}
+
+func Test_StorageAggregate(t *testing.T) {
+	db, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	day := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	tokens, _ := new(big.Int).SetString("9000000000000000000", 10)
+	records := []mySession{
+		{ID: 1, Owner: "x", Bytes: 1, Tokens: tokens, Started: day.Add(time.Hour), Updated: day.Add(time.Hour + 10*time.Second)},
+		{ID: 2, Owner: "x", Bytes: 2, Tokens: tokens, Started: day.Add(2 * time.Hour), Updated: day.Add(2*time.Hour + 500*time.Millisecond)},
+		{ID: 3, Owner: "y", Bytes: 3, Started: day.Add(26 * time.Hour), Updated: day.Add(26*time.Hour + time.Second)},
+		{ID: 4, Owner: "y", Bytes: 4, Started: day.Add(50 * time.Hour)},
+	}
+	for i := range records {
+		assert.NoError(t, db.Store(bucket, &records[i]))
+	}
+
+	groups, err := db.Aggregate(bucket, &mySession{}, storage.Aggregation{
+		Where:   []storage.Condition{storage.Lte("Started", day.Add(48*time.Hour))},
+		GroupBy: []string{"Started", "Owner"},
+		Sum:     []storage.Sum{storage.SumField("Bytes"), storage.SumField("Tokens"), storage.SumDuration("Started", "Updated")},
+	})
+	assert.NoError(t, err)
+	bigTokens, _ := new(big.Int).SetString("18000000000000000000", 10)
+	assert.ElementsMatch(t, []storage.Group{
+		{
+			Values: []interface{}{day, "x"},
+			Count:  2,
+			Sums:   []*big.Int{big.NewInt(3), bigTokens, big.NewInt(int64(10500 * time.Millisecond))},
+		},
+		{
+			Values: []interface{}{day.Add(24 * time.Hour), "y"},
+			Count:  1,
+			Sums:   []*big.Int{big.NewInt(3), big.NewInt(0), big.NewInt(int64(time.Second))},
+		},
+	}, groups)
+
+	// Sessions which have not ended yet last until now.
+	groups, err = db.Aggregate(bucket, &mySession{}, storage.Aggregation{
+		Where: []storage.Condition{storage.Eq("ID", int64(4))},
+		Sum:   []storage.Sum{storage.SumDuration("Started", "Updated")},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, groups, 1)
+	assert.InDelta(t, time.Since(day.Add(50*time.Hour)).Seconds(), time.Duration(groups[0].Sums[0].Int64()).Seconds(), 1)
+
+	groups, err = db.Aggregate(bucket, &mySession{}, storage.Aggregation{
+		Where: []storage.Condition{storage.Gte("Started", day.Add(72*time.Hour))},
+		Sum:   []storage.Sum{storage.SumField("Bytes")},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, groups)
+}
+
+func Test_StorageFindValues(t *testing.T) {
+	db, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	assert.NoError(t, db.SetValue(bucket, "first", myRecord{Owner: "a", Amount: 1}))
+	assert.NoError(t, db.SetValue(bucket, "second", myRecord{Owner: "b", Amount: 2}))
+
+	var result []myRecord
+	err = db.FindValues(bucket, []storage.Condition{storage.Lte("Amount", 1)}, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, []myRecord{{Owner: "a", Amount: 1}}, result)
+
+	err = db.FindValues("missing", nil, &result)
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+)
+
+var (
+	// ErrNoID is returned when the struct has no ID field
+	ErrNoID = errors.New("missing struct tag id or ID field")
+	// ErrZeroID is returned when the ID field of the struct is a zero value
+	ErrZeroID = errors.New("id field must not be a zero value")
+
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+type structInfo struct {
+	typ     reflect.Type
+	name    string
+	idField string
+}
+
+// structOf describes the struct the way BoltDB storage does: by its type name and the field tagged with `storm:"id"` or named ID.
+func structOf(data interface{}) (structInfo, error) {
+	typ := reflect.TypeOf(data)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return structInfo{}, errors.New("provided data must be a struct or a pointer to struct")
+	}
+
+	info := structInfo{typ: typ, name: typ.Name()}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if strings.Contains(","+field.Tag.Get("storm")+",", ",id,") {
+			info.idField = field.Name
+			break
+		}
+		if field.Name == "ID" && info.idField == "" {
+			info.idField = field.Name
+		}
+	}
+	if info.idField == "" {
+		return structInfo{}, ErrNoID
+	}
+	return info, nil
+}
+
+func (info structInfo) id(value reflect.Value) ([]byte, error) {
+	field := reflect.Indirect(value).FieldByName(info.idField)
+	if reflect.DeepEqual(field.Interface(), reflect.Zero(field.Type()).Interface()) {
+		return nil, ErrZeroID
+	}
+	return encodeKey(field.Interface())
+}
+
+// encodeKey encodes the key the same way as BoltDB storage does.
+func encodeKey(key interface{}) ([]byte, error) {
+	switch k := key.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return k, nil
+	case string:
+		return []byte(k), nil
+	case int:
+		return encodeNumber(int64(k))
+	case uint:
+		return encodeNumber(uint64(k))
+	case int8, int16, int32, int64, uint8, uint16, uint32, uint64:
+		return encodeNumber(k)
+	default:
+		return json.Marshal(key)
+	}
+}
+
+func encodeNumber(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.BigEndian, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonPath returns the path of the struct field in the stored JSON document.
+func jsonPath(typ reflect.Type, fieldName string) (string, reflect.Type, error) {
+	if typ.Kind() != reflect.Struct {
+		return "", nil, fmt.Errorf("can not query field %s of %s", fieldName, typ)
+	}
+	field, ok := typ.FieldByName(fieldName)
+	if !ok || field.PkgPath != "" {
+		return "", nil, fmt.Errorf("field %s not found", fieldName)
+	}
+
+	name := field.Name
+	if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == "-" {
+		return "", nil, fmt.Errorf("field %s is not stored", fieldName)
+	} else if tag != "" {
+		name = tag
+	}
+	return `$."` + name + `"`, field.Type, nil
+}
+
+// fieldExpr returns the SQL expression of the struct field, times are compared as julian days since they may be stored in different zones.
+// Indexes are created on the very same expressions, so that queries can use them.
+func fieldExpr(typ reflect.Type, fieldName string) (string, error) {
+	path, fieldType, err := jsonPath(typ, fieldName)
+	if err != nil {
+		return "", err
+	}
+	return valueExpr(path, fieldType == timeType), nil
+}
+
+func valueExpr(path string, isTime bool) string {
+	expr := fmt.Sprintf("json_extract(data, '%s')", path)
+	if isTime {
+		return "julianday(" + expr + ")"
+	}
+	return expr
+}
+
+// conditionExpr returns the SQL expression of the condition along with its argument.
+func conditionExpr(typ reflect.Type, condition storage.Condition) (string, interface{}, error) {
+	switch condition.Operator {
+	case storage.OperatorEqual, storage.OperatorGreaterOrEqual, storage.OperatorLessOrEqual:
+	default:
+		return "", nil, fmt.Errorf("unsupported operator %q", condition.Operator)
+	}
+
+	expr, err := fieldExpr(typ, condition.Field)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if t, ok := condition.Value.(time.Time); ok {
+		return fmt.Sprintf("%s %s julianday(?)", expr, condition.Operator), t.Format(time.RFC3339Nano), nil
+	}
+	arg, err := json.Marshal(condition.Value)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("%s %s json_extract(?, '$')", expr, condition.Operator), string(arg), nil
+}
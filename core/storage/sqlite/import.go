@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"go.etcd.io/bbolt"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrations/history"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrator"
+)
+
+var stormPrefix = []byte("__storm")
+
+// ImportBoltDB returns the migration copying the data of BoltDB storage kept in the given directory.
+// BoltDB storage is brought up to date with its own migrations first, nothing is imported if there is none.
+func ImportBoltDB(dir string) Migration {
+	return Migration{
+		Name: "import-boltdb",
+		Date: time.Date(2020, 10, 1, 13, 0, 0, 0, time.UTC),
+		Migrate: func(tx *sql.Tx) error {
+			if _, err := os.Stat(filepath.Join(dir, "myst.db")); os.IsNotExist(err) {
+				return nil
+			}
+
+			bolt, err := boltdb.NewStorage(dir)
+			if err != nil {
+				return err
+			}
+			defer bolt.Close()
+
+			if err := migrator.NewMigrator(bolt).RunMigrations(history.Sequence); err != nil {
+				return errors.Wrap(err, "failed to migrate BoltDB before import")
+			}
+			return importBolt(tx, bolt.DB().Bolt)
+		},
+	}
+}
+
+// importBolt copies values of the top level buckets and structs of the nested ones, skipping storm metadata and indexes.
+func importBolt(tx *sql.Tx, db *bbolt.DB) error {
+	stmt, err := tx.Prepare(`INSERT OR REPLACE INTO entries (bucket, type, key, data) VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	count := 0
+	err = db.View(func(btx *bbolt.Tx) error {
+		return btx.ForEach(func(name []byte, b *bbolt.Bucket) error {
+			if bytes.HasPrefix(name, stormPrefix) {
+				return nil
+			}
+
+			return b.ForEach(func(k, v []byte) error {
+				if bytes.HasPrefix(k, stormPrefix) {
+					return nil
+				}
+				if v != nil {
+					count++
+					_, err := stmt.Exec(string(name), "", k, string(v))
+					return err
+				}
+
+				return b.Bucket(k).ForEach(func(id, data []byte) error {
+					if bytes.HasPrefix(id, stormPrefix) || data == nil {
+						return nil
+					}
+					count++
+					_, err := stmt.Exec(string(name), string(k), id, string(data))
+					return err
+				})
+			})
+		})
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to import BoltDB")
+	}
+
+	log.Info().Msgf("Imported %d BoltDB entries", count)
+	return nil
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Migration represents a migration we want to run on SQLite database
+type Migration struct {
+	Name    string
+	Date    time.Time
+	Migrate func(tx *sql.Tx) error
+}
+
+// Sequence contains the whole migration sequence for SQLite database.
+// Invoices and other values looked up by their keys are served by the primary key, the rest of queried fields get their indexes.
+var Sequence = []Migration{
+	{
+		Name:    "create-entries",
+		Date:    time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC),
+		Migrate: createEntries,
+	},
+	{
+		Name:    "index-session-history",
+		Date:    time.Date(2020, 10, 1, 12, 1, 0, 0, time.UTC),
+		Migrate: createIndex("entries_started", "Started", true),
+	},
+	{
+		Name:    "index-settlement-history",
+		Date:    time.Date(2020, 10, 1, 12, 2, 0, 0, time.UTC),
+		Migrate: createIndex("entries_time", "Time", true),
+	},
+	{
+		Name:    "index-hermes-promises",
+		Date:    time.Date(2020, 10, 1, 12, 3, 0, 0, time.UTC),
+		Migrate: createIndex("entries_identity", "Identity", false),
+	},
+	{
+		Name:    "index-webhook-deliveries",
+		Date:    time.Date(2020, 10, 20, 12, 0, 0, 0, time.UTC),
+		Migrate: createIndex("entries_next_attempt", "NextAttempt", true),
+	},
+	{
+		Name:    "index-event-journal",
+		Date:    time.Date(2020, 10, 22, 12, 0, 0, 0, time.UTC),
+		Migrate: createIndex("entries_id", "ID", false),
+	},
+}
+
+func createEntries(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS entries (
+		bucket TEXT NOT NULL,
+		type TEXT NOT NULL,
+		key BLOB NOT NULL,
+		data TEXT NOT NULL,
+		PRIMARY KEY (bucket, type, key)
+	)`)
+	return err
+}
+
+func createIndex(name, field string, isTime bool) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		expr := valueExpr(`$."`+field+`"`, isTime)
+		_, err := tx.Exec(fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON entries (bucket, type, %s)`, name, expr))
+		return err
+	}
+}
+
+// RunMigrations runs the given sequence of migrations, each of them in its own transaction.
+func (s *SQLite) RunMigrations(sequence []Migration) error {
+	_, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS migrations (name TEXT PRIMARY KEY, date DATETIME NOT NULL)`)
+	if err != nil {
+		return err
+	}
+
+	sorted := make([]Migration, len(sequence))
+	copy(sorted, sequence)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+
+	for _, migration := range sorted {
+		if err := s.migrate(migration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLite) migrate(migration Migration) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var applied int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM migrations WHERE name = ?`, migration.Name).Scan(&applied); err != nil {
+		return err
+	}
+	if applied > 0 {
+		return nil
+	}
+
+	log.Info().Msg("Running migration " + migration.Name)
+	if err := migration.Migrate(tx); err != nil {
+		return err
+	}
+	log.Info().Msg("Saving migration " + migration.Name)
+	if _, err := tx.Exec(`INSERT INTO migrations (name, date) VALUES (?, ?)`, migration.Name, migration.Date); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,521 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	// Registers the sqlite3 driver.
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+)
+
+// SQLite is a storage keeping values and structs of the buckets in a single table of SQLite database.
+// Values are stored with an empty type, structs with the name of their type, so that both of them can share the bucket.
+// Keys and data are encoded the same way as BoltDB storage does, so that the data can be copied over as is.
+type SQLite struct {
+	db *sql.DB
+}
+
+var _ storage.Storage = (*SQLite)(nil)
+
+// NewStorage creates a new SQLite storage in the given directory.
+func NewStorage(path string) (*SQLite, error) {
+	return openDB(filepath.Join(path, "myst.sqlite"))
+}
+
+// openDB creates new or opens existing SQLite database
+func openDB(name string) (*SQLite, error) {
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_busy_timeout=5000&_journal_mode=WAL", name))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open SQLite")
+	}
+	// SQLite allows a single writer only, so the connection is shared to avoid busy errors.
+	db.SetMaxOpenConns(1)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to open SQLite")
+	}
+	return &SQLite{db: db}, nil
+}
+
+// GetValue gets key value
+func (s *SQLite) GetValue(bucket string, key interface{}, to interface{}) error {
+	id, err := encodeKey(key)
+	if err != nil {
+		return err
+	}
+
+	var data string
+	err = s.db.QueryRow(`SELECT data FROM entries WHERE bucket = ? AND type = '' AND key = ?`, bucket, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return storage.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), to)
+}
+
+// SetValue sets key value
+func (s *SQLite) SetValue(bucket string, key interface{}, to interface{}) error {
+	id, err := encodeKey(key)
+	if err != nil {
+		return err
+	}
+	return s.put(s.db, bucket, "", id, to)
+}
+
+// FindValues fetches values of the bucket matching all the conditions
+func (s *SQLite) FindValues(bucket string, where []storage.Condition, to interface{}) error {
+	err := s.find(bucket, false, storage.Query{Where: where}, to)
+	if err == storage.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// Store allows to keep struct grouped by the bucket
+func (s *SQLite) Store(bucket string, data interface{}) error {
+	info, err := structOf(data)
+	if err != nil {
+		return err
+	}
+	id, err := info.id(reflect.ValueOf(data))
+	if err != nil {
+		return err
+	}
+	return s.put(s.db, bucket, info.name, id, data)
+}
+
+// GetAllFrom allows to get all structs from the bucket
+func (s *SQLite) GetAllFrom(bucket string, data interface{}) error {
+	err := s.find(bucket, true, storage.Query{}, data)
+	if err == storage.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// Delete removes the given struct from the given bucket
+func (s *SQLite) Delete(bucket string, data interface{}) error {
+	info, err := structOf(data)
+	if err != nil {
+		return err
+	}
+	id, err := info.id(reflect.ValueOf(data))
+	if err != nil {
+		return err
+	}
+
+	res, err := s.db.Exec(`DELETE FROM entries WHERE bucket = ? AND type = ? AND key = ?`, bucket, info.name, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+// Update updates non zero fields of the stored struct
+func (s *SQLite) Update(bucket string, object interface{}) error {
+	info, err := structOf(object)
+	if err != nil {
+		return err
+	}
+	update := reflect.Indirect(reflect.ValueOf(object))
+	id, err := info.id(update)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var data string
+	err = tx.QueryRow(`SELECT data FROM entries WHERE bucket = ? AND type = ? AND key = ?`, bucket, info.name, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return storage.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	current := reflect.New(info.typ)
+	if err := json.Unmarshal([]byte(data), current.Interface()); err != nil {
+		return err
+	}
+	for i := 0; i < info.typ.NumField(); i++ {
+		if info.typ.Field(i).PkgPath != "" {
+			continue
+		}
+		field := update.Field(i)
+		if !reflect.DeepEqual(field.Interface(), reflect.Zero(field.Type()).Interface()) {
+			current.Elem().Field(i).Set(field)
+		}
+	}
+
+	if err := s.put(tx, bucket, info.name, id, current.Interface()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetOneByField returns an object from the given bucket by the given field
+func (s *SQLite) GetOneByField(bucket string, fieldName string, key interface{}, to interface{}) error {
+	info, err := structOf(to)
+	if err != nil {
+		return err
+	}
+
+	if fieldName == info.idField {
+		id, err := encodeKey(key)
+		if err != nil {
+			return err
+		}
+		var data string
+		err = s.db.QueryRow(`SELECT data FROM entries WHERE bucket = ? AND type = ? AND key = ?`, bucket, info.name, id).Scan(&data)
+		if err == sql.ErrNoRows {
+			return storage.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal([]byte(data), to)
+	}
+
+	result := reflect.New(reflect.SliceOf(info.typ))
+	err = s.find(bucket, true, storage.Query{Where: []storage.Condition{storage.Eq(fieldName, key)}}, result.Interface())
+	if err != nil {
+		return err
+	}
+	reflect.ValueOf(to).Elem().Set(result.Elem().Index(0))
+	return nil
+}
+
+// GetLast returns the last entry in the bucket
+func (s *SQLite) GetLast(bucket string, to interface{}) error {
+	info, err := structOf(to)
+	if err != nil {
+		return err
+	}
+
+	var data string
+	err = s.db.QueryRow(`SELECT data FROM entries WHERE bucket = ? AND type = ? ORDER BY key DESC LIMIT 1`, bucket, info.name).Scan(&data)
+	if err == sql.ErrNoRows {
+		return storage.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), to)
+}
+
+// Find fetches structs of the bucket selected by the query, returns ErrNotFound when there are none
+func (s *SQLite) Find(bucket string, query storage.Query, to interface{}) error {
+	return s.find(bucket, true, query, to)
+}
+
+// Aggregate groups the structs of the record type and sums their fields with a single SQL query.
+func (s *SQLite) Aggregate(bucket string, record interface{}, aggregation storage.Aggregation) ([]storage.Group, error) {
+	info, err := structOf(record)
+	if err != nil {
+		return nil, err
+	}
+
+	var columns, groupBy []string
+	var args []interface{}
+	isDate := make([]bool, len(aggregation.GroupBy))
+	for i, field := range aggregation.GroupBy {
+		path, fieldType, err := jsonPath(info.typ, field)
+		if err != nil {
+			return nil, err
+		}
+		isDate[i] = fieldType == timeType
+		if isDate[i] {
+			columns = append(columns, fmt.Sprintf("date(json_extract(data, '%s'))", path))
+		} else {
+			columns = append(columns, fmt.Sprintf("data -> '%s'", path))
+		}
+		groupBy = append(groupBy, fmt.Sprint(i+1))
+	}
+	columns = append(columns, "COUNT(*)")
+	for _, sum := range aggregation.Sum {
+		if sum.Since != "" {
+			until, err := fieldExpr(info.typ, sum.Field)
+			if err != nil {
+				return nil, err
+			}
+			since, err := fieldExpr(info.typ, sum.Since)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, fmt.Sprintf(
+				"SUM(CAST(round((CASE WHEN %[1]s = julianday('0001-01-01T00:00:00Z') THEN julianday(?) ELSE %[1]s END - %[2]s) * 86400000) AS INTEGER))",
+				until, since,
+			))
+			args = append(args, time.Now().UTC().Format(time.RFC3339Nano))
+			continue
+		}
+
+		path, _, err := jsonPath(info.typ, sum.Field)
+		if err != nil {
+			return nil, err
+		}
+		// Numbers are summed by their high and low 9 decimal digits, so that big integers do not overflow.
+		value := fmt.Sprintf("(data -> '%s')", path)
+		columns = append(columns,
+			fmt.Sprintf("SUM(CAST(substr(%[1]s, 1, max(length(%[1]s) - 9, 0)) AS INTEGER))", value),
+			fmt.Sprintf("SUM(CAST(substr(%s, -9) AS INTEGER))", value),
+		)
+	}
+
+	sqlQuery := `SELECT ` + strings.Join(columns, ", ") + ` FROM entries WHERE bucket = ? AND type = ?`
+	args = append(args, bucket, info.name)
+	for _, condition := range aggregation.Where {
+		expr, arg, err := conditionExpr(info.typ, condition)
+		if err != nil {
+			return nil, err
+		}
+		sqlQuery += " AND " + expr
+		args = append(args, arg)
+	}
+	if len(groupBy) > 0 {
+		sqlQuery += " GROUP BY " + strings.Join(groupBy, ", ")
+	}
+
+	rows, err := s.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := make([]storage.Group, 0)
+	for rows.Next() {
+		values := make([]sql.NullString, len(aggregation.GroupBy))
+		var count int
+		var sums []sql.NullInt64
+		for _, sum := range aggregation.Sum {
+			sums = append(sums, sql.NullInt64{})
+			if sum.Since == "" {
+				sums = append(sums, sql.NullInt64{})
+			}
+		}
+		dest := make([]interface{}, 0, len(values)+1+len(sums))
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		dest = append(dest, &count)
+		for i := range sums {
+			dest = append(dest, &sums[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			continue
+		}
+
+		group := storage.Group{Count: count}
+		for i, field := range aggregation.GroupBy {
+			value, err := groupValue(info.typ, field, values[i], isDate[i])
+			if err != nil {
+				return nil, err
+			}
+			group.Values = append(group.Values, value)
+		}
+		for _, sum := range aggregation.Sum {
+			if sum.Since != "" {
+				group.Sums = append(group.Sums, new(big.Int).Mul(big.NewInt(sums[0].Int64), big.NewInt(int64(time.Millisecond))))
+				sums = sums[1:]
+				continue
+			}
+			total := new(big.Int).Mul(big.NewInt(sums[0].Int64), big.NewInt(1e9))
+			group.Sums = append(group.Sums, total.Add(total, big.NewInt(sums[1].Int64)))
+			sums = sums[2:]
+		}
+		groups = append(groups, group)
+	}
+	return groups, rows.Err()
+}
+
+// groupValue decodes the value of the grouped field, times are grouped by the date.
+func groupValue(typ reflect.Type, fieldName string, value sql.NullString, isDate bool) (interface{}, error) {
+	field, _ := typ.FieldByName(fieldName)
+	if !value.Valid {
+		return reflect.Zero(field.Type).Interface(), nil
+	}
+	if isDate {
+		return time.Parse("2006-01-02", value.String)
+	}
+	decoded := reflect.New(field.Type)
+	if err := json.Unmarshal([]byte(value.String), decoded.Interface()); err != nil {
+		return nil, err
+	}
+	return decoded.Elem().Interface(), nil
+}
+
+// GetBuckets returns a list of buckets
+func (s *SQLite) GetBuckets() []string {
+	buckets := make([]string, 0)
+	rows, err := s.db.Query(`SELECT DISTINCT bucket FROM entries ORDER BY bucket`)
+	if err != nil {
+		return buckets
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bucket string
+		if err := rows.Scan(&bucket); err == nil {
+			buckets = append(buckets, bucket)
+		}
+	}
+	return buckets
+}
+
+// DB returns raw SQL database.
+func (s *SQLite) DB() *sql.DB {
+	return s.db
+}
+
+// Close closes database
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func (s *SQLite) put(db execer, bucket, typ string, key []byte, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(
+		`INSERT INTO entries (bucket, type, key, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT (bucket, type, key) DO UPDATE SET data = excluded.data`,
+		bucket, typ, key, string(data),
+	)
+	return err
+}
+
+// find decodes the entries selected by the query into the slice, structs are selected by the type of slice elements.
+func (s *SQLite) find(bucket string, structs bool, query storage.Query, to interface{}) error {
+	ref := reflect.ValueOf(to)
+	if ref.Kind() != reflect.Ptr || ref.Elem().Kind() != reflect.Slice {
+		return errors.New("provided target must be a pointer to a slice")
+	}
+	elemType := ref.Elem().Type().Elem()
+	recordType := elemType
+	if recordType.Kind() == reflect.Ptr {
+		recordType = recordType.Elem()
+	}
+
+	typ := ""
+	if structs {
+		if recordType.Kind() != reflect.Struct {
+			return errors.New("provided target must be a pointer to a slice of structs")
+		}
+		typ = recordType.Name()
+	}
+
+	sqlQuery := `SELECT data FROM entries WHERE bucket = ? AND type = ?`
+	args := []interface{}{bucket, typ}
+	for _, condition := range query.Where {
+		expr, arg, err := conditionExpr(recordType, condition)
+		if err != nil {
+			return err
+		}
+		sqlQuery += " AND " + expr
+		args = append(args, arg)
+	}
+
+	direction := ""
+	if query.Reverse {
+		direction = " DESC"
+	}
+	order := []string{"key" + direction}
+	if query.OrderBy != "" {
+		expr, err := fieldExpr(recordType, query.OrderBy)
+		if err != nil {
+			return err
+		}
+		order = append([]string{expr + direction}, order...)
+	}
+	sqlQuery += " ORDER BY " + strings.Join(order, ", ")
+	if query.Limit > 0 {
+		sqlQuery += " LIMIT ?"
+		args = append(args, query.Limit)
+	}
+
+	rows, err := s.db.Query(sqlQuery, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	results := reflect.MakeSlice(ref.Elem().Type(), 0, 0)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return err
+		}
+
+		record := reflect.New(recordType)
+		if err := json.Unmarshal([]byte(data), record.Interface()); err != nil {
+			return err
+		}
+		if elemType.Kind() == reflect.Ptr {
+			results = reflect.Append(results, record)
+		} else {
+			results = reflect.Append(results, record.Elem())
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	ref.Elem().Set(results)
+	if results.Len() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func requireAffected(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
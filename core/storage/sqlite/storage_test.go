@@ -0,0 +1,353 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+)
+
+type myTestType struct {
+	ID int64 `storm:"id"`
+}
+
+type testOwner struct {
+	Address string `json:"address"`
+}
+
+type testRecord struct {
+	Key     string `storm:"id"`
+	Owner   testOwner
+	Amount  int
+	Created time.Time `json:"created_at"`
+}
+
+var (
+	bucket = "test"
+)
+
+func createMockStorage(t *testing.T) (*SQLite, func(), error) {
+	dir := boltdbtest.CreateTempDir(t)
+	close := func() {
+		boltdbtest.RemoveTempDir(t, dir)
+	}
+	storage, err := NewStorage(dir)
+	if err == nil {
+		err = storage.RunMigrations(Sequence)
+	}
+	if err != nil {
+		close()
+		return nil, nil, err
+	}
+	close = func() {
+		storage.Close()
+		boltdbtest.RemoveTempDir(t, dir)
+	}
+	return storage, close, nil
+}
+
+func Test_StorageGetByID(t *testing.T) {
+	storage, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	data := myTestType{
+		ID: 1,
+	}
+	err = storage.Store(bucket, &data)
+	assert.Nil(t, err)
+
+	var result myTestType
+	err = storage.GetOneByField(bucket, "ID", data.ID, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, data.ID, result.ID)
+}
+
+func Test_StorageGetByID_NotFound(t *testing.T) {
+	storage, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	var result myTestType
+	err = storage.GetOneByField(bucket, "ID", "data.ID", &result)
+	assert.Equal(t, "not found", err.Error())
+}
+
+func Test_StorageGetByField(t *testing.T) {
+	storage, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	data := testRecord{Key: "1", Owner: testOwner{Address: "0x1"}, Amount: 10}
+	assert.NoError(t, storage.Store(bucket, &data))
+	assert.NoError(t, storage.Store(bucket, &testRecord{Key: "2", Owner: testOwner{Address: "0x2"}, Amount: 20}))
+
+	var result testRecord
+	err = storage.GetOneByField(bucket, "Owner", testOwner{Address: "0x1"}, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, data, result)
+}
+
+func Test_GetLastEntryInBucket(t *testing.T) {
+	storage, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	for _, id := range []int64{2, 256, 1} {
+		err = storage.Store(bucket, &myTestType{ID: id})
+		assert.Nil(t, err)
+	}
+
+	var res myTestType
+	err = storage.GetLast(bucket, &res)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(256), res.ID)
+}
+
+func Test_StorageGetLast_ErrsOnEmpty(t *testing.T) {
+	storage, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	var result myTestType
+	err = storage.GetLast(bucket, &result)
+	assert.Equal(t, "not found", err.Error())
+}
+
+func Test_StorageValues(t *testing.T) {
+	db, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	var value string
+	err = db.GetValue(bucket, "key", &value)
+	assert.Equal(t, storage.ErrNotFound, err)
+
+	assert.NoError(t, db.SetValue(bucket, "key", "first"))
+	assert.NoError(t, db.SetValue(bucket, "key", "second"))
+	assert.NoError(t, db.GetValue(bucket, "key", &value))
+	assert.Equal(t, "second", value)
+
+	// Values and structs of the same bucket do not mix.
+	assert.NoError(t, db.Store(bucket, &myTestType{ID: 1}))
+	var records []myTestType
+	assert.NoError(t, db.GetAllFrom(bucket, &records))
+	assert.Equal(t, []myTestType{{ID: 1}}, records)
+	assert.Equal(t, []string{bucket}, db.GetBuckets())
+}
+
+func Test_StorageFindValues(t *testing.T) {
+	db, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	first := testRecord{Key: "1", Owner: testOwner{Address: "0x1"}, Amount: 10}
+	second := testRecord{Key: "2", Owner: testOwner{Address: "0x2"}, Amount: 20}
+	assert.NoError(t, db.SetValue(bucket, first.Key, first))
+	assert.NoError(t, db.SetValue(bucket, second.Key, second))
+
+	var result []testRecord
+	err = db.FindValues(bucket, []storage.Condition{storage.Eq("Owner", testOwner{Address: "0x2"})}, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, []testRecord{second}, result)
+
+	err = db.FindValues(bucket, nil, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, []testRecord{first, second}, result)
+
+	err = db.FindValues(bucket, []storage.Condition{storage.Gte("Amount", 30)}, &result)
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+}
+
+func Test_StorageFind(t *testing.T) {
+	db, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	day := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	records := []testRecord{
+		{Key: "a", Amount: 1, Created: day.Add(3 * time.Hour)},
+		{Key: "b", Amount: 2, Created: day.Add(time.Hour)},
+		{Key: "c", Amount: 3, Created: day.Add(26 * time.Hour)},
+		// Stored in another zone, still the second hour of the day.
+		{Key: "d", Amount: 4, Created: day.Add(2 * time.Hour).In(time.FixedZone("EET", 3*60*60))},
+	}
+	for i := range records {
+		assert.NoError(t, db.Store(bucket, &records[i]))
+	}
+
+	var result []testRecord
+	err = db.Find(bucket, storage.Query{
+		Where: []storage.Condition{
+			storage.Gte("Created", day.Add(time.Hour)),
+			storage.Lte("Created", day.Add(3*time.Hour)),
+		},
+		OrderBy: "Created",
+		Reverse: true,
+	}, &result)
+	assert.NoError(t, err)
+
+	var keys []string
+	for _, r := range result {
+		keys = append(keys, r.Key)
+	}
+	assert.Equal(t, []string{"a", "d", "b"}, keys)
+
+	err = db.Find(bucket, storage.Query{OrderBy: "Amount", Limit: 2}, &result)
+	assert.NoError(t, err)
+	keys = nil
+	for _, r := range result {
+		keys = append(keys, r.Key)
+	}
+	assert.Equal(t, []string{"a", "b"}, keys)
+
+	err = db.Find(bucket, storage.Query{Where: []storage.Condition{storage.Gte("Created", day.Add(48*time.Hour))}}, &result)
+	assert.Equal(t, storage.ErrNotFound, err)
+
+	err = db.Find(bucket, storage.Query{Where: []storage.Condition{storage.Eq("Missing", 1)}}, &result)
+	assert.Error(t, err)
+}
+
+type testSession struct {
+	Key     string `storm:"id"`
+	Owner   testOwner
+	Bytes   uint64
+	Tokens  *big.Int
+	Started time.Time
+	Updated time.Time
+}
+
+func Test_StorageAggregate(t *testing.T) {
+	db, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	day := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	tokens, _ := new(big.Int).SetString("9000000000000000000", 10)
+	records := []testSession{
+		{Key: "a", Owner: testOwner{"x"}, Bytes: 1, Tokens: tokens, Started: day.Add(time.Hour), Updated: day.Add(time.Hour + 10*time.Second)},
+		// Stored in another zone, still the same day.
+		{Key: "b", Owner: testOwner{"x"}, Bytes: 2, Tokens: tokens, Started: day.Add(2 * time.Hour).In(time.FixedZone("EET", 3*60*60)), Updated: day.Add(2*time.Hour + 500*time.Millisecond)},
+		{Key: "c", Owner: testOwner{"y"}, Bytes: 3, Started: day.Add(26 * time.Hour), Updated: day.Add(26*time.Hour + time.Second)},
+		{Key: "d", Owner: testOwner{"y"}, Bytes: 4, Started: day.Add(50 * time.Hour)},
+	}
+	for i := range records {
+		assert.NoError(t, db.Store(bucket, &records[i]))
+	}
+
+	groups, err := db.Aggregate(bucket, &testSession{}, storage.Aggregation{
+		Where:   []storage.Condition{storage.Lte("Started", day.Add(48*time.Hour))},
+		GroupBy: []string{"Started", "Owner"},
+		Sum:     []storage.Sum{storage.SumField("Bytes"), storage.SumField("Tokens"), storage.SumDuration("Started", "Updated")},
+	})
+	assert.NoError(t, err)
+	bigTokens, _ := new(big.Int).SetString("18000000000000000000", 10)
+	assert.ElementsMatch(t, []storage.Group{
+		{
+			Values: []interface{}{day, testOwner{"x"}},
+			Count:  2,
+			Sums:   []*big.Int{big.NewInt(3), bigTokens, big.NewInt(int64(10500 * time.Millisecond))},
+		},
+		{
+			Values: []interface{}{day.Add(24 * time.Hour), testOwner{"y"}},
+			Count:  1,
+			Sums:   []*big.Int{big.NewInt(3), big.NewInt(0), big.NewInt(int64(time.Second))},
+		},
+	}, groups)
+
+	// Sessions which have not ended yet last until now.
+	groups, err = db.Aggregate(bucket, &testSession{}, storage.Aggregation{
+		Where: []storage.Condition{storage.Eq("Key", "d")},
+		Sum:   []storage.Sum{storage.SumDuration("Started", "Updated")},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, groups, 1)
+	assert.InDelta(t, time.Since(day.Add(50*time.Hour)).Seconds(), time.Duration(groups[0].Sums[0].Int64()).Seconds(), 1)
+
+	groups, err = db.Aggregate(bucket, &testSession{}, storage.Aggregation{
+		Where: []storage.Condition{storage.Gte("Started", day.Add(72*time.Hour))},
+		Sum:   []storage.Sum{storage.SumField("Bytes")},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, groups)
+}
+
+func Test_StorageUpdateAndDelete(t *testing.T) {
+	db, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	err = db.Update(bucket, &testRecord{Key: "1", Amount: 2})
+	assert.Equal(t, storage.ErrNotFound, err)
+
+	record := testRecord{Key: "1", Owner: testOwner{Address: "0x1"}, Amount: 1}
+	assert.NoError(t, db.Store(bucket, &record))
+	assert.NoError(t, db.Update(bucket, &testRecord{Key: "1", Amount: 2}))
+
+	var result testRecord
+	assert.NoError(t, db.GetOneByField(bucket, "Key", "1", &result))
+	assert.Equal(t, testRecord{Key: "1", Owner: testOwner{Address: "0x1"}, Amount: 2}, result)
+
+	assert.NoError(t, db.Delete(bucket, &record))
+	assert.Equal(t, storage.ErrNotFound, db.Delete(bucket, &record))
+	assert.Equal(t, ErrZeroID, db.Store(bucket, &testRecord{}))
+}
+
+func Test_ImportBoltDB(t *testing.T) {
+	dir := boltdbtest.CreateTempDir(t)
+	defer boltdbtest.RemoveTempDir(t, dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	record := testRecord{Key: "1", Owner: testOwner{Address: "0x1"}, Amount: 1, Created: time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)}
+	assert.NoError(t, bolt.Store(bucket, &record))
+	assert.NoError(t, bolt.Store(bucket, &myTestType{ID: 300}))
+	assert.NoError(t, bolt.SetValue(bucket, "key", "value"))
+	assert.NoError(t, bolt.Close())
+
+	db, err := NewStorage(dir)
+	assert.NoError(t, err)
+	defer db.Close()
+	assert.NoError(t, db.RunMigrations(append(Sequence, ImportBoltDB(dir))))
+
+	var records []testRecord
+	assert.NoError(t, db.GetAllFrom(bucket, &records))
+	assert.Equal(t, []testRecord{record}, records)
+
+	var last myTestType
+	assert.NoError(t, db.GetOneByField(bucket, "ID", int64(300), &last))
+	assert.Equal(t, int64(300), last.ID)
+
+	var value string
+	assert.NoError(t, db.GetValue(bucket, "key", &value))
+	assert.Equal(t, "value", value)
+
+	// Import runs once, so later changes are not overwritten.
+	assert.NoError(t, db.SetValue(bucket, "key", "changed"))
+	assert.NoError(t, db.RunMigrations(append(Sequence, ImportBoltDB(dir))))
+	assert.NoError(t, db.GetValue(bucket, "key", &value))
+	assert.Equal(t, "changed", value)
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package storage
+
+import "math/big"
+
+// Storage is a persistent storage of values and structs grouped by buckets.
+// Structs are identified by the field tagged with `storm:"id"`.
+type Storage interface {
+	// GetValue gets key value
+	GetValue(bucket string, key interface{}, to interface{}) error
+	// SetValue sets key value
+	SetValue(bucket string, key interface{}, to interface{}) error
+	// FindValues fetches values of the bucket matching all the conditions
+	FindValues(bucket string, where []Condition, to interface{}) error
+
+	// Store allows to keep struct grouped by the bucket
+	Store(bucket string, data interface{}) error
+	// GetAllFrom allows to get all structs from the bucket
+	GetAllFrom(bucket string, data interface{}) error
+	// Delete removes the given struct from the given bucket
+	Delete(bucket string, data interface{}) error
+	// Update updates non zero fields of the stored struct
+	Update(bucket string, object interface{}) error
+	// GetOneByField returns an object from the given bucket by the given field
+	GetOneByField(bucket string, fieldName string, key interface{}, to interface{}) error
+	// GetLast returns the last entry in the bucket
+	GetLast(bucket string, to interface{}) error
+	// Find fetches structs of the bucket selected by the query, returns ErrNotFound when there are none
+	Find(bucket string, query Query, to interface{}) error
+	// Aggregate groups the structs of the record type without loading all of them into memory
+	Aggregate(bucket string, record interface{}, aggregation Aggregation) ([]Group, error)
+
+	// GetBuckets returns a list of buckets
+	GetBuckets() []string
+	// Close closes database
+	Close() error
+}
+
+// Operator compares the struct field with the condition value.
+type Operator string
+
+const (
+	// OperatorEqual matches fields equal to the value
+	OperatorEqual Operator = "="
+	// OperatorGreaterOrEqual matches fields greater than or equal to the value
+	OperatorGreaterOrEqual Operator = ">="
+	// OperatorLessOrEqual matches fields less than or equal to the value
+	OperatorLessOrEqual Operator = "<="
+)
+
+// Condition matches struct field against the value.
+type Condition struct {
+	Field    string
+	Operator Operator
+	Value    interface{}
+}
+
+// Eq matches structs with the field equal to the value.
+func Eq(field string, value interface{}) Condition {
+	return Condition{Field: field, Operator: OperatorEqual, Value: value}
+}
+
+// Gte matches structs with the field greater than or equal to the value.
+func Gte(field string, value interface{}) Condition {
+	return Condition{Field: field, Operator: OperatorGreaterOrEqual, Value: value}
+}
+
+// Lte matches structs with the field less than or equal to the value.
+func Lte(field string, value interface{}) Condition {
+	return Condition{Field: field, Operator: OperatorLessOrEqual, Value: value}
+}
+
+// Query selects structs matching all the conditions, ordered by the given field.
+// Limit caps the count of the selected structs, zero means no limit.
+type Query struct {
+	Where   []Condition
+	OrderBy string
+	Reverse bool
+	Limit   int
+}
+
+// Aggregation groups the structs matching all the conditions by the values of the GroupBy fields
+// and sums the fields of every group. Time fields are grouped by the UTC day.
+type Aggregation struct {
+	Where   []Condition
+	GroupBy []string
+	Sum     []Sum
+}
+
+// Sum adds up the values of the non-negative integer or *big.Int field. When Since is set, it adds up
+// the durations between Since and Field times instead, rounded to milliseconds, zero Field time is taken as now.
+type Sum struct {
+	Field string
+	Since string
+}
+
+// SumField adds up the values of the field.
+func SumField(field string) Sum {
+	return Sum{Field: field}
+}
+
+// SumDuration adds up the durations from the since time field to the until one.
+func SumDuration(since, until string) Sum {
+	return Sum{Field: until, Since: since}
+}
+
+// Group holds the values of the GroupBy fields, the count of structs in the group and the sums
+// in the order of the aggregation, durations are summed in nanoseconds.
+type Group struct {
+	Values []interface{}
+	Count  int
+	Sums   []*big.Int
+}
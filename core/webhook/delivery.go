@@ -0,0 +1,222 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// HeaderEvent holds the name of the posted event.
+	HeaderEvent = "X-Mysterium-Event"
+	// HeaderDelivery holds the ID of the delivery, which stays the same when the delivery is retried.
+	HeaderDelivery = "X-Mysterium-Delivery"
+	// HeaderSignature holds HMAC-SHA256 of the body signed by the webhook secret, as "sha256=<hex>".
+	HeaderSignature = "X-Mysterium-Signature"
+)
+
+const (
+	deliveryTimeout = 10 * time.Second
+	pollInterval    = 5 * time.Second
+	cleanupInterval = time.Hour
+	retryBaseDelay  = 10 * time.Second
+	retryMaxDelay   = time.Hour
+)
+
+// Start starts delivering the queued events, including the ones left pending by the previous run of the node.
+func (d *Dispatcher) Start() {
+	go d.run()
+}
+
+// Stop stops delivering the events, the pending ones stay queued.
+func (d *Dispatcher) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.stop)
+	})
+}
+
+func (d *Dispatcher) run() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.deliverDue()
+		d.cleanup()
+
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// deliverDue attempts the pending deliveries, whose next attempt is due.
+func (d *Dispatcher) deliverDue() {
+	query := storage.Query{
+		Where: []storage.Condition{
+			storage.Eq("Status", DeliveryPending),
+			storage.Lte("NextAttempt", d.now().UTC()),
+		},
+		OrderBy: "NextAttempt",
+	}
+	var due []Delivery
+	err := d.storage.Find(deliveriesBucket, query, &due)
+	if errors.Is(err, storage.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get pending webhook deliveries")
+		return
+	}
+
+	webhooks, err := d.List()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to deliver webhook events")
+		return
+	}
+	byID := make(map[string]Webhook, len(webhooks))
+	for _, webhook := range webhooks {
+		byID[webhook.ID] = webhook
+	}
+
+	for i := range due {
+		select {
+		case <-d.stop:
+			return
+		default:
+		}
+
+		webhook, ok := byID[due[i].WebhookID]
+		if !ok {
+			d.finish(&due[i], DeliveryFailed, "webhook deleted")
+			continue
+		}
+		d.attempt(webhook, &due[i])
+	}
+}
+
+// attempt posts the delivery, scheduling the next attempt if it fails.
+func (d *Dispatcher) attempt(webhook Webhook, delivery *Delivery) {
+	err := d.send(webhook, *delivery)
+	delivery.Attempts++
+	switch {
+	case err == nil:
+		d.finish(delivery, DeliveryDelivered, "")
+	case delivery.Attempts >= d.config.MaxAttempts:
+		log.Warn().Err(err).Msgf("Giving up delivering webhook event %s to %s", delivery.Event, webhook.URL)
+		d.finish(delivery, DeliveryFailed, err.Error())
+	default:
+		log.Debug().Err(err).Msgf("Failed to deliver webhook event %s to %s", delivery.Event, webhook.URL)
+		now := d.now().UTC()
+		delivery.LastError = err.Error()
+		delivery.NextAttempt = now.Add(retryDelay(delivery.Attempts))
+		delivery.UpdatedAt = now
+		d.store(delivery)
+	}
+}
+
+func (d *Dispatcher) finish(delivery *Delivery, status DeliveryStatus, lastError string) {
+	delivery.Status = status
+	delivery.LastError = lastError
+	delivery.UpdatedAt = d.now().UTC()
+	d.store(delivery)
+}
+
+func (d *Dispatcher) store(delivery *Delivery) {
+	if err := d.storage.Store(deliveriesBucket, delivery); err != nil {
+		log.Error().Err(err).Msgf("Failed to store webhook delivery %s", delivery.ID)
+	}
+}
+
+func (d *Dispatcher) send(webhook Webhook, delivery Delivery) error {
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(webhook.Secret, []byte(delivery.Payload)))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected HTTP status %s", resp.Status)
+	}
+	return nil
+}
+
+// retryDelay doubles the delay after every failed attempt.
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > retryMaxDelay {
+		return retryMaxDelay
+	}
+	return delay
+}
+
+// cleanup deletes the finished deliveries older than the retention period.
+func (d *Dispatcher) cleanup() {
+	now := d.now().UTC()
+	if now.Sub(d.lastCleanup) < cleanupInterval {
+		return
+	}
+	d.lastCleanup = now
+
+	for _, status := range []DeliveryStatus{DeliveryDelivered, DeliveryFailed} {
+		query := storage.Query{
+			Where: []storage.Condition{
+				storage.Eq("Status", status),
+				storage.Lte("UpdatedAt", now.Add(-d.config.Retention)),
+			},
+		}
+		var expired []Delivery
+		err := d.storage.Find(deliveriesBucket, query, &expired)
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to get expired webhook deliveries")
+			return
+		}
+		for i := range expired {
+			if err := d.storage.Delete(deliveriesBucket, &expired[i]); err != nil {
+				log.Error().Err(err).Msgf("Failed to delete webhook delivery %s", expired[i].ID)
+			}
+		}
+	}
+}
@@ -0,0 +1,263 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package webhook
+
+import (
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/eventbus"
+	natevent "github.com/mysteriumnetwork/node/nat/event"
+	sessionevent "github.com/mysteriumnetwork/node/session/event"
+	pingpongevent "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+// Names of the events posted to the webhooks.
+const (
+	EventConnectionState      = "connection.state"
+	EventServiceStatus        = "service.status"
+	EventSessionStarted       = "session.started"
+	EventSessionEnded         = "session.ended"
+	EventInvoicePaid          = "invoice.paid"
+	EventPromiseReceived      = "promise.received"
+	EventSettlementCompleted  = "settlement.completed"
+	EventSettlementFailed     = "settlement.failed"
+	EventBalanceChanged       = "balance.changed"
+	EventBalanceLow           = "balance.low"
+	EventSpendingLimitReached = "spending.limit_reached"
+	EventNATTraversal         = "nat.traversal"
+)
+
+// Events lists all the events posted to the webhooks.
+var Events = []string{
+	EventConnectionState,
+	EventServiceStatus,
+	EventSessionStarted,
+	EventSessionEnded,
+	EventInvoicePaid,
+	EventPromiseReceived,
+	EventSettlementCompleted,
+	EventSettlementFailed,
+	EventBalanceChanged,
+	EventBalanceLow,
+	EventSpendingLimitReached,
+	EventNATTraversal,
+}
+
+// validFilter checks whether the filter matches any known event.
+func validFilter(filter string) bool {
+	if filter == "*" {
+		return true
+	}
+	prefix := strings.TrimSuffix(filter, "*")
+	for _, event := range Events {
+		if event == filter || (prefix != filter && strings.HasSuffix(prefix, ".") && strings.HasPrefix(event, prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribe queues the node events published on the bus for posting to the webhooks.
+func (d *Dispatcher) Subscribe(bus eventbus.Subscriber) error {
+	subscriptions := map[string]interface{}{
+		connectionstate.AppTopicConnectionState:    d.handleConnectionState,
+		servicestate.AppTopicServiceStatus:         d.handleServiceStatus,
+		sessionevent.AppTopicSession:               d.handleSession,
+		pingpongevent.AppTopicInvoicePaid:          d.handleInvoicePaid,
+		pingpongevent.AppTopicHermesPromise:        d.handleHermesPromise,
+		pingpongevent.AppTopicSettlementComplete:   d.handleSettlementComplete,
+		pingpongevent.AppTopicBalanceChanged:       d.handleBalanceChanged,
+		pingpongevent.AppTopicSpendingLimitReached: d.handleSpendingLimitReached,
+		natevent.AppTopicTraversal:                 d.handleNATTraversal,
+	}
+	for topic, fn := range subscriptions {
+		if err := bus.SubscribeAsync(topic, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type connectionStateData struct {
+	State       string `json:"state"`
+	SessionID   string `json:"session_id,omitempty"`
+	ConsumerID  string `json:"consumer_id,omitempty"`
+	ProviderID  string `json:"provider_id,omitempty"`
+	ServiceType string `json:"service_type,omitempty"`
+}
+
+func (d *Dispatcher) handleConnectionState(e connectionstate.AppEventConnectionState) {
+	d.enqueue(EventConnectionState, connectionStateData{
+		State:       string(e.State),
+		SessionID:   string(e.SessionInfo.SessionID),
+		ConsumerID:  e.SessionInfo.ConsumerID.Address,
+		ProviderID:  e.SessionInfo.Proposal.ProviderID,
+		ServiceType: e.SessionInfo.Proposal.ServiceType,
+	})
+}
+
+func (d *Dispatcher) handleServiceStatus(e servicestate.AppEventServiceStatus) {
+	d.enqueue(EventServiceStatus, e)
+}
+
+type sessionData struct {
+	SessionID   string    `json:"session_id"`
+	ServiceID   string    `json:"service_id"`
+	ServiceType string    `json:"service_type"`
+	ConsumerID  string    `json:"consumer_id"`
+	StartedAt   time.Time `json:"started_at"`
+}
+
+func (d *Dispatcher) handleSession(e sessionevent.AppEventSession) {
+	var event string
+	switch e.Status {
+	case sessionevent.CreatedStatus:
+		event = EventSessionStarted
+	case sessionevent.RemovedStatus:
+		event = EventSessionEnded
+	default:
+		return
+	}
+	d.enqueue(event, sessionData{
+		SessionID:   e.Session.ID,
+		ServiceID:   e.Service.ID,
+		ServiceType: e.Session.Proposal.ServiceType,
+		ConsumerID:  e.Session.ConsumerID.Address,
+		StartedAt:   e.Session.StartedAt.UTC(),
+	})
+}
+
+type invoicePaidData struct {
+	ConsumerID     string   `json:"consumer_id"`
+	ProviderID     string   `json:"provider_id"`
+	SessionID      string   `json:"session_id"`
+	AgreementTotal *big.Int `json:"agreement_total"`
+}
+
+func (d *Dispatcher) handleInvoicePaid(e pingpongevent.AppEventInvoicePaid) {
+	d.enqueue(EventInvoicePaid, invoicePaidData{
+		ConsumerID:     e.ConsumerID.Address,
+		ProviderID:     e.Invoice.Provider,
+		SessionID:      e.SessionID,
+		AgreementTotal: e.Invoice.AgreementTotal,
+	})
+}
+
+type promiseData struct {
+	ProviderID string   `json:"provider_id"`
+	HermesID   string   `json:"hermes_id"`
+	Amount     *big.Int `json:"amount"`
+}
+
+func (d *Dispatcher) handleHermesPromise(e pingpongevent.AppEventHermesPromise) {
+	d.enqueue(EventPromiseReceived, promiseData{
+		ProviderID: e.ProviderID.Address,
+		HermesID:   e.HermesID.Hex(),
+		Amount:     e.Promise.Amount,
+	})
+}
+
+type settlementData struct {
+	ProviderID string   `json:"provider_id"`
+	HermesID   string   `json:"hermes_id"`
+	Amount     *big.Int `json:"amount,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+func (d *Dispatcher) handleSettlementComplete(e pingpongevent.AppEventSettlementComplete) {
+	data := settlementData{
+		ProviderID: e.ProviderID.Address,
+		HermesID:   e.HermesID.Hex(),
+		Amount:     e.Amount,
+	}
+	if !e.Successful {
+		if e.Error != nil {
+			data.Error = e.Error.Error()
+		}
+		d.enqueue(EventSettlementFailed, data)
+		return
+	}
+	d.enqueue(EventSettlementCompleted, data)
+}
+
+type balanceData struct {
+	Identity  string   `json:"identity"`
+	Previous  *big.Int `json:"previous"`
+	Current   *big.Int `json:"current"`
+	Threshold *big.Int `json:"threshold,omitempty"`
+}
+
+func (d *Dispatcher) handleBalanceChanged(e pingpongevent.AppEventBalanceChanged) {
+	data := balanceData{
+		Identity: e.Identity.Address,
+		Previous: e.Previous,
+		Current:  e.Current,
+	}
+	d.enqueue(EventBalanceChanged, data)
+
+	// Low balance is announced once, when the balance drops below the threshold.
+	threshold := d.config.BalanceLowThreshold
+	if threshold == nil || threshold.Sign() <= 0 || e.Previous == nil || e.Current == nil {
+		return
+	}
+	if e.Previous.Cmp(threshold) >= 0 && e.Current.Cmp(threshold) < 0 {
+		data.Threshold = threshold
+		d.enqueue(EventBalanceLow, data)
+	}
+}
+
+type spendingLimitData struct {
+	ConsumerID string   `json:"consumer_id"`
+	ProviderID string   `json:"provider_id"`
+	SessionID  string   `json:"session_id"`
+	Limit      string   `json:"limit"`
+	Cap        *big.Int `json:"cap"`
+	Spent      *big.Int `json:"spent"`
+}
+
+func (d *Dispatcher) handleSpendingLimitReached(e pingpongevent.AppEventSpendingLimitReached) {
+	d.enqueue(EventSpendingLimitReached, spendingLimitData{
+		ConsumerID: e.ConsumerID.Address,
+		ProviderID: e.ProviderID.Address,
+		SessionID:  e.SessionID,
+		Limit:      e.Limit,
+		Cap:        e.Cap,
+		Spent:      e.Spent,
+	})
+}
+
+type natTraversalData struct {
+	Stage      string `json:"stage"`
+	Successful bool   `json:"successful"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (d *Dispatcher) handleNATTraversal(e natevent.Event) {
+	data := natTraversalData{
+		Stage:      e.Stage,
+		Successful: e.Successful,
+	}
+	if e.Error != nil {
+		data.Error = e.Error.Error()
+	}
+	d.enqueue(EventNATTraversal, data)
+}
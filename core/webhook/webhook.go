@@ -0,0 +1,332 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	webhooksBucket   = "webhooks"
+	deliveriesBucket = "webhook-deliveries"
+)
+
+var (
+	// ErrWebhookNotFound represents an error when the webhook does not exist.
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrDeliveryNotFound represents an error when the webhook delivery does not exist.
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	// ErrDeliveryPending represents an error when retrying the delivery which is still being attempted.
+	ErrDeliveryPending = errors.New("webhook delivery is still pending")
+)
+
+// Webhook is an HTTP endpoint the node events are posted to.
+type Webhook struct {
+	ID  string `storm:"id"`
+	URL string
+	// Events filters the posted events by their names, e.g. "session.started" or "settlement.*", all events are posted when empty.
+	Events []string
+	// Secret signs the posted events with HMAC-SHA256.
+	Secret    string
+	CreatedAt time.Time
+}
+
+// Matches checks whether the event passes the filter of the webhook.
+func (w Webhook) Matches(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, filter := range w.Events {
+		if filter == "*" || filter == event {
+			return true
+		}
+		if strings.HasSuffix(filter, ".*") && strings.HasPrefix(event, strings.TrimSuffix(filter, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryStatus represents the state of the webhook delivery.
+type DeliveryStatus string
+
+const (
+	// DeliveryPending means the delivery is waiting for its next attempt.
+	DeliveryPending = DeliveryStatus("pending")
+	// DeliveryDelivered means the webhook accepted the event.
+	DeliveryDelivered = DeliveryStatus("delivered")
+	// DeliveryFailed means the delivery ran out of attempts.
+	DeliveryFailed = DeliveryStatus("failed")
+)
+
+// Delivery is a node event queued for posting to the webhook.
+type Delivery struct {
+	ID          string `storm:"id"`
+	WebhookID   string
+	Event       string
+	Payload     string
+	Status      DeliveryStatus
+	Attempts    int
+	NextAttempt time.Time
+	LastError   string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// DeliveryFilter selects the listed deliveries, empty fields match everything.
+type DeliveryFilter struct {
+	WebhookID string
+	Status    DeliveryStatus
+}
+
+// Config configures the webhook deliveries.
+type Config struct {
+	// MaxAttempts is the number of attempts to deliver an event before giving up.
+	MaxAttempts int
+	// Retention is how long the delivered and failed deliveries are kept.
+	Retention time.Duration
+	// BalanceLowThreshold is the balance, the balance.low event is posted once the balance drops below, nil disables it.
+	BalanceLowThreshold *big.Int
+}
+
+// Dispatcher posts the node events to the webhooks. Events are queued in the node database
+// and retried with a backoff until the webhook accepts them or the attempts run out.
+type Dispatcher struct {
+	storage storage.Storage
+	client  *http.Client
+	config  Config
+	now     func() time.Time
+
+	lock        sync.Mutex
+	lastCleanup time.Time
+	wake        chan struct{}
+	stop        chan struct{}
+	stopOnce    sync.Once
+}
+
+// NewDispatcher creates webhook dispatcher keeping the webhooks and their deliveries in the given storage.
+func NewDispatcher(storage storage.Storage, config Config) *Dispatcher {
+	return &Dispatcher{
+		storage: storage,
+		client:  &http.Client{Timeout: deliveryTimeout},
+		config:  config,
+		now:     time.Now,
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Create adds the webhook posting the events matching the filters. A secret is generated if none is given.
+func (d *Dispatcher) Create(rawURL string, events []string, secret string) (Webhook, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Webhook{}, fmt.Errorf("invalid webhook URL %q, absolute HTTP(S) URL is required", rawURL)
+	}
+	for _, filter := range events {
+		if !validFilter(filter) {
+			return Webhook{}, fmt.Errorf("unknown webhook event %q", filter)
+		}
+	}
+	if secret == "" {
+		if secret, err = generateSecret(); err != nil {
+			return Webhook{}, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+	}
+
+	webhook := Webhook{
+		ID:        newID(),
+		URL:       rawURL,
+		Events:    events,
+		Secret:    secret,
+		CreatedAt: d.now().UTC(),
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if err := d.storage.Store(webhooksBucket, &webhook); err != nil {
+		return Webhook{}, fmt.Errorf("failed to store webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// List lists the webhooks.
+func (d *Dispatcher) List() ([]Webhook, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	var webhooks []Webhook
+	err := d.storage.GetAllFrom(webhooksBucket, &webhooks)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// Delete deletes the webhook, its pending deliveries fail on their next attempt.
+func (d *Dispatcher) Delete(id string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	var webhook Webhook
+	err := d.storage.GetOneByField(webhooksBucket, "ID", id, &webhook)
+	if errors.Is(err, storage.ErrNotFound) {
+		return ErrWebhookNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get webhook: %w", err)
+	}
+	if err := d.storage.Delete(webhooksBucket, &webhook); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return nil
+}
+
+// Deliveries lists the deliveries, the latest first.
+func (d *Dispatcher) Deliveries(filter DeliveryFilter) ([]Delivery, error) {
+	where := make([]storage.Condition, 0)
+	if filter.WebhookID != "" {
+		where = append(where, storage.Eq("WebhookID", filter.WebhookID))
+	}
+	if filter.Status != "" {
+		where = append(where, storage.Eq("Status", filter.Status))
+	}
+
+	var deliveries []Delivery
+	err := d.storage.Find(deliveriesBucket, storage.Query{Where: where, OrderBy: "CreatedAt", Reverse: true}, &deliveries)
+	if errors.Is(err, storage.ErrNotFound) {
+		return []Delivery{}, nil
+	}
+	return deliveries, err
+}
+
+// Retry queues the finished delivery to be attempted again.
+func (d *Dispatcher) Retry(id string) (Delivery, error) {
+	var delivery Delivery
+	err := d.storage.GetOneByField(deliveriesBucket, "ID", id, &delivery)
+	if errors.Is(err, storage.ErrNotFound) {
+		return Delivery{}, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return Delivery{}, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	if delivery.Status == DeliveryPending {
+		return Delivery{}, ErrDeliveryPending
+	}
+
+	now := d.now().UTC()
+	delivery.Status = DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttempt = now
+	delivery.UpdatedAt = now
+	if err := d.storage.Store(deliveriesBucket, &delivery); err != nil {
+		return Delivery{}, fmt.Errorf("failed to store webhook delivery: %w", err)
+	}
+	d.notify()
+	return delivery, nil
+}
+
+// envelope is the JSON body posted to the webhooks.
+type envelope struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// enqueue queues the event for every webhook it matches.
+func (d *Dispatcher) enqueue(event string, data interface{}) {
+	webhooks, err := d.List()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to queue webhook event")
+		return
+	}
+
+	now := d.now().UTC()
+	var payload []byte
+	for _, webhook := range webhooks {
+		if !webhook.Matches(event) {
+			continue
+		}
+		if payload == nil {
+			payload, err = json.Marshal(envelope{ID: newID(), Event: event, CreatedAt: now, Data: data})
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to encode webhook event %s", event)
+				return
+			}
+		}
+
+		delivery := Delivery{
+			ID:          newID(),
+			WebhookID:   webhook.ID,
+			Event:       event,
+			Payload:     string(payload),
+			Status:      DeliveryPending,
+			NextAttempt: now,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := d.storage.Store(deliveriesBucket, &delivery); err != nil {
+			log.Error().Err(err).Msgf("Failed to queue webhook event %s", event)
+		}
+	}
+	if payload != nil {
+		d.notify()
+	}
+}
+
+func (d *Dispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Sign returns hex encoded HMAC-SHA256 of the body, which is sent in the signature header as "sha256=<signature>".
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func generateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func newID() string {
+	return uuid.Must(uuid.NewV4()).String()
+}
@@ -0,0 +1,251 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+	sessionevent "github.com/mysteriumnetwork/node/session/event"
+	pingpongevent "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook_Matches(t *testing.T) {
+	assert.True(t, Webhook{}.Matches(EventSessionStarted))
+	assert.True(t, Webhook{Events: []string{"*"}}.Matches(EventSessionStarted))
+	assert.True(t, Webhook{Events: []string{EventBalanceLow, "session.*"}}.Matches(EventSessionEnded))
+	assert.True(t, Webhook{Events: []string{EventBalanceLow}}.Matches(EventBalanceLow))
+	assert.False(t, Webhook{Events: []string{EventBalanceLow}}.Matches(EventBalanceChanged))
+	assert.False(t, Webhook{Events: []string{"session.*"}}.Matches(EventSettlementFailed))
+}
+
+func TestDispatcher_Create(t *testing.T) {
+	dispatcher := newTestDispatcher(t, Config{})
+
+	for _, url := range []string{"", "ops.example.com/hooks", "ftp://ops.example.com", "https://"} {
+		_, err := dispatcher.Create(url, nil, "")
+		assert.Error(t, err, url)
+	}
+	for _, event := range []string{"session", "session.", "unknown.*", "balance.lo*"} {
+		_, err := dispatcher.Create("https://ops.example.com/hooks", []string{event}, "")
+		assert.Error(t, err, event)
+	}
+
+	hook, err := dispatcher.Create("https://ops.example.com/hooks", []string{"settlement.*", EventBalanceLow}, "")
+	require.NoError(t, err)
+	assert.Len(t, hook.Secret, 64)
+
+	hooks, err := dispatcher.List()
+	require.NoError(t, err)
+	assert.Equal(t, []Webhook{hook}, hooks)
+
+	assert.NoError(t, dispatcher.Delete(hook.ID))
+	assert.Equal(t, ErrWebhookNotFound, dispatcher.Delete(hook.ID))
+}
+
+func TestDispatcher_DeliversSignedEvents(t *testing.T) {
+	receiver := &testReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	dispatcher := newTestDispatcher(t, Config{MaxAttempts: 3})
+	hook, err := dispatcher.Create(server.URL, []string{"session.*"}, "secret")
+	require.NoError(t, err)
+
+	dispatcher.handleServiceStatus(servicestate.AppEventServiceStatus{ID: "service", Status: "Running"})
+	dispatcher.handleSession(sessionevent.AppEventSession{
+		Status:  sessionevent.CreatedStatus,
+		Service: sessionevent.ServiceContext{ID: "service"},
+		Session: sessionevent.SessionContext{ID: "session", ConsumerID: identity.FromAddress("0x1")},
+	})
+	dispatcher.deliverDue()
+
+	require.Len(t, receiver.requests, 1)
+	req := receiver.requests[0]
+	assert.Equal(t, EventSessionStarted, req.header.Get(HeaderEvent))
+	assert.Equal(t, "sha256="+Sign("secret", req.body), req.header.Get(HeaderSignature))
+
+	var body struct {
+		Event string      `json:"event"`
+		Data  sessionData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(req.body, &body))
+	assert.Equal(t, EventSessionStarted, body.Event)
+	assert.Equal(t, "session", body.Data.SessionID)
+	assert.Equal(t, "0x1", body.Data.ConsumerID)
+
+	deliveries, err := dispatcher.Deliveries(DeliveryFilter{WebhookID: hook.ID})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, req.header.Get(HeaderDelivery), deliveries[0].ID)
+	assert.Equal(t, DeliveryDelivered, deliveries[0].Status)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+}
+
+func TestDispatcher_RetriesFailedDeliveries(t *testing.T) {
+	receiver := &testReceiver{status: http.StatusServiceUnavailable}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	dispatcher := newTestDispatcher(t, Config{MaxAttempts: 2})
+	now := time.Date(2020, 10, 20, 12, 0, 0, 0, time.UTC)
+	dispatcher.now = func() time.Time { return now }
+	_, err := dispatcher.Create(server.URL, nil, "secret")
+	require.NoError(t, err)
+
+	dispatcher.handleSettlementComplete(pingpongevent.AppEventSettlementComplete{
+		ProviderID: identity.FromAddress("0x1"),
+		Error:      errors.New("transaction reverted"),
+	})
+	dispatcher.deliverDue()
+	delivery := onlyDelivery(t, dispatcher)
+	assert.Equal(t, EventSettlementFailed, delivery.Event)
+	assert.Equal(t, DeliveryPending, delivery.Status)
+	assert.Equal(t, "unexpected HTTP status 503 Service Unavailable", delivery.LastError)
+	assert.Equal(t, now.Add(retryBaseDelay), delivery.NextAttempt.UTC())
+
+	// The next attempt is not due yet.
+	dispatcher.deliverDue()
+	assert.Len(t, receiver.requests, 1)
+
+	now = now.Add(retryBaseDelay)
+	dispatcher.deliverDue()
+	assert.Len(t, receiver.requests, 2)
+	delivery = onlyDelivery(t, dispatcher)
+	assert.Equal(t, DeliveryFailed, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+
+	receiver.status = http.StatusOK
+	_, err = dispatcher.Retry(delivery.ID)
+	require.NoError(t, err)
+	dispatcher.deliverDue()
+	assert.Equal(t, DeliveryDelivered, onlyDelivery(t, dispatcher).Status)
+
+	// Finished deliveries are deleted after the retention period.
+	dispatcher.config.Retention = time.Hour
+	now = now.Add(2 * time.Hour)
+	dispatcher.cleanup()
+	deliveries, err := dispatcher.Deliveries(DeliveryFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+}
+
+func TestDispatcher_FailsDeliveriesOfDeletedWebhook(t *testing.T) {
+	dispatcher := newTestDispatcher(t, Config{MaxAttempts: 3})
+	hook, err := dispatcher.Create("http://127.0.0.1:1/hooks", nil, "secret")
+	require.NoError(t, err)
+
+	dispatcher.handleServiceStatus(servicestate.AppEventServiceStatus{ID: "service", Status: "Running"})
+	require.NoError(t, dispatcher.Delete(hook.ID))
+	dispatcher.deliverDue()
+
+	delivery := onlyDelivery(t, dispatcher)
+	assert.Equal(t, DeliveryFailed, delivery.Status)
+	assert.Equal(t, "webhook deleted", delivery.LastError)
+	assert.Equal(t, 0, delivery.Attempts)
+}
+
+func TestDispatcher_AnnouncesLowBalanceOnce(t *testing.T) {
+	dispatcher := newTestDispatcher(t, Config{BalanceLowThreshold: big.NewInt(100)})
+	_, err := dispatcher.Create("https://ops.example.com/hooks", []string{"balance.*"}, "secret")
+	require.NoError(t, err)
+
+	for _, balances := range [][2]int64{{0, 500}, {500, 150}, {150, 90}, {90, 20}, {20, 300}} {
+		dispatcher.handleBalanceChanged(pingpongevent.AppEventBalanceChanged{
+			Identity: identity.FromAddress("0x1"),
+			Previous: big.NewInt(balances[0]),
+			Current:  big.NewInt(balances[1]),
+		})
+	}
+
+	low, err := dispatcher.Deliveries(DeliveryFilter{})
+	require.NoError(t, err)
+	var events []string
+	for _, delivery := range low {
+		if delivery.Event == EventBalanceLow {
+			events = append(events, delivery.Payload)
+		}
+	}
+	require.Len(t, events, 1)
+	var body struct {
+		Data balanceData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(events[0]), &body))
+	assert.Equal(t, big.NewInt(90), body.Data.Current)
+	assert.Equal(t, big.NewInt(100), body.Data.Threshold)
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 10*time.Second, retryDelay(1))
+	assert.Equal(t, 20*time.Second, retryDelay(2))
+	assert.Equal(t, 80*time.Second, retryDelay(4))
+	assert.Equal(t, time.Hour, retryDelay(20))
+}
+
+func newTestDispatcher(t *testing.T, config Config) *Dispatcher {
+	dir, err := ioutil.TempDir("", "webhookTest")
+	require.NoError(t, err)
+	bolt, err := boltdb.NewStorage(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		bolt.Close()
+		os.RemoveAll(dir)
+	})
+	return NewDispatcher(bolt, config)
+}
+
+func onlyDelivery(t *testing.T, dispatcher *Dispatcher) Delivery {
+	deliveries, err := dispatcher.Deliveries(DeliveryFilter{})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	return deliveries[0]
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+type testReceiver struct {
+	lock     sync.Mutex
+	status   int
+	requests []receivedRequest
+}
+
+func (r *testReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.requests = append(r.requests, receivedRequest{header: req.Header, body: body})
+	if r.status != 0 {
+		w.WriteHeader(r.status)
+	}
+}
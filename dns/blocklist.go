@@ -0,0 +1,252 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// BlockMode defines the answer to the blocked DNS queries.
+type BlockMode string
+
+const (
+	// BlockModeNXDomain answers that the blocked name does not exist.
+	BlockModeNXDomain = BlockMode("nxdomain")
+	// BlockModeNull answers with 0.0.0.0 and :: addresses for the blocked name.
+	BlockModeNull = BlockMode("null")
+)
+
+// ParseBlockMode validates the answer to the blocked DNS queries.
+func ParseBlockMode(value string) (BlockMode, error) {
+	switch mode := BlockMode(value); mode {
+	case BlockModeNXDomain, BlockModeNull:
+		return mode, nil
+	}
+	return "", errors.Errorf("unknown DNS block mode %q", value)
+}
+
+// blockedTTL is the TTL of the answers to the blocked queries.
+const blockedTTL = 60
+
+// BlocklistStats describes the loaded blocklists and how many queries they blocked.
+type BlocklistStats struct {
+	Sources   []string
+	Domains   int
+	UpdatedAt time.Time
+	// Hits counts blocked queries by the scope of the blocking handler, e.g. consumer connection or service instance.
+	Hits map[string]uint64
+}
+
+// Blocklist holds the DNS names listed in hosts files and domain lists, loaded from local files or URLs.
+// Subdomains of the listed names are blocked too.
+type Blocklist struct {
+	sources         []string
+	refreshInterval time.Duration
+	mode            BlockMode
+	client          *http.Client
+
+	lock      sync.RWMutex
+	bySource  map[string]map[string]struct{}
+	domains   map[string]struct{}
+	updatedAt time.Time
+	hits      map[string]uint64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewBlocklist creates blocklist of the given sources, which are refreshed by the given interval once started.
+func NewBlocklist(sources []string, refreshInterval time.Duration, mode BlockMode) *Blocklist {
+	return &Blocklist{
+		sources:         sources,
+		refreshInterval: refreshInterval,
+		mode:            mode,
+		client:          &http.Client{Timeout: time.Minute},
+		bySource:        make(map[string]map[string]struct{}),
+		domains:         make(map[string]struct{}),
+		hits:            make(map[string]uint64),
+		stop:            make(chan struct{}),
+	}
+}
+
+// Start loads the blocklists and keeps refreshing them until stopped.
+func (b *Blocklist) Start() {
+	go func() {
+		b.Refresh()
+		if b.refreshInterval <= 0 {
+			return
+		}
+
+		ticker := time.NewTicker(b.refreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-b.stop:
+				return
+			case <-ticker.C:
+				b.Refresh()
+			}
+		}
+	}()
+}
+
+// Stop stops refreshing the blocklists.
+func (b *Blocklist) Stop() {
+	b.stopOnce.Do(func() {
+		close(b.stop)
+	})
+}
+
+// Refresh loads the blocklists again. The names loaded previously are kept for the sources failing to load.
+func (b *Blocklist) Refresh() {
+	loaded := make(map[string]map[string]struct{})
+	for _, source := range b.sources {
+		domains, err := b.load(source)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to load DNS blocklist %s", source)
+			continue
+		}
+		loaded[source] = domains
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for source, domains := range loaded {
+		b.bySource[source] = domains
+	}
+	b.domains = make(map[string]struct{})
+	for _, domains := range b.bySource {
+		for domain := range domains {
+			b.domains[domain] = struct{}{}
+		}
+	}
+	b.updatedAt = time.Now().UTC()
+	log.Info().Msgf("Loaded %d blocked DNS names", len(b.domains))
+}
+
+func (b *Blocklist) load(source string) (map[string]struct{}, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		file, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return parseBlocklist(file)
+	}
+
+	resp, err := b.client.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status %s", resp.Status)
+	}
+	return parseBlocklist(resp.Body)
+}
+
+// parseBlocklist reads names of hosts file, e.g. "0.0.0.0 ads.example.com", or of domain list with a name per line.
+func parseBlocklist(r io.Reader) (map[string]struct{}, error) {
+	domains := make(map[string]struct{})
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 0 && net.ParseIP(fields[0]) != nil {
+			fields = fields[1:]
+		}
+		for _, field := range fields {
+			name := strings.ToLower(dns.Fqdn(field))
+			// Names without a dot are local ones, like localhost.
+			if _, ok := dns.IsDomainName(name); !ok || dns.CountLabel(name) < 2 {
+				continue
+			}
+			domains[name] = struct{}{}
+		}
+	}
+	return domains, scanner.Err()
+}
+
+// IsBlocked checks whether the name or any of its parent domains is listed.
+func (b *Blocklist) IsBlocked(name string) bool {
+	name = strings.ToLower(dns.Fqdn(name))
+
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	for offset, end := 0, false; !end; offset, end = dns.NextLabel(name, offset) {
+		if _, ok := b.domains[name[offset:]]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Stats returns stats of the loaded blocklists.
+func (b *Blocklist) Stats() BlocklistStats {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	hits := make(map[string]uint64, len(b.hits))
+	for scope, count := range b.hits {
+		hits[scope] = count
+	}
+	return BlocklistStats{
+		Sources:   b.sources,
+		Domains:   len(b.domains),
+		UpdatedAt: b.updatedAt,
+		Hits:      hits,
+	}
+}
+
+// block counts the blocked query and returns the answer to it.
+func (b *Blocklist) block(scope string, req *dns.Msg) *dns.Msg {
+	b.lock.Lock()
+	b.hits[scope]++
+	b.lock.Unlock()
+
+	resp := &dns.Msg{}
+	if b.mode != BlockModeNull {
+		return resp.SetRcode(req, dns.RcodeNameError)
+	}
+
+	resp.SetReply(req)
+	question := req.Question[0]
+	header := dns.RR_Header{Name: question.Name, Rrtype: question.Qtype, Class: dns.ClassINET, Ttl: blockedTTL}
+	switch question.Qtype {
+	case dns.TypeA:
+		resp.Answer = append(resp.Answer, &dns.A{Hdr: header, A: net.IPv4zero})
+	case dns.TypeAAAA:
+		resp.Answer = append(resp.Answer, &dns.AAAA{Hdr: header, AAAA: net.IPv6zero})
+	}
+	return resp
+}
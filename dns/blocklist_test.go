@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBlocklist(t *testing.T) {
+	domains, err := parseBlocklist(strings.NewReader(`
+# hosts file
+127.0.0.1 localhost
+0.0.0.0 ads.example.com tracker.example.com # trailing comment
+::1 ip6-localhost
+
+malware.example.net
+Upper.Example.ORG.
+`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{
+		"ads.example.com.":     {},
+		"tracker.example.com.": {},
+		"malware.example.net.": {},
+		"upper.example.org.":   {},
+	}, domains)
+}
+
+func TestBlocklist_Refresh(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blocklist")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "domains.txt")
+	require.NoError(t, ioutil.WriteFile(file, []byte("ads.example.com\n"), 0600))
+
+	listed := "0.0.0.0 tracker.example.com\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if listed == "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(listed))
+	}))
+	defer server.Close()
+
+	blocklist := NewBlocklist([]string{file, server.URL}, 0, BlockModeNXDomain)
+	blocklist.Refresh()
+	assert.True(t, blocklist.IsBlocked("ads.example.com"))
+	assert.True(t, blocklist.IsBlocked("cdn.tracker.example.com."))
+	assert.False(t, blocklist.IsBlocked("example.com"))
+	assert.False(t, blocklist.IsBlocked("notads.example.com"))
+	assert.Equal(t, 2, blocklist.Stats().Domains)
+
+	// Names of the failing source are kept until it loads again.
+	listed = ""
+	require.NoError(t, ioutil.WriteFile(file, []byte("malware.example.net\n"), 0600))
+	blocklist.Refresh()
+	assert.False(t, blocklist.IsBlocked("ads.example.com"))
+	assert.True(t, blocklist.IsBlocked("malware.example.net"))
+	assert.True(t, blocklist.IsBlocked("tracker.example.com"))
+}
+
+func TestBlockByList(t *testing.T) {
+	resolver := &mockUpstream{ip: "1.2.3.4"}
+	blocklist := NewBlocklist(nil, 0, BlockModeNXDomain)
+	blocklist.domains["ads.example.com."] = struct{}{}
+	handler := BlockByList(upstreamHandler{resolver}, blocklist, "consumer")
+
+	resp := serve(handler, "cdn.ads.example.com")
+	assert.Equal(t, dns.RcodeNameError, resp.Rcode)
+	assert.Equal(t, 0, resolver.queries)
+
+	resp = serve(handler, "example.com")
+	assert.Equal(t, "1.2.3.4", resp.Answer[0].(*dns.A).A.String())
+	assert.Equal(t, 1, resolver.queries)
+
+	assert.Equal(t, map[string]uint64{"consumer": 1}, blocklist.Stats().Hits)
+}
+
+func TestBlockByList_NullMode(t *testing.T) {
+	blocklist := NewBlocklist(nil, 0, BlockModeNull)
+	blocklist.domains["ads.example.com."] = struct{}{}
+	handler := BlockByList(upstreamHandler{&mockUpstream{}}, blocklist, "service")
+
+	resp := serve(handler, "ads.example.com")
+	assert.Equal(t, dns.RcodeSuccess, resp.Rcode)
+	assert.Equal(t, "0.0.0.0", resp.Answer[0].(*dns.A).A.String())
+
+	writer := &recordingWriter{}
+	handler.ServeDNS(writer, new(dns.Msg).SetQuestion("ads.example.com.", dns.TypeAAAA))
+	assert.Equal(t, "::", writer.responseMsg.Answer[0].(*dns.AAAA).AAAA.String())
+
+	assert.Equal(t, map[string]uint64{"service": 2}, blocklist.Stats().Hits)
+}
+
+func TestResolveViaServers(t *testing.T) {
+	addr := serveDNS(t, upstreamHandler{&mockUpstream{ip: "1.2.3.4"}})
+	dial := func(ctx context.Context, network, address string) (net.Conn, error) {
+		if address != "192.0.2.2:53" {
+			return nil, &net.OpError{Op: "dial", Net: network, Err: assert.AnError}
+		}
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	}
+
+	resp := serve(ResolveViaServers([]string{"192.0.2.1", "192.0.2.2"}, dial), "example.com")
+	assert.Equal(t, "1.2.3.4", resp.Answer[0].(*dns.A).A.String())
+
+	resp = serve(ResolveViaServers([]string{"192.0.2.1"}, dial), "example.com")
+	assert.Equal(t, dns.RcodeServerFailure, resp.Rcode)
+}
+
+// upstreamHandler serves the queries by the upstream.
+type upstreamHandler struct {
+	upstream upstream
+}
+
+func (h upstreamHandler) ServeDNS(writer dns.ResponseWriter, req *dns.Msg) {
+	resp, err := h.upstream.exchange(context.Background(), req)
+	if err != nil {
+		resp = new(dns.Msg).SetRcode(req, dns.RcodeServerFailure)
+	}
+	writer.WriteMsg(resp)
+}
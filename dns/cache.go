@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const defaultCacheSize = 1000
+
+type cacheKey struct {
+	name          string
+	qtype, qclass uint16
+}
+
+type cacheEntry struct {
+	resp      *dns.Msg
+	storedAt  time.Time
+	expiresAt time.Time
+}
+
+// responseCache keeps DNS responses for the least TTL of their records.
+type responseCache struct {
+	size    int
+	now     func() time.Time
+	lock    sync.Mutex
+	entries map[cacheKey]cacheEntry
+}
+
+func newResponseCache(size int) *responseCache {
+	return &responseCache{
+		size:    size,
+		now:     time.Now,
+		entries: make(map[cacheKey]cacheEntry),
+	}
+}
+
+func keyOf(req *dns.Msg) (cacheKey, bool) {
+	if len(req.Question) != 1 {
+		return cacheKey{}, false
+	}
+	q := req.Question[0]
+	return cacheKey{name: strings.ToLower(q.Name), qtype: q.Qtype, qclass: q.Qclass}, true
+}
+
+// get returns a copy of the cached response to the request, with the TTLs reduced by the time it was cached for.
+func (c *responseCache) get(req *dns.Msg) (*dns.Msg, bool) {
+	key, ok := keyOf(req)
+	if !ok {
+		return nil, false
+	}
+
+	c.lock.Lock()
+	entry, ok := c.entries[key]
+	c.lock.Unlock()
+	now := c.now()
+	if !ok || !now.Before(entry.expiresAt) {
+		return nil, false
+	}
+
+	resp := entry.resp.Copy()
+	resp.Id = req.Id
+	resp.Question = req.Question
+	elapsed := uint32(now.Sub(entry.storedAt) / time.Second)
+	for _, records := range [][]dns.RR{resp.Answer, resp.Ns, resp.Extra} {
+		for _, rr := range records {
+			if header := rr.Header(); header.Rrtype != dns.TypeOPT && header.Ttl > elapsed {
+				header.Ttl -= elapsed
+			}
+		}
+	}
+	return resp, true
+}
+
+func (c *responseCache) put(req *dns.Msg, resp *dns.Msg) {
+	key, ok := keyOf(req)
+	if !ok || resp.Truncated || (resp.Rcode != dns.RcodeSuccess && resp.Rcode != dns.RcodeNameError) {
+		return
+	}
+	ttl, ok := leastTTL(resp)
+	if !ok || ttl == 0 {
+		return
+	}
+
+	now := c.now()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.entries) >= c.size {
+		c.evict(now)
+	}
+	c.entries[key] = cacheEntry{
+		resp:      resp.Copy(),
+		storedAt:  now,
+		expiresAt: now.Add(time.Duration(ttl) * time.Second),
+	}
+}
+
+// evict removes expired entries, or an arbitrary one if none has expired.
+func (c *responseCache) evict(now time.Time) {
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	for key := range c.entries {
+		if len(c.entries) < c.size {
+			return
+		}
+		delete(c.entries, key)
+	}
+}
+
+// leastTTL returns the least TTL of the answer records, or of the authority records for the negative responses.
+func leastTTL(resp *dns.Msg) (ttl uint32, ok bool) {
+	records := resp.Answer
+	if len(records) == 0 {
+		records = resp.Ns
+	}
+	for _, rr := range records {
+		if !ok || rr.Header().Ttl < ttl {
+			ttl, ok = rr.Header().Ttl, true
+		}
+	}
+	return ttl, ok
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"github.com/miekg/dns"
+)
+
+// BlockByList creates a DNS handler, which answers the queries of the names in the blocklist by itself,
+// passing the rest to the resolver. Blocked queries are counted under the given scope.
+func BlockByList(resolver dns.Handler, blocklist *Blocklist, scope string) dns.Handler {
+	return &blocklistHandler{
+		resolver:  resolver,
+		blocklist: blocklist,
+		scope:     scope,
+	}
+}
+
+type blocklistHandler struct {
+	resolver  dns.Handler
+	blocklist *Blocklist
+	scope     string
+}
+
+func (bh *blocklistHandler) ServeDNS(writer dns.ResponseWriter, req *dns.Msg) {
+	if len(req.Question) == 1 && bh.blocklist.IsBlocked(req.Question[0].Name) {
+		writer.WriteMsg(bh.blocklist.block(bh.scope, req))
+		return
+	}
+
+	bh.resolver.ServeDNS(writer, req)
+}
@@ -0,0 +1,228 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// SchemeDoH is the scheme of DNS-over-HTTPS upstream, e.g. doh://1.1.1.1/dns-query.
+	SchemeDoH = "doh"
+	// SchemeDoT is the scheme of DNS-over-TLS upstream, e.g. dot://1.1.1.1.
+	SchemeDoT = "dot"
+)
+
+// DialContextFunc connects to the address on the named network.
+type DialContextFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+type upstream interface {
+	exchange(ctx context.Context, req *dns.Msg) (*dns.Msg, error)
+	String() string
+}
+
+// ParseUpstream validates the encrypted DNS upstream given as doh://<IP>[:port]/path or dot://<IP>[:port].
+// The host has to be an IP address, so that reaching the upstream does not need a plaintext DNS query.
+func ParseUpstream(value string) (*url.URL, error) {
+	u, err := url.Parse(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid DNS upstream %q", value)
+	}
+	if u.Scheme != SchemeDoH && u.Scheme != SchemeDoT {
+		return nil, errors.Errorf("DNS upstream %q has unsupported scheme, expected %s:// or %s://", value, SchemeDoH, SchemeDoT)
+	}
+	if net.ParseIP(u.Hostname()) == nil {
+		return nil, errors.Errorf("DNS upstream %q has to be given by IP address", value)
+	}
+	return u, nil
+}
+
+// ResolveViaEncrypted creates DNS handler, which forwards queries to the given DNS-over-HTTPS and DNS-over-TLS upstreams,
+// falling back to the next upstream in order if the previous one fails. The upstreams are connected by the given dialer,
+// so that the queries are sent through the tunnel. Responses are cached for their TTL.
+func ResolveViaEncrypted(upstreams []string, dial DialContextFunc) (dns.Handler, error) {
+	handler := &encryptedHandler{cache: newResponseCache(defaultCacheSize)}
+	for _, value := range upstreams {
+		u, err := ParseUpstream(value)
+		if err != nil {
+			return nil, err
+		}
+		switch u.Scheme {
+		case SchemeDoH:
+			handler.upstreams = append(handler.upstreams, newDoHUpstream(u, dial))
+		case SchemeDoT:
+			handler.upstreams = append(handler.upstreams, newDoTUpstream(u, dial))
+		}
+	}
+	if len(handler.upstreams) == 0 {
+		return nil, errors.New("no encrypted DNS upstreams given")
+	}
+	return handler, nil
+}
+
+type encryptedHandler struct {
+	upstreams []upstream
+	cache     *responseCache
+}
+
+func (eh *encryptedHandler) ServeDNS(writer dns.ResponseWriter, req *dns.Msg) {
+	if resp, ok := eh.cache.get(req); ok {
+		writer.WriteMsg(resp)
+		return
+	}
+
+	for _, upstream := range eh.upstreams {
+		ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
+		resp, err := upstream.exchange(ctx, req)
+		cancel()
+		if err == nil && resp.Rcode == dns.RcodeServerFailure {
+			err = errors.New("server failure")
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("Error resolving DNS query via " + upstream.String())
+			continue
+		}
+
+		resp.Id = req.Id
+		eh.cache.put(req, resp)
+		writer.WriteMsg(resp)
+		return
+	}
+
+	resp := &dns.Msg{}
+	resp.SetRcode(req, dns.RcodeServerFailure)
+	writer.WriteMsg(resp)
+}
+
+// dohUpstream sends queries as defined by RFC 8484.
+type dohUpstream struct {
+	url    string
+	client *http.Client
+}
+
+func newDoHUpstream(u *url.URL, dial DialContextFunc) *dohUpstream {
+	endpoint := *u
+	endpoint.Scheme = "https"
+	return &dohUpstream{
+		url: endpoint.String(),
+		client: &http.Client{
+			Timeout: dnsTimeout,
+			Transport: &http.Transport{
+				DialContext:         dial,
+				TLSHandshakeTimeout: dnsTimeout,
+				ForceAttemptHTTP2:   true,
+				MaxIdleConns:        1,
+			},
+		},
+	}
+}
+
+func (u *dohUpstream) exchange(ctx context.Context, req *dns.Msg) (*dns.Msg, error) {
+	// ID is zeroed to let HTTP caches reuse the responses.
+	query := req.Copy()
+	query.Id = 0
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pack DNS query")
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, u.url, bytes.NewReader(packed))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/dns-message")
+	httpReq.Header.Set("Accept", "application/dns-message")
+
+	httpResp, err := u.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status %s", httpResp.Status)
+	}
+
+	body, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read DNS response")
+	}
+	resp := &dns.Msg{}
+	if err := resp.Unpack(body); err != nil {
+		return nil, errors.Wrap(err, "failed to unpack DNS response")
+	}
+	return resp, nil
+}
+
+func (u *dohUpstream) String() string {
+	return u.url
+}
+
+// dotUpstream sends queries as defined by RFC 7858.
+type dotUpstream struct {
+	addr      string
+	tlsConfig *tls.Config
+	dial      DialContextFunc
+}
+
+func newDoTUpstream(u *url.URL, dial DialContextFunc) *dotUpstream {
+	port := u.Port()
+	if port == "" {
+		port = "853"
+	}
+	return &dotUpstream{
+		addr:      net.JoinHostPort(u.Hostname(), port),
+		tlsConfig: &tls.Config{ServerName: u.Hostname()},
+		dial:      dial,
+	}
+}
+
+func (u *dotUpstream) exchange(ctx context.Context, req *dns.Msg) (*dns.Msg, error) {
+	rawConn, err := u.dial(ctx, "tcp", u.addr)
+	if err != nil {
+		return nil, err
+	}
+	conn := tls.Client(rawConn, u.tlsConfig)
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if err := conn.HandshakeContext(ctx); err != nil {
+		return nil, errors.Wrap(err, "TLS handshake failed")
+	}
+
+	co := &dns.Conn{Conn: conn}
+	if err := co.WriteMsg(req); err != nil {
+		return nil, err
+	}
+	return co.ReadMsg()
+}
+
+func (u *dotUpstream) String() string {
+	return SchemeDoT + "://" + u.addr
+}
@@ -0,0 +1,203 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUpstream(t *testing.T) {
+	for _, value := range []string{"doh://1.1.1.1/dns-query", "dot://9.9.9.9", "dot://[2620:fe::fe]:853"} {
+		_, err := ParseUpstream(value)
+		assert.NoError(t, err, value)
+	}
+	for _, value := range []string{"doh://dns.google/dns-query", "https://1.1.1.1/dns-query", "dot://", "1.1.1.1"} {
+		_, err := ParseUpstream(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestEncryptedHandler_FallsBackToNextUpstream(t *testing.T) {
+	failing := &mockUpstream{err: errors.New("unreachable")}
+	serverFailure := &mockUpstream{rcode: dns.RcodeServerFailure}
+	working := &mockUpstream{ip: "1.2.3.4"}
+	handler := &encryptedHandler{
+		upstreams: []upstream{failing, serverFailure, working},
+		cache:     newResponseCache(defaultCacheSize),
+	}
+
+	resp := serve(handler, "example.com")
+	assert.Equal(t, dns.RcodeSuccess, resp.Rcode)
+	assert.Equal(t, "1.2.3.4", resp.Answer[0].(*dns.A).A.String())
+	assert.Equal(t, 1, failing.queries)
+	assert.Equal(t, 1, serverFailure.queries)
+	assert.Equal(t, 1, working.queries)
+
+	// Cached response is served without querying the upstreams.
+	resp = serve(handler, "EXAMPLE.com")
+	assert.Equal(t, "1.2.3.4", resp.Answer[0].(*dns.A).A.String())
+	assert.Equal(t, 1, working.queries)
+}
+
+func TestEncryptedHandler_FailsIfAllUpstreamsFail(t *testing.T) {
+	handler := &encryptedHandler{
+		upstreams: []upstream{&mockUpstream{err: errors.New("unreachable")}},
+		cache:     newResponseCache(defaultCacheSize),
+	}
+
+	resp := serve(handler, "example.com")
+	assert.Equal(t, dns.RcodeServerFailure, resp.Rcode)
+}
+
+func TestResponseCache_Expires(t *testing.T) {
+	now := time.Now()
+	cache := newResponseCache(defaultCacheSize)
+	cache.now = func() time.Time { return now }
+
+	req := new(dns.Msg).SetQuestion("example.com.", dns.TypeA)
+	cache.put(req, answer(req, "1.2.3.4", 60))
+
+	now = now.Add(20 * time.Second)
+	resp, ok := cache.get(req)
+	require.True(t, ok)
+	assert.Equal(t, uint32(40), resp.Answer[0].Header().Ttl)
+
+	now = now.Add(40 * time.Second)
+	_, ok = cache.get(req)
+	assert.False(t, ok)
+}
+
+func TestDoHUpstream_Exchange(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/dns-message", r.Header.Get("Content-Type"))
+		body, _ := ioutil.ReadAll(r.Body)
+		req := &dns.Msg{}
+		require.NoError(t, req.Unpack(body))
+		packed, _ := answer(req, "1.2.3.4", 60).Pack()
+		w.Header().Set("Content-Type", "application/dns-message")
+		w.Write(packed)
+	}))
+	defer server.Close()
+
+	u, err := ParseUpstream("doh://" + server.Listener.Addr().String() + "/dns-query")
+	require.NoError(t, err)
+	upstream := newDoHUpstream(u, (&net.Dialer{}).DialContext)
+	upstream.client.Transport.(*http.Transport).TLSClientConfig = &tls.Config{RootCAs: rootCAs(server)}
+
+	resp, err := upstream.exchange(context.Background(), new(dns.Msg).SetQuestion("example.com.", dns.TypeA))
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", resp.Answer[0].(*dns.A).A.String())
+}
+
+func TestDoTUpstream_Exchange(t *testing.T) {
+	certServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer certServer.Close()
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: certServer.TLS.Certificates})
+	require.NoError(t, err)
+	server := &dns.Server{
+		Listener: listener,
+		Net:      "tcp-tls",
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			w.WriteMsg(answer(req, "1.2.3.4", 60))
+		}),
+	}
+	go server.ActivateAndServe()
+	defer server.Shutdown()
+
+	u, err := ParseUpstream("dot://" + listener.Addr().String())
+	require.NoError(t, err)
+	upstream := newDoTUpstream(u, (&net.Dialer{}).DialContext)
+	upstream.tlsConfig.RootCAs = rootCAs(certServer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := upstream.exchange(ctx, new(dns.Msg).SetQuestion("example.com.", dns.TypeA))
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", resp.Answer[0].(*dns.A).A.String())
+}
+
+func TestNewResolver(t *testing.T) {
+	handler := &encryptedHandler{
+		upstreams: []upstream{&mockUpstream{ip: "1.2.3.4"}},
+		cache:     newResponseCache(defaultCacheSize),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ips, err := NewResolver(handler).LookupIP(ctx, "ip4", "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", ips[0].String())
+}
+
+type mockUpstream struct {
+	ip      string
+	rcode   int
+	err     error
+	queries int
+}
+
+func (m *mockUpstream) exchange(_ context.Context, req *dns.Msg) (*dns.Msg, error) {
+	m.queries++
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.rcode != dns.RcodeSuccess {
+		return new(dns.Msg).SetRcode(req, m.rcode), nil
+	}
+	return answer(req, m.ip, 60), nil
+}
+
+func (m *mockUpstream) String() string {
+	return "mock"
+}
+
+func answer(req *dns.Msg, ip string, ttl uint32) *dns.Msg {
+	resp := new(dns.Msg).SetReply(req)
+	if req.Question[0].Qtype == dns.TypeA {
+		resp.Answer = append(resp.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl},
+			A:   net.ParseIP(ip),
+		})
+	}
+	return resp
+}
+
+func serve(handler dns.Handler, host string) *dns.Msg {
+	writer := &recordingWriter{}
+	handler.ServeDNS(writer, new(dns.Msg).SetQuestion(dns.Fqdn(host), dns.TypeA))
+	return writer.responseMsg
+}
+
+func rootCAs(server *httptest.Server) *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	return pool
+}
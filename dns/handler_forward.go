@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"context"
+	"net"
+
+	"github.com/miekg/dns"
+	"github.com/rs/zerolog/log"
+)
+
+// ResolveViaServers creates DNS handler, which forwards queries to the given DNS servers in order,
+// connecting them by the given dialer.
+func ResolveViaServers(servers []string, dial DialContextFunc) dns.Handler {
+	handler := &forwardHandler{dial: dial}
+	for _, server := range servers {
+		handler.addrs = append(handler.addrs, net.JoinHostPort(server, "53"))
+	}
+	return handler
+}
+
+type forwardHandler struct {
+	addrs []string
+	dial  DialContextFunc
+}
+
+func (fh *forwardHandler) ServeDNS(writer dns.ResponseWriter, req *dns.Msg) {
+	for _, addr := range fh.addrs {
+		resp, err := fh.exchange(req, "udp", addr)
+		if err == nil && resp.Truncated {
+			resp, err = fh.exchange(req, "tcp", addr)
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("Error proxying DNS query to " + addr)
+			continue
+		}
+
+		writer.WriteMsg(resp)
+		return
+	}
+
+	resp := &dns.Msg{}
+	resp.SetRcode(req, dns.RcodeServerFailure)
+	writer.WriteMsg(resp)
+}
+
+func (fh *forwardHandler) exchange(req *dns.Msg, network, addr string) (*dns.Msg, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
+	defer cancel()
+
+	conn, err := fh.dial(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	co := &dns.Conn{Conn: conn}
+	defer co.Close()
+	deadline, _ := ctx.Deadline()
+	co.SetDeadline(deadline)
+
+	if err := co.WriteMsg(req); err != nil {
+		return nil, err
+	}
+	return co.ReadMsg()
+}
@@ -18,6 +18,7 @@
 package dns
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/miekg/dns"
@@ -63,6 +64,12 @@ func (wh *whitelistHandler) ServeDNS(writer dns.ResponseWriter, req *dns.Msg) {
 }
 
 func (wh *whitelistHandler) whitelistByAnswer(response *dns.Msg) error {
+	for _, question := range response.Question {
+		if err := wh.checkDenied(strings.TrimRight(question.Name, ".")); err != nil {
+			return err
+		}
+	}
+
 	for _, record := range response.Answer {
 		switch recordValue := record.(type) {
 		case *dns.A:
@@ -78,6 +85,10 @@ func (wh *whitelistHandler) whitelistByARecord(record *dns.A) error {
 	host := strings.TrimRight(record.Hdr.Name, ".")
 	ip := record.A
 
+	if err := wh.checkDenied(host); err != nil {
+		return err
+	}
+
 	if wh.policies.IsHostAllowed(host) {
 		_, err := wh.trafficBlocker.AllowIPAccess(ip)
 		return err
@@ -85,3 +96,10 @@ func (wh *whitelistHandler) whitelistByARecord(record *dns.A) error {
 
 	return nil
 }
+
+func (wh *whitelistHandler) checkDenied(host string) error {
+	if wh.policies.IsHostDenied(host) {
+		return fmt.Errorf("host %s is denied by access policy", host)
+	}
+	return nil
+}
@@ -35,6 +35,9 @@ var (
 		Allow: []market.AccessRule{
 			{Type: market.AccessPolicyTypeDNSZone, Value: "wildcard.com"},
 		},
+		Deny: []market.AccessRule{
+			{Type: market.AccessPolicyTypeDNSHostname, Value: "blocked.wildcard.com"},
+		},
 	}
 
 	policyDNSHostname      = market.AccessPolicy{ID: "domain"}
@@ -102,7 +105,18 @@ func Test_WhitelistAnswers(t *testing.T) {
 			},
 			map[string]int{},
 		},
-
+		{
+			"should not allow lookalike of whitelisted wildcard hostname",
+			&dns.Msg{
+				Answer: []dns.RR{
+					&dns.A{
+						Hdr: dns.RR_Header{Name: "badwildcard.com.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 0},
+						A:   net.ParseIP("0.0.0.5"),
+					},
+				},
+			},
+			map[string]int{},
+		},
 		{
 			"should allow whitelisted wildcard hostname",
 			&dns.Msg{
@@ -164,6 +178,35 @@ func Test_WhitelistAnswers(t *testing.T) {
 	}
 }
 
+func Test_WhitelistAnswers_RejectsDeniedHost(t *testing.T) {
+	mockedBlocker := &trafficBlockerMock{
+		allowIPCalls: map[string]int{},
+	}
+	writer := &recordingWriter{}
+	handler := WhitelistAnswers(
+		dns.HandlerFunc(func(writer dns.ResponseWriter, req *dns.Msg) {
+			resp := &dns.Msg{}
+			resp.SetReply(req)
+			resp.Answer = []dns.RR{
+				&dns.A{
+					Hdr: dns.RR_Header{Name: "blocked.wildcard.com.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 0},
+					A:   net.ParseIP("0.0.0.10"),
+				},
+			}
+			writer.WriteMsg(resp)
+		}),
+		mockedBlocker,
+		createPolicies(),
+	)
+
+	req := &dns.Msg{}
+	req.SetQuestion("blocked.wildcard.com.", dns.TypeA)
+	handler.ServeDNS(writer, req)
+	assert.Equal(t, map[string]int{}, mockedBlocker.allowIPCalls)
+	assert.Equal(t, dns.RcodeNameError, writer.responseMsg.Rcode)
+	assert.Empty(t, writer.responseMsg.Answer)
+}
+
 func createPolicies() *policy.Repository {
 	repo := policy.NewRepository()
 	repo.SetPolicyRules(policyDNSZone, policyDNSZoneRules)
@@ -187,6 +230,14 @@ func (tbn *trafficBlockerMock) AllowURLAccess(rawURLs ...string) (firewall.Incom
 	return nil, nil
 }
 
+func (tbn *trafficBlockerMock) AllowTraffic(firewall.TrafficRule) (firewall.IncomingRuleRemove, error) {
+	return nil, nil
+}
+
+func (tbn *trafficBlockerMock) DenyTraffic(firewall.TrafficRule) (firewall.IncomingRuleRemove, error) {
+	return nil, nil
+}
+
 func (tbn *trafficBlockerMock) AllowIPAccess(ip net.IP) (firewall.IncomingRuleRemove, error) {
 	ipString := ip.String()
 	if _, called := tbn.allowIPCalls[ipString]; !called {
@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"context"
+	"net"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// LookupIPv4 resolves IPv4 addresses of the given host via the system DNS servers.
+func LookupIPv4(host string) ([]net.IP, error) {
+	cfg, err := configuration()
+	if err != nil {
+		// System DNS configuration is not available on every platform, fallback to the OS resolver.
+		log.Debug().Err(err).Msgf("Resolving %s via OS resolver", host)
+		return lookupIPv4ViaOS(host)
+	}
+
+	var servers []string
+	for _, server := range cfg.Servers {
+		servers = append(servers, net.JoinHostPort(server, cfg.Port))
+	}
+	return lookupIPv4(host, servers)
+}
+
+func lookupIPv4(host string, servers []string) ([]net.IP, error) {
+	client := &dns.Client{
+		DialTimeout:  dnsTimeout,
+		ReadTimeout:  dnsTimeout,
+		WriteTimeout: dnsTimeout,
+	}
+
+	req := &dns.Msg{}
+	req.SetQuestion(dns.Fqdn(host), dns.TypeA)
+
+	err := errors.New("no DNS servers configured")
+	for _, addr := range servers {
+		var resp *dns.Msg
+		resp, _, err = client.Exchange(req, addr)
+		if err != nil {
+			log.Error().Err(err).Msg("Error resolving DNS query via " + addr)
+			continue
+		}
+		if resp.Rcode != dns.RcodeSuccess {
+			return nil, errors.Errorf("failed to resolve %s: %s", host, dns.RcodeToString[resp.Rcode])
+		}
+
+		var ips []net.IP
+		for _, record := range resp.Answer {
+			if a, ok := record.(*dns.A); ok {
+				ips = append(ips, a.A)
+			}
+		}
+		if len(ips) == 0 {
+			return nil, errors.Errorf("no IPv4 addresses found for %s", host)
+		}
+		return ips, nil
+	}
+
+	return nil, errors.Wrapf(err, "failed to resolve %s", host)
+}
+
+func lookupIPv4ViaOS(host string) ([]net.IP, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
+	defer cancel()
+
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip4", host)
+	return ips, errors.Wrapf(err, "failed to resolve %s", host)
+}
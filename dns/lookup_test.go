@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_lookupIPv4(t *testing.T) {
+	addr := serveDNS(t, dns.HandlerFunc(func(writer dns.ResponseWriter, req *dns.Msg) {
+		resp := &dns.Msg{}
+		switch req.Question[0].Name {
+		case "corp.example.":
+			resp.SetReply(req)
+			resp.Answer = []dns.RR{
+				&dns.CNAME{
+					Hdr:    dns.RR_Header{Name: "corp.example.", Rrtype: dns.TypeCNAME, Class: dns.ClassINET},
+					Target: "lb.corp.example.",
+				},
+				&dns.A{
+					Hdr: dns.RR_Header{Name: "lb.corp.example.", Rrtype: dns.TypeA, Class: dns.ClassINET},
+					A:   net.ParseIP("10.1.0.1"),
+				},
+				&dns.A{
+					Hdr: dns.RR_Header{Name: "lb.corp.example.", Rrtype: dns.TypeA, Class: dns.ClassINET},
+					A:   net.ParseIP("10.1.0.2"),
+				},
+			}
+		case "empty.example.":
+			resp.SetReply(req)
+		default:
+			resp.SetRcode(req, dns.RcodeNameError)
+		}
+		writer.WriteMsg(resp)
+	}))
+
+	ips, err := lookupIPv4("corp.example", []string{addr})
+	assert.NoError(t, err)
+	assert.Equal(t, []net.IP{net.ParseIP("10.1.0.1").To4(), net.ParseIP("10.1.0.2").To4()}, ips)
+
+	_, err = lookupIPv4("empty.example", []string{addr})
+	assert.EqualError(t, err, "no IPv4 addresses found for empty.example")
+
+	_, err = lookupIPv4("unknown.example", []string{addr})
+	assert.EqualError(t, err, "failed to resolve unknown.example: NXDOMAIN")
+
+	_, err = lookupIPv4("corp.example", nil)
+	assert.EqualError(t, err, "failed to resolve corp.example: no DNS servers configured")
+}
+
+func serveDNS(t *testing.T, handler dns.Handler) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	server := &dns.Server{PacketConn: conn, Handler: handler, NotifyStartedFunc: func() { close(started) }}
+	go server.ActivateAndServe()
+	t.Cleanup(func() { server.Shutdown() })
+	<-started
+
+	return conn.LocalAddr().String()
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"context"
+	"net"
+
+	"github.com/miekg/dns"
+)
+
+// NewResolver returns resolver, which serves the lookups by the given handler in the process,
+// without any system DNS server involved.
+func NewResolver(handler dns.Handler) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(_ context.Context, _, _ string) (net.Conn, error) {
+			client, server := net.Pipe()
+			go serveConn(handler, server)
+			return client, nil
+		},
+	}
+}
+
+// serveConn serves the queries received on the stream connection until it is closed.
+func serveConn(handler dns.Handler, conn net.Conn) {
+	co := &dns.Conn{Conn: conn}
+	defer co.Close()
+	for {
+		req, err := co.ReadMsg()
+		if err != nil {
+			return
+		}
+		handler.ServeDNS(&connWriter{co}, req)
+	}
+}
+
+type connWriter struct {
+	*dns.Conn
+}
+
+func (cw *connWriter) WriteMsg(m *dns.Msg) error {
+	return cw.Conn.WriteMsg(m)
+}
+
+func (cw *connWriter) TsigStatus() error {
+	return nil
+}
+
+func (cw *connWriter) TsigTimersOnly(bool) {}
+
+func (cw *connWriter) Hijack() {}
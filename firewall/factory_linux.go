@@ -19,23 +19,36 @@
 
 package firewall
 
+import "github.com/mysteriumnetwork/node/firewall/nftables"
+
 // NewOutgoingTrafficFirewall creates firewall instance for outgoing traffic.
+// nftables are used when preferred by the chosen firewall backend, iptables are used otherwise.
 func NewOutgoingTrafficFirewall(enabled bool) OutgoingTrafficFirewall {
-	if enabled {
-		return &outgoingFirewallIptables{
+	if !enabled {
+		return &outgoingFirewallNoop{}
+	}
+
+	if nftables.Preferred() {
+		return &outgoingFirewallNftables{
 			referenceTracker: make(map[string]refCount),
 			trafficLockScope: none,
 		}
 	}
-
-	return &outgoingFirewallNoop{}
+	return &outgoingFirewallIptables{
+		referenceTracker: make(map[string]refCount),
+		trafficLockScope: none,
+	}
 }
 
 // NewIncomingTrafficFirewall creates firewall instance for incoming traffic.
+// nftables are used when preferred by the chosen firewall backend, iptables and ipset are used otherwise.
 func NewIncomingTrafficFirewall(enabled bool) IncomingTrafficFirewall {
-	if enabled {
-		return &incomingFirewallIptables{}
+	if !enabled {
+		return &incomingFirewallNoop{}
 	}
 
-	return &incomingFirewallNoop{}
+	if nftables.Preferred() {
+		return &incomingFirewallNftables{}
+	}
+	return &incomingFirewallIptables{}
 }
@@ -18,7 +18,10 @@
 package firewall
 
 import (
+	"fmt"
 	"net"
+
+	"github.com/mysteriumnetwork/node/core/port"
 )
 
 // IncomingTrafficFirewall defines provider side firewall, to control which traffic is enabled to pass and which not.
@@ -28,7 +31,35 @@ type IncomingTrafficFirewall interface {
 	BlockIncomingTraffic(network net.IPNet) (IncomingRuleRemove, error)
 	AllowURLAccess(rawURLs ...string) (IncomingRuleRemove, error)
 	AllowIPAccess(ip net.IP) (IncomingRuleRemove, error)
+	AllowTraffic(rule TrafficRule) (IncomingRuleRemove, error)
+	DenyTraffic(rule TrafficRule) (IncomingRuleRemove, error)
 }
 
 // IncomingRuleRemove type defines function for removal of created rule.
 type IncomingRuleRemove func() error
+
+// TrafficRule matches blocked traffic of the VPN network by its destination, empty fields match any traffic.
+type TrafficRule struct {
+	Source   *net.IPNet
+	Network  *net.IPNet
+	Protocol string
+	Ports    *port.Range
+}
+
+// String returns human readable rule description.
+func (r TrafficRule) String() string {
+	destination := "any"
+	if r.Network != nil {
+		destination = r.Network.String()
+	}
+	if r.Protocol != "" {
+		destination += " " + r.Protocol
+	}
+	if r.Ports != nil {
+		destination += fmt.Sprintf(" ports %d-%d", r.Ports.Start, r.Ports.End)
+	}
+	if r.Source != nil {
+		destination += " from " + r.Source.String()
+	}
+	return destination
+}
@@ -18,6 +18,7 @@
 package firewall
 
 import (
+	"fmt"
 	"net"
 	"net/url"
 	"strings"
@@ -31,6 +32,11 @@ import (
 const (
 	incomingFirewallChain = "MYST_PROVIDER_FIREWALL"
 	incomingFirewallIpset = "myst-provider-dst-whitelist"
+
+	// incomingFirewallDenyChain is checked first and rejects explicitly denied traffic
+	incomingFirewallDenyChain = "MYST_PROVIDER_FIREWALL_DENY"
+	// incomingFirewallAllowLine is the first line of allow rules, after the jump to deny chain
+	incomingFirewallAllowLine = 2
 )
 
 // incomingFirewallIptables allows incoming traffic blocking in IP granularity.
@@ -94,7 +100,7 @@ func (ibi *incomingFirewallIptables) AllowURLAccess(rawURLs ...string) (Incoming
 		}
 
 		remover, err := iptables.AddRuleWithRemoval(
-			iptables.InsertAt(incomingFirewallChain, 1).RuleSpec("-d", parsed.Hostname(), "-j", "ACCEPT"),
+			iptables.InsertAt(incomingFirewallChain, incomingFirewallAllowLine).RuleSpec("-d", parsed.Hostname(), "-j", "ACCEPT"),
 		)
 		if err != nil {
 			removeAll()
@@ -115,6 +121,44 @@ func (ibi *incomingFirewallIptables) AllowIPAccess(ip net.IP) (IncomingRuleRemov
 	}, nil
 }
 
+// AllowTraffic adds exception for traffic to the given destination.
+func (ibi *incomingFirewallIptables) AllowTraffic(rule TrafficRule) (IncomingRuleRemove, error) {
+	return ibi.addTrafficRule(iptables.InsertAt(incomingFirewallChain, incomingFirewallAllowLine), rule, "ACCEPT")
+}
+
+// DenyTraffic rejects traffic to the given destination, even if it is allowed by other rules.
+func (ibi *incomingFirewallIptables) DenyTraffic(rule TrafficRule) (IncomingRuleRemove, error) {
+	return ibi.addTrafficRule(iptables.AppendTo(incomingFirewallDenyChain), rule, "REJECT")
+}
+
+func (ibi *incomingFirewallIptables) addTrafficRule(chainRule iptables.Rule, rule TrafficRule, target string) (IncomingRuleRemove, error) {
+	var spec []string
+	if rule.Source != nil {
+		spec = append(spec, "-s", rule.Source.String())
+	}
+	if rule.Network != nil {
+		spec = append(spec, "-d", rule.Network.String())
+	}
+	if rule.Protocol != "" {
+		spec = append(spec, "-p", rule.Protocol)
+	}
+	if rule.Ports != nil {
+		if rule.Protocol == "" {
+			return nil, fmt.Errorf("protocol is required for port rule: %s", rule)
+		}
+		spec = append(spec, "--dport", fmt.Sprintf("%d:%d", rule.Ports.Start, rule.Ports.End))
+	}
+
+	remover, err := iptables.AddRuleWithRemoval(chainRule.RuleSpec(append(spec, "-j", target)...))
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		remover()
+		return nil
+	}, nil
+}
+
 func (ibi *incomingFirewallIptables) checkIpsetVersion() error {
 	output, err := ipset.Exec(ipset.OpVersion())
 	if err != nil {
@@ -127,10 +171,18 @@ func (ibi *incomingFirewallIptables) checkIpsetVersion() error {
 }
 
 func (ibi *incomingFirewallIptables) setupFirewallChain() error {
-	// Add chain
+	// Add chains
 	if _, err := iptables.Exec("-N", incomingFirewallChain); err != nil {
 		return err
 	}
+	if _, err := iptables.Exec("-N", incomingFirewallDenyChain); err != nil {
+		return err
+	}
+
+	// Append rule - denied packets are rejected before checking any exceptions
+	if _, err := iptables.Exec("-A", incomingFirewallChain, "-j", incomingFirewallDenyChain); err != nil {
+		return err
+	}
 
 	// Append rule - packets going to firewall with these destination IPs are whitelisted
 	if _, err := iptables.Exec("-A", incomingFirewallChain, "-m", "set", "--match-set", incomingFirewallIpset, "dst", "-j", "ACCEPT"); err != nil {
@@ -175,7 +227,16 @@ func (ibi *incomingFirewallIptables) cleanupStaleRules() error {
 	}
 
 	// Remove chain
-	_, err = iptables.Exec("-X", incomingFirewallChain)
+	if _, err := iptables.Exec("-X", incomingFirewallChain); err != nil {
+		return err
+	}
+
+	// Remove deny chain, it does not exist if rules were created by an older version
+	if _, err := iptables.Exec("-F", incomingFirewallDenyChain); err != nil {
+		log.Info().Err(err).Msg("[setup] Got error while flushing deny chain rules. Probably nothing to worry about")
+		return nil
+	}
+	_, err = iptables.Exec("-X", incomingFirewallDenyChain)
 	return err
 }
 
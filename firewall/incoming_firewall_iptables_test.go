@@ -21,6 +21,7 @@ import (
 	"net"
 	"testing"
 
+	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/firewall/ipset"
 	"github.com/mysteriumnetwork/node/firewall/iptables"
 	"github.com/stretchr/testify/assert"
@@ -53,6 +54,8 @@ func Test_incomingFirewallIptables_Setup(t *testing.T) {
 	assert.True(t, mockedIpset.VerifyCalledWithArgs("version"))
 	assert.True(t, mockedIpset.VerifyCalledWithArgs("create myst-provider-dst-whitelist hash:ip --timeout 86400"))
 	assert.True(t, mockedIptables.VerifyCalledWithArgs("-N MYST_PROVIDER_FIREWALL"))
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-N MYST_PROVIDER_FIREWALL_DENY"))
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-A MYST_PROVIDER_FIREWALL -j MYST_PROVIDER_FIREWALL_DENY"))
 	assert.True(t, mockedIptables.VerifyCalledWithArgs("-A MYST_PROVIDER_FIREWALL -m set --match-set myst-provider-dst-whitelist dst -j ACCEPT"))
 	assert.True(t, mockedIptables.VerifyCalledWithArgs("-A MYST_PROVIDER_FIREWALL -j REJECT"))
 }
@@ -79,6 +82,8 @@ func Test_incomingFirewallIptables_Teardown(t *testing.T) {
 	assert.True(t, mockedIpset.VerifyCalledWithArgs("destroy myst-provider-dst-whitelist"))
 	assert.True(t, mockedIptables.VerifyCalledWithArgs("-F MYST_PROVIDER_FIREWALL"))
 	assert.True(t, mockedIptables.VerifyCalledWithArgs("-X MYST_PROVIDER_FIREWALL"))
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-F MYST_PROVIDER_FIREWALL_DENY"))
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-X MYST_PROVIDER_FIREWALL_DENY"))
 }
 
 func Test_incomingFirewallIptables_TeardownIfPreviousCleanupFailed(t *testing.T) {
@@ -150,3 +155,43 @@ func Test_incomingFirewallIptables_AllowIPAccess(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, mockedIpset.VerifyCalledWithArgs("del myst-provider-dst-whitelist 1.2.3.4"))
 }
+
+func Test_incomingFirewallIptables_AllowTraffic(t *testing.T) {
+	mockedIptables := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	iptables.Exec = mockedIptables.Exec
+
+	fw := &incomingFirewallIptables{}
+
+	_, network, _ := net.ParseCIDR("93.184.216.0/24")
+	removeRule, err := fw.AllowTraffic(TrafficRule{Network: network, Protocol: "tcp", Ports: &port.Range{Start: 443, End: 443}})
+	assert.NoError(t, err)
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-I MYST_PROVIDER_FIREWALL 2 -d 93.184.216.0/24 -p tcp --dport 443:443 -j ACCEPT"))
+
+	err = removeRule()
+	assert.NoError(t, err)
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-D MYST_PROVIDER_FIREWALL -d 93.184.216.0/24 -p tcp --dport 443:443 -j ACCEPT"))
+
+	_, err = fw.AllowTraffic(TrafficRule{Ports: &port.Range{Start: 443, End: 443}})
+	assert.EqualError(t, err, "protocol is required for port rule: any ports 443-443")
+}
+
+func Test_incomingFirewallIptables_DenyTraffic(t *testing.T) {
+	mockedIptables := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	iptables.Exec = mockedIptables.Exec
+
+	fw := &incomingFirewallIptables{}
+
+	_, source, _ := net.ParseCIDR("10.8.0.0/24")
+	_, network, _ := net.ParseCIDR("10.0.0.0/8")
+	removeRule, err := fw.DenyTraffic(TrafficRule{Source: source, Network: network})
+	assert.NoError(t, err)
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-A MYST_PROVIDER_FIREWALL_DENY -s 10.8.0.0/24 -d 10.0.0.0/8 -j REJECT"))
+
+	err = removeRule()
+	assert.NoError(t, err)
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-D MYST_PROVIDER_FIREWALL_DENY -s 10.8.0.0/24 -d 10.0.0.0/8 -j REJECT"))
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/mysteriumnetwork/node/firewall/nftables"
+	"github.com/rs/zerolog/log"
+)
+
+// incomingFirewallTable holds all provider firewall rules, so they are cleaned up by deleting the table.
+var incomingFirewallTable = nftables.Table{Family: "inet", Name: "myst_provider_firewall"}
+
+const (
+	providerForwardChain  = "forward"
+	providerFirewallChain = "firewall"
+	providerAllowChain    = "allow"
+	providerDenyChain     = "deny"
+	providerWhitelist4    = "dst_whitelist4"
+	providerWhitelist6    = "dst_whitelist6"
+)
+
+// incomingFirewallTableDefinition rejects the traffic of blocked networks, unless it is allowed and not denied explicitly.
+// Whitelisted destinations expire the same way as in the ipset.
+const incomingFirewallTableDefinition = `	set dst_whitelist4 {
+		type ipv4_addr; flags timeout; timeout 24h;
+	}
+	set dst_whitelist6 {
+		type ipv6_addr; flags timeout; timeout 24h;
+	}
+	chain forward {
+		type filter hook forward priority 0; policy accept;
+	}
+	chain deny {
+	}
+	chain allow {
+	}
+	chain firewall {
+		jump deny
+		jump allow
+		ip daddr @dst_whitelist4 accept
+		ip6 daddr @dst_whitelist6 accept
+		reject
+	}`
+
+// incomingFirewallNftables allows incoming traffic blocking in IP granularity, every change is applied in a single transaction.
+type incomingFirewallNftables struct{}
+
+func (ibn *incomingFirewallNftables) Setup() error {
+	return nftables.SetupTable(incomingFirewallTable, incomingFirewallTableDefinition)
+}
+
+func (ibn *incomingFirewallNftables) Teardown() {
+	if err := nftables.DeleteTable(incomingFirewallTable); err != nil {
+		log.Warn().Err(err).Msg("Error cleaning up nftables rules, you might want to do it yourself")
+	}
+}
+
+func (ibn *incomingFirewallNftables) BlockIncomingTraffic(network net.IPNet) (IncomingRuleRemove, error) {
+	return ibn.addRules(nftables.AppendTo(incomingFirewallTable, providerForwardChain).Expr(
+		nftables.AddressFamily(network.String()), "saddr", network.String(), "jump", providerFirewallChain,
+	))
+}
+
+// AllowURLAccess adds URL based exception.
+func (ibn *incomingFirewallNftables) AllowURLAccess(rawURLs ...string) (IncomingRuleRemove, error) {
+	var rules []nftables.Rule
+	for _, rawURL := range rawURLs {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		host := parsed.Hostname()
+		// Host names are resolved by nft and may have several addresses, hence the anonymous set.
+		rules = append(rules, nftables.AppendTo(incomingFirewallTable, providerAllowChain).Expr(
+			nftables.AddressFamily(host), "daddr", "{", host, "}", "accept",
+		))
+	}
+	if len(rules) == 0 {
+		return func() error { return nil }, nil
+	}
+	return ibn.addRules(rules...)
+}
+
+func (ibn *incomingFirewallNftables) AllowIPAccess(ip net.IP) (IncomingRuleRemove, error) {
+	set := providerWhitelist4
+	if ip.To4() == nil {
+		set = providerWhitelist6
+	}
+	if err := nftables.AddElements(incomingFirewallTable, set, ip.String()); err != nil {
+		return nil, err
+	}
+	return func() error {
+		return nftables.DeleteElements(incomingFirewallTable, set, ip.String())
+	}, nil
+}
+
+// AllowTraffic adds exception for traffic to the given destination.
+func (ibn *incomingFirewallNftables) AllowTraffic(rule TrafficRule) (IncomingRuleRemove, error) {
+	return ibn.addTrafficRule(providerAllowChain, rule, "accept")
+}
+
+// DenyTraffic rejects traffic to the given destination, even if it is allowed by other rules.
+func (ibn *incomingFirewallNftables) DenyTraffic(rule TrafficRule) (IncomingRuleRemove, error) {
+	return ibn.addTrafficRule(providerDenyChain, rule, "reject")
+}
+
+func (ibn *incomingFirewallNftables) addTrafficRule(chain string, rule TrafficRule, verdict string) (IncomingRuleRemove, error) {
+	var expr []string
+	if rule.Source != nil {
+		expr = append(expr, nftables.AddressFamily(rule.Source.String()), "saddr", rule.Source.String())
+	}
+	if rule.Network != nil {
+		expr = append(expr, nftables.AddressFamily(rule.Network.String()), "daddr", rule.Network.String())
+	}
+	if rule.Protocol != "" {
+		expr = append(expr, "meta", "l4proto", rule.Protocol)
+	}
+	if rule.Ports != nil {
+		if rule.Protocol == "" {
+			return nil, fmt.Errorf("protocol is required for port rule: %s", rule)
+		}
+		expr = append(expr, rule.Protocol, "dport", fmt.Sprintf("%d-%d", rule.Ports.Start, rule.Ports.End))
+	}
+
+	return ibn.addRules(nftables.AppendTo(incomingFirewallTable, chain).Expr(append(expr, verdict)...))
+}
+
+func (ibn *incomingFirewallNftables) addRules(rules ...nftables.Rule) (IncomingRuleRemove, error) {
+	added, err := nftables.AddRules(rules...)
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		return nftables.DeleteRules(added...)
+	}, nil
+}
+
+var _ IncomingTrafficFirewall = &incomingFirewallNftables{}
@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/firewall/nftables"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_incomingFirewallNftables_BlockIncomingTraffic(t *testing.T) {
+	mockedApply := &nftablesApplyMock{}
+	nftables.Apply = mockedApply.Apply
+
+	fw := &incomingFirewallNftables{}
+	remove, err := fw.BlockIncomingTraffic(net.IPNet{IP: net.ParseIP("10.182.0.0").To4(), Mask: net.CIDRMask(24, 32)})
+	assert.NoError(t, err)
+	assert.True(t, mockedApply.VerifyApplied("add rule inet myst_provider_firewall forward ip saddr 10.182.0.0/24 jump firewall\n"))
+
+	assert.NoError(t, remove())
+	assert.True(t, mockedApply.VerifyApplied("delete rule inet myst_provider_firewall forward handle 1\n"))
+}
+
+func Test_incomingFirewallNftables_AllowIPAccess(t *testing.T) {
+	mockedApply := &nftablesApplyMock{}
+	nftables.Apply = mockedApply.Apply
+
+	fw := &incomingFirewallNftables{}
+	remove, err := fw.AllowIPAccess(net.ParseIP("2001:db8::1"))
+	assert.NoError(t, err)
+	assert.True(t, mockedApply.VerifyApplied("add element inet myst_provider_firewall dst_whitelist6 { 2001:db8::1 }\n"))
+
+	assert.NoError(t, remove())
+	assert.True(t, mockedApply.VerifyApplied("delete element inet myst_provider_firewall dst_whitelist6 { 2001:db8::1 }\n"))
+}
+
+func Test_incomingFirewallNftables_TrafficRules(t *testing.T) {
+	mockedApply := &nftablesApplyMock{}
+	nftables.Apply = mockedApply.Apply
+
+	_, network, _ := net.ParseCIDR("192.168.1.0/24")
+	_, source, _ := net.ParseCIDR("10.182.0.2/32")
+	fw := &incomingFirewallNftables{}
+
+	_, err := fw.AllowTraffic(TrafficRule{Network: network, Protocol: "tcp", Ports: &port.Range{Start: 80, End: 443}})
+	assert.NoError(t, err)
+	assert.True(t, mockedApply.VerifyApplied("add rule inet myst_provider_firewall allow ip daddr 192.168.1.0/24 meta l4proto tcp tcp dport 80-443 accept\n"))
+
+	_, err = fw.DenyTraffic(TrafficRule{Source: source, Network: network})
+	assert.NoError(t, err)
+	assert.True(t, mockedApply.VerifyApplied("add rule inet myst_provider_firewall deny ip saddr 10.182.0.2/32 ip daddr 192.168.1.0/24 reject\n"))
+
+	_, err = fw.AllowTraffic(TrafficRule{Ports: &port.Range{Start: 80, End: 80}})
+	assert.Error(t, err)
+}
+
+func Test_incomingFirewallNftables_SetupFails(t *testing.T) {
+	mockedApply := &nftablesApplyMock{err: errors.New("Error: Could not process rule: Operation not supported")}
+	nftables.Apply = mockedApply.Apply
+
+	fw := &incomingFirewallNftables{}
+	assert.Error(t, fw.Setup())
+}
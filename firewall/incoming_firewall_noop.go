@@ -69,4 +69,22 @@ func (ifn *incomingFirewallNoop) AllowIPAccess(ip net.IP) (IncomingRuleRemove, e
 	}, nil
 }
 
+// AllowTraffic logs traffic for which access was requested.
+func (ifn *incomingFirewallNoop) AllowTraffic(rule TrafficRule) (IncomingRuleRemove, error) {
+	log.Info().Msgf("Allow traffic to %s", rule)
+	return func() error {
+		log.Info().Msgf("Rule allowing traffic to %s removed", rule)
+		return nil
+	}, nil
+}
+
+// DenyTraffic logs traffic for which access was denied.
+func (ifn *incomingFirewallNoop) DenyTraffic(rule TrafficRule) (IncomingRuleRemove, error) {
+	log.Info().Msgf("Deny traffic to %s", rule)
+	return func() error {
+		log.Info().Msgf("Rule denying traffic to %s removed", rule)
+		return nil
+	}, nil
+}
+
 var _ IncomingTrafficFirewall = &incomingFirewallNoop{}
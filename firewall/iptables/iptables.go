@@ -29,11 +29,22 @@ import (
 // Exec executes given args
 var Exec = defaultExec
 
+// Exec6 executes given args with ip6tables
+var Exec6 = defaultExec6
+
 func defaultExec(args ...string) ([]string, error) {
-	args = append([]string{"sudo", "/usr/sbin/iptables"}, args...)
+	return execute("/usr/sbin/iptables", args...)
+}
+
+func defaultExec6(args ...string) ([]string, error) {
+	return execute("/usr/sbin/ip6tables", args...)
+}
+
+func execute(binary string, args ...string) ([]string, error) {
+	args = append([]string{"sudo", binary}, args...)
 	output, err := cmdutil.ExecOutput(args...)
 	if err != nil {
-		return nil, errors.Wrap(err, "iptables cmd error")
+		return nil, errors.Wrapf(err, "%s cmd error", binary)
 	}
 
 	outputScanner := bufio.NewScanner(bytes.NewBufferString(output))
@@ -46,11 +57,15 @@ func defaultExec(args ...string) ([]string, error) {
 
 // AddRuleWithRemoval activates given rule
 func AddRuleWithRemoval(rule Rule) (func(), error) {
-	if _, err := Exec(rule.ApplyArgs()...); err != nil {
+	exec := Exec
+	if rule.IsIPv6() {
+		exec = Exec6
+	}
+	if _, err := exec(rule.ApplyArgs()...); err != nil {
 		return nil, err
 	}
 	return func() {
-		_, err := Exec(rule.RemoveArgs()...)
+		_, err := exec(rule.RemoveArgs()...)
 		if err != nil {
 			log.Warn().Err(err).Msgf("Error executing rule: %v you might wanna do it yourself", rule.RemoveArgs())
 		}
@@ -0,0 +1,277 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nftables
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/firewall/iptables"
+	"github.com/mysteriumnetwork/node/utils/cmdutil"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Exec executes given args
+var Exec = defaultExec
+
+// Apply executes given script as a single transaction, so either all of its commands take effect or none of them.
+// Added rules are echoed back together with their handles.
+var Apply = defaultApply
+
+func defaultExec(args ...string) ([]string, error) {
+	args = append([]string{"sudo", "/usr/sbin/nft"}, args...)
+	output, err := cmdutil.ExecOutput(args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "nft cmd error")
+	}
+	return splitLines(output)
+}
+
+func defaultApply(script string) ([]string, error) {
+	output, err := cmdutil.ExecOutputWithInput(script, "sudo", "/usr/sbin/nft", "--echo", "--handle", "--file", "-")
+	if err != nil {
+		return nil, errors.Wrap(err, "nft transaction error")
+	}
+	return splitLines(output)
+}
+
+func splitLines(output string) ([]string, error) {
+	outputScanner := bufio.NewScanner(bytes.NewBufferString(output))
+	var lines []string
+	for outputScanner.Scan() {
+		lines = append(lines, outputScanner.Text())
+	}
+	return lines, outputScanner.Err()
+}
+
+// Firewall backends, which can be chosen by SetBackend.
+const (
+	// BackendAuto picks nftables, unless iptables FORWARD rules are present.
+	BackendAuto = "auto"
+	// BackendNftables picks nftables whenever the system supports them.
+	BackendNftables = "nftables"
+	// BackendIptables always picks iptables.
+	BackendIptables = "iptables"
+)
+
+var (
+	availableOnce sync.Once
+	available     bool
+
+	backend       = BackendAuto
+	preferredOnce sync.Once
+	preferred     bool
+)
+
+// SetBackend chooses the firewall backend, it has to be called before the first Preferred call.
+func SetBackend(name string) error {
+	switch name {
+	case BackendAuto, BackendNftables, BackendIptables:
+		backend = name
+		return nil
+	default:
+		return fmt.Errorf("unknown firewall backend %q, expected one of: %s, %s, %s", name, BackendAuto, BackendNftables, BackendIptables)
+	}
+}
+
+// Preferred checks if nftables should be used instead of iptables, the check is made once.
+//
+// Accept verdicts of nftables tables can't override drops of iptables chains, e.g. FORWARD policy DROP
+// set by Docker, so the auto backend keeps iptables whenever the FORWARD chain of iptables
+// (either legacy or iptables-nft) has rules or a restrictive policy.
+func Preferred() bool {
+	preferredOnce.Do(func() {
+		preferred = checkPreferred(backend)
+	})
+	return preferred
+}
+
+func checkPreferred(backend string) bool {
+	switch backend {
+	case BackendIptables:
+		return false
+	case BackendNftables:
+		return Available()
+	}
+
+	if !Available() {
+		return false
+	}
+	if rules := iptablesForwardRules(); len(rules) > 0 {
+		log.Info().Msgf("iptables FORWARD chain is in use, using iptables instead of nftables: %v", rules)
+		return false
+	}
+	return true
+}
+
+// iptablesForwardRules returns rules and a restrictive policy of iptables FORWARD chain.
+func iptablesForwardRules() (rules []string) {
+	output, err := iptables.Exec("-S", "FORWARD")
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to list iptables FORWARD chain")
+		return nil
+	}
+	for _, line := range output {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "-A FORWARD") || (strings.HasPrefix(line, "-P FORWARD") && line != "-P FORWARD ACCEPT") {
+			rules = append(rules, line)
+		}
+	}
+	return rules
+}
+
+// Available checks if nftables can be used in the system, the check is made once.
+func Available() bool {
+	availableOnce.Do(func() {
+		available = checkAvailable()
+	})
+	return available
+}
+
+func checkAvailable() bool {
+	output, err := Exec("--version")
+	if err != nil {
+		log.Debug().Err(err).Msg("nftables are not available")
+		return false
+	}
+	for _, line := range output {
+		log.Info().Msg("[version check] " + line)
+	}
+	// Listing fails if the kernel has no nf_tables support, even though the binary is there.
+	if _, err := Exec("list", "tables"); err != nil {
+		log.Debug().Err(err).Msg("nftables are not supported by the kernel")
+		return false
+	}
+	return true
+}
+
+// Table is a table of nftables rules, owned by a single component.
+type Table struct {
+	Family string
+	Name   string
+}
+
+// String returns the table reference used in the nft commands.
+func (t Table) String() string {
+	return t.Family + " " + t.Name
+}
+
+// SetupTable atomically replaces the table with the given definition, dropping rules left from previous runs.
+func SetupTable(table Table, definition string) error {
+	script := fmt.Sprintf("table %[1]s\ndelete table %[1]s\ntable %[1]s {\n%[2]s\n}\n", table, definition)
+	_, err := Apply(script)
+	return err
+}
+
+// DeleteTable deletes the table with all of its rules, if it exists.
+func DeleteTable(table Table) error {
+	_, err := Apply(fmt.Sprintf("table %[1]s\ndelete table %[1]s\n", table))
+	return err
+}
+
+// AddElements adds elements to the set of the table, existing elements are left intact.
+func AddElements(table Table, set string, elements ...string) error {
+	_, err := Apply(fmt.Sprintf("add element %s %s { %s }\n", table, set, strings.Join(elements, ", ")))
+	return err
+}
+
+// DeleteElements removes elements from the set of the table.
+func DeleteElements(table Table, set string, elements ...string) error {
+	_, err := Apply(fmt.Sprintf("delete element %s %s { %s }\n", table, set, strings.Join(elements, ", ")))
+	return err
+}
+
+// AddRules atomically adds given rules and returns them together with handles assigned by the kernel.
+func AddRules(rules ...Rule) ([]Rule, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	var script strings.Builder
+	for _, rule := range rules {
+		script.WriteString(rule.addCommand() + "\n")
+	}
+	output, err := Apply(script.String())
+	if err != nil {
+		return nil, err
+	}
+
+	handles := parseHandles(output)
+	if len(handles) != len(rules) {
+		return nil, fmt.Errorf("expected %d rule handles, got %d", len(rules), len(handles))
+	}
+	added := make([]Rule, len(rules))
+	for i := range rules {
+		added[i] = rules[i]
+		added[i].handle = handles[i]
+	}
+	return added, nil
+}
+
+// DeleteRules atomically deletes given rules, which were added before.
+func DeleteRules(rules ...Rule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	var script strings.Builder
+	for _, rule := range rules {
+		script.WriteString(rule.deleteCommand() + "\n")
+	}
+	_, err := Apply(script.String())
+	return err
+}
+
+// AddRulesWithRemoval atomically adds given rules and returns a function removing them.
+func AddRulesWithRemoval(rules ...Rule) (func(), error) {
+	added, err := AddRules(rules...)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		if err := DeleteRules(added...); err != nil {
+			log.Warn().Err(err).Msgf("Error deleting rules %v you might wanna do it yourself", added)
+		}
+	}, nil
+}
+
+// parseHandles returns handles of the rules echoed by nft, e.g. "add rule inet t c accept # handle 4".
+func parseHandles(output []string) (handles []int) {
+	const marker = "# handle "
+	for _, line := range output {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "add rule") && !strings.HasPrefix(line, "insert rule") {
+			continue
+		}
+		i := strings.LastIndex(line, marker)
+		if i < 0 {
+			continue
+		}
+		handle, err := strconv.Atoi(line[i+len(marker):])
+		if err != nil {
+			continue
+		}
+		handles = append(handles, handle)
+	}
+	return handles
+}
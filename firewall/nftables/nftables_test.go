@@ -0,0 +1,135 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nftables
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/firewall/iptables"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTable = Table{Family: "inet", Name: "myst_test"}
+
+var defaultIptablesExec = iptables.Exec
+
+func TestAddRules(t *testing.T) {
+	var script string
+	Apply = func(s string) ([]string, error) {
+		script = s
+		return []string{
+			"add rule inet myst_test forward ip saddr 10.182.0.0/24 accept # handle 4",
+			"insert rule inet myst_test forward ip daddr 10.182.0.0/24 accept # handle 5",
+		}, nil
+	}
+	defer func() { Apply = defaultApply }()
+
+	rules, err := AddRules(
+		AppendTo(testTable, "forward").Expr("ip", "saddr", "10.182.0.0/24", "accept"),
+		InsertTo(testTable, "forward").Expr("ip", "daddr", "10.182.0.0/24", "accept"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "add rule inet myst_test forward ip saddr 10.182.0.0/24 accept\n"+
+		"insert rule inet myst_test forward ip daddr 10.182.0.0/24 accept\n", script)
+	require.Len(t, rules, 2)
+	assert.Equal(t, 4, rules[0].Handle())
+	assert.Equal(t, 5, rules[1].Handle())
+
+	err = DeleteRules(rules...)
+	assert.NoError(t, err)
+	assert.Equal(t, "delete rule inet myst_test forward handle 4\n"+
+		"delete rule inet myst_test forward handle 5\n", script)
+}
+
+func TestAddRules_MissingHandles(t *testing.T) {
+	Apply = func(s string) ([]string, error) {
+		return nil, nil
+	}
+	defer func() { Apply = defaultApply }()
+
+	_, err := AddRules(AppendTo(testTable, "forward").Expr("accept"))
+	assert.Error(t, err)
+}
+
+func TestAddRulesWithRemoval_Fails(t *testing.T) {
+	Apply = func(s string) ([]string, error) {
+		return nil, errors.New("Error: Could not process rule: No such file or directory")
+	}
+	defer func() { Apply = defaultApply }()
+
+	_, err := AddRulesWithRemoval(AppendTo(testTable, "forward").Expr("accept"))
+	assert.Error(t, err)
+}
+
+func TestSetupTable(t *testing.T) {
+	var script string
+	Apply = func(s string) ([]string, error) {
+		script = s
+		return nil, nil
+	}
+	defer func() { Apply = defaultApply }()
+
+	err := SetupTable(testTable, "\tchain forward {}")
+	assert.NoError(t, err)
+	assert.Equal(t, "table inet myst_test\ndelete table inet myst_test\ntable inet myst_test {\n\tchain forward {}\n}\n", script)
+}
+
+func TestAddressFamily(t *testing.T) {
+	assert.Equal(t, "ip", AddressFamily("10.182.0.1"))
+	assert.Equal(t, "ip", AddressFamily("10.182.0.0/24"))
+	assert.Equal(t, "ip6", AddressFamily("fd10:182::/64"))
+}
+
+func TestCheckPreferred(t *testing.T) {
+	Exec = func(args ...string) ([]string, error) {
+		return nil, nil
+	}
+	defer func() { Exec = defaultExec }()
+	availableOnce.Do(func() {})
+	available = true
+	defer func() { availableOnce, available = sync.Once{}, false }()
+
+	var forward []string
+	iptables.Exec = func(args ...string) ([]string, error) {
+		return forward, nil
+	}
+	defer func() { iptables.Exec = defaultIptablesExec }()
+
+	forward = []string{"-P FORWARD ACCEPT"}
+	assert.True(t, checkPreferred(BackendAuto))
+	assert.False(t, checkPreferred(BackendIptables))
+
+	forward = []string{"-P FORWARD DROP", "-A FORWARD -j DOCKER-USER"}
+	assert.False(t, checkPreferred(BackendAuto))
+	assert.True(t, checkPreferred(BackendNftables))
+
+	forward = []string{"-P FORWARD ACCEPT", "-A FORWARD -i wg0 -j ACCEPT"}
+	assert.False(t, checkPreferred(BackendAuto))
+}
+
+func TestSetBackend(t *testing.T) {
+	defer func() { backend = BackendAuto }()
+
+	assert.NoError(t, SetBackend(BackendIptables))
+	assert.Equal(t, BackendIptables, backend)
+	assert.Error(t, SetBackend("pf"))
+	assert.Equal(t, BackendIptables, backend)
+}
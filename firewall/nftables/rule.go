@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nftables
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rule is a packet filter rule for nftables.
+type Rule struct {
+	table  Table
+	chain  string
+	action string
+	expr   []string
+	handle int
+}
+
+// AppendTo creates a new rule to be appended to the specified chain.
+func AppendTo(table Table, chain string) Rule {
+	return Rule{table: table, chain: chain, action: "add"}
+}
+
+// InsertTo creates a new rule to be inserted at the beginning of the specified chain.
+func InsertTo(table Table, chain string) Rule {
+	return Rule{table: table, chain: chain, action: "insert"}
+}
+
+// Expr sets the rule statements (see `man nft`).
+func (r Rule) Expr(expr ...string) Rule {
+	r.expr = expr
+	return r
+}
+
+// Handle returns the handle of the rule, which is known once the rule is added.
+func (r Rule) Handle() int {
+	return r.handle
+}
+
+// String returns the rule statements.
+func (r Rule) String() string {
+	return strings.Join(r.expr, " ")
+}
+
+func (r Rule) addCommand() string {
+	return fmt.Sprintf("%s rule %s %s %s", r.action, r.table, r.chain, r)
+}
+
+func (r Rule) deleteCommand() string {
+	return fmt.Sprintf("delete rule %s %s handle %d", r.table, r.chain, r.handle)
+}
+
+// AddressFamily returns the nft protocol expression matching the address family of the given IP, i.e. "ip" or "ip6".
+func AddressFamily(ip string) string {
+	if strings.Contains(ip, ":") {
+		return "ip6"
+	}
+	return "ip"
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+import (
+	"fmt"
+	"strings"
+)
+
+// nftablesApplyMock records applied scripts and echoes added rules with sequential handles.
+type nftablesApplyMock struct {
+	scripts []string
+	handle  int
+	err     error
+}
+
+func (nam *nftablesApplyMock) Apply(script string) ([]string, error) {
+	nam.scripts = append(nam.scripts, script)
+	if nam.err != nil {
+		return nil, nam.err
+	}
+
+	var output []string
+	for _, line := range strings.Split(script, "\n") {
+		if strings.HasPrefix(line, "add rule") || strings.HasPrefix(line, "insert rule") {
+			nam.handle++
+			output = append(output, fmt.Sprintf("%s # handle %d", line, nam.handle))
+		}
+	}
+	return output, nil
+}
+
+func (nam *nftablesApplyMock) VerifyApplied(script string) bool {
+	for _, applied := range nam.scripts {
+		if applied == script {
+			return true
+		}
+	}
+	return false
+}
@@ -17,6 +17,8 @@
 
 package firewall
 
+import "strings"
+
 const (
 	// Global scope overrides session scope and is not affected by session scope calls.
 	Global Scope = "global"
@@ -35,7 +37,8 @@ var DefaultOutgoingFirewall OutgoingTrafficFirewall = &outgoingFirewallNoop{}
 type OutgoingTrafficFirewall interface {
 	Setup() error
 	Teardown()
-	BlockOutgoingTraffic(scope Scope, outboundIP string) (OutgoingRuleRemove, error)
+	BlockOutgoingTraffic(scope Scope, outboundIPs ...string) (OutgoingRuleRemove, error)
+	BlockOutgoingTrafficTo(scope Scope, networks []string, outboundIPs ...string) (OutgoingRuleRemove, error)
 	AllowIPAccess(ip string) (OutgoingRuleRemove, error)
 	AllowURLAccess(rawURLs ...string) (OutgoingRuleRemove, error)
 }
@@ -47,8 +50,15 @@ type Scope string
 type OutgoingRuleRemove func()
 
 // BlockNonTunnelTraffic effectively disallows any outgoing traffic from consumer node with specified scope.
-func BlockNonTunnelTraffic(scope Scope, outboundIP string) (OutgoingRuleRemove, error) {
-	return DefaultOutgoingFirewall.BlockOutgoingTraffic(scope, outboundIP)
+// Traffic is blocked from every given outbound IP, both IPv4 and IPv6 ones.
+func BlockNonTunnelTraffic(scope Scope, outboundIPs ...string) (OutgoingRuleRemove, error) {
+	return DefaultOutgoingFirewall.BlockOutgoingTraffic(scope, outboundIPs...)
+}
+
+// BlockNonTunnelTrafficTo disallows outgoing traffic to the given networks unless it goes through the tunnel,
+// traffic to other destinations is not affected. It protects the split tunnel limited to the include routes.
+func BlockNonTunnelTrafficTo(scope Scope, networks []string, outboundIPs ...string) (OutgoingRuleRemove, error) {
+	return DefaultOutgoingFirewall.BlockOutgoingTrafficTo(scope, networks, outboundIPs...)
 }
 
 // AllowURLAccess adds exception to blocked traffic for specified URL (host part is usually taken).
@@ -61,6 +71,18 @@ func AllowIPAccess(ip string) (OutgoingRuleRemove, error) {
 	return DefaultOutgoingFirewall.AllowIPAccess(ip)
 }
 
+// blockTrafficKey is the reference key of the traffic block, blocks of different networks are tracked separately.
+func blockTrafficKey(networks []string) string {
+	if len(networks) == 0 {
+		return "block-traffic"
+	}
+	return "block-traffic:" + strings.Join(networks, ",")
+}
+
+func isIPv6Network(network string) bool {
+	return strings.Contains(network, ":")
+}
+
 // Reset firewall state - usually called when cleanup is needed (during shutdown).
 func Reset() {
 	DefaultOutgoingFirewall.Teardown()
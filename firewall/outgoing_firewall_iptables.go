@@ -18,6 +18,7 @@
 package firewall
 
 import (
+	"net"
 	"net/url"
 	"strings"
 	"sync"
@@ -28,56 +29,133 @@ import (
 
 const killswitchChain = "MYST_CONSUMER_KILL_SWITCH"
 
-type refCount struct {
-	count int
-	f     func()
-}
+// ipv6NetworkMask is the mask of the network the outbound IPv6 address belongs to.
+var ipv6NetworkMask = net.CIDRMask(64, 128)
+
+type iptablesExec func(args ...string) ([]string, error)
 
 type outgoingFirewallIptables struct {
 	lock             sync.Mutex
 	trafficLockScope Scope
 	referenceTracker map[string]refCount
+	// ipv6 is set when ip6tables kill switch chain was set up.
+	ipv6 bool
 }
 
 // Setup tries to setup all changes made by setup and leave system in the state before setup.
 func (obi *outgoingFirewallIptables) Setup() error {
-	if err := obi.checkIptablesVersion(); err != nil {
+	if err := obi.setup(iptables.Exec, false); err != nil {
+		return err
+	}
+
+	if err := obi.setup(iptables.Exec6, true); err != nil {
+		log.Warn().Err(err).Msg("Failed to setup ip6tables kill switch, IPv6 traffic will not be blocked")
+		return nil
+	}
+	obi.ipv6 = true
+	return nil
+}
+
+func (obi *outgoingFirewallIptables) setup(exec iptablesExec, ipv6 bool) error {
+	if err := obi.checkIptablesVersion(exec); err != nil {
 		return err
 	}
-	if err := obi.cleanupStaleRules(); err != nil {
+	if err := obi.cleanupStaleRules(exec); err != nil {
 		return err
 	}
-	return obi.setupKillSwitchChain()
+	return obi.setupKillSwitchChain(exec, ipv6)
 }
 
 // Teardown tries to cleanup all changes made by setup and leave system in the state before setup.
 func (obi *outgoingFirewallIptables) Teardown() {
-	if err := obi.cleanupStaleRules(); err != nil {
+	if err := obi.cleanupStaleRules(iptables.Exec); err != nil {
 		log.Warn().Err(err).Msg("Error cleaning up iptables rules, you might want to do it yourself")
 	}
+	if !obi.ipv6 {
+		return
+	}
+	if err := obi.cleanupStaleRules(iptables.Exec6); err != nil {
+		log.Warn().Err(err).Msg("Error cleaning up ip6tables rules, you might want to do it yourself")
+	}
 }
 
 // BlockOutgoingTraffic effectively disallows any outgoing traffic from consumer node with specified scope.
-func (obi *outgoingFirewallIptables) BlockOutgoingTraffic(scope Scope, outboundIP string) (OutgoingRuleRemove, error) {
+// IPv6 traffic is blocked for the whole /64 network of the outbound IPv6 address, which covers temporary addresses too.
+func (obi *outgoingFirewallIptables) BlockOutgoingTraffic(scope Scope, outboundIPs ...string) (OutgoingRuleRemove, error) {
+	return obi.BlockOutgoingTrafficTo(scope, nil, outboundIPs...)
+}
+
+// BlockOutgoingTrafficTo disallows outgoing traffic to the given networks with specified scope, all traffic is blocked when none are given.
+func (obi *outgoingFirewallIptables) BlockOutgoingTrafficTo(scope Scope, networks []string, outboundIPs ...string) (OutgoingRuleRemove, error) {
 	if obi.trafficLockScope == Global {
 		// nothing can override global lock
 		return func() {}, nil
 	}
 	obi.trafficLockScope = scope
-	return obi.trackingReferenceCall("block-traffic", func() (OutgoingRuleRemove, error) {
-		// Take custom chain into effect for packets in OUTPUT
-		return iptables.AddRuleWithRemoval(
-			iptables.AppendTo("OUTPUT").RuleSpec("-s", outboundIP, "-j", killswitchChain),
-		)
+	return trackingReferenceCall(&obi.lock, obi.referenceTracker, blockTrafficKey(networks), func() (OutgoingRuleRemove, error) {
+		var ruleRemovers []func()
+		removeAll := func() {
+			for _, ruleRemover := range ruleRemovers {
+				ruleRemover()
+			}
+		}
+		for _, outboundIP := range outboundIPs {
+			for _, rule := range obi.blockRules(outboundIP, networks) {
+				// Take custom chain into effect for packets in OUTPUT
+				remover, err := iptables.AddRuleWithRemoval(rule)
+				if err != nil {
+					removeAll()
+					return nil, err
+				}
+				ruleRemovers = append(ruleRemovers, remover)
+			}
+		}
+		return removeAll, nil
 	})
 }
 
+func (obi *outgoingFirewallIptables) blockRules(outboundIP string, networks []string) []iptables.Rule {
+	ip := net.ParseIP(outboundIP)
+	source := outboundIP
+	ipv6 := ip != nil && ip.To4() == nil
+	if ipv6 {
+		if !obi.ipv6 {
+			log.Warn().Msgf("ip6tables kill switch is not available, IPv6 traffic from %s will not be blocked", outboundIP)
+			return nil
+		}
+		source = (&net.IPNet{IP: ip.Mask(ipv6NetworkMask), Mask: ipv6NetworkMask}).String()
+	}
+
+	newRule := func(spec ...string) iptables.Rule {
+		rule := iptables.AppendTo("OUTPUT").RuleSpec(append([]string{"-s", source}, spec...)...)
+		if ipv6 {
+			rule = rule.IPv6()
+		}
+		return rule
+	}
+	if len(networks) == 0 {
+		return []iptables.Rule{newRule("-j", killswitchChain)}
+	}
+	var rules []iptables.Rule
+	for _, network := range networks {
+		if isIPv6Network(network) == ipv6 {
+			rules = append(rules, newRule("-d", network, "-j", killswitchChain))
+		}
+	}
+	return rules
+}
+
 // AllowIPAccess adds exception to blocked traffic for specified URL (host part is usually taken).
 func (obi *outgoingFirewallIptables) AllowIPAccess(ip string) (OutgoingRuleRemove, error) {
-	return obi.trackingReferenceCall("allow:"+ip, func() (rule OutgoingRuleRemove, e error) {
-		return iptables.AddRuleWithRemoval(
-			iptables.InsertAt(killswitchChain, 1).RuleSpec("-d", ip, "-j", "ACCEPT"),
-		)
+	return trackingReferenceCall(&obi.lock, obi.referenceTracker, "allow:"+ip, func() (OutgoingRuleRemove, error) {
+		rule := iptables.InsertAt(killswitchChain, 1).RuleSpec("-d", ip, "-j", "ACCEPT")
+		if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+			if !obi.ipv6 {
+				return func() {}, nil
+			}
+			rule = rule.IPv6()
+		}
+		return iptables.AddRuleWithRemoval(rule)
 	})
 }
 
@@ -106,8 +184,8 @@ func (obi *outgoingFirewallIptables) AllowURLAccess(rawURLs ...string) (Outgoing
 	return removeAll, nil
 }
 
-func (obi *outgoingFirewallIptables) checkIptablesVersion() error {
-	output, err := iptables.Exec("--version")
+func (obi *outgoingFirewallIptables) checkIptablesVersion(exec iptablesExec) error {
+	output, err := exec("--version")
 	if err != nil {
 		return err
 	}
@@ -117,31 +195,37 @@ func (obi *outgoingFirewallIptables) checkIptablesVersion() error {
 	return nil
 }
 
-func (obi *outgoingFirewallIptables) setupKillSwitchChain() error {
+func (obi *outgoingFirewallIptables) setupKillSwitchChain(exec iptablesExec, ipv6 bool) error {
 	// Add chain
-	if _, err := iptables.Exec("-N", killswitchChain); err != nil {
+	if _, err := exec("-N", killswitchChain); err != nil {
 		return err
 	}
 	// Append rule - by default all packets going to kill switch chain are rejected
-	if _, err := iptables.Exec("-A", killswitchChain, "-m", "conntrack", "--ctstate", "NEW", "-j", "REJECT"); err != nil {
+	if _, err := exec("-A", killswitchChain, "-m", "conntrack", "--ctstate", "NEW", "-j", "REJECT"); err != nil {
 		return err
 	}
 
 	// Insert rule - TODO for now always allow outgoing DNS traffic, BUT it should be exposed as separate firewall call
-	if _, err := iptables.Exec("-I", killswitchChain, "1", "-p", "udp", "--dport", "53", "-j", "ACCEPT"); err != nil {
+	if _, err := exec("-I", killswitchChain, "1", "-p", "udp", "--dport", "53", "-j", "ACCEPT"); err != nil {
 		return err
 	}
 	// Insert rule - TCP DNS is not so popular - but for the sake of humanity, lets allow it too
-	if _, err := iptables.Exec("-I", killswitchChain, "1", "-p", "tcp", "--dport", "53", "-j", "ACCEPT"); err != nil {
+	if _, err := exec("-I", killswitchChain, "1", "-p", "tcp", "--dport", "53", "-j", "ACCEPT"); err != nil {
 		return err
 	}
+	// Insert rule - IPv6 neighbor discovery and path MTU discovery are needed for the tunnel to work
+	if ipv6 {
+		if _, err := exec("-I", killswitchChain, "1", "-p", "ipv6-icmp", "-j", "ACCEPT"); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
-func (obi *outgoingFirewallIptables) cleanupStaleRules() error {
+func (obi *outgoingFirewallIptables) cleanupStaleRules(exec iptablesExec) error {
 	// List rules
-	rules, err := iptables.Exec("-S", "OUTPUT")
+	rules, err := exec("-S", "OUTPUT")
 	if err != nil {
 		return err
 	}
@@ -150,61 +234,27 @@ func (obi *outgoingFirewallIptables) cleanupStaleRules() error {
 		if strings.HasSuffix(rule, killswitchChain) {
 			deleteRule := strings.Replace(rule, "-A", "-D", 1)
 			deleteRuleArgs := strings.Split(deleteRule, " ")
-			if _, err := iptables.Exec(deleteRuleArgs...); err != nil {
+			if _, err := exec(deleteRuleArgs...); err != nil {
 				return err
 			}
 		}
 	}
 
 	// List chain rules
-	if _, err := iptables.Exec("-L", killswitchChain); err != nil {
+	if _, err := exec("-L", killswitchChain); err != nil {
 		// error means no such chain - log error just in case and bail out
 		log.Info().Err(err).Msg("[setup] Got error while listing kill switch chain rules. Probably nothing to worry about")
 		return nil
 	}
 
 	// Remove chain rules
-	if _, err := iptables.Exec("-F", killswitchChain); err != nil {
+	if _, err := exec("-F", killswitchChain); err != nil {
 		return err
 	}
 
 	// Remove chain
-	_, err = iptables.Exec("-X", killswitchChain)
+	_, err = exec("-X", killswitchChain)
 	return err
 }
 
-func (obi *outgoingFirewallIptables) trackingReferenceCall(ref string, actualCall func() (OutgoingRuleRemove, error)) (OutgoingRuleRemove, error) {
-	obi.lock.Lock()
-	defer obi.lock.Unlock()
-
-	refCount := obi.referenceTracker[ref]
-	if refCount.count == 0 {
-		removeRule, err := actualCall()
-		if err != nil {
-			return nil, err
-		}
-		refCount.f = removeRule
-
-		refCount.count++
-		obi.referenceTracker[ref] = refCount
-	}
-
-	return obi.decreaseRefCall(ref), nil
-}
-
-func (obi *outgoingFirewallIptables) decreaseRefCall(ref string) OutgoingRuleRemove {
-	return func() {
-		obi.lock.Lock()
-		defer obi.lock.Unlock()
-
-		refCount := obi.referenceTracker[ref]
-		if refCount.count == 1 {
-			refCount.f()
-
-			refCount.count--
-			obi.referenceTracker[ref] = refCount
-		}
-	}
-}
-
 var _ OutgoingTrafficFirewall = &outgoingFirewallIptables{}
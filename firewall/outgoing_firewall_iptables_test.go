@@ -18,6 +18,7 @@
 package firewall
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/mysteriumnetwork/node/firewall/iptables"
@@ -42,6 +43,58 @@ func Test_outgoingFirewallIptables_BlocksAllOutgoingTraffic(t *testing.T) {
 	assert.True(t, mockedExec.VerifyCalledWithArgs("-D", "OUTPUT", "-s", "1.1.1.1", "-j", killswitchChain))
 }
 
+func Test_outgoingFirewallIptables_BlocksOutgoingIPv6Network(t *testing.T) {
+	mockedExec := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	iptables.Exec = mockedExec.Exec
+	mockedExec6 := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	iptables.Exec6 = mockedExec6.Exec
+
+	fw := &outgoingFirewallIptables{
+		referenceTracker: make(map[string]refCount),
+		ipv6:             true,
+	}
+
+	removeRuleFunc, err := fw.BlockOutgoingTraffic(Session, "1.1.1.1", "2001:db8::5")
+	assert.NoError(t, err)
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-A", "OUTPUT", "-s", "1.1.1.1", "-j", killswitchChain))
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-A", "OUTPUT", "-s", "2001:db8::/64", "-j", killswitchChain))
+
+	removeRuleFunc()
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-D", "OUTPUT", "-s", "1.1.1.1", "-j", killswitchChain))
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-D", "OUTPUT", "-s", "2001:db8::/64", "-j", killswitchChain))
+}
+
+func Test_outgoingFirewallIptables_BlocksOutgoingTrafficToNetworks(t *testing.T) {
+	mockedExec := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	iptables.Exec = mockedExec.Exec
+
+	fw := &outgoingFirewallIptables{
+		referenceTracker: make(map[string]refCount),
+	}
+
+	removeRuleFunc, err := fw.BlockOutgoingTrafficTo(Session, []string{"10.0.0.0/8", "8.8.8.0/24"}, "1.1.1.1", "2001:db8::5")
+	assert.NoError(t, err)
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-A", "OUTPUT", "-s", "1.1.1.1", "-d", "10.0.0.0/8", "-j", killswitchChain))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-A", "OUTPUT", "-s", "1.1.1.1", "-d", "8.8.8.0/24", "-j", killswitchChain))
+	assert.False(t, mockedExec.VerifyCalledWithArgs("-A", "OUTPUT", "-s", "1.1.1.1", "-j", killswitchChain))
+
+	// Blocking all traffic is tracked separately from blocking the networks.
+	removeAllFunc, err := fw.BlockOutgoingTraffic(Session, "1.1.1.1")
+	assert.NoError(t, err)
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-A", "OUTPUT", "-s", "1.1.1.1", "-j", killswitchChain))
+	removeAllFunc()
+
+	removeRuleFunc()
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-D", "OUTPUT", "-s", "1.1.1.1", "-d", "10.0.0.0/8", "-j", killswitchChain))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-D", "OUTPUT", "-s", "1.1.1.1", "-d", "8.8.8.0/24", "-j", killswitchChain))
+}
+
 func Test_outgoingFirewallIptables_SessionTrafficBlockIsNoopWhenGlobalBlockWasCalled(t *testing.T) {
 	mockedExec := iptablesExecMock{
 		mocks: map[string]iptablesExecResult{},
@@ -123,6 +176,14 @@ func Test_outgoingFirewallIptables_SetupIsSuccessful(t *testing.T) {
 		},
 	}
 	iptables.Exec = mockedExec.Exec
+	mockedExec6 := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{
+			"--version": {
+				output: []string{"ip6tables v1.6.0"},
+			},
+		},
+	}
+	iptables.Exec6 = mockedExec6.Exec
 
 	fw := &outgoingFirewallIptables{
 		referenceTracker: make(map[string]refCount),
@@ -130,6 +191,43 @@ func Test_outgoingFirewallIptables_SetupIsSuccessful(t *testing.T) {
 	assert.NoError(t, fw.Setup())
 	assert.True(t, mockedExec.VerifyCalledWithArgs("-N", killswitchChain))
 	assert.True(t, mockedExec.VerifyCalledWithArgs("-A", killswitchChain, "-m", "conntrack", "--ctstate", "NEW", "-j", "REJECT"))
+	assert.False(t, mockedExec.VerifyCalledWithArgs("-I", killswitchChain, "1", "-p", "ipv6-icmp", "-j", "ACCEPT"))
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-N", killswitchChain))
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-A", killswitchChain, "-m", "conntrack", "--ctstate", "NEW", "-j", "REJECT"))
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-I", killswitchChain, "1", "-p", "ipv6-icmp", "-j", "ACCEPT"))
+	assert.True(t, fw.ipv6)
+}
+
+func Test_outgoingFirewallIptables_SetupIsSuccessfulWithoutIp6tables(t *testing.T) {
+	mockedExec := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{
+			"--version": {
+				output: []string{"iptables v1.6.0"},
+			},
+		},
+	}
+	iptables.Exec = mockedExec.Exec
+	mockedExec6 := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{
+			"--version": {
+				err: errors.New("ip6tables: command not found"),
+			},
+		},
+	}
+	iptables.Exec6 = mockedExec6.Exec
+
+	fw := &outgoingFirewallIptables{
+		referenceTracker: make(map[string]refCount),
+	}
+	assert.NoError(t, fw.Setup())
+	assert.False(t, fw.ipv6)
+	assert.False(t, mockedExec6.VerifyCalledWithArgs("-N", killswitchChain))
+
+	// IPv6 traffic is not blocked, but IPv4 one still is.
+	_, err := fw.BlockOutgoingTraffic(Session, "1.1.1.1", "2001:db8::5")
+	assert.NoError(t, err)
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-A", "OUTPUT", "-s", "1.1.1.1", "-j", killswitchChain))
+	assert.False(t, mockedExec6.VerifyCalledWithArgs("-A", "OUTPUT", "-s", "2001:db8::/64", "-j", killswitchChain))
 }
 
 func Test_outgoingFirewallIptables_SetupIsSucessfulIfPreviousCleanupFailed(t *testing.T) {
@@ -156,6 +254,7 @@ func Test_outgoingFirewallIptables_SetupIsSucessfulIfPreviousCleanupFailed(t *te
 		},
 	}
 	iptables.Exec = mockedExec.Exec
+	iptables.Exec6 = (&iptablesExecMock{mocks: map[string]iptablesExecResult{}}).Exec
 
 	fw := &outgoingFirewallIptables{
 		referenceTracker: make(map[string]refCount),
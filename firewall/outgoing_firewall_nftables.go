@@ -0,0 +1,147 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+import (
+	"net"
+	"net/url"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/firewall/nftables"
+	"github.com/rs/zerolog/log"
+)
+
+// killswitchTable holds all kill switch rules, so they are cleaned up by deleting the table.
+var killswitchTable = nftables.Table{Family: "inet", Name: "myst_consumer_kill_switch"}
+
+const (
+	killswitchOutputChain = "output"
+	killswitchRulesChain  = "kill_switch"
+)
+
+// killswitchTableDefinition rejects new connections of the blocked source addresses, except DNS, IPv6 neighbor discovery and allowed IPs.
+const killswitchTableDefinition = `	chain output {
+		type filter hook output priority 0; policy accept;
+	}
+	chain kill_switch {
+		udp dport 53 accept
+		tcp dport 53 accept
+		meta l4proto ipv6-icmp accept
+		ct state new reject
+	}`
+
+// outgoingFirewallNftables is the kill switch for systems with nftables, every change is applied in a single transaction.
+type outgoingFirewallNftables struct {
+	lock             sync.Mutex
+	trafficLockScope Scope
+	referenceTracker map[string]refCount
+}
+
+// Setup tries to setup all changes made by setup and leave system in the state before setup.
+func (obn *outgoingFirewallNftables) Setup() error {
+	return nftables.SetupTable(killswitchTable, killswitchTableDefinition)
+}
+
+// Teardown tries to cleanup all changes made by setup and leave system in the state before setup.
+func (obn *outgoingFirewallNftables) Teardown() {
+	if err := nftables.DeleteTable(killswitchTable); err != nil {
+		log.Warn().Err(err).Msg("Error cleaning up nftables rules, you might want to do it yourself")
+	}
+}
+
+// BlockOutgoingTraffic effectively disallows any outgoing traffic from consumer node with specified scope.
+// IPv6 traffic is blocked for the whole /64 network of the outbound IPv6 address, which covers temporary addresses too.
+func (obn *outgoingFirewallNftables) BlockOutgoingTraffic(scope Scope, outboundIPs ...string) (OutgoingRuleRemove, error) {
+	return obn.BlockOutgoingTrafficTo(scope, nil, outboundIPs...)
+}
+
+// BlockOutgoingTrafficTo disallows outgoing traffic to the given networks with specified scope, all traffic is blocked when none are given.
+func (obn *outgoingFirewallNftables) BlockOutgoingTrafficTo(scope Scope, networks []string, outboundIPs ...string) (OutgoingRuleRemove, error) {
+	if obn.trafficLockScope == Global {
+		// nothing can override global lock
+		return func() {}, nil
+	}
+	obn.trafficLockScope = scope
+	return trackingReferenceCall(&obn.lock, obn.referenceTracker, blockTrafficKey(networks), func() (OutgoingRuleRemove, error) {
+		var rules []nftables.Rule
+		for _, outboundIP := range outboundIPs {
+			source := outboundIP
+			ip := net.ParseIP(outboundIP)
+			ipv6 := ip != nil && ip.To4() == nil
+			if ipv6 {
+				source = (&net.IPNet{IP: ip.Mask(ipv6NetworkMask), Mask: ipv6NetworkMask}).String()
+			}
+			family := nftables.AddressFamily(source)
+			if len(networks) == 0 {
+				rules = append(rules, nftables.AppendTo(killswitchTable, killswitchOutputChain).Expr(
+					family, "saddr", source, "jump", killswitchRulesChain,
+				))
+				continue
+			}
+			for _, network := range networks {
+				if isIPv6Network(network) != ipv6 {
+					continue
+				}
+				rules = append(rules, nftables.AppendTo(killswitchTable, killswitchOutputChain).Expr(
+					family, "saddr", source, family, "daddr", network, "jump", killswitchRulesChain,
+				))
+			}
+		}
+		if len(rules) == 0 {
+			return func() {}, nil
+		}
+		return nftables.AddRulesWithRemoval(rules...)
+	})
+}
+
+// AllowIPAccess adds exception to blocked traffic for specified URL (host part is usually taken).
+func (obn *outgoingFirewallNftables) AllowIPAccess(ip string) (OutgoingRuleRemove, error) {
+	return trackingReferenceCall(&obn.lock, obn.referenceTracker, "allow:"+ip, func() (OutgoingRuleRemove, error) {
+		// Host names are resolved by nft and may have several addresses, hence the anonymous set.
+		return nftables.AddRulesWithRemoval(nftables.InsertTo(killswitchTable, killswitchRulesChain).Expr(
+			nftables.AddressFamily(ip), "daddr", "{", ip, "}", "accept",
+		))
+	})
+}
+
+// AllowURLAccess adds URL based exception.
+func (obn *outgoingFirewallNftables) AllowURLAccess(rawURLs ...string) (OutgoingRuleRemove, error) {
+	var ruleRemovers []func()
+	removeAll := func() {
+		for _, ruleRemover := range ruleRemovers {
+			ruleRemover()
+		}
+	}
+	for _, rawURL := range rawURLs {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+
+		remover, err := obn.AllowIPAccess(parsed.Hostname())
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+		ruleRemovers = append(ruleRemovers, remover)
+	}
+	return removeAll, nil
+}
+
+var _ OutgoingTrafficFirewall = &outgoingFirewallNftables{}
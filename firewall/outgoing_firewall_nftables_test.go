@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+import (
+	"testing"
+
+	"github.com/mysteriumnetwork/node/firewall/nftables"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_outgoingFirewallNftables_BlocksOutgoingTrafficInSingleTransaction(t *testing.T) {
+	mockedApply := &nftablesApplyMock{}
+	nftables.Apply = mockedApply.Apply
+
+	fw := &outgoingFirewallNftables{
+		referenceTracker: make(map[string]refCount),
+	}
+
+	removeRuleFunc, err := fw.BlockOutgoingTraffic(Session, "1.1.1.1", "2001:db8::5")
+	assert.NoError(t, err)
+	assert.True(t, mockedApply.VerifyApplied(
+		"add rule inet myst_consumer_kill_switch output ip saddr 1.1.1.1 jump kill_switch\n"+
+			"add rule inet myst_consumer_kill_switch output ip6 saddr 2001:db8::/64 jump kill_switch\n",
+	))
+
+	removeRuleFunc()
+	assert.True(t, mockedApply.VerifyApplied(
+		"delete rule inet myst_consumer_kill_switch output handle 1\n"+
+			"delete rule inet myst_consumer_kill_switch output handle 2\n",
+	))
+}
+
+func Test_outgoingFirewallNftables_BlocksOutgoingTrafficToNetworks(t *testing.T) {
+	mockedApply := &nftablesApplyMock{}
+	nftables.Apply = mockedApply.Apply
+
+	fw := &outgoingFirewallNftables{
+		referenceTracker: make(map[string]refCount),
+	}
+
+	removeRuleFunc, err := fw.BlockOutgoingTrafficTo(Session, []string{"10.0.0.0/8"}, "1.1.1.1", "2001:db8::5")
+	assert.NoError(t, err)
+	assert.True(t, mockedApply.VerifyApplied(
+		"add rule inet myst_consumer_kill_switch output ip saddr 1.1.1.1 ip daddr 10.0.0.0/8 jump kill_switch\n",
+	))
+
+	removeRuleFunc()
+	assert.True(t, mockedApply.VerifyApplied("delete rule inet myst_consumer_kill_switch output handle 1\n"))
+}
+
+func Test_outgoingFirewallNftables_SessionTrafficBlockIsNoopWhenGlobalBlockWasCalled(t *testing.T) {
+	mockedApply := &nftablesApplyMock{}
+	nftables.Apply = mockedApply.Apply
+
+	fw := &outgoingFirewallNftables{
+		referenceTracker: make(map[string]refCount),
+	}
+
+	_, err := fw.BlockOutgoingTraffic(Global, "1.1.1.1")
+	assert.NoError(t, err)
+	assert.Len(t, mockedApply.scripts, 1)
+
+	_, err = fw.BlockOutgoingTraffic(Session, "1.1.1.1")
+	assert.NoError(t, err)
+	assert.Len(t, mockedApply.scripts, 1)
+}
+
+func Test_outgoingFirewallNftables_AllowIPAccess(t *testing.T) {
+	mockedApply := &nftablesApplyMock{}
+	nftables.Apply = mockedApply.Apply
+
+	fw := &outgoingFirewallNftables{
+		referenceTracker: make(map[string]refCount),
+	}
+
+	removeRuleFunc, err := fw.AllowURLAccess("https://example.com:8080/path", "http://[2001:db8::1]")
+	assert.NoError(t, err)
+	assert.True(t, mockedApply.VerifyApplied("insert rule inet myst_consumer_kill_switch kill_switch ip daddr { example.com } accept\n"))
+	assert.True(t, mockedApply.VerifyApplied("insert rule inet myst_consumer_kill_switch kill_switch ip6 daddr { 2001:db8::1 } accept\n"))
+
+	removeRuleFunc()
+	assert.True(t, mockedApply.VerifyApplied("delete rule inet myst_consumer_kill_switch kill_switch handle 1\n"))
+	assert.True(t, mockedApply.VerifyApplied("delete rule inet myst_consumer_kill_switch kill_switch handle 2\n"))
+}
+
+func Test_outgoingFirewallNftables_SetupReplacesTable(t *testing.T) {
+	mockedApply := &nftablesApplyMock{}
+	nftables.Apply = mockedApply.Apply
+
+	fw := &outgoingFirewallNftables{
+		referenceTracker: make(map[string]refCount),
+	}
+
+	assert.NoError(t, fw.Setup())
+	assert.Len(t, mockedApply.scripts, 1)
+	assert.Contains(t, mockedApply.scripts[0], "delete table inet myst_consumer_kill_switch\n")
+	assert.Contains(t, mockedApply.scripts[0], "ct state new reject")
+
+	fw.Teardown()
+	assert.True(t, mockedApply.VerifyApplied("table inet myst_consumer_kill_switch\ndelete table inet myst_consumer_kill_switch\n"))
+}
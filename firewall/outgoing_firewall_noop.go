@@ -36,13 +36,21 @@ func (ofn *outgoingFirewallNoop) Teardown() {
 }
 
 // BlockOutgoingTraffic just logs the call.
-func (ofn *outgoingFirewallNoop) BlockOutgoingTraffic(scope Scope, outboundIP string) (OutgoingRuleRemove, error) {
+func (ofn *outgoingFirewallNoop) BlockOutgoingTraffic(scope Scope, outboundIPs ...string) (OutgoingRuleRemove, error) {
 	log.Info().Msg("Outgoing traffic block requested")
 	return func() {
 		log.Info().Msg("Outgoing traffic block removed")
 	}, nil
 }
 
+// BlockOutgoingTrafficTo just logs the call.
+func (ofn *outgoingFirewallNoop) BlockOutgoingTrafficTo(scope Scope, networks []string, outboundIPs ...string) (OutgoingRuleRemove, error) {
+	log.Info().Msgf("Outgoing traffic block to %v requested", networks)
+	return func() {
+		log.Info().Msgf("Outgoing traffic block to %v removed", networks)
+	}, nil
+}
+
 // AllowIPAccess logs IP for which access was requested.
 func (ofn *outgoingFirewallNoop) AllowIPAccess(ip string) (OutgoingRuleRemove, error) {
 	log.Info().Msgf("Allow IP %s access", ip)
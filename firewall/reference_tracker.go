@@ -0,0 +1,60 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+import "sync"
+
+type refCount struct {
+	count int
+	f     func()
+}
+
+// trackingReferenceCall makes the actual call only for the first reference, the rule is removed when the reference is released.
+func trackingReferenceCall(lock *sync.Mutex, tracker map[string]refCount, ref string, actualCall func() (OutgoingRuleRemove, error)) (OutgoingRuleRemove, error) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	refCount := tracker[ref]
+	if refCount.count == 0 {
+		removeRule, err := actualCall()
+		if err != nil {
+			return nil, err
+		}
+		refCount.f = removeRule
+
+		refCount.count++
+		tracker[ref] = refCount
+	}
+
+	return decreaseRefCall(lock, tracker, ref), nil
+}
+
+func decreaseRefCall(lock *sync.Mutex, tracker map[string]refCount, ref string) OutgoingRuleRemove {
+	return func() {
+		lock.Lock()
+		defer lock.Unlock()
+
+		refCount := tracker[ref]
+		if refCount.count == 1 {
+			refCount.f()
+
+			refCount.count--
+			tracker[ref] = refCount
+		}
+	}
+}
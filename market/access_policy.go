@@ -24,6 +24,12 @@ const (
 	AccessPolicyTypeDNSHostname = "dns_hostname"
 	// AccessPolicyTypeDNSZone Explicitly allow just specific DNS zone ("example.com" matches "example.com" and all of its subdomains)
 	AccessPolicyTypeDNSZone = "dns_zone"
+	// AccessPolicyTypeDestinationCIDR Explicitly allow just specific destination network ("93.184.216.0/24" or "93.184.216.34")
+	AccessPolicyTypeDestinationCIDR = "dst_cidr"
+	// AccessPolicyTypeDestinationPort Explicitly allow just specific destination port, optionally limited to protocol ("tcp:443", "udp:53", "8000-8100")
+	AccessPolicyTypeDestinationPort = "dst_port"
+	// AccessPolicyTypeConsumerCountry Explicitly allow just consumers from specific country ("LT")
+	AccessPolicyTypeConsumerCountry = "consumer_country"
 )
 
 // AccessPolicy represents the access controls for proposal
@@ -38,6 +44,8 @@ type AccessPolicyRuleSet struct {
 	Title       string       `json:"title"`
 	Description string       `json:"description"`
 	Allow       []AccessRule `json:"allow"`
+	// Deny rules take precedence over the allow ones
+	Deny []AccessRule `json:"deny,omitempty"`
 }
 
 // AccessRule represents rule specifying whether connection should be allowed
@@ -28,6 +28,11 @@ type ServiceDefinition interface {
 	GetLocation() Location
 }
 
+// IPv6ServiceDefinition is implemented by service definitions of the services, which may route IPv6 traffic.
+type IPv6ServiceDefinition interface {
+	SupportsIPv6() bool
+}
+
 // UnsupportedServiceDefinition represents unknown or unsupported service definition returned by deserializer
 type UnsupportedServiceDefinition struct {
 }
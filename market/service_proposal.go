@@ -57,6 +57,9 @@ type ServiceProposal struct {
 
 	// AccessPolicies represents the access controls for proposal
 	AccessPolicies *[]AccessPolicy `json:"access_policies,omitempty"`
+
+	// Bandwidth limit of every session in Kbps, sessions are not limited when zero
+	BandwidthKbps uint64 `json:"bandwidth_kbps,omitempty"`
 }
 
 // UniqueID returns unique proposal composite ID
@@ -79,6 +82,7 @@ func (proposal *ServiceProposal) UnmarshalJSON(data []byte) error {
 		PaymentMethod     *json.RawMessage `json:"payment_method"`
 		ProviderContacts  *json.RawMessage `json:"provider_contacts"`
 		AccessPolicies    *[]AccessPolicy  `json:"access_policies,omitempty"`
+		BandwidthKbps     uint64           `json:"bandwidth_kbps,omitempty"`
 	}
 	if err := json.Unmarshal(data, &jsonData); err != nil {
 		return err
@@ -106,6 +110,7 @@ func (proposal *ServiceProposal) UnmarshalJSON(data []byte) error {
 	proposal.ProviderContacts = unserializeContacts(jsonData.ProviderContacts)
 
 	proposal.AccessPolicies = jsonData.AccessPolicies
+	proposal.BandwidthKbps = jsonData.BandwidthKbps
 	return nil
 }
 
@@ -123,6 +128,11 @@ func (proposal *ServiceProposal) SetAccessPolicies(ap *[]AccessPolicy) {
 	proposal.AccessPolicies = ap
 }
 
+// SetBandwidth updates bandwidth limit of every session in the proposal.
+func (proposal *ServiceProposal) SetBandwidth(kbps uint64) {
+	proposal.BandwidthKbps = kbps
+}
+
 // SetPaymentMethod updates payment method in the proposal.
 func (proposal *ServiceProposal) SetPaymentMethod(pm PaymentMethod) {
 	if pm != nil {
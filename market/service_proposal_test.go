@@ -257,3 +257,28 @@ func Test_ServiceProposal_UnserializeAccessPolicy(t *testing.T) {
 	assert.Equal(t, expected, actual)
 	assert.True(t, actual.IsSupported())
 }
+
+func Test_ServiceProposal_UnserializeBandwidth(t *testing.T) {
+	jsonData := []byte(`{
+		"id": 1,
+		"format": "format/X",
+		"service_type": "mock_service",
+		"service_definition": null,
+		"payment_method_type": "mock_payment",
+		"payment_method": {},
+		"provider_id": "node",
+		"provider_contacts": [
+			{ "type" : "mock_contact" , "definition" : {}}
+		],
+		"bandwidth_kbps": 10000
+	}`)
+
+	var actual ServiceProposal
+	err := json.Unmarshal(jsonData, &actual)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10000), actual.BandwidthKbps)
+
+	data, err := json.Marshal(actual)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"bandwidth_kbps":10000`)
+}
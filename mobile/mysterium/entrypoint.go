@@ -423,6 +423,10 @@ func (mb *MobileNode) Reconnect(req *ConnectRequest) *ConnectResponse {
 	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
 	defer cancel()
 	if err := mb.connectionManager.CheckChannel(ctx); err != nil {
+		if err := mb.connectionManager.Resume(); err == nil {
+			log.Info().Msg("Reconnect is not needed - p2p channel is resumed")
+			return &ConnectResponse{}
+		}
 		log.Info().Msgf("Forcing reconnect after failed channel: %s", err)
 		return reconnect()
 	}
@@ -130,7 +130,12 @@ func (c *wireguardConnection) Start(ctx context.Context, options connection.Conn
 	if options.ProviderNATConn != nil {
 		options.ProviderNATConn.Close()
 		config.LocalPort = options.ProviderNATConn.LocalAddr().(*net.UDPAddr).Port
-		config.Provider.Endpoint.Port = options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).Port
+		remoteAddr := options.ProviderNATConn.RemoteAddr().(*net.UDPAddr)
+		// Provider is reached via relay when NAT hole punching fails.
+		if !remoteAddr.IP.IsLoopback() {
+			config.Provider.Endpoint.IP = remoteAddr.IP
+		}
+		config.Provider.Endpoint.Port = remoteAddr.Port
 	}
 
 	if err := c.device.Start(c.privateKey, config, options.ChannelConn); err != nil {
@@ -264,6 +269,9 @@ func (w *wireguardDeviceImpl) applyConfig(devApi *device.Device, privateKey stri
 			AllowedIPs: []string{"0.0.0.0/1", "128.0.0.0/1"},
 		},
 	}
+	if config.Consumer.IPv6Address.IP != nil {
+		deviceConfig.Peer.AllowedIPs = append(deviceConfig.Peer.AllowedIPs, "::/1", "8000::/1")
+	}
 
 	if err := devApi.IpcSetOperation(bufio.NewReader(strings.NewReader(deviceConfig.Encode()))); err != nil {
 		return fmt.Errorf("could not complete ipc operation: %w", err)
@@ -277,6 +285,10 @@ func (w *wireguardDeviceImpl) newTunnDevice(wgTunnSetup WireguardTunnelSetup, co
 	wgTunnSetup.NewTunnel()
 	wgTunnSetup.SetSessionName("wg-tun-session")
 	wgTunnSetup.AddTunnelAddress(consumerIP.IP.String(), prefixLen)
+	if consumerIPv6 := config.Consumer.IPv6Address; consumerIPv6.IP != nil {
+		prefixLen6, _ := consumerIPv6.Mask.Size()
+		wgTunnSetup.AddTunnelAddress(consumerIPv6.IP.String(), prefixLen6)
+	}
 	wgTunnSetup.SetMTU(androidTunMtu)
 	wgTunnSetup.SetBlocking(true)
 
@@ -292,6 +304,9 @@ func (w *wireguardDeviceImpl) newTunnDevice(wgTunnSetup WireguardTunnelSetup, co
 	// Route all traffic through tunnel
 	wgTunnSetup.AddRoute("0.0.0.0", 1)
 	wgTunnSetup.AddRoute("128.0.0.0", 1)
+	// IPv6 traffic is routed through tunnel too, it is dropped there if provider does not route it, instead of leaking outside.
+	wgTunnSetup.AddRoute("::", 1)
+	wgTunnSetup.AddRoute("8000::", 1)
 
 	fd, err := wgTunnSetup.Establish()
 	if err != nil {
@@ -17,18 +17,40 @@
 
 package nat
 
-import "os/exec"
+import (
+	"os/exec"
 
-// NewService returns linux os specific nat service based on ip tables
+	"github.com/mysteriumnetwork/node/firewall/nftables"
+)
+
+// NewService returns linux os specific nat service based on nftables when preferred by the chosen firewall backend,
+// ip tables otherwise. nftables accept verdicts can't override iptables FORWARD chain drops, see nftables.Preferred.
 func NewService() NATService {
-	return &serviceIPTables{
-		ipForward: serviceIPForward{
-			CommandFactory: func(name string, arg ...string) Command {
-				return exec.Command(name, arg...)
-			},
-			CommandEnable:  []string{"sudo", "/sbin/sysctl", "-w", "net.ipv4.ip_forward=1"},
-			CommandDisable: []string{"sudo", "/sbin/sysctl", "-w", "net.ipv4.ip_forward=0"},
-			CommandRead:    []string{"/sbin/sysctl", "-n", "net.ipv4.ip_forward"},
+	ipForward := serviceIPForward{
+		CommandFactory: func(name string, arg ...string) Command {
+			return exec.Command(name, arg...)
+		},
+		CommandEnable:  []string{"sudo", "/sbin/sysctl", "-w", "net.ipv4.ip_forward=1"},
+		CommandDisable: []string{"sudo", "/sbin/sysctl", "-w", "net.ipv4.ip_forward=0"},
+		CommandRead:    []string{"/sbin/sysctl", "-n", "net.ipv4.ip_forward"},
+	}
+	ipForward6 := serviceIPForward{
+		CommandFactory: func(name string, arg ...string) Command {
+			return exec.Command(name, arg...)
 		},
+		CommandEnable:  []string{"sudo", "/sbin/sysctl", "-w", "net.ipv6.conf.all.forwarding=1"},
+		CommandDisable: []string{"sudo", "/sbin/sysctl", "-w", "net.ipv6.conf.all.forwarding=0"},
+		CommandRead:    []string{"/sbin/sysctl", "-n", "net.ipv6.conf.all.forwarding"},
+	}
+
+	if nftables.Preferred() {
+		return &serviceNftables{
+			ipForward:  ipForward,
+			ipForward6: ipForward6,
+		}
+	}
+	return &serviceIPTables{
+		ipForward:  ipForward,
+		ipForward6: ipForward6,
 	}
 }
@@ -28,10 +28,19 @@ type NATService interface {
 	Disable() error
 }
 
+// IPv6Support is implemented by NAT services, which are able to forward IPv6 traffic.
+type IPv6Support interface {
+	SupportsIPv6() bool
+}
+
 // Options params to setup firewall/NAT rules.
 type Options struct {
-	VPNNetwork        net.IPNet
-	ProviderExtIP     net.IP
+	VPNNetwork    net.IPNet
+	ProviderExtIP net.IP
+	// VPNNetwork6 is forwarded by the NAT services supporting IPv6, unique local networks are NATed to ProviderExtIP6,
+	// global ones are routed as is.
+	VPNNetwork6       net.IPNet
+	ProviderExtIP6    net.IP
 	EnableDNSRedirect bool
 	DNSIP             net.IP
 	DNSPort           int
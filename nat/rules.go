@@ -25,7 +25,8 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func protectedNetworks() (nets []*net.IPNet) {
+// ProtectedNetworks returns the provider networks protected from access by consumers.
+func ProtectedNetworks() (nets []*net.IPNet) {
 	cfg := config.GetString(config.FlagFirewallProtectedNetworks)
 	if cfg == "" {
 		return nil
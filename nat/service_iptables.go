@@ -18,6 +18,7 @@
 package nat
 
 import (
+	"net"
 	"strconv"
 	"sync"
 
@@ -32,8 +33,16 @@ type serviceIPTables struct {
 	mu        sync.Mutex
 	rules     []iptables.Rule
 	ipForward serviceIPForward
+	// ipForward6 is enabled only once IPv6 network is set up, since it stops the system from accepting router advertisements by default.
+	ipForward6        serviceIPForward
+	ipForward6Enabled bool
 }
 
+var _ IPv6Support = &serviceIPTables{}
+
+// uniqueLocalNetwork is IPv6 range of the networks, which are not routed in the internet (RFC 4193).
+var uniqueLocalNetwork = net.IPNet{IP: net.ParseIP("fc00::"), Mask: net.CIDRMask(7, 128)}
+
 const (
 	chainForward     = "FORWARD"
 	chainPreRouting  = "PREROUTING"
@@ -60,7 +69,15 @@ func (svc *serviceIPTables) Setup(opts Options) (appliedRules []interface{}, err
 		}
 	}()
 
-	for _, rule := range makeIPTablesRules(opts) {
+	rules := makeIPTablesRules(opts)
+	if opts.VPNNetwork6.IP != nil {
+		if err := svc.enableIPForward6(); err != nil {
+			return nil, err
+		}
+		rules = append(rules, makeIP6TablesRules(opts)...)
+	}
+
+	for _, rule := range rules {
 		if err := svc.applyRule(rule); err != nil {
 			return nil, err
 		}
@@ -100,11 +117,31 @@ func (svc *serviceIPTables) Enable() error {
 // Disable disables NAT service and deletes all rules.
 func (svc *serviceIPTables) Disable() error {
 	svc.ipForward.Disable()
+	if svc.ipForward6Enabled {
+		svc.ipForward6.Disable()
+		svc.ipForward6Enabled = false
+	}
 	return svc.Del(untypedIptRules(svc.rules))
 }
 
+// SupportsIPv6 reports that IPv6 network is forwarded by ip6tables rules.
+func (svc *serviceIPTables) SupportsIPv6() bool {
+	return true
+}
+
+func (svc *serviceIPTables) enableIPForward6() error {
+	if svc.ipForward6Enabled {
+		return nil
+	}
+	if err := svc.ipForward6.Enable(); err != nil {
+		return errors.Wrap(err, "failed to enable IPv6 forwarding")
+	}
+	svc.ipForward6Enabled = true
+	return nil
+}
+
 func (svc *serviceIPTables) applyRule(rule iptables.Rule) error {
-	if err := iptablesExec(rule.ApplyArgs()...); err != nil {
+	if err := execRule(rule, rule.ApplyArgs()...); err != nil {
 		return err
 	}
 	svc.rules = append(svc.rules, rule)
@@ -112,7 +149,7 @@ func (svc *serviceIPTables) applyRule(rule iptables.Rule) error {
 }
 
 func (svc *serviceIPTables) removeRule(rule iptables.Rule) error {
-	if err := iptablesExec(rule.RemoveArgs()...); err != nil {
+	if err := execRule(rule, rule.RemoveArgs()...); err != nil {
 		return err
 	}
 	for i := range svc.rules {
@@ -148,7 +185,10 @@ func makeIPTablesRules(opts Options) (rules []iptables.Rule) {
 	}
 
 	// Protect private networks rule
-	for _, ipNet := range protectedNetworks() {
+	for _, ipNet := range ProtectedNetworks() {
+		if ipNet.IP.To4() == nil {
+			continue
+		}
 		rule := iptables.AppendTo(chainForward).RuleSpec(
 			"--source", vpnNetwork, "--destination", ipNet.String(),
 			"--jump", "DROP")
@@ -168,6 +208,45 @@ func makeIPTablesRules(opts Options) (rules []iptables.Rule) {
 	return rules
 }
 
+func makeIP6TablesRules(opts Options) (rules []iptables.Rule) {
+	vpnNetwork := opts.VPNNetwork6.String()
+
+	// Protect private networks rule, other sessions networks are protected too
+	protected := []*net.IPNet{&uniqueLocalNetwork}
+	for _, ipNet := range ProtectedNetworks() {
+		if ipNet.IP.To4() == nil {
+			protected = append(protected, ipNet)
+		}
+	}
+	for _, ipNet := range protected {
+		rule := iptables.AppendTo(chainForward).RuleSpec(
+			"--source", vpnNetwork, "--destination", ipNet.String(),
+			"--jump", "DROP").IPv6()
+		rules = append(rules, rule)
+	}
+
+	// NAT forwarding rule, global networks are routed without translation
+	if uniqueLocalNetwork.Contains(opts.VPNNetwork6.IP) {
+		rule := iptables.AppendTo(chainPostRouting).RuleSpec("--source", vpnNetwork, "!", "--destination", vpnNetwork,
+			"--jump", "SNAT", "--to", opts.ProviderExtIP6.String(),
+			"--table", "nat").IPv6()
+		rules = append(rules, rule)
+	}
+
+	// ACCEPT forwarding rules
+	rules = append(rules, iptables.AppendTo(chainForward).RuleSpec("--source", vpnNetwork, "--jump", "ACCEPT").IPv6())
+	rules = append(rules, iptables.AppendTo(chainForward).RuleSpec("--destination", vpnNetwork, "--jump", "ACCEPT").IPv6())
+
+	return rules
+}
+
+func execRule(rule iptables.Rule, args ...string) error {
+	if rule.IsIPv6() {
+		return ip6tablesExec(args...)
+	}
+	return iptablesExec(args...)
+}
+
 func iptablesExec(args ...string) error {
 	args = append([]string{"/usr/sbin/iptables"}, args...)
 	if err := cmdutil.SudoExec(args...); err != nil {
@@ -176,6 +255,14 @@ func iptablesExec(args ...string) error {
 	return nil
 }
 
+func ip6tablesExec(args ...string) error {
+	args = append([]string{"/usr/sbin/ip6tables"}, args...)
+	if err := cmdutil.SudoExec(args...); err != nil {
+		return errors.Wrap(err, "error calling IP6Tables")
+	}
+	return nil
+}
+
 func untypedIptRules(rules []iptables.Rule) []interface{} {
 	res := make([]interface{}, len(rules))
 	for i := range rules {
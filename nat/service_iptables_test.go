@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nat
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_makeIP6TablesRules_NATsUniqueLocalNetwork(t *testing.T) {
+	rules := makeIP6TablesRules(Options{
+		VPNNetwork6:    net.IPNet{IP: net.ParseIP("fd10:182:0:1::"), Mask: net.CIDRMask(64, 128)},
+		ProviderExtIP6: net.ParseIP("2001:db8::1"),
+	})
+
+	var args [][]string
+	for _, rule := range rules {
+		assert.True(t, rule.IsIPv6())
+		args = append(args, rule.ApplyArgs())
+	}
+	assert.Contains(t, args, []string{"-A", "FORWARD", "--source", "fd10:182:0:1::/64", "--destination", "fc00::/7", "--jump", "DROP"})
+	assert.Contains(t, args, []string{"-A", "POSTROUTING", "--source", "fd10:182:0:1::/64", "!", "--destination", "fd10:182:0:1::/64", "--jump", "SNAT", "--to", "2001:db8::1", "--table", "nat"})
+	assert.Contains(t, args, []string{"-A", "FORWARD", "--source", "fd10:182:0:1::/64", "--jump", "ACCEPT"})
+	assert.Contains(t, args, []string{"-A", "FORWARD", "--destination", "fd10:182:0:1::/64", "--jump", "ACCEPT"})
+}
+
+func Test_makeIP6TablesRules_RoutesGlobalNetwork(t *testing.T) {
+	rules := makeIP6TablesRules(Options{
+		VPNNetwork6:    net.IPNet{IP: net.ParseIP("2001:db8:0:1::"), Mask: net.CIDRMask(64, 128)},
+		ProviderExtIP6: net.ParseIP("2001:db8::1"),
+	})
+
+	for _, rule := range rules {
+		assert.NotContains(t, rule.ApplyArgs(), "SNAT")
+	}
+	assert.Len(t, rules, 3)
+}
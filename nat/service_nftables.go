@@ -0,0 +1,237 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nat
+
+import (
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/firewall/nftables"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// natTable holds all NAT/Firewall rules of the sessions, so they are cleaned up by deleting the table.
+var natTable = nftables.Table{Family: "inet", Name: "myst_nat"}
+
+const (
+	natPreRoutingChain  = "prerouting"
+	natPostRoutingChain = "postrouting"
+	natForwardChain     = "forward"
+)
+
+// natTableDefinition has base chains only, the rules are added per session.
+// Note that the accept verdict is final for this table only, a drop policy of other tables still applies.
+const natTableDefinition = `	chain prerouting {
+		type nat hook prerouting priority -100; policy accept;
+	}
+	chain postrouting {
+		type nat hook postrouting priority 100; policy accept;
+	}
+	chain forward {
+		type filter hook forward priority 0; policy accept;
+	}`
+
+// serviceNftables is the NAT service for systems with nftables, rules of each session are applied in a single transaction.
+type serviceNftables struct {
+	mu         sync.Mutex
+	tableReady bool
+	ipForward  serviceIPForward
+	// ipForward6 is enabled only once IPv6 network is set up, since it stops the system from accepting router advertisements by default.
+	ipForward6        serviceIPForward
+	ipForward6Enabled bool
+}
+
+var _ IPv6Support = &serviceNftables{}
+
+// Setup sets NAT/Firewall rules for the given NATOptions.
+func (svc *serviceNftables) Setup(opts Options) (appliedRules []interface{}, err error) {
+	log.Info().Msg("Setting up NAT/Firewall rules")
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	if err := svc.setupTable(); err != nil {
+		return nil, err
+	}
+
+	rules := makeNftablesRules(opts)
+	if opts.VPNNetwork6.IP != nil {
+		if err := svc.enableIPForward6(); err != nil {
+			return nil, err
+		}
+		rules = append(rules, makeNftables6Rules(opts)...)
+	}
+
+	applied, err := nftables.AddRules(rules...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to add nftables rules")
+	}
+	log.Info().Msg("Setting up NAT/Firewall rules... done")
+	return untypedNftRules(applied), nil
+}
+
+// Del removes given NAT/Firewall rules that were previously set up.
+func (svc *serviceNftables) Del(rules []interface{}) error {
+	log.Info().Msg("Deleting NAT/Firewall rules")
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	if !svc.tableReady {
+		return nil
+	}
+	err := nftables.DeleteRules(typedNftRules(rules)...)
+	log.Info().Err(err).Msg("Deleting NAT/Firewall rules... done")
+	return err
+}
+
+// Enable enables NAT service.
+func (svc *serviceNftables) Enable() error {
+	err := svc.ipForward.Enable()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to enable IP forwarding")
+		return err
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	return svc.setupTable()
+}
+
+// Disable disables NAT service and deletes all rules.
+func (svc *serviceNftables) Disable() error {
+	svc.ipForward.Disable()
+	if svc.ipForward6Enabled {
+		svc.ipForward6.Disable()
+		svc.ipForward6Enabled = false
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	svc.tableReady = false
+	return nftables.DeleteTable(natTable)
+}
+
+// SupportsIPv6 reports that IPv6 network is forwarded by the same inet table.
+func (svc *serviceNftables) SupportsIPv6() bool {
+	return true
+}
+
+// setupTable creates the table once, dropping the rules left by the previous run.
+func (svc *serviceNftables) setupTable() error {
+	if svc.tableReady {
+		return nil
+	}
+	if err := nftables.SetupTable(natTable, natTableDefinition); err != nil {
+		return errors.Wrap(err, "failed to setup nftables table")
+	}
+	svc.tableReady = true
+	return nil
+}
+
+func (svc *serviceNftables) enableIPForward6() error {
+	if svc.ipForward6Enabled {
+		return nil
+	}
+	if err := svc.ipForward6.Enable(); err != nil {
+		return errors.Wrap(err, "failed to enable IPv6 forwarding")
+	}
+	svc.ipForward6Enabled = true
+	return nil
+}
+
+func makeNftablesRules(opts Options) (rules []nftables.Rule) {
+	vpnNetwork := opts.VPNNetwork.String()
+
+	if opts.EnableDNSRedirect {
+		// DNS port redirect rules
+		for _, protocol := range []string{"udp", "tcp"} {
+			rules = append(rules, nftables.AppendTo(natTable, natPreRoutingChain).Expr(
+				"ip", "saddr", vpnNetwork, "ip", "daddr", opts.DNSIP.String(), protocol, "dport", "53",
+				"redirect", "to", ":"+strconv.Itoa(opts.DNSPort),
+			))
+		}
+	}
+
+	// Protect private networks rule
+	for _, ipNet := range ProtectedNetworks() {
+		if ipNet.IP.To4() == nil {
+			continue
+		}
+		rules = append(rules, nftables.AppendTo(natTable, natForwardChain).Expr(
+			"ip", "saddr", vpnNetwork, "ip", "daddr", ipNet.String(), "drop",
+		))
+	}
+
+	// NAT forwarding rule
+	rules = append(rules, nftables.AppendTo(natTable, natPostRoutingChain).Expr(
+		"ip", "saddr", vpnNetwork, "ip", "daddr", "!=", vpnNetwork, "snat", "to", opts.ProviderExtIP.String(),
+	))
+
+	// ACCEPT forwarding rules
+	rules = append(rules, nftables.AppendTo(natTable, natForwardChain).Expr("ip", "saddr", vpnNetwork, "accept"))
+	rules = append(rules, nftables.AppendTo(natTable, natForwardChain).Expr("ip", "daddr", vpnNetwork, "accept"))
+
+	return rules
+}
+
+func makeNftables6Rules(opts Options) (rules []nftables.Rule) {
+	vpnNetwork := opts.VPNNetwork6.String()
+
+	// Protect private networks rule, other sessions networks are protected too
+	protected := []*net.IPNet{&uniqueLocalNetwork}
+	for _, ipNet := range ProtectedNetworks() {
+		if ipNet.IP.To4() == nil {
+			protected = append(protected, ipNet)
+		}
+	}
+	for _, ipNet := range protected {
+		rules = append(rules, nftables.AppendTo(natTable, natForwardChain).Expr(
+			"ip6", "saddr", vpnNetwork, "ip6", "daddr", ipNet.String(), "drop",
+		))
+	}
+
+	// NAT forwarding rule, global networks are routed without translation
+	if uniqueLocalNetwork.Contains(opts.VPNNetwork6.IP) {
+		rules = append(rules, nftables.AppendTo(natTable, natPostRoutingChain).Expr(
+			"ip6", "saddr", vpnNetwork, "ip6", "daddr", "!=", vpnNetwork, "snat", "to", opts.ProviderExtIP6.String(),
+		))
+	}
+
+	// ACCEPT forwarding rules
+	rules = append(rules, nftables.AppendTo(natTable, natForwardChain).Expr("ip6", "saddr", vpnNetwork, "accept"))
+	rules = append(rules, nftables.AppendTo(natTable, natForwardChain).Expr("ip6", "daddr", vpnNetwork, "accept"))
+
+	return rules
+}
+
+func untypedNftRules(rules []nftables.Rule) []interface{} {
+	res := make([]interface{}, len(rules))
+	for i := range rules {
+		res[i] = rules[i]
+	}
+	return res
+}
+
+func typedNftRules(rules []interface{}) []nftables.Rule {
+	res := make([]nftables.Rule, len(rules))
+	for i := range rules {
+		res[i] = rules[i].(nftables.Rule)
+	}
+	return res
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nat
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_makeNftablesRules(t *testing.T) {
+	rules := makeNftablesRules(Options{
+		VPNNetwork:        net.IPNet{IP: net.ParseIP("10.182.0.0").To4(), Mask: net.CIDRMask(24, 32)},
+		ProviderExtIP:     net.ParseIP("192.0.2.1"),
+		EnableDNSRedirect: true,
+		DNSIP:             net.ParseIP("10.182.0.1"),
+		DNSPort:           11253,
+	})
+
+	var exprs []string
+	for _, rule := range rules {
+		exprs = append(exprs, rule.String())
+	}
+	assert.Equal(t, []string{
+		"ip saddr 10.182.0.0/24 ip daddr 10.182.0.1 udp dport 53 redirect to :11253",
+		"ip saddr 10.182.0.0/24 ip daddr 10.182.0.1 tcp dport 53 redirect to :11253",
+		"ip saddr 10.182.0.0/24 ip daddr != 10.182.0.0/24 snat to 192.0.2.1",
+		"ip saddr 10.182.0.0/24 accept",
+		"ip daddr 10.182.0.0/24 accept",
+	}, exprs)
+}
+
+func Test_makeNftables6Rules_NATsUniqueLocalNetwork(t *testing.T) {
+	rules := makeNftables6Rules(Options{
+		VPNNetwork6:    net.IPNet{IP: net.ParseIP("fd10:182:0:1::"), Mask: net.CIDRMask(64, 128)},
+		ProviderExtIP6: net.ParseIP("2001:db8::1"),
+	})
+
+	var exprs []string
+	for _, rule := range rules {
+		exprs = append(exprs, rule.String())
+	}
+	assert.Contains(t, exprs, "ip6 saddr fd10:182:0:1::/64 ip6 daddr fc00::/7 drop")
+	assert.Contains(t, exprs, "ip6 saddr fd10:182:0:1::/64 ip6 daddr != fd10:182:0:1::/64 snat to 2001:db8::1")
+	assert.Contains(t, exprs, "ip6 saddr fd10:182:0:1::/64 accept")
+	assert.Contains(t, exprs, "ip6 daddr fd10:182:0:1::/64 accept")
+}
+
+func Test_makeNftables6Rules_RoutesGlobalNetwork(t *testing.T) {
+	rules := makeNftables6Rules(Options{
+		VPNNetwork6:    net.IPNet{IP: net.ParseIP("2001:db8:0:1::"), Mask: net.CIDRMask(64, 128)},
+		ProviderExtIP6: net.ParseIP("2001:db8::1"),
+	})
+
+	for _, rule := range rules {
+		assert.NotContains(t, rule.String(), "snat")
+	}
+	assert.Len(t, rules, 3)
+}
@@ -105,7 +105,7 @@ func makePfctlRules(opts Options) (rules []string, err error) {
 	}
 
 	// Protect private networks rule
-	networks := protectedNetworks()
+	networks := ProtectedNetworks()
 	if len(networks) > 0 {
 		var targets []string
 		for _, network := range networks {
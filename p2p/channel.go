@@ -44,12 +44,19 @@ var (
 
 	// ErrHandlerNotFound indicates that peer is not registered handler yet.
 	ErrHandlerNotFound = errors.New("p2p peer handler not found")
+
+	// ErrResumeNotSupported indicates that channel can not be moved to another consumer address.
+	ErrResumeNotSupported = errors.New("p2p channel resume is not supported")
+
+	// punchPayload is sent to open NAT mapping towards the peer. KCP session drops it as it fails to decrypt.
+	punchPayload = []byte{0}
 )
 
 const (
 	kcpMTUSize            = 1280
 	mtuLimit              = 1500
 	initialTrafficTimeout = 10 * time.Second
+	punchPacketCount      = 3
 )
 
 // ChannelSender is used to send messages.
@@ -82,6 +89,15 @@ type Channel interface {
 	Close() error
 }
 
+// ResumableChannel is a consumer channel which can follow the consumer to another network.
+type ResumableChannel interface {
+	Channel
+
+	// Resume rebinds the channel to the current network and asks the peer to continue it
+	// from the new consumer address, keeping the channel and everything running over it.
+	Resume(ctx context.Context) error
+}
+
 // HandlerFunc is channel request handler func signature.
 type HandlerFunc func(c Context) error
 
@@ -179,6 +195,10 @@ type channel struct {
 
 	// terminate remote aliveness checking only once
 	remoteAliveOnce sync.Once
+
+	// roam asks the peer to continue channel from the current consumer public IP and given local ports.
+	// It is set for consumer channels only.
+	roam func(ctx context.Context, ports []int) error
 }
 
 // newChannel creates new p2p channel with initialized crypto primitives for data encryption
@@ -245,7 +265,6 @@ func (c *channel) launchReadSendLoops() {
 // If remote peer addr changes it will be updated and next send will use new addr.
 func (c *channel) remoteReadLoop() {
 	buf := make([]byte, mtuLimit)
-	latestPeerAddr := c.peer.addr()
 
 	go c.checkIfChannelAlive()
 
@@ -256,8 +275,12 @@ func (c *channel) remoteReadLoop() {
 		default:
 		}
 
-		n, addr, err := c.tr.remoteConn.ReadFrom(buf)
+		conn := c.remoteConn()
+		n, addr, err := conn.ReadFrom(buf)
 		if err != nil {
+			if c.remoteConnReplaced(conn) {
+				continue
+			}
 			if !errNetClose(err) {
 				log.Error().Err(err).Msg("Read from remote conn failed")
 			}
@@ -270,10 +293,9 @@ func (c *channel) remoteReadLoop() {
 
 		// Check if peer address changed.
 		if addr, ok := addr.(*net.UDPAddr); ok {
-			if !addr.IP.Equal(latestPeerAddr.IP) || addr.Port != latestPeerAddr.Port {
+			if latestPeerAddr := c.peer.addr(); !addr.IP.Equal(latestPeerAddr.IP) || addr.Port != latestPeerAddr.Port {
 				log.Debug().Msgf("Peer address changed from %v to %v", latestPeerAddr, addr)
 				c.peer.updateAddr(addr)
-				latestPeerAddr = addr
 			}
 		}
 
@@ -306,12 +328,18 @@ func (c *channel) remoteSendLoop() {
 			return
 		}
 
-		_, err = c.tr.remoteConn.WriteToUDP(buf[:n], c.peer.addr())
+		conn := c.remoteConn()
+		_, err = conn.WriteToUDP(buf[:n], c.peer.addr())
 		if err != nil {
-			if !errNetClose(err) {
-				log.Error().Err(err).Msgf("Write to remote peer conn failed")
+			if c.remoteConnReplaced(conn) {
+				continue
 			}
-			return
+			if errNetClose(err) {
+				return
+			}
+			// Network may be unreachable for a while when consumer switches networks,
+			// lost packets are retransmitted by KCP session.
+			log.Error().Err(err).Msgf("Write to remote peer conn failed")
 		}
 	}
 }
@@ -469,7 +497,33 @@ func (c *channel) Close() error {
 
 // Conn returns underlying channel's UDP connection.
 func (c *channel) Conn() *net.UDPConn {
-	return c.tr.remoteConn
+	return c.remoteConn()
+}
+
+// Resume rebinds the channel to the current network and asks the peer to continue it
+// from the new consumer address, keeping the channel and everything running over it.
+func (c *channel) Resume(ctx context.Context) error {
+	c.mu.RLock()
+	roam := c.roam
+	c.mu.RUnlock()
+	if roam == nil {
+		return ErrResumeNotSupported
+	}
+
+	if err := c.rebindRemoteConn(); err != nil {
+		return fmt.Errorf("could not rebind remote conn: %w", err)
+	}
+	// Open our NAT first, so that peer punching back towards us gets through.
+	c.punch(c.peer.addr())
+
+	ports := []int{c.Conn().LocalAddr().(*net.UDPAddr).Port}
+	if serviceConn := c.ServiceConn(); serviceConn != nil {
+		ports = append(ports, serviceConn.LocalAddr().(*net.UDPAddr).Port)
+	}
+	if err := roam(ctx, ports); err != nil {
+		return fmt.Errorf("could not move p2p channel to the new address: %w", err)
+	}
+	return nil
 }
 
 // Send sends message to given topic. Peer listening to topic will receive message.
@@ -547,6 +601,82 @@ func (c *channel) setServiceConn(conn *net.UDPConn) {
 	c.serviceConn = conn
 }
 
+func (c *channel) setRoam(roam func(ctx context.Context, ports []int) error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.roam = roam
+}
+
+// moveTo switches channel to the new peer address and punches a hole towards it.
+func (c *channel) moveTo(addr *net.UDPAddr) {
+	log.Debug().Msgf("Peer moved from %v to %v", c.peer.addr(), addr)
+	c.peer.updateAddr(addr)
+	c.punch(addr)
+}
+
+// moveServiceTo points service conn to the new peer address and punches a hole towards it from the service port.
+// Services which took over the port by closing the conn (e.g. wireguard) are left to follow the peer themselves.
+func (c *channel) moveServiceTo(addr *net.UDPAddr) {
+	serviceConn := c.ServiceConn()
+	if serviceConn == nil {
+		return
+	}
+	if err := reconnectUDP(serviceConn, addr); err != nil {
+		log.Debug().Err(err).Msgf("Could not move service conn to %v", addr)
+		return
+	}
+	for i := 0; i < punchPacketCount; i++ {
+		if _, err := serviceConn.Write(punchPayload); err != nil {
+			log.Debug().Err(err).Msgf("Could not punch towards %v from service port", addr)
+			return
+		}
+	}
+}
+
+func (c *channel) punch(addr *net.UDPAddr) {
+	for i := 0; i < punchPacketCount; i++ {
+		if _, err := c.remoteConn().WriteToUDP(punchPayload, addr); err != nil {
+			log.Debug().Err(err).Msgf("Could not punch towards %v", addr)
+			return
+		}
+	}
+}
+
+func (c *channel) remoteConn() *net.UDPConn {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.tr.remoteConn
+}
+
+// remoteConnReplaced checks whether conn was replaced by rebinding, so its errors can be ignored.
+func (c *channel) remoteConnReplaced(conn *net.UDPConn) bool {
+	return c.remoteConn() != conn
+}
+
+// rebindRemoteConn replaces remote conn bound to the address of the network it was created on
+// with the one listening on all interfaces on the same port.
+func (c *channel) rebindRemoteConn() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.stop:
+		return errors.New("channel is closed")
+	default:
+	}
+
+	localAddr := c.tr.remoteConn.LocalAddr().(*net.UDPAddr)
+	c.tr.remoteConn.Close()
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{Port: localAddr.Port})
+	if err != nil {
+		return fmt.Errorf("could not listen UDP: %w", err)
+	}
+	c.tr.remoteConn = conn
+	return nil
+}
+
 func (c *channel) setUpnpPortsRelease(release []func()) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -258,6 +258,38 @@ func TestChannel_Detect_And_Update_Peer_Addr(t *testing.T) {
 	_, err = consumer.Send(ctx, "ping", &Message{Data: []byte("pingasssas")})
 }
 
+func TestChannel_MoveServiceTo(t *testing.T) {
+	provider, consumer, err := createTestChannels()
+	require.NoError(t, err)
+	defer consumer.Close()
+	defer provider.Close()
+
+	ports, err := acquirePorts(2)
+	require.NoError(t, err)
+	serviceConn, err := net.DialUDP("udp4", &net.UDPAddr{Port: ports[0]}, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: ports[1]})
+	require.NoError(t, err)
+	provider.(*channel).setServiceConn(serviceConn)
+
+	// Consumer service conn moved to the new port.
+	consumerServiceConn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	defer consumerServiceConn.Close()
+	provider.(*channel).moveServiceTo(consumerServiceConn.LocalAddr().(*net.UDPAddr))
+
+	buf := make([]byte, 16)
+	require.NoError(t, consumerServiceConn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err := consumerServiceConn.ReadFromUDP(buf)
+	require.NoError(t, err)
+	assert.Equal(t, punchPayload, buf[:n])
+
+	_, err = consumerServiceConn.WriteToUDP([]byte("ping"), serviceConn.LocalAddr().(*net.UDPAddr))
+	require.NoError(t, err)
+	require.NoError(t, serviceConn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, err = serviceConn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf[:n]))
+}
+
 func BenchmarkChannel_Send(b *testing.B) {
 	provider, consumer, err := createTestChannels()
 	require.NoError(b, err)
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"time"
 
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/core/port"
@@ -36,6 +37,8 @@ const (
 	pingMaxPorts       = 20
 	requiredConnCount  = 2
 	consumerInitialTTL = 128
+	// relayDialTimeout limits waiting for the peer at the relay, since peers fall back to it at slightly different times.
+	relayDialTimeout = 20 * time.Second
 )
 
 type brokerConnector interface {
@@ -62,6 +65,10 @@ func channelHandlersReadySubject(providerID identity.Identity, serviceType strin
 	return fmt.Sprintf("%s.%s.p2p-channel-handlers-ready", providerID.Address, serviceType)
 }
 
+func channelRoamSubject(providerID identity.Identity, serviceType string) string {
+	return fmt.Sprintf("%s.%s.p2p-channel-roam", providerID.Address, serviceType)
+}
+
 func acquireLocalPorts(portPool port.ServicePortSupplier, n int) ([]int, error) {
 	ports, err := portPool.AcquireMultiple(n)
 	if err != nil {
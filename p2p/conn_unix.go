@@ -0,0 +1,44 @@
+//+build !windows
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"net"
+	"syscall"
+)
+
+// reconnectUDP points already connected UDP conn to the new remote address.
+// Conn keeps reporting the initial remote address, while packets are sent to and received from the new one.
+func reconnectUDP(conn *net.UDPConn, addr *net.UDPAddr) error {
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	sockaddr := &syscall.SockaddrInet4{Port: addr.Port}
+	copy(sockaddr.Addr[:], addr.IP.To4())
+
+	var connectErr error
+	if err := rawConn.Control(func(fd uintptr) {
+		connectErr = syscall.Connect(int(fd), sockaddr)
+	}); err != nil {
+		return err
+	}
+	return connectErr
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p/relay"
 	"github.com/mysteriumnetwork/node/pb"
 
 	"github.com/rs/zerolog/log"
@@ -113,6 +114,12 @@ func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Ident
 		dial = m.dialDirect
 	}
 	conn1, conn2, err := dial(ctx, providerID, config)
+	relayed := false
+	if err != nil && config.relay != "" {
+		log.Warn().Err(err).Msgf("Could not dial p2p channel directly, falling back to relay %s", config.relay)
+		conn1, conn2, err = m.dialRelay(ctx, config)
+		relayed = true
+	}
 	if err != nil {
 		return nil, fmt.Errorf("could not dial p2p channel: %w", err)
 	}
@@ -132,6 +139,12 @@ func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Ident
 	}
 	channel.setTracer(tracer)
 	channel.setServiceConn(conn2)
+	// Relay knows peers by their addresses, so relayed channel can not follow the consumer.
+	if !relayed {
+		channel.setRoam(func(ctx context.Context, ports []int) error {
+			return m.roam(ctx, consumerID, providerID, serviceType, contactDef, config, ports)
+		})
+	}
 	channel.launchReadSendLoops()
 	config.tracer.EndStage(traceAck)
 
@@ -206,6 +219,7 @@ func (m *dialer) startConfigExchange(config *p2pConnectConfig, ctx context.Conte
 	config.peerPubKey = peerPubKey
 	config.peerPublicIP = peerConnConfig.PublicIP
 	config.peerPorts = int32ToIntSlice(peerConnConfig.Ports)
+	config.peerRelays = peerConnConfig.Relays
 	return config, nil
 }
 
@@ -217,6 +231,17 @@ func (m *dialer) ackConfigExchange(config *p2pConnectConfig, ctx context.Context
 		PublicIP: config.publicIP,
 		Ports:    intToInt32Slice(config.localPorts),
 	}
+	// Pinger may fail if both peers are behind symmetric NAT, so choose the relay to fall back to.
+	if len(config.peerRelays) > 0 && len(config.peerPorts) != requiredConnCount {
+		token, err := relay.NewToken()
+		if err != nil {
+			return err
+		}
+		config.relay = config.peerRelays[0]
+		config.relayToken = token
+		connConfig.Relays = []string{config.relay}
+		connConfig.RelayToken = token[:]
+	}
 	connConfigCiphertext, err := encryptConnConfigMsg(connConfig, config.privateKey, config.peerPubKey)
 	if err != nil {
 		return fmt.Errorf("could not encrypt config msg: %v", err)
@@ -298,6 +323,76 @@ func (m *dialer) dialPinger(ctx context.Context, providerID identity.Identity, c
 	return conns[0], conns[1], nil
 }
 
+func (m *dialer) dialRelay(ctx context.Context, config *p2pConnectConfig) (*net.UDPConn, *net.UDPConn, error) {
+	trace := config.tracer.StartStage("Consumer P2P dial (relay)")
+	defer config.tracer.EndStage(trace)
+
+	host, _, err := net.SplitHostPort(config.relay)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid relay address: %w", err)
+	}
+	if _, err := firewall.AllowIPAccess(host); err != nil {
+		return nil, nil, fmt.Errorf("could not add relay IP firewall rule: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, relayDialTimeout)
+	defer cancel()
+	conns, err := relay.Dial(ctx, config.relay, config.relayToken, relay.RoleConsumer, requiredConnCount)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not dial relay: %w", err)
+	}
+	return conns[0], conns[1], nil
+}
+
+// roam sends consumer's new public IP and ports to provider, which continues the channel from them.
+func (m *dialer) roam(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, contactDef ContactDefinition, config *p2pConnectConfig, ports []int) error {
+	publicIP, err := m.ipResolver.GetPublicIP()
+	if err != nil {
+		return fmt.Errorf("could not get public IP: %w", err)
+	}
+
+	brokerConn, err := m.connect(contactDef, trace.NewTracer("Consumer P2P roam"))
+	if err != nil {
+		return fmt.Errorf("could not open broker conn: %w", err)
+	}
+	defer brokerConn.Close()
+
+	connConfig := &pb.P2PConnectConfig{
+		PublicIP: publicIP,
+		Ports:    intToInt32Slice(ports),
+	}
+	connConfigCiphertext, err := encryptConnConfigMsg(connConfig, config.privateKey, config.peerPubKey)
+	if err != nil {
+		return fmt.Errorf("could not encrypt config msg: %w", err)
+	}
+	roamMsg := &pb.P2PConfigExchangeMsg{
+		PublicKey:        config.publicKey.Hex(),
+		ConfigCiphertext: connConfigCiphertext,
+	}
+	log.Debug().Msgf("Consumer %s sending new address %s:%v to provider %s", consumerID.Address, publicIP, ports, providerID.Address)
+	packedMsg, err := packSignedMsg(m.signer, consumerID, roamMsg)
+	if err != nil {
+		return fmt.Errorf("could not pack signed message: %w", err)
+	}
+	reply, err := m.sendSignedMsg(ctx, channelRoamSubject(providerID, serviceType), packedMsg, brokerConn)
+	if err != nil {
+		return fmt.Errorf("could not send signed message: %w", err)
+	}
+
+	replySignedMsg, err := unpackSignedMsg(m.verifier, reply)
+	if err != nil {
+		return fmt.Errorf("could not unpack peer signed message: %w", err)
+	}
+	var replyMsg pb.P2PConfigExchangeMsg
+	if err := proto.Unmarshal(replySignedMsg.Data, &replyMsg); err != nil {
+		return fmt.Errorf("could not unmarshal peer signed message payload: %w", err)
+	}
+	if replyMsg.PublicKey != config.peerPubKey.Hex() {
+		return errors.New("roam confirmed by unknown peer")
+	}
+	return nil
+}
+
 func (m *dialer) sendSignedMsg(ctx context.Context, subject string, msg []byte, brokerConn nats.Connection) ([]byte, error) {
 	reply, err := brokerConn.RequestWithContext(ctx, subject, msg)
 	if err != nil {
@@ -18,7 +18,9 @@
 package p2p
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"net"
 	"net/url"
 	"testing"
@@ -30,12 +32,15 @@ import (
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/nat/mapping"
 	"github.com/mysteriumnetwork/node/nat/traversal"
+	"github.com/mysteriumnetwork/node/p2p/relay"
 	"github.com/mysteriumnetwork/node/trace"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestDialer_Exchange_And_Communication_With_Provider(t *testing.T) {
 	providerPinger, consumerPinger := natTestPingers(t)
+	relayServer := relayTestServer(t)
+	defer relayServer.Stop()
 
 	tests := []struct {
 		name              string
@@ -43,6 +48,7 @@ func TestDialer_Exchange_And_Communication_With_Provider(t *testing.T) {
 		natProviderPinger natProviderPinger
 		natConsumerPinger natConsumerPinger
 		portMapper        mapping.PortMapper
+		relays            []string
 	}{
 		{
 			name:              "Provider with public IP",
@@ -71,6 +77,14 @@ func TestDialer_Exchange_And_Communication_With_Provider(t *testing.T) {
 			natConsumerPinger: traversal.NewNoopPinger(),
 			portMapper:        &mockPortMapper{enabled: false},
 		},
+		{
+			name:              "Provider and consumer behind symmetric NAT with relay",
+			ipResolver:        ip.NewResolverMockMultiple("127.0.0.1", "1.1.1.1"),
+			natProviderPinger: &mockProviderNATPinger{err: errors.New("ping timeout")},
+			natConsumerPinger: &mockConsumerNATPinger{err: errors.New("ping timeout")},
+			portMapper:        &mockPortMapper{},
+			relays:            []string{relayServer.Addr().String()},
+		},
 	}
 
 	for _, test := range tests {
@@ -86,7 +100,7 @@ func TestDialer_Exchange_And_Communication_With_Provider(t *testing.T) {
 			portPool := port.NewPool()
 
 			// Provider starts listening.
-			channelListener := NewListener(brokerConn, signerFactory, verifier, test.ipResolver, test.natProviderPinger, portPool, test.portMapper)
+			channelListener := NewListener(brokerConn, signerFactory, verifier, test.ipResolver, test.natProviderPinger, portPool, test.portMapper, test.relays)
 			_, err := channelListener.Listen(providerID, "wireguard", func(ch Channel) {
 				ch.Handle("test", func(c Context) error {
 					return c.OkWithReply(&Message{Data: []byte("pong")})
@@ -109,6 +123,105 @@ func TestDialer_Exchange_And_Communication_With_Provider(t *testing.T) {
 	}
 }
 
+func TestDialer_ResumeChannel(t *testing.T) {
+	relayServer := relayTestServer(t)
+	defer relayServer.Stop()
+
+	tests := []struct {
+		name              string
+		ipResolver        ip.Resolver
+		natProviderPinger natProviderPinger
+		natConsumerPinger natConsumerPinger
+		relays            []string
+		expectedErr       error
+	}{
+		{
+			name:              "Direct channel follows consumer",
+			ipResolver:        ip.NewResolverMock("127.0.0.1"),
+			natProviderPinger: &mockProviderNATPinger{},
+			natConsumerPinger: &mockConsumerNATPinger{},
+		},
+		{
+			name:              "Relayed channel can not be resumed",
+			ipResolver:        ip.NewResolverMockMultiple("127.0.0.1", "1.1.1.1"),
+			natProviderPinger: &mockProviderNATPinger{err: errors.New("ping timeout")},
+			natConsumerPinger: &mockConsumerNATPinger{err: errors.New("ping timeout")},
+			relays:            []string{relayServer.Addr().String()},
+			expectedErr:       ErrResumeNotSupported,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			providerID := identity.FromAddress("0x1")
+			signerFactory := func(id identity.Identity) identity.Signer {
+				return &identity.SignerFake{}
+			}
+			verifier := &identity.VerifierFake{}
+			brokerConn := nats.StartConnectionMock()
+			defer brokerConn.Close()
+			mockBroker := &mockBroker{conn: brokerConn}
+			portPool := port.NewPool()
+
+			providerChannels := make(chan Channel, 1)
+			channelListener := NewListener(brokerConn, signerFactory, verifier, test.ipResolver, test.natProviderPinger, portPool, &mockPortMapper{}, test.relays)
+			_, err := channelListener.Listen(providerID, "wireguard", func(ch Channel) {
+				ch.Handle("test", func(c Context) error {
+					return c.OkWithReply(&Message{Data: []byte("pong")})
+				})
+				providerChannels <- ch
+			})
+			assert.NoError(t, err)
+
+			channelDialer := NewDialer(mockBroker, signerFactory, verifier, test.ipResolver, test.natConsumerPinger, portPool)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			consumerChannel, err := channelDialer.Dial(ctx, identity.FromAddress("0x2"), providerID, "wireguard", ContactDefinition{BrokerAddresses: []string{"broker"}}, trace.NewTracer("Dial"))
+			assert.NoError(t, err)
+			defer consumerChannel.Close()
+			providerChannel := <-providerChannels
+
+			err = consumerChannel.(ResumableChannel).Resume(ctx)
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			// Channel listens on all interfaces after resume and provider keeps sending to the same consumer port.
+			assert.True(t, consumerChannel.Conn().LocalAddr().(*net.UDPAddr).IP.IsUnspecified())
+			assert.Equal(t, consumerChannel.Conn().LocalAddr().(*net.UDPAddr).Port, providerChannel.(*channel).peer.addr().Port)
+
+			res, err := consumerChannel.Send(ctx, "test", &Message{Data: []byte("ping")})
+			assert.NoError(t, err)
+			assert.Equal(t, "pong", string(res.Data))
+
+			// Service conns keep working too.
+			_, err = consumerChannel.ServiceConn().Write([]byte("service ping"))
+			assert.NoError(t, err)
+			assert.Equal(t, "service ping", readServicePacket(t, providerChannel.ServiceConn()))
+			_, err = providerChannel.ServiceConn().Write([]byte("service pong"))
+			assert.NoError(t, err)
+			assert.Equal(t, "service pong", readServicePacket(t, consumerChannel.ServiceConn()))
+		})
+	}
+}
+
+// readServicePacket reads the packet from service conn skipping hole punching ones.
+func readServicePacket(t *testing.T, conn *net.UDPConn) string {
+	buf := make([]byte, 1024)
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	for {
+		n, err := conn.Read(buf)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		if !bytes.Equal(buf[:n], punchPayload) {
+			return string(buf[:n])
+		}
+	}
+}
+
 func natTestPingers(t *testing.T) (providerPinger natProviderPinger, consumerPinger natConsumerPinger) {
 	ports, err := acquirePorts(2)
 	assert.NoError(t, err)
@@ -123,20 +236,30 @@ func natTestPingers(t *testing.T) (providerPinger natProviderPinger, consumerPin
 	return
 }
 
+func relayTestServer(t *testing.T) *relay.Server {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	assert.NoError(t, err)
+	server := relay.NewServer(conn, relay.DefaultIdleTimeout, relay.DefaultLimits)
+	go server.Serve()
+	return server
+}
+
 type mockConsumerNATPinger struct {
 	conns []*net.UDPConn
+	err   error
 }
 
 func (m *mockConsumerNATPinger) PingProviderPeer(ctx context.Context, ip string, localPorts, remotePorts []int, initialTTL int, n int) (conns []*net.UDPConn, err error) {
-	return m.conns, nil
+	return m.conns, m.err
 }
 
 type mockProviderNATPinger struct {
 	conns []*net.UDPConn
+	err   error
 }
 
 func (m *mockProviderNATPinger) PingConsumerPeer(ctx context.Context, ip string, localPorts, remotePorts []int, initialTTL int, n int) (conns []*net.UDPConn, err error) {
-	return m.conns, nil
+	return m.conns, m.err
 }
 
 type mockBroker struct {
@@ -0,0 +1,30 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+// AppTopicKeepAliveFailed represents the topic of failed p2p keepalive pings.
+const AppTopicKeepAliveFailed = "p2p_keepalive_failed"
+
+// AppEventKeepAliveFailed represents the payload that is sent on the AppTopicKeepAliveFailed topic.
+type AppEventKeepAliveFailed struct {
+	SessionID string
+	// Consumer is true for the pings sent by consumer, false for the ones sent by provider.
+	Consumer bool
+	// Fatal is true when the max count of consecutive failures is reached and the channel is given up.
+	Fatal bool
+}
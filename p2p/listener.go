@@ -19,6 +19,7 @@ package p2p
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"sync"
@@ -31,6 +32,7 @@ import (
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/nat/mapping"
 	"github.com/mysteriumnetwork/node/nat/traversal"
+	"github.com/mysteriumnetwork/node/p2p/relay"
 	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/node/trace"
 
@@ -51,16 +53,19 @@ type Listener interface {
 }
 
 // NewListener creates new p2p communication listener which is used on provider side.
-func NewListener(brokerConn nats.Connection, signer identity.SignerFactory, verifier identity.Verifier, ipResolver ip.Resolver, providerPinger natProviderPinger, portPool port.ServicePortSupplier, portMapper mapping.PortMapper) Listener {
+// Relays are offered to the consumers as a fallback path when NAT hole punching fails.
+func NewListener(brokerConn nats.Connection, signer identity.SignerFactory, verifier identity.Verifier, ipResolver ip.Resolver, providerPinger natProviderPinger, portPool port.ServicePortSupplier, portMapper mapping.PortMapper, relays []string) Listener {
 	return &listener{
 		brokerConn:     brokerConn,
 		pendingConfigs: map[PublicKey]p2pConnectConfig{},
+		channels:       map[PublicKey]activeChannel{},
 		ipResolver:     ipResolver,
 		signer:         signer,
 		verifier:       verifier,
 		portPool:       portPool,
 		providerPinger: providerPinger,
 		portMapper:     portMapper,
+		relays:         relays,
 	}
 }
 
@@ -73,11 +78,21 @@ type listener struct {
 	verifier       identity.Verifier
 	ipResolver     ip.Resolver
 	portMapper     mapping.PortMapper
+	relays         []string
 
 	// Keys holds pendingConfigs temporary configs for provider side since it
 	// need to handle key exchange in two steps.
 	pendingConfigs   map[PublicKey]p2pConnectConfig
 	pendingConfigsMu sync.Mutex
+
+	// channels holds established channels by consumer keys, so consumers can move them to other addresses.
+	channels   map[PublicKey]activeChannel
+	channelsMu sync.Mutex
+}
+
+type activeChannel struct {
+	channel *channel
+	config  p2pConnectConfig
 }
 
 type p2pConnectConfig struct {
@@ -90,6 +105,11 @@ type p2pConnectConfig struct {
 	peerPubKey       PublicKey
 	tracer           *trace.Tracer
 	upnpPortsRelease []func()
+	// peerRelays are relays offered by provider.
+	peerRelays []string
+	// relay is the relay chosen by consumer for the fallback path, if any.
+	relay      string
+	relayToken relay.Token
 }
 
 func (c *p2pConnectConfig) peerIP() string {
@@ -151,6 +171,7 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		}(msg.Reply)
 
 		var conn1, conn2 *net.UDPConn
+		relayed := false
 		if len(config.peerPorts) == requiredConnCount {
 			traceDial := config.tracer.StartStage("Provider P2P dial (upnp)")
 			log.Debug().Msg("Skipping consumer ping")
@@ -170,6 +191,11 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 			log.Debug().Msgf("Pinging consumer with IP %s using ports %v:%v initial ttl: %v",
 				config.peerIP(), config.localPorts, config.peerPorts, providerInitialTTL)
 			conns, err := m.providerPinger.PingConsumerPeer(context.Background(), config.peerIP(), config.localPorts, config.peerPorts, providerInitialTTL, requiredConnCount)
+			if err != nil && config.relay != "" {
+				log.Warn().Err(err).Msgf("Could not ping peer, falling back to relay %s", config.relay)
+				conns, err = m.dialRelay(config)
+				relayed = true
+			}
 			if err != nil {
 				log.Err(err).Msg("Could not ping peer")
 				return
@@ -192,6 +218,10 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		channelHandlers(channel)
 
 		channel.launchReadSendLoops()
+		// Relay knows peers by their addresses, so relayed channel can not follow the consumer.
+		if !relayed {
+			m.addChannel(channel, *config)
+		}
 
 		// Send handlers ready to consumer.
 		if err := m.providerChannelHandlersReady(providerID, serviceType); err != nil {
@@ -209,6 +239,22 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		return func() {}, fmt.Errorf("could not get subscribe to config exchange acknowledge topic: %w", err)
 	}
 
+	roamSub, err := m.brokerConn.Subscribe(channelRoamSubject(providerID, serviceType), func(msg *nats_lib.Msg) {
+		if err := m.providerRoam(providerID, msg); err != nil {
+			log.Err(err).Msg("Could not handle channel roam")
+			return
+		}
+	})
+	if err != nil {
+		if err := configSub.Unsubscribe(); err != nil {
+			log.Err(err).Msg("Failed to unsubscribe from config exchange topic")
+		}
+		if err := ackSub.Unsubscribe(); err != nil {
+			log.Err(err).Msg("Failed to unsubscribe from config exchange acknowledge topic")
+		}
+		return func() {}, fmt.Errorf("could not get subscribe to channel roam topic: %w", err)
+	}
+
 	return func() {
 		if err := configSub.Unsubscribe(); err != nil {
 			log.Err(err).Msg("Failed to unsubscribe from config exchange topic")
@@ -216,6 +262,9 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		if err := ackSub.Unsubscribe(); err != nil {
 			log.Err(err).Msg("Failed to unsubscribe from config exchange acknowledge topic")
 		}
+		if err := roamSub.Unsubscribe(); err != nil {
+			log.Err(err).Msg("Failed to unsubscribe from channel roam topic")
+		}
 	}, nil
 }
 
@@ -265,6 +314,7 @@ func (m *listener) providerStartConfigExchange(signerID identity.Identity, msg *
 	config := pb.P2PConnectConfig{
 		PublicIP: publicIP,
 		Ports:    intToInt32Slice(localPorts),
+		Relays:   m.relays,
 	}
 	configCiphertext, err := encryptConnConfigMsg(&config, privateKey, peerPubKey)
 	if err != nil {
@@ -369,6 +419,11 @@ func (m *listener) providerAckConfigExchange(msg *nats_lib.Msg) (*p2pConnectConf
 
 	log.Debug().Msgf("Decrypted consumer config: %v", peerConfig)
 
+	chosenRelay, relayToken, err := m.chosenRelay(peerConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	return &p2pConnectConfig{
 		peerPublicIP:     peerConfig.PublicIP,
 		peerPorts:        int32ToIntSlice(peerConfig.Ports),
@@ -379,9 +434,83 @@ func (m *listener) providerAckConfigExchange(msg *nats_lib.Msg) (*p2pConnectConf
 		publicIP:         config.publicIP,
 		tracer:           config.tracer,
 		upnpPortsRelease: config.upnpPortsRelease,
+		relay:            chosenRelay,
+		relayToken:       relayToken,
 	}, nil
 }
 
+// chosenRelay returns the relay chosen by consumer, it must be one of the offered relays.
+func (m *listener) chosenRelay(peerConfig *pb.P2PConnectConfig) (string, relay.Token, error) {
+	if len(peerConfig.Relays) == 0 {
+		return "", relay.Token{}, nil
+	}
+	for _, offered := range m.relays {
+		if offered != peerConfig.Relays[0] {
+			continue
+		}
+		token, err := relay.TokenFromBytes(peerConfig.RelayToken)
+		if err != nil {
+			return "", relay.Token{}, err
+		}
+		return offered, token, nil
+	}
+	return "", relay.Token{}, fmt.Errorf("relay %s was not offered", peerConfig.Relays[0])
+}
+
+func (m *listener) dialRelay(config *p2pConnectConfig) ([]*net.UDPConn, error) {
+	trace := config.tracer.StartStage("Provider P2P dial (relay)")
+	defer config.tracer.EndStage(trace)
+
+	ctx, cancel := context.WithTimeout(context.Background(), relayDialTimeout)
+	defer cancel()
+	return relay.Dial(ctx, config.relay, config.relayToken, relay.RoleProvider, requiredConnCount)
+}
+
+// providerRoam moves the channel and its service conn to the new consumer address and punches holes towards it.
+func (m *listener) providerRoam(signerID identity.Identity, msg *nats_lib.Msg) error {
+	signedMsg, err := unpackSignedMsg(m.verifier, msg.Data)
+	if err != nil {
+		return fmt.Errorf("could not unpack signed msg: %w", err)
+	}
+	var peerRoamMsg pb.P2PConfigExchangeMsg
+	if err := proto.Unmarshal(signedMsg.Data, &peerRoamMsg); err != nil {
+		return fmt.Errorf("could not unmarshal roam msg: %w", err)
+	}
+	peerPubKey, err := DecodePublicKey(peerRoamMsg.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	active, ok := m.activeChannel(peerPubKey)
+	if !ok {
+		return fmt.Errorf("channel not found for key %s", peerPubKey.Hex())
+	}
+
+	// Only the consumer holding the channel keys is able to encrypt the config.
+	peerConfig, err := decryptConnConfigMsg(peerRoamMsg.ConfigCiphertext, active.config.privateKey, peerPubKey)
+	if err != nil {
+		return fmt.Errorf("could not decrypt peer conn config: %w", err)
+	}
+	if len(peerConfig.Ports) == 0 {
+		return errors.New("peer conn config has no ports")
+	}
+
+	config := active.config
+	config.peerPublicIP = peerConfig.PublicIP
+	config.peerPorts = int32ToIntSlice(peerConfig.Ports)
+	active.channel.moveTo(&net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[0]})
+	if len(config.peerPorts) > 1 {
+		active.channel.moveServiceTo(&net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[1]})
+	}
+
+	replyMsg := &pb.P2PConfigExchangeMsg{PublicKey: config.publicKey.Hex()}
+	packedMsg, err := packSignedMsg(m.signer, signerID, replyMsg)
+	if err != nil {
+		return fmt.Errorf("could not pack signed message: %w", err)
+	}
+	return m.brokerConn.Publish(msg.Reply, packedMsg)
+}
+
 func (m *listener) providerChannelHandlersReady(providerID identity.Identity, serviceType string) error {
 	handlersReadyMsg := pb.P2PChannelHandlersReady{Value: "HANDLERS READY"}
 
@@ -412,3 +541,25 @@ func (m *listener) deletePendingConfig(peerPubKey PublicKey) {
 	defer m.pendingConfigsMu.Unlock()
 	delete(m.pendingConfigs, peerPubKey)
 }
+
+func (m *listener) activeChannel(peerPubKey PublicKey) (activeChannel, bool) {
+	m.channelsMu.Lock()
+	defer m.channelsMu.Unlock()
+	active, ok := m.channels[peerPubKey]
+	return active, ok
+}
+
+// addChannel keeps the channel until it is closed.
+func (m *listener) addChannel(ch *channel, config p2pConnectConfig) {
+	m.channelsMu.Lock()
+	defer m.channelsMu.Unlock()
+	m.channels[config.peerPubKey] = activeChannel{channel: ch, config: config}
+
+	go func() {
+		<-ch.stop
+
+		m.channelsMu.Lock()
+		defer m.channelsMu.Unlock()
+		delete(m.channels, config.peerPubKey)
+	}()
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// registerInterval is the interval of registration retries, until the peer registers too.
+const registerInterval = 250 * time.Millisecond
+
+// Dial registers given count of connections with the relay and waits until the peer with the opposite role
+// registers its connections with the same token. Returned connections send packets to the relay, which
+// forwards them to the peer.
+func Dial(ctx context.Context, relayAddr string, token Token, role Role, count int) ([]*net.UDPConn, error) {
+	addr, err := net.ResolveUDPAddr("udp4", relayAddr)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve relay address: %w", err)
+	}
+
+	var conns []*net.UDPConn
+	for slot := 0; slot < count; slot++ {
+		conn, err := net.DialUDP("udp4", nil, addr)
+		if err == nil {
+			err = register(ctx, conn, controlMsg{kind: msgRegister, role: role, slot: byte(slot), token: token})
+			if err != nil {
+				conn.Close()
+			}
+		}
+		if err != nil {
+			for _, c := range conns {
+				c.Close()
+			}
+			return nil, fmt.Errorf("could not register with relay %s: %w", relayAddr, err)
+		}
+		conns = append(conns, conn)
+	}
+	return conns, nil
+}
+
+func register(ctx context.Context, conn *net.UDPConn, msg controlMsg) error {
+	// Reset deadline for the connection to be used by the caller.
+	defer conn.SetReadDeadline(time.Time{})
+
+	buf := make([]byte, controlMsgSize+1)
+	for {
+		if _, err := conn.Write(msg.marshal()); err != nil {
+			return err
+		}
+
+		deadline := time.Now().Add(registerInterval)
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := conn.SetReadDeadline(deadline); err != nil {
+				return err
+			}
+			n, err := conn.Read(buf)
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			reply, ok := parseControlMsg(buf[:n])
+			if ok && reply.kind == msgReady && reply.slot == msg.slot && reply.token == msg.token {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relay
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+)
+
+// Role is the side of p2p connection registering with the relay.
+type Role byte
+
+const (
+	// RoleConsumer is the role of the dialing peer.
+	RoleConsumer Role = 1
+	// RoleProvider is the role of the listening peer.
+	RoleProvider Role = 2
+)
+
+// peer returns the role of the other side of the connection.
+func (r Role) peer() Role {
+	if r == RoleConsumer {
+		return RoleProvider
+	}
+	return RoleConsumer
+}
+
+func (r Role) valid() bool {
+	return r == RoleConsumer || r == RoleProvider
+}
+
+// TokenSize is the size of the token in bytes.
+const TokenSize = 16
+
+// Token identifies a pair of peers at the relay. It is exchanged by peers in the encrypted p2p config,
+// so nobody else can bind to their connections.
+type Token [TokenSize]byte
+
+// NewToken generates a random token.
+func NewToken() (Token, error) {
+	var t Token
+	if _, err := rand.Read(t[:]); err != nil {
+		return t, fmt.Errorf("could not generate relay token: %w", err)
+	}
+	return t, nil
+}
+
+// TokenFromBytes creates a token from its bytes.
+func TokenFromBytes(b []byte) (Token, error) {
+	var t Token
+	if len(b) != TokenSize {
+		return t, fmt.Errorf("invalid relay token size %d", len(b))
+	}
+	copy(t[:], b)
+	return t, nil
+}
+
+type msgType byte
+
+const (
+	msgRegister msgType = 1
+	msgReady    msgType = 2
+)
+
+// magic prefixes the control messages, which are never forwarded to the peers. It does not collide with
+// the encrypted p2p channel packets and WireGuard messages starting with the message type and zero bytes.
+var magic = []byte("MYSTRELAY")
+
+const controlMsgSize = 9 + 3 + TokenSize
+
+// controlMsg is a message between the peer and the relay, either registering the peer's connection or
+// confirming that both peers of the connection are registered.
+type controlMsg struct {
+	kind  msgType
+	role  Role
+	slot  byte
+	token Token
+}
+
+func (m controlMsg) marshal() []byte {
+	b := make([]byte, 0, controlMsgSize)
+	b = append(b, magic...)
+	b = append(b, byte(m.kind), byte(m.role), m.slot)
+	return append(b, m.token[:]...)
+}
+
+// parseControlMsg parses the control message, reporting false for any other packet.
+func parseControlMsg(b []byte) (controlMsg, bool) {
+	if len(b) != controlMsgSize || !bytes.HasPrefix(b, magic) {
+		return controlMsg{}, false
+	}
+	b = b[len(magic):]
+	m := controlMsg{kind: msgType(b[0]), role: Role(b[1]), slot: b[2]}
+	copy(m.token[:], b[3:])
+	return m, m.role.valid()
+}
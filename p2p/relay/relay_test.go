@@ -0,0 +1,213 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relay
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelay_ForwardsPacketsBetweenPeers(t *testing.T) {
+	server := startServer(t, DefaultIdleTimeout)
+	defer server.Stop()
+
+	token, err := NewToken()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	providerConns := make(chan []*net.UDPConn)
+	go func() {
+		conns, err := Dial(ctx, server.Addr().String(), token, RoleProvider, 2)
+		assert.NoError(t, err)
+		providerConns <- conns
+	}()
+	consumer, err := Dial(ctx, server.Addr().String(), token, RoleConsumer, 2)
+	require.NoError(t, err)
+	provider := <-providerConns
+	require.Len(t, provider, 2)
+
+	for i := range consumer {
+		_, err = consumer[i].Write([]byte("ping"))
+		require.NoError(t, err)
+		assert.Equal(t, "ping", read(t, provider[i]))
+
+		_, err = provider[i].Write([]byte("pong"))
+		require.NoError(t, err)
+		assert.Equal(t, "pong", read(t, consumer[i]))
+	}
+}
+
+func TestRelay_DoesNotForwardToStrangers(t *testing.T) {
+	server := startServer(t, DefaultIdleTimeout)
+	defer server.Stop()
+
+	stranger, err := net.DialUDP("udp4", nil, server.Addr().(*net.UDPAddr))
+	require.NoError(t, err)
+	defer stranger.Close()
+
+	_, err = stranger.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	stranger.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	_, err = stranger.Read(make([]byte, 10))
+	assert.Error(t, err)
+}
+
+func TestRelay_DialTimesOutWithoutPeer(t *testing.T) {
+	server := startServer(t, DefaultIdleTimeout)
+	defer server.Stop()
+
+	token, err := NewToken()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	_, err = Dial(ctx, server.Addr().String(), token, RoleConsumer, 1)
+	assert.Error(t, err)
+}
+
+func TestRelay_ExpiresIdleBindings(t *testing.T) {
+	server := startServer(t, 50*time.Millisecond)
+	defer server.Stop()
+
+	token, err := NewToken()
+	require.NoError(t, err)
+	conn, err := net.DialUDP("udp4", nil, server.Addr().(*net.UDPAddr))
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write(controlMsg{kind: msgRegister, role: RoleConsumer, token: token}.marshal())
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return len(server.bindings) == 0 && len(server.routes) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestRelay_LimitsBindingsPerIP(t *testing.T) {
+	server := startServerWithLimits(t, DefaultIdleTimeout, Limits{MaxBindings: 10, MaxBindingsPerIP: 2, RegistrationsPerSecond: 100})
+	defer server.Stop()
+
+	registerTokens(t, server, 3)
+
+	assert.Eventually(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return registrationsProcessed(server, 3) && len(server.bindings) == 2 && server.ipBindings["127.0.0.1"] == 2
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRelay_LimitsBindingsInTotal(t *testing.T) {
+	server := startServerWithLimits(t, DefaultIdleTimeout, Limits{MaxBindings: 1, MaxBindingsPerIP: 10, RegistrationsPerSecond: 100})
+	defer server.Stop()
+
+	registerTokens(t, server, 2)
+
+	assert.Eventually(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return registrationsProcessed(server, 2) && len(server.bindings) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRelay_LimitsRegistrationRate(t *testing.T) {
+	server := startServerWithLimits(t, DefaultIdleTimeout, Limits{MaxBindings: 10, MaxBindingsPerIP: 10, RegistrationsPerSecond: 1})
+	defer server.Stop()
+
+	registerTokens(t, server, 3)
+
+	assert.Eventually(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return registrationsProcessed(server, 3) && len(server.bindings) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRelay_ExpiredBindingsReleaseIPLimit(t *testing.T) {
+	server := startServerWithLimits(t, 50*time.Millisecond, Limits{MaxBindings: 10, MaxBindingsPerIP: 1, RegistrationsPerSecond: 100})
+	defer server.Stop()
+
+	registerTokens(t, server, 1)
+	assert.Eventually(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return len(server.bindings) == 0 && len(server.ipBindings) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestParseControlMsg(t *testing.T) {
+	token, err := NewToken()
+	require.NoError(t, err)
+	msg := controlMsg{kind: msgReady, role: RoleProvider, slot: 1, token: token}
+
+	parsed, ok := parseControlMsg(msg.marshal())
+	assert.True(t, ok)
+	assert.Equal(t, msg, parsed)
+
+	_, ok = parseControlMsg([]byte("MYSTRELAY"))
+	assert.False(t, ok)
+	_, ok = parseControlMsg(append([]byte{4, 0, 0, 0}, make([]byte, controlMsgSize-4)...))
+	assert.False(t, ok)
+}
+
+func startServer(t *testing.T, idleTimeout time.Duration) *Server {
+	return startServerWithLimits(t, idleTimeout, DefaultLimits)
+}
+
+func startServerWithLimits(t *testing.T, idleTimeout time.Duration, limits Limits) *Server {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	server := NewServer(conn, idleTimeout, limits)
+	go server.Serve()
+	return server
+}
+
+// registerTokens sends registrations of the given count of new tokens from a single connection.
+func registerTokens(t *testing.T, server *Server, count int) {
+	conn, err := net.DialUDP("udp4", nil, server.Addr().(*net.UDPAddr))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	for i := 0; i < count; i++ {
+		token, err := NewToken()
+		require.NoError(t, err)
+		_, err = conn.Write(controlMsg{kind: msgRegister, role: RoleConsumer, token: token}.marshal())
+		require.NoError(t, err)
+	}
+}
+
+// registrationsProcessed reports whether the server has seen the given count of registrations from localhost.
+func registrationsProcessed(server *Server, count int) bool {
+	w, ok := server.registrations["127.0.0.1"]
+	return ok && w.count == count
+}
+
+func read(t *testing.T, conn *net.UDPConn) string {
+	buf := make([]byte, 100)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	return string(buf[:n])
+}
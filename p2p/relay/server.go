@@ -0,0 +1,255 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relay
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultIdleTimeout is the time after which the connection without any traffic is forgotten by the relay.
+const DefaultIdleTimeout = 3 * time.Minute
+
+// Limits bound the resources a relay spends on the registering peers.
+type Limits struct {
+	// MaxBindings is the total number of bindings the relay keeps.
+	MaxBindings int
+	// MaxBindingsPerIP is the number of bindings a single source IP can create.
+	MaxBindingsPerIP int
+	// RegistrationsPerSecond is the number of register messages accepted from a single source IP per second.
+	RegistrationsPerSecond int
+}
+
+// DefaultLimits are the relay limits suitable for a public relay.
+var DefaultLimits = Limits{
+	MaxBindings:            100000,
+	MaxBindingsPerIP:       64,
+	RegistrationsPerSecond: 20,
+}
+
+// Server is a UDP relay forwarding packets between the peers registered with the same token.
+// Packets are forwarded as is, they are end-to-end encrypted by the peers.
+type Server struct {
+	conn        *net.UDPConn
+	idleTimeout time.Duration
+	limits      Limits
+
+	mu            sync.Mutex
+	bindings      map[bindingKey]*binding
+	routes        map[string]route
+	ipBindings    map[string]int
+	registrations map[string]*registrationWindow
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// bindingKey identifies a single connection of the peers, since a p2p channel consists of several connections.
+type bindingKey struct {
+	token Token
+	slot  byte
+}
+
+type binding struct {
+	key      bindingKey
+	addrs    map[Role]*net.UDPAddr
+	owner    string
+	lastSeen time.Time
+}
+
+// registrationWindow counts the register messages of a source IP during the current second.
+type registrationWindow struct {
+	start time.Time
+	count int
+}
+
+// route is the binding and the role of the peer sending from the address.
+type route struct {
+	binding *binding
+	role    Role
+}
+
+// NewServer creates a new relay server serving on the given connection.
+func NewServer(conn *net.UDPConn, idleTimeout time.Duration, limits Limits) *Server {
+	return &Server{
+		conn:          conn,
+		idleTimeout:   idleTimeout,
+		limits:        limits,
+		bindings:      make(map[bindingKey]*binding),
+		routes:        make(map[string]route),
+		ipBindings:    make(map[string]int),
+		registrations: make(map[string]*registrationWindow),
+		stop:          make(chan struct{}),
+	}
+}
+
+// Addr returns the address the relay is serving on.
+func (s *Server) Addr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+// Serve forwards packets until the server is stopped.
+func (s *Server) Serve() error {
+	go s.expireIdle()
+
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-s.stop:
+				return nil
+			default:
+				return err
+			}
+		}
+
+		if msg, ok := parseControlMsg(buf[:n]); ok {
+			if msg.kind == msgRegister {
+				s.register(addr, msg)
+			}
+			continue
+		}
+		s.forward(addr, buf[:n])
+	}
+}
+
+// Stop stops the server and closes its connection.
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		if err := s.conn.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to close relay connection")
+		}
+	})
+}
+
+func (s *Server) register(addr *net.UDPAddr, msg controlMsg) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ip := addr.IP.String()
+	if !s.allowRegistration(ip, time.Now()) {
+		log.Debug().Msgf("Relay registration rate exceeded by %s", ip)
+		return
+	}
+
+	key := bindingKey{token: msg.token, slot: msg.slot}
+	b, ok := s.bindings[key]
+	if !ok {
+		if len(s.bindings) >= s.limits.MaxBindings {
+			log.Warn().Msgf("Relay bindings limit reached, dropping registration from %s", addr)
+			return
+		}
+		if s.ipBindings[ip] >= s.limits.MaxBindingsPerIP {
+			log.Debug().Msgf("Relay bindings limit reached by %s", ip)
+			return
+		}
+		b = &binding{key: key, addrs: make(map[Role]*net.UDPAddr), owner: ip}
+		s.bindings[key] = b
+		s.ipBindings[ip]++
+	}
+	// Peer address may change if it registers again after NAT mapping expired.
+	if old, ok := b.addrs[msg.role]; ok && old.String() != addr.String() {
+		delete(s.routes, old.String())
+	}
+	b.addrs[msg.role] = addr
+	b.lastSeen = time.Now()
+	s.routes[addr.String()] = route{binding: b, role: msg.role}
+
+	peerAddr, ok := b.addrs[msg.role.peer()]
+	if !ok {
+		return
+	}
+	log.Debug().Msgf("Relay peers paired: %s <-> %s", addr, peerAddr)
+	s.send(addr, controlMsg{kind: msgReady, role: msg.role, slot: msg.slot, token: msg.token})
+	s.send(peerAddr, controlMsg{kind: msgReady, role: msg.role.peer(), slot: msg.slot, token: msg.token})
+}
+
+// allowRegistration counts the register message of the IP and reports whether it fits into the rate limit.
+func (s *Server) allowRegistration(ip string, now time.Time) bool {
+	w, ok := s.registrations[ip]
+	if !ok || now.Sub(w.start) >= time.Second {
+		w = &registrationWindow{start: now}
+		s.registrations[ip] = w
+	}
+	w.count++
+	return w.count <= s.limits.RegistrationsPerSecond
+}
+
+func (s *Server) forward(addr *net.UDPAddr, packet []byte) {
+	s.mu.Lock()
+	r, ok := s.routes[addr.String()]
+	var peerAddr *net.UDPAddr
+	if ok {
+		peerAddr = r.binding.addrs[r.role.peer()]
+		r.binding.lastSeen = time.Now()
+	}
+	s.mu.Unlock()
+
+	if peerAddr == nil {
+		return
+	}
+	if _, err := s.conn.WriteToUDP(packet, peerAddr); err != nil {
+		log.Debug().Err(err).Msgf("Failed to forward packet to %s", peerAddr)
+	}
+}
+
+func (s *Server) send(addr *net.UDPAddr, msg controlMsg) {
+	if _, err := s.conn.WriteToUDP(msg.marshal(), addr); err != nil {
+		log.Debug().Err(err).Msgf("Failed to send relay message to %s", addr)
+	}
+}
+
+func (s *Server) expireIdle() {
+	ticker := time.NewTicker(s.idleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+
+		s.mu.Lock()
+		for key, b := range s.bindings {
+			if time.Since(b.lastSeen) < s.idleTimeout {
+				continue
+			}
+			for _, addr := range b.addrs {
+				delete(s.routes, addr.String())
+			}
+			delete(s.bindings, key)
+			s.ipBindings[b.owner]--
+			if s.ipBindings[b.owner] <= 0 {
+				delete(s.ipBindings, b.owner)
+			}
+		}
+		now := time.Now()
+		for ip, w := range s.registrations {
+			if now.Sub(w.start) >= time.Second {
+				delete(s.registrations, ip)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,512 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: pb/control.proto
+
+package pbconnect
+
+import (
+	context "context"
+	errors "errors"
+	connect_go "github.com/bufbuild/connect-go"
+	pb "github.com/mysteriumnetwork/node/pb"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect_go.IsAtLeastVersion0_1_0
+
+const (
+	// ControlServiceName is the fully-qualified name of the ControlService service.
+	ControlServiceName = "pb.ControlService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// ControlServiceListIdentitiesProcedure is the fully-qualified name of the ControlService's
+	// ListIdentities RPC.
+	ControlServiceListIdentitiesProcedure = "/pb.ControlService/ListIdentities"
+	// ControlServiceCurrentIdentityProcedure is the fully-qualified name of the ControlService's
+	// CurrentIdentity RPC.
+	ControlServiceCurrentIdentityProcedure = "/pb.ControlService/CurrentIdentity"
+	// ControlServiceUnlockIdentityProcedure is the fully-qualified name of the ControlService's
+	// UnlockIdentity RPC.
+	ControlServiceUnlockIdentityProcedure = "/pb.ControlService/UnlockIdentity"
+	// ControlServiceConnectProcedure is the fully-qualified name of the ControlService's Connect RPC.
+	ControlServiceConnectProcedure = "/pb.ControlService/Connect"
+	// ControlServiceDisconnectProcedure is the fully-qualified name of the ControlService's Disconnect
+	// RPC.
+	ControlServiceDisconnectProcedure = "/pb.ControlService/Disconnect"
+	// ControlServiceGetConnectionProcedure is the fully-qualified name of the ControlService's
+	// GetConnection RPC.
+	ControlServiceGetConnectionProcedure = "/pb.ControlService/GetConnection"
+	// ControlServiceWatchConnectionProcedure is the fully-qualified name of the ControlService's
+	// WatchConnection RPC.
+	ControlServiceWatchConnectionProcedure = "/pb.ControlService/WatchConnection"
+	// ControlServiceListServicesProcedure is the fully-qualified name of the ControlService's
+	// ListServices RPC.
+	ControlServiceListServicesProcedure = "/pb.ControlService/ListServices"
+	// ControlServiceStartServiceProcedure is the fully-qualified name of the ControlService's
+	// StartService RPC.
+	ControlServiceStartServiceProcedure = "/pb.ControlService/StartService"
+	// ControlServiceStopServiceProcedure is the fully-qualified name of the ControlService's
+	// StopService RPC.
+	ControlServiceStopServiceProcedure = "/pb.ControlService/StopService"
+	// ControlServiceListSessionsProcedure is the fully-qualified name of the ControlService's
+	// ListSessions RPC.
+	ControlServiceListSessionsProcedure = "/pb.ControlService/ListSessions"
+	// ControlServiceListProposalsProcedure is the fully-qualified name of the ControlService's
+	// ListProposals RPC.
+	ControlServiceListProposalsProcedure = "/pb.ControlService/ListProposals"
+	// ControlServiceGetPayoutInfoProcedure is the fully-qualified name of the ControlService's
+	// GetPayoutInfo RPC.
+	ControlServiceGetPayoutInfoProcedure = "/pb.ControlService/GetPayoutInfo"
+	// ControlServiceUpdatePayoutInfoProcedure is the fully-qualified name of the ControlService's
+	// UpdatePayoutInfo RPC.
+	ControlServiceUpdatePayoutInfoProcedure = "/pb.ControlService/UpdatePayoutInfo"
+	// ControlServiceWatchStateProcedure is the fully-qualified name of the ControlService's WatchState
+	// RPC.
+	ControlServiceWatchStateProcedure = "/pb.ControlService/WatchState"
+)
+
+// ControlServiceClient is a client for the pb.ControlService service.
+type ControlServiceClient interface {
+	// ListIdentities lists identities stored in the keystore.
+	ListIdentities(context.Context, *connect_go.Request[pb.ListIdentitiesRequest]) (*connect_go.Response[pb.ListIdentitiesResponse], error)
+	// CurrentIdentity selects the identity used by the node, creating a new one if there are none.
+	CurrentIdentity(context.Context, *connect_go.Request[pb.CurrentIdentityRequest]) (*connect_go.Response[pb.Identity], error)
+	// UnlockIdentity unlocks the identity with the given passphrase.
+	UnlockIdentity(context.Context, *connect_go.Request[pb.UnlockIdentityRequest]) (*connect_go.Response[pb.UnlockIdentityResponse], error)
+	// Connect creates a consumer connection to the provider.
+	Connect(context.Context, *connect_go.Request[pb.ConnectRequest]) (*connect_go.Response[pb.ConnectionStatus], error)
+	// Disconnect closes the consumer connection.
+	Disconnect(context.Context, *connect_go.Request[pb.DisconnectRequest]) (*connect_go.Response[pb.DisconnectResponse], error)
+	// GetConnection returns the status of the consumer connection.
+	GetConnection(context.Context, *connect_go.Request[pb.GetConnectionRequest]) (*connect_go.Response[pb.ConnectionStatus], error)
+	// WatchConnection streams the status of the consumer connection, starting with the current one.
+	WatchConnection(context.Context, *connect_go.Request[pb.WatchConnectionRequest]) (*connect_go.ServerStreamForClient[pb.ConnectionStatus], error)
+	// ListServices lists services running on the node.
+	ListServices(context.Context, *connect_go.Request[pb.ListServicesRequest]) (*connect_go.Response[pb.ListServicesResponse], error)
+	// StartService starts the provider service.
+	StartService(context.Context, *connect_go.Request[pb.StartServiceRequest]) (*connect_go.Response[pb.Service], error)
+	// StopService stops the provider service.
+	StopService(context.Context, *connect_go.Request[pb.StopServiceRequest]) (*connect_go.Response[pb.StopServiceResponse], error)
+	// ListSessions lists the history of consumer and provider sessions.
+	ListSessions(context.Context, *connect_go.Request[pb.ListSessionsRequest]) (*connect_go.Response[pb.ListSessionsResponse], error)
+	// ListProposals lists proposals of the providers.
+	ListProposals(context.Context, *connect_go.Request[pb.ListProposalsRequest]) (*connect_go.Response[pb.ListProposalsResponse], error)
+	// GetPayoutInfo returns the payout info of the identity.
+	GetPayoutInfo(context.Context, *connect_go.Request[pb.GetPayoutInfoRequest]) (*connect_go.Response[pb.PayoutInfo], error)
+	// UpdatePayoutInfo sets the payout address of the identity.
+	UpdatePayoutInfo(context.Context, *connect_go.Request[pb.UpdatePayoutInfoRequest]) (*connect_go.Response[pb.UpdatePayoutInfoResponse], error)
+	// WatchState streams the state of the node, starting with the current one.
+	WatchState(context.Context, *connect_go.Request[pb.WatchStateRequest]) (*connect_go.ServerStreamForClient[pb.NodeState], error)
+}
+
+// NewControlServiceClient constructs a client for the pb.ControlService service. By default, it
+// uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewControlServiceClient(httpClient connect_go.HTTPClient, baseURL string, opts ...connect_go.ClientOption) ControlServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &controlServiceClient{
+		listIdentities: connect_go.NewClient[pb.ListIdentitiesRequest, pb.ListIdentitiesResponse](
+			httpClient,
+			baseURL+ControlServiceListIdentitiesProcedure,
+			opts...,
+		),
+		currentIdentity: connect_go.NewClient[pb.CurrentIdentityRequest, pb.Identity](
+			httpClient,
+			baseURL+ControlServiceCurrentIdentityProcedure,
+			opts...,
+		),
+		unlockIdentity: connect_go.NewClient[pb.UnlockIdentityRequest, pb.UnlockIdentityResponse](
+			httpClient,
+			baseURL+ControlServiceUnlockIdentityProcedure,
+			opts...,
+		),
+		connect: connect_go.NewClient[pb.ConnectRequest, pb.ConnectionStatus](
+			httpClient,
+			baseURL+ControlServiceConnectProcedure,
+			opts...,
+		),
+		disconnect: connect_go.NewClient[pb.DisconnectRequest, pb.DisconnectResponse](
+			httpClient,
+			baseURL+ControlServiceDisconnectProcedure,
+			opts...,
+		),
+		getConnection: connect_go.NewClient[pb.GetConnectionRequest, pb.ConnectionStatus](
+			httpClient,
+			baseURL+ControlServiceGetConnectionProcedure,
+			opts...,
+		),
+		watchConnection: connect_go.NewClient[pb.WatchConnectionRequest, pb.ConnectionStatus](
+			httpClient,
+			baseURL+ControlServiceWatchConnectionProcedure,
+			opts...,
+		),
+		listServices: connect_go.NewClient[pb.ListServicesRequest, pb.ListServicesResponse](
+			httpClient,
+			baseURL+ControlServiceListServicesProcedure,
+			opts...,
+		),
+		startService: connect_go.NewClient[pb.StartServiceRequest, pb.Service](
+			httpClient,
+			baseURL+ControlServiceStartServiceProcedure,
+			opts...,
+		),
+		stopService: connect_go.NewClient[pb.StopServiceRequest, pb.StopServiceResponse](
+			httpClient,
+			baseURL+ControlServiceStopServiceProcedure,
+			opts...,
+		),
+		listSessions: connect_go.NewClient[pb.ListSessionsRequest, pb.ListSessionsResponse](
+			httpClient,
+			baseURL+ControlServiceListSessionsProcedure,
+			opts...,
+		),
+		listProposals: connect_go.NewClient[pb.ListProposalsRequest, pb.ListProposalsResponse](
+			httpClient,
+			baseURL+ControlServiceListProposalsProcedure,
+			opts...,
+		),
+		getPayoutInfo: connect_go.NewClient[pb.GetPayoutInfoRequest, pb.PayoutInfo](
+			httpClient,
+			baseURL+ControlServiceGetPayoutInfoProcedure,
+			opts...,
+		),
+		updatePayoutInfo: connect_go.NewClient[pb.UpdatePayoutInfoRequest, pb.UpdatePayoutInfoResponse](
+			httpClient,
+			baseURL+ControlServiceUpdatePayoutInfoProcedure,
+			opts...,
+		),
+		watchState: connect_go.NewClient[pb.WatchStateRequest, pb.NodeState](
+			httpClient,
+			baseURL+ControlServiceWatchStateProcedure,
+			opts...,
+		),
+	}
+}
+
+// controlServiceClient implements ControlServiceClient.
+type controlServiceClient struct {
+	listIdentities   *connect_go.Client[pb.ListIdentitiesRequest, pb.ListIdentitiesResponse]
+	currentIdentity  *connect_go.Client[pb.CurrentIdentityRequest, pb.Identity]
+	unlockIdentity   *connect_go.Client[pb.UnlockIdentityRequest, pb.UnlockIdentityResponse]
+	connect          *connect_go.Client[pb.ConnectRequest, pb.ConnectionStatus]
+	disconnect       *connect_go.Client[pb.DisconnectRequest, pb.DisconnectResponse]
+	getConnection    *connect_go.Client[pb.GetConnectionRequest, pb.ConnectionStatus]
+	watchConnection  *connect_go.Client[pb.WatchConnectionRequest, pb.ConnectionStatus]
+	listServices     *connect_go.Client[pb.ListServicesRequest, pb.ListServicesResponse]
+	startService     *connect_go.Client[pb.StartServiceRequest, pb.Service]
+	stopService      *connect_go.Client[pb.StopServiceRequest, pb.StopServiceResponse]
+	listSessions     *connect_go.Client[pb.ListSessionsRequest, pb.ListSessionsResponse]
+	listProposals    *connect_go.Client[pb.ListProposalsRequest, pb.ListProposalsResponse]
+	getPayoutInfo    *connect_go.Client[pb.GetPayoutInfoRequest, pb.PayoutInfo]
+	updatePayoutInfo *connect_go.Client[pb.UpdatePayoutInfoRequest, pb.UpdatePayoutInfoResponse]
+	watchState       *connect_go.Client[pb.WatchStateRequest, pb.NodeState]
+}
+
+// ListIdentities calls pb.ControlService.ListIdentities.
+func (c *controlServiceClient) ListIdentities(ctx context.Context, req *connect_go.Request[pb.ListIdentitiesRequest]) (*connect_go.Response[pb.ListIdentitiesResponse], error) {
+	return c.listIdentities.CallUnary(ctx, req)
+}
+
+// CurrentIdentity calls pb.ControlService.CurrentIdentity.
+func (c *controlServiceClient) CurrentIdentity(ctx context.Context, req *connect_go.Request[pb.CurrentIdentityRequest]) (*connect_go.Response[pb.Identity], error) {
+	return c.currentIdentity.CallUnary(ctx, req)
+}
+
+// UnlockIdentity calls pb.ControlService.UnlockIdentity.
+func (c *controlServiceClient) UnlockIdentity(ctx context.Context, req *connect_go.Request[pb.UnlockIdentityRequest]) (*connect_go.Response[pb.UnlockIdentityResponse], error) {
+	return c.unlockIdentity.CallUnary(ctx, req)
+}
+
+// Connect calls pb.ControlService.Connect.
+func (c *controlServiceClient) Connect(ctx context.Context, req *connect_go.Request[pb.ConnectRequest]) (*connect_go.Response[pb.ConnectionStatus], error) {
+	return c.connect.CallUnary(ctx, req)
+}
+
+// Disconnect calls pb.ControlService.Disconnect.
+func (c *controlServiceClient) Disconnect(ctx context.Context, req *connect_go.Request[pb.DisconnectRequest]) (*connect_go.Response[pb.DisconnectResponse], error) {
+	return c.disconnect.CallUnary(ctx, req)
+}
+
+// GetConnection calls pb.ControlService.GetConnection.
+func (c *controlServiceClient) GetConnection(ctx context.Context, req *connect_go.Request[pb.GetConnectionRequest]) (*connect_go.Response[pb.ConnectionStatus], error) {
+	return c.getConnection.CallUnary(ctx, req)
+}
+
+// WatchConnection calls pb.ControlService.WatchConnection.
+func (c *controlServiceClient) WatchConnection(ctx context.Context, req *connect_go.Request[pb.WatchConnectionRequest]) (*connect_go.ServerStreamForClient[pb.ConnectionStatus], error) {
+	return c.watchConnection.CallServerStream(ctx, req)
+}
+
+// ListServices calls pb.ControlService.ListServices.
+func (c *controlServiceClient) ListServices(ctx context.Context, req *connect_go.Request[pb.ListServicesRequest]) (*connect_go.Response[pb.ListServicesResponse], error) {
+	return c.listServices.CallUnary(ctx, req)
+}
+
+// StartService calls pb.ControlService.StartService.
+func (c *controlServiceClient) StartService(ctx context.Context, req *connect_go.Request[pb.StartServiceRequest]) (*connect_go.Response[pb.Service], error) {
+	return c.startService.CallUnary(ctx, req)
+}
+
+// StopService calls pb.ControlService.StopService.
+func (c *controlServiceClient) StopService(ctx context.Context, req *connect_go.Request[pb.StopServiceRequest]) (*connect_go.Response[pb.StopServiceResponse], error) {
+	return c.stopService.CallUnary(ctx, req)
+}
+
+// ListSessions calls pb.ControlService.ListSessions.
+func (c *controlServiceClient) ListSessions(ctx context.Context, req *connect_go.Request[pb.ListSessionsRequest]) (*connect_go.Response[pb.ListSessionsResponse], error) {
+	return c.listSessions.CallUnary(ctx, req)
+}
+
+// ListProposals calls pb.ControlService.ListProposals.
+func (c *controlServiceClient) ListProposals(ctx context.Context, req *connect_go.Request[pb.ListProposalsRequest]) (*connect_go.Response[pb.ListProposalsResponse], error) {
+	return c.listProposals.CallUnary(ctx, req)
+}
+
+// GetPayoutInfo calls pb.ControlService.GetPayoutInfo.
+func (c *controlServiceClient) GetPayoutInfo(ctx context.Context, req *connect_go.Request[pb.GetPayoutInfoRequest]) (*connect_go.Response[pb.PayoutInfo], error) {
+	return c.getPayoutInfo.CallUnary(ctx, req)
+}
+
+// UpdatePayoutInfo calls pb.ControlService.UpdatePayoutInfo.
+func (c *controlServiceClient) UpdatePayoutInfo(ctx context.Context, req *connect_go.Request[pb.UpdatePayoutInfoRequest]) (*connect_go.Response[pb.UpdatePayoutInfoResponse], error) {
+	return c.updatePayoutInfo.CallUnary(ctx, req)
+}
+
+// WatchState calls pb.ControlService.WatchState.
+func (c *controlServiceClient) WatchState(ctx context.Context, req *connect_go.Request[pb.WatchStateRequest]) (*connect_go.ServerStreamForClient[pb.NodeState], error) {
+	return c.watchState.CallServerStream(ctx, req)
+}
+
+// ControlServiceHandler is an implementation of the pb.ControlService service.
+type ControlServiceHandler interface {
+	// ListIdentities lists identities stored in the keystore.
+	ListIdentities(context.Context, *connect_go.Request[pb.ListIdentitiesRequest]) (*connect_go.Response[pb.ListIdentitiesResponse], error)
+	// CurrentIdentity selects the identity used by the node, creating a new one if there are none.
+	CurrentIdentity(context.Context, *connect_go.Request[pb.CurrentIdentityRequest]) (*connect_go.Response[pb.Identity], error)
+	// UnlockIdentity unlocks the identity with the given passphrase.
+	UnlockIdentity(context.Context, *connect_go.Request[pb.UnlockIdentityRequest]) (*connect_go.Response[pb.UnlockIdentityResponse], error)
+	// Connect creates a consumer connection to the provider.
+	Connect(context.Context, *connect_go.Request[pb.ConnectRequest]) (*connect_go.Response[pb.ConnectionStatus], error)
+	// Disconnect closes the consumer connection.
+	Disconnect(context.Context, *connect_go.Request[pb.DisconnectRequest]) (*connect_go.Response[pb.DisconnectResponse], error)
+	// GetConnection returns the status of the consumer connection.
+	GetConnection(context.Context, *connect_go.Request[pb.GetConnectionRequest]) (*connect_go.Response[pb.ConnectionStatus], error)
+	// WatchConnection streams the status of the consumer connection, starting with the current one.
+	WatchConnection(context.Context, *connect_go.Request[pb.WatchConnectionRequest], *connect_go.ServerStream[pb.ConnectionStatus]) error
+	// ListServices lists services running on the node.
+	ListServices(context.Context, *connect_go.Request[pb.ListServicesRequest]) (*connect_go.Response[pb.ListServicesResponse], error)
+	// StartService starts the provider service.
+	StartService(context.Context, *connect_go.Request[pb.StartServiceRequest]) (*connect_go.Response[pb.Service], error)
+	// StopService stops the provider service.
+	StopService(context.Context, *connect_go.Request[pb.StopServiceRequest]) (*connect_go.Response[pb.StopServiceResponse], error)
+	// ListSessions lists the history of consumer and provider sessions.
+	ListSessions(context.Context, *connect_go.Request[pb.ListSessionsRequest]) (*connect_go.Response[pb.ListSessionsResponse], error)
+	// ListProposals lists proposals of the providers.
+	ListProposals(context.Context, *connect_go.Request[pb.ListProposalsRequest]) (*connect_go.Response[pb.ListProposalsResponse], error)
+	// GetPayoutInfo returns the payout info of the identity.
+	GetPayoutInfo(context.Context, *connect_go.Request[pb.GetPayoutInfoRequest]) (*connect_go.Response[pb.PayoutInfo], error)
+	// UpdatePayoutInfo sets the payout address of the identity.
+	UpdatePayoutInfo(context.Context, *connect_go.Request[pb.UpdatePayoutInfoRequest]) (*connect_go.Response[pb.UpdatePayoutInfoResponse], error)
+	// WatchState streams the state of the node, starting with the current one.
+	WatchState(context.Context, *connect_go.Request[pb.WatchStateRequest], *connect_go.ServerStream[pb.NodeState]) error
+}
+
+// NewControlServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewControlServiceHandler(svc ControlServiceHandler, opts ...connect_go.HandlerOption) (string, http.Handler) {
+	controlServiceListIdentitiesHandler := connect_go.NewUnaryHandler(
+		ControlServiceListIdentitiesProcedure,
+		svc.ListIdentities,
+		opts...,
+	)
+	controlServiceCurrentIdentityHandler := connect_go.NewUnaryHandler(
+		ControlServiceCurrentIdentityProcedure,
+		svc.CurrentIdentity,
+		opts...,
+	)
+	controlServiceUnlockIdentityHandler := connect_go.NewUnaryHandler(
+		ControlServiceUnlockIdentityProcedure,
+		svc.UnlockIdentity,
+		opts...,
+	)
+	controlServiceConnectHandler := connect_go.NewUnaryHandler(
+		ControlServiceConnectProcedure,
+		svc.Connect,
+		opts...,
+	)
+	controlServiceDisconnectHandler := connect_go.NewUnaryHandler(
+		ControlServiceDisconnectProcedure,
+		svc.Disconnect,
+		opts...,
+	)
+	controlServiceGetConnectionHandler := connect_go.NewUnaryHandler(
+		ControlServiceGetConnectionProcedure,
+		svc.GetConnection,
+		opts...,
+	)
+	controlServiceWatchConnectionHandler := connect_go.NewServerStreamHandler(
+		ControlServiceWatchConnectionProcedure,
+		svc.WatchConnection,
+		opts...,
+	)
+	controlServiceListServicesHandler := connect_go.NewUnaryHandler(
+		ControlServiceListServicesProcedure,
+		svc.ListServices,
+		opts...,
+	)
+	controlServiceStartServiceHandler := connect_go.NewUnaryHandler(
+		ControlServiceStartServiceProcedure,
+		svc.StartService,
+		opts...,
+	)
+	controlServiceStopServiceHandler := connect_go.NewUnaryHandler(
+		ControlServiceStopServiceProcedure,
+		svc.StopService,
+		opts...,
+	)
+	controlServiceListSessionsHandler := connect_go.NewUnaryHandler(
+		ControlServiceListSessionsProcedure,
+		svc.ListSessions,
+		opts...,
+	)
+	controlServiceListProposalsHandler := connect_go.NewUnaryHandler(
+		ControlServiceListProposalsProcedure,
+		svc.ListProposals,
+		opts...,
+	)
+	controlServiceGetPayoutInfoHandler := connect_go.NewUnaryHandler(
+		ControlServiceGetPayoutInfoProcedure,
+		svc.GetPayoutInfo,
+		opts...,
+	)
+	controlServiceUpdatePayoutInfoHandler := connect_go.NewUnaryHandler(
+		ControlServiceUpdatePayoutInfoProcedure,
+		svc.UpdatePayoutInfo,
+		opts...,
+	)
+	controlServiceWatchStateHandler := connect_go.NewServerStreamHandler(
+		ControlServiceWatchStateProcedure,
+		svc.WatchState,
+		opts...,
+	)
+	return "/pb.ControlService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case ControlServiceListIdentitiesProcedure:
+			controlServiceListIdentitiesHandler.ServeHTTP(w, r)
+		case ControlServiceCurrentIdentityProcedure:
+			controlServiceCurrentIdentityHandler.ServeHTTP(w, r)
+		case ControlServiceUnlockIdentityProcedure:
+			controlServiceUnlockIdentityHandler.ServeHTTP(w, r)
+		case ControlServiceConnectProcedure:
+			controlServiceConnectHandler.ServeHTTP(w, r)
+		case ControlServiceDisconnectProcedure:
+			controlServiceDisconnectHandler.ServeHTTP(w, r)
+		case ControlServiceGetConnectionProcedure:
+			controlServiceGetConnectionHandler.ServeHTTP(w, r)
+		case ControlServiceWatchConnectionProcedure:
+			controlServiceWatchConnectionHandler.ServeHTTP(w, r)
+		case ControlServiceListServicesProcedure:
+			controlServiceListServicesHandler.ServeHTTP(w, r)
+		case ControlServiceStartServiceProcedure:
+			controlServiceStartServiceHandler.ServeHTTP(w, r)
+		case ControlServiceStopServiceProcedure:
+			controlServiceStopServiceHandler.ServeHTTP(w, r)
+		case ControlServiceListSessionsProcedure:
+			controlServiceListSessionsHandler.ServeHTTP(w, r)
+		case ControlServiceListProposalsProcedure:
+			controlServiceListProposalsHandler.ServeHTTP(w, r)
+		case ControlServiceGetPayoutInfoProcedure:
+			controlServiceGetPayoutInfoHandler.ServeHTTP(w, r)
+		case ControlServiceUpdatePayoutInfoProcedure:
+			controlServiceUpdatePayoutInfoHandler.ServeHTTP(w, r)
+		case ControlServiceWatchStateProcedure:
+			controlServiceWatchStateHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedControlServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedControlServiceHandler struct{}
+
+func (UnimplementedControlServiceHandler) ListIdentities(context.Context, *connect_go.Request[pb.ListIdentitiesRequest]) (*connect_go.Response[pb.ListIdentitiesResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("pb.ControlService.ListIdentities is not implemented"))
+}
+
+func (UnimplementedControlServiceHandler) CurrentIdentity(context.Context, *connect_go.Request[pb.CurrentIdentityRequest]) (*connect_go.Response[pb.Identity], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("pb.ControlService.CurrentIdentity is not implemented"))
+}
+
+func (UnimplementedControlServiceHandler) UnlockIdentity(context.Context, *connect_go.Request[pb.UnlockIdentityRequest]) (*connect_go.Response[pb.UnlockIdentityResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("pb.ControlService.UnlockIdentity is not implemented"))
+}
+
+func (UnimplementedControlServiceHandler) Connect(context.Context, *connect_go.Request[pb.ConnectRequest]) (*connect_go.Response[pb.ConnectionStatus], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("pb.ControlService.Connect is not implemented"))
+}
+
+func (UnimplementedControlServiceHandler) Disconnect(context.Context, *connect_go.Request[pb.DisconnectRequest]) (*connect_go.Response[pb.DisconnectResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("pb.ControlService.Disconnect is not implemented"))
+}
+
+func (UnimplementedControlServiceHandler) GetConnection(context.Context, *connect_go.Request[pb.GetConnectionRequest]) (*connect_go.Response[pb.ConnectionStatus], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("pb.ControlService.GetConnection is not implemented"))
+}
+
+func (UnimplementedControlServiceHandler) WatchConnection(context.Context, *connect_go.Request[pb.WatchConnectionRequest], *connect_go.ServerStream[pb.ConnectionStatus]) error {
+	return connect_go.NewError(connect_go.CodeUnimplemented, errors.New("pb.ControlService.WatchConnection is not implemented"))
+}
+
+func (UnimplementedControlServiceHandler) ListServices(context.Context, *connect_go.Request[pb.ListServicesRequest]) (*connect_go.Response[pb.ListServicesResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("pb.ControlService.ListServices is not implemented"))
+}
+
+func (UnimplementedControlServiceHandler) StartService(context.Context, *connect_go.Request[pb.StartServiceRequest]) (*connect_go.Response[pb.Service], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("pb.ControlService.StartService is not implemented"))
+}
+
+func (UnimplementedControlServiceHandler) StopService(context.Context, *connect_go.Request[pb.StopServiceRequest]) (*connect_go.Response[pb.StopServiceResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("pb.ControlService.StopService is not implemented"))
+}
+
+func (UnimplementedControlServiceHandler) ListSessions(context.Context, *connect_go.Request[pb.ListSessionsRequest]) (*connect_go.Response[pb.ListSessionsResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("pb.ControlService.ListSessions is not implemented"))
+}
+
+func (UnimplementedControlServiceHandler) ListProposals(context.Context, *connect_go.Request[pb.ListProposalsRequest]) (*connect_go.Response[pb.ListProposalsResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("pb.ControlService.ListProposals is not implemented"))
+}
+
+func (UnimplementedControlServiceHandler) GetPayoutInfo(context.Context, *connect_go.Request[pb.GetPayoutInfoRequest]) (*connect_go.Response[pb.PayoutInfo], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("pb.ControlService.GetPayoutInfo is not implemented"))
+}
+
+func (UnimplementedControlServiceHandler) UpdatePayoutInfo(context.Context, *connect_go.Request[pb.UpdatePayoutInfoRequest]) (*connect_go.Response[pb.UpdatePayoutInfoResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("pb.ControlService.UpdatePayoutInfo is not implemented"))
+}
+
+func (UnimplementedControlServiceHandler) WatchState(context.Context, *connect_go.Request[pb.WatchStateRequest], *connect_go.ServerStream[pb.NodeState]) error {
+	return connect_go.NewError(connect_go.CodeUnimplemented, errors.New("pb.ControlService.WatchState is not implemented"))
+}
@@ -55,6 +55,19 @@ func (c *ClientConfig) SetProtocol(protocol string) {
 	}
 }
 
+// SetRoutes routes traffic through the tunnel, limiting it to the include networks if there are any
+func (c *ClientConfig) SetRoutes(splitTunnel connection.SplitTunnel) {
+	if len(splitTunnel.Include) == 0 {
+		c.SetParam("redirect-gateway", "def1", "bypass-dhcp")
+	}
+	for _, network := range splitTunnel.Include {
+		c.SetParam("route", network.IP.String(), net.IP(network.Mask).String())
+	}
+	for _, network := range splitTunnel.Exclude {
+		c.SetParam("route", network.IP.String(), net.IP(network.Mask).String(), "net_gateway")
+	}
+}
+
 func defaultClientConfig(runtimeDir string, scriptSearchPath string) *ClientConfig {
 	clientConfig := ClientConfig{GenericConfig: config.NewConfig(runtimeDir, scriptSearchPath), VpnConfig: nil}
 
@@ -70,7 +83,6 @@ func defaultClientConfig(runtimeDir string, scriptSearchPath string) *ClientConf
 
 	clientConfig.SetParam("reneg-sec", "0")
 	clientConfig.SetParam("resolv-retry", "infinite")
-	clientConfig.SetParam("redirect-gateway", "def1", "bypass-dhcp")
 
 	return &clientConfig
 }
@@ -102,7 +114,12 @@ func NewClientConfigFromSession(vpnConfig VPNConfig, scriptDir string, runtimeDi
 	var remotePort, localPort int
 	if options.ProviderNATConn != nil && vpnConfig.RemoteIP != "127.0.0.1" {
 		options.ProviderNATConn.Close()
-		remotePort = options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).Port
+		remoteAddr := options.ProviderNATConn.RemoteAddr().(*net.UDPAddr)
+		// Provider is reached via relay when NAT hole punching fails.
+		if !remoteAddr.IP.IsLoopback() {
+			vpnConfig.RemoteIP = remoteAddr.IP.String()
+		}
+		remotePort = remoteAddr.Port
 		localPort = options.ProviderNATConn.LocalAddr().(*net.UDPAddr).Port
 	} else {
 		remotePort = vpnConfig.RemotePort
@@ -113,6 +130,7 @@ func NewClientConfigFromSession(vpnConfig VPNConfig, scriptDir string, runtimeDi
 	clientFileConfig.SetReconnectRetry(2)
 	clientFileConfig.SetClientMode(vpnConfig.RemoteIP, remotePort, localPort)
 	clientFileConfig.SetProtocol(vpnConfig.RemoteProtocol)
+	clientFileConfig.SetRoutes(options.SplitTunnel)
 	clientFileConfig.SetTLSCACertificate(vpnConfig.CACertificate)
 	clientFileConfig.SetTLSCrypt(vpnConfig.TLSPresharedKey)
 
@@ -0,0 +1,72 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package openvpn
+
+import (
+	"net"
+	"testing"
+
+	"github.com/mysteriumnetwork/go-openvpn/openvpn/config"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientConfig_SetRoutes(t *testing.T) {
+	tests := []struct {
+		name        string
+		splitTunnel connection.SplitTunnel
+		expected    []string
+	}{
+		{
+			name:     "routes all traffic through the tunnel",
+			expected: []string{"--redirect-gateway", "def1", "bypass-dhcp"},
+		},
+		{
+			name: "excludes networks from the tunnel",
+			splitTunnel: connection.SplitTunnel{
+				Exclude: []net.IPNet{{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(8, 32)}},
+			},
+			expected: []string{
+				"--redirect-gateway", "def1", "bypass-dhcp",
+				"--route", "10.0.0.0", "255.0.0.0", "net_gateway",
+			},
+		},
+		{
+			name: "routes only included networks through the tunnel",
+			splitTunnel: connection.SplitTunnel{
+				Include: []net.IPNet{{IP: net.IPv4(8, 8, 8, 0), Mask: net.CIDRMask(24, 32)}},
+				Exclude: []net.IPNet{{IP: net.IPv4(8, 8, 8, 8), Mask: net.CIDRMask(32, 32)}},
+			},
+			expected: []string{
+				"--route", "8.8.8.0", "255.255.255.0",
+				"--route", "8.8.8.8", "255.255.255.255", "net_gateway",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clientConfig := &ClientConfig{GenericConfig: config.NewConfig("", "")}
+			clientConfig.SetRoutes(test.splitTunnel)
+
+			args, err := clientConfig.ToArguments()
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, args)
+		})
+	}
+}
@@ -22,6 +22,8 @@ import (
 
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/event"
+	"github.com/rs/zerolog/log"
 )
 
 // SessionMap defines map of current sessions
@@ -34,6 +36,7 @@ type clientMap struct {
 	sessions SessionMap
 	// TODO: use clientID to kill OpenVPN session (client-kill {clientID}) when promise processor instructs so
 	sessionClientIDs map[int]session.ID
+	sessionTunnels   map[session.ID]chan event.TunnelContext
 	sessionMapLock   sync.Mutex
 }
 
@@ -42,6 +45,7 @@ func NewClientMap(sessionMap SessionMap) *clientMap {
 	return &clientMap{
 		sessions:         sessionMap,
 		sessionClientIDs: make(map[int]session.ID),
+		sessionTunnels:   make(map[session.ID]chan event.TunnelContext),
 	}
 }
 
@@ -88,3 +92,42 @@ func (cm *clientMap) GetClientSession(clientID int) (session.ID, bool) {
 	sessionID, exist := cm.sessionClientIDs[clientID]
 	return sessionID, exist
 }
+
+// TrackTunnels returns a channel reporting tunnels of Openvpn clients which are using given session.
+func (cm *clientMap) TrackTunnels(id session.ID) <-chan event.TunnelContext {
+	cm.sessionMapLock.Lock()
+	defer cm.sessionMapLock.Unlock()
+
+	tunnels := make(chan event.TunnelContext, 1)
+	cm.sessionTunnels[id] = tunnels
+	return tunnels
+}
+
+// UntrackTunnels stops reporting tunnels of given session.
+func (cm *clientMap) UntrackTunnels(id session.ID) {
+	cm.sessionMapLock.Lock()
+	defer cm.sessionMapLock.Unlock()
+
+	delete(cm.sessionTunnels, id)
+}
+
+// ReportTunnel reports established tunnel of given Openvpn client to its session.
+func (cm *clientMap) ReportTunnel(clientID int, tunnel event.TunnelContext) {
+	cm.sessionMapLock.Lock()
+	defer cm.sessionMapLock.Unlock()
+
+	sessionID, exist := cm.sessionClientIDs[clientID]
+	if !exist {
+		return
+	}
+	tunnels, exist := cm.sessionTunnels[sessionID]
+	if !exist {
+		return
+	}
+
+	select {
+	case tunnels <- tunnel:
+	default:
+		log.Warn().Msgf("Tunnel of session %s was not reported, previous one is still pending", sessionID)
+	}
+}
@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"net"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientMap_ReportTunnel(t *testing.T) {
+	clients := NewClientMap(nil)
+	tunnels := clients.TrackTunnels(session.ID("session1"))
+	clients.Add(1, session.ID("session1"))
+	clients.Add(2, session.ID("session2"))
+
+	tunnel := event.TunnelContext{Interface: "tun0", IP: net.ParseIP("10.8.0.6")}
+	clients.ReportTunnel(2, tunnel)
+	clients.ReportTunnel(1, tunnel)
+	assert.Equal(t, tunnel, <-tunnels)
+	assert.Len(t, tunnels, 0)
+
+	clients.UntrackTunnels(session.ID("session1"))
+	clients.ReportTunnel(1, tunnel)
+	assert.Len(t, tunnels, 0)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/nat"
@@ -41,7 +42,11 @@ func NewManager(nodeOptions node.Options,
 	portPool port.ServicePortSupplier,
 	bus eventbus.EventBus,
 	trafficFirewall firewall.IncomingTrafficFirewall,
+	blocklist *dns.Blocklist,
 ) *Manager {
+	if !serviceOptions.DNSBlocking {
+		blocklist = nil
+	}
 	return &Manager{
 		nodeOptions:     nodeOptions,
 		serviceOptions:  serviceOptions,
@@ -52,6 +57,7 @@ func NewManager(nodeOptions node.Options,
 		trafficFirewall: trafficFirewall,
 		country:         country,
 		ipResolver:      ipResolver,
+		blocklist:       blocklist,
 
 		openvpnClients: NewClientMap(sessionMap),
 	}
@@ -24,15 +24,16 @@ import (
 	"net"
 
 	"github.com/mysteriumnetwork/go-openvpn/openvpn"
+	"github.com/mysteriumnetwork/go-openvpn/openvpn/middlewares/server"
 	"github.com/mysteriumnetwork/go-openvpn/openvpn/middlewares/server/filter"
 	"github.com/mysteriumnetwork/go-openvpn/openvpn/middlewares/state"
 	"github.com/mysteriumnetwork/go-openvpn/openvpn/tls"
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/service"
-	"github.com/mysteriumnetwork/node/core/shaper"
 	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
@@ -42,6 +43,7 @@ import (
 	nat_event "github.com/mysteriumnetwork/node/nat/event"
 	openvpn_service "github.com/mysteriumnetwork/node/services/openvpn"
 	"github.com/mysteriumnetwork/node/session"
+	sevent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/utils/netutil"
 	"github.com/mysteriumnetwork/node/utils/stringutil"
 	"github.com/rs/zerolog/log"
@@ -61,6 +63,7 @@ type Manager struct {
 	ports           port.ServicePortSupplier
 	natEventGetter  NATEventGetter
 	dnsProxy        *dns.Proxy
+	blocklist       *dns.Blocklist
 	bus             eventbus.EventBus
 	trafficFirewall firewall.IncomingTrafficFirewall
 	vpnNetwork      net.IPNet
@@ -86,20 +89,26 @@ func (m *Manager) Serve(instance *service.Instance) (err error) {
 		Mask: net.IPMask(net.ParseIP(m.serviceOptions.Netmask).To4()),
 	}
 
+	if instance.Policies().HasTrafficRules() {
+		removeRules, err := instance.Policies().ApplyTrafficRules(m.trafficFirewall, m.vpnNetwork)
+		if err != nil {
+			return fmt.Errorf("failed to enable traffic blocking: %w", err)
+		}
+		defer func() {
+			if err := removeRules(); err != nil {
+				log.Warn().Err(err).Msg("failed to disable traffic blocking")
+			}
+		}()
+	}
+
 	var dnsPort = 11153
 	dnsHandler, err := dns.ResolveViaSystem()
 	if err == nil {
 		if instance.Policies().HasDNSRules() {
 			dnsHandler = dns.WhitelistAnswers(dnsHandler, m.trafficFirewall, instance.Policies())
-			removeRule, err := m.trafficFirewall.BlockIncomingTraffic(m.vpnNetwork)
-			if err != nil {
-				return fmt.Errorf("failed to enable traffic blocking: %w", err)
-			}
-			defer func() {
-				if err := removeRule(); err != nil {
-					log.Warn().Err(err).Msg("failed to disable traffic blocking")
-				}
-			}()
+		}
+		if m.blocklist != nil {
+			dnsHandler = dns.BlockByList(dnsHandler, m.blocklist, string(instance.ID))
 		}
 
 		m.dnsProxy = dns.NewProxy("", dnsPort, dnsHandler)
@@ -112,6 +121,9 @@ func (m *Manager) Serve(instance *service.Instance) (err error) {
 	} else {
 		log.Warn().Err(err).Msg("Provider DNS will not be available")
 	}
+	if !m.dnsOK && instance.Policies().HasDNSRules() {
+		return policy.ErrDNSRulesUnenforced
+	}
 
 	servicePort, err := m.ports.Acquire()
 	if err != nil {
@@ -153,13 +165,6 @@ func (m *Manager) Serve(instance *service.Instance) (err error) {
 		return fmt.Errorf("failed to setup NAT/firewall rules: %w", err)
 	}
 
-	s := shaper.New(m.bus)
-	err = s.Start(m.openvpnProcess.DeviceName())
-	if err != nil {
-		log.Error().Err(err).Msg("Could not start traffic shaper")
-	}
-	defer s.Clear(m.openvpnProcess.DeviceName())
-
 	log.Info().Msg("OpenVPN server waiting")
 	return m.openvpnProcess.Wait()
 }
@@ -206,8 +211,11 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		return nil, fmt.Errorf("could not proxy connection to OpenVPN server: %w", err)
 	}
 
+	tunnels := m.openvpnClients.TrackTunnels(session.ID(sessionID))
+
 	destroy := func() {
 		log.Info().Msgf("Cleaning up session %s", sessionID)
+		m.openvpnClients.UntrackTunnels(session.ID(sessionID))
 
 		sessionClients := m.openvpnClients.GetSessionClients(session.ID(sessionID))
 		for clientID := range sessionClients {
@@ -217,7 +225,19 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		}
 	}
 
-	return &service.ConfigParams{SessionServiceConfig: vpnConfig, SessionDestroyCallback: destroy}, nil
+	return &service.ConfigParams{SessionServiceConfig: vpnConfig, SessionDestroyCallback: destroy, SessionTunnel: tunnels}, nil
+}
+
+// handleClientEvent reports tunnel of the session once its Openvpn client connection is established.
+func (m *Manager) handleClientEvent(event server.ClientEvent) {
+	if event.EventType != server.Established {
+		return
+	}
+
+	m.openvpnClients.ReportTunnel(event.ClientID, sevent.TunnelContext{
+		Interface: m.openvpnProcess.DeviceName(),
+		IP:        net.ParseIP(event.Env["ifconfig_pool_remote_ip"]),
+	})
 }
 
 func (m *Manager) startServer() error {
@@ -240,6 +260,7 @@ func (m *Manager) startServer() error {
 
 	stateChannel := make(chan openvpn.State, 10)
 	m.openvpnAuth = newAuthHandler(m.openvpnClients, identity.NewExtractor())
+	m.openvpnAuth.ClientsSubscribe(m.handleClientEvent)
 	m.openvpnProcess = openvpn.CreateNewProcess(
 		m.nodeOptions.Openvpn.BinaryPath(),
 		vpnServerConfig.GenericConfig,
@@ -31,15 +31,26 @@ type Options struct {
 	Port     int    `json:"port"`
 	Subnet   string `json:"subnet"`
 	Netmask  string `json:"netmask"`
+	// BandwidthKbps limits bandwidth of every session, sessions are not limited when zero
+	BandwidthKbps uint64 `json:"bandwidth_kbps"`
+	// DNSBlocking answers DNS queries of the names in the node blocklists by the provider itself
+	DNSBlocking bool `json:"dns_blocking"`
+}
+
+// SessionBandwidthKbps returns bandwidth limit of every session in Kbps.
+func (o Options) SessionBandwidthKbps() uint64 {
+	return o.BandwidthKbps
 }
 
 // GetOptions returns effective OpenVPN service options from application configuration.
 func GetOptions() Options {
 	return Options{
-		Protocol: config.GetString(config.FlagOpenvpnProtocol),
-		Port:     config.GetInt(config.FlagOpenvpnPort),
-		Subnet:   config.GetString(config.FlagOpenvpnSubnet),
-		Netmask:  config.GetString(config.FlagOpenvpnNetmask),
+		Protocol:      config.GetString(config.FlagOpenvpnProtocol),
+		Port:          config.GetInt(config.FlagOpenvpnPort),
+		Subnet:        config.GetString(config.FlagOpenvpnSubnet),
+		Netmask:       config.GetString(config.FlagOpenvpnNetmask),
+		BandwidthKbps: service.DefaultBandwidthKbps(),
+		DNSBlocking:   config.GetBool(config.FlagDNSBlocklistProvider),
 	}
 }
 
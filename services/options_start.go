@@ -26,6 +26,7 @@ import (
 	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/services/noop"
 	"github.com/mysteriumnetwork/node/services/openvpn"
+	"github.com/mysteriumnetwork/node/services/socks5"
 	"github.com/mysteriumnetwork/node/services/wireguard"
 	"github.com/urfave/cli/v2"
 )
@@ -50,6 +51,10 @@ func GetStartOptions(serviceType string) (opts StartOptions, err error) {
 		opts.PaymentPricePerGB = getPrice(config.FlagNoopPriceGB, config.FlagPaymentPricePerGB)
 		opts.PaymentPricePerMinute = getPrice(config.FlagNoopPriceMinute, config.FlagPaymentPricePerMinute)
 		opts.AccessPolicyList = getPolicies(config.FlagNoopAccessPolicies, config.FlagAccessPolicyList)
+	case socks5.ServiceType:
+		opts.PaymentPricePerGB = getPrice(config.FlagSOCKS5PriceGB, config.FlagPaymentPricePerGB)
+		opts.PaymentPricePerMinute = getPrice(config.FlagSOCKS5PriceMinute, config.FlagPaymentPricePerMinute)
+		opts.AccessPolicyList = getPolicies(config.FlagSOCKS5AccessPolicies, config.FlagAccessPolicyList)
 	}
 	return opts, nil
 }
@@ -0,0 +1,220 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package socks5
+
+import (
+	"context"
+	"encoding/json"
+	stdlog "log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/armon/go-socks5"
+	"github.com/libp2p/go-yamux"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	netcontext "golang.org/x/net/context"
+	"golang.org/x/net/proxy"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+)
+
+// ConnectionOptions represents consumer connection options.
+type ConnectionOptions struct {
+	// ListenAddress is the local address SOCKS5 proxy is served on to the consumer applications.
+	ListenAddress string
+}
+
+// NewConnection creates a new SOCKS5 connection.
+func NewConnection(opts ConnectionOptions) (connection.Connection, error) {
+	return &Connection{
+		done:    make(chan struct{}),
+		stateCh: make(chan connectionstate.State, 100),
+		opts:    opts,
+		stats:   &stats{},
+	}, nil
+}
+
+// Connection serves local SOCKS5 proxy which relays the connections to the provider proxy.
+type Connection struct {
+	stopOnce sync.Once
+	done     chan struct{}
+	stateCh  chan connectionstate.State
+
+	opts     ConnectionOptions
+	stats    *stats
+	mux      *yamux.Session
+	listener net.Listener
+}
+
+var _ connection.Connection = &Connection{}
+
+// State returns connection state channel.
+func (c *Connection) State() <-chan connectionstate.State {
+	return c.stateCh
+}
+
+// Statistics returns connection statistics.
+func (c *Connection) Statistics() (connectionstate.Statistics, error) {
+	sent, received := c.stats.get()
+	return connectionstate.Statistics{
+		At:            time.Now(),
+		BytesSent:     sent,
+		BytesReceived: received,
+	}, nil
+}
+
+// Start connects to the provider proxy and starts serving local proxy.
+func (c *Connection) Start(ctx context.Context, options connection.ConnectOptions) (err error) {
+	var config ServiceConfig
+	if err := json.Unmarshal(options.SessionConfig, &config); err != nil {
+		return errors.Wrap(err, "failed to unmarshal connection config")
+	}
+	if options.ProviderNATConn == nil {
+		return errors.New("SOCKS5 connection requires p2p service connection")
+	}
+
+	defer func() {
+		if err != nil {
+			c.Stop()
+		}
+	}()
+
+	c.stateCh <- connectionstate.Connecting
+
+	sess, err := dial(options.ProviderNATConn, config.Key)
+	if err != nil {
+		return errors.Wrap(err, "could not dial provider proxy")
+	}
+	c.mux, err = yamux.Client(sess, muxConfig())
+	if err != nil {
+		sess.Close()
+		return errors.Wrap(err, "could not create session multiplexer")
+	}
+
+	dialer, err := proxy.SOCKS5("tcp", options.ProviderID.Address, &proxy.Auth{User: config.Username, Password: config.Password}, streamDialer{c})
+	if err != nil {
+		return errors.Wrap(err, "could not create provider proxy dialer")
+	}
+	server, err := socks5.New(&socks5.Config{
+		// Names are resolved by provider, so DNS requests do not leak and its access policies apply.
+		Resolver: providerResolver{},
+		Dial: func(_ netcontext.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.Dial(network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return proxiedConn{conn}, nil
+		},
+		Logger: stdlog.New(log.Logger, "", 0),
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not create local proxy")
+	}
+
+	c.listener, err = net.Listen("tcp", c.opts.ListenAddress)
+	if err != nil {
+		return errors.Wrap(err, "could not listen for local proxy connections")
+	}
+	go func() {
+		if err := server.Serve(c.listener); err != nil {
+			log.Debug().Err(err).Msg("SOCKS5 local proxy stopped")
+		}
+	}()
+	log.Info().Msgf("SOCKS5 proxy is available on %s", c.listener.Addr())
+
+	c.stateCh <- connectionstate.Connected
+	return nil
+}
+
+// ListenAddress returns the address local proxy is served on.
+func (c *Connection) ListenAddress() string {
+	if c.listener == nil {
+		return ""
+	}
+	return c.listener.Addr().String()
+}
+
+// Wait blocks until connection is stopped.
+func (c *Connection) Wait() error {
+	<-c.done
+	return nil
+}
+
+// GetConfig returns the consumer configuration for session creation
+func (c *Connection) GetConfig() (connection.ConsumerConfig, error) {
+	return nil, nil
+}
+
+// Stop stops serving local proxy and closes the connection to provider.
+func (c *Connection) Stop() {
+	c.stopOnce.Do(func() {
+		log.Info().Msg("Stopping SOCKS5 connection")
+		c.stateCh <- connectionstate.Disconnecting
+
+		if c.listener != nil {
+			if err := c.listener.Close(); err != nil {
+				log.Warn().Err(err).Msg("Failed to close SOCKS5 local proxy")
+			}
+		}
+		if c.mux != nil {
+			if err := c.mux.Close(); err != nil {
+				log.Warn().Err(err).Msg("Failed to close SOCKS5 connection")
+			}
+		}
+
+		c.stateCh <- connectionstate.NotConnected
+
+		close(c.stateCh)
+		close(c.done)
+	})
+}
+
+// streamDialer opens the streams to provider proxy.
+type streamDialer struct {
+	c *Connection
+}
+
+// Dial opens new stream to provider proxy, the address is ignored.
+func (sd streamDialer) Dial(_, _ string) (net.Conn, error) {
+	stream, err := sd.c.mux.Open()
+	if err != nil {
+		return nil, err
+	}
+	return sd.c.stats.counted(stream), nil
+}
+
+// providerResolver leaves names unresolved for provider to resolve them.
+type providerResolver struct{}
+
+// Resolve returns no IP, so the name is passed to provider.
+func (providerResolver) Resolve(ctx netcontext.Context, _ string) (netcontext.Context, net.IP, error) {
+	return ctx, nil, nil
+}
+
+// proxiedConn is the connection to destination through provider proxy.
+type proxiedConn struct {
+	net.Conn
+}
+
+// LocalAddr returns unspecified TCP address, as local proxy replies with TCP bind address.
+func (proxiedConn) LocalAddr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4zero}
+}
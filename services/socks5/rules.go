@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package socks5
+
+import (
+	"errors"
+	"net"
+	"syscall"
+
+	"github.com/armon/go-socks5"
+	"golang.org/x/net/context"
+
+	"github.com/mysteriumnetwork/node/core/policy"
+)
+
+var errDestinationProtected = errors.New("destination is protected")
+
+// policyRules permits connections to the destinations allowed by access policies of the service,
+// provider's own and protected networks are never reachable.
+type policyRules struct {
+	policies  *policy.Repository
+	protected []*net.IPNet
+}
+
+// Allow checks if the request is allowed, only connect command is supported.
+// Names are resolved before the check and the resolved IP is dialed, so it can't be rebound in between.
+func (pr policyRules) Allow(ctx context.Context, req *socks5.Request) (context.Context, bool) {
+	if req.Command != socks5.ConnectCommand {
+		return ctx, false
+	}
+
+	dest := req.DestAddr
+	if dest.IP == nil || isProtected(dest.IP, pr.protected) {
+		return ctx, false
+	}
+	return ctx, pr.policies.IsDestinationAllowed(dest.FQDN, dest.IP, "tcp", dest.Port)
+}
+
+// dialUnprotected returns dial function refusing to connect to the protected destinations,
+// in case any other path than the allowed request gets to the dial.
+func dialUnprotected(protected []*net.IPNet) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := net.Dialer{
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isProtected(ip, protected) {
+				return errDestinationProtected
+			}
+			return nil
+		},
+	}
+	return dialer.DialContext
+}
+
+func isProtected(ip net.IP, protected []*net.IPNet) bool {
+	if ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return true
+	}
+	for _, network := range protected {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,234 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package socks5
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	stdlog "log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/armon/go-socks5"
+	"github.com/libp2p/go-yamux"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	kcp "github.com/xtaci/kcp-go/v5"
+
+	"github.com/mysteriumnetwork/node/core/location/locationstate"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/nat"
+	"github.com/mysteriumnetwork/node/session/event"
+)
+
+// NewManager creates new instance of SOCKS5 service
+func NewManager(eventBus eventbus.Publisher) *Manager {
+	return &Manager{
+		done:              make(chan struct{}),
+		eventBus:          eventBus,
+		statsFrequency:    time.Second,
+		protectedNetworks: nat.ProtectedNetworks(),
+		sessionCleanup:    map[string]func(){},
+	}
+}
+
+// Manager represents an instance of SOCKS5 service
+type Manager struct {
+	done        chan struct{}
+	startStopMu sync.Mutex
+
+	eventBus          eventbus.Publisher
+	statsFrequency    time.Duration
+	protectedNetworks []*net.IPNet
+
+	serviceInstance  *service.Instance
+	sessionCleanup   map[string]func()
+	sessionCleanupMu sync.Mutex
+}
+
+// ProvideConfig provides the session credentials to the consumer and serves SOCKS5 proxy over the service connection.
+func (m *Manager) ProvideConfig(sessionID string, _ json.RawMessage, remoteConn *net.UDPConn) (*service.ConfigParams, error) {
+	log.Info().Msg("Accepting new SOCKS5 connection")
+	if remoteConn == nil {
+		return nil, errors.New("SOCKS5 service requires p2p service connection")
+	}
+
+	config, err := newServiceConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not generate session credentials")
+	}
+
+	server, err := socks5.New(&socks5.Config{
+		Credentials: socks5.StaticCredentials{config.Username: config.Password},
+		Rules:       policyRules{policies: m.serviceInstance.Policies(), protected: m.protectedNetworks},
+		Dial:        dialUnprotected(m.protectedNetworks),
+		Logger:      stdlog.New(log.Logger, "", 0),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create SOCKS5 server")
+	}
+
+	listener, err := listen(remoteConn, config.Key)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not listen on service connection")
+	}
+
+	sessionStats := &stats{}
+	stop := make(chan struct{})
+	go m.serve(listener, server, sessionStats)
+	go m.publishStats(sessionID, sessionStats, stop)
+
+	var once sync.Once
+	destroy := func() {
+		once.Do(func() {
+			log.Info().Msgf("Cleaning up session %s", sessionID)
+			m.sessionCleanupMu.Lock()
+			delete(m.sessionCleanup, sessionID)
+			m.sessionCleanupMu.Unlock()
+
+			close(stop)
+			if err := listener.Close(); err != nil {
+				log.Warn().Err(err).Msg("Failed to close SOCKS5 listener")
+			}
+		})
+	}
+
+	m.sessionCleanupMu.Lock()
+	m.sessionCleanup[sessionID] = destroy
+	m.sessionCleanupMu.Unlock()
+
+	return &service.ConfigParams{SessionServiceConfig: config, SessionDestroyCallback: destroy}, nil
+}
+
+// serve proxies the streams of consumer sessions until the listener is closed.
+func (m *Manager) serve(listener *kcp.Listener, server *socks5.Server, sessionStats *stats) {
+	for {
+		sess, err := listener.AcceptKCP()
+		if err != nil {
+			return
+		}
+		sess.SetMtu(kcpMTUSize)
+
+		mux, err := yamux.Server(sess, muxConfig())
+		if err != nil {
+			log.Warn().Err(err).Msg("Could not create SOCKS5 session multiplexer")
+			sess.Close()
+			continue
+		}
+		go func() {
+			defer mux.Close()
+			for {
+				stream, err := mux.Accept()
+				if err != nil {
+					return
+				}
+				go func() {
+					if err := server.ServeConn(sessionStats.counted(stream)); err != nil {
+						log.Debug().Err(err).Msg("SOCKS5 connection closed")
+					}
+				}()
+			}
+		}()
+	}
+}
+
+// publishStats publishes the session data transfer, so the consumer gets invoiced for it.
+func (m *Manager) publishStats(sessionID string, sessionStats *stats, stop <-chan struct{}) {
+	for {
+		select {
+		case <-time.After(m.statsFrequency):
+			sent, received := sessionStats.get()
+			m.eventBus.Publish(event.AppTopicDataTransferred, event.AppEventDataTransferred{
+				ID:   sessionID,
+				Up:   sent,
+				Down: received,
+			})
+		case <-stop:
+			log.Info().Msgf("Stopped publishing statistics for session %s", sessionID)
+			return
+		}
+	}
+}
+
+// Serve starts service - does block
+func (m *Manager) Serve(instance *service.Instance) error {
+	log.Info().Msg("SOCKS5: starting")
+	m.startStopMu.Lock()
+	m.serviceInstance = instance
+	m.startStopMu.Unlock()
+
+	log.Info().Msg("SOCKS5: started")
+	<-m.done
+	return nil
+}
+
+// Stop stops service.
+func (m *Manager) Stop() error {
+	log.Info().Msg("SOCKS5: stopping")
+	m.startStopMu.Lock()
+	defer m.startStopMu.Unlock()
+
+	m.sessionCleanupMu.Lock()
+	cleanups := make([]func(), 0, len(m.sessionCleanup))
+	for _, cleanup := range m.sessionCleanup {
+		cleanups = append(cleanups, cleanup)
+	}
+	m.sessionCleanupMu.Unlock()
+
+	for _, cleanup := range cleanups {
+		cleanup()
+	}
+
+	close(m.done)
+	log.Info().Msg("SOCKS5: stopped")
+	return nil
+}
+
+func newServiceConfig() (ServiceConfig, error) {
+	secret := make([]byte, 48)
+	if _, err := rand.Read(secret); err != nil {
+		return ServiceConfig{}, err
+	}
+	return ServiceConfig{
+		Username: hex.EncodeToString(secret[:8]),
+		Password: hex.EncodeToString(secret[8:16]),
+		Key:      secret[16:],
+	}, nil
+}
+
+// GetProposal returns the proposal for SOCKS5 service for given country
+func GetProposal(location locationstate.Location) market.ServiceProposal {
+	return market.ServiceProposal{
+		ServiceType: ServiceType,
+		ServiceDefinition: ServiceDefinition{
+			Location: market.Location{
+				Continent: location.Continent,
+				Country:   location.Country,
+				City:      location.City,
+
+				ASN:      location.ASN,
+				ISP:      location.ISP,
+				NodeType: location.NodeType,
+			},
+		},
+	}
+}
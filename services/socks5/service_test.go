@@ -0,0 +1,201 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package socks5
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/armon/go-socks5"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/proxy"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/location/locationstate"
+	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/session/event"
+)
+
+func Test_GetProposal(t *testing.T) {
+	assert.Exactly(
+		t,
+		market.ServiceProposal{
+			ServiceType: "socks5",
+			ServiceDefinition: ServiceDefinition{
+				Location: market.Location{Country: "LT"},
+			},
+		},
+		GetProposal(locationstate.Location{Country: "LT"}),
+	)
+}
+
+func Test_Manager_ProvideConfig_FailsWithoutServiceConn(t *testing.T) {
+	manager := NewManager(mocks.NewEventBus())
+
+	params, err := manager.ProvideConfig("", nil, nil)
+
+	assert.Nil(t, params)
+	assert.Error(t, err)
+}
+
+func Test_Connection_DeniesProviderLoopback(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	defer target.Close()
+	_, targetPort, _ := net.SplitHostPort(target.Listener.Addr().String())
+
+	// Without any policies every destination is allowed, except the provider itself.
+	policies := policy.NewRepository()
+	bus := mocks.NewEventBus()
+	manager := NewManager(bus)
+	manager.statsFrequency = 10 * time.Millisecond
+	go manager.Serve(service.NewInstance(identity.FromAddress("0x1"), ServiceType, nil, market.ServiceProposal{}, servicestate.Running, manager, policies, nil))
+	defer manager.Stop()
+	waitABit()
+
+	providerConn, consumerConn := serviceConnPair(t)
+	params, err := manager.ProvideConfig("session1", nil, providerConn)
+	assert.NoError(t, err)
+	sessionConfig, err := json.Marshal(params.SessionServiceConfig)
+	assert.NoError(t, err)
+
+	conn, err := NewConnection(ConnectionOptions{ListenAddress: "127.0.0.1:0"})
+	assert.NoError(t, err)
+	err = conn.Start(context.Background(), connection.ConnectOptions{
+		ProviderID:      identity.FromAddress("0x1"),
+		SessionConfig:   sessionConfig,
+		ProviderNATConn: consumerConn,
+	})
+	assert.NoError(t, err)
+	defer conn.Stop()
+	assert.Equal(t, connectionstate.Connecting, <-conn.State())
+	assert.Equal(t, connectionstate.Connected, <-conn.State())
+
+	dialer, err := proxy.SOCKS5("tcp", conn.(*Connection).ListenAddress(), nil, proxy.Direct)
+	assert.NoError(t, err)
+
+	for _, addr := range []string{"localhost:" + targetPort, target.Listener.Addr().String(), "[::1]:" + targetPort, "0.0.0.0:" + targetPort} {
+		_, err = dialer.Dial("tcp", addr)
+		assert.Error(t, err, addr)
+	}
+
+	// The SOCKS5 handshakes still went through the session.
+	stats, err := conn.Statistics()
+	assert.NoError(t, err)
+	assert.NotZero(t, stats.BytesSent)
+	assert.NotZero(t, stats.BytesReceived)
+
+	waitABit()
+	transferred, ok := bus.Pop().(event.AppEventDataTransferred)
+	assert.True(t, ok)
+	assert.Equal(t, "session1", transferred.ID)
+	assert.NotZero(t, transferred.Up)
+	assert.NotZero(t, transferred.Down)
+}
+
+func Test_policyRules_Allow(t *testing.T) {
+	_, lan, _ := net.ParseCIDR("192.168.0.0/16")
+	policies := policy.NewRepository()
+	rules := policyRules{policies: policies, protected: []*net.IPNet{lan}}
+
+	allowed := func(addr socks5.AddrSpec) bool {
+		_, ok := rules.Allow(context.Background(), &socks5.Request{Command: socks5.ConnectCommand, DestAddr: &addr})
+		return ok
+	}
+	assert.True(t, allowed(socks5.AddrSpec{FQDN: "example.com", IP: net.ParseIP("93.184.216.34"), Port: 80}))
+	assert.False(t, allowed(socks5.AddrSpec{FQDN: "example.com", Port: 80}))
+	assert.False(t, allowed(socks5.AddrSpec{IP: net.ParseIP("127.0.0.1"), Port: 4050}))
+	assert.False(t, allowed(socks5.AddrSpec{FQDN: "rebound.example.com", IP: net.ParseIP("127.0.0.1"), Port: 4050}))
+
+	assert.False(t, allowed(socks5.AddrSpec{IP: net.ParseIP("::ffff:127.0.0.1"), Port: 4050}))
+	assert.False(t, allowed(socks5.AddrSpec{IP: net.ParseIP("169.254.169.254"), Port: 80}))
+	assert.False(t, allowed(socks5.AddrSpec{IP: net.ParseIP("0.0.0.0"), Port: 80}))
+	assert.False(t, allowed(socks5.AddrSpec{IP: net.ParseIP("192.168.1.1"), Port: 80}))
+
+
+	// Allowing the name explicitly does not let consumers reach the provider itself.
+	localhost := policy.NewRepository()
+	localhost.SetPolicyRules(
+		market.AccessPolicy{ID: "localhost"},
+		market.AccessPolicyRuleSet{ID: "localhost", Allow: []market.AccessRule{{Type: market.AccessPolicyTypeDNSHostname, Value: "localhost"}}},
+	)
+	_, ok := policyRules{policies: localhost}.Allow(context.Background(), &socks5.Request{
+		Command:  socks5.ConnectCommand,
+		DestAddr: &socks5.AddrSpec{FQDN: "localhost", IP: net.ParseIP("127.0.0.1"), Port: 4050},
+	})
+	assert.False(t, ok)
+
+	_, ok = rules.Allow(context.Background(), &socks5.Request{Command: socks5.BindCommand, DestAddr: &socks5.AddrSpec{IP: net.ParseIP("93.184.216.34"), Port: 80}})
+	assert.False(t, ok)
+
+	// Traffic rules are enforced on the resolved destination.
+	policies.SetPolicyRules(
+		market.AccessPolicy{ID: "web"},
+		market.AccessPolicyRuleSet{
+			ID:    "web",
+			Allow: []market.AccessRule{{Type: market.AccessPolicyTypeDestinationPort, Value: "tcp:443"}},
+			Deny:  []market.AccessRule{{Type: market.AccessPolicyTypeDestinationCIDR, Value: "93.184.216.0/24"}},
+		},
+	)
+	assert.True(t, allowed(socks5.AddrSpec{FQDN: "ipinfo.io", IP: net.ParseIP("34.117.59.81"), Port: 443}))
+	assert.False(t, allowed(socks5.AddrSpec{FQDN: "ipinfo.io", IP: net.ParseIP("34.117.59.81"), Port: 80}))
+	assert.False(t, allowed(socks5.AddrSpec{FQDN: "example.com", IP: net.ParseIP("93.184.216.34"), Port: 443}))
+}
+
+func Test_dialUnprotected(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	_, err = dialUnprotected(nil)(context.Background(), "tcp", listener.Addr().String())
+	assert.Error(t, err)
+}
+
+// serviceConnPair returns UDP conns connected to each other, as p2p channel ones are.
+func serviceConnPair(t *testing.T) (*net.UDPConn, *net.UDPConn) {
+	addrA, addrB := freeUDPAddr(t), freeUDPAddr(t)
+	connA, err := net.DialUDP("udp4", addrA, addrB)
+	assert.NoError(t, err)
+	connB, err := net.DialUDP("udp4", addrB, addrA)
+	assert.NoError(t, err)
+	return connA, connB
+}
+
+func freeUDPAddr(t *testing.T) *net.UDPAddr {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	assert.NoError(t, err)
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr)
+}
+
+// usually time.Sleep call gives a chance for other goroutines to kick in important when testing async code
+func waitABit() {
+	time.Sleep(50 * time.Millisecond)
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package socks5
+
+import (
+	"net"
+	"sync/atomic"
+
+	"github.com/libp2p/go-yamux"
+	"github.com/rs/zerolog/log"
+	kcp "github.com/xtaci/kcp-go/v5"
+)
+
+const kcpMTUSize = 1280
+
+// serviceConn adapts connected service UDP conn to the packet conn KCP works with,
+// as writes with address are not allowed on connected UDP conns.
+type serviceConn struct {
+	*net.UDPConn
+}
+
+// ReadFrom reads the packet from the peer.
+func (c serviceConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	n, err := c.Read(b)
+	return n, c.RemoteAddr(), err
+}
+
+// WriteTo writes the packet to the peer, the address is ignored.
+func (c serviceConn) WriteTo(b []byte, _ net.Addr) (int, error) {
+	return c.Write(b)
+}
+
+// listen serves KCP sessions over the service conn, it closes the conn once closed.
+func listen(conn *net.UDPConn, key []byte) (*kcp.Listener, error) {
+	blockCrypt, err := kcp.NewSalsa20BlockCrypt(key)
+	if err != nil {
+		return nil, err
+	}
+	return kcp.ServeConn(blockCrypt, 10, 3, serviceConn{conn})
+}
+
+// dial opens KCP session over the service conn, it closes the conn once closed.
+func dial(conn *net.UDPConn, key []byte) (*kcp.UDPSession, error) {
+	blockCrypt, err := kcp.NewSalsa20BlockCrypt(key)
+	if err != nil {
+		return nil, err
+	}
+	sess, err := kcp.NewConn3(1, conn.RemoteAddr(), blockCrypt, 10, 3, serviceConn{conn})
+	if err != nil {
+		return nil, err
+	}
+	sess.SetMtu(kcpMTUSize)
+	return sess, nil
+}
+
+func muxConfig() *yamux.Config {
+	config := yamux.DefaultConfig()
+	config.LogOutput = log.Logger
+	return config
+}
+
+// stats counts the bytes transferred through the proxy.
+type stats struct {
+	sent, received uint64
+}
+
+func (s *stats) counted(conn net.Conn) net.Conn {
+	return &countedConn{Conn: conn, stats: s}
+}
+
+func (s *stats) get() (sent, received uint64) {
+	return atomic.LoadUint64(&s.sent), atomic.LoadUint64(&s.received)
+}
+
+// countedConn counts the bytes read from and written to the conn.
+type countedConn struct {
+	net.Conn
+	stats *stats
+}
+
+// Read reads data from the connection.
+func (c *countedConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	atomic.AddUint64(&c.stats.received, uint64(n))
+	return n, err
+}
+
+// Write writes data to the connection.
+func (c *countedConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	atomic.AddUint64(&c.stats.sent, uint64(n))
+	return n, err
+}
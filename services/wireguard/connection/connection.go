@@ -24,9 +24,11 @@ import (
 	"sync"
 	"time"
 
+	miekgdns "github.com/miekg/dns"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/firewall"
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
 	"github.com/mysteriumnetwork/node/services/wireguard/key"
@@ -36,27 +38,35 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// dnsBlockingScope counts the DNS queries blocked for consumer connections.
+const dnsBlockingScope = "consumer"
+
 // Options represents connection options.
 type Options struct {
 	DNSScriptDir     string
 	HandshakeTimeout time.Duration
+	// Blocklist filters DNS queries of the connections, nil when no blocklists are configured
+	Blocklist *dns.Blocklist
+	// DNSBlocking is the default for the connections not choosing whether to filter DNS queries
+	DNSBlocking bool
 }
 
 // NewConnection returns new WireGuard connection.
-func NewConnection(opts Options, ipResolver ip.Resolver, endpointFactory wg.EndpointFactory, handshakeWaiter HandshakeWaiter) (connection.Connection, error) {
+func NewConnection(opts Options, ipResolver ip.Resolver, endpointFactory wg.EndpointFactory, proxyEndpointFactory wg.ProxyEndpointFactory, handshakeWaiter HandshakeWaiter) (connection.Connection, error) {
 	privateKey, err := key.GeneratePrivateKey()
 	if err != nil {
 		return nil, errors.Wrap(err, "could not generate private key")
 	}
 
 	return &Connection{
-		done:                make(chan struct{}),
-		stateCh:             make(chan connectionstate.State, 100),
-		privateKey:          privateKey,
-		opts:                opts,
-		ipResolver:          ipResolver,
-		connEndpointFactory: endpointFactory,
-		handshakeWaiter:     handshakeWaiter,
+		done:                 make(chan struct{}),
+		stateCh:              make(chan connectionstate.State, 100),
+		privateKey:           privateKey,
+		opts:                 opts,
+		ipResolver:           ipResolver,
+		connEndpointFactory:  endpointFactory,
+		proxyEndpointFactory: proxyEndpointFactory,
+		handshakeWaiter:      handshakeWaiter,
 	}, nil
 }
 
@@ -74,9 +84,14 @@ type Connection struct {
 	opts                Options
 	connEndpointFactory wg.EndpointFactory
 	handshakeWaiter     HandshakeWaiter
+
+	proxyEndpointFactory wg.ProxyEndpointFactory
+	proxies              *localProxies
+	dnsProxy             *dns.Proxy
 }
 
-var _ connection.Connection = &Connection{}
+var _ connection.TunnelConnection = &Connection{}
+var _ connection.ProxyConnection = &Connection{}
 
 // State returns connection state channel.
 func (c *Connection) State() <-chan connectionstate.State {
@@ -103,11 +118,15 @@ func (c *Connection) Start(ctx context.Context, options connection.ConnectOption
 		return errors.Wrap(err, "failed to unmarshal connection config")
 	}
 
-	removeAllowedIPRule, err := firewall.AllowIPAccess(config.Provider.Endpoint.IP.String())
-	if err != nil {
-		return errors.Wrap(err, "failed to add firewall exception for wireguard remote IP")
+	// Nested connection reaches its provider through the outer tunnel and proxy mode leaves the system traffic intact,
+	// so no exception is needed.
+	if options.OuterIface == "" && options.Params.Proxy == nil {
+		removeAllowedIPRule, err := firewall.AllowIPAccess(config.Provider.Endpoint.IP.String())
+		if err != nil {
+			return errors.Wrap(err, "failed to add firewall exception for wireguard remote IP")
+		}
+		c.removeAllowedIPRule = removeAllowedIPRule
 	}
-	c.removeAllowedIPRule = removeAllowedIPRule
 
 	defer func() {
 		if err != nil {
@@ -120,22 +139,48 @@ func (c *Connection) Start(ctx context.Context, options connection.ConnectOption
 	if options.ProviderNATConn != nil {
 		options.ProviderNATConn.Close()
 		config.LocalPort = options.ProviderNATConn.LocalAddr().(*net.UDPAddr).Port
-		config.Provider.Endpoint.Port = options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).Port
+		remoteAddr := options.ProviderNATConn.RemoteAddr().(*net.UDPAddr)
+		// Provider is reached via relay when NAT hole punching fails.
+		if !remoteAddr.IP.IsLoopback() {
+			config.Provider.Endpoint.IP = remoteAddr.IP
+		}
+		config.Provider.Endpoint.Port = remoteAddr.Port
 	}
 
-	dnsIPs, err := options.Params.DNS.ResolveIPs(config.Consumer.DNSIPs)
-	if err != nil {
-		return errors.Wrap(err, "could not resolve DNS IPs")
+	upstreams, encryptedDNS := options.Params.DNS.Encrypted()
+	var dnsIPs []string
+	if !encryptedDNS {
+		if dnsIPs, err = options.Params.DNS.ResolveIPs(config.Consumer.DNSIPs); err != nil {
+			return errors.Wrap(err, "could not resolve DNS IPs")
+		}
+	}
+
+	blockDNS := c.blocksDNS(options.Params)
+	localDNS := encryptedDNS || blockDNS
+	deviceDNS := dnsIPs
+	if localDNS {
+		if !encryptedDNS && len(dnsIPs) == 0 {
+			// System DNS servers are read before the connection replaces them by the local DNS proxy.
+			if dnsIPs, err = dns.ConfiguredServers(); err != nil {
+				return errors.Wrap(err, "could not read system DNS servers")
+			}
+		}
+		// Queries are sent to the local DNS proxy on the tunnel address, which filters and forwards them to the upstreams.
+		deviceDNS = []string{config.Consumer.IPAddress.IP.String()}
 	}
 
 	log.Info().Msg("Starting new connection")
-	conn, err := c.startConn(wgcfg.DeviceConfig{
-		IfaceName:    "", // Interface name will be generated by connection endpoint.
-		Subnet:       config.Consumer.IPAddress,
-		PrivateKey:   c.privateKey,
-		ListenPort:   config.LocalPort,
-		DNS:          dnsIPs,
-		DNSScriptDir: c.opts.DNSScriptDir,
+	conn, err := c.startConn(options.Params.Proxy != nil, wgcfg.DeviceConfig{
+		IfaceName:     "", // Interface name will be generated by connection endpoint.
+		Subnet:        config.Consumer.IPAddress,
+		Subnet6:       config.Consumer.IPv6Address,
+		PrivateKey:    c.privateKey,
+		ListenPort:    config.LocalPort,
+		DNS:           deviceDNS,
+		DNSScriptDir:  c.opts.DNSScriptDir,
+		OuterIface:    options.OuterIface,
+		IncludeRoutes: options.SplitTunnel.Include,
+		ExcludeRoutes: options.SplitTunnel.Exclude,
 		Peer: wgcfg.Peer{
 			Endpoint:               &config.Provider.Endpoint,
 			PublicKey:              config.Provider.PublicKey,
@@ -155,12 +200,29 @@ func (c *Connection) Start(ctx context.Context, options connection.ConnectOption
 		return errors.Wrap(err, "failed while waiting for a peer handshake")
 	}
 
+	if localDNS {
+		if err := c.startDNS(conn, upstreams, dnsIPs, blockDNS, config.Consumer.IPAddress.IP); err != nil {
+			return errors.Wrap(err, "could not start local DNS")
+		}
+	}
+
+	if proxy, ok := conn.(wg.ProxyConnectionEndpoint); ok {
+		c.proxies, err = startLocalProxies(*options.Params.Proxy, proxy.DialContext)
+		if err != nil {
+			return errors.Wrap(err, "could not start local proxies")
+		}
+	}
+
 	c.stateCh <- connectionstate.Connected
 	return nil
 }
 
-func (c *Connection) startConn(conf wgcfg.DeviceConfig) (wg.ConnectionEndpoint, error) {
-	conn, err := c.connEndpointFactory()
+func (c *Connection) startConn(proxy bool, conf wgcfg.DeviceConfig) (conn wg.ConnectionEndpoint, err error) {
+	if proxy {
+		conn, err = c.proxyEndpointFactory()
+	} else {
+		conn, err = c.connEndpointFactory()
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create new connection endpoint")
 	}
@@ -173,6 +235,62 @@ func (c *Connection) startConn(conf wgcfg.DeviceConfig) (wg.ConnectionEndpoint,
 	return conn, nil
 }
 
+// blocksDNS tells whether DNS queries of the connection are filtered by the blocklist.
+func (c *Connection) blocksDNS(params connection.ConnectParams) bool {
+	if c.opts.Blocklist == nil {
+		return false
+	}
+	if params.DNSBlocking != nil {
+		return *params.DNSBlocking
+	}
+	return c.opts.DNSBlocking
+}
+
+// startDNS serves DNS queries by the encrypted upstreams, or by the plain DNS servers when there are none,
+// reaching them through the tunnel. The names in the blocklist are answered locally if blocking is enabled.
+func (c *Connection) startDNS(conn wg.ConnectionEndpoint, upstreams, servers []string, block bool, listenIP net.IP) (err error) {
+	proxy, isProxy := conn.(wg.ProxyConnectionEndpoint)
+	var dial dns.DialContextFunc = (&net.Dialer{}).DialContext
+	if isProxy {
+		dial = proxy.DialContext
+	}
+
+	var handler miekgdns.Handler
+	if len(upstreams) > 0 {
+		if handler, err = dns.ResolveViaEncrypted(upstreams, dial); err != nil {
+			return err
+		}
+	} else {
+		handler = dns.ResolveViaServers(servers, dial)
+	}
+	if block {
+		handler = dns.BlockByList(handler, c.opts.Blocklist, dnsBlockingScope)
+	}
+
+	if isProxy {
+		proxy.UseResolver(dns.NewResolver(handler))
+		return nil
+	}
+	c.dnsProxy = dns.NewProxy(listenIP.String(), 53, handler)
+	return c.dnsProxy.Run()
+}
+
+// InterfaceName returns the name of the wireguard interface of the started connection.
+func (c *Connection) InterfaceName() string {
+	if c.connectionEndpoint == nil {
+		return ""
+	}
+	return c.connectionEndpoint.InterfaceName()
+}
+
+// ProxyAddresses returns the addresses of the local proxies, if the connection was started in proxy mode.
+func (c *Connection) ProxyAddresses() []net.Addr {
+	if c.proxies == nil {
+		return nil
+	}
+	return c.proxies.addresses()
+}
+
 // Wait blocks until wireguard connection not stopped.
 func (c *Connection) Wait() error {
 	<-c.done
@@ -202,6 +320,16 @@ func (c *Connection) Stop() {
 			c.removeAllowedIPRule()
 		}
 
+		if c.proxies != nil {
+			c.proxies.stop()
+		}
+
+		if c.dnsProxy != nil {
+			if err := c.dnsProxy.Stop(); err != nil {
+				log.Error().Err(err).Msg("Failed to stop DNS proxy")
+			}
+		}
+
 		if c.connectionEndpoint != nil {
 			if err := c.connectionEndpoint.Stop(); err != nil {
 				log.Error().Err(err).Msg("Failed to close wireguard connection")
@@ -18,10 +18,15 @@
 package connection
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net"
+	"net/http"
+	"sync"
 	"testing"
 	"time"
 
@@ -31,6 +36,7 @@ import (
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/proxy"
 )
 
 func TestConnectionStartStop(t *testing.T) {
@@ -93,6 +99,77 @@ func TestConnectionStopOnceAfterHandshakeErrorAndStopCall(t *testing.T) {
 	assert.Equal(t, connectionstate.NotConnected, <-conn.State())
 }
 
+func TestConnectionProxyMode(t *testing.T) {
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer echo.Close()
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+			go io.Copy(conn, conn)
+		}
+	}()
+
+	conn := newConn(t)
+	endpoint := &mockProxyConnectionEndpoint{tunnelAddress: echo.Addr().String()}
+	conn.proxyEndpointFactory = func() (wg.ProxyConnectionEndpoint, error) {
+		return endpoint, nil
+	}
+	sessionConfig, _ := json.Marshal(newServiceConfig())
+	err = conn.Start(context.Background(), connection.ConnectOptions{
+		Params: connection.ConnectParams{
+			DNS:   "1.2.3.4",
+			Proxy: &connection.ProxyParams{SOCKS5Address: "127.0.0.1:0", HTTPAddress: "127.0.0.1:0"},
+		},
+		SessionConfig: sessionConfig,
+	})
+	assert.NoError(t, err)
+	defer conn.Stop()
+	assert.Equal(t, connectionstate.Connecting, <-conn.State())
+	assert.Equal(t, connectionstate.Connected, <-conn.State())
+
+	addrs := conn.ProxyAddresses()
+	assert.Len(t, addrs, 2)
+
+	// SOCKS5 proxy leaves the host name for the tunnel to resolve.
+	dialer, err := proxy.SOCKS5("tcp", addrs[0].String(), nil, proxy.Direct)
+	assert.NoError(t, err)
+	socksConn, err := dialer.Dial("tcp", "socks.example.com:80")
+	assert.NoError(t, err)
+	assertEcho(t, socksConn)
+	assert.Equal(t, "socks.example.com:80", endpoint.dialed())
+
+	httpConn, err := net.Dial("tcp", addrs[1].String())
+	assert.NoError(t, err)
+	_, err = fmt.Fprint(httpConn, "CONNECT http.example.com:443 HTTP/1.1\r\nHost: http.example.com:443\r\n\r\n")
+	assert.NoError(t, err)
+	reader := bufio.NewReader(httpConn)
+	resp, err := http.ReadResponse(reader, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "http.example.com:443", endpoint.dialed())
+	_, err = httpConn.Write([]byte("ping"))
+	assert.NoError(t, err)
+	reply := make([]byte, 4)
+	_, err = io.ReadFull(reader, reply)
+	assert.NoError(t, err)
+	assert.Equal(t, "ping", string(reply))
+	httpConn.Close()
+}
+
+func assertEcho(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+	_, err := conn.Write([]byte("ping"))
+	assert.NoError(t, err)
+	reply := make([]byte, 4)
+	_, err = io.ReadFull(conn, reply)
+	assert.NoError(t, err)
+	assert.Equal(t, "ping", string(reply))
+}
+
 func newConn(t *testing.T) *Connection {
 	endpointFactory := func() (wg.ConnectionEndpoint, error) {
 		return &mockConnectionEndpoint{}, nil
@@ -100,7 +177,10 @@ func newConn(t *testing.T) *Connection {
 	opts := Options{
 		DNSScriptDir: "/dns/dir",
 	}
-	conn, err := NewConnection(opts, ip.NewResolverMock("172.44.1.12"), endpointFactory, &mockHandshakeWaiter{})
+	proxyEndpointFactory := func() (wg.ProxyConnectionEndpoint, error) {
+		return &mockProxyConnectionEndpoint{}, nil
+	}
+	conn, err := NewConnection(opts, ip.NewResolverMock("172.44.1.12"), endpointFactory, proxyEndpointFactory, &mockHandshakeWaiter{})
 	assert.NoError(t, err)
 	return conn.(*Connection)
 }
@@ -118,8 +198,9 @@ func newServiceConfig() wg.ServiceConfig {
 			Endpoint:  *endpoint,
 		},
 		Consumer: struct {
-			IPAddress net.IPNet
-			DNSIPs    string
+			IPAddress   net.IPNet
+			IPv6Address net.IPNet
+			DNSIPs      string
 		}{
 			IPAddress: net.IPNet{
 				IP:   net.IPv4(127, 0, 0, 1),
@@ -146,6 +227,32 @@ func (mce *mockConnectionEndpoint) PeerStats() (*wgcfg.Stats, error) {
 	return &wgcfg.Stats{LastHandshake: time.Now(), BytesSent: 10, BytesReceived: 11}, nil
 }
 
+type mockProxyConnectionEndpoint struct {
+	mockConnectionEndpoint
+	tunnelAddress string
+
+	lock     sync.Mutex
+	address  string
+	resolver *net.Resolver
+}
+
+func (m *mockProxyConnectionEndpoint) UseResolver(resolver *net.Resolver) {
+	m.resolver = resolver
+}
+
+func (m *mockProxyConnectionEndpoint) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	m.lock.Lock()
+	m.address = address
+	m.lock.Unlock()
+	return (&net.Dialer{}).DialContext(ctx, network, m.tunnelAddress)
+}
+
+func (m *mockProxyConnectionEndpoint) dialed() string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.address
+}
+
 type mockHandshakeWaiter struct {
 	err error
 }
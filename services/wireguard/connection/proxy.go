@@ -0,0 +1,187 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"context"
+	"io"
+	stdlog "log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/armon/go-socks5"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	netcontext "golang.org/x/net/context"
+)
+
+type dialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// localProxies serves SOCKS5 and HTTP CONNECT proxies on the local addresses, dialing the destinations through the tunnel.
+type localProxies struct {
+	dial      dialFunc
+	listeners []net.Listener
+	// closers stop the proxies by closing either their listeners or servers owning the listeners.
+	closers []io.Closer
+	wg      sync.WaitGroup
+}
+
+func startLocalProxies(params connection.ProxyParams, dial dialFunc) (_ *localProxies, err error) {
+	p := &localProxies{dial: dial}
+	defer func() {
+		if err != nil {
+			p.stop()
+		}
+	}()
+
+	if params.SOCKS5Address != "" {
+		server, err := socks5.New(&socks5.Config{
+			Resolver: tunnelResolver{},
+			Dial: func(ctx netcontext.Context, network, addr string) (net.Conn, error) {
+				return dial(ctx, network, addr)
+			},
+			Logger: stdlog.New(log.Logger, "", 0),
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create SOCKS5 proxy")
+		}
+		listener, err := p.listen(params.SOCKS5Address)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not start SOCKS5 proxy")
+		}
+		p.closers = append(p.closers, listener)
+		p.serve(func() error { return server.Serve(listener) })
+	}
+
+	if params.HTTPAddress != "" {
+		server := &http.Server{
+			Handler:  http.HandlerFunc(p.serveConnect),
+			ErrorLog: stdlog.New(log.Logger, "", 0),
+		}
+		listener, err := p.listen(params.HTTPAddress)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not start HTTP proxy")
+		}
+		p.closers = append(p.closers, server)
+		p.serve(func() error { return server.Serve(listener) })
+	}
+
+	return p, nil
+}
+
+func (p *localProxies) listen(address string) (net.Listener, error) {
+	if err := connection.ValidateProxyAddress(address); err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	p.listeners = append(p.listeners, listener)
+	log.Info().Msgf("Local proxy listening on %s", listener.Addr())
+	return listener, nil
+}
+
+func (p *localProxies) serve(serve func() error) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		if err := serve(); err != nil && err != http.ErrServerClosed {
+			log.Debug().Err(err).Msg("Local proxy stopped")
+		}
+	}()
+}
+
+// serveConnect tunnels the connection of HTTP CONNECT request to the requested host.
+func (p *localProxies) serveConnect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodConnect {
+		http.Error(w, "only CONNECT method is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+	remote, err := p.dial(ctx, "tcp", r.Host)
+	if err != nil {
+		log.Debug().Err(err).Msgf("Could not connect to %s", r.Host)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer remote.Close()
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "hijacking is not supported", http.StatusInternalServerError)
+		return
+	}
+	local, buffered, err := hijacker.Hijack()
+	if err != nil {
+		log.Debug().Err(err).Msg("Could not hijack HTTP connection")
+		return
+	}
+	defer local.Close()
+
+	if _, err := local.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+		return
+	}
+	// The client may start sending data right after the request, before reading the response.
+	if n := buffered.Reader.Buffered(); n > 0 {
+		data, _ := buffered.Reader.Peek(n)
+		if _, err := remote.Write(data); err != nil {
+			return
+		}
+	}
+
+	done := make(chan struct{}, 2)
+	relay := func(dst, src net.Conn) {
+		io.Copy(dst, src)
+		done <- struct{}{}
+	}
+	go relay(remote, local)
+	go relay(local, remote)
+	<-done
+}
+
+// addresses returns the addresses the proxies listen on.
+func (p *localProxies) addresses() []net.Addr {
+	addrs := make([]net.Addr, 0, len(p.listeners))
+	for _, listener := range p.listeners {
+		addrs = append(addrs, listener.Addr())
+	}
+	return addrs
+}
+
+func (p *localProxies) stop() {
+	for _, closer := range p.closers {
+		if err := closer.Close(); err != nil {
+			log.Debug().Err(err).Msg("Failed to close local proxy")
+		}
+	}
+	p.wg.Wait()
+}
+
+// tunnelResolver leaves names unresolved for them to be resolved through the tunnel.
+type tunnelResolver struct{}
+
+// Resolve returns no IP, so the name is passed to the dialer.
+func (tunnelResolver) Resolve(ctx netcontext.Context, _ string) (netcontext.Context, net.IP, error) {
+	return ctx, nil, nil
+}
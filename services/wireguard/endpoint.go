@@ -18,6 +18,9 @@
 package wireguard
 
 import (
+	"context"
+	"net"
+
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
 )
 
@@ -34,3 +37,15 @@ type ConnectionEndpoint interface {
 	InterfaceName() string
 	Stop() error
 }
+
+// ProxyEndpointFactory creates new connection endpoint which terminates the tunnel in the userspace network stack.
+type ProxyEndpointFactory func() (ProxyConnectionEndpoint, error)
+
+// ProxyConnectionEndpoint represents Wireguard network instance terminated in the userspace network stack,
+// so that the tunnel is reachable only through the connections it dials, not the system routes.
+type ProxyConnectionEndpoint interface {
+	ConnectionEndpoint
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+	// UseResolver makes the host names dialed through the tunnel resolved by the given resolver.
+	UseResolver(resolver *net.Resolver)
+}
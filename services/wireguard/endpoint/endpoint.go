@@ -88,6 +88,9 @@ func (ce *connectionEndpoint) StartProviderMode(publicIP string, config wgcfg.De
 
 	config.IfaceName = iface
 	config.Subnet.IP = netutil.FirstIP(config.Subnet)
+	if config.Subnet6.IP != nil {
+		config.Subnet6.IP = netutil.FirstIP(config.Subnet6)
+	}
 	ce.cfg = config
 	ce.endpoint = net.UDPAddr{IP: net.ParseIP(publicIP), Port: config.ListenPort}
 
@@ -119,9 +122,19 @@ func (ce *connectionEndpoint) Config() (wg.ServiceConfig, error) {
 	config.Provider.Endpoint = ce.endpoint
 	config.Consumer.IPAddress = ce.cfg.Subnet
 	config.Consumer.IPAddress.IP = ce.consumerIP(ce.cfg.Subnet)
+	if ce.cfg.Subnet6.IP != nil {
+		config.Consumer.IPv6Address = net.IPNet{IP: consumerIPv6(ce.cfg.Subnet6), Mask: ce.cfg.Subnet6.Mask}
+	}
 	return config, nil
 }
 
+// consumerIPv6 returns the second address of the IPv6 subnet, the first one is taken by the provider.
+func consumerIPv6(subnet net.IPNet) net.IP {
+	ip := subnet.IP.Mask(subnet.Mask)
+	ip[len(ip)-1] = byte(2)
+	return ip
+}
+
 // Stop closes wireguard client and destroys wireguard network interface.
 func (ce *connectionEndpoint) Stop() error {
 	if err := ce.wgClient.Close(); err != nil {
@@ -61,12 +61,20 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) error {
 	deviceConfig.PrivateKey = &privateKey
 	deviceConfig.ListenPort = &port
 
-	if err := c.up(config.IfaceName, config.Subnet); err != nil {
+	if err := c.up(config.IfaceName, config.Subnet, config.Subnet6); err != nil {
 		return err
 	}
 
 	if config.Peer.Endpoint != nil {
-		if err := configureRoutes(config.IfaceName, config.Peer.Endpoint.IP); err != nil {
+		err := netutil.ConfigureTunnelRoutes(netutil.TunnelRoutes{
+			Iface:      config.IfaceName,
+			Peer:       config.Peer.Endpoint.IP,
+			OuterIface: config.OuterIface,
+			Include:    config.IncludeRoutes,
+			Exclude:    config.ExcludeRoutes,
+			IPv6:       config.Subnet6.IP != nil,
+		})
+		if err != nil {
 			return err
 		}
 	}
@@ -143,7 +151,7 @@ func (c *client) DestroyDevice(name string) error {
 	return cmdutil.SudoExec("ip", "link", "del", "dev", name)
 }
 
-func (c *client) up(iface string, ipAddr net.IPNet) error {
+func (c *client) up(iface string, ipAddr, ipAddr6 net.IPNet) error {
 	if d, err := c.wgClient.Device(iface); err != nil || d.Name != iface {
 		if err := cmdutil.SudoExec("ip", "link", "add", "dev", iface, "type", "wireguard"); err != nil {
 			return err
@@ -154,6 +162,12 @@ func (c *client) up(iface string, ipAddr net.IPNet) error {
 		return err
 	}
 
+	if ipAddr6.IP != nil {
+		if err := cmdutil.SudoExec("ip", "-6", "address", "replace", "dev", iface, ipAddr6.String()); err != nil {
+			return err
+		}
+	}
+
 	return cmdutil.SudoExec("ip", "link", "set", "dev", iface, "up")
 }
 
@@ -174,13 +188,6 @@ func (c *client) Close() (err error) {
 	return nil
 }
 
-func configureRoutes(iface string, ip net.IP) error {
-	if err := netutil.ExcludeRoute(ip); err != nil {
-		return err
-	}
-	return netutil.AddDefaultRoute(iface)
-}
-
 func stringToKey(key string) (wgtypes.Key, error) {
 	k, err := base64.StdEncoding.DecodeString(key)
 	if err != nil {
@@ -0,0 +1,110 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netstack
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	wg "github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/services/wireguard/endpoint/userspace"
+	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
+	"golang.zx2c4.com/wireguard/device"
+)
+
+// ErrProviderMode indicates that the provider can not terminate tunnels in the userspace network stack.
+var ErrProviderMode = errors.New("provider mode is not supported by the userspace network stack")
+
+// NewConnectionEndpoint returns new connection endpoint, which runs WireGuard and the network stack
+// inside the process, so it neither needs privileges nor touches the system routes and DNS.
+func NewConnectionEndpoint() (wg.ProxyConnectionEndpoint, error) {
+	return &connectionEndpoint{}, nil
+}
+
+type connectionEndpoint struct {
+	tun    *netTun
+	net    *Net
+	devAPI *device.Device
+}
+
+// StartConsumerMode starts WireGuard device on top of the userspace network stack.
+func (ce *connectionEndpoint) StartConsumerMode(config wgcfg.DeviceConfig) (err error) {
+	addresses := []net.IP{config.Subnet.IP}
+	if config.Subnet6.IP != nil {
+		addresses = append(addresses, config.Subnet6.IP)
+	}
+	ce.tun, err = newNetTun(addresses, device.DefaultMTU)
+	if err != nil {
+		return fmt.Errorf("could not create userspace network stack: %w", err)
+	}
+	ce.net = newNet(ce.tun.stack, config.DNS)
+
+	ce.devAPI = device.NewDevice(ce.tun, device.NewLogger(device.LogLevelDebug, "[netstack-wg]"))
+	if err := ce.devAPI.IpcSetOperation(bufio.NewReader(strings.NewReader(config.Encode()))); err != nil {
+		ce.devAPI.Close()
+		return fmt.Errorf("could not configure device: %w", err)
+	}
+	ce.devAPI.Up()
+	return nil
+}
+
+// StartProviderMode is not supported, providers always need the system network stack to forward traffic.
+func (ce *connectionEndpoint) StartProviderMode(string, wgcfg.DeviceConfig) error {
+	return ErrProviderMode
+}
+
+// PeerStats returns stats information about connected peer.
+func (ce *connectionEndpoint) PeerStats() (*wgcfg.Stats, error) {
+	deviceState, err := userspace.ParseUserspaceDevice(ce.devAPI.IpcGetOperation)
+	if err != nil {
+		return nil, err
+	}
+	return userspace.ParseDevicePeerStats(deviceState)
+}
+
+// Config is not supported, since it describes the provider side of the tunnel.
+func (ce *connectionEndpoint) Config() (wg.ServiceConfig, error) {
+	return wg.ServiceConfig{}, ErrProviderMode
+}
+
+// InterfaceName returns an empty name, since there is no system interface behind the endpoint.
+func (ce *connectionEndpoint) InterfaceName() string {
+	return ""
+}
+
+// DialContext connects to the address on the named network through the tunnel.
+func (ce *connectionEndpoint) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return ce.net.DialContext(ctx, network, address)
+}
+
+// UseResolver makes the host names dialed through the tunnel resolved by the given resolver.
+func (ce *connectionEndpoint) UseResolver(resolver *net.Resolver) {
+	ce.net.resolver = resolver
+}
+
+// Stop closes WireGuard device together with the network stack.
+func (ce *connectionEndpoint) Stop() error {
+	if ce.devAPI != nil {
+		ce.devAPI.Close() // Closes the network stack too.
+	}
+	return nil
+}
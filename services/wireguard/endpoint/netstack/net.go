@@ -0,0 +1,130 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netstack
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/adapters/gonet"
+	"gvisor.dev/gvisor/pkg/tcpip/network/ipv4"
+	"gvisor.dev/gvisor/pkg/tcpip/network/ipv6"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+)
+
+// Net dials connections through the userspace network stack.
+type Net struct {
+	stack    *stack.Stack
+	resolver *net.Resolver
+}
+
+// newNet creates dialer of given network stack. Host names are resolved by given DNS servers
+// through the same stack, or by the system resolver if there are none.
+func newNet(s *stack.Stack, dnsServers []string) *Net {
+	n := &Net{stack: s, resolver: net.DefaultResolver}
+	if len(dnsServers) > 0 {
+		server := net.JoinHostPort(dnsServers[0], "53")
+		n.resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return n.dial(ctx, network, server)
+			},
+		}
+	}
+	return n
+}
+
+// DialContext connects to the address on the named network through the network stack.
+// Only TCP and UDP networks are supported.
+func (n *Net) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ips, err := n.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ip := range ips {
+		if !matchesNetwork(network, ip.IP) {
+			continue
+		}
+		var conn net.Conn
+		if conn, err = n.dial(ctx, network, net.JoinHostPort(ip.String(), port)); err == nil {
+			return conn, nil
+		}
+	}
+	if err == nil {
+		err = fmt.Errorf("no suitable address found for %s", host)
+	}
+	return nil, &net.OpError{Op: "dial", Net: network, Err: err}
+}
+
+func (n *Net) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q: %w", portStr, err)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", host)
+	}
+
+	addr, protocol := fullAddress(ip, uint16(port))
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+		conn, err := gonet.DialContextTCP(ctx, n.stack, addr, protocol)
+		if err != nil {
+			return nil, err
+		}
+		return conn, nil
+	case "udp", "udp4", "udp6":
+		conn, err := gonet.DialUDP(n.stack, nil, &addr, protocol)
+		if err != nil {
+			return nil, err
+		}
+		return conn, nil
+	}
+	return nil, net.UnknownNetworkError(network)
+}
+
+func fullAddress(ip net.IP, port uint16) (tcpip.FullAddress, tcpip.NetworkProtocolNumber) {
+	if ip4 := ip.To4(); ip4 != nil {
+		return tcpip.FullAddress{NIC: nicID, Addr: tcpip.AddrFrom4Slice(ip4), Port: port}, ipv4.ProtocolNumber
+	}
+	return tcpip.FullAddress{NIC: nicID, Addr: tcpip.AddrFrom16Slice(ip.To16()), Port: port}, ipv6.ProtocolNumber
+}
+
+func matchesNetwork(network string, ip net.IP) bool {
+	switch network {
+	case "tcp4", "udp4":
+		return ip.To4() != nil
+	case "tcp6", "udp6":
+		return ip.To4() == nil
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netstack
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.zx2c4.com/wireguard/device"
+	"gvisor.dev/gvisor/pkg/tcpip/adapters/gonet"
+)
+
+func TestNet_DialContext(t *testing.T) {
+	consumer, err := newNetTun([]net.IP{net.ParseIP("10.182.0.2")}, device.DefaultMTU)
+	require.NoError(t, err)
+	defer consumer.Close()
+	provider, err := newNetTun([]net.IP{net.ParseIP("10.182.0.1")}, device.DefaultMTU)
+	require.NoError(t, err)
+	defer provider.Close()
+
+	// Packets sent by one stack are received by the other one, as if the tunnel was between them.
+	go forward(consumer, provider)
+	go forward(provider, consumer)
+
+	listenAddr, protocol := fullAddress(net.ParseIP("10.182.0.1"), 8080)
+	listener, err := gonet.ListenTCP(provider.stack, listenAddr, protocol)
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.Copy(conn, conn)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := newNet(consumer.stack, nil).DialContext(ctx, "tcp", "10.182.0.1:8080")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping"))
+	assert.NoError(t, err)
+	reply := make([]byte, 4)
+	_, err = io.ReadFull(conn, reply)
+	assert.NoError(t, err)
+	assert.Equal(t, "ping", string(reply))
+}
+
+func TestNet_DialContextUnsupportedNetwork(t *testing.T) {
+	dev, err := newNetTun([]net.IP{net.ParseIP("10.182.0.2")}, device.DefaultMTU)
+	require.NoError(t, err)
+	defer dev.Close()
+
+	_, err = newNet(dev.stack, nil).DialContext(context.Background(), "unix", "10.182.0.1:8080")
+	assert.Error(t, err)
+}
+
+func forward(from, to *netTun) {
+	buf := make([]byte, device.MaxMessageSize)
+	for {
+		n, err := from.Read(buf, 0)
+		if err != nil {
+			return
+		}
+		if _, err := to.Write(buf[:n], 0); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netstack
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+
+	"golang.zx2c4.com/wireguard/tun"
+	"gvisor.dev/gvisor/pkg/buffer"
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+	"gvisor.dev/gvisor/pkg/tcpip/link/channel"
+	"gvisor.dev/gvisor/pkg/tcpip/network/ipv4"
+	"gvisor.dev/gvisor/pkg/tcpip/network/ipv6"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+	"gvisor.dev/gvisor/pkg/tcpip/transport/tcp"
+	"gvisor.dev/gvisor/pkg/tcpip/transport/udp"
+)
+
+const nicID = 1
+
+// netTun is a TUN device backed by the userspace network stack instead of the system one.
+// Packets written by WireGuard are injected into the stack and packets sent by the stack are read back by WireGuard.
+type netTun struct {
+	ep       *channel.Endpoint
+	stack    *stack.Stack
+	events   chan tun.Event
+	incoming chan *buffer.View
+	mtu      int
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// newNetTun creates the userspace network stack with given local addresses, routing all traffic to the TUN device.
+func newNetTun(addresses []net.IP, mtu int) (*netTun, error) {
+	dev := &netTun{
+		ep: channel.New(1024, uint32(mtu), ""),
+		stack: stack.New(stack.Options{
+			NetworkProtocols:   []stack.NetworkProtocolFactory{ipv4.NewProtocol, ipv6.NewProtocol},
+			TransportProtocols: []stack.TransportProtocolFactory{tcp.NewProtocol, udp.NewProtocol},
+			HandleLocal:        true,
+		}),
+		events:   make(chan tun.Event, 10),
+		incoming: make(chan *buffer.View),
+		mtu:      mtu,
+		closed:   make(chan struct{}),
+	}
+
+	sackEnabled := tcpip.TCPSACKEnabled(true)
+	if err := dev.stack.SetTransportProtocolOption(tcp.ProtocolNumber, &sackEnabled); err != nil {
+		return nil, fmt.Errorf("could not enable TCP SACK: %v", err)
+	}
+
+	dev.ep.AddNotify(dev)
+	if err := dev.stack.CreateNIC(nicID, dev.ep); err != nil {
+		return nil, fmt.Errorf("could not create NIC: %v", err)
+	}
+
+	for _, ip := range addresses {
+		protocol, addr := ipv6.ProtocolNumber, tcpip.AddrFrom16Slice(ip.To16())
+		route := header.IPv6EmptySubnet
+		if ip4 := ip.To4(); ip4 != nil {
+			protocol, addr = ipv4.ProtocolNumber, tcpip.AddrFrom4Slice(ip4)
+			route = header.IPv4EmptySubnet
+		}
+
+		protocolAddr := tcpip.ProtocolAddress{Protocol: protocol, AddressWithPrefix: addr.WithPrefix()}
+		if err := dev.stack.AddProtocolAddress(nicID, protocolAddr, stack.AddressProperties{}); err != nil {
+			return nil, fmt.Errorf("could not add address %s: %v", ip, err)
+		}
+		dev.stack.AddRoute(tcpip.Route{Destination: route, NIC: nicID})
+	}
+
+	dev.events <- tun.EventUp
+	return dev, nil
+}
+
+// File returns nil, since there is no file descriptor behind the userspace network stack.
+func (t *netTun) File() *os.File {
+	return nil
+}
+
+// Read reads the next packet sent by the network stack.
+func (t *netTun) Read(buf []byte, offset int) (int, error) {
+	select {
+	case view := <-t.incoming:
+		defer view.Release()
+		return view.Read(buf[offset:])
+	case <-t.closed:
+		return 0, os.ErrClosed
+	}
+}
+
+// Write injects the packet received from the tunnel into the network stack.
+func (t *netTun) Write(buf []byte, offset int) (int, error) {
+	packet := buf[offset:]
+	if len(packet) == 0 {
+		return 0, nil
+	}
+
+	pkt := stack.NewPacketBuffer(stack.PacketBufferOptions{Payload: buffer.MakeWithData(packet)})
+	defer pkt.DecRef()
+
+	switch header.IPVersion(packet) {
+	case header.IPv4Version:
+		t.ep.InjectInbound(header.IPv4ProtocolNumber, pkt)
+	case header.IPv6Version:
+		t.ep.InjectInbound(header.IPv6ProtocolNumber, pkt)
+	default:
+		return 0, syscall.EAFNOSUPPORT
+	}
+	return len(buf), nil
+}
+
+// WriteNotify is called by the channel endpoint once the network stack has a packet to send.
+func (t *netTun) WriteNotify() {
+	pkt := t.ep.Read()
+	if pkt.IsNil() {
+		return
+	}
+
+	view := pkt.ToView()
+	pkt.DecRef()
+
+	select {
+	case t.incoming <- view:
+	case <-t.closed:
+		view.Release()
+	}
+}
+
+// Flush does nothing, since packets are not buffered.
+func (t *netTun) Flush() error {
+	return nil
+}
+
+// MTU returns the MTU of the device.
+func (t *netTun) MTU() (int, error) {
+	return t.mtu, nil
+}
+
+// Name returns the name of the device.
+func (t *netTun) Name() (string, error) {
+	return "netstack", nil
+}
+
+// Events returns the channel of device events.
+func (t *netTun) Events() chan tun.Event {
+	return t.events
+}
+
+// Close stops the network stack.
+func (t *netTun) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.closed)
+		t.stack.RemoveNIC(nicID)
+		t.ep.Close()
+		close(t.events)
+	})
+	return nil
+}
@@ -47,6 +47,11 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) (err error) {
 	if c.tun, err = CreateTUN(config.IfaceName, config.Subnet); err != nil {
 		return errors.Wrap(err, "failed to create TUN device")
 	}
+	if config.Subnet6.IP != nil {
+		if err := netutil.AssignIP(config.IfaceName, config.Subnet6); err != nil {
+			return errors.Wrap(err, "failed to assign IPv6 address")
+		}
+	}
 
 	c.devAPI = device.NewDevice(c.tun, device.NewLogger(device.LogLevelDebug, "[userspace-wg]"))
 	if err := c.setDeviceConfig(config.Encode()); err != nil {
@@ -56,13 +61,18 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) (err error) {
 	c.devAPI.Up()
 
 	// For consumer mode we need to exclude provider's IP from VPN tunnel
-	// and add default routes to forward all traffic via VPN tunnel.
+	// and add routes to forward traffic via VPN tunnel.
 	if config.Peer.Endpoint != nil {
-		if err := netutil.ExcludeRoute(config.Peer.Endpoint.IP); err != nil {
-			return fmt.Errorf("could not exclude route %s: %w", config.Peer.Endpoint.IP.String(), err)
-		}
-		if err := netutil.AddDefaultRoute(config.IfaceName); err != nil {
-			return fmt.Errorf("could not add default route for %s: %w", config.IfaceName, err)
+		err := netutil.ConfigureTunnelRoutes(netutil.TunnelRoutes{
+			Iface:      config.IfaceName,
+			Peer:       config.Peer.Endpoint.IP,
+			OuterIface: config.OuterIface,
+			Include:    config.IncludeRoutes,
+			Exclude:    config.ExcludeRoutes,
+			IPv6:       config.Subnet6.IP != nil,
+		})
+		if err != nil {
+			return err
 		}
 	}
 
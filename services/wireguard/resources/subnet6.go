@@ -0,0 +1,33 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package resources
+
+import "net"
+
+// MaxSubnet6PrefixLen is the longest prefix of IPv6 subnet, which still fits /64 network for every connection.
+const MaxSubnet6PrefixLen = 56
+
+// IPNet6 returns /64 network of the IPv6 subnet for the connection, which was given the IPv4 network.
+// Networks are indexed the same way IPv4 ones are, so they are allocated and released together.
+func IPNet6(subnet6 net.IPNet, ipnet net.IPNet) net.IPNet {
+	ip := subnet6.IP.Mask(subnet6.Mask)
+	if ip4 := ipnet.IP.To4(); ip4 != nil {
+		ip[7] = ip4[2]
+	}
+	return net.IPNet{IP: ip, Mask: net.CIDRMask(64, 8*net.IPv6len)}
+}
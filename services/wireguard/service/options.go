@@ -19,6 +19,7 @@ package service
 
 import (
 	"encoding/json"
+	"fmt"
 	"net"
 
 	"github.com/mysteriumnetwork/node/config"
@@ -32,6 +33,17 @@ import (
 type Options struct {
 	Ports  *port.Range
 	Subnet net.IPNet
+	// Subnet6 is the IPv6 network sessions get their /64 networks from, IPv6 is not provided when it is empty.
+	Subnet6 net.IPNet
+	// BandwidthKbps limits bandwidth of every session, sessions are not limited when zero
+	BandwidthKbps uint64
+	// DNSBlocking answers DNS queries of the names in the node blocklists by the provider itself
+	DNSBlocking bool
+}
+
+// SessionBandwidthKbps returns bandwidth limit of every session in Kbps.
+func (o Options) SessionBandwidthKbps() uint64 {
+	return o.BandwidthKbps
 }
 
 // DefaultOptions is a wireguard service configuration that will be used if no options provided.
@@ -51,6 +63,11 @@ func GetOptions() Options {
 		ipnet = &DefaultOptions.Subnet
 	}
 
+	subnet6, err := parseSubnet6(config.GetString(config.FlagWireguardListenSubnet6))
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to parse IPv6 subnet option, IPv6 will not be provided")
+	}
+
 	portRange, err := port.ParseRange(config.GetString(config.FlagWireguardListenPorts))
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to parse listen port range, using default value")
@@ -62,11 +79,33 @@ func GetOptions() Options {
 		portRange = port.UnspecifiedRange()
 	}
 	return Options{
-		Ports:  portRange,
-		Subnet: *ipnet,
+		Ports:         portRange,
+		Subnet:        *ipnet,
+		Subnet6:       subnet6,
+		BandwidthKbps: service.DefaultBandwidthKbps(),
+		DNSBlocking:   config.GetBool(config.FlagDNSBlocklistProvider),
 	}
 }
 
+// parseSubnet6 parses IPv6 subnet, which has to be large enough to allocate /64 network for every session.
+func parseSubnet6(subnet string) (net.IPNet, error) {
+	if subnet == "" {
+		return net.IPNet{}, nil
+	}
+
+	_, ipnet, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return net.IPNet{}, err
+	}
+	if ipnet.IP.To4() != nil {
+		return net.IPNet{}, fmt.Errorf("%s is not an IPv6 subnet", subnet)
+	}
+	if size, _ := ipnet.Mask.Size(); size > resources.MaxSubnet6PrefixLen {
+		return net.IPNet{}, fmt.Errorf("IPv6 subnet prefix must not be longer than /%d", resources.MaxSubnet6PrefixLen)
+	}
+	return *ipnet, nil
+}
+
 // ParseJSONOptions function fills in Wireguard options from JSON request
 func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
 	var requestOptions = GetOptions()
@@ -75,26 +114,42 @@ func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
 	}
 
 	opts := DefaultOptions
+	opts.BandwidthKbps = requestOptions.BandwidthKbps
+	opts.DNSBlocking = requestOptions.DNSBlocking
 	err := json.Unmarshal(*request, &opts)
 	return opts, err
 }
 
 // MarshalJSON implements json.Marshaler interface to provide human readable configuration.
 func (o Options) MarshalJSON() ([]byte, error) {
+	var subnet6 string
+	if o.Subnet6.IP != nil {
+		subnet6 = o.Subnet6.String()
+	}
+
 	return json.Marshal(&struct {
-		Ports  string `json:"ports"`
-		Subnet string `json:"subnet"`
+		Ports         string `json:"ports"`
+		Subnet        string `json:"subnet"`
+		Subnet6       string `json:"subnet6,omitempty"`
+		BandwidthKbps uint64 `json:"bandwidth_kbps"`
+		DNSBlocking   bool   `json:"dns_blocking"`
 	}{
-		Ports:  o.Ports.String(),
-		Subnet: o.Subnet.String(),
+		Ports:         o.Ports.String(),
+		Subnet:        o.Subnet.String(),
+		Subnet6:       subnet6,
+		BandwidthKbps: o.BandwidthKbps,
+		DNSBlocking:   o.DNSBlocking,
 	})
 }
 
 // UnmarshalJSON implements json.Unmarshaler interface to receive human readable configuration.
 func (o *Options) UnmarshalJSON(data []byte) error {
 	var options struct {
-		Ports  string `json:"ports"`
-		Subnet string `json:"subnet"`
+		Ports         string  `json:"ports"`
+		Subnet        string  `json:"subnet"`
+		Subnet6       string  `json:"subnet6"`
+		BandwidthKbps *uint64 `json:"bandwidth_kbps"`
+		DNSBlocking   *bool   `json:"dns_blocking"`
 	}
 
 	if err := json.Unmarshal(data, &options); err != nil {
@@ -115,6 +170,19 @@ func (o *Options) UnmarshalJSON(data []byte) error {
 		}
 		o.Subnet = *ipnet
 	}
+	if len(options.Subnet6) > 0 {
+		subnet6, err := parseSubnet6(options.Subnet6)
+		if err != nil {
+			return err
+		}
+		o.Subnet6 = subnet6
+	}
+	if options.BandwidthKbps != nil {
+		o.BandwidthKbps = *options.BandwidthKbps
+	}
+	if options.DNSBlocking != nil {
+		o.DNSBlocking = *options.DNSBlocking
+	}
 
 	return nil
 }
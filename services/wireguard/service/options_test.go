@@ -61,6 +61,32 @@ func Test_ParseJSONOptions_ValidRequest(t *testing.T) {
 	}, options)
 }
 
+func Test_ParseJSONOptions_BandwidthRequest(t *testing.T) {
+	configureDefaults()
+	request := json.RawMessage(`{"bandwidth_kbps": 10000}`)
+	options, err := ParseJSONOptions(&request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10000), options.(Options).SessionBandwidthKbps())
+
+	data, err := json.Marshal(options)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"ports":"0:0","subnet":"10.182.0.0/16","bandwidth_kbps":10000,"dns_blocking":false}`, string(data))
+}
+
+func Test_ParseJSONOptions_DNSBlockingRequest(t *testing.T) {
+	configureDefaults()
+	request := json.RawMessage(`{"dns_blocking": true}`)
+	options, err := ParseJSONOptions(&request)
+
+	assert.NoError(t, err)
+	assert.True(t, options.(Options).DNSBlocking)
+
+	data, err := json.Marshal(options)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"ports":"0:0","subnet":"10.182.0.0/16","bandwidth_kbps":0,"dns_blocking":true}`, string(data))
+}
+
 func configureDefaults() {
 	ctx := emptyContext()
 	config.ParseFlagsServiceWireguard(ctx)
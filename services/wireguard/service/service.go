@@ -24,7 +24,7 @@ import (
 )
 
 // GetProposal returns the proposal for wireguard service
-func GetProposal(location locationstate.Location) market.ServiceProposal {
+func GetProposal(location locationstate.Location, ipv6 bool) market.ServiceProposal {
 	marketLocation := market.Location{
 		Continent: location.Continent,
 		Country:   location.Country,
@@ -40,6 +40,7 @@ func GetProposal(location locationstate.Location) market.ServiceProposal {
 		ServiceDefinition: wg.ServiceDefinition{
 			Location:          marketLocation,
 			LocationOriginate: marketLocation,
+			IPv6:              ipv6,
 		},
 	}
 }
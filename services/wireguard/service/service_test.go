@@ -54,10 +54,16 @@ func Test_GetProposal(t *testing.T) {
 				LocationOriginate: market.Location{Country: country},
 			},
 		},
-		GetProposal(locationstate.Location{Country: country}),
+		GetProposal(locationstate.Location{Country: country}, false),
 	)
 }
 
+func Test_GetProposalIPv6(t *testing.T) {
+	proposal := GetProposal(locationstate.Location{Country: country}, true)
+
+	assert.True(t, proposal.ServiceDefinition.(wg.ServiceDefinition).IPv6)
+}
+
 func Test_Manager_Stop(t *testing.T) {
 	manager := newManagerStub(pubIP, outIP, country)
 	service := service.NewInstance(
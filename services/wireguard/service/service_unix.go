@@ -27,9 +27,9 @@ import (
 	"time"
 
 	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/service"
-	"github.com/mysteriumnetwork/node/core/shaper"
 	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
@@ -40,6 +40,7 @@ import (
 	"github.com/mysteriumnetwork/node/services/wireguard/key"
 	"github.com/mysteriumnetwork/node/services/wireguard/resources"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
+	sevent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/utils/netutil"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -60,10 +61,15 @@ func NewManager(
 	options Options,
 	portSupplier port.ServicePortSupplier,
 	trafficFirewall firewall.IncomingTrafficFirewall,
+	blocklist *dns.Blocklist,
 ) *Manager {
 	resourcesAllocator := resources.NewAllocator(portSupplier, options.Subnet)
+	if !options.DNSBlocking {
+		blocklist = nil
+	}
 
 	return &Manager{
+		subnet6:            ipv6Subnet(options.Subnet6, ipResolver, natService),
 		done:               make(chan struct{}),
 		resourcesAllocator: resourcesAllocator,
 		ipResolver:         ipResolver,
@@ -71,6 +77,7 @@ func NewManager(
 		natEventGetter:     natEventGetter,
 		eventBus:           eventBus,
 		trafficFirewall:    trafficFirewall,
+		blocklist:          blocklist,
 
 		connEndpointFactory: func() (wg.ConnectionEndpoint, error) {
 			return endpoint.NewConnectionEndpoint(resourcesAllocator)
@@ -95,6 +102,8 @@ type Manager struct {
 	dnsOK    bool
 	dnsPort  int
 	dnsProxy *dns.Proxy
+	// blocklist filters DNS queries of the sessions, nil when blocking is disabled
+	blocklist *dns.Blocklist
 
 	connEndpointFactory func() (wg.ConnectionEndpoint, error)
 
@@ -106,6 +115,31 @@ type Manager struct {
 
 	country    string
 	outboundIP string
+
+	// subnet6 is empty when IPv6 is not provided.
+	subnet6      net.IPNet
+	outboundIPv6 string
+}
+
+// ipv6Subnet returns IPv6 subnet of the sessions, if provider is able to forward their IPv6 traffic.
+func ipv6Subnet(subnet6 net.IPNet, ipResolver ip.Resolver, natService nat.NATService) net.IPNet {
+	if subnet6.IP == nil {
+		return net.IPNet{}
+	}
+	if s, ok := natService.(nat.IPv6Support); !ok || !s.SupportsIPv6() {
+		log.Warn().Msg("IPv6 forwarding is not supported on this platform, IPv6 will not be provided")
+		return net.IPNet{}
+	}
+	if _, err := ipResolver.GetOutboundIPv6(); err != nil {
+		log.Warn().Err(err).Msg("No IPv6 connectivity, IPv6 will not be provided")
+		return net.IPNet{}
+	}
+	return subnet6
+}
+
+// IPv6 checks if sessions are provided with IPv6 connectivity.
+func (m *Manager) IPv6() bool {
+	return m.subnet6.IP != nil
 }
 
 // ProvideConfig provides the config for consumer and handles new WireGuard connection.
@@ -116,6 +150,9 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 	if err != nil {
 		return nil, errors.Wrap(err, "could not unmarshal wg consumer config")
 	}
+	if !m.dnsOK && m.serviceInstance.Policies().HasDNSRules() {
+		return nil, policy.ErrDNSRulesUnenforced
+	}
 
 	remoteConn.Close()
 	listenPort := remoteConn.LocalAddr().(*net.UDPAddr).Port
@@ -139,27 +176,32 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		return nil, errors.Wrap(err, "could not get peer config")
 	}
 
-	var dnsIP net.IP
 	var releaseTrafficFirewall firewall.IncomingRuleRemove
-	if m.dnsOK {
-		if m.serviceInstance.Policies().HasDNSRules() {
-			releaseTrafficFirewall, err = m.trafficFirewall.BlockIncomingTraffic(providerConfig.Subnet)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to enable traffic blocking")
-			}
+	if m.serviceInstance.Policies().HasTrafficRules() {
+		releaseTrafficFirewall, err = m.serviceInstance.Policies().ApplyTrafficRules(m.trafficFirewall, providerConfig.Subnet)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to enable traffic blocking")
 		}
+	}
 
+	var dnsIP net.IP
+	if m.dnsOK {
 		dnsIP = netutil.FirstIP(config.Consumer.IPAddress)
 		config.Consumer.DNSIPs = dnsIP.String()
 	}
 
-	natRules, err := m.natService.Setup(nat.Options{
+	natOptions := nat.Options{
 		VPNNetwork:        config.Consumer.IPAddress,
 		DNSIP:             dnsIP,
 		ProviderExtIP:     net.ParseIP(m.outboundIP),
 		EnableDNSRedirect: m.dnsOK,
 		DNSPort:           m.dnsPort,
-	})
+	}
+	if config.Consumer.IPv6Address.IP != nil {
+		natOptions.VPNNetwork6 = providerConfig.Subnet6
+		natOptions.ProviderExtIP6 = net.ParseIP(m.outboundIPv6)
+	}
+	natRules, err := m.natService.Setup(natOptions)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to setup NAT/firewall rules")
 	}
@@ -167,12 +209,13 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 	statsPublisher := newStatsPublisher(m.eventBus, time.Second)
 	go statsPublisher.start(sessionID, conn)
 
-	ifaceName := conn.InterfaceName()
-	s := shaper.New(m.eventBus)
-	err = s.Start(ifaceName)
-	if err != nil {
-		log.Error().Err(err).Msg("Could not start traffic shaper")
+	tunnel := make(chan sevent.TunnelContext, 1)
+	tunnelContext := sevent.TunnelContext{Interface: conn.InterfaceName(), IP: config.Consumer.IPAddress.IP}
+	if config.Consumer.IPv6Address.IP != nil {
+		ip6 := config.Consumer.IPv6Address
+		tunnelContext.IPv6Network = net.IPNet{IP: ip6.IP.Mask(ip6.Mask), Mask: ip6.Mask}
 	}
+	tunnel <- tunnelContext
 
 	destroy := func() {
 		log.Info().Msgf("Cleaning up session %s", sessionID)
@@ -182,8 +225,6 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 
 		statsPublisher.stop()
 
-		s.Clear(ifaceName)
-
 		if releaseTrafficFirewall != nil {
 			if err := releaseTrafficFirewall(); err != nil {
 				log.Warn().Err(err).Msg("failed to disable traffic blocking")
@@ -209,7 +250,7 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 	m.sessionCleanup[sessionID] = destroy
 	m.sessionCleanupMu.Unlock()
 
-	return &service.ConfigParams{SessionServiceConfig: config, SessionDestroyCallback: destroy}, nil
+	return &service.ConfigParams{SessionServiceConfig: config, SessionDestroyCallback: destroy, SessionTunnel: tunnel}, nil
 }
 
 func (m *Manager) createProviderConfig(listenPort int, peerPublicKey string) (wgcfg.DeviceConfig, error) {
@@ -223,9 +264,16 @@ func (m *Manager) createProviderConfig(listenPort int, peerPublicKey string) (wg
 		return wgcfg.DeviceConfig{}, fmt.Errorf("could not generate private key: %w", err)
 	}
 
+	// Traffic rules are applied to IPv4 network only, IPv6 traffic would bypass them.
+	var network6 net.IPNet
+	if m.IPv6() && m.outboundIPv6 != "" && !m.serviceInstance.Policies().HasTrafficRules() {
+		network6 = resources.IPNet6(m.subnet6, network)
+	}
+
 	return wgcfg.DeviceConfig{
 		IfaceName:  "", // Interface name will be generated by connection endpoint.
 		Subnet:     network,
+		Subnet6:    network6,
 		PrivateKey: privateKey,
 		ListenPort: listenPort,
 		DNS:        nil,
@@ -262,6 +310,15 @@ func (m *Manager) Serve(instance *service.Instance) error {
 	if err != nil {
 		return errors.Wrap(err, "could not get outbound IP")
 	}
+	if m.IPv6() {
+		m.outboundIPv6, err = m.ipResolver.GetOutboundIPv6()
+		if err != nil {
+			log.Warn().Err(err).Msg("Could not get outbound IPv6, IPv6 will not be provided")
+		}
+		if m.serviceInstance.Policies().HasTrafficRules() {
+			log.Warn().Msg("Traffic rules are applied to IPv4 only, IPv6 will not be provided")
+		}
+	}
 
 	// Start DNS proxy.
 	m.dnsPort = 11253
@@ -271,6 +328,9 @@ func (m *Manager) Serve(instance *service.Instance) error {
 		if m.serviceInstance.Policies().HasDNSRules() {
 			dnsHandler = dns.WhitelistAnswers(dnsHandler, m.trafficFirewall, instance.Policies())
 		}
+		if m.blocklist != nil {
+			dnsHandler = dns.BlockByList(dnsHandler, m.blocklist, string(instance.ID))
+		}
 
 		m.dnsProxy = dns.NewProxy("", m.dnsPort, dnsHandler)
 		if err := m.dnsProxy.Run(); err != nil {
@@ -282,6 +342,10 @@ func (m *Manager) Serve(instance *service.Instance) error {
 	} else {
 		log.Warn().Err(err).Msg("Provider DNS will not be available")
 	}
+	if !m.dnsOK && m.serviceInstance.Policies().HasDNSRules() {
+		m.startStopMu.Unlock()
+		return policy.ErrDNSRulesUnenforced
+	}
 
 	m.startStopMu.Unlock()
 	log.Info().Msg("Wireguard: started")
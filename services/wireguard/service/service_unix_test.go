@@ -0,0 +1,58 @@
+//+build !windows
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Manager_ProviderConfig_FailsWhenDNSRulesCanNotBeEnforced(t *testing.T) {
+	policies := policy.NewRepository()
+	policies.SetPolicyRules(
+		market.AccessPolicy{ID: "local:hosts"},
+		market.AccessPolicyRuleSet{
+			ID:    "local:hosts",
+			Allow: []market.AccessRule{{Type: market.AccessPolicyTypeDNSHostname, Value: "ipinfo.io"}},
+		},
+	)
+	manager := newManagerStub(pubIP, outIP, country)
+	manager.serviceInstance = service.NewInstance(
+		identity.FromAddress("0x1"),
+		"",
+		nil,
+		market.ServiceProposal{},
+		servicestate.Running,
+		nil,
+		policies,
+		nil,
+	)
+
+	params, err := manager.ProvideConfig("", []byte(`{"PublicKey": "key"}`), nil)
+
+	assert.Nil(t, params)
+	assert.Equal(t, policy.ErrDNSRulesUnenforced, err)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/nat"
@@ -46,6 +47,7 @@ func NewManager(
 	options Options,
 	portSupplier port.ServicePortSupplier,
 	trafficFirewall firewall.IncomingTrafficFirewall,
+	blocklist *dns.Blocklist,
 ) *Manager {
 	return &Manager{}
 }
@@ -53,6 +55,11 @@ func NewManager(
 // Manager represents an instance of Wireguard service
 type Manager struct{}
 
+// IPv6 checks if sessions are provided with IPv6 connectivity.
+func (manager *Manager) IPv6() bool {
+	return false
+}
+
 // ProvideConfig provides the config for consumer
 func (manager *Manager) ProvideConfig(_ string, _ json.RawMessage, _ *net.UDPConn) (*service.ConfigParams, error) {
 	return nil, errors.New("not implemented")
@@ -35,6 +35,9 @@ type ServiceDefinition struct {
 	// Approximate information on location where the actual tunnelled traffic will originate from.
 	// This is used by providers having their own means of setting tunnels to other remote exit points.
 	LocationOriginate market.Location `json:"location_originate"`
+
+	// IPv6 is set when the service routes IPv6 traffic of the consumers.
+	IPv6 bool `json:"ipv6,omitempty"`
 }
 
 // GetLocation returns geographic location of service definition provider
@@ -42,6 +45,11 @@ func (service ServiceDefinition) GetLocation() market.Location {
 	return service.Location
 }
 
+// SupportsIPv6 checks if the service routes IPv6 traffic of the consumers.
+func (service ServiceDefinition) SupportsIPv6() bool {
+	return service.IPv6
+}
+
 // ServiceConfig represent a Wireguard service provider configuration that will be passed to the consumer for establishing a connection.
 type ServiceConfig struct {
 	// LocalPort and RemotePort are needed for NAT hole punching only.
@@ -55,7 +63,9 @@ type ServiceConfig struct {
 	}
 	Consumer struct {
 		IPAddress net.IPNet
-		DNSIPs    string
+		// IPv6Address is empty when provider does not route IPv6 traffic.
+		IPv6Address net.IPNet
+		DNSIPs      string
 	}
 }
 
@@ -74,8 +84,14 @@ func (s ServiceConfig) MarshalJSON() ([]byte, error) {
 		Endpoint  string `json:"endpoint"`
 	}
 	type consumer struct {
-		IPAddress string `json:"ip_address"`
-		DNSIPs    string `json:"dns_ips"`
+		IPAddress   string `json:"ip_address"`
+		IPv6Address string `json:"ipv6_address,omitempty"`
+		DNSIPs      string `json:"dns_ips"`
+	}
+
+	var ipv6Address string
+	if s.Consumer.IPv6Address.IP != nil {
+		ipv6Address = s.Consumer.IPv6Address.String()
 	}
 
 	return json.Marshal(&struct {
@@ -93,8 +109,9 @@ func (s ServiceConfig) MarshalJSON() ([]byte, error) {
 			Endpoint:  s.Provider.Endpoint.String(),
 		},
 		Consumer: consumer{
-			IPAddress: s.Consumer.IPAddress.String(),
-			DNSIPs:    s.Consumer.DNSIPs,
+			IPAddress:   s.Consumer.IPAddress.String(),
+			IPv6Address: ipv6Address,
+			DNSIPs:      s.Consumer.DNSIPs,
 		},
 	})
 }
@@ -106,8 +123,9 @@ func (s *ServiceConfig) UnmarshalJSON(data []byte) error {
 		Endpoint  string `json:"endpoint"`
 	}
 	type consumer struct {
-		IPAddress string `json:"ip_address"`
-		DNSIPs    string `json:"dns_ips"`
+		IPAddress   string `json:"ip_address"`
+		IPv6Address string `json:"ipv6_address"`
+		DNSIPs      string `json:"dns_ips"`
 	}
 	var config struct {
 		LocalPort  int      `json:"local_port"`
@@ -130,6 +148,16 @@ func (s *ServiceConfig) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	var ipv6Address net.IPNet
+	if config.Consumer.IPv6Address != "" {
+		ip6, ipnet6, err := net.ParseCIDR(config.Consumer.IPv6Address)
+		if err != nil {
+			return err
+		}
+		ipv6Address = *ipnet6
+		ipv6Address.IP = ip6
+	}
+
 	s.Ports = config.Ports
 	s.LocalPort = config.LocalPort
 	s.RemotePort = config.RemotePort
@@ -138,6 +166,7 @@ func (s *ServiceConfig) UnmarshalJSON(data []byte) error {
 	s.Consumer.DNSIPs = config.Consumer.DNSIPs
 	s.Consumer.IPAddress = *ipnet
 	s.Consumer.IPAddress.IP = ip
+	s.Consumer.IPv6Address = ipv6Address
 
 	return nil
 }
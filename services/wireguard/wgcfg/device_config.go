@@ -38,13 +38,21 @@ type Stats struct {
 
 // DeviceConfig describes wireguard device configuration.
 type DeviceConfig struct {
-	IfaceName  string    `json:"iface_name"`
-	Subnet     net.IPNet `json:"subnet"`
+	IfaceName string    `json:"iface_name"`
+	Subnet    net.IPNet `json:"subnet"`
+	// Subnet6 is the IPv6 network of the tunnel, tunnel carries IPv4 traffic only when it is empty.
+	Subnet6    net.IPNet `json:"subnet6"`
 	PrivateKey string    `json:"private_key"`
 	ListenPort int       `json:"listen_port"`
 	DNS        []string  `json:"dns"`
 	// Used only for unix.
 	DNSScriptDir string `json:"dns_script_dir"`
+	// OuterIface is set when the tunnel is nested into another one, peer endpoint is routed through it.
+	OuterIface string `json:"outer_iface"`
+	// IncludeRoutes limits the tunnel to the given networks, all traffic is routed through it when empty.
+	IncludeRoutes []net.IPNet `json:"include_routes"`
+	// ExcludeRoutes are networks routed outside of the tunnel.
+	ExcludeRoutes []net.IPNet `json:"exclude_routes"`
 
 	Peer Peer `json:"peer"`
 }
@@ -59,13 +67,17 @@ func (dc DeviceConfig) MarshalJSON() ([]byte, error) {
 	}
 
 	type deviceConfig struct {
-		IfaceName    string   `json:"iface_name"`
-		Subnet       string   `json:"subnet"`
-		PrivateKey   string   `json:"private_key"`
-		ListenPort   int      `json:"listen_port"`
-		DNS          []string `json:"dns"`
-		DNSScriptDir string   `json:"dns_script_dir"`
-		Peer         peer     `json:"peer"`
+		IfaceName     string   `json:"iface_name"`
+		Subnet        string   `json:"subnet"`
+		Subnet6       string   `json:"subnet6,omitempty"`
+		PrivateKey    string   `json:"private_key"`
+		ListenPort    int      `json:"listen_port"`
+		DNS           []string `json:"dns"`
+		DNSScriptDir  string   `json:"dns_script_dir"`
+		OuterIface    string   `json:"outer_iface,omitempty"`
+		IncludeRoutes []string `json:"include_routes,omitempty"`
+		ExcludeRoutes []string `json:"exclude_routes,omitempty"`
+		Peer          peer     `json:"peer"`
 	}
 
 	var peerEndpoint string
@@ -73,13 +85,22 @@ func (dc DeviceConfig) MarshalJSON() ([]byte, error) {
 		peerEndpoint = dc.Peer.Endpoint.String()
 	}
 
+	var subnet6 string
+	if dc.Subnet6.IP != nil {
+		subnet6 = dc.Subnet6.String()
+	}
+
 	return json.Marshal(&deviceConfig{
-		IfaceName:    dc.IfaceName,
-		Subnet:       dc.Subnet.String(),
-		PrivateKey:   dc.PrivateKey,
-		ListenPort:   dc.ListenPort,
-		DNS:          dc.DNS,
-		DNSScriptDir: dc.DNSScriptDir,
+		IfaceName:     dc.IfaceName,
+		Subnet:        dc.Subnet.String(),
+		Subnet6:       subnet6,
+		PrivateKey:    dc.PrivateKey,
+		ListenPort:    dc.ListenPort,
+		DNS:           dc.DNS,
+		DNSScriptDir:  dc.DNSScriptDir,
+		OuterIface:    dc.OuterIface,
+		IncludeRoutes: networksToStrings(dc.IncludeRoutes),
+		ExcludeRoutes: networksToStrings(dc.ExcludeRoutes),
 		Peer: peer{
 			PublicKey:              dc.Peer.PublicKey,
 			Endpoint:               peerEndpoint,
@@ -99,13 +120,17 @@ func (dc *DeviceConfig) UnmarshalJSON(data []byte) error {
 	}
 
 	type deviceConfig struct {
-		IfaceName    string   `json:"iface_name"`
-		Subnet       string   `json:"subnet"`
-		PrivateKey   string   `json:"private_key"`
-		ListenPort   int      `json:"listen_port"`
-		DNS          []string `json:"dns"`
-		DNSScriptDir string   `json:"dns_script_dir"`
-		Peer         peer     `json:"peer"`
+		IfaceName     string   `json:"iface_name"`
+		Subnet        string   `json:"subnet"`
+		Subnet6       string   `json:"subnet6,omitempty"`
+		PrivateKey    string   `json:"private_key"`
+		ListenPort    int      `json:"listen_port"`
+		DNS           []string `json:"dns"`
+		DNSScriptDir  string   `json:"dns_script_dir"`
+		OuterIface    string   `json:"outer_iface,omitempty"`
+		IncludeRoutes []string `json:"include_routes,omitempty"`
+		ExcludeRoutes []string `json:"exclude_routes,omitempty"`
+		Peer          peer     `json:"peer"`
 	}
 
 	cfg := deviceConfig{}
@@ -119,6 +144,26 @@ func (dc *DeviceConfig) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("could not parse subnet: %w", err)
 	}
 
+	var subnet6 net.IPNet
+	if cfg.Subnet6 != "" {
+		ip6, ipnet6, err := net.ParseCIDR(cfg.Subnet6)
+		if err != nil {
+			return fmt.Errorf("could not parse IPv6 subnet: %w", err)
+		}
+		subnet6 = *ipnet6
+		subnet6.IP = ip6
+	}
+
+	includeRoutes, err := parseNetworks(cfg.IncludeRoutes)
+	if err != nil {
+		return fmt.Errorf("could not parse include routes: %w", err)
+	}
+
+	excludeRoutes, err := parseNetworks(cfg.ExcludeRoutes)
+	if err != nil {
+		return fmt.Errorf("could not parse exclude routes: %w", err)
+	}
+
 	var peerEndpoint *net.UDPAddr
 	if cfg.Peer.Endpoint != "" {
 		peerEndpoint, err = net.ResolveUDPAddr("udp", cfg.Peer.Endpoint)
@@ -130,10 +175,14 @@ func (dc *DeviceConfig) UnmarshalJSON(data []byte) error {
 	dc.IfaceName = cfg.IfaceName
 	dc.Subnet = *ipnet
 	dc.Subnet.IP = ip
+	dc.Subnet6 = subnet6
 	dc.PrivateKey = cfg.PrivateKey
 	dc.ListenPort = cfg.ListenPort
 	dc.DNS = cfg.DNS
 	dc.DNSScriptDir = cfg.DNSScriptDir
+	dc.OuterIface = cfg.OuterIface
+	dc.IncludeRoutes = includeRoutes
+	dc.ExcludeRoutes = excludeRoutes
 	dc.Peer = Peer{
 		PublicKey:              cfg.Peer.PublicKey,
 		Endpoint:               peerEndpoint,
@@ -144,6 +193,26 @@ func (dc *DeviceConfig) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+func networksToStrings(networks []net.IPNet) []string {
+	var res []string
+	for _, network := range networks {
+		res = append(res, network.String())
+	}
+	return res
+}
+
+func parseNetworks(networks []string) ([]net.IPNet, error) {
+	var res []net.IPNet
+	for _, network := range networks {
+		_, ipnet, err := net.ParseCIDR(network)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *ipnet)
+	}
+	return res, nil
+}
+
 // Encode encodes device config into string representation which is used for
 // userspace and kernel space wireguard configuration.
 func (dc *DeviceConfig) Encode() string {
@@ -151,6 +151,61 @@ func TestDeviceConfig_UnmarshalJSON(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "Test unmarshal nested tunnel",
+			config: `{"iface_name":"myst1","subnet":"10.0.182.2/24","private_key":"DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=","listen_port":53511,"outer_iface":"myst0","peer":{"public_key":"DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=","endpoint":"182.122.22.19:3233","allowed_i_ps":["0.0.0.0/0"],"keep_alive_period_seconds":20}}`,
+			expected: DeviceConfig{
+				IfaceName:  "myst1",
+				Subnet:     net.IPNet{IP: net.ParseIP("10.0.182.2"), Mask: net.IPv4Mask(255, 255, 255, 0)},
+				PrivateKey: "DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=",
+				ListenPort: 53511,
+				OuterIface: "myst0",
+				Peer: Peer{
+					PublicKey:              "DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=",
+					Endpoint:               endpoint(),
+					AllowedIPs:             []string{"0.0.0.0/0"},
+					KeepAlivePeriodSeconds: 20,
+				},
+			},
+		},
+		{
+			name:   "Test unmarshal split tunnel",
+			config: `{"iface_name":"myst0","subnet":"10.0.182.2/24","private_key":"DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=","listen_port":53511,"include_routes":["8.8.8.0/24"],"exclude_routes":["10.0.0.0/8","192.168.1.10/32"],"peer":{"public_key":"DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=","endpoint":"182.122.22.19:3233","allowed_i_ps":["0.0.0.0/0"],"keep_alive_period_seconds":20}}`,
+			expected: DeviceConfig{
+				IfaceName:     "myst0",
+				Subnet:        net.IPNet{IP: net.ParseIP("10.0.182.2"), Mask: net.IPv4Mask(255, 255, 255, 0)},
+				PrivateKey:    "DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=",
+				ListenPort:    53511,
+				IncludeRoutes: []net.IPNet{{IP: net.IPv4(8, 8, 8, 0).To4(), Mask: net.CIDRMask(24, 32)}},
+				ExcludeRoutes: []net.IPNet{
+					{IP: net.IPv4(10, 0, 0, 0).To4(), Mask: net.CIDRMask(8, 32)},
+					{IP: net.IPv4(192, 168, 1, 10).To4(), Mask: net.CIDRMask(32, 32)},
+				},
+				Peer: Peer{
+					PublicKey:              "DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=",
+					Endpoint:               endpoint(),
+					AllowedIPs:             []string{"0.0.0.0/0"},
+					KeepAlivePeriodSeconds: 20,
+				},
+			},
+		},
+		{
+			name:   "Test unmarshal dual stack tunnel",
+			config: `{"iface_name":"myst0","subnet":"10.0.182.2/24","subnet6":"fd10:182:0:1::2/64","private_key":"DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=","listen_port":53511,"peer":{"public_key":"DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=","endpoint":"182.122.22.19:3233","allowed_i_ps":["0.0.0.0/0","::/0"],"keep_alive_period_seconds":20}}`,
+			expected: DeviceConfig{
+				IfaceName:  "myst0",
+				Subnet:     net.IPNet{IP: net.ParseIP("10.0.182.2"), Mask: net.IPv4Mask(255, 255, 255, 0)},
+				Subnet6:    net.IPNet{IP: net.ParseIP("fd10:182:0:1::2"), Mask: net.CIDRMask(64, 128)},
+				PrivateKey: "DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=",
+				ListenPort: 53511,
+				Peer: Peer{
+					PublicKey:              "DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=",
+					Endpoint:               endpoint(),
+					AllowedIPs:             []string{"0.0.0.0/0", "::/0"},
+					KeepAlivePeriodSeconds: 20,
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -19,6 +19,7 @@ package event
 
 import (
 	"math/big"
+	"net"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -33,6 +34,8 @@ const (
 	AppTopicDataTransferred = "Session data transferred"
 	// AppTopicTokensEarned is a topic for publish events about tokens earned as a provider.
 	AppTopicTokensEarned = "SessionTokensEarned"
+	// AppTopicSessionTunnel represents the session tunnel change topic.
+	AppTopicSessionTunnel = "Session tunnel change"
 )
 
 // AppEventDataTransferred represents the data transfer event
@@ -81,3 +84,19 @@ type SessionContext struct {
 	HermesID         common.Address
 	Proposal         market.ServiceProposal
 }
+
+// AppEventSessionTunnel represents the session tunnel change payload
+type AppEventSessionTunnel struct {
+	Status  Status
+	Service ServiceContext
+	Session SessionContext
+	Tunnel  TunnelContext
+}
+
+// TunnelContext holds provider side tunnel metadata of the session
+type TunnelContext struct {
+	Interface string
+	IP        net.IP
+	// IPv6Network is the IPv6 network routed to the consumer, empty when the tunnel carries IPv4 only.
+	IPv6Network net.IPNet
+}
@@ -36,6 +36,8 @@ const (
 	AppTopicInvoicePaid = "invoice_paid"
 	// AppTopicSettlementRequest forces the settlement of promises for given provider/hermes.
 	AppTopicSettlementRequest = "settlement_request"
+	// AppTopicSettlementComplete represents the topic of finished promise settlements, either successful or failed.
+	AppTopicSettlementComplete = "settlement_complete"
 )
 
 // AppEventSettlementRequest represents the payload that is sent on the AppTopicSettlementRequest topic.
@@ -45,6 +47,15 @@ type AppEventSettlementRequest struct {
 	ChainID    int64
 }
 
+// AppEventSettlementComplete represents the payload that is sent on the AppTopicSettlementComplete topic.
+type AppEventSettlementComplete struct {
+	ProviderID identity.Identity
+	HermesID   common.Address
+	Amount     *big.Int
+	Successful bool
+	Error      error
+}
+
 // AppEventHermesPromise represents the payload that is sent on the AppTopicHermesPromise.
 type AppEventHermesPromise struct {
 	Promise    crypto.Promise
@@ -89,3 +100,26 @@ type AppEventGrandTotalChanged struct {
 	HermesID   common.Address
 	ConsumerID identity.Identity
 }
+
+// AppTopicSpendingLimitReached represents a topic to which we send spending limit events of the consumer.
+const AppTopicSpendingLimitReached = "spending_limit_reached"
+
+const (
+	// SpendingLimitSession is the limit of a single session spending.
+	SpendingLimitSession = "session"
+	// SpendingLimitDay is the limit of the daily spending.
+	SpendingLimitDay = "day"
+	// SpendingLimitMonth is the limit of the monthly spending.
+	SpendingLimitMonth = "month"
+)
+
+// AppEventSpendingLimitReached represents the payload sent when paying the invoice would exceed the spending limit,
+// the session is disconnected instead.
+type AppEventSpendingLimitReached struct {
+	ConsumerID identity.Identity
+	ProviderID identity.Identity
+	SessionID  string
+	Limit      string
+	Cap        *big.Int
+	Spent      *big.Int // Spending including the refused invoice.
+}
@@ -117,11 +117,10 @@ func InvoiceFactoryCreator(
 	maxUnpaidInvoiceValue *big.Int,
 	blockchainHelper bcHelper,
 	eventBus eventbus.EventBus,
-	proposal market.ServiceProposal,
 	promiseHandler promiseHandler,
 	providersHermes common.Address,
-) func(identity.Identity, identity.Identity, int64, common.Address, string, chan crypto.ExchangeMessage) (service.PaymentEngine, error) {
-	return func(providerID, consumerID identity.Identity, chainID int64, hermesID common.Address, sessionID string, exchangeChan chan crypto.ExchangeMessage) (service.PaymentEngine, error) {
+) service.PaymentEngineFactory {
+	return func(providerID, consumerID identity.Identity, chainID int64, hermesID common.Address, sessionID string, proposal market.ServiceProposal, exchangeChan chan crypto.ExchangeMessage) (service.PaymentEngine, error) {
 		timeTracker := session.NewTracker(mbtime.Now)
 		deps := InvoiceTrackerDeps{
 			Proposal:                   proposal,
@@ -160,8 +159,10 @@ func ExchangeFactoryFunc(
 	channelImplementation string,
 	registryAddress string,
 	eventBus eventbus.EventBus,
-	dataLeewayMegabytes uint64) func(channel p2p.Channel, consumer, provider identity.Identity, hermes common.Address, proposal market.ServiceProposal) (connection.PaymentIssuer, error) {
-	return func(channel p2p.Channel, consumer, provider identity.Identity, hermes common.Address, proposal market.ServiceProposal) (connection.PaymentIssuer, error) {
+	dataLeewayMegabytes uint64,
+	sessionHistory consumedSessionStorage) func(channel p2p.Channel, consumer, provider identity.Identity, hermes common.Address, proposal market.ServiceProposal, limits *connection.SpendingLimits) (connection.PaymentIssuer, error) {
+	channelLocks := NewChannelLocks()
+	return func(channel p2p.Channel, consumer, provider identity.Identity, hermes common.Address, proposal market.ServiceProposal, limits *connection.SpendingLimits) (connection.PaymentIssuer, error) {
 		invoices, err := invoiceReceiver(channel)
 		if err != nil {
 			return nil, err
@@ -181,6 +182,9 @@ func ExchangeFactoryFunc(
 			HermesAddress:             hermes,
 			DataLeeway:                datasize.MiB * datasize.BitSize(dataLeewayMegabytes),
 			ChainID:                   config.GetInt64(config.FlagChainID),
+			SpendingLimits:            ConfiguredSpendingLimits(limits),
+			SessionHistory:            sessionHistory,
+			PromiseLock:               channelLocks.Get(hermes, consumer),
 		}
 		return NewInvoicePayer(deps), nil
 	}
@@ -92,6 +92,7 @@ type hermesPromiseSettler struct {
 	transactor                 transactor
 	channelProvider            hermesChannelProvider
 	settlementHistoryStorage   settlementHistoryStorage
+	publisher                  eventbus.Publisher
 
 	// TODO: Consider adding chain ID to this as well.
 	currentState map[identity.Identity]settlementState
@@ -108,7 +109,7 @@ type HermesPromiseSettlerConfig struct {
 }
 
 // NewHermesPromiseSettler creates a new instance of hermes promise settler.
-func NewHermesPromiseSettler(transactor transactor, channelProvider hermesChannelProvider, providerChannelStatusProvider providerChannelStatusProvider, registrationStatusProvider registrationStatusProvider, ks ks, settlementHistoryStorage settlementHistoryStorage, config HermesPromiseSettlerConfig) *hermesPromiseSettler {
+func NewHermesPromiseSettler(transactor transactor, channelProvider hermesChannelProvider, providerChannelStatusProvider providerChannelStatusProvider, registrationStatusProvider registrationStatusProvider, ks ks, settlementHistoryStorage settlementHistoryStorage, publisher eventbus.Publisher, config HermesPromiseSettlerConfig) *hermesPromiseSettler {
 	return &hermesPromiseSettler{
 		bc:                         providerChannelStatusProvider,
 		ks:                         ks,
@@ -117,6 +118,7 @@ func NewHermesPromiseSettler(transactor transactor, channelProvider hermesChanne
 		currentState:               make(map[identity.Identity]settlementState),
 		channelProvider:            channelProvider,
 		settlementHistoryStorage:   settlementHistoryStorage,
+		publisher:                  publisher,
 
 		// defaulting to a queue of 5, in case we have a few active identities.
 		settleQueue: make(chan receivedPromise, 5),
@@ -450,9 +452,11 @@ func (aps *hermesPromiseSettler) settle(
 				log.Error().Err(err).Msg("Could not store settlement history")
 			}
 
+			aps.publishSettlementComplete(provider, hermesID, info.AmountSentToBeneficiary, nil)
 			return
 		case <-time.After(aps.config.MaxWaitForSettlement):
 			log.Info().Msgf("Settle timeout for %v", provider)
+			aps.publishSettlementComplete(provider, hermesID, nil, ErrSettleTimeout)
 
 			// send a signal to waiter that the settlement has timed out
 			errCh <- ErrSettleTimeout
@@ -464,12 +468,23 @@ func (aps *hermesPromiseSettler) settle(
 	if err != nil {
 		cancel()
 		log.Error().Err(err).Msgf("Could not settle promise for %v", provider)
+		aps.publishSettlementComplete(provider, hermesID, nil, err)
 		return err
 	}
 
 	return <-errCh
 }
 
+func (aps *hermesPromiseSettler) publishSettlementComplete(provider identity.Identity, hermesID common.Address, amount *big.Int, err error) {
+	aps.publisher.Publish(event.AppTopicSettlementComplete, event.AppEventSettlementComplete{
+		ProviderID: provider,
+		HermesID:   hermesID,
+		Amount:     amount,
+		Successful: err == nil,
+		Error:      err,
+	})
+}
+
 func (aps *hermesPromiseSettler) isSettling(id identity.Identity) bool {
 	aps.lock.RLock()
 	defer aps.lock.RUnlock()
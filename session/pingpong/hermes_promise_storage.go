@@ -23,12 +23,10 @@ import (
 	"math/big"
 	"sync"
 
-	"github.com/asdine/storm/v3/codec/json"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/payments/crypto"
-	"go.etcd.io/bbolt"
 )
 
 const hermesPromiseBucketName = "hermes_promises"
@@ -38,14 +36,14 @@ var ErrAttemptToOverwrite = errors.New("attempted to overwrite a promise with an
 
 // HermesPromiseStorage allows for storing of hermes promises.
 type HermesPromiseStorage struct {
-	lock sync.Mutex
-	bolt *boltdb.Bolt
+	lock    sync.Mutex
+	storage storage.Storage
 }
 
 // NewHermesPromiseStorage returns a new instance of the hermes promise storage.
-func NewHermesPromiseStorage(bolt *boltdb.Bolt) *HermesPromiseStorage {
+func NewHermesPromiseStorage(storage storage.Storage) *HermesPromiseStorage {
 	return &HermesPromiseStorage{
-		bolt: bolt,
+		storage: storage,
 	}
 }
 
@@ -78,7 +76,7 @@ func (aps *HermesPromiseStorage) Store(promise HermesPromise) error {
 		return ErrAttemptToOverwrite
 	}
 
-	if err := aps.bolt.SetValue(aps.getBucketName(promise.Promise.ChainID), promise.ChannelID, promise); err != nil {
+	if err := aps.storage.SetValue(aps.getBucketName(promise.Promise.ChainID), promise.ChannelID, promise); err != nil {
 		return fmt.Errorf("could not store hermes promise: %w", err)
 	}
 	return nil
@@ -86,7 +84,7 @@ func (aps *HermesPromiseStorage) Store(promise HermesPromise) error {
 
 func (aps *HermesPromiseStorage) get(chainID int64, channelID string) (HermesPromise, error) {
 	result := &HermesPromise{}
-	err := aps.bolt.GetValue(aps.getBucketName(chainID), channelID, result)
+	err := aps.storage.GetValue(aps.getBucketName(chainID), channelID, result)
 	if err != nil {
 		if err.Error() == errBoltNotFound {
 			err = ErrNotFound
@@ -120,38 +118,16 @@ func (aps *HermesPromiseStorage) List(filter HermesPromiseFilter) ([]HermesPromi
 	aps.lock.Lock()
 	defer aps.lock.Unlock()
 
-	result := make([]HermesPromise, 0)
-	err := aps.bolt.DB().Bolt.View(func(tx *bbolt.Tx) error {
-		bucket := tx.Bucket([]byte(aps.getBucketName(filter.ChainID)))
-		if bucket == nil {
-			return nil
-		}
+	where := make([]storage.Condition, 0)
+	if filter.Identity != nil {
+		where = append(where, storage.Eq("Identity", *filter.Identity))
+	}
+	if filter.HermesID != nil {
+		where = append(where, storage.Eq("HermesID", *filter.HermesID))
+	}
 
-		return bucket.ForEach(func(k, v []byte) error {
-			if string(k) == "__storm_metadata" {
-				return nil
-			}
-
-			var entry HermesPromise
-			if err := json.Codec.Unmarshal(v, &entry); err != nil {
-				return err
-			}
-
-			if filter.Identity != nil {
-				if *filter.Identity != entry.Identity {
-					return nil
-				}
-			}
-			if filter.HermesID != nil {
-				if *filter.HermesID != entry.HermesID {
-					return nil
-				}
-			}
-
-			result = append(result, entry)
-			return nil
-		})
-	})
+	result := make([]HermesPromise, 0)
+	err := aps.storage.FindValues(aps.getBucketName(filter.ChainID), where, &result)
 	if err != nil {
 		return nil, fmt.Errorf("could not list hermes promises: %w", err)
 	}
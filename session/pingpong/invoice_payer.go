@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/eventbus"
@@ -81,6 +82,7 @@ type InvoicePayer struct {
 
 	dataTransferred     DataTransferred
 	dataTransferredLock sync.Mutex
+	sessionIDLock       sync.RWMutex
 }
 
 type hashSigner interface {
@@ -102,10 +104,45 @@ type InvoicePayerDeps struct {
 	HermesAddress             common.Address
 	DataLeeway                datasize.BitSize
 	ChainID                   int64
+	SpendingLimits            connection.SpendingLimits
+	SessionHistory            consumedSessionStorage
+	// PromiseLock serializes promise issuing, since the promised grand total of a consumer channel
+	// is shared by concurrent sessions, e.g. the hops of a multi-hop connection.
+	PromiseLock sync.Locker
+}
+
+// ChannelLocks provides the promise locks of consumer channels, keyed by hermes and consumer.
+type ChannelLocks struct {
+	lock  sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// NewChannelLocks returns a new instance of channel locks.
+func NewChannelLocks() *ChannelLocks {
+	return &ChannelLocks{
+		locks: make(map[string]*sync.Mutex),
+	}
+}
+
+// Get returns the promise lock of the consumer channel with the given hermes.
+func (cl *ChannelLocks) Get(hermes common.Address, consumer identity.Identity) sync.Locker {
+	cl.lock.Lock()
+	defer cl.lock.Unlock()
+
+	key := strings.ToLower(hermes.Hex() + consumer.Address)
+	lock, ok := cl.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		cl.locks[key] = lock
+	}
+	return lock
 }
 
 // NewInvoicePayer returns a new instance of exchange message tracker.
 func NewInvoicePayer(ipd InvoicePayerDeps) *InvoicePayer {
+	if ipd.PromiseLock == nil {
+		ipd.PromiseLock = &sync.Mutex{}
+	}
 	return &InvoicePayer{
 		stop: make(chan struct{}),
 		deps: ipd,
@@ -135,6 +172,10 @@ func (ip *InvoicePayer) Start() error {
 	if err != nil {
 		return errors.Wrap(err, "could not subscribe to data transfer events")
 	}
+	err = ip.deps.EventBus.Subscribe(connectionstate.AppTopicConnectionHopStatistics, ip.consumeDataTransferredEvent)
+	if err != nil {
+		return errors.Wrap(err, "could not subscribe to hop data transfer events")
+	}
 
 	for {
 		select {
@@ -147,6 +188,11 @@ func (ip *InvoicePayer) Start() error {
 				return errors.Wrap(err, "invoice not valid")
 			}
 
+			err = ip.checkSpendingLimits(invoice)
+			if err != nil {
+				return err
+			}
+
 			err = ip.issueExchangeMessage(invoice)
 			if err != nil {
 				return err
@@ -250,6 +296,9 @@ func (ip *InvoicePayer) chainID() int64 {
 }
 
 func (ip *InvoicePayer) issueExchangeMessage(invoice crypto.Invoice) error {
+	ip.deps.PromiseLock.Lock()
+	defer ip.deps.PromiseLock.Unlock()
+
 	amountToPromise, diff, err := ip.calculateAmountToPromise(invoice)
 	if err != nil {
 		return errors.Wrap(err, "could not calculate amount to promise")
@@ -267,7 +316,7 @@ func (ip *InvoicePayer) issueExchangeMessage(invoice crypto.Invoice) error {
 
 	ip.deps.EventBus.Publish(event.AppTopicInvoicePaid, event.AppEventInvoicePaid{
 		ConsumerID: ip.deps.Identity,
-		SessionID:  ip.deps.SessionID,
+		SessionID:  ip.getSessionID(),
 		Invoice:    invoice,
 	})
 
@@ -281,11 +330,17 @@ func (ip *InvoicePayer) Stop() {
 	ip.once.Do(func() {
 		log.Debug().Msg("Stopping...")
 		_ = ip.deps.EventBus.Unsubscribe(connectionstate.AppTopicConnectionStatistics, ip.consumeDataTransferredEvent)
+		_ = ip.deps.EventBus.Unsubscribe(connectionstate.AppTopicConnectionHopStatistics, ip.consumeDataTransferredEvent)
 		close(ip.stop)
 	})
 }
 
 func (ip *InvoicePayer) consumeDataTransferredEvent(e connectionstate.AppEventConnectionStatistics) {
+	// Skip statistics of other sessions, e.g. other hops of a multi-hop connection.
+	if string(e.SessionInfo.SessionID) != ip.getSessionID() {
+		return
+	}
+
 	// From a server perspective, bytes up are the actual bytes the client downloaded(aka the bytes we pushed to the consumer)
 	// To lessen the confusion, I suggest having the bytes reversed on the session instance.
 	// This way, the session will show that it downloaded the bytes in a manner that is easier to comprehend.
@@ -321,5 +376,15 @@ func (ip *InvoicePayer) getDataTransferred() DataTransferred {
 
 // SetSessionID updates invoice payer dependencies to set session ID once session established.
 func (ip *InvoicePayer) SetSessionID(sessionID string) {
+	ip.sessionIDLock.Lock()
+	defer ip.sessionIDLock.Unlock()
+
 	ip.deps.SessionID = sessionID
 }
+
+func (ip *InvoicePayer) getSessionID() string {
+	ip.sessionIDLock.RLock()
+	defer ip.sessionIDLock.RUnlock()
+
+	return ip.deps.SessionID
+}
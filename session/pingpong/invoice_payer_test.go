@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
@@ -584,6 +585,7 @@ func TestInvoicePayer_issueExchangeMessage_publishesEvents(t *testing.T) {
 	}
 	emt := &InvoicePayer{
 		deps: InvoicePayerDeps{
+			PromiseLock: &sync.Mutex{},
 			PeerExchangeMessageSender: &MockPeerExchangeMessageSender{
 				chanToWriteTo: make(chan crypto.ExchangeMessage, 10),
 			},
@@ -759,6 +761,7 @@ func TestInvoicePayer_issueExchangeMessage(t *testing.T) {
 					Ks:                        tt.fields.keystore,
 					Identity:                  tt.fields.identity,
 					EventBus:                  mocks.NewEventBus(),
+					PromiseLock:               &sync.Mutex{},
 				},
 			}
 			emt.lastInvoice = tt.fields.lastInvoice
@@ -818,6 +821,23 @@ func (mtt *mockTimeTracker) Elapsed() time.Duration {
 	return mtt.timeToReturn
 }
 
+func TestInvoicePayer_consumeDataTransferredEvent_skipsOtherSessions(t *testing.T) {
+	ip := NewInvoicePayer(InvoicePayerDeps{})
+	ip.SetSessionID("exit-session")
+
+	ip.consumeDataTransferredEvent(connectionstate.AppEventConnectionStatistics{
+		Stats:       connectionstate.Statistics{BytesSent: 100, BytesReceived: 200},
+		SessionInfo: connectionstate.Status{SessionID: "entry-session"},
+	})
+	assert.Equal(t, DataTransferred{}, ip.getDataTransferred())
+
+	ip.consumeDataTransferredEvent(connectionstate.AppEventConnectionStatistics{
+		Stats:       connectionstate.Statistics{BytesSent: 10, BytesReceived: 20},
+		SessionInfo: connectionstate.Status{SessionID: "exit-session"},
+	})
+	assert.Equal(t, DataTransferred{Up: 10, Down: 20}, ip.getDataTransferred())
+}
+
 func Test_estimateInvoiceTolerance(t *testing.T) {
 	type args struct {
 		elapsed     time.Duration
@@ -968,3 +988,13 @@ func Test_estimateInvoiceTolerance(t *testing.T) {
 		})
 	}
 }
+
+func TestChannelLocks_Get(t *testing.T) {
+	locks := NewChannelLocks()
+	hermes := common.HexToAddress("0x1")
+	consumer := identity.FromAddress("0xAbC")
+
+	assert.Same(t, locks.Get(hermes, consumer), locks.Get(hermes, identity.FromAddress("0xabc")))
+	assert.NotSame(t, locks.Get(hermes, consumer), locks.Get(common.HexToAddress("0x2"), consumer))
+	assert.NotSame(t, locks.Get(hermes, consumer), locks.Get(hermes, identity.FromAddress("0xdef")))
+}
@@ -22,23 +22,21 @@ import (
 	"math/big"
 	"time"
 
-	"github.com/asdine/storm/v3"
-	"github.com/asdine/storm/v3/q"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/payments/crypto"
 )
 
 // SettlementHistoryStorage stores the settlement events for historical purposes.
 type SettlementHistoryStorage struct {
-	bolt *boltdb.Bolt
+	storage storage.Storage
 }
 
 // NewSettlementHistoryStorage returns a new instance of the SettlementHistoryStorage.
-func NewSettlementHistoryStorage(bolt *boltdb.Bolt) *SettlementHistoryStorage {
+func NewSettlementHistoryStorage(storage storage.Storage) *SettlementHistoryStorage {
 	return &SettlementHistoryStorage{
-		bolt: bolt,
+		storage: storage,
 	}
 }
 
@@ -59,7 +57,7 @@ const settlementHistoryBucket = "settlement-history"
 
 // Store stores a given settlement history entry.
 func (shs *SettlementHistoryStorage) Store(she SettlementHistoryEntry) error {
-	return shs.bolt.DB().From(settlementHistoryBucket).Save(&she)
+	return shs.storage.Store(settlementHistoryBucket, &she)
 }
 
 // SettlementHistoryFilter defines all flags for filtering in settlement history storage.
@@ -72,28 +70,23 @@ type SettlementHistoryFilter struct {
 
 // List retrieves stored entries.
 func (shs *SettlementHistoryStorage) List(filter SettlementHistoryFilter) (result []SettlementHistoryEntry, err error) {
-	where := make([]q.Matcher, 0)
+	where := make([]storage.Condition, 0)
 	if filter.TimeFrom != nil {
-		where = append(where, q.Gte("Time", filter.TimeFrom.UTC()))
+		where = append(where, storage.Gte("Time", filter.TimeFrom.UTC()))
 	}
 	if filter.TimeTo != nil {
-		where = append(where, q.Lte("Time", filter.TimeTo.UTC()))
+		where = append(where, storage.Lte("Time", filter.TimeTo.UTC()))
 	}
 	if filter.ProviderID != nil {
-		where = append(where, q.Eq("ProviderID", filter.ProviderID))
+		where = append(where, storage.Eq("ProviderID", *filter.ProviderID))
 	}
 	if filter.HermesID != nil {
-		where = append(where, q.Eq("HermesID", filter.HermesID))
+		where = append(where, storage.Eq("HermesID", *filter.HermesID))
 	}
 
-	sq := shs.bolt.DB().
-		From(settlementHistoryBucket).
-		Select(q.And(where...)).
-		OrderBy("Time").
-		Reverse()
-
-	err = sq.Find(&result)
-	if errors.Is(err, storm.ErrNotFound) {
+	query := storage.Query{Where: where, OrderBy: "Time", Reverse: true}
+	err = shs.storage.Find(settlementHistoryBucket, query, &result)
+	if errors.Is(err, storage.ErrNotFound) {
 		return []SettlementHistoryEntry{}, nil
 	}
 
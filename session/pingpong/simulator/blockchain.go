@@ -0,0 +1,308 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package simulator
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mysteriumnetwork/payments/bindings"
+	paymentClient "github.com/mysteriumnetwork/payments/client"
+)
+
+// ErrNotSupported is returned for blockchain transactions the node is not supposed to send by itself.
+var ErrNotSupported = errors.New("not supported by the payments simulator")
+
+// Blockchain reads the payment channels of the simulator in place of the blockchain.
+type Blockchain struct {
+	sim *Simulator
+}
+
+var _ paymentClient.BC = (*Blockchain)(nil)
+
+// Blockchain returns the blockchain client backed by the simulator.
+func (s *Simulator) Blockchain() *Blockchain {
+	return &Blockchain{sim: s}
+}
+
+// GetHermesFee returns the fee of simulated Hermes.
+func (b *Blockchain) GetHermesFee(hermesAddress common.Address) (uint16, error) {
+	return b.sim.config.HermesFee, nil
+}
+
+// CalculateHermesFee calculates the fee Hermes takes from the given value.
+func (b *Blockchain) CalculateHermesFee(hermesAddress common.Address, value *big.Int) (*big.Int, error) {
+	return b.sim.hermesFee(value), nil
+}
+
+// IsRegisteredAsProvider checks if the identity is registered.
+func (b *Blockchain) IsRegisteredAsProvider(hermesAddress, registryAddress, addressToCheck common.Address) (bool, error) {
+	return b.IsRegistered(registryAddress, addressToCheck)
+}
+
+// GetProviderChannel returns the provider channel of the identity.
+func (b *Blockchain) GetProviderChannel(hermesAddress common.Address, addressToCheck common.Address, pending bool) (paymentClient.ProviderChannel, error) {
+	b.sim.lock.Lock()
+	defer b.sim.lock.Unlock()
+
+	acc, ok := b.sim.state.Accounts[addressToCheck]
+	if !ok || !acc.Registered {
+		return paymentClient.ProviderChannel{
+			Settled:       new(big.Int),
+			Stake:         new(big.Int),
+			LastUsedNonce: new(big.Int),
+			Timelock:      new(big.Int),
+		}, nil
+	}
+	return paymentClient.ProviderChannel{
+		Settled:       new(big.Int).Set(acc.Settled),
+		Stake:         new(big.Int).Set(acc.Stake),
+		LastUsedNonce: new(big.Int).Set(acc.Nonce),
+		Timelock:      new(big.Int),
+	}, nil
+}
+
+// IsRegistered checks if the identity is registered.
+func (b *Blockchain) IsRegistered(registryAddress, addressToCheck common.Address) (bool, error) {
+	b.sim.lock.Lock()
+	defer b.sim.lock.Unlock()
+
+	acc, ok := b.sim.state.Accounts[addressToCheck]
+	return ok && acc.Registered, nil
+}
+
+// SubscribeToPromiseSettledEvent subscribes to settlements of the provider channel.
+func (b *Blockchain) SubscribeToPromiseSettledEvent(providerID, hermesID common.Address) (chan *bindings.HermesImplementationPromiseSettled, func(), error) {
+	sink, cancel := b.sim.subscribe(b.sim.providerChannel(providerID))
+	return sink, cancel, nil
+}
+
+// GetMystBalance returns the balance of consumer channel.
+func (b *Blockchain) GetMystBalance(mystSCAddress, address common.Address) (*big.Int, error) {
+	b.sim.lock.Lock()
+	defer b.sim.lock.Unlock()
+
+	acc, ok := b.sim.accountByChannel(address)
+	if !ok {
+		return new(big.Int), nil
+	}
+	return new(big.Int).Set(acc.Balance), nil
+}
+
+// SubscribeToConsumerBalanceEvent returns the subscription which receives no events, as top ups go through the simulator.
+func (b *Blockchain) SubscribeToConsumerBalanceEvent(channel, mystSCAddress common.Address, timeout time.Duration) (chan *bindings.MystTokenTransfer, func(), error) {
+	sink, cancel := idleMystTransfers()
+	return sink, cancel, nil
+}
+
+// RegisterIdentity is not supported, identities are registered through transactor.
+func (b *Blockchain) RegisterIdentity(rr paymentClient.RegistrationRequest) (*types.Transaction, error) {
+	return nil, ErrNotSupported
+}
+
+// TransferMyst is not supported.
+func (b *Blockchain) TransferMyst(req paymentClient.TransferRequest) (*types.Transaction, error) {
+	return nil, ErrNotSupported
+}
+
+// IsHermesRegistered checks if the simulated Hermes is asked about.
+func (b *Blockchain) IsHermesRegistered(registryAddress, hermesID common.Address) (bool, error) {
+	return hermesID == b.sim.config.HermesID, nil
+}
+
+// GetHermesOperator returns the operator of simulated Hermes.
+func (b *Blockchain) GetHermesOperator(hermesID common.Address) (common.Address, error) {
+	return b.sim.OperatorAddress(), nil
+}
+
+// SettleAndRebalance is not supported, promises are settled through transactor.
+func (b *Blockchain) SettleAndRebalance(req paymentClient.SettleAndRebalanceRequest) (*types.Transaction, error) {
+	return nil, ErrNotSupported
+}
+
+// SettleWithBeneficiary is not supported, promises are settled through transactor.
+func (b *Blockchain) SettleWithBeneficiary(req paymentClient.SettleWithBeneficiaryRequest) (*types.Transaction, error) {
+	return nil, ErrNotSupported
+}
+
+// GetConsumerChannelsHermes returns the simulated Hermes of consumer channel.
+func (b *Blockchain) GetConsumerChannelsHermes(channelAddress common.Address) (paymentClient.ConsumersHermes, error) {
+	return paymentClient.ConsumersHermes{
+		Operator:        b.sim.OperatorAddress(),
+		ContractAddress: b.sim.config.HermesID,
+		Settled:         new(big.Int),
+	}, nil
+}
+
+// GetConsumerChannelOperator returns the identity owning consumer channel.
+func (b *Blockchain) GetConsumerChannelOperator(channelAddress common.Address) (common.Address, error) {
+	b.sim.lock.Lock()
+	defer b.sim.lock.Unlock()
+
+	acc, ok := b.sim.accountByChannel(channelAddress)
+	if !ok || !acc.Registered {
+		return common.Address{}, errors.New("consumer channel does not exist")
+	}
+	return acc.Identity, nil
+}
+
+// GetProviderChannelByID returns the provider channel with the given ID.
+func (b *Blockchain) GetProviderChannelByID(acc common.Address, chID []byte) (paymentClient.ProviderChannel, error) {
+	b.sim.lock.Lock()
+	var provider common.Address
+	for id := range b.sim.state.Accounts {
+		channelID := b.sim.providerChannel(id)
+		if string(channelID[:]) == string(chID) {
+			provider = id
+			break
+		}
+	}
+	b.sim.lock.Unlock()
+
+	return b.GetProviderChannel(acc, provider, false)
+}
+
+// SubscribeToIdentityRegistrationEvents returns the subscription which receives no events, as registrations complete instantly.
+func (b *Blockchain) SubscribeToIdentityRegistrationEvents(registryAddress common.Address) (chan *bindings.RegistryRegisteredIdentity, func(), error) {
+	sink := make(chan *bindings.RegistryRegisteredIdentity)
+	var once sync.Once
+	return sink, func() {
+		once.Do(func() { close(sink) })
+	}, nil
+}
+
+// SubscribeToConsumerChannelBalanceUpdate returns the subscription which receives no events, as top ups go through the simulator.
+func (b *Blockchain) SubscribeToConsumerChannelBalanceUpdate(mystSCAddress common.Address, channelAddresses []common.Address) (chan *bindings.MystTokenTransfer, func(), error) {
+	sink, cancel := idleMystTransfers()
+	return sink, cancel, nil
+}
+
+// SettlePromise is not supported, promises are settled through transactor.
+func (b *Blockchain) SettlePromise(req paymentClient.SettleRequest) (*types.Transaction, error) {
+	return nil, ErrNotSupported
+}
+
+// SubscribeToPromiseSettledEventByChannelID subscribes to settlements of the given provider channels.
+func (b *Blockchain) SubscribeToPromiseSettledEventByChannelID(hermesID common.Address, providerAddresses [][32]byte) (chan *bindings.HermesImplementationPromiseSettled, func(), error) {
+	sink, cancel := b.sim.subscribe(providerAddresses...)
+	return sink, cancel, nil
+}
+
+// SubscribeToMystTokenTransfers returns the subscription which receives no events, as top ups go through the simulator.
+func (b *Blockchain) SubscribeToMystTokenTransfers(mystSCAddress common.Address) (chan *bindings.MystTokenTransfer, func(), error) {
+	sink, cancel := idleMystTransfers()
+	return sink, cancel, nil
+}
+
+// NetworkID returns the simulated chain ID.
+func (b *Blockchain) NetworkID() (*big.Int, error) {
+	return big.NewInt(b.sim.config.ChainID), nil
+}
+
+// GetConsumerChannel returns the consumer channel, it fails for channels of unregistered identities as they are not deployed.
+func (b *Blockchain) GetConsumerChannel(addr common.Address, mystSCAddress common.Address) (paymentClient.ConsumerChannel, error) {
+	b.sim.lock.Lock()
+	defer b.sim.lock.Unlock()
+
+	acc, ok := b.sim.accountByChannel(addr)
+	if !ok || !acc.Registered {
+		return paymentClient.ConsumerChannel{}, errors.New("consumer channel does not exist")
+	}
+	return paymentClient.ConsumerChannel{
+		Settled: new(big.Int),
+		Balance: new(big.Int).Set(acc.Balance),
+	}, nil
+}
+
+// GetEthBalance returns zero, simulated transactions cost nothing.
+func (b *Blockchain) GetEthBalance(address common.Address) (*big.Int, error) {
+	return new(big.Int), nil
+}
+
+// TransferEth is not supported.
+func (b *Blockchain) TransferEth(etr paymentClient.EthTransferRequest) (*types.Transaction, error) {
+	return nil, ErrNotSupported
+}
+
+// GetHermessAvailableBalance returns the total balance of consumer channels, which backs the promises of simulated Hermes.
+func (b *Blockchain) GetHermessAvailableBalance(hermesAddress common.Address) (*big.Int, error) {
+	b.sim.lock.Lock()
+	defer b.sim.lock.Unlock()
+
+	total := new(big.Int)
+	for _, acc := range b.sim.state.Accounts {
+		total.Add(total, acc.Balance)
+	}
+	return total, nil
+}
+
+// DecreaseProviderStake is not supported, stake is decreased through transactor.
+func (b *Blockchain) DecreaseProviderStake(req paymentClient.DecreaseProviderStakeRequest) (*types.Transaction, error) {
+	return nil, ErrNotSupported
+}
+
+// SettleIntoStake is not supported, promises are settled through transactor.
+func (b *Blockchain) SettleIntoStake(req paymentClient.SettleIntoStakeRequest) (*types.Transaction, error) {
+	return nil, ErrNotSupported
+}
+
+// IncreaseProviderStake is not supported.
+func (b *Blockchain) IncreaseProviderStake(req paymentClient.ProviderStakeIncreaseRequest) (*types.Transaction, error) {
+	return nil, ErrNotSupported
+}
+
+// TransactionReceipt is not supported.
+func (b *Blockchain) TransactionReceipt(hash common.Hash) (*types.Receipt, error) {
+	return nil, ErrNotSupported
+}
+
+// GetHermesURL returns the URL of simulated Hermes.
+func (b *Blockchain) GetHermesURL(registryID, hermesID common.Address) (string, error) {
+	return b.sim.URL() + hermesPath, nil
+}
+
+// GetStakeThresholds returns the stake limits, which are not enforced by the simulator.
+func (b *Blockchain) GetStakeThresholds(hermesID common.Address) (min, max *big.Int, err error) {
+	return new(big.Int), new(big.Int).Set(unlimited), nil
+}
+
+// GetBeneficiary returns the beneficiary of the identity.
+func (b *Blockchain) GetBeneficiary(registryAddress, identity common.Address) (common.Address, error) {
+	b.sim.lock.Lock()
+	defer b.sim.lock.Unlock()
+
+	acc, ok := b.sim.state.Accounts[identity]
+	if !ok || !acc.Registered {
+		return common.Address{}, nil
+	}
+	return acc.Beneficiary, nil
+}
+
+var unlimited = new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil)
+
+func idleMystTransfers() (chan *bindings.MystTokenTransfer, func()) {
+	sink := make(chan *bindings.MystTokenTransfer)
+	var once sync.Once
+	return sink, func() {
+		once.Do(func() { close(sink) })
+	}
+}
@@ -0,0 +1,238 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package simulator
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/payments/crypto"
+
+	"github.com/mysteriumnetwork/node/session/pingpong"
+)
+
+// hermesError is the error returned to Hermes callers along with the cause data, if any.
+type hermesError struct {
+	cause error
+	data  string
+}
+
+func (he hermesError) Error() string {
+	return he.cause.Error()
+}
+
+func (s *Simulator) addHermesRoutes(router *httprouter.Router, prefix string) {
+	router.POST(prefix+"/request_promise", s.handleRequestPromise)
+	router.POST(prefix+"/reveal_r", s.handleRevealR)
+	router.GET(prefix+"/data/consumer/:id", s.handleConsumerData)
+}
+
+func (s *Simulator) handleRequestPromise(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req pingpong.RequestPromise
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeHermesError(w, pingpong.ErrHermesMalformedJSON)
+		return
+	}
+
+	promise, err := s.issuePromise(req)
+	if err != nil {
+		writeHermesError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, promise)
+}
+
+func (s *Simulator) handleRevealR(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req pingpong.RevealObject
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeHermesError(w, pingpong.ErrHermesMalformedJSON)
+		return
+	}
+
+	if err := s.revealR(req); err != nil {
+		writeHermesError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, pingpong.RevealSuccess{Message: "R succesfully revealed"})
+}
+
+func (s *Simulator) handleConsumerData(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	data, err := s.consumerData(common.HexToAddress(params.ByName("id")))
+	if err != nil {
+		writeHermesError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[int64]pingpong.ConsumerData{s.config.ChainID: data})
+}
+
+// issuePromise exchanges the consumer promise to the promise of Hermes to provider.
+func (s *Simulator) issuePromise(req pingpong.RequestPromise) (crypto.Promise, error) {
+	em := req.ExchangeMessage
+	if em.ChainID != s.config.ChainID || em.Promise.Amount == nil || em.AgreementID == nil {
+		return crypto.Promise{}, pingpong.ErrHermesMalformedJSON
+	}
+
+	consumer, err := em.RecoverConsumerIdentity()
+	if err != nil || !em.IsMessageValid(consumer) || !em.Promise.IsPromiseValid(consumer) {
+		return crypto.Promise{}, pingpong.ErrHermesInvalidSignature
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	consumerAcc, ok := s.state.Accounts[consumer]
+	if !ok || !consumerAcc.Registered {
+		return crypto.Promise{}, pingpong.ErrConsumerUnregistered
+	}
+	if em.Promise.Amount.Cmp(consumerAcc.Promised) < 0 {
+		return crypto.Promise{}, pingpong.ErrHermesPromiseValueTooLow
+	}
+	if em.Promise.Amount.Cmp(consumerAcc.Balance) > 0 {
+		return crypto.Promise{}, pingpong.ErrHermesOverspend
+	}
+
+	providerAcc := s.account(common.HexToAddress(em.Provider))
+	if providerAcc.Unrevealed != nil {
+		// Repeated request of the same promise, e.g. after a lost response.
+		if bytes.Equal(providerAcc.Unrevealed.Promise.Hashlock, em.Promise.Hashlock) {
+			return providerAcc.Unrevealed.Promise, nil
+		}
+		return crypto.Promise{}, hermesError{cause: pingpong.ErrNeedsRRecovery, data: providerAcc.Unrevealed.RecoveryData}
+	}
+
+	fee := req.TransactorFee
+	if fee == nil {
+		fee = new(big.Int)
+	}
+	increment := new(big.Int).Sub(em.Promise.Amount, consumerAcc.Promised)
+	amount := new(big.Int).Add(providerAcc.Earned, increment)
+	channelID := s.providerChannel(providerAcc.Identity)
+	promise, err := crypto.CreatePromise(hex.EncodeToString(channelID[:]), s.config.ChainID, amount, fee, hex.EncodeToString(em.Promise.Hashlock), operatorSigner{s.operator}, s.OperatorAddress())
+	if err != nil {
+		return crypto.Promise{}, pingpong.ErrHermesInternal
+	}
+
+	consumerAcc.Promised = new(big.Int).Set(em.Promise.Amount)
+	consumerAcc.LatestPromise = em.Promise
+	providerAcc.Earned = amount
+	providerAcc.Unrevealed = &unrevealed{
+		Promise:      *promise,
+		AgreementID:  em.AgreementID,
+		RecoveryData: req.RRecoveryData,
+	}
+	if err := s.save(); err != nil {
+		return crypto.Promise{}, pingpong.ErrHermesInternal
+	}
+	return *promise, nil
+}
+
+func (s *Simulator) revealR(req pingpong.RevealObject) error {
+	r, err := hex.DecodeString(req.R)
+	if err != nil {
+		return pingpong.ErrHermesMalformedJSON
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	acc, ok := s.state.Accounts[common.HexToAddress(req.Provider)]
+	if !ok || acc.Unrevealed == nil {
+		return pingpong.ErrHermesNoPreviousPromise
+	}
+	if !bytes.Equal(ethcrypto.Keccak256(r), acc.Unrevealed.Promise.Hashlock) {
+		return pingpong.ErrHermesHashlockMissmatch
+	}
+
+	acc.Unrevealed = nil
+	if err := s.save(); err != nil {
+		return pingpong.ErrHermesInternal
+	}
+	return nil
+}
+
+func (s *Simulator) consumerData(id common.Address) (pingpong.ConsumerData, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	acc, ok := s.state.Accounts[id]
+	if !ok || !acc.Registered {
+		return pingpong.ConsumerData{}, pingpong.ErrHermesNotFound
+	}
+
+	data := pingpong.ConsumerData{
+		Identity:    acc.Identity.Hex(),
+		Beneficiary: acc.Beneficiary.Hex(),
+		ChannelID:   s.consumerChannel(acc.Identity).Hex(),
+		Balance:     new(big.Int).Set(acc.Balance),
+		Settled:     new(big.Int),
+		Stake:       new(big.Int).Set(acc.Stake),
+	}
+	if acc.LatestPromise.Amount != nil {
+		data.LatestPromise = pingpong.LatestPromise{
+			ChainID:   acc.LatestPromise.ChainID,
+			ChannelID: hexPrefixed(acc.LatestPromise.ChannelID),
+			Amount:    acc.LatestPromise.Amount,
+			Fee:       acc.LatestPromise.Fee,
+			Hashlock:  hexPrefixed(acc.LatestPromise.Hashlock),
+			Signature: hexPrefixed(acc.LatestPromise.Signature),
+		}
+	}
+	return data, nil
+}
+
+func hexPrefixed(b []byte) string {
+	return "0x" + hex.EncodeToString(b)
+}
+
+func writeHermesError(w http.ResponseWriter, err error) {
+	he := hermesError{cause: err}
+	errors.As(err, &he)
+
+	status := http.StatusBadRequest
+	switch he.cause {
+	case pingpong.ErrHermesNotFound:
+		status = http.StatusNotFound
+	case pingpong.ErrHermesInternal:
+		status = http.StatusInternalServerError
+	case pingpong.ErrTooManyRequests:
+		status = http.StatusTooManyRequests
+	}
+
+	writeJSON(w, status, struct {
+		CausedBy     string `json:"cause"`
+		ErrorMessage string `json:"message"`
+		ErrorData    string `json:"data"`
+	}{
+		CausedBy:     he.cause.Error(),
+		ErrorMessage: he.cause.Error(),
+		ErrorData:    he.data,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package simulator
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+)
+
+// Registry reports the registration status of identities registered in the simulator.
+type Registry struct {
+	sim       *Simulator
+	publisher eventbus.Publisher
+}
+
+var _ registry.IdentityRegistry = (*Registry)(nil)
+
+// Registry returns the identity registry backed by the simulator.
+func (s *Simulator) Registry(publisher eventbus.Publisher) *Registry {
+	return &Registry{sim: s, publisher: publisher}
+}
+
+// GetRegistrationStatus returns the registration status of the identity.
+func (r *Registry) GetRegistrationStatus(chainID int64, id identity.Identity) (registry.RegistrationStatus, error) {
+	registered, err := r.sim.Blockchain().IsRegistered(r.sim.config.RegistryAddress, common.HexToAddress(id.Address))
+	if err != nil || !registered {
+		return registry.Unregistered, err
+	}
+	return registry.Registered, nil
+}
+
+// Subscribe subscribes to registrations sent to transactor, which complete instantly in the simulator.
+func (r *Registry) Subscribe(bus eventbus.Subscriber) error {
+	return bus.Subscribe(registry.AppTopicTransactorRegistration, r.handleRegistrationEvent)
+}
+
+func (r *Registry) handleRegistrationEvent(ev registry.IdentityRegistrationRequest) {
+	id := identity.FromAddress(ev.Identity)
+	status, _ := r.GetRegistrationStatus(ev.ChainID, id)
+	go r.publisher.Publish(registry.AppTopicIdentityRegistration, registry.AppEventIdentityRegistration{
+		ID:      id,
+		Status:  status,
+		ChainID: ev.ChainID,
+	})
+}
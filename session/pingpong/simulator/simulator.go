@@ -0,0 +1,352 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package simulator
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/payments/bindings"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+)
+
+const (
+	// hermesPath is the path Hermes is served on, node always talks to Hermes on api/v2.
+	hermesPath = "/api/v2"
+	// transactorPath is the path transactor is served on.
+	transactorPath = "/transactor"
+
+	stateBucket = "payments-simulator"
+	stateKey    = "state"
+)
+
+// Config represents the payment network simulated by the simulator.
+type Config struct {
+	ChainID               int64
+	HermesID              common.Address
+	RegistryAddress       common.Address
+	ChannelImplementation common.Address
+	// HermesFee is the fee Hermes takes from settlements, in hundredths of percent.
+	HermesFee uint16
+	// TransactorFee is the fee transactor charges for registration, settlement and stake decrease.
+	TransactorFee *big.Int
+	// InitialBalance is the balance consumer channel gets on identity registration.
+	InitialBalance *big.Int
+}
+
+// DefaultConfig returns the simulator config for the given chain and Hermes.
+func DefaultConfig(chainID int64, hermesID, registryAddress, channelImplementation common.Address) Config {
+	return Config{
+		ChainID:               chainID,
+		HermesID:              hermesID,
+		RegistryAddress:       registryAddress,
+		ChannelImplementation: channelImplementation,
+		HermesFee:             1000,
+		TransactorFee:         crypto.FloatToBigMyst(0.01),
+		InitialBalance:        crypto.FloatToBigMyst(10),
+	}
+}
+
+// Simulator simulates Hermes, transactor and payment channels on blockchain in process.
+// It serves the same HTTP API as Hermes and transactor, so the node talks to it as to the real services.
+type Simulator struct {
+	config   Config
+	storage  storage.Storage
+	operator *ecdsa.PrivateKey
+
+	lock  sync.Mutex
+	state state
+
+	subscriptionLock sync.Mutex
+	subscriptions    map[[32]byte][]chan *bindings.HermesImplementationPromiseSettled
+
+	server   *http.Server
+	listener net.Listener
+}
+
+type state struct {
+	OperatorKey string                      `json:"operator_key"`
+	Accounts    map[common.Address]*account `json:"accounts"`
+}
+
+// account keeps both consumer and provider channels of the identity.
+type account struct {
+	Identity     common.Address `json:"identity"`
+	Registered   bool           `json:"registered"`
+	RegisteredAt time.Time      `json:"registered_at"`
+	Beneficiary  common.Address `json:"beneficiary"`
+
+	// Balance is the balance of consumer channel.
+	Balance *big.Int `json:"balance"`
+	// Promised is the grand total promised by consumer to Hermes.
+	Promised      *big.Int       `json:"promised"`
+	LatestPromise crypto.Promise `json:"latest_promise"`
+
+	Stake *big.Int `json:"stake"`
+	// Earned is the amount of the latest promise issued by Hermes to provider.
+	Earned     *big.Int    `json:"earned"`
+	Settled    *big.Int    `json:"settled"`
+	Nonce      *big.Int    `json:"nonce"`
+	Unrevealed *unrevealed `json:"unrevealed,omitempty"`
+}
+
+// unrevealed is the promise issued to provider whose R is not revealed to Hermes yet.
+type unrevealed struct {
+	Promise      crypto.Promise `json:"promise"`
+	AgreementID  *big.Int       `json:"agreement_id"`
+	RecoveryData string         `json:"recovery_data"`
+}
+
+// New creates the simulator. The state is kept in the given storage, if any, so it survives restarts.
+// Otherwise it lives in memory only.
+func New(config Config, db storage.Storage) (*Simulator, error) {
+	s := &Simulator{
+		config:        config,
+		storage:       db,
+		state:         state{Accounts: make(map[common.Address]*account)},
+		subscriptions: make(map[[32]byte][]chan *bindings.HermesImplementationPromiseSettled),
+	}
+
+	if db != nil {
+		err := db.GetValue(stateBucket, stateKey, &s.state)
+		if err != nil && err != storage.ErrNotFound {
+			return nil, errors.Wrap(err, "could not load simulator state")
+		}
+		if s.state.Accounts == nil {
+			s.state.Accounts = make(map[common.Address]*account)
+		}
+	}
+
+	var err error
+	if s.state.OperatorKey == "" {
+		s.operator, err = ethcrypto.GenerateKey()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not generate Hermes operator key")
+		}
+		s.state.OperatorKey = hex.EncodeToString(ethcrypto.FromECDSA(s.operator))
+		if err := s.save(); err != nil {
+			return nil, err
+		}
+	} else {
+		s.operator, err = ethcrypto.HexToECDSA(s.state.OperatorKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not load Hermes operator key")
+		}
+	}
+
+	return s, nil
+}
+
+// Start starts serving Hermes and transactor APIs on the given address.
+func (s *Simulator) Start(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return errors.Wrap(err, "could not start payments simulator")
+	}
+	s.listener = listener
+	s.server = &http.Server{Handler: s.Handler()}
+
+	go func() {
+		if err := s.server.Serve(listener); err != http.ErrServerClosed {
+			log.Error().Err(err).Msg("Payments simulator stopped")
+		}
+	}()
+	log.Warn().Msgf("Payments simulator started on %s, payments are not real", listener.Addr())
+	return nil
+}
+
+// Stop stops serving the APIs.
+func (s *Simulator) Stop() error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Close()
+}
+
+// Handler returns HTTP handler serving Hermes API on api/v2 and transactor API on transactor paths.
+func (s *Simulator) Handler() http.Handler {
+	router := httprouter.New()
+	s.addHermesRoutes(router, hermesPath)
+	s.addTransactorRoutes(router, transactorPath)
+	return router
+}
+
+// URL returns the base URL of the started simulator.
+func (s *Simulator) URL() string {
+	return fmt.Sprintf("http://%s", s.listener.Addr())
+}
+
+// TransactorURL returns the URL of transactor API of the started simulator.
+func (s *Simulator) TransactorURL() string {
+	return s.URL() + transactorPath
+}
+
+// OperatorAddress returns the address of Hermes operator, which signs the promises issued by Hermes.
+func (s *Simulator) OperatorAddress() common.Address {
+	return ethcrypto.PubkeyToAddress(s.operator.PublicKey)
+}
+
+// TopUp adds the given amount to the balance of consumer channel of the identity.
+func (s *Simulator) TopUp(id common.Address, amount *big.Int) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	acc := s.account(id)
+	acc.Balance = new(big.Int).Add(acc.Balance, amount)
+	return s.save()
+}
+
+// account returns the account of the identity, creating unregistered one if there is none.
+func (s *Simulator) account(id common.Address) *account {
+	acc, ok := s.state.Accounts[id]
+	if !ok {
+		acc = &account{
+			Identity:    id,
+			Beneficiary: id,
+			Balance:     new(big.Int),
+			Promised:    new(big.Int),
+			Stake:       new(big.Int),
+			Earned:      new(big.Int),
+			Settled:     new(big.Int),
+			Nonce:       new(big.Int),
+		}
+		s.state.Accounts[id] = acc
+	}
+	return acc
+}
+
+// accountByChannel returns the account owning the given consumer channel.
+func (s *Simulator) accountByChannel(channel common.Address) (*account, bool) {
+	for _, acc := range s.state.Accounts {
+		if s.consumerChannel(acc.Identity) == channel {
+			return acc, true
+		}
+	}
+	return nil, false
+}
+
+func (s *Simulator) consumerChannel(id common.Address) common.Address {
+	addr, err := crypto.GenerateChannelAddress(id.Hex(), s.config.HermesID.Hex(), s.config.RegistryAddress.Hex(), s.config.ChannelImplementation.Hex())
+	if err != nil {
+		return common.Address{}
+	}
+	return common.HexToAddress(addr)
+}
+
+func (s *Simulator) providerChannel(id common.Address) [32]byte {
+	var channelID [32]byte
+	copy(channelID[:], crypto.GenerateProviderChannelIDBytes(id, s.config.HermesID))
+	return channelID
+}
+
+func (s *Simulator) hermesFee(amount *big.Int) *big.Int {
+	fee := new(big.Int).Mul(amount, big.NewInt(int64(s.config.HermesFee)))
+	return fee.Div(fee, big.NewInt(10000))
+}
+
+func (s *Simulator) transactorFee() *big.Int {
+	if s.config.TransactorFee == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(s.config.TransactorFee)
+}
+
+// subscribe subscribes to settlements of the given provider channels, cancel closes the returned channel.
+func (s *Simulator) subscribe(channelIDs ...[32]byte) (chan *bindings.HermesImplementationPromiseSettled, func()) {
+	s.subscriptionLock.Lock()
+	defer s.subscriptionLock.Unlock()
+
+	sink := make(chan *bindings.HermesImplementationPromiseSettled, 10)
+	for _, channelID := range channelIDs {
+		s.subscriptions[channelID] = append(s.subscriptions[channelID], sink)
+	}
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			s.subscriptionLock.Lock()
+			defer s.subscriptionLock.Unlock()
+
+			for _, channelID := range channelIDs {
+				sinks := s.subscriptions[channelID]
+				for i := range sinks {
+					if sinks[i] == sink {
+						s.subscriptions[channelID] = append(sinks[:i], sinks[i+1:]...)
+						break
+					}
+				}
+				if len(s.subscriptions[channelID]) == 0 {
+					delete(s.subscriptions, channelID)
+				}
+			}
+			close(sink)
+		})
+	}
+	return sink, cancel
+}
+
+func (s *Simulator) publishSettlement(ev *bindings.HermesImplementationPromiseSettled) {
+	s.subscriptionLock.Lock()
+	defer s.subscriptionLock.Unlock()
+
+	for _, sink := range s.subscriptions[ev.ChannelId] {
+		select {
+		case sink <- ev:
+		default:
+			log.Warn().Msgf("Dropped settlement event of channel %x, subscriber is not reading", ev.ChannelId)
+		}
+	}
+}
+
+func (s *Simulator) save() error {
+	if s.storage == nil {
+		return nil
+	}
+	return errors.Wrap(s.storage.SetValue(stateBucket, stateKey, s.state), "could not save simulator state")
+}
+
+// txHash returns random hash to stand for the hash of simulated transaction.
+func txHash() common.Hash {
+	var hash common.Hash
+	_, _ = rand.Read(hash[:])
+	return hash
+}
+
+// operatorSigner signs hashes with Hermes operator key for the payments library.
+type operatorSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func (os operatorSigner) SignHash(_ accounts.Account, hash []byte) ([]byte, error) {
+	return ethcrypto.Sign(hash, os.key)
+}
@@ -0,0 +1,251 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package simulator
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	paymentClient "github.com/mysteriumnetwork/payments/client"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/requests"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+)
+
+var (
+	chainID               = int64(5)
+	hermesID              = common.HexToAddress("0x7621a5E6EC206309f4E703A3E6E2bA7d5FB2FA4d")
+	registryAddress       = common.HexToAddress("0x3dD81545F3149538EdCb6691A4FfEE1898Bd2ef0")
+	channelImplementation = common.HexToAddress("0x599d43715DF3070f83355D9D90AE62c159E62A75")
+)
+
+type testKeystore interface {
+	Accounts() []accounts.Account
+	SignHash(a accounts.Account, hash []byte) ([]byte, error)
+}
+
+type testNode struct {
+	ks         testKeystore
+	consumer   common.Address
+	provider   common.Address
+	http       *requests.HTTPClient
+	bc         *paymentClient.MultichainBlockchainClient
+	transactor *registry.Transactor
+	hermes     *pingpong.HermesCaller
+}
+
+func newTestNode(t *testing.T, sim *Simulator) *testNode {
+	ks := identity.NewMockKeystore()
+	consumer, err := ks.NewAccount("")
+	assert.NoError(t, err)
+	assert.NoError(t, ks.Unlock(consumer, ""))
+	provider, err := ks.NewAccount("")
+	assert.NoError(t, err)
+	assert.NoError(t, ks.Unlock(provider, ""))
+
+	httpClient := requests.NewHTTPClient("0.0.0.0", time.Second)
+	bc := paymentClient.NewMultichainBlockchainClient(map[int64]paymentClient.BC{chainID: sim.Blockchain()})
+	hermesURL, err := bc.GetHermesURL(chainID, registryAddress, hermesID)
+	assert.NoError(t, err)
+
+	signerFactory := func(id identity.Identity) identity.Signer {
+		return identity.NewSigner(ks, id)
+	}
+	return &testNode{
+		ks:         ks,
+		consumer:   consumer.Address,
+		provider:   provider.Address,
+		http:       httpClient,
+		bc:         bc,
+		transactor: registry.NewTransactor(httpClient, sim.TransactorURL(), registryAddress.Hex(), hermesID.Hex(), channelImplementation.Hex(), signerFactory, mocks.NewEventBus(), bc),
+		hermes:     pingpong.NewHermesCaller(httpClient, hermesURL),
+	}
+}
+
+func (n *testNode) exchangeMessage(t *testing.T, sim *Simulator, r []byte, agreementID, amount int64) crypto.ExchangeMessage {
+	invoice := crypto.CreateInvoice(big.NewInt(agreementID), big.NewInt(amount), new(big.Int), r, chainID)
+	invoice.Provider = n.provider.Hex()
+	em, err := crypto.CreateExchangeMessage(chainID, invoice, big.NewInt(amount), sim.consumerChannel(n.consumer).Hex(), hermesID.Hex(), n.ks, n.consumer)
+	assert.NoError(t, err)
+	return *em
+}
+
+func newR(t *testing.T) []byte {
+	r := make([]byte, 32)
+	_, err := rand.Read(r)
+	assert.NoError(t, err)
+	return r
+}
+
+func startSimulator(t *testing.T) *Simulator {
+	config := DefaultConfig(chainID, hermesID, registryAddress, channelImplementation)
+	config.HermesFee = 1000
+	config.TransactorFee = big.NewInt(10)
+	config.InitialBalance = big.NewInt(1000)
+
+	sim, err := New(config, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, sim.Start("127.0.0.1:0"))
+	return sim
+}
+
+func TestSimulator_PromiseExchange(t *testing.T) {
+	sim := startSimulator(t)
+	defer sim.Stop()
+	node := newTestNode(t, sim)
+
+	r := newR(t)
+	_, err := node.hermes.RequestPromise(pingpong.RequestPromise{ExchangeMessage: node.exchangeMessage(t, sim, r, 1, 100)})
+	assert.True(t, errors.Is(err, pingpong.ErrConsumerUnregistered))
+
+	assert.NoError(t, node.transactor.RegisterIdentity(node.consumer.Hex(), new(big.Int), big.NewInt(10), "", chainID, nil))
+	status, err := sim.Registry(mocks.NewEventBus()).GetRegistrationStatus(chainID, identity.FromAddress(node.consumer.Hex()))
+	assert.NoError(t, err)
+	assert.Equal(t, registry.Registered, status)
+
+	promise, err := node.hermes.RequestPromise(pingpong.RequestPromise{
+		ExchangeMessage: node.exchangeMessage(t, sim, r, 1, 100),
+		TransactorFee:   big.NewInt(10),
+		RRecoveryData:   "recovery",
+	})
+	assert.NoError(t, err)
+	assert.True(t, promise.IsPromiseValid(sim.OperatorAddress()))
+	assert.Equal(t, big.NewInt(100), promise.Amount)
+	channelID := sim.providerChannel(node.provider)
+	assert.Equal(t, channelID[:], promise.ChannelID)
+
+	// R of the previous promise has to be revealed first.
+	_, err = node.hermes.RequestPromise(pingpong.RequestPromise{ExchangeMessage: node.exchangeMessage(t, sim, newR(t), 1, 150)})
+	var hermesErr pingpong.HermesErrorResponse
+	assert.True(t, errors.As(err, &hermesErr))
+	assert.Equal(t, pingpong.ErrNeedsRRecovery, hermesErr.Cause())
+	assert.Equal(t, "recovery", hermesErr.Data())
+
+	assert.True(t, errors.Is(node.hermes.RevealR(hex.EncodeToString(newR(t)), node.provider.Hex(), big.NewInt(1)), pingpong.ErrHermesHashlockMissmatch))
+	assert.NoError(t, node.hermes.RevealR(hex.EncodeToString(r), node.provider.Hex(), big.NewInt(1)))
+
+	promise, err = node.hermes.RequestPromise(pingpong.RequestPromise{ExchangeMessage: node.exchangeMessage(t, sim, newR(t), 1, 150)})
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(150), promise.Amount)
+
+	_, err = node.hermes.RequestPromise(pingpong.RequestPromise{ExchangeMessage: node.exchangeMessage(t, sim, newR(t), 2, 2000)})
+	assert.True(t, errors.Is(err, pingpong.ErrHermesOverspend))
+
+	data, err := node.hermes.GetConsumerData(chainID, node.consumer.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(150), data.LatestPromise.Amount)
+	assert.Equal(t, big.NewInt(1000), data.Balance)
+}
+
+func TestSimulator_Settlement(t *testing.T) {
+	sim := startSimulator(t)
+	defer sim.Stop()
+	node := newTestNode(t, sim)
+
+	dir := boltdbtest.CreateTempDir(t)
+	defer boltdbtest.RemoveTempDir(t, dir)
+	db, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	assert.NoError(t, node.transactor.RegisterIdentity(node.consumer.Hex(), new(big.Int), big.NewInt(10), "", chainID, nil))
+	assert.NoError(t, node.transactor.RegisterIdentity(node.provider.Hex(), big.NewInt(50), big.NewInt(10), "", chainID, nil))
+
+	r := newR(t)
+	em := node.exchangeMessage(t, sim, r, 1, 500)
+	promise, err := node.hermes.RequestPromise(pingpong.RequestPromise{ExchangeMessage: em, TransactorFee: big.NewInt(10)})
+	assert.NoError(t, err)
+
+	promiseStorage := pingpong.NewHermesPromiseStorage(db)
+	providerChannelID, err := crypto.GenerateProviderChannelID(node.provider.Hex(), hermesID.Hex())
+	assert.NoError(t, err)
+	assert.NoError(t, promiseStorage.Store(pingpong.HermesPromise{
+		ChannelID:   providerChannelID,
+		Identity:    identity.FromAddress(node.provider.Hex()),
+		HermesID:    hermesID,
+		Promise:     promise,
+		R:           hex.EncodeToString(r),
+		AgreementID: em.AgreementID,
+	}))
+	channels := pingpong.NewHermesChannelRepository(promiseStorage, node.bc, mocks.NewEventBus())
+	_, err = channels.Fetch(chainID, identity.FromAddress(node.provider.Hex()), hermesID)
+	assert.NoError(t, err)
+
+	bus := mocks.NewEventBus()
+	history := pingpong.NewSettlementHistoryStorage(db)
+	settler := pingpong.NewHermesPromiseSettler(node.transactor, channels, node.bc, sim.Registry(bus), node.ks, history, bus, pingpong.HermesPromiseSettlerConfig{
+		HermesAddress:        hermesID,
+		MaxWaitForSettlement: 5 * time.Second,
+	})
+
+	assert.NoError(t, settler.ForceSettle(chainID, identity.FromAddress(node.provider.Hex()), hermesID))
+
+	entries, err := history.List(pingpong.SettlementHistoryFilter{})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	// 500 minus 10% of Hermes and 10 of transactor.
+	assert.Equal(t, big.NewInt(440), entries[0].Amount)
+	assert.Equal(t, big.NewInt(500), entries[0].TotalSettled)
+
+	// Provider beneficiary is the consumer channel of the provider by default.
+	balance, err := node.bc.GetMystBalance(chainID, common.Address{}, sim.consumerChannel(node.provider))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1440), balance)
+
+	assert.Equal(t, pingpong.ErrNothingToSettle, settler.ForceSettle(chainID, identity.FromAddress(node.consumer.Hex()), hermesID))
+	assert.Error(t, node.transactor.SettleAndRebalance(hermesID.Hex(), node.provider.Hex(), promise))
+}
+
+func TestSimulator_KeepsStateInStorage(t *testing.T) {
+	dir := boltdbtest.CreateTempDir(t)
+	defer boltdbtest.RemoveTempDir(t, dir)
+	db, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	config := DefaultConfig(chainID, hermesID, registryAddress, channelImplementation)
+	sim, err := New(config, db)
+	assert.NoError(t, err)
+	id := common.HexToAddress("0x1")
+	assert.NoError(t, sim.register(registry.IdentityRegistrationRequest{Identity: id.Hex()}))
+	assert.NoError(t, sim.TopUp(id, big.NewInt(5)))
+
+	restarted, err := New(config, db)
+	assert.NoError(t, err)
+	assert.Equal(t, sim.OperatorAddress(), restarted.OperatorAddress())
+
+	registered, err := restarted.Blockchain().IsRegistered(registryAddress, id)
+	assert.NoError(t, err)
+	assert.True(t, registered)
+	balance, err := restarted.Blockchain().GetMystBalance(common.Address{}, sim.consumerChannel(id))
+	assert.NoError(t, err)
+	assert.Equal(t, new(big.Int).Add(config.InitialBalance, big.NewInt(5)), balance)
+}
@@ -0,0 +1,278 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package simulator
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/payments/bindings"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/mysteriumnetwork/node/identity/registry"
+)
+
+var (
+	errInvalidPromise       = errors.New("promise is not signed by hermes operator")
+	errProviderUnregistered = errors.New("provider is not registered")
+	errForeignChannel       = errors.New("promise is not issued to the provider channel")
+	errNothingToSettle      = errors.New("promise is already settled")
+	errFeesNotCovered       = errors.New("settled amount does not cover the fees")
+	errStakeTooLow          = errors.New("stake is lower than the requested decrease")
+	errReferralUnsupported  = errors.New("referral tokens are not supported by the payments simulator")
+)
+
+func (s *Simulator) addTransactorRoutes(router *httprouter.Router, prefix string) {
+	router.GET(prefix+"/fee/:chain/register", s.handleFees)
+	router.GET(prefix+"/fee/:chain/settle", s.handleFees)
+	router.GET(prefix+"/fee/:chain/stake/decrease", s.handleFees)
+	router.POST(prefix+"/identity/register", s.handleRegister)
+	router.POST(prefix+"/identity/register/referer", s.handleRegister)
+	router.POST(prefix+"/identity/register/bounty", s.handleBounty)
+	router.GET(prefix+"/identity/:id/status", s.handleRegistrationStatus)
+	router.POST(prefix+"/identity/settle_and_rebalance", s.handleSettle)
+	router.POST(prefix+"/identity/settle/into_stake", s.handleSettleIntoStake)
+	router.POST(prefix+"/identity/settle_with_beneficiary", s.handleSettleWithBeneficiary)
+	router.POST(prefix+"/stake/decrease", s.handleDecreaseStake)
+	router.GET(prefix+"/referal/:token/reward", s.handleTokenReward)
+	router.POST(prefix+"/rp/tokens/request", s.handleReferralToken)
+}
+
+func (s *Simulator) handleFees(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	writeJSON(w, http.StatusOK, registry.FeesResponse{
+		Fee:        s.transactorFee(),
+		ValidUntil: time.Now().Add(time.Hour),
+	})
+}
+
+func (s *Simulator) handleRegister(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req registry.IdentityRegistrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeTransactorError(w, err)
+		return
+	}
+	if err := s.register(req); err != nil {
+		writeTransactorError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Simulator) handleBounty(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func (s *Simulator) handleRegistrationStatus(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	statuses := []registry.TransactorStatusResponse{}
+	if acc, ok := s.state.Accounts[common.HexToAddress(params.ByName("id"))]; ok && acc.Registered {
+		statuses = append(statuses, registry.TransactorStatusResponse{
+			IdentityID:   acc.Identity.Hex(),
+			Status:       registry.TransactorRegistrationEntryStatusSucceed,
+			TxHash:       txHash().Hex(),
+			CreatedAt:    acc.RegisteredAt,
+			UpdatedAt:    acc.RegisteredAt,
+			BountyAmount: new(big.Int),
+			ChainID:      s.config.ChainID,
+		})
+	}
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (s *Simulator) handleSettle(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req registry.PromiseSettlementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeTransactorError(w, err)
+		return
+	}
+	if err := s.settle(req.ProviderID, req.ChainID, req, nil, false); err != nil {
+		writeTransactorError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Simulator) handleSettleIntoStake(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req registry.PromiseSettlementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeTransactorError(w, err)
+		return
+	}
+	if err := s.settle(req.ProviderID, req.ChainID, req, nil, true); err != nil {
+		writeTransactorError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Simulator) handleSettleWithBeneficiary(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req registry.SettleWithBeneficiaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeTransactorError(w, err)
+		return
+	}
+	beneficiary := common.HexToAddress(req.Beneficiary)
+	if err := s.settle(req.ProviderID, req.ChainID, req.Promise, &beneficiary, false); err != nil {
+		writeTransactorError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Simulator) handleDecreaseStake(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req registry.DecreaseProviderStakeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeTransactorError(w, err)
+		return
+	}
+	if err := s.decreaseStake(common.HexToAddress(req.ProviderID), new(big.Int).SetUint64(req.Amount)); err != nil {
+		writeTransactorError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Simulator) handleTokenReward(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	writeJSON(w, http.StatusOK, registry.TokenRewardResponse{Reward: new(big.Int)})
+}
+
+func (s *Simulator) handleReferralToken(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	writeTransactorError(w, errReferralUnsupported)
+}
+
+// register registers the identity, crediting its consumer channel with the initial balance.
+func (s *Simulator) register(req registry.IdentityRegistrationRequest) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	acc := s.account(common.HexToAddress(req.Identity))
+	if acc.Registered {
+		return nil
+	}
+
+	acc.Registered = true
+	acc.RegisteredAt = time.Now().UTC()
+	acc.Beneficiary = s.consumerChannel(acc.Identity)
+	if common.IsHexAddress(req.Beneficiary) && common.HexToAddress(req.Beneficiary) != (common.Address{}) {
+		acc.Beneficiary = common.HexToAddress(req.Beneficiary)
+	}
+	if s.config.InitialBalance != nil {
+		acc.Balance = new(big.Int).Add(acc.Balance, s.config.InitialBalance)
+	}
+	if req.Stake != nil {
+		acc.Stake = new(big.Int).Set(req.Stake)
+	}
+	return s.save()
+}
+
+// settle settles the promise issued by Hermes to provider, transferring the unsettled amount without fees
+// to the beneficiary, or to the stake.
+func (s *Simulator) settle(providerID string, chainID int64, req registry.PromiseSettlementRequest, beneficiary *common.Address, intoStake bool) error {
+	if req.Amount == nil || req.TransactorFee == nil {
+		return errors.New("promise amount and fee are required")
+	}
+	promise, err := crypto.NewPromise(chainID, req.ChannelID, req.Amount, req.TransactorFee, req.Preimage, req.Signature)
+	if err != nil {
+		return err
+	}
+	if !promise.IsPromiseValid(s.OperatorAddress()) {
+		return errInvalidPromise
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	acc, ok := s.state.Accounts[common.HexToAddress(providerID)]
+	if !ok || !acc.Registered {
+		return errProviderUnregistered
+	}
+	channelID := s.providerChannel(acc.Identity)
+	if !bytes.Equal(promise.ChannelID, channelID[:]) {
+		return errForeignChannel
+	}
+
+	amount := new(big.Int).Sub(promise.Amount, acc.Settled)
+	if amount.Sign() <= 0 {
+		return errNothingToSettle
+	}
+	fees := new(big.Int).Add(s.hermesFee(amount), promise.Fee)
+	if fees.Cmp(amount) > 0 {
+		return errFeesNotCovered
+	}
+	sent := new(big.Int).Sub(amount, fees)
+
+	if beneficiary != nil {
+		acc.Beneficiary = *beneficiary
+		acc.Nonce = new(big.Int).Add(acc.Nonce, big.NewInt(1))
+	}
+	acc.Settled = new(big.Int).Set(promise.Amount)
+	// Settlement reveals R on blockchain.
+	if acc.Unrevealed != nil && bytes.Equal(acc.Unrevealed.Promise.Hashlock, promise.Hashlock) {
+		acc.Unrevealed = nil
+	}
+	if intoStake {
+		acc.Stake = new(big.Int).Add(acc.Stake, sent)
+	} else if channelOwner, ok := s.accountByChannel(acc.Beneficiary); ok {
+		channelOwner.Balance = new(big.Int).Add(channelOwner.Balance, sent)
+	}
+	if err := s.save(); err != nil {
+		return err
+	}
+
+	s.publishSettlement(&bindings.HermesImplementationPromiseSettled{
+		ChannelId:               channelID,
+		Beneficiary:             acc.Beneficiary,
+		AmountSentToBeneficiary: sent,
+		Fees:                    fees,
+		Raw:                     types.Log{TxHash: txHash()},
+	})
+	return nil
+}
+
+func (s *Simulator) decreaseStake(providerID common.Address, amount *big.Int) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	acc, ok := s.state.Accounts[providerID]
+	if !ok || !acc.Registered {
+		return errProviderUnregistered
+	}
+	if acc.Stake.Cmp(amount) < 0 {
+		return errStakeTooLow
+	}
+	acc.Stake = new(big.Int).Sub(acc.Stake, amount)
+	acc.Nonce = new(big.Int).Add(acc.Nonce, big.NewInt(1))
+	return s.save()
+}
+
+func writeTransactorError(w http.ResponseWriter, err error) {
+	writeJSON(w, http.StatusBadRequest, struct {
+		Error string `json:"error"`
+	}{
+		Error: err.Error(),
+	})
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrSpendingLimitReached represents an error when paying the invoice would exceed the spending limit of the consumer.
+var ErrSpendingLimitReached = errors.New("spending limit reached")
+
+type consumedSessionStorage interface {
+	List(filter *session.Filter) ([]session.History, error)
+}
+
+// ConfiguredSpendingLimits returns the spending limits set in the config, tightened by the given ones if any.
+func ConfiguredSpendingLimits(limits *connection.SpendingLimits) connection.SpendingLimits {
+	configured := connection.SpendingLimits{
+		Session:     mystCap(config.GetFloat64(config.FlagPaymentsConsumerLimitSession)),
+		Day:         mystCap(config.GetFloat64(config.FlagPaymentsConsumerLimitDay)),
+		Month:       mystCap(config.GetFloat64(config.FlagPaymentsConsumerLimitMonth)),
+		PerProvider: config.GetBool(config.FlagPaymentsConsumerLimitPerProvider),
+	}
+	if limits == nil {
+		return configured
+	}
+	return configured.Tighten(*limits)
+}
+
+func mystCap(myst float64) *big.Int {
+	if myst <= 0 {
+		return nil
+	}
+	return crypto.FloatToBigMyst(myst)
+}
+
+// checkSpendingLimits checks whether paying the invoice keeps the spending of the consumer within the limits.
+// The spending of the current session is taken from the invoice, the one of the other sessions from the session history.
+func (ip *InvoicePayer) checkSpendingLimits(invoice crypto.Invoice) error {
+	limits := ip.deps.SpendingLimits
+	spent := invoice.AgreementTotal
+	if exceeds(spent, limits.Session) {
+		return ip.spendingLimitReached(event.SpendingLimitSession, limits.Session, spent)
+	}
+	if limits.Day == nil && limits.Month == nil {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	filter := session.NewFilter().
+		SetDirection(session.DirectionConsumed).
+		SetConsumerID(ip.deps.Identity).
+		SetStartedFrom(dayStart)
+	if limits.Month != nil {
+		filter.SetStartedFrom(monthStart)
+	}
+	if limits.PerProvider {
+		filter.SetProviderID(ip.deps.Peer)
+	}
+	sessions, err := ip.deps.SessionHistory.List(filter)
+	if err != nil {
+		return fmt.Errorf("could not get consumed sessions: %w", err)
+	}
+
+	spentDay, spentMonth := new(big.Int).Set(spent), new(big.Int).Set(spent)
+	for _, s := range sessions {
+		if string(s.SessionID) == ip.getSessionID() || s.Tokens == nil {
+			continue
+		}
+		spentMonth.Add(spentMonth, s.Tokens)
+		if !s.Started.Before(dayStart) {
+			spentDay.Add(spentDay, s.Tokens)
+		}
+	}
+
+	if exceeds(spentDay, limits.Day) {
+		return ip.spendingLimitReached(event.SpendingLimitDay, limits.Day, spentDay)
+	}
+	if exceeds(spentMonth, limits.Month) {
+		return ip.spendingLimitReached(event.SpendingLimitMonth, limits.Month, spentMonth)
+	}
+	return nil
+}
+
+func (ip *InvoicePayer) spendingLimitReached(limit string, cap, spent *big.Int) error {
+	log.Warn().Msgf("Refusing to pay invoice, %s spending limit %v would be exceeded: %v", limit, cap, spent)
+	ip.deps.EventBus.Publish(event.AppTopicSpendingLimitReached, event.AppEventSpendingLimitReached{
+		ConsumerID: ip.deps.Identity,
+		ProviderID: ip.deps.Peer,
+		SessionID:  ip.getSessionID(),
+		Limit:      limit,
+		Cap:        cap,
+		Spent:      spent,
+	})
+	return errors.Wrapf(ErrSpendingLimitReached, "%s limit", limit)
+}
+
+func exceeds(spent, cap *big.Int) bool {
+	return cap != nil && spent != nil && spent.Cmp(cap) > 0
+}
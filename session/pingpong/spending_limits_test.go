@@ -0,0 +1,114 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSessionHistory struct {
+	sessions []session.History
+	filter   *session.Filter
+}
+
+func (msh *mockSessionHistory) List(filter *session.Filter) ([]session.History, error) {
+	msh.filter = filter
+	return msh.sessions, nil
+}
+
+func TestInvoicePayer_checkSpendingLimits(t *testing.T) {
+	now := time.Now().UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	history := &mockSessionHistory{
+		sessions: []session.History{
+			{SessionID: "current", Tokens: big.NewInt(1000), Started: now},
+			{SessionID: "today", Tokens: big.NewInt(30), Started: now},
+			{SessionID: "this-month", Tokens: big.NewInt(50), Started: dayStart.Add(-time.Nanosecond)},
+		},
+	}
+
+	for _, tc := range []struct {
+		name   string
+		limits connection.SpendingLimits
+		total  int64
+		limit  string
+	}{
+		{name: "unlimited", total: 1000},
+		{name: "within session cap", limits: connection.SpendingLimits{Session: big.NewInt(10)}, total: 10},
+		{name: "exceeds session cap", limits: connection.SpendingLimits{Session: big.NewInt(10)}, total: 11, limit: event.SpendingLimitSession},
+		{name: "within daily cap", limits: connection.SpendingLimits{Day: big.NewInt(40)}, total: 10},
+		{name: "exceeds daily cap", limits: connection.SpendingLimits{Day: big.NewInt(40)}, total: 11, limit: event.SpendingLimitDay},
+		{name: "within monthly cap", limits: connection.SpendingLimits{Month: big.NewInt(90)}, total: 10},
+		{name: "exceeds monthly cap", limits: connection.SpendingLimits{Month: big.NewInt(90)}, total: 11, limit: event.SpendingLimitMonth},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mp := &mockPublisher{publicationChan: make(chan testEvent, 1)}
+			ip := NewInvoicePayer(InvoicePayerDeps{
+				EventBus:       mp,
+				Identity:       identity.FromAddress("0x1"),
+				Peer:           identity.FromAddress("0x2"),
+				SessionID:      "current",
+				SpendingLimits: tc.limits,
+				SessionHistory: history,
+			})
+
+			err := ip.checkSpendingLimits(crypto.Invoice{AgreementTotal: big.NewInt(tc.total)})
+
+			if tc.limit == "" {
+				assert.NoError(t, err)
+				assert.Len(t, mp.publicationChan, 0)
+				return
+			}
+			assert.True(t, errors.Is(err, ErrSpendingLimitReached))
+			ev := <-mp.publicationChan
+			assert.Equal(t, event.AppTopicSpendingLimitReached, ev.name)
+			reached := ev.value.(event.AppEventSpendingLimitReached)
+			assert.Equal(t, tc.limit, reached.Limit)
+			assert.Equal(t, "current", reached.SessionID)
+			assert.Equal(t, identity.FromAddress("0x2"), reached.ProviderID)
+		})
+	}
+}
+
+func TestInvoicePayer_checkSpendingLimits_PerProvider(t *testing.T) {
+	history := &mockSessionHistory{}
+	ip := NewInvoicePayer(InvoicePayerDeps{
+		EventBus:       &mockPublisher{},
+		Identity:       identity.FromAddress("0x1"),
+		Peer:           identity.FromAddress("0x2"),
+		SpendingLimits: connection.SpendingLimits{Day: big.NewInt(40), PerProvider: true},
+		SessionHistory: history,
+	})
+
+	err := ip.checkSpendingLimits(crypto.Invoice{AgreementTotal: big.NewInt(10)})
+
+	assert.NoError(t, err)
+	assert.Equal(t, identity.FromAddress("0x2"), *history.filter.ProviderID)
+	assert.Equal(t, identity.FromAddress("0x1"), *history.filter.ConsumerID)
+	assert.Equal(t, session.DirectionConsumed, *history.filter.Direction)
+}
@@ -0,0 +1,108 @@
+// +build linux,!android
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sleep
+
+import (
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// checkInterval is how often the clocks are compared, it bounds the delay of the wake-up notification.
+	checkInterval = 2 * time.Second
+	// sleepThreshold is the least time spent suspended to be considered a sleep.
+	sleepThreshold = time.Second
+)
+
+// Start starts sleep events notifier. Linux stops the monotonic clock while suspended,
+// so the sleep is detected as the time passed on the boot clock, but not on the monotonic one.
+func (n *Notifier) Start() {
+	log.Debug().Msg("Register for sleep events")
+	detector := newSleepDetector(readClocks)
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-n.stop:
+			return
+		case <-ticker.C:
+		}
+
+		if slept, ok := detector.check(); ok {
+			log.Info().Msgf("Woke up after %s of sleep", slept)
+			n.eventBus.Publish(AppTopicSleepNotification, EventWakeup)
+		}
+	}
+}
+
+// Stop stops sleep events notifier
+func (n *Notifier) Stop() {
+	n.stopOnce.Do(func() {
+		log.Debug().Msg("Unregister sleep events")
+		close(n.stop)
+	})
+}
+
+type clocksFunc func() (boot, monotonic time.Duration, err error)
+
+func readClocks() (boot, monotonic time.Duration, err error) {
+	var ts unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_BOOTTIME, &ts); err != nil {
+		return 0, 0, err
+	}
+	boot = time.Duration(ts.Nano())
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &ts); err != nil {
+		return 0, 0, err
+	}
+	return boot, time.Duration(ts.Nano()), nil
+}
+
+type sleepDetector struct {
+	clocks             clocksFunc
+	lastBoot, lastMono time.Duration
+	initialized        bool
+}
+
+func newSleepDetector(clocks clocksFunc) *sleepDetector {
+	d := &sleepDetector{clocks: clocks}
+	d.check()
+	return d
+}
+
+// check returns the time spent suspended since the previous check, if it was long enough to be a sleep.
+func (d *sleepDetector) check() (time.Duration, bool) {
+	boot, mono, err := d.clocks()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to read clocks for sleep detection")
+		return 0, false
+	}
+	defer func() {
+		d.lastBoot, d.lastMono, d.initialized = boot, mono, true
+	}()
+	if !d.initialized {
+		return 0, false
+	}
+
+	slept := (boot - d.lastBoot) - (mono - d.lastMono)
+	return slept, slept >= sleepThreshold
+}
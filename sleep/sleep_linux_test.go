@@ -0,0 +1,55 @@
+// +build linux,!android
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sleep
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSleepDetector(t *testing.T) {
+	var boot, mono time.Duration
+	var err error
+	detector := newSleepDetector(func() (time.Duration, time.Duration, error) {
+		return boot, mono, err
+	})
+
+	boot, mono = 2*time.Second, 2*time.Second
+	_, slept := detector.check()
+	assert.False(t, slept)
+
+	boot, mono = 10*time.Minute, 4*time.Second
+	duration, slept := detector.check()
+	assert.True(t, slept)
+	assert.Equal(t, 10*time.Minute-2*time.Second-2*time.Second, duration)
+
+	err = errors.New("clock unavailable")
+	boot = 20 * time.Minute
+	_, slept = detector.check()
+	assert.False(t, slept)
+
+	err = nil
+	boot, mono = 10*time.Minute+2*time.Second+100*time.Millisecond, 6*time.Second
+	_, slept = detector.check()
+	assert.False(t, slept)
+}
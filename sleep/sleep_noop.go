@@ -1,4 +1,4 @@
-// +build !darwin,!windows
+// +build !darwin,!windows,!linux android
 
 /*
  * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
@@ -127,13 +127,23 @@ func configureNetwork(cfg wgcfg.DeviceConfig, dnsManager dns.Manager) error {
 	if err := netutil.AssignIP(cfg.IfaceName, cfg.Subnet); err != nil {
 		return fmt.Errorf("failed to assign IP address: %w", err)
 	}
+	if cfg.Subnet6.IP != nil {
+		if err := netutil.AssignIP(cfg.IfaceName, cfg.Subnet6); err != nil {
+			return fmt.Errorf("failed to assign IPv6 address: %w", err)
+		}
+	}
 
 	if cfg.Peer.Endpoint != nil {
-		if err := netutil.ExcludeRoute(cfg.Peer.Endpoint.IP); err != nil {
-			return fmt.Errorf("could not exclude route %s: %w", cfg.Peer.Endpoint.IP.String(), err)
-		}
-		if err := netutil.AddDefaultRoute(cfg.IfaceName); err != nil {
-			return fmt.Errorf("could not add default route for %s: %w", cfg.IfaceName, err)
+		err := netutil.ConfigureTunnelRoutes(netutil.TunnelRoutes{
+			Iface:      cfg.IfaceName,
+			Peer:       cfg.Peer.Endpoint.IP,
+			OuterIface: cfg.OuterIface,
+			Include:    cfg.IncludeRoutes,
+			Exclude:    cfg.ExcludeRoutes,
+			IPv6:       cfg.Subnet6.IP != nil,
+		})
+		if err != nil {
+			return err
 		}
 	}
 
@@ -101,6 +101,46 @@ func (client *Client) AuthChangePassword(request contract.ChangePasswordRequest)
 	return nil
 }
 
+// SetToken sets the token used to authorize the requests, e.g. API token.
+func (client *Client) SetToken(token string) {
+	client.http.SetToken(token)
+}
+
+// AuthListTokens lists API tokens
+func (client *Client) AuthListTokens() (res contract.APITokenListResponse, err error) {
+	response, err := client.http.Get("/auth/tokens", url.Values{})
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// AuthCreateToken creates API token, the token is only returned once
+func (client *Client) AuthCreateToken(request contract.APITokenRequest) (res contract.APITokenDTO, err error) {
+	response, err := client.http.Post("/auth/tokens", request)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// AuthRevokeToken revokes API token
+func (client *Client) AuthRevokeToken(name string) error {
+	response, err := client.http.Delete("/auth/tokens/"+url.PathEscape(name), nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
 // GetIdentities returns a list of client identities
 func (client *Client) GetIdentities() (ids []contract.IdentityRefDTO, err error) {
 	response, err := client.http.Get("identities", url.Values{})
@@ -55,3 +55,49 @@ type ChangePasswordRequest struct {
 	OldPassword string `json:"old_password"`
 	NewPassword string `json:"new_password"`
 }
+
+// APITokenRequest request used to create API token.
+// swagger:model APITokenRequest
+type APITokenRequest struct {
+	// example: dashboard
+	Name string `json:"name"`
+
+	// Any of read-only, connection-control, service-control, payments and admin.
+	// example: ["read-only"]
+	Scopes []string `json:"scopes"`
+}
+
+// APITokenDTO represents API token, the token itself is only set when created.
+// swagger:model APITokenDTO
+type APITokenDTO struct {
+	// example: dashboard
+	Name string `json:"name"`
+
+	// example: myst_8e2c9f3c0a6b4d1e
+	Token string `json:"token,omitempty"`
+
+	// example: ["read-only"]
+	Scopes []string `json:"scopes"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	CreatedAt string `json:"created_at"`
+}
+
+// NewAPITokenDTO maps to API token.
+func NewAPITokenDTO(token auth.APIToken) APITokenDTO {
+	scopes := make([]string, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	return APITokenDTO{
+		Name:      token.Name,
+		Scopes:    scopes,
+		CreatedAt: token.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// APITokenListResponse lists API tokens.
+// swagger:model APITokenListResponse
+type APITokenListResponse struct {
+	Tokens []APITokenDTO `json:"tokens"`
+}
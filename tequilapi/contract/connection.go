@@ -46,9 +46,45 @@ func NewConnectionInfoDTO(session connectionstate.Status) ConnectionInfoDTO {
 		proposalRes := NewProposalDTO(session.Proposal)
 		response.Proposal = &proposalRes
 	}
+	for _, hop := range session.Hops {
+		response.Hops = append(response.Hops, NewConnectionHopDTO(hop))
+	}
+	return response
+}
+
+// NewConnectionHopDTO maps to API connection hop.
+func NewConnectionHopDTO(hop connectionstate.HopStatus) ConnectionHopDTO {
+	response := ConnectionHopDTO{
+		Status:        string(hop.State),
+		SessionID:     string(hop.SessionID),
+		BytesSent:     hop.Statistics.BytesSent,
+		BytesReceived: hop.Statistics.BytesReceived,
+	}
+	if hop.Proposal.ProviderID != "" {
+		proposalRes := NewProposalDTO(hop.Proposal)
+		response.Proposal = &proposalRes
+	}
 	return response
 }
 
+// ConnectionHopDTO holds details of a single provider session in multi-hop connection.
+// swagger:model ConnectionHopDTO
+type ConnectionHopDTO struct {
+	// example: Connected
+	Status string `json:"status"`
+
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	SessionID string `json:"session_id,omitempty"`
+
+	Proposal *ProposalDTO `json:"proposal,omitempty"`
+
+	// example: 1024
+	BytesSent uint64 `json:"bytes_sent"`
+
+	// example: 1024
+	BytesReceived uint64 `json:"bytes_received"`
+}
+
 // ConnectionInfoDTO holds partial consumer connection details.
 // swagger:model ConnectionInfoDTO
 type ConnectionInfoDTO struct {
@@ -66,6 +102,9 @@ type ConnectionInfoDTO struct {
 
 	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
 	SessionID string `json:"session_id,omitempty"`
+
+	// hops of multi-hop connection, starting with the entry one
+	Hops []ConnectionHopDTO `json:"hops,omitempty"`
 }
 
 // NewConnectionDTO maps to API connection.
@@ -151,6 +190,11 @@ type ConnectionCreateRequest struct {
 	// example: openvpn
 	ServiceType string `json:"service_type"`
 
+	// entry provider identity, connection to the provider is tunneled through it when set
+	// required: false
+	// example: 0x0000000000000000000000000000000000000004
+	EntryProviderID string `json:"entry_provider_id,omitempty"`
+
 	// connect options
 	// required: false
 	ConnectOptions ConnectOptions `json:"connect_options,omitempty"`
@@ -165,9 +209,97 @@ func (cr ConnectionCreateRequest) Validate() *validation.FieldErrorMap {
 	if len(cr.ProviderID) == 0 {
 		errs.ForField("provider_id").Required()
 	}
+	if len(cr.EntryProviderID) != 0 && cr.EntryProviderID == cr.ProviderID {
+		errs.ForField("entry_provider_id").Invalid("Entry provider must differ from the exit one")
+	}
+	if _, err := connection.NewDNSOption(string(cr.ConnectOptions.DNS)); err != nil {
+		errs.ForField("connect_options.dns").Invalid(err.Error())
+	}
+	for _, route := range cr.ConnectOptions.IncludeRoutes {
+		if err := connection.ValidateRoute(route); err != nil {
+			errs.ForField("connect_options.include_routes").Invalid(err.Error())
+		}
+	}
+	for _, route := range cr.ConnectOptions.ExcludeRoutes {
+		if err := connection.ValidateRoute(route); err != nil {
+			errs.ForField("connect_options.exclude_routes").Invalid(err.Error())
+		}
+	}
+	if len(cr.ConnectOptions.IncludeApplications) != 0 {
+		errs.ForField("connect_options.include_applications").Invalid(connection.ErrApplicationSplitTunnel.Error())
+	}
+	if len(cr.ConnectOptions.ExcludeApplications) != 0 {
+		errs.ForField("connect_options.exclude_applications").Invalid(connection.ErrApplicationSplitTunnel.Error())
+	}
+	if proxy := cr.ConnectOptions.Proxy; proxy != nil {
+		if proxy.SOCKS5Address == "" && proxy.HTTPAddress == "" {
+			errs.ForField("connect_options.proxy").Required()
+		}
+		if len(cr.EntryProviderID) != 0 || len(cr.ConnectOptions.IncludeRoutes) != 0 || len(cr.ConnectOptions.ExcludeRoutes) != 0 {
+			errs.ForField("connect_options.proxy").Invalid("Proxy mode can not be combined with multi-hop connection or split tunneling")
+		}
+		if proxy.SOCKS5Address != "" {
+			if err := connection.ValidateProxyAddress(proxy.SOCKS5Address); err != nil {
+				errs.ForField("connect_options.proxy.socks5_address").Invalid(err.Error())
+			}
+		}
+		if proxy.HTTPAddress != "" {
+			if err := connection.ValidateProxyAddress(proxy.HTTPAddress); err != nil {
+				errs.ForField("connect_options.proxy.http_address").Invalid(err.Error())
+			}
+		}
+	}
+	if limits := cr.ConnectOptions.SpendingLimits; limits != nil {
+		if limits.Session < 0 || limits.Day < 0 || limits.Month < 0 {
+			errs.ForField("connect_options.spending_limits").Invalid("Spending caps can not be negative")
+		}
+	}
 	return errs
 }
 
+// ConnectParams maps the request options to the connection parameters.
+func (cr ConnectionCreateRequest) ConnectParams() connection.ConnectParams {
+	dns := connection.DNSOptionAuto
+	if cr.ConnectOptions.DNS != "" {
+		dns = cr.ConnectOptions.DNS
+	}
+
+	params := connection.ConnectParams{
+		DisableKillSwitch:   cr.ConnectOptions.DisableKillSwitch,
+		DNS:                 dns,
+		IncludeRoutes:       cr.ConnectOptions.IncludeRoutes,
+		ExcludeRoutes:       cr.ConnectOptions.ExcludeRoutes,
+		IncludeApplications: cr.ConnectOptions.IncludeApplications,
+		ExcludeApplications: cr.ConnectOptions.ExcludeApplications,
+		DNSBlocking:         cr.ConnectOptions.DNSBlocking,
+	}
+	if cr.ConnectOptions.Failover {
+		params.Failover = &connection.FailoverPolicy{}
+	}
+	if proxy := cr.ConnectOptions.Proxy; proxy != nil {
+		params.Proxy = &connection.ProxyParams{
+			SOCKS5Address: proxy.SOCKS5Address,
+			HTTPAddress:   proxy.HTTPAddress,
+		}
+	}
+	if limits := cr.ConnectOptions.SpendingLimits; limits != nil {
+		params.SpendingLimits = &connection.SpendingLimits{
+			Session:     mystCap(limits.Session),
+			Day:         mystCap(limits.Day),
+			Month:       mystCap(limits.Month),
+			PerProvider: limits.PerProvider,
+		}
+	}
+	return params
+}
+
+func mystCap(myst float64) *big.Int {
+	if myst <= 0 {
+		return nil
+	}
+	return crypto.FloatToBigMyst(myst)
+}
+
 // ConnectOptions holds tequilapi connect options
 // swagger:model ConnectOptionsDTO
 type ConnectOptions struct {
@@ -178,6 +310,68 @@ type ConnectOptions struct {
 	// DNS to use
 	// required: false
 	// default: auto
-	// example: auto, provider, system, "1.1.1.1,8.8.8.8"
+	// example: auto, provider, system, "1.1.1.1,8.8.8.8", "doh://1.1.1.1/dns-query,dot://9.9.9.9"
 	DNS connection.DNSOption `json:"dns"`
+	// CIDRs, IP addresses and domain names routed through the tunnel, all traffic is routed when empty.
+	// required: false
+	// example: ["10.0.0.0/8", "git.example.com"]
+	IncludeRoutes []string `json:"include_routes,omitempty"`
+	// CIDRs, IP addresses and domain names routed outside of the tunnel
+	// required: false
+	// example: ["192.168.1.0/24", "intranet.example.com"]
+	ExcludeRoutes []string `json:"exclude_routes,omitempty"`
+	// applications routed through the tunnel, per application split tunneling is not supported and requests with it are rejected
+	// required: false
+	IncludeApplications []string `json:"include_applications,omitempty"`
+	// applications routed outside of the tunnel, per application split tunneling is not supported and requests with it are rejected
+	// required: false
+	ExcludeApplications []string `json:"exclude_applications,omitempty"`
+	// reconnect to an equivalent provider of the same service type, country and not higher price once the current one stops responding
+	// required: false
+	// example: true
+	Failover bool `json:"failover,omitempty"`
+	// terminate the tunnel inside the node and expose it as local proxies instead of routing the system traffic through it
+	// required: false
+	Proxy *ProxyOptions `json:"proxy,omitempty"`
+	// caps the spending of the connection on top of the configured ones, the connection is disconnected once a cap would be exceeded
+	// required: false
+	SpendingLimits *SpendingLimitsOptions `json:"spending_limits,omitempty"`
+	// block the DNS names listed in the node blocklists, the node configuration decides when omitted
+	// required: false
+	// example: true
+	DNSBlocking *bool `json:"dns_blocking,omitempty"`
+}
+
+// SpendingLimitsOptions holds spending caps in MYST, they can only tighten the configured caps
+// swagger:model SpendingLimitsOptionsDTO
+type SpendingLimitsOptions struct {
+	// caps the spending of a single session, unlimited when zero
+	// required: false
+	// example: 0.5
+	Session float64 `json:"session,omitempty"`
+	// caps the spending of the sessions started during the current UTC day, unlimited when zero
+	// required: false
+	// example: 2
+	Day float64 `json:"day,omitempty"`
+	// caps the spending of the sessions started during the current UTC month, unlimited when zero
+	// required: false
+	// example: 20
+	Month float64 `json:"month,omitempty"`
+	// apply the daily and monthly caps to each provider separately
+	// required: false
+	// example: false
+	PerProvider bool `json:"per_provider,omitempty"`
+}
+
+// ProxyOptions holds listen addresses of the local proxies, at least one of them is required
+// swagger:model ProxyOptionsDTO
+type ProxyOptions struct {
+	// loopback listen address of the SOCKS5 proxy, it is not started when empty
+	// required: false
+	// example: 127.0.0.1:1081
+	SOCKS5Address string `json:"socks5_address,omitempty"`
+	// loopback listen address of the HTTP CONNECT proxy, it is not started when empty
+	// required: false
+	// example: 127.0.0.1:8081
+	HTTPAddress string `json:"http_address,omitempty"`
 }
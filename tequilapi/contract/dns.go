@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/dns"
+)
+
+// DNSBlocklistDTO describes the DNS blocklists and the queries blocked by them.
+// swagger:model DNSBlocklistDTO
+type DNSBlocklistDTO struct {
+	// example: ["https://example.com/hosts", "/etc/mysterium-node/blocklist.txt"]
+	Sources []string `json:"sources"`
+
+	// number of the listed DNS names
+	// example: 84210
+	Domains int `json:"domains"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	UpdatedAt string `json:"updated_at,omitempty"`
+
+	// blocked queries by consumer connection ("consumer") and by service instance ID
+	// example: {"consumer": 12, "6ba7b810-9dad-11d1-80b4-00c04fd430c8": 305}
+	Hits map[string]uint64 `json:"hits"`
+}
+
+// NewDNSBlocklistDTO maps to DNS blocklist stats.
+func NewDNSBlocklistDTO(stats dns.BlocklistStats) DNSBlocklistDTO {
+	dto := DNSBlocklistDTO{
+		Sources: stats.Sources,
+		Domains: stats.Domains,
+		Hits:    stats.Hits,
+	}
+	if dto.Sources == nil {
+		dto.Sources = []string{}
+	}
+	if dto.Hits == nil {
+		dto.Hits = map[string]uint64{}
+	}
+	if !stats.UpdatedAt.IsZero() {
+		dto.UpdatedAt = stats.UpdatedAt.Format(time.RFC3339)
+	}
+	return dto
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/journal"
+)
+
+// JournalEntryDTO represents the recorded event bus event.
+// swagger:model JournalEntryDTO
+type JournalEntryDTO struct {
+	// sequential ID of the entry, used to resume reading
+	// example: 1042
+	ID uint64 `json:"id"`
+
+	// example: Session change
+	Topic string `json:"topic"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	Time string `json:"time"`
+
+	// published event
+	Payload json.RawMessage `json:"payload"`
+}
+
+// NewJournalEntryDTO maps to journal entry.
+func NewJournalEntryDTO(entry journal.Entry) JournalEntryDTO {
+	return JournalEntryDTO{
+		ID:      entry.ID,
+		Topic:   entry.Topic,
+		Time:    entry.Time.Format(time.RFC3339Nano),
+		Payload: json.RawMessage(entry.Payload),
+	}
+}
+
+// JournalEntryListResponse represents the list of journal entries.
+// swagger:model JournalEntryListResponse
+type JournalEntryListResponse struct {
+	Entries []JournalEntryDTO `json:"entries"`
+}
@@ -34,6 +34,7 @@ func NewProposalDTO(p market.ServiceProposal) ProposalDTO {
 		ServiceDefinition: NewServiceDefinitionDTO(p.ServiceDefinition),
 		AccessPolicies:    p.AccessPolicies,
 		PaymentMethod:     NewPaymentMethodDTO(p.PaymentMethod),
+		BandwidthKbps:     p.BandwidthKbps,
 	}
 }
 
@@ -57,8 +58,13 @@ func NewServiceDefinitionDTO(s market.ServiceDefinition) ServiceDefinitionDTO {
 	if s == nil {
 		return ServiceDefinitionDTO{}
 	}
+	var ipv6 bool
+	if service, ok := s.(market.IPv6ServiceDefinition); ok {
+		ipv6 = service.SupportsIPv6()
+	}
 	return ServiceDefinitionDTO{
 		LocationOriginate: NewServiceLocationsDTO(s.GetLocation()),
+		IPv6:              ipv6,
 	}
 }
 
@@ -107,6 +113,10 @@ type ProposalDTO struct {
 
 	// PaymentMethod
 	PaymentMethod PaymentMethodDTO `json:"payment_method"`
+
+	// bandwidth limit of every session in Kbps, sessions are not limited when zero
+	// example: 5000
+	BandwidthKbps uint64 `json:"bandwidth_kbps,omitempty"`
 }
 
 func (p ProposalDTO) String() string {
@@ -117,6 +127,8 @@ func (p ProposalDTO) String() string {
 // swagger:model ServiceDefinitionDTO
 type ServiceDefinitionDTO struct {
 	LocationOriginate ServiceLocationDTO `json:"location_originate"`
+	// IPv6 is set when the service routes IPv6 traffic
+	IPv6 bool `json:"ipv6,omitempty"`
 }
 
 // ServiceLocationDTO holds service location metadata.
@@ -0,0 +1,141 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/webhook"
+)
+
+// WebhookRequest request used to create webhook.
+// swagger:model WebhookRequest
+type WebhookRequest struct {
+	// absolute HTTP(S) URL the events are posted to
+	// example: https://ops.example.com/myst-hooks
+	URL string `json:"url"`
+
+	// names of the posted events, "*" suffix matches a group of events, all events are posted when empty
+	// example: ["session.started", "balance.low", "settlement.failed"]
+	Events []string `json:"events"`
+
+	// HMAC-SHA256 secret of the X-Mysterium-Signature header, generated when empty
+	// example: 2f1c6a0e9d3b4c7f
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookDTO represents the webhook.
+// swagger:model WebhookDTO
+type WebhookDTO struct {
+	// example: 6ba7b810-9dad-11d1-80b4-00c04fd430c8
+	ID string `json:"id"`
+
+	// example: https://ops.example.com/myst-hooks
+	URL string `json:"url"`
+
+	// example: ["session.started", "balance.low", "settlement.failed"]
+	Events []string `json:"events"`
+
+	// only returned when the webhook is created
+	// example: 2f1c6a0e9d3b4c7f
+	Secret string `json:"secret,omitempty"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	CreatedAt string `json:"created_at"`
+}
+
+// NewWebhookDTO maps to webhook, leaving out its secret.
+func NewWebhookDTO(hook webhook.Webhook) WebhookDTO {
+	events := hook.Events
+	if events == nil {
+		events = []string{}
+	}
+	return WebhookDTO{
+		ID:        hook.ID,
+		URL:       hook.URL,
+		Events:    events,
+		CreatedAt: hook.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// WebhookListResponse represents the list of webhooks.
+// swagger:model WebhookListResponse
+type WebhookListResponse struct {
+	Webhooks []WebhookDTO `json:"webhooks"`
+}
+
+// WebhookDeliveryDTO represents the delivery of an event to the webhook.
+// swagger:model WebhookDeliveryDTO
+type WebhookDeliveryDTO struct {
+	// example: 7c9e6679-7425-40de-944b-e07fc1f90ae7
+	ID string `json:"id"`
+
+	// example: 6ba7b810-9dad-11d1-80b4-00c04fd430c8
+	WebhookID string `json:"webhook_id"`
+
+	// example: session.started
+	Event string `json:"event"`
+
+	// body posted to the webhook
+	Payload json.RawMessage `json:"payload"`
+
+	// example: pending
+	Status string `json:"status"`
+
+	// example: 2
+	Attempts int `json:"attempts"`
+
+	// set while the delivery is pending
+	// example: 2019-06-06T11:04:43.910035Z
+	NextAttemptAt string `json:"next_attempt_at,omitempty"`
+
+	// example: unexpected HTTP status 503 Service Unavailable
+	LastError string `json:"last_error,omitempty"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	CreatedAt string `json:"created_at"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	UpdatedAt string `json:"updated_at"`
+}
+
+// NewWebhookDeliveryDTO maps to webhook delivery.
+func NewWebhookDeliveryDTO(delivery webhook.Delivery) WebhookDeliveryDTO {
+	dto := WebhookDeliveryDTO{
+		ID:        delivery.ID,
+		WebhookID: delivery.WebhookID,
+		Event:     delivery.Event,
+		Payload:   json.RawMessage(delivery.Payload),
+		Status:    string(delivery.Status),
+		Attempts:  delivery.Attempts,
+		LastError: delivery.LastError,
+		CreatedAt: delivery.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: delivery.UpdatedAt.Format(time.RFC3339),
+	}
+	if delivery.Status == webhook.DeliveryPending {
+		dto.NextAttemptAt = delivery.NextAttempt.Format(time.RFC3339)
+	}
+	return dto
+}
+
+// WebhookDeliveryListResponse represents the list of webhook deliveries.
+// swagger:model WebhookDeliveryListResponse
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryDTO `json:"deliveries"`
+}
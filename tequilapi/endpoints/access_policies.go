@@ -21,8 +21,10 @@ import (
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/requests"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/rs/zerolog/log"
 )
 
 // swagger:model AccessPolicies
@@ -35,6 +37,7 @@ type accessPolicy struct {
 	Title       string       `json:"title"`
 	Description string       `json:"description"`
 	Allow       []accessRule `json:"allow"`
+	Deny        []accessRule `json:"deny,omitempty"`
 }
 
 type accessRule struct {
@@ -42,23 +45,29 @@ type accessRule struct {
 	Value string `json:"value"`
 }
 
+type localPolicies interface {
+	List() ([]market.AccessPolicyRuleSet, error)
+}
+
 type accessPoliciesEndpoint struct {
 	httpClient              *requests.HTTPClient
 	accessPolicyEndpointURL string
+	localPolicies           localPolicies
 }
 
 // NewAccessPoliciesEndpoint creates and returns access policies endpoint
-func NewAccessPoliciesEndpoint(httpClient *requests.HTTPClient, accessPolicyEndpointURL string) *accessPoliciesEndpoint {
+func NewAccessPoliciesEndpoint(httpClient *requests.HTTPClient, accessPolicyEndpointURL string, localPolicies localPolicies) *accessPoliciesEndpoint {
 	return &accessPoliciesEndpoint{
 		httpClient:              httpClient,
 		accessPolicyEndpointURL: accessPolicyEndpointURL,
+		localPolicies:           localPolicies,
 	}
 }
 
 // swagger:operation GET /access-policies AccessPolicies
 // ---
 // summary: Returns access policies
-// description: Returns list of access policies
+// description: Returns list of access policies, followed by the ones loaded from local files
 // responses:
 //   200:
 //     description: List of access policies
@@ -69,23 +78,56 @@ func NewAccessPoliciesEndpoint(httpClient *requests.HTTPClient, accessPolicyEndp
 //     schema:
 //       "$ref": "#/definitions/ErrorMessageDTO"
 func (ape *accessPoliciesEndpoint) List(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
-	req, err := requests.NewGetRequest(ape.accessPolicyEndpointURL, "", nil)
+	localRules, err := ape.localPolicies.List()
 	if err != nil {
 		utils.SendError(resp, err, http.StatusInternalServerError)
 		return
 	}
-	r := accessPolicyCollection{}
-	err = ape.httpClient.DoRequestAndParseResponse(req, &r)
+
+	r, err := ape.fetchRemote()
 	if err != nil {
-		utils.SendError(resp, err, http.StatusInternalServerError)
-		return
+		// Local policies are still listed while the remote ones are unavailable.
+		if len(localRules) == 0 {
+			utils.SendError(resp, err, http.StatusInternalServerError)
+			return
+		}
+		log.Warn().Err(err).Msg("Failed to fetch remote access policies, listing local ones only")
+		r = accessPolicyCollection{Entries: []accessPolicy{}}
+	}
+
+	for _, rules := range localRules {
+		r.Entries = append(r.Entries, accessPolicy{
+			ID:          rules.ID,
+			Title:       rules.Title,
+			Description: rules.Description,
+			Allow:       toAccessRules(rules.Allow),
+			Deny:        toAccessRules(rules.Deny),
+		})
 	}
 
 	utils.WriteAsJSON(r, resp)
 }
 
+func (ape *accessPoliciesEndpoint) fetchRemote() (accessPolicyCollection, error) {
+	r := accessPolicyCollection{}
+	req, err := requests.NewGetRequest(ape.accessPolicyEndpointURL, "", nil)
+	if err != nil {
+		return r, err
+	}
+	err = ape.httpClient.DoRequestAndParseResponse(req, &r)
+	return r, err
+}
+
+func toAccessRules(rules []market.AccessRule) []accessRule {
+	result := make([]accessRule, len(rules))
+	for i, rule := range rules {
+		result[i] = accessRule{Type: rule.Type, Value: rule.Value}
+	}
+	return result
+}
+
 // AddRoutesForAccessPolicies attaches access policies endpoints to router
-func AddRoutesForAccessPolicies(httpClient *requests.HTTPClient, router *httprouter.Router, accessPolicyEndpointURL string) {
-	ape := NewAccessPoliciesEndpoint(httpClient, accessPolicyEndpointURL)
+func AddRoutesForAccessPolicies(httpClient *requests.HTTPClient, router *httprouter.Router, accessPolicyEndpointURL string, localPolicies localPolicies) {
+	ape := NewAccessPoliciesEndpoint(httpClient, accessPolicyEndpointURL, localPolicies)
 	router.GET("/access-policies", ape.List)
 }
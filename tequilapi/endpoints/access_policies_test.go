@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/requests"
 	"github.com/stretchr/testify/assert"
 )
@@ -49,7 +50,7 @@ func Test_Get_AccessPolicies_ReturnsAccessPolicies(t *testing.T) {
 	server := newTestServer(http.StatusOK, mockResponse)
 
 	router := httprouter.New()
-	AddRoutesForAccessPolicies(requests.NewHTTPClient(bindAllAddress, requests.DefaultTimeout), router, server.URL)
+	AddRoutesForAccessPolicies(requests.NewHTTPClient(bindAllAddress, requests.DefaultTimeout), router, server.URL, &mockLocalPolicies{})
 
 	req, err := http.NewRequest(
 		http.MethodGet,
@@ -69,7 +70,7 @@ func Test_Get_AccessPolicies_WhenRequestFails_ReturnsError(t *testing.T) {
 	server := newTestServer(http.StatusInternalServerError, `{"error": "something bad"}`)
 
 	router := httprouter.New()
-	AddRoutesForAccessPolicies(requests.NewHTTPClient(bindAllAddress, requests.DefaultTimeout), router, server.URL)
+	AddRoutesForAccessPolicies(requests.NewHTTPClient(bindAllAddress, requests.DefaultTimeout), router, server.URL, &mockLocalPolicies{})
 
 	req, err := http.NewRequest(
 		http.MethodGet,
@@ -84,6 +85,100 @@ func Test_Get_AccessPolicies_WhenRequestFails_ReturnsError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
 }
 
+func Test_Get_AccessPolicies_ListsLocalPolicies(t *testing.T) {
+	server := newTestServer(http.StatusOK, `{"entries": []}`)
+	local := &mockLocalPolicies{
+		rules: []market.AccessPolicyRuleSet{
+			{
+				ID:    "local:mail",
+				Title: "Mail",
+				Deny:  []market.AccessRule{{Type: market.AccessPolicyTypeDestinationPort, Value: "tcp:25"}},
+			},
+		},
+	}
+
+	router := httprouter.New()
+	AddRoutesForAccessPolicies(requests.NewHTTPClient(bindAllAddress, requests.DefaultTimeout), router, server.URL, local)
+
+	req, err := http.NewRequest(
+		http.MethodGet,
+		"/access-policies",
+		nil,
+	)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"entries": [
+				{
+					"id": "local:mail",
+					"title": "Mail",
+					"description": "",
+					"allow": [],
+					"deny": [{"type": "dst_port", "value": "tcp:25"}]
+				}
+			]
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func Test_Get_AccessPolicies_WhenRequestFails_ListsLocalPolicies(t *testing.T) {
+	server := newTestServer(http.StatusInternalServerError, `{"error": "something bad"}`)
+	local := &mockLocalPolicies{
+		rules: []market.AccessPolicyRuleSet{
+			{
+				ID:    "local:mail",
+				Title: "Mail",
+				Deny:  []market.AccessRule{{Type: market.AccessPolicyTypeDestinationPort, Value: "tcp:25"}},
+			},
+		},
+	}
+
+	router := httprouter.New()
+	AddRoutesForAccessPolicies(requests.NewHTTPClient(bindAllAddress, requests.DefaultTimeout), router, server.URL, local)
+
+	req, err := http.NewRequest(
+		http.MethodGet,
+		"/access-policies",
+		nil,
+	)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"entries": [
+				{
+					"id": "local:mail",
+					"title": "Mail",
+					"description": "",
+					"allow": [],
+					"deny": [{"type": "dst_port", "value": "tcp:25"}]
+				}
+			]
+		}`,
+		resp.Body.String(),
+	)
+}
+
+type mockLocalPolicies struct {
+	rules []market.AccessPolicyRuleSet
+}
+
+func (m *mockLocalPolicies) List() ([]market.AccessPolicyRuleSet, error) {
+	return m.rules, nil
+}
+
 func newTestServer(mockStatus int, mockResponse string) *httptest.Server {
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(mockStatus)
@@ -19,6 +19,7 @@ package endpoints
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
@@ -32,12 +33,19 @@ import (
 type authenticationAPI struct {
 	jwtAuthenticator jwtAuthenticator
 	authenticator    authenticator
+	apiTokens        apiTokens
 }
 
 type jwtAuthenticator interface {
 	CreateToken(username string) (auth.JWT, error)
 }
 
+type apiTokens interface {
+	Create(name string, scopes []auth.Scope) (string, auth.APIToken, error)
+	List() ([]auth.APIToken, error)
+	Revoke(name string) error
+}
+
 type authenticator interface {
 	CheckCredentials(username, password string) error
 	ChangePassword(username, oldPassword, newPassword string) error
@@ -198,6 +206,129 @@ func (api *authenticationAPI) ChangePassword(httpRes http.ResponseWriter, httpRe
 	}
 }
 
+// swagger:operation GET /auth/tokens Authentication listAPITokens
+// ---
+// summary: List API tokens
+// description: Lists named API tokens, without the tokens themselves
+// responses:
+//   200:
+//     description: List of API tokens
+//     schema:
+//       "$ref": "#/definitions/APITokenListResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (api *authenticationAPI) ListTokens(httpRes http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	tokens, err := api.apiTokens.List()
+	if err != nil {
+		utils.SendError(httpRes, err, http.StatusInternalServerError)
+		return
+	}
+
+	response := contract.APITokenListResponse{Tokens: []contract.APITokenDTO{}}
+	for _, token := range tokens {
+		response.Tokens = append(response.Tokens, contract.NewAPITokenDTO(token))
+	}
+	utils.WriteAsJSON(response, httpRes)
+}
+
+// swagger:operation POST /auth/tokens Authentication createAPIToken
+// ---
+// summary: Create API token
+// description: Creates named long-lived API token with the given scopes, the token is only returned once
+// parameters:
+//   - in: body
+//     name: body
+//     schema:
+//       $ref: "#/definitions/APITokenRequest"
+// responses:
+//   200:
+//     description: API token created
+//     schema:
+//       "$ref": "#/definitions/APITokenDTO"
+//   400:
+//     description: Body parsing error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   409:
+//     description: API token with the same name already exists
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (api *authenticationAPI) CreateToken(httpRes http.ResponseWriter, httpReq *http.Request, _ httprouter.Params) {
+	var req contract.APITokenRequest
+	if err := json.NewDecoder(httpReq.Body).Decode(&req); err != nil {
+		utils.SendError(httpRes, err, http.StatusBadRequest)
+		return
+	}
+
+	scopes := make([]auth.Scope, 0, len(req.Scopes))
+	for _, name := range req.Scopes {
+		scope, err := auth.ParseScope(name)
+		if err != nil {
+			utils.SendError(httpRes, err, http.StatusBadRequest)
+			return
+		}
+		scopes = append(scopes, scope)
+	}
+	if req.Name == "" || len(scopes) == 0 {
+		utils.SendErrorMessage(httpRes, "name and at least one scope are required", http.StatusBadRequest)
+		return
+	}
+
+	token, apiToken, err := api.apiTokens.Create(req.Name, scopes)
+	if errors.Is(err, auth.ErrAPITokenExists) {
+		utils.SendError(httpRes, err, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		utils.SendError(httpRes, err, http.StatusInternalServerError)
+		return
+	}
+
+	response := contract.NewAPITokenDTO(apiToken)
+	response.Token = token
+	utils.WriteAsJSON(response, httpRes)
+}
+
+// swagger:operation DELETE /auth/tokens/{name} Authentication revokeAPIToken
+// ---
+// summary: Revoke API token
+// description: Revokes named API token
+// parameters:
+//   - in: path
+//     name: name
+//     description: Name of the API token
+//     type: string
+//     required: true
+// responses:
+//   202:
+//     description: API token revoked
+//   404:
+//     description: API token not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (api *authenticationAPI) RevokeToken(httpRes http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	err := api.apiTokens.Revoke(params.ByName("name"))
+	if errors.Is(err, auth.ErrAPITokenNotFound) {
+		utils.SendError(httpRes, err, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.SendError(httpRes, err, http.StatusInternalServerError)
+		return
+	}
+	httpRes.WriteHeader(http.StatusAccepted)
+}
+
 func toAuthRequest(req *http.Request) (contract.AuthRequest, error) {
 	var request contract.AuthRequest
 	err := json.NewDecoder(req.Body).Decode(&request)
@@ -223,13 +354,18 @@ func AddRoutesForAuthentication(
 	router *httprouter.Router,
 	auth authenticator,
 	jwtAuth jwtAuthenticator,
+	tokens apiTokens,
 ) {
 	api := &authenticationAPI{
 		authenticator:    auth,
 		jwtAuthenticator: jwtAuth,
+		apiTokens:        tokens,
 	}
 	router.PUT("/auth/password", api.ChangePassword)
 	router.POST(TequilapiAuthenticateEndpointPath, api.Authenticate)
 	router.POST(TequilapiLoginEndpointPath, api.Login)
 	router.DELETE("/auth/logout", api.Logout)
+	router.GET("/auth/tokens", api.ListTokens)
+	router.POST("/auth/tokens", api.CreateToken)
+	router.DELETE("/auth/tokens/:name", api.RevokeToken)
 }
@@ -19,6 +19,7 @@ package endpoints
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -32,7 +33,6 @@ import (
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
-	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
 
@@ -169,14 +169,35 @@ func (ce *ConnectionEndpoint) Create(resp http.ResponseWriter, req *http.Request
 		return
 	}
 
-	err = ce.manager.Connect(consumerID, common.HexToAddress(cr.HermesID), *proposal, getConnectOptions(cr))
+	connectParams := cr.ConnectParams()
+	if cr.EntryProviderID != "" {
+		entryProposal, err := ce.proposalRepository.Proposal(market.ProposalID{
+			ProviderID:  cr.EntryProviderID,
+			ServiceType: cr.ServiceType,
+		})
+		if err != nil {
+			utils.SendError(resp, err, http.StatusInternalServerError)
+			return
+		}
+		if entryProposal == nil {
+			utils.SendError(resp, errors.New("entry provider has no service proposals"), http.StatusBadRequest)
+			return
+		}
+		connectParams.EntryProposal = entryProposal
+	}
+
+	err = ce.manager.Connect(consumerID, common.HexToAddress(cr.HermesID), *proposal, connectParams)
 
 	if err != nil {
-		switch err {
-		case connection.ErrAlreadyExists:
+		switch {
+		case err == connection.ErrAlreadyExists:
 			utils.SendError(resp, err, http.StatusConflict)
-		case connection.ErrConnectionCancelled:
+		case err == connection.ErrConnectionCancelled:
 			utils.SendError(resp, err, statusConnectCancelled)
+		case errors.Is(err, connection.ErrUnsupportedMultiHop), err == connection.ErrMultiHopIncludeRoutes,
+			errors.Is(err, connection.ErrUnsupportedProxy), err == connection.ErrProxyUnsupportedParams,
+			err == connection.ErrApplicationSplitTunnel:
+			utils.SendError(resp, err, http.StatusBadRequest)
 		default:
 			log.Error().Err(err).Msg("")
 			utils.SendError(resp, err, http.StatusInternalServerError)
@@ -262,15 +283,3 @@ func toConnectionRequest(req *http.Request) (*contract.ConnectionCreateRequest,
 	}
 	return &connectionRequest, nil
 }
-
-func getConnectOptions(cr *contract.ConnectionCreateRequest) connection.ConnectParams {
-	dns := connection.DNSOptionAuto
-	if cr.ConnectOptions.DNS != "" {
-		dns = cr.ConnectOptions.DNS
-	}
-
-	return connection.ConnectParams{
-		DisableKillSwitch: cr.ConnectOptions.DisableKillSwitch,
-		DNS:               dns,
-	}
-}
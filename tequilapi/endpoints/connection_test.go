@@ -50,6 +50,8 @@ type mockConnectionManager struct {
 	requestedProvider    identity.Identity
 	requestedHermesID    common.Address
 	requestedServiceType string
+	requestedEntry       *market.ServiceProposal
+	requestedParams      connection.ConnectParams
 }
 
 func (cm *mockConnectionManager) Connect(consumerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal, options connection.ConnectParams) error {
@@ -57,6 +59,8 @@ func (cm *mockConnectionManager) Connect(consumerID identity.Identity, hermesID
 	cm.requestedHermesID = hermesID
 	cm.requestedProvider = identity.FromAddress(proposal.ProviderID)
 	cm.requestedServiceType = proposal.ServiceType
+	cm.requestedEntry = options.EntryProposal
+	cm.requestedParams = options
 	return cm.onConnectReturn
 }
 
@@ -77,6 +81,10 @@ func (cm *mockConnectionManager) Reconnect() {
 	return
 }
 
+func (cm *mockConnectionManager) Resume() error {
+	return nil
+}
+
 func (cm *mockConnectionManager) Wait() error {
 	return nil
 }
@@ -347,6 +355,231 @@ func TestPutWithServiceTypeOverridesDefault(t *testing.T) {
 	assert.Equal(t, "noop", fakeManager.requestedServiceType)
 }
 
+func TestPutWithEntryProviderCreatesMultiHopConnection(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+
+	repository := mockRepositoryWithProposal("exit-node", "wireguard")
+	repository.proposals = append(repository.proposals, market.ServiceProposal{
+		ID:                2,
+		ServiceType:       "wireguard",
+		ServiceDefinition: TestServiceDefinition{},
+		ProviderID:        "entry-node",
+	})
+	connEndpoint := NewConnectionEndpoint(&fakeManager, &mockStateProvider{}, repository, mockIdentityRegistryInstance)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "exit-node",
+				"entry_provider_id" : "entry-node",
+				"hermes_id": "hermes",
+				"service_type": "wireguard"
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, identity.FromAddress("exit-node"), fakeManager.requestedProvider)
+	if assert.NotNil(t, fakeManager.requestedEntry) {
+		assert.Equal(t, "entry-node", fakeManager.requestedEntry.ProviderID)
+	}
+}
+
+func TestPutWithSameEntryAndExitProviderReturns422(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, mockRepositoryWithProposal("node", "wireguard"), mockIdentityRegistryInstance)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "node",
+				"entry_provider_id" : "node",
+				"service_type": "wireguard"
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Nil(t, fakeManager.requestedEntry)
+}
+
+func TestPutWithSplitTunnelRoutesCreatesConnection(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, mockRepositoryWithProposal("node", "wireguard"), mockIdentityRegistryInstance)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "node",
+				"service_type": "wireguard",
+				"connect_options": {
+					"include_routes": ["8.8.8.0/24"],
+					"exclude_routes": ["192.168.1.0/24", "intranet.example.com"]
+				}
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, []string{"8.8.8.0/24"}, fakeManager.requestedParams.IncludeRoutes)
+	assert.Equal(t, []string{"192.168.1.0/24", "intranet.example.com"}, fakeManager.requestedParams.ExcludeRoutes)
+}
+
+func TestPutWithApplicationSplitTunnelIsRejected(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, mockRepositoryWithProposal("node", "wireguard"), mockIdentityRegistryInstance)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "node",
+				"service_type": "wireguard",
+				"connect_options": {
+					"include_applications": ["firefox"]
+				}
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), connection.ErrApplicationSplitTunnel.Error())
+	assert.Nil(t, fakeManager.requestedParams.IncludeApplications)
+}
+
+func TestPutWithFailoverCreatesConnection(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, mockRepositoryWithProposal("node", "wireguard"), mockIdentityRegistryInstance)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "node",
+				"service_type": "wireguard",
+				"connect_options": {
+					"failover": true
+				}
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, &connection.FailoverPolicy{}, fakeManager.requestedParams.Failover)
+}
+
+func TestPutWithProxyCreatesConnection(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, mockRepositoryWithProposal("node", "wireguard"), mockIdentityRegistryInstance)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "node",
+				"service_type": "wireguard",
+				"connect_options": {
+					"proxy": {"socks5_address": "127.0.0.1:1081", "http_address": "127.0.0.1:8081"}
+				}
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(
+		t,
+		&connection.ProxyParams{SOCKS5Address: "127.0.0.1:1081", HTTPAddress: "127.0.0.1:8081"},
+		fakeManager.requestedParams.Proxy,
+	)
+}
+
+func TestPutWithInvalidProxyReturns422(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, mockRepositoryWithProposal("node", "wireguard"), mockIdentityRegistryInstance)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "node",
+				"service_type": "wireguard",
+				"connect_options": {
+					"proxy": {"socks5_address": "1081", "http_address": "0.0.0.0:8081"}
+				}
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "validation_error",
+			"errors": {
+				"connect_options.proxy.socks5_address": [ {"code": "invalid", "message": "address 1081: missing port in address"} ],
+				"connect_options.proxy.http_address": [ {"code": "invalid", "message": "proxy must listen on a loopback address: 0.0.0.0:8081"} ]
+			}
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestPutWithInvalidSplitTunnelRouteReturns422(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, mockRepositoryWithProposal("node", "wireguard"), mockIdentityRegistryInstance)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "node",
+				"service_type": "wireguard",
+				"connect_options": {
+					"exclude_routes": ["10.0.0.0/33"]
+				}
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "validation_error",
+			"errors": {
+				"connect_options.exclude_routes": [ {"code": "invalid", "message": "invalid CIDR address: 10.0.0.0/33"} ]
+			}
+		}`,
+		resp.Body.String(),
+	)
+}
+
 func TestDeleteCallsDisconnect(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
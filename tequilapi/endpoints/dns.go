@@ -0,0 +1,72 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/dns"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type blocklistStats interface {
+	Stats() dns.BlocklistStats
+}
+
+// DNSEndpoint struct represents endpoints about DNS filtering
+type DNSEndpoint struct {
+	blocklist blocklistStats
+}
+
+// NewDNSEndpoint creates and returns DNS endpoint, blocklist is nil when no blocklists are configured.
+func NewDNSEndpoint(blocklist blocklistStats) *DNSEndpoint {
+	return &DNSEndpoint{
+		blocklist: blocklist,
+	}
+}
+
+// Blocklist provides DNS blocklist stats
+// swagger:operation GET /dns/blocklist DNS DNSBlocklistDTO
+// ---
+// summary: Shows DNS blocklist stats
+// description: Returns the configured DNS blocklists, the number of the listed names and the blocked queries by consumer connection and service instance
+// responses:
+//   200:
+//     description: DNS blocklist stats
+//     schema:
+//       "$ref": "#/definitions/DNSBlocklistDTO"
+func (de *DNSEndpoint) Blocklist(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	var stats dns.BlocklistStats
+	if de.blocklist != nil {
+		stats = de.blocklist.Stats()
+	}
+	utils.WriteAsJSON(contract.NewDNSBlocklistDTO(stats), resp)
+}
+
+// AddRoutesForDNS adds DNS routes to given router
+func AddRoutesForDNS(router *httprouter.Router, blocklist *dns.Blocklist) {
+	var stats blocklistStats
+	if blocklist != nil {
+		stats = blocklist
+	}
+	dnsEndpoint := NewDNSEndpoint(stats)
+
+	router.GET("/dns/blocklist", dnsEndpoint.Blocklist)
+}
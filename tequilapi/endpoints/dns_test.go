@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DNSBlocklist_ReturnsStats(t *testing.T) {
+	blocklist := &mockBlocklist{stats: dns.BlocklistStats{
+		Sources:   []string{"https://example.com/hosts"},
+		Domains:   2,
+		UpdatedAt: time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC),
+		Hits:      map[string]uint64{"consumer": 3},
+	}}
+
+	req, err := http.NewRequest(http.MethodGet, "/dns/blocklist", nil)
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+	router := httprouter.New()
+	router.GET("/dns/blocklist", NewDNSEndpoint(blocklist).Blocklist)
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"sources": ["https://example.com/hosts"],
+		"domains": 2,
+		"updated_at": "2020-09-01T12:00:00Z",
+		"hits": {"consumer": 3}
+	}`, resp.Body.String())
+}
+
+func Test_DNSBlocklist_ReturnsEmptyStats_WithoutBlocklist(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/dns/blocklist", nil)
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+	router := httprouter.New()
+	AddRoutesForDNS(router, nil)
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"sources": [], "domains": 0, "hits": {}}`, resp.Body.String())
+}
+
+type mockBlocklist struct {
+	stats dns.BlocklistStats
+}
+
+func (m *mockBlocklist) Stats() dns.BlocklistStats {
+	return m.stats
+}
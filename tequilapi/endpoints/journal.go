@@ -0,0 +1,265 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/journal"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	journalDefaultLimit = 100
+	journalMaxLimit     = 1000
+)
+
+var errJournalDisabled = errors.New("event journal is disabled, select the recorded topics with --journal.topics")
+
+type eventJournal interface {
+	Enabled() bool
+	Entries(filter journal.Filter) ([]journal.Entry, error)
+	Listen() chan journal.Entry
+	Unlisten(listener chan journal.Entry)
+}
+
+type journalEndpoint struct {
+	journal eventJournal
+}
+
+// swagger:operation GET /events/journal Events listJournalEntries
+// ---
+// summary: List event journal entries
+// description: Lists the recorded events, oldest first
+// parameters:
+//   - in: query
+//     name: topic
+//     description: Event bus topic
+//     type: string
+//   - in: query
+//     name: from
+//     description: RFC3339 time the entries are recorded at or after
+//     type: string
+//   - in: query
+//     name: to
+//     description: RFC3339 time the entries are recorded at or before
+//     type: string
+//   - in: query
+//     name: after_id
+//     description: ID of the last entry already read
+//     type: integer
+//   - in: query
+//     name: limit
+//     description: Maximum number of the entries, 100 by default and 1000 at most
+//     type: integer
+// responses:
+//   200:
+//     description: List of journal entries
+//     schema:
+//       "$ref": "#/definitions/JournalEntryListResponse"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Event journal is disabled
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (e *journalEndpoint) Entries(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if !e.journal.Enabled() {
+		utils.SendError(resp, errJournalDisabled, http.StatusNotFound)
+		return
+	}
+
+	filter, err := parseJournalFilter(req)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	entries, err := e.journal.Entries(filter)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	response := contract.JournalEntryListResponse{Entries: []contract.JournalEntryDTO{}}
+	for _, entry := range entries {
+		response.Entries = append(response.Entries, contract.NewJournalEntryDTO(entry))
+	}
+	utils.WriteAsJSON(response, resp)
+}
+
+// swagger:operation GET /events/journal/stream Events streamJournalEntries
+// ---
+// summary: Stream event journal entries
+// description: Streams the recorded events as server-sent events with the entry ID as the event ID.
+//   The entries after the one given by Last-Event-ID header or last_event_id parameter are replayed first,
+//   so a reconnecting client does not miss any events.
+// parameters:
+//   - in: query
+//     name: topic
+//     description: Event bus topic
+//     type: string
+//   - in: query
+//     name: last_event_id
+//     description: ID of the last entry already read, used when Last-Event-ID header is not set
+//     type: integer
+// responses:
+//   200:
+//     description: Stream of JournalEntryDTO
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Event journal is disabled
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (e *journalEndpoint) Stream(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if !e.journal.Enabled() {
+		utils.SendError(resp, errJournalDisabled, http.StatusNotFound)
+		return
+	}
+	f, ok := resp.(http.Flusher)
+	if !ok {
+		utils.SendError(resp, errors.New("not a flusher - cannot continue"), http.StatusBadRequest)
+		return
+	}
+
+	lastEventID := req.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = req.URL.Query().Get("last_event_id")
+	}
+	var lastID uint64
+	if lastEventID != "" {
+		var err error
+		if lastID, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			utils.SendError(resp, errors.New("could not parse last event ID"), http.StatusBadRequest)
+			return
+		}
+	}
+	topic := req.URL.Query().Get("topic")
+
+	// Listen before replaying, so that nothing recorded in between is missed.
+	listener := e.journal.Listen()
+	defer e.journal.Unlisten(listener)
+
+	resp.Header().Set("Content-Type", "text/event-stream")
+	resp.Header().Set("Cache-Control", "no-cache,no-transform")
+	resp.Header().Set("Connection", "keep-alive")
+
+	for {
+		entries, err := e.journal.Entries(journal.Filter{Topic: topic, AfterID: lastID, Limit: journalMaxLimit})
+		if err != nil {
+			log.Error().Err(err).Msg("Could not replay the event journal")
+			return
+		}
+		for _, entry := range entries {
+			if err := writeJournalEntry(resp, entry); err != nil {
+				return
+			}
+			lastID = entry.ID
+		}
+		if len(entries) < journalMaxLimit {
+			break
+		}
+	}
+	f.Flush()
+
+	for {
+		select {
+		case entry, open := <-listener:
+			if !open {
+				// The client has fallen behind or the node is stopping, it resumes from the last received entry on reconnect.
+				return
+			}
+			if entry.ID <= lastID || (topic != "" && entry.Topic != topic) {
+				continue
+			}
+			if err := writeJournalEntry(resp, entry); err != nil {
+				return
+			}
+			lastID = entry.ID
+			f.Flush()
+		case <-req.Context().Done():
+			return
+		}
+	}
+}
+
+func writeJournalEntry(resp http.ResponseWriter, entry journal.Entry) error {
+	data, err := json.Marshal(contract.NewJournalEntryDTO(entry))
+	if err != nil {
+		log.Error().Err(err).Msg("Could not marshal journal entry")
+		return err
+	}
+	_, err = fmt.Fprintf(resp, "id: %d\ndata: %s\n\n", entry.ID, data)
+	return err
+}
+
+func parseJournalFilter(req *http.Request) (journal.Filter, error) {
+	query := req.URL.Query()
+	filter := journal.Filter{
+		Topic: query.Get("topic"),
+		Limit: journalDefaultLimit,
+	}
+
+	var err error
+	if from := query.Get("from"); from != "" {
+		if filter.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return filter, errors.New("could not parse from, RFC3339 time is expected")
+		}
+	}
+	if to := query.Get("to"); to != "" {
+		if filter.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return filter, errors.New("could not parse to, RFC3339 time is expected")
+		}
+	}
+	if afterID := query.Get("after_id"); afterID != "" {
+		if filter.AfterID, err = strconv.ParseUint(afterID, 10, 64); err != nil {
+			return filter, errors.New("could not parse after_id")
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit < 1 || filter.Limit > journalMaxLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", journalMaxLimit)
+		}
+	}
+	return filter, nil
+}
+
+// AddRoutesForJournal adds event journal routes to given router
+func AddRoutesForJournal(router *httprouter.Router, journal eventJournal) {
+	endpoint := &journalEndpoint{journal: journal}
+
+	router.GET("/events/journal", endpoint.Entries)
+	router.GET("/events/journal/stream", endpoint.Stream)
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/journal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_JournalEntries(t *testing.T) {
+	mock := newMockJournal(3)
+	router := httprouter.New()
+	AddRoutesForJournal(router, mock)
+
+	req := httptest.NewRequest(http.MethodGet, "/events/journal?topic=topic&from=2020-10-22T12:00:00Z&after_id=1&limit=2", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"entries": [
+		{"id": 2, "topic": "topic", "time": "2020-10-22T12:00:02Z", "payload": {"n": 2}},
+		{"id": 3, "topic": "topic", "time": "2020-10-22T12:00:03Z", "payload": {"n": 3}}
+	]}`, resp.Body.String())
+	assert.Equal(t, journal.Filter{
+		Topic:   "topic",
+		From:    time.Date(2020, 10, 22, 12, 0, 0, 0, time.UTC),
+		AfterID: 1,
+		Limit:   2,
+	}, mock.filter)
+
+	for _, query := range []string{"from=yesterday", "after_id=-1", "limit=0", "limit=1001"} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/events/journal?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, resp.Code, query)
+	}
+
+	mock.enabled = false
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/events/journal", nil))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func Test_JournalStream_ResumesFromLastEventID(t *testing.T) {
+	mock := newMockJournal(3)
+	router := httprouter.New()
+	AddRoutesForJournal(router, mock)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/events/journal/stream", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	assert.Equal(t, "id: 2", readLine(t, reader))
+	assert.Equal(t, `data: {"id":2,"topic":"topic","time":"2020-10-22T12:00:02Z","payload":{"n":2}}`, readLine(t, reader))
+	readLine(t, reader)
+	assert.Equal(t, "id: 3", readLine(t, reader))
+	readLine(t, reader)
+	readLine(t, reader)
+
+	// Entries already replayed are skipped.
+	listener := <-mock.listeners
+	listener <- mock.entries[2]
+	listener <- journal.Entry{ID: 4, Topic: "topic", Time: time.Date(2020, 10, 22, 12, 0, 4, 0, time.UTC), Payload: `{"n":4}`}
+	assert.Equal(t, "id: 4", readLine(t, reader))
+}
+
+func readLine(t *testing.T, reader *bufio.Reader) string {
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	return strings.TrimSuffix(line, "\n")
+}
+
+type mockJournal struct {
+	enabled   bool
+	entries   []journal.Entry
+	filter    journal.Filter
+	listeners chan chan journal.Entry
+}
+
+func newMockJournal(count int) *mockJournal {
+	mock := &mockJournal{enabled: true, listeners: make(chan chan journal.Entry, 1)}
+	for i := 1; i <= count; i++ {
+		mock.entries = append(mock.entries, journal.Entry{
+			ID:      uint64(i),
+			Topic:   "topic",
+			Time:    time.Date(2020, 10, 22, 12, 0, i, 0, time.UTC),
+			Payload: `{"n":` + string(rune('0'+i)) + `}`,
+		})
+	}
+	return mock
+}
+
+func (m *mockJournal) Enabled() bool {
+	return m.enabled
+}
+
+func (m *mockJournal) Entries(filter journal.Filter) ([]journal.Entry, error) {
+	m.filter = filter
+	var entries []journal.Entry
+	for _, entry := range m.entries {
+		if entry.ID > filter.AfterID && len(entries) < filter.Limit {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (m *mockJournal) Listen() chan journal.Entry {
+	listener := make(chan journal.Entry, 2)
+	m.listeners <- listener
+	return listener
+}
+
+func (m *mockJournal) Unlisten(listener chan journal.Entry) {}
@@ -0,0 +1,42 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// swagger:operation GET /metrics Metrics metrics
+// ---
+// summary: Returns node metrics
+// description: Returns node metrics in Prometheus text format
+// responses:
+//   200:
+//     description: Metrics in Prometheus text format
+func metricsHandler(handler http.Handler) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		handler.ServeHTTP(w, r)
+	}
+}
+
+// AddRoutesForMetrics adds Prometheus metrics route to given router
+func AddRoutesForMetrics(router *httprouter.Router, handler http.Handler) {
+	router.GET("/metrics", metricsHandler(handler))
+}
@@ -20,6 +20,7 @@ package endpoints
 import (
 	"math/big"
 	"net/http"
+	"strconv"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
@@ -69,6 +70,14 @@ func NewProposalsEndpoint(proposalRepository proposal.Repository, qualityProvide
 //     description: the access policy source to filter the proposals by
 //     type: string
 //   - in: query
+//     name: min_bandwidth_kbps
+//     description: the minimal bandwidth of every session in Kbps, proposals without bandwidth limit always match
+//     type: integer
+//   - in: query
+//     name: ipv6
+//     description: if set to true, returns only proposals of the services routing IPv6 traffic.
+//     type: boolean
+//   - in: query
 //     name: fetch_metrics
 //     description: if set to true, fetches the connection success metrics for nodes. False by default.
 //     type: boolean
@@ -104,6 +113,15 @@ func (pe *proposalsEndpoint) List(resp http.ResponseWriter, req *http.Request, p
 		return
 	}
 
+	var minBandwidth uint64
+	if bandwidth := req.URL.Query().Get("min_bandwidth_kbps"); bandwidth != "" {
+		minBandwidth, err = strconv.ParseUint(bandwidth, 10, 64)
+		if err != nil {
+			utils.SendError(resp, errors.New("could not parse minimal bandwidth"), http.StatusBadRequest)
+			return
+		}
+	}
+
 	proposals, err := pe.proposalRepository.Proposals(&proposal.Filter{
 		ProviderID:          req.URL.Query().Get("provider_id"),
 		ServiceType:         req.URL.Query().Get("service_type"),
@@ -113,6 +131,8 @@ func (pe *proposalsEndpoint) List(resp http.ResponseWriter, req *http.Request, p
 		UpperGBPriceBound:   upperGBPriceBound,
 		LowerTimePriceBound: lowerTimePriceBound,
 		UpperTimePriceBound: upperTimePriceBound,
+		MinBandwidthKbps:    minBandwidth,
+		IPv6:                req.URL.Query().Get("ipv6") == "true",
 		ExcludeUnsupported:  true,
 		IncludeFailed:       req.URL.Query().Get("monitoring_failed") == "true",
 	})
@@ -193,6 +193,61 @@ func TestProposalsEndpointAcceptsAccessPolicyParams(t *testing.T) {
 	)
 }
 
+func TestProposalsEndpointAcceptsBandwidthParam(t *testing.T) {
+	limited := serviceProposals[0]
+	limited.BandwidthKbps = 10000
+	repository := &mockProposalRepository{
+		proposals: []market.ServiceProposal{limited},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/irrelevant?min_bandwidth_kbps=5000", nil)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}).List
+	handlerFunc(resp, req, nil)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"bandwidth_kbps":10000`)
+	assert.Equal(t,
+		&proposal.Filter{
+			MinBandwidthKbps:   5000,
+			ExcludeUnsupported: true,
+		},
+		repository.recordedFilter,
+	)
+
+	req, err = http.NewRequest(http.MethodGet, "/irrelevant?min_bandwidth_kbps=fast", nil)
+	assert.Nil(t, err)
+
+	resp = httptest.NewRecorder()
+	handlerFunc(resp, req, nil)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestProposalsEndpointAcceptsIPv6Param(t *testing.T) {
+	repository := &mockProposalRepository{
+		proposals: serviceProposals,
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/irrelevant?ipv6=true", nil)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}).List
+	handlerFunc(resp, req, nil)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t,
+		&proposal.Filter{
+			IPv6:               true,
+			ExcludeUnsupported: true,
+		},
+		repository.recordedFilter,
+	)
+}
+
 func TestProposalsEndpointList(t *testing.T) {
 	repository := &mockProposalRepository{
 		proposals: serviceProposals,
@@ -373,6 +428,11 @@ func (m *mockProposalRepository) Proposal(id market.ProposalID) (*market.Service
 	if len(m.proposals) == 0 {
 		return nil, nil
 	}
+	for i := range m.proposals {
+		if m.proposals[i].ProviderID == id.ProviderID {
+			return &m.proposals[i], nil
+		}
+	}
 	return &m.proposals[0], nil
 }
 
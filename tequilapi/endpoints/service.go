@@ -24,9 +24,7 @@ import (
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/identity"
-	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/services"
-	"github.com/mysteriumnetwork/node/session/pingpong"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
 	"github.com/mysteriumnetwork/node/tequilapi/validation"
@@ -154,7 +152,7 @@ func (se *ServiceEndpoint) ServiceStart(resp http.ResponseWriter, req *http.Requ
 		sr.Type,
 		sr.AccessPolicies.IDs,
 		sr.Options,
-		pingpong.NewPaymentMethod(sr.PaymentMethod.PriceGB, sr.PaymentMethod.PriceMinute),
+		service.Price{PerGB: sr.PaymentMethod.PriceGB, PerMinute: sr.PaymentMethod.PriceMinute},
 	)
 	if err == service.ErrorLocation {
 		utils.SendError(resp, err, http.StatusBadRequest)
@@ -293,7 +291,7 @@ func toServiceInfoResponse(id service.ID, instance *service.Instance) contract.S
 		Type:       instance.Type,
 		Options:    instance.Options,
 		Status:     string(instance.State()),
-		Proposal:   contract.NewProposalDTO(instance.Proposal),
+		Proposal:   contract.NewProposalDTO(instance.CopyProposal()),
 	}
 }
 
@@ -324,7 +322,7 @@ func validateServiceRequest(sr contract.ServiceStartRequest) *validation.FieldEr
 
 // ServiceManager represents service manager that is used for services management.
 type ServiceManager interface {
-	Start(providerID identity.Identity, serviceType string, policies []string, options service.Options, pm market.PaymentMethod) (service.ID, error)
+	Start(providerID identity.Identity, serviceType string, policies []string, options service.Options, basePrice service.Price) (service.ID, error)
 	Stop(id service.ID) error
 	Service(id service.ID) *service.Instance
 	Kill() error
@@ -29,6 +29,7 @@ import (
 	nodeEvent "github.com/mysteriumnetwork/node/core/node/event"
 	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
 	"github.com/mysteriumnetwork/node/eventbus"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -50,6 +51,8 @@ const (
 	ServiceStatusEvent EventType = "service-status"
 	// StateChangeEvent represents the state change
 	StateChangeEvent EventType = "state-change"
+	// SpendingLimitReachedEvent represents the consumer connection disconnected due to the spending limit
+	SpendingLimitReachedEvent EventType = "spending-limit-reached"
 )
 
 // Handler represents an sse handler
@@ -86,7 +89,10 @@ func (h *Handler) Subscribe(bus eventbus.Subscriber) error {
 		return err
 	}
 	err = bus.Subscribe(stateEvent.AppTopicState, h.ConsumeStateEvent)
-	return err
+	if err != nil {
+		return err
+	}
+	return bus.Subscribe(pingpongEvent.AppTopicSpendingLimitReached, h.ConsumeSpendingLimitEvent)
 }
 
 // Sub subscribes a user to sse
@@ -267,3 +273,27 @@ func (h *Handler) ConsumeStateEvent(event stateEvent.State) {
 		Payload: mapState(event),
 	})
 }
+
+type spendingLimitRes struct {
+	ConsumerID string   `json:"consumer_id"`
+	ProviderID string   `json:"provider_id"`
+	SessionID  string   `json:"session_id"`
+	Limit      string   `json:"limit"`
+	Cap        *big.Int `json:"cap"`
+	Spent      *big.Int `json:"spent"`
+}
+
+// ConsumeSpendingLimitEvent consumes the spending limit event
+func (h *Handler) ConsumeSpendingLimitEvent(e pingpongEvent.AppEventSpendingLimitReached) {
+	h.send(Event{
+		Type: SpendingLimitReachedEvent,
+		Payload: spendingLimitRes{
+			ConsumerID: e.ConsumerID.Address,
+			ProviderID: e.ProviderID.Address,
+			SessionID:  e.SessionID,
+			Limit:      e.Limit,
+			Cap:        e.Cap,
+			Spent:      e.Spent,
+		},
+	})
+}
@@ -33,7 +33,9 @@ import (
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	nodeEvent "github.com/mysteriumnetwork/node/core/node/event"
 	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/stretchr/testify/assert"
 )
@@ -258,3 +260,28 @@ func TestHandler_SendsInitialAndFollowingStates(t *testing.T) {
 
 	<-serveExit
 }
+
+func TestHandler_ConsumeSpendingLimitEvent(t *testing.T) {
+	h := NewSSEHandler(&mockStateProvider{})
+
+	h.ConsumeSpendingLimitEvent(pingpongEvent.AppEventSpendingLimitReached{
+		ConsumerID: identity.FromAddress("0x1"),
+		ProviderID: identity.FromAddress("0x2"),
+		SessionID:  "session",
+		Limit:      pingpongEvent.SpendingLimitDay,
+		Cap:        big.NewInt(100),
+		Spent:      big.NewInt(120),
+	})
+
+	assert.JSONEq(t, `{
+  "payload": {
+    "consumer_id": "0x1",
+    "provider_id": "0x2",
+    "session_id": "session",
+    "limit": "day",
+    "cap": 100,
+    "spent": 120
+  },
+  "type": "spending-limit-reached"
+}`, <-h.messages)
+}
@@ -0,0 +1,233 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/webhook"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type webhooks interface {
+	Create(url string, events []string, secret string) (webhook.Webhook, error)
+	List() ([]webhook.Webhook, error)
+	Delete(id string) error
+	Deliveries(filter webhook.DeliveryFilter) ([]webhook.Delivery, error)
+	Retry(id string) (webhook.Delivery, error)
+}
+
+type webhookEndpoint struct {
+	webhooks webhooks
+}
+
+// swagger:operation GET /webhooks Webhooks listWebhooks
+// ---
+// summary: List webhooks
+// description: Lists webhooks the node events are posted to, their secrets are left out
+// responses:
+//   200:
+//     description: List of webhooks
+//     schema:
+//       "$ref": "#/definitions/WebhookListResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (e *webhookEndpoint) List(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	hooks, err := e.webhooks.List()
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	response := contract.WebhookListResponse{Webhooks: []contract.WebhookDTO{}}
+	for _, hook := range hooks {
+		response.Webhooks = append(response.Webhooks, contract.NewWebhookDTO(hook))
+	}
+	utils.WriteAsJSON(response, resp)
+}
+
+// swagger:operation POST /webhooks Webhooks createWebhook
+// ---
+// summary: Create webhook
+// description: Creates webhook posting the matching node events signed by HMAC-SHA256, the secret is only returned once
+// parameters:
+//   - in: body
+//     name: body
+//     schema:
+//       $ref: "#/definitions/WebhookRequest"
+// responses:
+//   200:
+//     description: Webhook created
+//     schema:
+//       "$ref": "#/definitions/WebhookDTO"
+//   400:
+//     description: Body parsing error or invalid URL or event
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (e *webhookEndpoint) Create(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var request contract.WebhookRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	hook, err := e.webhooks.Create(request.URL, request.Events, request.Secret)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	response := contract.NewWebhookDTO(hook)
+	response.Secret = hook.Secret
+	utils.WriteAsJSON(response, resp)
+}
+
+// swagger:operation DELETE /webhooks/{id} Webhooks deleteWebhook
+// ---
+// summary: Delete webhook
+// description: Deletes webhook, its pending deliveries are given up
+// parameters:
+//   - in: path
+//     name: id
+//     description: ID of the webhook
+//     type: string
+//     required: true
+// responses:
+//   202:
+//     description: Webhook deleted
+//   404:
+//     description: Webhook not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (e *webhookEndpoint) Delete(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	err := e.webhooks.Delete(params.ByName("id"))
+	if errors.Is(err, webhook.ErrWebhookNotFound) {
+		utils.SendError(resp, err, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	resp.WriteHeader(http.StatusAccepted)
+}
+
+// swagger:operation GET /webhooks/deliveries Webhooks listWebhookDeliveries
+// ---
+// summary: List webhook deliveries
+// description: Lists deliveries of the node events to the webhooks, the latest first
+// parameters:
+//   - in: query
+//     name: webhook_id
+//     description: ID of the webhook
+//     type: string
+//   - in: query
+//     name: status
+//     description: Status of the delivery
+//     type: string
+//     enum: [pending, delivered, failed]
+// responses:
+//   200:
+//     description: List of webhook deliveries
+//     schema:
+//       "$ref": "#/definitions/WebhookDeliveryListResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (e *webhookEndpoint) Deliveries(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	query := req.URL.Query()
+	deliveries, err := e.webhooks.Deliveries(webhook.DeliveryFilter{
+		WebhookID: query.Get("webhook_id"),
+		Status:    webhook.DeliveryStatus(query.Get("status")),
+	})
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	response := contract.WebhookDeliveryListResponse{Deliveries: []contract.WebhookDeliveryDTO{}}
+	for _, delivery := range deliveries {
+		response.Deliveries = append(response.Deliveries, contract.NewWebhookDeliveryDTO(delivery))
+	}
+	utils.WriteAsJSON(response, resp)
+}
+
+// swagger:operation POST /webhooks/deliveries/{id}/retry Webhooks retryWebhookDelivery
+// ---
+// summary: Retry webhook delivery
+// description: Queues the delivered or failed event to be posted to the webhook again
+// parameters:
+//   - in: path
+//     name: id
+//     description: ID of the webhook delivery
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Webhook delivery queued
+//     schema:
+//       "$ref": "#/definitions/WebhookDeliveryDTO"
+//   404:
+//     description: Webhook delivery not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   409:
+//     description: Webhook delivery is still pending
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (e *webhookEndpoint) Retry(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	delivery, err := e.webhooks.Retry(params.ByName("id"))
+	switch {
+	case errors.Is(err, webhook.ErrDeliveryNotFound):
+		utils.SendError(resp, err, http.StatusNotFound)
+		return
+	case errors.Is(err, webhook.ErrDeliveryPending):
+		utils.SendError(resp, err, http.StatusConflict)
+		return
+	case err != nil:
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	utils.WriteAsJSON(contract.NewWebhookDeliveryDTO(delivery), resp)
+}
+
+// AddRoutesForWebhooks adds webhook routes to given router
+func AddRoutesForWebhooks(router *httprouter.Router, webhooks webhooks) {
+	endpoint := &webhookEndpoint{webhooks: webhooks}
+
+	router.GET("/webhooks", endpoint.List)
+	router.POST("/webhooks", endpoint.Create)
+	router.DELETE("/webhooks/:id", endpoint.Delete)
+	router.GET("/webhooks/deliveries", endpoint.Deliveries)
+	router.POST("/webhooks/deliveries/:id/retry", endpoint.Retry)
+}
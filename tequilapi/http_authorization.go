@@ -0,0 +1,141 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tequilapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/core/auth"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+// Authorizer resolves the scopes granted by the token.
+type Authorizer interface {
+	Authorize(token string) ([]auth.Scope, error)
+	TokensIssued() (bool, error)
+}
+
+type authorizationHandler struct {
+	originalHandler http.Handler
+	authorizer      Authorizer
+	required        bool
+}
+
+// ApplyAuthorization wraps original handler by checking whether the token of the request grants the scope of the route.
+// Requests without the token are let through unless the authorization is required or any API token is issued,
+// since scoped tokens would be pointless if the requests without them were granted everything.
+func ApplyAuthorization(original http.Handler, authorizer Authorizer, required bool) http.Handler {
+	return &authorizationHandler{originalHandler: original, authorizer: authorizer, required: required}
+}
+
+func (wrapper *authorizationHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if isPublicRoute(req) {
+		wrapper.originalHandler.ServeHTTP(resp, req)
+		return
+	}
+
+	token := requestToken(req)
+	if token == "" {
+		required := wrapper.required
+		if !required {
+			issued, err := wrapper.authorizer.TokensIssued()
+			if err != nil {
+				utils.SendError(resp, err, http.StatusInternalServerError)
+				return
+			}
+			required = issued
+		}
+		if required {
+			utils.SendErrorMessage(resp, "authorization token is required", http.StatusUnauthorized)
+			return
+		}
+		wrapper.originalHandler.ServeHTTP(resp, req)
+		return
+	}
+
+	scopes, err := wrapper.authorizer.Authorize(token)
+	if errors.Is(err, auth.ErrUnauthorized) {
+		utils.SendError(resp, err, http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	if required := requiredScope(req); !auth.Allows(scopes, required) {
+		utils.SendErrorMessage(resp, "token lacks the "+string(required)+" scope", http.StatusForbidden)
+		return
+	}
+	wrapper.originalHandler.ServeHTTP(resp, req)
+}
+
+func requestToken(req *http.Request) string {
+	if header := req.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimPrefix(header, "Bearer ")
+	}
+	if cookie, err := req.Cookie(auth.JWTCookieName); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+func isPublicRoute(req *http.Request) bool {
+	switch {
+	case req.Method == http.MethodOptions:
+		return true
+	case req.URL.Path == "/healthcheck", req.URL.Path == "/", strings.HasPrefix(req.URL.Path, "/docs/"):
+		return req.Method == http.MethodGet
+	case req.URL.Path == "/auth/authenticate", req.URL.Path == "/auth/login", req.URL.Path == "/auth/logout":
+		return true
+	}
+	return false
+}
+
+// requiredScope maps the routes to the scopes needed to access them.
+func requiredScope(req *http.Request) auth.Scope {
+	path := req.URL.Path
+	switch {
+	case strings.HasPrefix(path, "/auth/tokens"), strings.HasPrefix(path, "/mmn/api-key"), strings.HasPrefix(path, "/debug/pprof"),
+		strings.HasPrefix(path, "/webhooks"):
+		return auth.ScopeAdmin
+	case req.Method == http.MethodGet || req.Method == http.MethodHead:
+		return auth.ScopeReadOnly
+	case path == "/connection":
+		return auth.ScopeConnection
+	case path == "/services" || strings.HasPrefix(path, "/services/"):
+		return auth.ScopeService
+	case strings.HasPrefix(path, "/transactor/"), isIdentityPaymentRoute(path):
+		return auth.ScopePayments
+	}
+	return auth.ScopeAdmin
+}
+
+func isIdentityPaymentRoute(path string) bool {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "identities" {
+		return false
+	}
+	switch parts[2] {
+	case "payout", "beneficiary", "payment-order", "register":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tequilapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockAuthorizer struct {
+	tokens map[string][]auth.Scope
+	issued bool
+}
+
+func (ma *mockAuthorizer) Authorize(token string) ([]auth.Scope, error) {
+	scopes, ok := ma.tokens[token]
+	if !ok {
+		return nil, auth.ErrUnauthorized
+	}
+	return scopes, nil
+}
+
+func (ma *mockAuthorizer) TokensIssued() (bool, error) {
+	return ma.issued, nil
+}
+
+var testTokens = map[string][]auth.Scope{
+	"dashboard": {auth.ScopeReadOnly},
+	"vpn":       {auth.ScopeConnection},
+	"admin":     {auth.ScopeAdmin},
+}
+
+func TestAuthorization(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		method   string
+		path     string
+		token    string
+		required bool
+		issued   bool
+		status   int
+	}{
+		{name: "read-only reads", method: http.MethodGet, path: "/identities", token: "dashboard", status: http.StatusOK},
+		{name: "read-only withdraws", method: http.MethodPost, path: "/transactor/settle/sync", token: "dashboard", status: http.StatusForbidden},
+		{name: "read-only sets payout", method: http.MethodPut, path: "/identities/0x1/payout", token: "dashboard", status: http.StatusForbidden},
+		{name: "read-only stops", method: http.MethodPost, path: "/stop", token: "dashboard", status: http.StatusForbidden},
+		{name: "read-only reads API key", method: http.MethodGet, path: "/mmn/api-key", token: "dashboard", status: http.StatusForbidden},
+		{name: "read-only reads webhooks", method: http.MethodGet, path: "/webhooks/deliveries", token: "dashboard", status: http.StatusForbidden},
+		{name: "connection control connects", method: http.MethodPut, path: "/connection", token: "vpn", status: http.StatusOK},
+		{name: "connection control starts service", method: http.MethodPost, path: "/services", token: "vpn", status: http.StatusForbidden},
+		{name: "admin stops", method: http.MethodPost, path: "/stop", token: "admin", status: http.StatusOK},
+		{name: "invalid token", method: http.MethodGet, path: "/identities", token: "invalid", status: http.StatusUnauthorized},
+		{name: "no token", method: http.MethodPost, path: "/stop", status: http.StatusOK},
+		{name: "no token when required", method: http.MethodGet, path: "/identities", required: true, status: http.StatusUnauthorized},
+		{name: "no token when API tokens are issued", method: http.MethodPost, path: "/stop", issued: true, status: http.StatusUnauthorized},
+		{name: "public route when API tokens are issued", method: http.MethodGet, path: "/healthcheck", issued: true, status: http.StatusOK},
+		{name: "public route when required", method: http.MethodGet, path: "/healthcheck", required: true, status: http.StatusOK},
+		{name: "login when required", method: http.MethodPost, path: "/auth/login", required: true, status: http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			resp := httptest.NewRecorder()
+			mock := &mockedHTTPHandler{}
+
+			authorizer := &mockAuthorizer{tokens: testTokens, issued: tc.issued}
+			ApplyAuthorization(mock, authorizer, tc.required).ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.status, resp.Code)
+			assert.Equal(t, tc.status == http.StatusOK, mock.wasCalled)
+		})
+	}
+}
+
+func TestAuthorizationUsesCookie(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/stop", nil)
+	req.AddCookie(&http.Cookie{Name: auth.JWTCookieName, Value: "dashboard"})
+	resp := httptest.NewRecorder()
+	mock := &mockedHTTPHandler{}
+
+	ApplyAuthorization(mock, &mockAuthorizer{tokens: testTokens}, false).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.False(t, mock.wasCalled)
+}
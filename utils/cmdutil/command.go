@@ -57,3 +57,17 @@ func ExecOutput(args ...string) (output string, err error) {
 	}
 	return string(out), nil
 }
+
+// ExecOutputWithInput executes external command with the given standard input and logs output on the debug level.
+// It returns a combined stderr and stdout output and exit code in case of an error.
+func ExecOutputWithInput(input string, args ...string) (output string, err error) {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(input)
+	out, err := cmd.CombinedOutput()
+	logSkipFrame := log.With().CallerWithSkipFrameCount(3).Logger()
+	(&logSkipFrame).Debug().Msgf("%q input:\n%s\noutput:\n%s", strings.Join(args, " "), input, out)
+	if err != nil {
+		return string(out), errors.Errorf("%q: %v output: %s", strings.Join(args, " "), err, out)
+	}
+	return string(out), nil
+}
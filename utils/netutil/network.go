@@ -23,7 +23,7 @@ import (
 	"strings"
 
 	"github.com/jackpal/gateway"
-	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -44,12 +44,12 @@ type route struct {
 }
 
 type routeManager struct {
-	db          *boltdb.Bolt
+	db          storage.Storage
 	deleteRoute func(ip, wg string) error
 }
 
 // SetRouteManagerStorage initiate defaultRouteManager with a provided storage.
-func SetRouteManagerStorage(db *boltdb.Bolt) {
+func SetRouteManagerStorage(db storage.Storage) {
 	defaultRouteManager = &routeManager{
 		db:          db,
 		deleteRoute: deleteRoute,
@@ -96,16 +96,57 @@ func ExcludeRoute(ip net.IP) error {
 		return fmt.Errorf("failed to get default gateway: %w", err)
 	}
 
-	if defaultRouteManager != nil {
-		err := defaultRouteManager.db.Store(routeRecordBucket, &route{
-			Record: strings.Join([]string{ip.String(), gw.String()}, routeRecordDelimeter),
-		})
-		if err != nil {
-			log.Error().Err(err).Msgf("Failed to save %s record", routeRecordBucket)
-		}
+	storeRoute(ip.String(), gw)
+	return excludeRoute(ip, gw)
+}
+
+// ExcludeNetwork excludes given network from VPN tunnel.
+func ExcludeNetwork(network net.IPNet) error {
+	// Directly connected networks are routed outside of the tunnel already.
+	if isLocalNetwork(network) {
+		return nil
 	}
 
-	return excludeRoute(ip, gw)
+	gw, err := gateway.DiscoverGateway()
+	if err != nil {
+		return fmt.Errorf("failed to get default gateway: %w", err)
+	}
+
+	storeRoute(network.String(), gw)
+	return excludeNetwork(network, gw)
+}
+
+func storeRoute(destination string, gw net.IP) {
+	if defaultRouteManager == nil {
+		return
+	}
+
+	err := defaultRouteManager.db.Store(routeRecordBucket, &route{
+		Record: strings.Join([]string{destination, gw.String()}, routeRecordDelimeter),
+	})
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to save %s record", routeRecordBucket)
+	}
+}
+
+func isLocalNetwork(network net.IPNet) bool {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+
+	size, _ := network.Mask.Size()
+	for _, addr := range addrs {
+		local, ok := addr.(*net.IPNet)
+		if !ok || local.IP.IsLoopback() {
+			continue
+		}
+		localSize, _ := local.Mask.Size()
+		if local.Contains(network.IP) && localSize <= size {
+			return true
+		}
+	}
+	return false
 }
 
 // AddDefaultRoute adds default VPN tunnel route.
@@ -113,8 +154,89 @@ func AddDefaultRoute(iface string) error {
 	return addDefaultRoute(iface)
 }
 
+// AddDefaultRoute6 adds default VPN tunnel route for IPv6 traffic.
+func AddDefaultRoute6(iface string) error {
+	return addDefaultRoute6(iface)
+}
+
+// AddRoute routes given network through the given tunnel interface.
+func AddRoute(network net.IPNet, iface string) error {
+	return addRoute(network, iface)
+}
+
+// TunnelRoutes describes routes of the consumer VPN tunnel.
+type TunnelRoutes struct {
+	// Iface is the tunnel interface.
+	Iface string
+	// Peer is the tunnel endpoint, which is always reached outside of the tunnel.
+	Peer net.IP
+	// OuterIface is set when the tunnel is nested into another one, Peer is reached through it.
+	OuterIface string
+	// Include limits the tunnel to the given networks, all traffic is routed through it when empty.
+	Include []net.IPNet
+	// Exclude networks are routed outside of the tunnel.
+	Exclude []net.IPNet
+	// IPv6 is set when the tunnel carries IPv6 traffic, it is routed through the tunnel together with IPv4 one then.
+	IPv6 bool
+}
+
+// ConfigureTunnelRoutes routes traffic through the consumer VPN tunnel.
+// Nested tunnel routes all traffic of the outer one, which takes care of the split tunneling.
+func ConfigureTunnelRoutes(routes TunnelRoutes) error {
+	if routes.OuterIface != "" {
+		if err := RouteVia(routes.Peer, routes.OuterIface); err != nil {
+			return fmt.Errorf("could not route %s via %s: %w", routes.Peer, routes.OuterIface, err)
+		}
+		if err := AddNestedDefaultRoute(routes.Iface); err != nil {
+			return fmt.Errorf("could not add nested default route for %s: %w", routes.Iface, err)
+		}
+		return nil
+	}
+
+	if err := ExcludeRoute(routes.Peer); err != nil {
+		return fmt.Errorf("could not exclude route %s: %w", routes.Peer, err)
+	}
+	for _, network := range routes.Exclude {
+		if err := ExcludeNetwork(network); err != nil {
+			return fmt.Errorf("could not exclude route %s: %w", network.String(), err)
+		}
+	}
+
+	if len(routes.Include) == 0 {
+		if err := AddDefaultRoute(routes.Iface); err != nil {
+			return fmt.Errorf("could not add default route for %s: %w", routes.Iface, err)
+		}
+		if routes.IPv6 {
+			if err := AddDefaultRoute6(routes.Iface); err != nil {
+				return fmt.Errorf("could not add default IPv6 route for %s: %w", routes.Iface, err)
+			}
+		}
+		return nil
+	}
+	for _, network := range routes.Include {
+		if err := AddRoute(network, routes.Iface); err != nil {
+			return fmt.Errorf("could not route %s via %s: %w", network.String(), routes.Iface, err)
+		}
+	}
+	return nil
+}
+
+// RouteVia routes given IP through the given tunnel interface.
+func RouteVia(ip net.IP, iface string) error {
+	return routeVia(ip, iface)
+}
+
+// AddNestedDefaultRoute adds default VPN tunnel route for a tunnel nested into another one.
+// Routes are more specific than the default routes of the outer tunnel, so they take precedence.
+func AddNestedDefaultRoute(iface string) error {
+	return addNestedDefaultRoute(iface)
+}
+
 // AssignIP assigns subnet to given interface.
 func AssignIP(iface string, subnet net.IPNet) error {
+	if subnet.IP.To4() == nil {
+		return assignIP6(iface, subnet)
+	}
 	return assignIP(iface, subnet)
 }
 
@@ -20,6 +20,7 @@ package netutil
 import (
 	"net"
 	"os/exec"
+	"strconv"
 
 	"github.com/mysteriumnetwork/node/utils/cmdutil"
 )
@@ -28,10 +29,19 @@ func assignIP(iface string, subnet net.IPNet) error {
 	return cmdutil.SudoExec("ifconfig", iface, subnet.String(), peerIP(subnet).String())
 }
 
+func assignIP6(iface string, subnet net.IPNet) error {
+	prefixLen, _ := subnet.Mask.Size()
+	return cmdutil.SudoExec("ifconfig", iface, "inet6", subnet.IP.String(), "prefixlen", strconv.Itoa(prefixLen), "alias")
+}
+
 func excludeRoute(ip, gw net.IP) error {
 	return cmdutil.SudoExec("route", "add", "-host", ip.String(), gw.String())
 }
 
+func excludeNetwork(network net.IPNet, gw net.IP) error {
+	return cmdutil.SudoExec("route", "add", "-net", network.String(), gw.String())
+}
+
 func deleteRoute(ip, gw string) error {
 	return cmdutil.SudoExec("route", "delete", ip, gw)
 }
@@ -44,6 +54,32 @@ func addDefaultRoute(iface string) error {
 	return cmdutil.SudoExec("route", "add", "-net", "128.0.0.0/1", "-interface", iface)
 }
 
+func addDefaultRoute6(iface string) error {
+	if err := cmdutil.SudoExec("route", "add", "-inet6", "-net", "::/1", "-interface", iface); err != nil {
+		return err
+	}
+
+	return cmdutil.SudoExec("route", "add", "-inet6", "-net", "8000::/1", "-interface", iface)
+}
+
+func addRoute(network net.IPNet, iface string) error {
+	return cmdutil.SudoExec("route", "add", "-net", network.String(), "-interface", iface)
+}
+
+func routeVia(ip net.IP, iface string) error {
+	return cmdutil.SudoExec("route", "add", "-host", ip.String(), "-interface", iface)
+}
+
+func addNestedDefaultRoute(iface string) error {
+	for _, subnet := range []string{"0.0.0.0/2", "64.0.0.0/2", "128.0.0.0/2", "192.0.0.0/2"} {
+		if err := cmdutil.SudoExec("route", "add", "-net", subnet, "-interface", iface); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func peerIP(subnet net.IPNet) net.IP {
 	lastOctetID := len(subnet.IP) - 1
 	if subnet.IP[lastOctetID] == byte(1) {
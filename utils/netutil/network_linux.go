@@ -31,10 +31,18 @@ func assignIP(iface string, subnet net.IPNet) error {
 	return cmdutil.SudoExec("ip", "link", "set", "dev", iface, "up")
 }
 
+func assignIP6(iface string, subnet net.IPNet) error {
+	return cmdutil.SudoExec("ip", "-6", "address", "replace", "dev", iface, subnet.String())
+}
+
 func excludeRoute(ip, gw net.IP) error {
 	return cmdutil.SudoExec("ip", "route", "add", ip.String(), "via", gw.String())
 }
 
+func excludeNetwork(network net.IPNet, gw net.IP) error {
+	return cmdutil.SudoExec("ip", "route", "add", network.String(), "via", gw.String())
+}
+
 func deleteRoute(ip, gw string) error {
 	return cmdutil.SudoExec("ip", "route", "delete", ip, "via", gw)
 }
@@ -47,6 +55,32 @@ func addDefaultRoute(iface string) error {
 	return cmdutil.SudoExec("ip", "route", "add", "128.0.0.0/1", "dev", iface)
 }
 
+func addDefaultRoute6(iface string) error {
+	if err := cmdutil.SudoExec("ip", "-6", "route", "add", "::/1", "dev", iface); err != nil {
+		return err
+	}
+
+	return cmdutil.SudoExec("ip", "-6", "route", "add", "8000::/1", "dev", iface)
+}
+
+func addRoute(network net.IPNet, iface string) error {
+	return cmdutil.SudoExec("ip", "route", "add", network.String(), "dev", iface)
+}
+
+func routeVia(ip net.IP, iface string) error {
+	return cmdutil.SudoExec("ip", "route", "add", ip.String(), "dev", iface)
+}
+
+func addNestedDefaultRoute(iface string) error {
+	for _, subnet := range []string{"0.0.0.0/2", "64.0.0.0/2", "128.0.0.0/2", "192.0.0.0/2"} {
+		if err := cmdutil.SudoExec("ip", "route", "add", subnet, "dev", iface); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func logNetworkStats() {
 	for _, args := range [][]string{{"iptables", "-L", "-n"}, {"iptables", "-L", "-n", "-t", "nat"}, {"ip", "route", "list"}, {"ip", "address", "list"}} {
 		out, err := exec.Command("sudo", args...).CombinedOutput()
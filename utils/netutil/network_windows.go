@@ -22,6 +22,7 @@ import (
 	"net"
 	"os/exec"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -32,13 +33,28 @@ func assignIP(iface string, subnet net.IPNet) error {
 	return errors.Wrap(err, string(out))
 }
 
+func assignIP6(iface string, subnet net.IPNet) error {
+	out, err := exec.Command("powershell", "-Command", "netsh interface ipv6 add address interface=\""+iface+"\" address="+subnet.String()).CombinedOutput()
+	return errors.Wrap(err, string(out))
+}
+
 func excludeRoute(ip, gw net.IP) error {
 	out, err := exec.Command("powershell", "-Command", "route add "+ip.String()+"/32 "+gw.String()).CombinedOutput()
 	return errors.Wrap(err, string(out))
 }
 
+func excludeNetwork(network net.IPNet, gw net.IP) error {
+	out, err := exec.Command("powershell", "-Command", "route add "+network.String()+" "+gw.String()).CombinedOutput()
+	return errors.Wrap(err, string(out))
+}
+
 func deleteRoute(ip, gw string) error {
-	out, err := exec.Command("powershell", "-Command", "route delete "+ip+"/32").CombinedOutput()
+	// Excluded networks are stored in CIDR notation, single IPs are not.
+	if !strings.Contains(ip, "/") {
+		ip += "/32"
+	}
+
+	out, err := exec.Command("powershell", "-Command", "route delete "+ip).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to delete route: %w, %s", err, string(out))
 	}
@@ -60,6 +76,51 @@ func addDefaultRoute(name string) error {
 	return errors.Wrap(err, string(out))
 }
 
+func addDefaultRoute6(name string) error {
+	for _, prefix := range []string{"::/1", "8000::/1"} {
+		if out, err := exec.Command("powershell", "-Command", "netsh interface ipv6 add route prefix="+prefix+" interface=\""+name+"\"").CombinedOutput(); err != nil {
+			return errors.Wrap(err, string(out))
+		}
+	}
+
+	return nil
+}
+
+func addRoute(network net.IPNet, name string) error {
+	id, gw, err := interfaceInfo(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to get info of interface: "+name)
+	}
+
+	out, err := exec.Command("powershell", "-Command", "route add "+network.String()+" "+gw+" if "+id).CombinedOutput()
+	return errors.Wrap(err, string(out))
+}
+
+func routeVia(ip net.IP, name string) error {
+	id, gw, err := interfaceInfo(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to get info of interface: "+name)
+	}
+
+	out, err := exec.Command("powershell", "-Command", "route add "+ip.String()+"/32 "+gw+" if "+id).CombinedOutput()
+	return errors.Wrap(err, string(out))
+}
+
+func addNestedDefaultRoute(name string) error {
+	id, gw, err := interfaceInfo(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to get info of interface: "+name)
+	}
+
+	for _, subnet := range []string{"0.0.0.0/2", "64.0.0.0/2", "128.0.0.0/2", "192.0.0.0/2"} {
+		if out, err := exec.Command("powershell", "-Command", "route add "+subnet+" "+gw+" if "+id).CombinedOutput(); err != nil {
+			return errors.Wrap(err, string(out))
+		}
+	}
+
+	return nil
+}
+
 func interfaceInfo(name string) (id, gw string, err error) {
 	iface, err := net.InterfaceByName(name)
 	if err != nil {